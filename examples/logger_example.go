@@ -27,7 +27,10 @@ func main() {
 		return
 	}
 
-	logger.InitDefaultLogger(logConfig)
+	if err := logger.InitDefaultLogger(logConfig); err != nil {
+		fmt.Printf("初始化日志失败: %v\n", err)
+		return
+	}
 	log := logger.GetDefaultLogger()
 	defer log.Close()
 
@@ -0,0 +1,315 @@
+// Package backtest 在历史K线上逐根回放筛选策略，输出交易次数、胜率、盈亏比、收益和回撤的汇总表，
+// 作为策略上线前的快速反馈。信号与扫描器使用相同的逻辑（indicators.Scanner.EvaluateStrategy）
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+)
+
+// 平仓原因常量
+const (
+	ExitSignal     = "signal"      // 卖出信号
+	ExitStopLoss   = "stop_loss"   // 触及止损价
+	ExitTakeProfit = "take_profit" // 触及止盈价
+	ExitMaxHold    = "max_hold"    // 超过最长持有K线数
+	ExitEnd        = "end"         // 回测结束时按最后收盘价平仓
+)
+
+// Options 表示回测参数，零值字段使用默认值
+type Options struct {
+	Timeframe         string                        // K线周期，默认为 day
+	WarmupDays        int                           // 在回测区间之前多取的天数，用于指标预热，默认100
+	MinScore          float64                       // 买入或卖出信号得分之和达到该值时开仓或平仓，默认0.5
+	StopLossPercent   float64                       // 止损百分比，为0时不设止损
+	TakeProfitPercent float64                       // 止盈百分比，为0时不设止盈
+	MaxHoldBars       int                           // 最长持有的K线数，为0时不限制
+	CommissionPercent float64                       // 每次买入和卖出的佣金百分比
+	Registry          *indicators.IndicatorRegistry // 指标注册表，为空时使用默认注册表
+}
+
+// withDefaults 返回填充默认值后的参数（内部方法）
+func (o Options) withDefaults() Options {
+	if o.Timeframe == "" {
+		o.Timeframe = "day"
+	}
+	if o.WarmupDays <= 0 {
+		o.WarmupDays = 100
+	}
+	if o.MinScore <= 0 {
+		o.MinScore = 0.5
+	}
+	if o.Registry == nil {
+		o.Registry = indicators.NewIndicatorRegistry()
+	}
+	return o
+}
+
+// Trade 表示一笔回测交易，只做多
+type Trade struct {
+	Symbol     string    `json:"symbol"`
+	EntryTime  time.Time `json:"entry_time"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitTime   time.Time `json:"exit_time"`
+	ExitPrice  float64   `json:"exit_price"`
+	Bars       int       `json:"bars"`       // 持有的K线数
+	Return     float64   `json:"return_pct"` // 扣除佣金后的收益百分比
+	Reason     string    `json:"reason"`
+}
+
+// Stats 表示一组交易的汇总统计
+type Stats struct {
+	Symbol       string  `json:"symbol"`
+	Trades       int     `json:"trades"`
+	Wins         int     `json:"wins"`
+	WinRate      float64 `json:"win_rate"`      // 百分比
+	ProfitFactor float64 `json:"profit_factor"` // 盈利总和与亏损总和之比，没有亏损时为0
+	TotalReturn  float64 `json:"total_return"`  // 复利累计收益百分比
+	MaxDrawdown  float64 `json:"max_drawdown"`  // 按平仓计算的权益最大回撤百分比
+}
+
+// Report 表示一次回测的结果
+type Report struct {
+	Strategy string            `json:"strategy"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Symbols  []Stats           `json:"symbols"`
+	Total    Stats             `json:"total"` // 各股票等权组合的汇总
+	Trades   []Trade           `json:"trades"`
+	Errors   map[string]string `json:"errors,omitempty"` // 获取数据或回测失败的股票
+}
+
+// LoadStrategyFile 读取YAML格式的策略文件，格式与配置文件 strategies 下的单个策略相同，
+// 没有 name 时使用文件名（不含扩展名）作为策略名称
+func LoadStrategyFile(path string) (indicators.Strategy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return indicators.Strategy{}, err
+	}
+
+	var strategy indicators.Strategy
+	if err := yaml.Unmarshal(data, &strategy); err != nil {
+		return indicators.Strategy{}, fmt.Errorf("failed to parse strategy file %s: %v", path, err)
+	}
+	if strategy.Name == "" {
+		strategy.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for i, ind := range strategy.Indicators {
+		if ind.Type == "" {
+			strategy.Indicators[i].Type = ind.Name
+		}
+	}
+	if len(strategy.Indicators) == 0 {
+		return indicators.Strategy{}, fmt.Errorf("strategy file %s has no indicators", path)
+	}
+	return strategy, nil
+}
+
+// RunStrategyReport 在symbols（如指数成分股）的 [from, to] 区间内回测策略：每根K线收盘时评估信号，
+// 买入得分达到 MinScore 时在下一根K线开盘买入，卖出得分达到 MinScore、触及止损/止盈或超过最长持有K线数时平仓。
+// 不评估策略的市场过滤条件。单只股票失败时记录在 Report.Errors 中，所有股票都失败时返回错误
+func RunStrategyReport(ctx context.Context, dataManager *datasource.Manager, strategy indicators.Strategy, symbols []string, from, to time.Time, opts Options) (*Report, error) {
+	opts = opts.withDefaults()
+	bars, errs, err := loadBars(ctx, dataManager, symbols, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	return runReport(indicators.NewScanner(opts.Registry, dataManager), strategy, symbols, bars, errs, from, to, opts)
+}
+
+// loadBars 获取所有股票在回测区间和预热期内的K线，失败的股票记录在返回的错误表中（内部函数）
+func loadBars(ctx context.Context, dataManager *datasource.Manager, symbols []string, from, to time.Time, opts Options) (map[string][]datasource.StockData, map[string]string, error) {
+	bars := make(map[string][]datasource.StockData, len(symbols))
+	errs := make(map[string]string)
+	for _, symbol := range symbols {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		data, err := dataManager.GetStockData(ctx, symbol, opts.Timeframe, from.AddDate(0, 0, -opts.WarmupDays), to)
+		if err != nil {
+			errs[symbol] = err.Error()
+			continue
+		}
+		bars[symbol] = data
+	}
+	return bars, errs, nil
+}
+
+// runReport 在已获取的K线上回测 [from, to] 区间（内部函数）
+func runReport(scanner *indicators.Scanner, strategy indicators.Strategy, symbols []string, bars map[string][]datasource.StockData, loadErrs map[string]string, from, to time.Time, opts Options) (*Report, error) {
+	report := &Report{Strategy: strategy.Name, From: from, To: to, Errors: make(map[string]string)}
+	for symbol, msg := range loadErrs {
+		report.Errors[symbol] = msg
+	}
+	for _, symbol := range symbols {
+		data, ok := bars[symbol]
+		if !ok {
+			continue
+		}
+		// 只使用区间结束之前的K线
+		end := sort.Search(len(data), func(i int) bool { return data[i].Timestamp.After(to) })
+		trades, err := simulate(scanner, strategy, symbol, data[:end], from, opts)
+		if err != nil {
+			report.Errors[symbol] = err.Error()
+			continue
+		}
+		report.Trades = append(report.Trades, trades...)
+		report.Symbols = append(report.Symbols, symbolStats(symbol, trades))
+	}
+
+	if len(report.Symbols) == 0 && len(symbols) > 0 {
+		return report, errors.New("backtest failed for all symbols")
+	}
+	report.Total = portfolioStats(report.Symbols, report.Trades)
+	return report, nil
+}
+
+// simulate 逐根K线回放一只股票，返回完成的交易（内部函数）
+func simulate(scanner *indicators.Scanner, strategy indicators.Strategy, symbol string, bars []datasource.StockData, from time.Time, opts Options) ([]Trade, error) {
+	start := sort.Search(len(bars), func(i int) bool { return !bars[i].Timestamp.Before(from) })
+	if start >= len(bars) {
+		return nil, fmt.Errorf("no bars in backtest period")
+	}
+
+	// 先在全部K线上评估一次，尽早发现指标配置错误；之后K线不足导致的错误视为预热期
+	if _, err := scanner.EvaluateStrategy(symbol, strategy, bars); err != nil {
+		return nil, err
+	}
+
+	var trades []Trade
+	var open *Trade
+	pendingEntry, pendingExit := false, false
+	commission := opts.CommissionPercent / 100
+
+	closeTrade := func(bar datasource.StockData, price float64, reason string) {
+		open.ExitTime = bar.Timestamp
+		open.ExitPrice = price
+		open.Reason = reason
+		open.Return = (price*(1-commission)/(open.EntryPrice*(1+commission)) - 1) * 100
+		trades = append(trades, *open)
+		open = nil
+	}
+
+	for i := start; i < len(bars); i++ {
+		bar := bars[i]
+
+		// 上一根K线收盘时的信号在本根开盘执行
+		if pendingExit && open != nil {
+			closeTrade(bar, bar.Open, ExitSignal)
+		} else if pendingEntry && open == nil {
+			open = &Trade{Symbol: symbol, EntryTime: bar.Timestamp, EntryPrice: bar.Open}
+		}
+		pendingEntry, pendingExit = false, false
+
+		// 止损优先于止盈，跳空越过时按开盘价成交
+		if open != nil {
+			open.Bars++
+			stop := open.EntryPrice * (1 - opts.StopLossPercent/100)
+			target := open.EntryPrice * (1 + opts.TakeProfitPercent/100)
+			switch {
+			case opts.StopLossPercent > 0 && bar.Low <= stop:
+				closeTrade(bar, math.Min(bar.Open, stop), ExitStopLoss)
+			case opts.TakeProfitPercent > 0 && bar.High >= target:
+				closeTrade(bar, math.Max(bar.Open, target), ExitTakeProfit)
+			case opts.MaxHoldBars > 0 && open.Bars >= opts.MaxHoldBars:
+				closeTrade(bar, bar.Close, ExitMaxHold)
+			}
+		}
+
+		if i == len(bars)-1 {
+			break
+		}
+		results, err := scanner.EvaluateStrategy(symbol, strategy, bars[:i+1])
+		if err != nil {
+			continue
+		}
+		var buyScore, sellScore float64
+		for _, result := range results {
+			if result.IsBuySignal {
+				buyScore += result.Score
+			}
+			if result.IsSellSignal {
+				sellScore += result.Score
+			}
+		}
+		if open == nil {
+			pendingEntry = buyScore >= opts.MinScore && buyScore > sellScore
+		} else {
+			pendingExit = sellScore >= opts.MinScore
+		}
+	}
+
+	if open != nil {
+		last := bars[len(bars)-1]
+		closeTrade(last, last.Close, ExitEnd)
+	}
+	return trades, nil
+}
+
+// symbolStats 计算一只股票的交易统计，权益按交易收益复利计算（内部函数）
+func symbolStats(symbol string, trades []Trade) Stats {
+	stats := Stats{Symbol: symbol, Trades: len(trades)}
+	var profits, losses float64
+	equity, peak := 1.0, 1.0
+	for _, trade := range trades {
+		if trade.Return > 0 {
+			stats.Wins++
+			profits += trade.Return
+		} else {
+			losses -= trade.Return
+		}
+		equity *= 1 + trade.Return/100
+		peak = math.Max(peak, equity)
+		stats.MaxDrawdown = math.Max(stats.MaxDrawdown, (1-equity/peak)*100)
+	}
+	if stats.Trades > 0 {
+		stats.WinRate = float64(stats.Wins) / float64(stats.Trades) * 100
+	}
+	if losses > 0 {
+		stats.ProfitFactor = profits / losses
+	}
+	stats.TotalReturn = (equity - 1) * 100
+	return stats
+}
+
+// portfolioStats 计算各股票等权组合的汇总：收益为各股票累计收益的均值，回撤按所有交易的平仓时间顺序计算（内部函数）
+func portfolioStats(symbols []Stats, trades []Trade) Stats {
+	total := symbolStats("TOTAL", trades)
+	if len(symbols) == 0 {
+		return total
+	}
+
+	total.TotalReturn = 0
+	for _, s := range symbols {
+		total.TotalReturn += s.TotalReturn / float64(len(symbols))
+	}
+
+	sorted := append([]Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ExitTime.Before(sorted[j].ExitTime) })
+	equities := make(map[string]float64, len(symbols))
+	for _, s := range symbols {
+		equities[s.Symbol] = 1
+	}
+	portfolio, peak := 1.0, 1.0
+	total.MaxDrawdown = 0
+	for _, trade := range sorted {
+		before := equities[trade.Symbol]
+		equities[trade.Symbol] = before * (1 + trade.Return/100)
+		portfolio += (equities[trade.Symbol] - before) / float64(len(symbols))
+		peak = math.Max(peak, portfolio)
+		total.MaxDrawdown = math.Max(total.MaxDrawdown, (1-portfolio/peak)*100)
+	}
+	return total
+}
@@ -0,0 +1,94 @@
+package backtest
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+// closeBars 生成开盘价等于收盘价的日线（内部函数）
+func closeBars(symbol string, start time.Time, closes ...float64) []datasource.StockData {
+	bars := make([]datasource.StockData, len(closes))
+	for i, c := range closes {
+		bars[i] = datasource.StockData{Symbol: symbol, Timestamp: start.AddDate(0, 0, i), Open: c, High: c + 0.5, Low: c - 0.5, Close: c, Volume: 1000}
+	}
+	return bars
+}
+
+func TestRunStrategyReport(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := testutil.NewMockDataSource("")
+	// 动量上穿0后下一根开盘买入，下穿0后下一根开盘卖出：100买入99卖出，99买入104卖出，105买入持有到结束
+	source.SetBars("AAPL", "day", closeBars("AAPL", start, 100, 99, 98, 99, 100, 101, 100, 99, 98, 97, 98, 99, 102, 104, 103, 104, 105))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "momentum.yaml")
+	content := "indicators:\n  - name: Momentum\n    parameters: {period: 1}\n    buy_condition: cross_above\n    sell_condition: cross_below\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	strategy, err := LoadStrategyFile(path)
+	if err != nil || strategy.Name != "momentum" || strategy.Indicators[0].Type != indicators.IndicatorTypeMomentum {
+		t.Fatalf("读取策略文件失败: %+v %v", strategy, err)
+	}
+
+	report, err := RunStrategyReport(context.Background(), testutil.NewManager(source), strategy, []string{"AAPL", "MSFT"},
+		start, start.AddDate(0, 0, 30), Options{WarmupDays: 1})
+	if err != nil {
+		t.Fatalf("回测失败: %v", err)
+	}
+	if _, ok := report.Errors["MSFT"]; !ok {
+		t.Error("没有数据的股票应记录在错误中")
+	}
+	if len(report.Trades) != 3 {
+		t.Fatalf("交易 = %+v", report.Trades)
+	}
+	first, second, last := report.Trades[0], report.Trades[1], report.Trades[2]
+	if first.EntryPrice != 100 || first.ExitPrice != 99 || first.Reason != ExitSignal {
+		t.Errorf("第一笔交易 = %+v", first)
+	}
+	if second.EntryPrice != 99 || second.ExitPrice != 104 {
+		t.Errorf("第二笔交易 = %+v", second)
+	}
+	if last.Reason != ExitEnd || last.ExitPrice != 105 {
+		t.Errorf("回测结束时应按最后收盘价平仓: %+v", last)
+	}
+
+	stats := report.Symbols[0]
+	if stats.Trades != 3 || stats.Wins != 1 || math.Abs(stats.ProfitFactor-5/0.99) > 1e-6 || math.Abs(stats.MaxDrawdown-1) > 1e-9 {
+		t.Errorf("统计 = %+v", stats)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteTable(&buf); err != nil || !strings.Contains(buf.String(), "TOTAL") || !strings.Contains(buf.String(), "MSFT 回测失败") {
+		t.Errorf("汇总表 = %s", buf.String())
+	}
+}
+
+func TestStopLoss(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := testutil.NewMockDataSource("")
+	source.SetBars("AAPL", "day", closeBars("AAPL", start, 100, 99, 100, 100, 95, 96))
+	strategy := indicators.Strategy{Name: "momentum", Indicators: []indicators.IndicatorConfig{
+		{Type: indicators.IndicatorTypeMomentum, Parameters: indicators.IndicatorParams{"period": 1}, BuyCondition: indicators.ConditionCrossAbove},
+	}}
+
+	report, err := RunStrategyReport(context.Background(), testutil.NewManager(source), strategy, []string{"AAPL"},
+		start, start.AddDate(0, 0, 10), Options{StopLossPercent: 2})
+	if err != nil {
+		t.Fatalf("回测失败: %v", err)
+	}
+	// 第4根开盘100买入，第5根跳空低开95，按开盘价止损
+	if len(report.Trades) != 1 || report.Trades[0].Reason != ExitStopLoss || report.Trades[0].ExitPrice != 95 {
+		t.Errorf("应按跳空开盘价止损: %+v", report.Trades)
+	}
+}
@@ -0,0 +1,377 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+)
+
+// ParamRange 表示一个待优化参数的取值范围
+type ParamRange struct {
+	Indicator string  // 指标名称，对应策略中 IndicatorConfig.Name
+	Param     string  // 参数名，buy_threshold、sell_threshold 和 weight 设置指标配置的同名字段，其他设置指标参数
+	Min       float64 // 最小值
+	Max       float64 // 最大值
+	Step      float64 // 取值步长，为0时为连续值；周期类整数参数应设为1
+}
+
+// OptimizeOptions 表示遗传算法参数优化的参数，零值字段使用默认值
+type OptimizeOptions struct {
+	Backtest      Options             // 每次评估使用的回测参数
+	Population    int                 // 种群大小，默认20
+	Generations   int                 // 最大代数，默认30
+	MutationRate  float64             // 每个参数的变异概率，默认0.2
+	Elite         int                 // 直接保留到下一代的最优个体数，默认2
+	Patience      int                 // 连续多少代最优适应度没有提升时提前停止，默认5
+	Folds         int                 // 交叉验证折数，把回测区间按时间等分，适应度为各折得分的平均值，默认3
+	Objective     func(Stats) float64 // 单折得分，默认为组合的累计收益百分比
+	Seed          int64               // 随机数种子，相同种子结果可复现
+	MinFoldTrades int                 // 每折至少需要的交易次数，不足时该折得分为0，避免过拟合到极少的交易
+}
+
+// withDefaults 返回填充默认值后的参数（内部方法）
+func (o OptimizeOptions) withDefaults() OptimizeOptions {
+	o.Backtest = o.Backtest.withDefaults()
+	if o.Population <= 1 {
+		o.Population = 20
+	}
+	if o.Generations <= 0 {
+		o.Generations = 30
+	}
+	if o.MutationRate <= 0 {
+		o.MutationRate = 0.2
+	}
+	if o.Elite <= 0 {
+		o.Elite = 2
+	}
+	if o.Elite >= o.Population {
+		o.Elite = o.Population - 1
+	}
+	if o.Patience <= 0 {
+		o.Patience = 5
+	}
+	if o.Folds <= 0 {
+		o.Folds = 3
+	}
+	if o.Objective == nil {
+		o.Objective = func(s Stats) float64 { return s.TotalReturn }
+	}
+	return o
+}
+
+// Candidate 表示一组参数取值及其适应度
+type Candidate struct {
+	Params     map[string]float64 `json:"params"`      // 键为 "指标名称.参数名"
+	Fitness    float64            `json:"fitness"`     // 各折得分的平均值，任何一折回测失败时为负无穷
+	FoldScores []float64          `json:"fold_scores"` // 各折得分，按时间顺序
+}
+
+// OptimizeResult 表示参数优化的结果
+type OptimizeResult struct {
+	Best        Candidate           `json:"best"`
+	Strategy    indicators.Strategy `json:"strategy"`    // 应用最优参数后的策略
+	Generations int                 `json:"generations"` // 实际运行的代数，提前停止时小于最大代数
+	Evaluations int                 `json:"evaluations"` // 实际回测的参数组合数，重复的组合只回测一次
+	History     []float64           `json:"history"`     // 每代结束时的最优适应度
+}
+
+// Optimize 用遗传算法在ranges内搜索使策略适应度最大的参数：锦标赛选择、均匀交叉、按概率在当前值附近扰动的变异，
+// 并保留精英个体。适应度为回测区间按时间等分后各折得分的平均值，参数组合数随参数个数指数增长时可以代替穷举网格。
+// K线只在开始时获取一次，所有评估共用
+func Optimize(ctx context.Context, dataManager *datasource.Manager, strategy indicators.Strategy, symbols []string, from, to time.Time, ranges []ParamRange, opts OptimizeOptions) (*OptimizeResult, error) {
+	if len(ranges) == 0 {
+		return nil, errors.New("no parameter ranges to optimize")
+	}
+	for _, r := range ranges {
+		if err := validateRange(strategy, r); err != nil {
+			return nil, err
+		}
+	}
+	if !to.After(from) {
+		return nil, errors.New("optimization period is empty")
+	}
+	opts = opts.withDefaults()
+
+	bars, _, err := loadBars(ctx, dataManager, symbols, from, to, opts.Backtest)
+	if err != nil {
+		return nil, err
+	}
+	if len(bars) == 0 {
+		return nil, errors.New("no data for any symbol")
+	}
+
+	o := &optimizer{
+		ranges:   ranges,
+		strategy: strategy,
+		symbols:  symbols,
+		bars:     bars,
+		folds:    foldPeriods(from, to, opts.Folds),
+		opts:     opts,
+		scanner:  indicators.NewScanner(opts.Backtest.Registry, dataManager),
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		cache:    make(map[string]Candidate),
+	}
+	return o.run(ctx)
+}
+
+// optimizer 保存一次参数优化的状态（内部类型）
+type optimizer struct {
+	ranges   []ParamRange
+	strategy indicators.Strategy
+	symbols  []string
+	bars     map[string][]datasource.StockData
+	folds    [][2]time.Time
+	opts     OptimizeOptions
+	scanner  *indicators.Scanner
+	rng      *rand.Rand
+	cache    map[string]Candidate // 已评估的参数组合，键为参数值拼接
+}
+
+// run 逐代演化种群，直到达到最大代数或连续 Patience 代没有提升（内部方法）
+func (o *optimizer) run(ctx context.Context) (*OptimizeResult, error) {
+	population := make([][]float64, o.opts.Population)
+	for i := range population {
+		population[i] = o.random()
+	}
+
+	result := &OptimizeResult{Best: Candidate{Fitness: math.Inf(-1)}}
+	stale := 0
+	for gen := 0; gen < o.opts.Generations; gen++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		scored := make([]Candidate, len(population))
+		for i, genes := range population {
+			scored[i] = o.evaluate(genes)
+		}
+		// 按适应度降序，同分时保持原顺序以便复现
+		order := make([]int, len(scored))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return scored[order[a]].Fitness > scored[order[b]].Fitness })
+
+		result.Generations = gen + 1
+		if best := scored[order[0]]; best.Fitness > result.Best.Fitness+1e-9 {
+			result.Best = best
+			stale = 0
+		} else {
+			stale++
+		}
+		result.History = append(result.History, result.Best.Fitness)
+		if stale >= o.opts.Patience {
+			break
+		}
+
+		next := make([][]float64, 0, len(population))
+		for _, i := range order[:o.opts.Elite] {
+			next = append(next, population[i])
+		}
+		for len(next) < len(population) {
+			a := population[o.tournament(scored)]
+			b := population[o.tournament(scored)]
+			next = append(next, o.mutate(o.crossover(a, b)))
+		}
+		population = next
+	}
+
+	if math.IsInf(result.Best.Fitness, -1) {
+		return nil, errors.New("backtest failed for all parameter combinations")
+	}
+	result.Evaluations = len(o.cache)
+	strategy, err := applyParams(o.strategy, o.ranges, o.genes(result.Best))
+	if err != nil {
+		return nil, err
+	}
+	result.Strategy = strategy
+	return result, nil
+}
+
+// evaluate 回测一组参数在各折上的得分，结果按参数值缓存（内部方法）
+func (o *optimizer) evaluate(genes []float64) Candidate {
+	key := geneKey(genes)
+	if c, ok := o.cache[key]; ok {
+		return c
+	}
+
+	c := Candidate{Params: make(map[string]float64, len(genes)), Fitness: math.Inf(-1)}
+	for i, r := range o.ranges {
+		c.Params[r.Indicator+"."+r.Param] = genes[i]
+	}
+	strategy, err := applyParams(o.strategy, o.ranges, genes)
+	if err == nil {
+		sum, valid := 0.0, 0
+		for _, fold := range o.folds {
+			report, err := runReport(o.scanner, strategy, o.symbols, o.bars, nil, fold[0], fold[1], o.opts.Backtest)
+			score := math.Inf(-1)
+			if err == nil {
+				score = 0
+				if report.Total.Trades >= o.opts.MinFoldTrades {
+					score = o.opts.Objective(report.Total)
+				}
+				sum += score
+				valid++
+			}
+			c.FoldScores = append(c.FoldScores, score)
+		}
+		// 任何一折失败（如参数组合无效）都视为不可用
+		if valid == len(o.folds) {
+			c.Fitness = sum / float64(valid)
+		}
+	}
+	o.cache[key] = c
+	return c
+}
+
+// genes 按 ranges 的顺序取出候选参数值（内部方法）
+func (o *optimizer) genes(c Candidate) []float64 {
+	genes := make([]float64, len(o.ranges))
+	for i, r := range o.ranges {
+		genes[i] = c.Params[r.Indicator+"."+r.Param]
+	}
+	return genes
+}
+
+// random 在各参数范围内随机取值（内部方法）
+func (o *optimizer) random() []float64 {
+	genes := make([]float64, len(o.ranges))
+	for i, r := range o.ranges {
+		genes[i] = r.snap(r.Min + o.rng.Float64()*(r.Max-r.Min))
+	}
+	return genes
+}
+
+// tournament 随机抽取3个个体，返回适应度最高者的下标（内部方法）
+func (o *optimizer) tournament(scored []Candidate) int {
+	best := o.rng.Intn(len(scored))
+	for k := 1; k < 3; k++ {
+		if i := o.rng.Intn(len(scored)); scored[i].Fitness > scored[best].Fitness {
+			best = i
+		}
+	}
+	return best
+}
+
+// crossover 均匀交叉，每个参数等概率取自任一父代（内部方法）
+func (o *optimizer) crossover(a, b []float64) []float64 {
+	child := make([]float64, len(a))
+	for i := range child {
+		if o.rng.Intn(2) == 0 {
+			child[i] = a[i]
+		} else {
+			child[i] = b[i]
+		}
+	}
+	return child
+}
+
+// mutate 每个参数按变异概率在当前值附近（范围的20%内）重新取值（内部方法）
+func (o *optimizer) mutate(genes []float64) []float64 {
+	for i, r := range o.ranges {
+		if o.rng.Float64() >= o.opts.MutationRate {
+			continue
+		}
+		delta := (o.rng.Float64()*2 - 1) * 0.2 * (r.Max - r.Min)
+		if r.Step > 0 && math.Abs(delta) < r.Step {
+			delta = math.Copysign(r.Step, delta)
+		}
+		genes[i] = r.snap(genes[i] + delta)
+	}
+	return genes
+}
+
+// snap 把取值限制在范围内并对齐到步长（内部方法）
+func (r ParamRange) snap(v float64) float64 {
+	if r.Step > 0 {
+		v = r.Min + math.Round((v-r.Min)/r.Step)*r.Step
+	}
+	return math.Max(r.Min, math.Min(r.Max, v))
+}
+
+// validateRange 检查参数范围和对应的指标（内部函数）
+func validateRange(strategy indicators.Strategy, r ParamRange) error {
+	if r.Param == "" {
+		return fmt.Errorf("parameter name is required for indicator %s", r.Indicator)
+	}
+	if r.Max < r.Min || r.Step < 0 {
+		return fmt.Errorf("invalid range for %s.%s", r.Indicator, r.Param)
+	}
+	if indicatorIndex(strategy, r.Indicator) < 0 {
+		return fmt.Errorf("indicator %s not found in strategy %s", r.Indicator, strategy.Name)
+	}
+	return nil
+}
+
+// indicatorIndex 返回策略中名称为name的指标下标，不存在时返回-1（内部函数）
+func indicatorIndex(strategy indicators.Strategy, name string) int {
+	for i, ind := range strategy.Indicators {
+		if ind.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyParams 返回应用参数值后的策略副本，不修改原策略（内部函数）
+func applyParams(strategy indicators.Strategy, ranges []ParamRange, genes []float64) (indicators.Strategy, error) {
+	configs := make([]indicators.IndicatorConfig, len(strategy.Indicators))
+	for i, ind := range strategy.Indicators {
+		params := make(indicators.IndicatorParams, len(ind.Parameters))
+		for k, v := range ind.Parameters {
+			params[k] = v
+		}
+		ind.Parameters = params
+		configs[i] = ind
+	}
+	strategy.Indicators = configs
+
+	for i, r := range ranges {
+		idx := indicatorIndex(strategy, r.Indicator)
+		if idx < 0 {
+			return strategy, fmt.Errorf("indicator %s not found in strategy %s", r.Indicator, strategy.Name)
+		}
+		ind := &strategy.Indicators[idx]
+		switch r.Param {
+		case "buy_threshold":
+			ind.BuyThreshold = genes[i]
+		case "sell_threshold":
+			ind.SellThreshold = genes[i]
+		case "weight":
+			ind.Weight = genes[i]
+		default:
+			ind.Parameters[r.Param] = genes[i]
+		}
+	}
+	return strategy, nil
+}
+
+// foldPeriods 把 [from, to] 按时间等分为n折（内部函数）
+func foldPeriods(from, to time.Time, n int) [][2]time.Time {
+	step := to.Sub(from) / time.Duration(n)
+	folds := make([][2]time.Time, n)
+	for i := range folds {
+		folds[i][0] = from.Add(time.Duration(i) * step)
+		folds[i][1] = from.Add(time.Duration(i+1) * step)
+	}
+	folds[n-1][1] = to
+	return folds
+}
+
+// geneKey 把参数值拼接为缓存键（内部函数）
+func geneKey(genes []float64) string {
+	parts := make([]string, len(genes))
+	for i, g := range genes {
+		parts[i] = strconv.FormatFloat(g, 'g', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
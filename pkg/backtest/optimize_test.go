@@ -0,0 +1,90 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+func TestOptimize(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var closes []float64
+	for i := 0; i < 120; i++ {
+		closes = append(closes, 100+10*math.Sin(float64(i)/6)+float64(i)/10)
+	}
+	source := testutil.NewMockDataSource("")
+	source.SetBars("AAPL", "day", closeBars("AAPL", start, closes...))
+	dm := testutil.NewManager(source)
+
+	strategy := indicators.Strategy{Name: "momentum", Indicators: []indicators.IndicatorConfig{
+		{Name: "mom", Type: indicators.IndicatorTypeMomentum, Parameters: indicators.IndicatorParams{"period": 1},
+			BuyCondition: indicators.ConditionCrossAbove, SellCondition: indicators.ConditionCrossBelow},
+	}}
+	ranges := []ParamRange{{Indicator: "mom", Param: "period", Min: 1, Max: 12, Step: 1}}
+	from, to := start.AddDate(0, 0, 20), start.AddDate(0, 0, 119)
+	opts := OptimizeOptions{Backtest: Options{WarmupDays: 20}, Population: 8, Generations: 10, Seed: 1}
+
+	result, err := Optimize(context.Background(), dm, strategy, []string{"AAPL"}, from, to, ranges, opts)
+	if err != nil {
+		t.Fatalf("优化失败: %v", err)
+	}
+	if len(result.Best.FoldScores) != 3 || len(result.History) != result.Generations {
+		t.Errorf("结果 = %+v", result)
+	}
+	period := result.Best.Params["mom.period"]
+	if period != math.Round(period) || period < 1 || period > 12 {
+		t.Errorf("参数应对齐到步长并在范围内: %v", period)
+	}
+	if got := result.Strategy.Indicators[0].Parameters.GetInt("period", 0); got != int(period) {
+		t.Errorf("最优策略的周期 = %d, 期望 %v", got, period)
+	}
+	if strategy.Indicators[0].Parameters["period"] != 1 {
+		t.Error("不应修改原策略")
+	}
+
+	// 与逐个评估全部取值的结果比较
+	o := &optimizer{ranges: ranges, strategy: strategy, symbols: []string{"AAPL"}, folds: foldPeriods(from, to, 3),
+		opts: opts.withDefaults(), scanner: indicators.NewScanner(indicators.NewIndicatorRegistry(), dm), cache: make(map[string]Candidate)}
+	o.bars, _, _ = loadBars(context.Background(), dm, []string{"AAPL"}, from, to, o.opts.Backtest)
+	best := math.Inf(-1)
+	for p := 1.0; p <= 12; p++ {
+		best = math.Max(best, o.evaluate([]float64{p}).Fitness)
+	}
+	if math.Abs(result.Best.Fitness-best) > 1e-9 {
+		t.Errorf("最优适应度 = %v, 穷举最优 = %v", result.Best.Fitness, best)
+	}
+
+	again, _ := Optimize(context.Background(), dm, strategy, []string{"AAPL"}, from, to, ranges, opts)
+	if again.Best.Fitness != result.Best.Fitness || again.Generations != result.Generations {
+		t.Error("相同种子的结果应可复现")
+	}
+}
+
+func TestOptimizeEarlyStop(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := testutil.NewMockDataSource("")
+	source.SetBars("AAPL", "day", closeBars("AAPL", start, 100, 99, 98, 99, 100, 101, 100, 99, 98, 97, 98, 99, 102, 104))
+	strategy := indicators.Strategy{Indicators: []indicators.IndicatorConfig{
+		{Name: "mom", Type: indicators.IndicatorTypeMomentum, BuyCondition: indicators.ConditionCrossAbove},
+	}}
+
+	// 只有一个取值时最优适应度不会提升，第一代之后再经过 Patience 代停止
+	ranges := []ParamRange{{Indicator: "mom", Param: "period", Min: 1, Max: 1, Step: 1}}
+	result, err := Optimize(context.Background(), testutil.NewManager(source), strategy, []string{"AAPL"},
+		start, start.AddDate(0, 0, 14), ranges, OptimizeOptions{Backtest: Options{WarmupDays: 1}, Folds: 1, Patience: 3})
+	if err != nil {
+		t.Fatalf("优化失败: %v", err)
+	}
+	if result.Generations != 4 || result.Evaluations != 1 {
+		t.Errorf("应提前停止: 代数 = %d, 评估次数 = %d", result.Generations, result.Evaluations)
+	}
+
+	if _, err := Optimize(context.Background(), testutil.NewManager(source), strategy, []string{"AAPL"},
+		start, start.AddDate(0, 0, 14), []ParamRange{{Indicator: "rsi", Param: "period", Min: 2, Max: 20}}, OptimizeOptions{}); err == nil {
+		t.Error("策略中不存在的指标应返回错误")
+	}
+}
@@ -0,0 +1,36 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// WriteTable 以对齐的文本表格写出每只股票和组合的交易次数、胜率、盈亏比、收益和最大回撤，以及失败的股票
+func (r *Report) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "策略 %s，%s 至 %s\n", r.Strategy, r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "股票\t交易\t胜率%\t盈亏比\t收益%\t最大回撤%\t")
+	for _, s := range append(append([]Stats(nil), r.Symbols...), r.Total) {
+		profitFactor := "-"
+		if s.ProfitFactor > 0 {
+			profitFactor = fmt.Sprintf("%.2f", s.ProfitFactor)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%s\t%.2f\t%.2f\t\n", s.Symbol, s.Trades, s.WinRate, profitFactor, s.TotalReturn, s.MaxDrawdown)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	symbols := make([]string, 0, len(r.Errors))
+	for symbol := range r.Errors {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		fmt.Fprintf(w, "%s 回测失败: %s\n", symbol, r.Errors[symbol])
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// reorderHold 是被延后的回报最长等待下一条回报的时间，超时后单独推送
+const reorderHold = time.Second
+
+// chaosBroker 包装券商并注入故障：下单、撤单、健康检查和可借券查询按概率延迟或返回 ErrInjected（内部类型）
+type chaosBroker struct {
+	trading.Broker
+	faults   BrokerFaults
+	injector *injector
+}
+
+// streamBroker 是推送成交回报的券商的包装，回报按概率乱序或重复推送（内部类型）
+type streamBroker struct {
+	*chaosBroker
+	updates chan trading.OrderUpdate
+}
+
+// NewBroker 包装券商，seed为0时使用当前时间作为随机数种子
+// 券商推送成交回报（trading.ExecutionStream）时，返回的券商同样推送回报，并按概率打乱顺序和重复推送
+func NewBroker(broker trading.Broker, faults BrokerFaults, seed int64) trading.Broker {
+	b := &chaosBroker{Broker: broker, faults: faults, injector: newInjector(seed)}
+	stream, ok := broker.(trading.ExecutionStream)
+	if !ok {
+		return b
+	}
+	s := &streamBroker{chaosBroker: b, updates: make(chan trading.OrderUpdate, 100)}
+	go s.forward(stream.OrderUpdates())
+	return s
+}
+
+// SubmitOrder 提交订单，按概率延迟或在提交前返回错误
+func (b *chaosBroker) SubmitOrder(ctx context.Context, order trading.Order) (*trading.Order, error) {
+	if err := b.injector.inject(ctx, b.faults.Faults, "submit order"); err != nil {
+		return nil, err
+	}
+	return b.Broker.SubmitOrder(ctx, order)
+}
+
+// CancelOrder 取消订单，按概率延迟或在撤单前返回错误
+func (b *chaosBroker) CancelOrder(ctx context.Context, order trading.Order) error {
+	if err := b.injector.inject(ctx, b.faults.Faults, "cancel order"); err != nil {
+		return err
+	}
+	return b.Broker.CancelOrder(ctx, order)
+}
+
+// HealthCheck 检查券商连接，按概率延迟或返回错误
+func (b *chaosBroker) HealthCheck(ctx context.Context) error {
+	if err := b.injector.inject(ctx, b.faults.Faults, "health check"); err != nil {
+		return err
+	}
+	return b.Broker.HealthCheck(ctx)
+}
+
+// ShortAvailability 查询可借券情况，券商不支持查询时返回错误，与没有包装时引擎的处理一致
+func (b *chaosBroker) ShortAvailability(ctx context.Context, symbol string) (trading.ShortAvailability, error) {
+	locator, ok := b.Broker.(trading.ShortLocator)
+	if !ok {
+		return trading.ShortAvailability{}, fmt.Errorf("broker does not provide borrow availability")
+	}
+	if err := b.injector.inject(ctx, b.faults.Faults, "short availability"); err != nil {
+		return trading.ShortAvailability{}, err
+	}
+	return locator.ShortAvailability(ctx, symbol)
+}
+
+// OrderUpdates 返回经过乱序和重复处理的回报，被包装券商关闭通道后关闭
+func (s *streamBroker) OrderUpdates() <-chan trading.OrderUpdate {
+	return s.updates
+}
+
+// forward 转发回报：按概率把回报延后到下一条回报之后推送，或推送两次（内部方法）
+func (s *streamBroker) forward(in <-chan trading.OrderUpdate) {
+	defer close(s.updates)
+
+	var held *trading.OrderUpdate
+	var release <-chan time.Time // 被延后的回报超时后单独推送
+	for {
+		select {
+		case update, ok := <-in:
+			if !ok {
+				if held != nil {
+					s.send(*held)
+				}
+				return
+			}
+			if held == nil && s.injector.chance(s.faults.ReorderRate) {
+				held, release = &update, time.After(reorderHold)
+				continue
+			}
+			s.send(update)
+			if held != nil {
+				s.send(*held)
+				held, release = nil, nil
+			}
+		case <-release:
+			s.updates <- *held
+			held, release = nil, nil
+		}
+	}
+}
+
+// send 推送一条回报，按概率重复推送（内部方法）
+func (s *streamBroker) send(update trading.OrderUpdate) {
+	s.updates <- update
+	if s.injector.chance(s.faults.DuplicateRate) {
+		s.updates <- update
+	}
+}
@@ -0,0 +1,114 @@
+// Package chaos 提供券商和数据源的故障注入包装，按配置随机返回错误、增加延迟峰值、
+// 打乱成交回报的顺序和重复推送回报，用于在投入真实资金前验证引擎的恢复能力
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjected 是故障注入返回的错误
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Faults 表示一类请求的故障注入概率，概率取值为0到1，为0时不注入
+type Faults struct {
+	ErrorRate     float64 `json:"error_rate" yaml:"error_rate"`         // 请求返回错误的概率
+	LatencyRate   float64 `json:"latency_rate" yaml:"latency_rate"`     // 请求前增加延迟峰值的概率
+	LatencyMillis int     `json:"latency_millis" yaml:"latency_millis"` // 延迟峰值的时长（毫秒）
+}
+
+// Validate 检查故障注入概率和延迟是否有效
+func (f Faults) Validate() error {
+	if f.ErrorRate < 0 || f.ErrorRate > 1 || f.LatencyRate < 0 || f.LatencyRate > 1 {
+		return fmt.Errorf("rates must be between 0 and 1")
+	}
+	if f.LatencyMillis < 0 {
+		return fmt.Errorf("latency_millis must not be negative")
+	}
+	if f.LatencyRate > 0 && f.LatencyMillis == 0 {
+		return fmt.Errorf("latency_millis is required when latency_rate is set")
+	}
+	return nil
+}
+
+// BrokerFaults 表示券商的故障注入设置，乱序和重复只作用于推送成交回报的券商（trading.ExecutionStream）
+type BrokerFaults struct {
+	Faults        `yaml:",inline"`
+	ReorderRate   float64 `json:"reorder_rate" yaml:"reorder_rate"`     // 回报被延后到下一条回报之后推送的概率
+	DuplicateRate float64 `json:"duplicate_rate" yaml:"duplicate_rate"` // 回报被重复推送的概率
+}
+
+// Validate 检查券商故障注入设置是否有效
+func (f BrokerFaults) Validate() error {
+	if err := f.Faults.Validate(); err != nil {
+		return err
+	}
+	if f.ReorderRate < 0 || f.ReorderRate > 1 || f.DuplicateRate < 0 || f.DuplicateRate > 1 {
+		return fmt.Errorf("rates must be between 0 and 1")
+	}
+	return nil
+}
+
+// Config 表示故障注入配置，启用后系统的券商和所有数据源都会被包装
+type Config struct {
+	Enabled    bool         `json:"enabled" yaml:"enabled"`
+	Seed       int64        `json:"seed" yaml:"seed"` // 随机数种子，为0时使用当前时间，设置后每次运行注入相同的故障序列
+	Broker     BrokerFaults `json:"broker" yaml:"broker"`
+	DataSource Faults       `json:"datasource" yaml:"datasource"`
+}
+
+// Validate 检查故障注入配置是否有效
+func (c Config) Validate() error {
+	if err := c.Broker.Validate(); err != nil {
+		return fmt.Errorf("broker: %w", err)
+	}
+	if err := c.DataSource.Validate(); err != nil {
+		return fmt.Errorf("datasource: %w", err)
+	}
+	return nil
+}
+
+// injector 按概率决定是否注入故障，可以并发使用（内部类型）
+type injector struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// newInjector 创建使用给定种子的注入器，种子为0时使用当前时间（内部函数）
+func newInjector(seed int64) *injector {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &injector{rng: rand.New(rand.NewSource(seed))}
+}
+
+// chance 以rate的概率返回true（内部方法）
+func (i *injector) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rng.Float64() < rate
+}
+
+// inject 按概率增加延迟峰值和返回错误，延迟期间上下文取消时返回上下文的错误（内部方法）
+func (i *injector) inject(ctx context.Context, faults Faults, op string) error {
+	if i.chance(faults.LatencyRate) {
+		timer := time.NewTimer(time.Duration(faults.LatencyMillis) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if i.chance(faults.ErrorRate) {
+		return fmt.Errorf("%s: %w", op, ErrInjected)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{Enabled: true, Broker: BrokerFaults{Faults: Faults{ErrorRate: 0.1, LatencyRate: 0.1, LatencyMillis: 100}, ReorderRate: 0.5}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("有效的配置不应报错: %v", err)
+	}
+	invalid := []Config{
+		{Broker: BrokerFaults{Faults: Faults{ErrorRate: 1.5}}},
+		{Broker: BrokerFaults{DuplicateRate: -0.1}},
+		{DataSource: Faults{LatencyRate: 0.5}},
+		{DataSource: Faults{LatencyMillis: -1}},
+	}
+	for _, cfg := range invalid {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("无效的配置应报错: %+v", cfg)
+		}
+	}
+}
+
+func TestBrokerFaults(t *testing.T) {
+	ctx := context.Background()
+	inner := testutil.NewMockBroker("")
+	order := trading.Order{ID: "o1", Symbol: "AAPL", Quantity: 10, Type: trading.OrderTypeMarket, Side: trading.OrderSideBuy}
+
+	failing := NewBroker(inner, BrokerFaults{Faults: Faults{ErrorRate: 1}}, 1)
+	if _, err := failing.SubmitOrder(ctx, order); !errors.Is(err, ErrInjected) {
+		t.Errorf("应返回注入的错误: %v", err)
+	}
+	if err := failing.CancelOrder(ctx, order); !errors.Is(err, ErrInjected) {
+		t.Errorf("撤单应返回注入的错误: %v", err)
+	}
+	if len(inner.Orders()) != 0 || len(inner.Canceled()) != 0 {
+		t.Error("注入错误时请求不应到达券商")
+	}
+	if failing.Name() != inner.Name() {
+		t.Errorf("名称应与被包装的券商一致: %s", failing.Name())
+	}
+	if _, err := failing.(trading.ShortLocator).ShortAvailability(ctx, "AAPL"); err == nil {
+		t.Error("被包装的券商不支持查询可借券时应返回错误")
+	}
+
+	// 延迟峰值期间上下文超时
+	slow := NewBroker(inner, BrokerFaults{Faults: Faults{LatencyRate: 1, LatencyMillis: 1000}}, 1)
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := slow.SubmitOrder(timeout, order); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("延迟期间应返回上下文超时: %v", err)
+	}
+
+	healthy := NewBroker(inner, BrokerFaults{}, 1)
+	if submitted, err := healthy.SubmitOrder(ctx, order); err != nil || submitted.Status != trading.OrderStatusFilled {
+		t.Errorf("没有故障时应正常下单: %+v %v", submitted, err)
+	}
+	if _, ok := healthy.(trading.ExecutionStream); !ok {
+		t.Error("被包装的券商推送回报时，包装后应同样推送")
+	}
+	if _, ok := NewBroker(trading.NewSimulatedBroker("", nil), BrokerFaults{}, 1).(trading.ExecutionStream); ok {
+		t.Error("被包装的券商不推送回报时，包装后也不应推送")
+	}
+}
+
+// nextUpdate 在超时前读取一条回报
+func nextUpdate(t *testing.T, updates <-chan trading.OrderUpdate) trading.OrderUpdate {
+	t.Helper()
+	select {
+	case update := <-updates:
+		return update
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待回报超时")
+	}
+	return trading.OrderUpdate{}
+}
+
+func TestBrokerStreamFaults(t *testing.T) {
+	update := func(filled int64) trading.OrderUpdate {
+		return trading.OrderUpdate{Order: trading.Order{ID: "o1", FilledQty: filled}}
+	}
+
+	// 第一条回报被延后到第二条之后
+	inner := testutil.NewMockBroker("")
+	stream := NewBroker(inner, BrokerFaults{ReorderRate: 1}, 1).(trading.ExecutionStream)
+	inner.Push(update(4))
+	inner.Push(update(10))
+	if first, second := nextUpdate(t, stream.OrderUpdates()), nextUpdate(t, stream.OrderUpdates()); first.Order.FilledQty != 10 || second.Order.FilledQty != 4 {
+		t.Errorf("回报应乱序推送: %d, %d", first.Order.FilledQty, second.Order.FilledQty)
+	}
+	// 没有下一条回报时，被延后的回报在被包装券商关闭后推送
+	inner.Push(update(12))
+	inner.Close()
+	if last := nextUpdate(t, stream.OrderUpdates()); last.Order.FilledQty != 12 {
+		t.Errorf("被延后的回报不应丢失: %d", last.Order.FilledQty)
+	}
+	if _, ok := <-stream.OrderUpdates(); ok {
+		t.Error("被包装券商关闭后通道应关闭")
+	}
+
+	inner = testutil.NewMockBroker("")
+	stream = NewBroker(inner, BrokerFaults{DuplicateRate: 1}, 1).(trading.ExecutionStream)
+	inner.Push(update(4))
+	if first, second := nextUpdate(t, stream.OrderUpdates()), nextUpdate(t, stream.OrderUpdates()); first.Order.FilledQty != 4 || second.Order.FilledQty != 4 {
+		t.Errorf("回报应重复推送: %d, %d", first.Order.FilledQty, second.Order.FilledQty)
+	}
+}
+
+func TestEngineRecoversFromStreamFaults(t *testing.T) {
+	ctx := context.Background()
+	inner := testutil.NewMockBroker("")
+	inner.Script(testutil.Accept())
+	broker := NewBroker(inner, BrokerFaults{ReorderRate: 1, DuplicateRate: 1}, 1)
+
+	engine := trading.NewBaseTradingEngine(testutil.NewManager(testutil.NewMockDataSource("")), trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.Enable()
+	order, err := engine.SubmitOrder(ctx, "AAPL", 10, 100, trading.OrderTypeLimit, trading.OrderSideBuy)
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+
+	// 部分成交和全部成交的回报乱序并重复到达
+	partial, filled := *order, *order
+	partial.Status, partial.FilledQty, partial.AvgFillPrice = trading.OrderStatusPartial, 4, 100
+	filled.Status, filled.FilledQty, filled.AvgFillPrice = trading.OrderStatusFilled, 10, 100
+	inner.Push(trading.OrderUpdate{Order: partial})
+	inner.Push(trading.OrderUpdate{Order: filled})
+	inner.Close()
+	engine.RunExecutionStream(ctx)
+
+	position, err := engine.GetPosition(ctx, "AAPL")
+	if err != nil || position.Quantity != 10 {
+		t.Errorf("乱序和重复的回报不应重复计入持仓: %+v %v", position, err)
+	}
+	if current, _ := engine.GetOrder(ctx, order.ID); current.Status != trading.OrderStatusFilled || current.FilledQty != 10 {
+		t.Errorf("订单应为全部成交: %+v", current)
+	}
+}
+
+func TestDataSourceFaults(t *testing.T) {
+	ctx := context.Background()
+	inner := testutil.NewMockDataSource("")
+	inner.SetQuote(datasource.Quote{Symbol: "AAPL", LastPrice: 100})
+
+	failing := NewDataSource(inner, Faults{ErrorRate: 1}, 1)
+	_, err := failing.GetRealTimeQuote(ctx, "AAPL")
+	var dsErr *datasource.DataSourceError
+	if !errors.As(err, &dsErr) || dsErr.Code != ErrorCodeInjected || dsErr.Source != "mock" {
+		t.Errorf("应返回注入的数据源错误: %v", err)
+	}
+	if inner.Calls("GetRealTimeQuote") != 0 {
+		t.Error("注入错误时请求不应到达数据源")
+	}
+
+	healthy := NewDataSource(inner, Faults{ErrorRate: 0.5}, 42)
+	failures := 0
+	for i := 0; i < 100; i++ {
+		if _, err := healthy.GetRealTimeQuote(ctx, "AAPL"); err != nil {
+			failures++
+		}
+	}
+	if failures < 30 || failures > 70 {
+		t.Errorf("错误比例应接近配置的概率: %d/100", failures)
+	}
+
+	// 相同的种子注入相同的故障序列
+	a, b := NewDataSource(inner, Faults{ErrorRate: 0.5}, 7), NewDataSource(inner, Faults{ErrorRate: 0.5}, 7)
+	for i := 0; i < 20; i++ {
+		_, errA := a.HealthCheck(ctx)
+		_, errB := b.HealthCheck(ctx)
+		if (errA == nil) != (errB == nil) {
+			t.Fatal("相同种子的故障序列应一致")
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// ErrorCodeInjected 是故障注入返回的数据源错误代码
+const ErrorCodeInjected = "INJECTED_FAULT"
+
+// DataSource 包装数据源并注入故障：每次请求按概率延迟或返回代码为 ErrorCodeInjected 的错误
+type DataSource struct {
+	datasource.DataSource
+	faults   Faults
+	injector *injector
+}
+
+// NewDataSource 包装数据源，seed为0时使用当前时间作为随机数种子
+// 包装后的数据源可以直接添加到 datasource.Manager，管理器的降级和报价路由照常处理注入的错误
+func NewDataSource(source datasource.DataSource, faults Faults, seed int64) *DataSource {
+	return &DataSource{DataSource: source, faults: faults, injector: newInjector(seed)}
+}
+
+// inject 按概率注入故障，返回数据源错误（内部方法）
+func (d *DataSource) inject(ctx context.Context, op string) error {
+	err := d.injector.inject(ctx, d.faults, op)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	return &datasource.DataSourceError{Source: d.Name(), Code: ErrorCodeInjected, Message: err.Error(), Time: time.Now()}
+}
+
+// HealthCheck 检查数据源的健康状态
+func (d *DataSource) HealthCheck(ctx context.Context) (bool, error) {
+	if err := d.inject(ctx, "health check"); err != nil {
+		return false, err
+	}
+	return d.DataSource.HealthCheck(ctx)
+}
+
+// GetStockData 获取指定股票的价格数据
+func (d *DataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]datasource.StockData, error) {
+	if err := d.inject(ctx, "get stock data"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetStockData(ctx, symbol, timeframe, from, to)
+}
+
+// GetMultipleStockData 批量获取多只股票的价格数据
+func (d *DataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]datasource.StockData, error) {
+	if err := d.inject(ctx, "get multiple stock data"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetMultipleStockData(ctx, symbols, timeframe, from, to)
+}
+
+// GetRealTimeQuote 获取实时报价
+func (d *DataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*datasource.Quote, error) {
+	if err := d.inject(ctx, "get realtime quote"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetRealTimeQuote(ctx, symbol)
+}
+
+// GetRealTimeQuotes 批量获取实时报价
+func (d *DataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*datasource.Quote, error) {
+	if err := d.inject(ctx, "get realtime quotes"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetRealTimeQuotes(ctx, symbols)
+}
+
+// GetHistoricalQuotes 获取NBBO历史报价
+func (d *DataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]datasource.Quote, error) {
+	if err := d.inject(ctx, "get historical quotes"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetHistoricalQuotes(ctx, symbol, from, to)
+}
+
+// GetTrades 获取逐笔成交
+func (d *DataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]datasource.TradePrint, error) {
+	if err := d.inject(ctx, "get trades"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetTrades(ctx, symbol, from, to)
+}
+
+// GetOptionChain 获取期权链
+func (d *DataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]datasource.OptionQuote, error) {
+	if err := d.inject(ctx, "get option chain"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetOptionChain(ctx, underlying, expiry)
+}
+
+// GetFundamentals 获取基本面数据
+func (d *DataSource) GetFundamentals(ctx context.Context, symbol string) (*datasource.Fundamentals, error) {
+	if err := d.inject(ctx, "get fundamentals"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetFundamentals(ctx, symbol)
+}
+
+// GetCorporateActions 获取公司行动
+func (d *DataSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*datasource.CorporateActions, error) {
+	if err := d.inject(ctx, "get corporate actions"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetCorporateActions(ctx, symbol, from, to)
+}
+
+// SubscribeQuotes 订阅实时报价推送，只对建立订阅的请求注入故障
+func (d *DataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan datasource.Quote, error) {
+	if err := d.inject(ctx, "subscribe quotes"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.SubscribeQuotes(ctx, symbols)
+}
+
+// GetAllStocks 获取所有可交易的股票列表
+func (d *DataSource) GetAllStocks(ctx context.Context) ([]datasource.Stock, error) {
+	if err := d.inject(ctx, "get all stocks"); err != nil {
+		return nil, err
+	}
+	return d.DataSource.GetAllStocks(ctx)
+}
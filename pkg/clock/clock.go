@@ -0,0 +1,85 @@
+// Package clock 提供可替换的时钟，交易引擎、监控列表和日志通过它获取当前时间，
+// 回测和回放可以使用模拟时间，单元测试可以精确控制时间戳
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 定义了获取当前时间的接口
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+}
+
+// Func 将函数适配为时钟，如回放数据源的模拟时钟
+type Func func() time.Time
+
+// Now 返回当前时间
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// Real 是使用系统时间的时钟
+var Real Clock = Func(time.Now)
+
+// Or 返回c，c为空时返回系统时钟
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+// Delayed 返回落后系统时间delay的时钟，用于延迟行情的模拟交易
+func Delayed(delay time.Duration) Clock {
+	return Func(func() time.Time {
+		return time.Now().Add(-delay)
+	})
+}
+
+// NewReplay 返回从start开始按speed倍速前进的时钟，用于历史行情回放，speed不是正数时按1倍速
+func NewReplay(start time.Time, speed float64) Clock {
+	if speed <= 0 {
+		speed = 1
+	}
+	began := time.Now()
+	return Func(func() time.Time {
+		elapsed := time.Duration(float64(time.Since(began)) * speed)
+		return start.Add(elapsed)
+	})
+}
+
+// Manual 是手动设置和推进的时钟，用于测试和逐步回放，可以并发使用
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManual 创建从给定时间开始的手动时钟
+func NewManual(start time.Time) *Manual {
+	return &Manual{now: start}
+}
+
+// Now 返回当前设置的时间
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Set 将时钟设置为给定时间
+func (m *Manual) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = t
+}
+
+// Advance 将时钟推进d并返回推进后的时间
+func (m *Manual) Advance(d time.Duration) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+	return m.now
+}
@@ -0,0 +1,44 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManual(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	c := NewManual(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("Now() = %v, 期望 %v", c.Now(), start)
+	}
+	if now := c.Advance(time.Minute); !now.Equal(start.Add(time.Minute)) || !c.Now().Equal(now) {
+		t.Errorf("推进后的时间不正确: %v", now)
+	}
+	c.Set(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("设置后的时间不正确: %v", c.Now())
+	}
+}
+
+func TestOr(t *testing.T) {
+	if d := time.Since(Or(nil).Now()); d < 0 || d > time.Second {
+		t.Errorf("未设置时钟时应使用系统时钟: %v", d)
+	}
+	fixed := Func(func() time.Time { return time.Unix(100, 0) })
+	if Or(fixed).Now().Unix() != 100 {
+		t.Error("应使用设置的时钟")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	c := NewReplay(start, 60)
+	time.Sleep(20 * time.Millisecond)
+	// 60倍速下20毫秒约为1.2秒模拟时间
+	if elapsed := c.Now().Sub(start); elapsed < time.Second || elapsed > time.Minute {
+		t.Errorf("回放时钟前进的时间不正确: %v", elapsed)
+	}
+	if d := time.Since(Delayed(time.Hour).Now()); d < time.Hour || d > time.Hour+time.Second {
+		t.Errorf("延迟时钟应落后系统时间: %v", d)
+	}
+}
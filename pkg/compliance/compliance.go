@@ -0,0 +1,286 @@
+// Package compliance 从引擎的预写日志生成订单记录簿（book of record）：
+// 规范化的订单和成交事件（新订单、撤单、改单、成交、拒绝），按交易日导出为CSV，
+// 时间精确到毫秒，字段参照FINRA CAT的订单事件，供券商自营商的监管报送使用
+package compliance
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// EventType 表示订单事件的类型
+type EventType string
+
+const (
+	EventNew       EventType = "NEW"       // 新订单
+	EventCancel    EventType = "CANCEL"    // 撤单
+	EventReplace   EventType = "REPLACE"   // 改单：订单的数量、限价或止损价发生变化
+	EventExecution EventType = "EXECUTION" // 成交，部分成交时每次成交一条
+	EventReject    EventType = "REJECT"    // 券商拒绝
+)
+
+// TimestampFormat 是导出文件中时间戳的格式，精确到毫秒并带时区偏移
+const TimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// Event 表示订单记录簿中的一条事件
+type Event struct {
+	Seq           uint64            `json:"seq"` // 预写日志中的序号
+	Type          EventType         `json:"type"`
+	Timestamp     time.Time         `json:"timestamp"`
+	OrderID       string            `json:"order_id"`
+	ClientOrderID string            `json:"client_order_id,omitempty"`
+	BrokerOrderID string            `json:"broker_order_id,omitempty"`
+	Symbol        string            `json:"symbol"`
+	Side          trading.OrderSide `json:"side"`
+	OrderType     trading.OrderType `json:"order_type"`
+	Quantity      int64             `json:"quantity"`
+	Price         float64           `json:"price,omitempty"`
+	StopPrice     float64           `json:"stop_price,omitempty"`
+	ExecQty       int64             `json:"exec_qty,omitempty"`   // 本次成交数量，只用于成交事件
+	ExecPrice     float64           `json:"exec_price,omitempty"` // 本次成交价格，只用于成交事件
+	LeavesQty     int64             `json:"leaves_qty"`           // 事件发生后的剩余未成交数量
+	Strategy      string            `json:"strategy,omitempty"`
+	Reason        string            `json:"reason,omitempty"` // 拒绝原因
+}
+
+// orderState 是生成事件时记录的订单最近状态（内部类型）
+type orderState struct {
+	quantity  int64
+	price     float64
+	stopPrice float64
+	filled    int64
+	broker    string
+	done      bool
+}
+
+// Builder 按写入顺序接收预写日志记录并生成订单事件
+// 同一订单的后续记录与最近状态比较：数量、限价或止损价变化时生成改单事件，成交数量增加时生成成交事件
+type Builder struct {
+	orders map[string]*orderState
+	events []Event
+}
+
+// NewBuilder 创建事件生成器
+func NewBuilder() *Builder {
+	return &Builder{orders: make(map[string]*orderState)}
+}
+
+// Add 处理一条预写日志记录，与订单无关的记录被忽略
+func (b *Builder) Add(record trading.WALRecord) {
+	if record.Order == nil {
+		return
+	}
+	order := *record.Order
+	state, known := b.orders[order.ID]
+
+	switch record.Type {
+	case trading.WALOrderSubmitted:
+		if known {
+			return
+		}
+		b.orders[order.ID] = &orderState{quantity: order.Quantity, price: order.Price, stopPrice: order.StopPrice, broker: order.BrokerOrderID}
+		b.emit(EventNew, record, order, b.orders[order.ID])
+		return
+	case trading.WALOrderAccepted, trading.WALOrderRejected, trading.WALOrderFilled, trading.WALOrderCanceled, trading.WALOrderUpdated:
+	default:
+		return
+	}
+
+	if !known {
+		// 新订单记录在日志轮转前，或券商直接推送的订单，以首次出现的记录作为新订单事件
+		state = &orderState{quantity: order.Quantity, price: order.Price, stopPrice: order.StopPrice}
+		b.orders[order.ID] = state
+		b.emit(EventNew, record, order, state)
+	}
+	if order.BrokerOrderID != "" {
+		state.broker = order.BrokerOrderID
+	}
+	if order.Quantity != state.quantity || order.Price != state.price || order.StopPrice != state.stopPrice {
+		state.quantity, state.price, state.stopPrice = order.Quantity, order.Price, order.StopPrice
+		b.emit(EventReplace, record, order, state)
+	}
+
+	if record.Type == trading.WALOrderUpdated && record.Fill != nil {
+		b.execute(record, order, state, record.Fill.FilledQty, record.Fill.AvgFillPrice, record.Fill.FilledAt)
+	} else if order.FilledQty > state.filled {
+		b.execute(record, order, state, order.FilledQty-state.filled, order.AvgFillPrice, order.FilledAt)
+	}
+
+	if state.done {
+		return
+	}
+	switch {
+	case record.Type == trading.WALOrderRejected || order.Status == trading.OrderStatusRejected:
+		state.done = true
+		event := b.emit(EventReject, record, order, state)
+		event.Reason = order.RejectReason
+	case record.Type == trading.WALOrderCanceled || order.Status == trading.OrderStatusCanceled:
+		state.done = true
+		b.emit(EventCancel, record, order, state)
+	}
+}
+
+// Events 返回按日志顺序生成的所有事件
+func (b *Builder) Events() []Event {
+	return b.events
+}
+
+// execute 生成成交事件，成交时间为空时使用记录的时间（内部方法）
+func (b *Builder) execute(record trading.WALRecord, order trading.Order, state *orderState, qty int64, price float64, at *time.Time) {
+	if qty <= 0 {
+		return
+	}
+	state.filled += qty
+	if at != nil && !at.IsZero() {
+		record.Timestamp = *at
+	}
+	event := b.emit(EventExecution, record, order, state)
+	event.ExecQty = qty
+	event.ExecPrice = price
+}
+
+// emit 追加一条事件并返回它，供调用方补充事件特有的字段（内部方法）
+func (b *Builder) emit(eventType EventType, record trading.WALRecord, order trading.Order, state *orderState) *Event {
+	leaves := state.quantity - state.filled
+	if state.done || leaves < 0 {
+		leaves = 0
+	}
+	b.events = append(b.events, Event{
+		Seq:           record.Seq,
+		Type:          eventType,
+		Timestamp:     record.Timestamp,
+		OrderID:       order.ID,
+		ClientOrderID: order.ClientOrderID,
+		BrokerOrderID: state.broker,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		OrderType:     order.Type,
+		Quantity:      state.quantity,
+		Price:         state.price,
+		StopPrice:     state.stopPrice,
+		LeavesQty:     leaves,
+		Strategy:      order.Strategy,
+	})
+	return &b.events[len(b.events)-1]
+}
+
+// ReadEvents 回放预写日志并生成所有订单事件
+func ReadEvents(wal trading.WAL) ([]Event, error) {
+	builder := NewBuilder()
+	if err := wal.Replay(func(record trading.WALRecord) error {
+		builder.Add(record)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to replay wal: %w", err)
+	}
+	return builder.Events(), nil
+}
+
+// FilterDay 返回时间戳在指定时区的某个自然日内的事件
+func FilterDay(events []Event, day time.Time, loc *time.Location) []Event {
+	day = day.In(loc)
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, 1)
+
+	var filtered []Event
+	for _, event := range events {
+		if !event.Timestamp.Before(start) && event.Timestamp.Before(end) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+// WriteCSV 以每个事件一行的格式导出事件，时间戳转换到指定时区并精确到毫秒
+func WriteCSV(w io.Writer, events []Event, loc *time.Location) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{
+		"seq", "event_type", "timestamp", "order_id", "client_order_id", "broker_order_id", "symbol", "side",
+		"order_type", "quantity", "price", "stop_price", "exec_qty", "exec_price", "leaves_qty", "strategy", "reason",
+	})
+	for _, event := range events {
+		writer.Write([]string{
+			strconv.FormatUint(event.Seq, 10),
+			string(event.Type),
+			event.Timestamp.In(loc).Format(TimestampFormat),
+			event.OrderID,
+			event.ClientOrderID,
+			event.BrokerOrderID,
+			event.Symbol,
+			string(event.Side),
+			string(event.OrderType),
+			strconv.FormatInt(event.Quantity, 10),
+			formatPrice(event.Price),
+			formatPrice(event.StopPrice),
+			formatQuantity(event.ExecQty),
+			formatPrice(event.ExecPrice),
+			strconv.FormatInt(event.LeavesQty, 10),
+			event.Strategy,
+			event.Reason,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// FileName 返回某个交易日的导出文件名
+func FileName(day time.Time) string {
+	return "orders-" + day.Format("2006-01-02") + ".csv"
+}
+
+// ExportDay 将预写日志中某个交易日（按指定时区划分）的事件写入 dir 下的 orders-YYYY-MM-DD.csv，
+// 文件已存在时整体替换，返回文件路径和事件数。没有事件的交易日同样写入只有表头的文件，
+// 以便区分没有订单和没有导出
+func ExportDay(wal trading.WAL, dir string, day time.Time, loc *time.Location) (string, int, error) {
+	events, err := ReadEvents(wal)
+	if err != nil {
+		return "", 0, err
+	}
+	events = FilterDay(events, day, loc)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create export dir: %w", err)
+	}
+	path := filepath.Join(dir, FileName(day.In(loc)))
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := WriteCSV(file, events, loc); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return "", 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return "", 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", 0, fmt.Errorf("failed to replace export file: %w", err)
+	}
+	return path, len(events), nil
+}
+
+// formatPrice 格式化价格，为0时返回空字符串（内部函数）
+func formatPrice(price float64) string {
+	if price == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+// formatQuantity 格式化数量，为0时返回空字符串（内部函数）
+func formatQuantity(qty int64) string {
+	if qty == 0 {
+		return ""
+	}
+	return strconv.FormatInt(qty, 10)
+}
@@ -0,0 +1,158 @@
+package compliance
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+func TestBuilderEvents(t *testing.T) {
+	at := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	order := trading.Order{ID: "o1", Symbol: "AAPL", Quantity: 100, Price: 50, Type: trading.OrderTypeLimit, Side: trading.OrderSideBuy, ClientOrderID: "c1"}
+	record := func(seq uint64, recordType string, order trading.Order, fill *trading.Order) trading.WALRecord {
+		return trading.WALRecord{Seq: seq, Type: recordType, Timestamp: at.Add(time.Duration(seq) * time.Millisecond), Order: &order, Fill: fill}
+	}
+
+	accepted := order
+	accepted.Status, accepted.BrokerOrderID = trading.OrderStatusAccepted, "b1"
+	replaced := accepted
+	replaced.Quantity, replaced.Price = 80, 49.5
+	partial := replaced
+	partial.Status, partial.FilledQty, partial.AvgFillPrice = trading.OrderStatusPartial, 30, 49.5
+	fill := partial
+	fill.FilledQty = 30
+	canceled := partial
+	canceled.Status = trading.OrderStatusCanceled
+
+	builder := NewBuilder()
+	for _, r := range []trading.WALRecord{
+		record(1, trading.WALOrderSubmitted, order, nil),
+		record(2, trading.WALOrderAccepted, accepted, nil),
+		{Seq: 3, Type: trading.WALEngineDisabled, Timestamp: at},
+		record(4, trading.WALOrderUpdated, replaced, nil),
+		record(5, trading.WALOrderUpdated, partial, &fill),
+		record(6, trading.WALOrderCanceled, canceled, nil),
+		// 重复的撤单回报不应重复生成事件
+		record(7, trading.WALOrderUpdated, canceled, nil),
+	} {
+		builder.Add(r)
+	}
+
+	events := builder.Events()
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	if expected := []EventType{EventNew, EventReplace, EventExecution, EventCancel}; !reflect.DeepEqual(types, expected) {
+		t.Fatalf("事件类型不正确: %v", types)
+	}
+	if events[0].Quantity != 100 || events[0].LeavesQty != 100 || events[0].BrokerOrderID != "" || events[0].ClientOrderID != "c1" {
+		t.Errorf("新订单事件不正确: %+v", events[0])
+	}
+	if events[1].Quantity != 80 || events[1].Price != 49.5 || events[1].BrokerOrderID != "b1" || events[1].Seq != 4 {
+		t.Errorf("改单事件不正确: %+v", events[1])
+	}
+	if events[2].ExecQty != 30 || events[2].ExecPrice != 49.5 || events[2].LeavesQty != 50 {
+		t.Errorf("成交事件不正确: %+v", events[2])
+	}
+	if events[3].LeavesQty != 0 || !events[3].Timestamp.Equal(at.Add(6*time.Millisecond)) {
+		t.Errorf("撤单事件不正确: %+v", events[3])
+	}
+
+	rejected := order
+	rejected.ID, rejected.Status, rejected.RejectReason = "o2", trading.OrderStatusRejected, "insufficient buying power"
+	builder.Add(record(8, trading.WALOrderSubmitted, rejected, nil))
+	builder.Add(record(9, trading.WALOrderRejected, rejected, nil))
+	if last := builder.Events()[len(builder.Events())-1]; last.Type != EventReject || last.Reason != "insufficient buying power" {
+		t.Errorf("拒绝事件不正确: %+v", last)
+	}
+}
+
+func TestExportDay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	wal, err := trading.OpenFileWAL(filepath.Join(dir, "engine.wal"), false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+
+	ny, _ := time.LoadLocation("America/New_York")
+	// 美东时间3月7日收盘后和3月8日开盘
+	start := time.Date(2024, 3, 7, 20, 59, 59, 999000000, time.UTC)
+	manual := clock.NewManual(start)
+	broker := testutil.NewMockBroker("")
+	broker.Script(testutil.Accept(), testutil.Accept())
+
+	engine := trading.NewBaseTradingEngine(testutil.NewManager(testutil.NewMockDataSource("")), trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetClock(manual)
+	engine.SetBroker(broker)
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	first, err := engine.SubmitOrder(ctx, "AAPL", 10, 100, trading.OrderTypeLimit, trading.OrderSideBuy)
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	manual.Set(time.Date(2024, 3, 8, 14, 30, 0, 123000000, time.UTC))
+	second, err := engine.SubmitOrder(ctx, "MSFT", 5, 400, trading.OrderTypeLimit, trading.OrderSideBuy)
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	// 前一天的订单在当天成交，第二个订单撤单
+	filled := *first
+	filled.Status, filled.FilledQty, filled.AvgFillPrice = trading.OrderStatusFilled, 10, 99.5
+	executedAt := time.Date(2024, 3, 8, 14, 30, 1, 456000000, time.UTC)
+	broker.Push(trading.OrderUpdate{Order: filled, Execution: &trading.Execution{Quantity: 10, Price: 99.5, ExecutedAt: executedAt}})
+	broker.Close()
+	engine.RunExecutionStream(ctx)
+	if err := engine.CancelOrder(ctx, second.ID); err != nil {
+		t.Fatalf("撤单失败: %v", err)
+	}
+
+	exportDir := filepath.Join(dir, "compliance")
+	path, count, err := ExportDay(wal, exportDir, time.Date(2024, 3, 8, 12, 0, 0, 0, ny), ny)
+	if err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	if filepath.Base(path) != "orders-2024-03-08.csv" || count != 3 {
+		t.Errorf("导出文件或事件数不正确: %s %d", path, count)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("打开导出文件失败: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("读取导出文件失败: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("应有表头和3条事件: %q", rows)
+	}
+	summary := func(row []string) []string { return []string{row[1], row[2], row[6], row[12], row[13], row[14]} }
+	expected := [][]string{
+		{"NEW", "2024-03-08T09:30:00.123-05:00", "MSFT", "", "", "5"},
+		{"EXECUTION", "2024-03-08T09:30:01.456-05:00", "AAPL", "10", "99.5", "0"},
+		{"CANCEL", "2024-03-08T09:30:00.123-05:00", "MSFT", "", "", "0"},
+	}
+	for i, row := range rows[1:] {
+		if got := summary(row); !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("第%d条事件不正确: %q", i+1, got)
+		}
+	}
+
+	// 前一天只有新订单
+	if _, count, err := ExportDay(wal, exportDir, start, ny); err != nil || count != 1 {
+		t.Errorf("前一天应只有新订单事件: %d %v", count, err)
+	}
+}
@@ -0,0 +1,859 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourusername/qhft-system/pkg/chaos"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/fix"
+	"github.com/yourusername/qhft-system/pkg/gateway"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/logger"
+	"github.com/yourusername/qhft-system/pkg/orchestrator"
+	"github.com/yourusername/qhft-system/pkg/risk"
+	"github.com/yourusername/qhft-system/pkg/scanexport"
+	"github.com/yourusername/qhft-system/pkg/schedule"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// EnvPrefix 是环境变量覆盖配置时使用的前缀
+const EnvPrefix = "QHFT"
+
+// 数据源类型常量
+const (
+	DataSourceTypePolygon   = "polygon"
+	DataSourceTypeFinnhub   = "finnhub"   // 按每分钟请求限额排队发出请求
+	DataSourceTypeIBKR      = "ibkr"      // 连接本机运行的TWS或IB Gateway，base_url 为 host:port
+	DataSourceTypeRecording = "recording" // 读取录制器写入的目录，通常配合模拟模式的回放使用
+)
+
+// BrokerFIX 是使用FIX会话下单时 trading.broker.name 的取值
+const BrokerFIX = "fix"
+
+// 模拟模式的数据来源
+const (
+	PaperDataLive    = "live"    // 使用实时数据
+	PaperDataDelayed = "delayed" // 数据落后真实时间固定时长
+	PaperDataReplay  = "replay"  // 从历史时间点开始按倍速回放
+)
+
+// Config 表示系统的统一配置
+type Config struct {
+	Server       ServerConfig                `json:"server" yaml:"server"`
+	DataSources  map[string]DataSourceConfig `json:"datasources" yaml:"datasources"`
+	Trading      TradingConfig               `json:"trading" yaml:"trading"`
+	Strategies   map[string]StrategyConfig   `json:"strategies" yaml:"strategies"`
+	Logging      logger.LogConfig            `json:"logging" yaml:"logging"`
+	TradeLog     TradeLogConfig              `json:"trade_log" yaml:"trade_log"`
+	Monitoring   MonitoringConfig            `json:"monitoring" yaml:"monitoring"`
+	Scanner      ScannerConfig               `json:"scanner" yaml:"scanner"`
+	Watchlist    WatchlistConfig             `json:"watchlist" yaml:"watchlist"`
+	Shutdown     ShutdownConfig              `json:"shutdown" yaml:"shutdown"`
+	Orchestrator OrchestratorConfig          `json:"orchestrator" yaml:"orchestrator"`
+	Messaging    MessagingConfig             `json:"messaging" yaml:"messaging"`
+	Webhooks     WebhookConfig               `json:"webhooks" yaml:"webhooks"`
+	Risk         RiskConfig                  `json:"risk" yaml:"risk"`
+	Paper        PaperConfig                 `json:"paper" yaml:"paper"`
+	Schedule     ScheduleConfig              `json:"schedule" yaml:"schedule"`
+	Symbols      SymbolsConfig               `json:"symbols" yaml:"symbols"`
+	Universes    UniversesConfig             `json:"universes" yaml:"universes"`
+	Recorder     RecorderConfig              `json:"recorder" yaml:"recorder"`
+	QuoteRouting QuoteRoutingConfig          `json:"quote_routing" yaml:"quote_routing"`
+	CorporateActions CorporateActionsConfig  `json:"corporate_actions" yaml:"corporate_actions"`
+	Chaos        chaos.Config                `json:"chaos" yaml:"chaos"`
+	Compliance   ComplianceConfig            `json:"compliance" yaml:"compliance"`
+	Statements   StatementsConfig            `json:"statements" yaml:"statements"`
+}
+
+// ServerConfig 表示服务器配置
+type ServerConfig struct {
+	Host  string `json:"host" yaml:"host"`
+	Port  int    `json:"port" yaml:"port"`
+	Debug bool   `json:"debug" yaml:"debug"`
+}
+
+// Address 返回服务器监听地址
+func (s ServerConfig) Address() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+// DataSourceConfig 表示单个数据源的配置
+// 名称为空时使用datasources中的键名，类型为空时也使用键名
+type DataSourceConfig struct {
+	datasource.DataSourceConfig `yaml:",inline"`
+	Type                        string `json:"type" yaml:"type"`
+	Primary                     bool   `json:"primary" yaml:"primary"` // 是否作为主数据源
+	Path                        string `json:"path" yaml:"path"`       // recording类型数据源的录制目录
+}
+
+// TradingConfig 表示交易配置
+type TradingConfig struct {
+	Broker                trading.BrokerConfig    `json:"broker" yaml:"broker"`
+	Limits                trading.TradingLimits   `json:"limits" yaml:"limits"`
+	Enabled               bool                    `json:"enabled" yaml:"enabled"`                                 // 启动后是否立即启用交易
+	WALPath               string                  `json:"wal_path" yaml:"wal_path"`                               // 引擎预写日志路径，为空时不记录
+	WALSync               bool                    `json:"wal_sync" yaml:"wal_sync"`                               // 每次写入后同步到磁盘
+	EquityPath            string                  `json:"equity_path" yaml:"equity_path"`                         // 账户权益快照文件，为空时只保存在内存中
+	EquityIntervalSeconds int                     `json:"equity_interval_seconds" yaml:"equity_interval_seconds"` // 记录权益快照的间隔，为0时不记录
+	FIX                   fix.Config              `json:"fix" yaml:"fix"`                                         // broker.name 为 fix 时使用的会话配置
+	Rounding              trading.RoundingConfig  `json:"rounding" yaml:"rounding"`                               // 下单前的报价单位和交易单位取整
+	RateGuard             trading.RateGuardConfig `json:"rate_guard" yaml:"rate_guard"`                           // 下单频率异常检测
+	Halts                 trading.HaltConfig      `json:"halts" yaml:"halts"`                                     // 停牌检测
+	ShortSale             trading.ShortSaleConfig `json:"short_sale" yaml:"short_sale"`                           // 卖空限制和可借券检查
+	Accrual               trading.AccrualConfig   `json:"accrual" yaml:"accrual"`                                 // 持仓分红和现金利息计提
+	AccountWatch          trading.AccountWatchConfig `json:"account_watch" yaml:"account_watch"`                  // 账户快照和异常告警
+	StopIntervalSeconds   int                     `json:"stop_interval_seconds" yaml:"stop_interval_seconds"`     // 本地检查止损和止盈的间隔，为0时不检查
+	MarkIntervalSeconds   int                     `json:"mark_interval_seconds" yaml:"mark_interval_seconds"`     // 按最新报价重新估值持仓的间隔，为0时只在成交时更新
+}
+
+// StrategyConfig 表示筛选策略配置
+type StrategyConfig struct {
+	Enabled    bool                         `json:"enabled" yaml:"enabled"`
+	Indicators []indicators.IndicatorConfig `json:"indicators" yaml:"indicators"`
+	Filters    []indicators.MarketFilter    `json:"filters" yaml:"filters"`   // 引用其他股票的市场过滤条件
+	Universe   indicators.Universe          `json:"universe" yaml:"universe"` // 策略自己的股票范围，为空时扫描 scanner 的股票
+}
+
+// TradeLogConfig 表示交易日志配置
+type TradeLogConfig struct {
+	Dir                       string `json:"dir" yaml:"dir"`
+	logger.TradeLoggerOptions `yaml:",inline"`
+}
+
+// MonitoringConfig 表示监控配置
+type MonitoringConfig struct {
+	HealthCheckTimeoutSeconds int    `json:"health_check_timeout_seconds" yaml:"health_check_timeout_seconds"`
+	ClockReferenceURL         string `json:"clock_reference_url" yaml:"clock_reference_url"` // 为空时不检查时钟偏差
+	MaxClockSkewMillis        int    `json:"max_clock_skew_ms" yaml:"max_clock_skew_ms"`
+}
+
+// ScannerConfig 表示定时扫描配置
+type ScannerConfig struct {
+	Enabled         bool     `json:"enabled" yaml:"enabled"`
+	IntervalSeconds int      `json:"interval_seconds" yaml:"interval_seconds"`
+	Symbols         []string `json:"symbols" yaml:"symbols"`
+	Universe        string   `json:"universe" yaml:"universe"`     // 指数或ETF名称，如 sp500，扫描其成分股并与symbols合并
+	Strategies      []string `json:"strategies" yaml:"strategies"` // 为空时扫描所有启用的策略
+	Schedule        string   `json:"schedule" yaml:"schedule"`     // 日历表达式，如 "every 5m during rth"，设置后代替interval_seconds
+	Prime           string   `json:"prime" yaml:"prime"`           // 日历表达式，如 "30 minutes before open"，在此时预取扫描所需的K线并缓存
+	Timeframe       string   `json:"timeframe" yaml:"timeframe"`
+	LookbackDays    int      `json:"lookback_days" yaml:"lookback_days"`
+
+	Sinks   []scanexport.SinkConfig `json:"sinks" yaml:"sinks"`     // 每轮扫描后输出候选列表
+	Ranking scanexport.RankOptions  `json:"ranking" yaml:"ranking"` // 每轮扫描后对所有策略的候选排名
+}
+
+// WatchlistConfig 表示监控列表配置
+type WatchlistConfig struct {
+	Enabled             bool `json:"enabled" yaml:"enabled"`
+	ScanIntervalSeconds int  `json:"scan_interval_seconds" yaml:"scan_interval_seconds"`
+}
+
+// ShutdownConfig 表示关闭流程配置
+type ShutdownConfig struct {
+	TimeoutSeconds   int  `json:"timeout_seconds" yaml:"timeout_seconds"`
+	CancelOpenOrders bool `json:"cancel_open_orders" yaml:"cancel_open_orders"` // 关闭时取消所有未完成订单
+}
+
+// OrchestratorConfig 表示多策略编排配置
+type OrchestratorConfig struct {
+	Enabled         bool                        `json:"enabled" yaml:"enabled"`
+	IntervalSeconds int                         `json:"interval_seconds" yaml:"interval_seconds"`
+	ConflictPolicy  orchestrator.ConflictPolicy `json:"conflict_policy" yaml:"conflict_policy"`
+	Allocations     []orchestrator.Allocation   `json:"allocations" yaml:"allocations"`
+}
+
+// MessagingConfig 表示NATS/Kafka消息总线配置
+type MessagingConfig struct {
+	Enabled      bool     `json:"enabled" yaml:"enabled"`
+	Driver       string   `json:"driver" yaml:"driver"` // nats 或 kafka
+	URL          string   `json:"url" yaml:"url"`       // NATS地址，或逗号分隔的Kafka broker列表
+	Group        string   `json:"group" yaml:"group"`   // Kafka消费组
+	Prefix       string   `json:"prefix" yaml:"prefix"`
+	Source       string   `json:"source" yaml:"source"` // 本进程标识
+	ExportTopics []string `json:"export_topics" yaml:"export_topics"`
+	ImportTopics []string `json:"import_topics" yaml:"import_topics"`
+	AcceptOrders bool     `json:"accept_orders" yaml:"accept_orders"` // 是否从消息总线接收下单请求
+}
+
+// RiskConfig 表示组合风险服务配置
+type RiskConfig struct {
+	Enabled         bool             `json:"enabled" yaml:"enabled"`
+	IntervalSeconds int              `json:"interval_seconds" yaml:"interval_seconds"`
+	Benchmark       string           `json:"benchmark" yaml:"benchmark"`
+	LookbackDays    int              `json:"lookback_days" yaml:"lookback_days"`
+	Confidence      float64          `json:"confidence" yaml:"confidence"`
+	HorizonDays     int              `json:"horizon_days" yaml:"horizon_days"`
+	Limits          risk.Limits      `json:"limits" yaml:"limits"`
+	Allocation      AllocationConfig `json:"allocation" yaml:"allocation"` // 不依赖 enabled，单独启用
+}
+
+// AllocationConfig 表示按策略业绩定期调整资金分配的配置，调整结果写入交易引擎的策略限制
+type AllocationConfig struct {
+	Enabled               bool   `json:"enabled" yaml:"enabled"`
+	Schedule              string `json:"schedule" yaml:"schedule"` // 调整分配的日历表达式，如 "at 17:00"
+	risk.AllocationPolicy `yaml:",inline"`
+}
+
+// WebhookConfig 表示外部信号Webhook配置
+type WebhookConfig struct {
+	Enabled bool                    `json:"enabled" yaml:"enabled"`
+	Path    string                  `json:"path" yaml:"path"` // 挂载路径，告警提交到 <path><source>
+	Sources []gateway.WebhookSource `json:"sources" yaml:"sources"`
+}
+
+// ScheduleConfig 表示交易日历和定时任务配置
+type ScheduleConfig struct {
+	Timezone    string   `json:"timezone" yaml:"timezone"`         // 交易所时区
+	Holidays    []string `json:"holidays" yaml:"holidays"`         // NYSE规则之外的额外休市日
+	EarlyCloses []string `json:"early_closes" yaml:"early_closes"` // NYSE规则之外的额外提前收盘日
+	EODSummary  string   `json:"eod_summary" yaml:"eod_summary"`   // 写入每日交易汇总的日历表达式，为空时不写入
+}
+
+// SymbolsConfig 表示股票列表缓存配置
+type SymbolsConfig struct {
+	CachePath string `json:"cache_path" yaml:"cache_path"` // 股票列表缓存文件，为空时不缓存
+	Refresh   string `json:"refresh" yaml:"refresh"`       // 刷新缓存的日历表达式，如 "at 20:00"，为空时只在缓存为空时刷新
+}
+
+// RecorderConfig 表示行情录制配置
+type RecorderConfig struct {
+	Enabled   bool   `json:"enabled" yaml:"enabled"`
+	Dir       string `json:"dir" yaml:"dir"`                 // 录制目录
+	MaxFileMB int    `json:"max_file_mb" yaml:"max_file_mb"` // 单个文件轮转前的最大大小，为0时使用默认值
+
+	Verify        string `json:"verify" yaml:"verify"`                 // 抽查录制数据与数据源是否一致的日历表达式，为空时不校验
+	VerifySamples int    `json:"verify_samples" yaml:"verify_samples"` // 每次抽查的股票和日期组合数，为0时使用默认值
+	VerifyDays    int    `json:"verify_days" yaml:"verify_days"`       // 只抽查最近的若干个交易日，为0时不限制
+	Repair        bool   `json:"repair" yaml:"repair"`                 // 是否用数据源的数据修复不一致的录制数据
+}
+
+// ComplianceConfig 表示订单记录簿导出配置，事件从引擎的预写日志生成
+type ComplianceConfig struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+	Dir      string `json:"dir" yaml:"dir"`           // 导出目录，每个交易日一个 orders-YYYY-MM-DD.csv
+	Schedule string `json:"schedule" yaml:"schedule"` // 导出当天事件的日历表达式，如 "at 17:00"
+}
+
+// StatementsConfig 表示月度对账单配置
+type StatementsConfig struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+	Dir      string `json:"dir" yaml:"dir"`           // 对账单目录，每月一个 statement-YYYY-MM.pdf
+	Schedule string `json:"schedule" yaml:"schedule"` // 检查是否生成对账单的日历表达式，只在每月最后一个交易日生成，如 "at 17:00"
+	Account  string `json:"account" yaml:"account"`   // 对账单上显示的账户名称，为空时使用券商账户ID
+}
+
+// QuoteRoutingConfig 表示实时报价按延迟选择数据源的配置，为0的字段使用默认值
+type QuoteRoutingConfig struct {
+	Enabled        bool    `json:"enabled" yaml:"enabled"`
+	Window         int     `json:"window" yaml:"window"`                     // 每个数据源统计最近的请求数
+	SwitchMargin   float64 `json:"switch_margin" yaml:"switch_margin"`       // 其他数据源的平均延迟低该比例以上才切换，如0.2
+	MinSuccessRate float64 `json:"min_success_rate" yaml:"min_success_rate"` // 成功率低于该值的数据源视为不健康
+	ProbeSeconds   int     `json:"probe_seconds" yaml:"probe_seconds"`       // 探测未选中数据源的间隔秒数
+}
+
+// CorporateActionsConfig 表示拆股和分红的配置
+type CorporateActionsConfig struct {
+	Adjustment string `json:"adjustment" yaml:"adjustment"` // K线的复权方式：none、splits 或 all，为空时为 splits
+}
+
+// UniversesConfig 表示指数和ETF成分股配置
+type UniversesConfig struct {
+	Dir string `json:"dir" yaml:"dir"` // 成分股文件目录，每个指数或ETF一个 <名称>.csv 文件
+}
+
+// Calendar 根据配置创建交易日历
+func (s ScheduleConfig) Calendar() (*schedule.Calendar, error) {
+	return schedule.NewCalendar(s.Timezone, s.Holidays, s.EarlyCloses)
+}
+
+// PaperConfig 表示模拟（演练）模式配置
+// 启用后券商切换为模拟券商，数据源切换为延迟或回放数据，所有日志和交易记录都标记为模拟，
+// 同一份配置只需切换 paper.enabled（或 QHFT_PAPER_ENABLED）即可在演练和实盘之间切换
+type PaperConfig struct {
+	Enabled      bool    `json:"enabled" yaml:"enabled"`
+	Data         string  `json:"data" yaml:"data"`                   // live、delayed 或 replay
+	DelaySeconds int     `json:"delay_seconds" yaml:"delay_seconds"` // delayed模式下数据落后的秒数
+	ReplayStart  string  `json:"replay_start" yaml:"replay_start"`   // replay模式的起始时间，RFC3339或日期
+	ReplaySpeed  float64 `json:"replay_speed" yaml:"replay_speed"`   // replay模式的回放倍速
+}
+
+// ReplayStartTime 解析回放起始时间，支持RFC3339和 2006-01-02 格式
+func (p PaperConfig) ReplayStartTime() (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, p.ReplayStart); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", p.ReplayStart)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid replay start %q", p.ReplayStart)
+	}
+	return t, nil
+}
+
+// Default 返回带有默认值的配置
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Host: "0.0.0.0",
+			Port: 8080,
+		},
+		DataSources: make(map[string]DataSourceConfig),
+		Trading: TradingConfig{
+			Broker: trading.BrokerConfig{
+				IsPaperTrading: true,
+			},
+			Limits: trading.TradingLimits{
+				MaxPositions:           20,
+				MaxPositionSizePercent: 5.0,
+				MaxDailyTrades:         50,
+				StopLossPercent:        2.0,
+				TakeProfitPercent:      5.0,
+			},
+		},
+		Strategies: make(map[string]StrategyConfig),
+		Logging: logger.LogConfig{
+			Level:      logger.LogLevelInfo,
+			Format:     logger.LogFormatJSON,
+			Output:     logger.LogOutputConsole,
+			FilePath:   "./logs/qhft.log",
+			MaxSizeMB:  100,
+			MaxBackups: 10,
+			MaxAgeDays: 30,
+		},
+		TradeLog: TradeLogConfig{
+			Dir: "./logs/trades",
+		},
+		Monitoring: MonitoringConfig{
+			HealthCheckTimeoutSeconds: 5,
+			MaxClockSkewMillis:        1000,
+		},
+		Scanner: ScannerConfig{
+			IntervalSeconds: 300,
+			Timeframe:       "day",
+			LookbackDays:    120,
+		},
+		Watchlist: WatchlistConfig{
+			Enabled:             true,
+			ScanIntervalSeconds: 60,
+		},
+		Shutdown: ShutdownConfig{
+			TimeoutSeconds:   30,
+			CancelOpenOrders: true,
+		},
+		Orchestrator: OrchestratorConfig{
+			IntervalSeconds: 60,
+			ConflictPolicy:  orchestrator.ConflictPriority,
+		},
+		Messaging: MessagingConfig{
+			Driver: "nats",
+			Prefix: "qhft",
+		},
+		Webhooks: WebhookConfig{
+			Path: "/webhooks/",
+		},
+		Risk: RiskConfig{
+			IntervalSeconds: 300,
+			Benchmark:       "SPY",
+			LookbackDays:    250,
+			Confidence:      0.95,
+			HorizonDays:     1,
+		},
+		Paper: PaperConfig{
+			Data:         PaperDataDelayed,
+			DelaySeconds: 900,
+			ReplaySpeed:  1,
+		},
+		Schedule: ScheduleConfig{
+			Timezone: schedule.DefaultTimezone,
+		},
+	}
+}
+
+// Load 从YAML文件加载配置，并应用环境变量覆盖和校验
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse 解析YAML配置内容，并应用环境变量覆盖和校验
+// 环境变量名由前缀和YAML路径组成，例如 QHFT_TRADING_BROKER_API_KEY、QHFT_DATASOURCES_POLYGON_API_KEY
+func Parse(data []byte) (*Config, error) {
+	cfg := Default()
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %v", err)
+	}
+
+	if err := ApplyEnv(&cfg, EnvPrefix, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	cfg.normalize()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// normalize 补全依赖键名的字段（内部方法）
+func (c *Config) normalize() {
+	for key, ds := range c.DataSources {
+		if ds.Name == "" {
+			ds.Name = key
+		}
+		if ds.Type == "" {
+			ds.Type = key
+		}
+		c.DataSources[key] = ds
+	}
+
+	for key, strategy := range c.Strategies {
+		for i, ind := range strategy.Indicators {
+			if ind.Type == "" {
+				strategy.Indicators[i].Type = ind.Name
+			}
+		}
+		c.Strategies[key] = strategy
+	}
+}
+
+// Validate 校验配置，返回所有发现的问题
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port < 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 0 and 65535, got %d", c.Server.Port))
+	}
+
+	primaries := 0
+	enabled := 0
+	for _, key := range sortedKeys(c.DataSources) {
+		ds := c.DataSources[key]
+		if !ds.Enabled {
+			continue
+		}
+		enabled++
+		if ds.Primary {
+			primaries++
+		}
+		switch ds.Type {
+		case DataSourceTypePolygon, DataSourceTypeFinnhub:
+			if ds.APIKey == "" && len(ds.APIKeys) == 0 {
+				errs = append(errs, fmt.Errorf("datasources.%s.api_key or api_keys is required", key))
+			}
+			switch ds.KeySelection {
+			case "", datasource.KeySelectionRoundRobin, datasource.KeySelectionLeastUsed:
+			default:
+				errs = append(errs, fmt.Errorf("datasources.%s.key_selection %q is not supported", key, ds.KeySelection))
+			}
+			if ds.KeyCooldownSeconds < 0 {
+				errs = append(errs, fmt.Errorf("datasources.%s.key_cooldown_seconds must not be negative", key))
+			}
+			if ds.RequestsPerMinute < 0 {
+				errs = append(errs, fmt.Errorf("datasources.%s.requests_per_minute must not be negative", key))
+			}
+		case DataSourceTypeIBKR:
+			if ds.BaseURL != "" {
+				if _, _, err := net.SplitHostPort(strings.TrimPrefix(ds.BaseURL, "tcp://")); err != nil {
+					errs = append(errs, fmt.Errorf("datasources.%s.base_url must be the TWS host:port: %v", key, err))
+				}
+			}
+			if ds.ClientID < 0 {
+				errs = append(errs, fmt.Errorf("datasources.%s.client_id must not be negative", key))
+			}
+		case DataSourceTypeRecording:
+			if ds.Path == "" {
+				errs = append(errs, fmt.Errorf("datasources.%s.path is required for recording data sources", key))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("datasources.%s.type %q is not supported", key, ds.Type))
+		}
+		if ds.TimeoutSeconds < 0 || ds.RetryAttempts < 0 || ds.RetryDelaySeconds < 0 {
+			errs = append(errs, fmt.Errorf("datasources.%s timeouts and retries must not be negative", key))
+		}
+		if ds.Timezone != "" {
+			if _, err := time.LoadLocation(ds.Timezone); err != nil {
+				errs = append(errs, fmt.Errorf("datasources.%s.timezone: %v", key, err))
+			}
+		}
+	}
+	if enabled == 0 {
+		errs = append(errs, fmt.Errorf("at least one enabled data source is required"))
+	}
+	if primaries > 1 {
+		errs = append(errs, fmt.Errorf("only one data source can be primary, got %d", primaries))
+	}
+
+	if c.Paper.Enabled {
+		// 模拟模式下不连接真实券商，无需校验券商凭证和FIX会话
+	} else if c.Trading.Broker.Name == BrokerFIX {
+		if err := c.Trading.FIX.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("trading.fix: %w", err))
+		}
+	} else if !c.Trading.Broker.IsPaperTrading && (c.Trading.Broker.APIKey == "" || c.Trading.Broker.APISecret == "") {
+		errs = append(errs, fmt.Errorf("trading.broker.api_key and api_secret are required for live trading"))
+	}
+
+	switch c.Trading.Broker.PositionMode {
+	case "", trading.PositionModeNetting, trading.PositionModeHedging:
+	default:
+		errs = append(errs, fmt.Errorf("trading.broker.position_mode must be netting or hedging, got %q", c.Trading.Broker.PositionMode))
+	}
+	switch c.Trading.Broker.StopMode {
+	case "", trading.StopModeLocal, trading.StopModeBroker:
+	default:
+		errs = append(errs, fmt.Errorf("trading.broker.stop_mode must be local or broker, got %q", c.Trading.Broker.StopMode))
+	}
+	if c.Trading.StopIntervalSeconds < 0 {
+		errs = append(errs, fmt.Errorf("trading.stop_interval_seconds must not be negative"))
+	}
+	if c.Trading.MarkIntervalSeconds < 0 {
+		errs = append(errs, fmt.Errorf("trading.mark_interval_seconds must not be negative"))
+	}
+
+	if c.Trading.EquityIntervalSeconds < 0 {
+		errs = append(errs, fmt.Errorf("trading.equity_interval_seconds must not be negative"))
+	}
+	if err := c.Trading.Rounding.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trading.rounding: %w", err))
+	}
+	if err := c.Trading.RateGuard.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trading.rate_guard: %w", err))
+	}
+	if err := c.Trading.Halts.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trading.halts: %w", err))
+	}
+	if err := c.Trading.ShortSale.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trading.short_sale: %w", err))
+	}
+	if err := c.Trading.AccountWatch.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trading.account_watch: %w", err))
+	}
+	if a := c.Trading.Accrual; a.Enabled {
+		if a.Schedule == "" {
+			errs = append(errs, fmt.Errorf("trading.accrual.schedule is required when accrual is enabled"))
+		}
+		if err := a.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("trading.accrual: %w", err))
+		}
+	}
+
+	limits := c.Trading.Limits
+	if limits.MaxPositions < 0 || limits.MaxDailyTrades < 0 {
+		errs = append(errs, fmt.Errorf("trading.limits counts must not be negative"))
+	}
+	if limits.MaxPositionSizePercent < 0 || limits.MaxPositionSizePercent > 100 {
+		errs = append(errs, fmt.Errorf("trading.limits.max_position_size_percent must be between 0 and 100"))
+	}
+	if limits.StopLossPercent < 0 || limits.TakeProfitPercent < 0 {
+		errs = append(errs, fmt.Errorf("trading.limits stop loss and take profit must not be negative"))
+	}
+	if limits.MaxDailyLossPercent < 0 || limits.MaxDailyLossPercent > 100 {
+		errs = append(errs, fmt.Errorf("trading.limits.max_daily_loss_percent must be between 0 and 100"))
+	}
+	if err := limits.TimeExit.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trading.limits.time_exit: %w", err))
+	}
+	if limits.DuplicateWindowSeconds < 0 {
+		errs = append(errs, fmt.Errorf("trading.limits.duplicate_window_seconds must not be negative"))
+	}
+	for _, name := range sortedKeys(limits.Strategies) {
+		if err := limits.Strategies[name].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("trading.limits.strategies.%s: %w", name, err))
+		}
+	}
+
+	for _, key := range sortedKeys(c.Strategies) {
+		strategy := c.Strategies[key]
+		if strategy.Enabled && len(strategy.Indicators) == 0 {
+			errs = append(errs, fmt.Errorf("strategies.%s has no indicators", key))
+		}
+		for i, ind := range strategy.Indicators {
+			if ind.Type == "" {
+				errs = append(errs, fmt.Errorf("strategies.%s.indicators[%d] requires a name or type", key, i))
+			}
+			if ind.BuyCondition == "" && ind.SellCondition == "" {
+				errs = append(errs, fmt.Errorf("strategies.%s.indicators[%d] requires a buy or sell condition", key, i))
+			}
+		}
+		for i, filter := range strategy.Filters {
+			if filter.Symbol == "" || filter.Type == "" || filter.Condition == "" {
+				errs = append(errs, fmt.Errorf("strategies.%s.filters[%d] requires symbol, type and condition", key, i))
+			}
+			switch filter.Signals {
+			case indicators.FilterSignalsAll, indicators.FilterSignalsBuy, indicators.FilterSignalsSell:
+			default:
+				errs = append(errs, fmt.Errorf("strategies.%s.filters[%d].signals %q is invalid", key, i, filter.Signals))
+			}
+			if filter.LookbackDays < 0 {
+				errs = append(errs, fmt.Errorf("strategies.%s.filters[%d].lookback_days must not be negative", key, i))
+			}
+		}
+		if strategy.Universe.Index != "" && c.Universes.Dir == "" {
+			errs = append(errs, fmt.Errorf("strategies.%s.universe.index requires universes.dir", key))
+		}
+		if strategy.Universe.Filter != "" {
+			if _, err := indicators.ParseUniverseFilter(strategy.Universe.Filter); err != nil {
+				errs = append(errs, fmt.Errorf("strategies.%s.universe.filter: %w", key, err))
+			}
+		}
+	}
+
+	switch c.Logging.Level {
+	case logger.LogLevelDebug, logger.LogLevelInfo, logger.LogLevelWarn, logger.LogLevelError, logger.LogLevelFatal:
+	default:
+		errs = append(errs, fmt.Errorf("logging.level %q is invalid", c.Logging.Level))
+	}
+	switch c.Logging.Format {
+	case logger.LogFormatText, logger.LogFormatJSON:
+	default:
+		errs = append(errs, fmt.Errorf("logging.format %q is invalid", c.Logging.Format))
+	}
+	switch c.Logging.Output {
+	case logger.LogOutputConsole:
+	case logger.LogOutputFile, logger.LogOutputBoth:
+		if c.Logging.FilePath == "" {
+			errs = append(errs, fmt.Errorf("logging.file_path is required for file output"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("logging.output %q is invalid", c.Logging.Output))
+	}
+
+	if c.TradeLog.Dir == "" {
+		errs = append(errs, fmt.Errorf("trade_log.dir is required"))
+	}
+
+	if c.Monitoring.HealthCheckTimeoutSeconds < 0 || c.Monitoring.MaxClockSkewMillis < 0 {
+		errs = append(errs, fmt.Errorf("monitoring timeouts must not be negative"))
+	}
+
+	if c.Scanner.Enabled {
+		if c.Scanner.IntervalSeconds <= 0 && c.Scanner.Schedule == "" {
+			errs = append(errs, fmt.Errorf("scanner.interval_seconds must be positive when no schedule is set"))
+		}
+		if len(c.Scanner.Symbols) == 0 && c.Scanner.Universe == "" && !c.strategiesHaveUniverses() {
+			errs = append(errs, fmt.Errorf("scanner.symbols or scanner.universe is required when the scanner is enabled, unless every scanned strategy has its own universe"))
+		}
+		if c.Scanner.Universe != "" && c.Universes.Dir == "" {
+			errs = append(errs, fmt.Errorf("scanner.universe requires universes.dir"))
+		}
+		for _, name := range c.Scanner.Strategies {
+			if _, ok := c.Strategies[name]; !ok {
+				errs = append(errs, fmt.Errorf("scanner.strategies references unknown strategy %q", name))
+			}
+		}
+		if c.Scanner.Ranking.Enabled {
+			if err := c.Scanner.Ranking.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("scanner.ranking: %w", err))
+			}
+		}
+		for i, sink := range c.Scanner.Sinks {
+			if err := sink.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("scanner.sinks[%d]: %w", i, err))
+			} else if sink.Type == scanexport.SinkMessaging && !c.Messaging.Enabled {
+				errs = append(errs, fmt.Errorf("scanner.sinks[%d]: messaging sink requires messaging.enabled", i))
+			}
+		}
+	}
+	if c.Watchlist.Enabled && c.Watchlist.ScanIntervalSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("watchlist.scan_interval_seconds must be positive"))
+	}
+	if c.Orchestrator.Enabled {
+		if c.Orchestrator.IntervalSeconds <= 0 {
+			errs = append(errs, fmt.Errorf("orchestrator.interval_seconds must be positive"))
+		}
+		switch c.Orchestrator.ConflictPolicy {
+		case orchestrator.ConflictPriority, orchestrator.ConflictNetScore, orchestrator.ConflictSkip:
+		default:
+			errs = append(errs, fmt.Errorf("orchestrator.conflict_policy %q is invalid", c.Orchestrator.ConflictPolicy))
+		}
+		for i, allocation := range c.Orchestrator.Allocations {
+			if err := allocation.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("orchestrator.allocations[%d]: %w", i, err))
+			}
+			if _, ok := c.Strategies[allocation.Strategy]; !ok {
+				errs = append(errs, fmt.Errorf("orchestrator.allocations[%d] references unknown strategy %q", i, allocation.Strategy))
+			}
+		}
+	}
+	if c.Messaging.Enabled {
+		if c.Messaging.Driver != "nats" && c.Messaging.Driver != "kafka" {
+			errs = append(errs, fmt.Errorf("messaging.driver %q is invalid", c.Messaging.Driver))
+		}
+		if c.Messaging.Driver == "kafka" && c.Messaging.URL == "" {
+			errs = append(errs, fmt.Errorf("messaging.url is required for kafka"))
+		}
+	}
+	if c.Risk.Enabled {
+		if c.Risk.IntervalSeconds <= 0 || c.Risk.LookbackDays < 2 || c.Risk.HorizonDays <= 0 {
+			errs = append(errs, fmt.Errorf("risk.interval_seconds, lookback_days and horizon_days must be positive"))
+		}
+		if c.Risk.Confidence <= 0 || c.Risk.Confidence >= 1 {
+			errs = append(errs, fmt.Errorf("risk.confidence must be between 0 and 1, got %v", c.Risk.Confidence))
+		}
+		l := c.Risk.Limits
+		if l.MaxVaRPercent < 0 || l.MaxGrossExposurePercent < 0 || l.MaxNetExposurePercent < 0 || l.MaxBetaExposurePercent < 0 || l.MaxConcentrationPercent < 0 {
+			errs = append(errs, fmt.Errorf("risk.limits must not be negative"))
+		}
+		if err := l.Correlation.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("risk.limits: %w", err))
+		}
+	}
+	if a := c.Risk.Allocation; a.Enabled {
+		if a.Schedule == "" {
+			errs = append(errs, fmt.Errorf("risk.allocation.schedule is required when allocation is enabled"))
+		}
+		if err := a.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("risk.allocation: %w", err))
+		}
+	}
+	if c.Webhooks.Enabled {
+		if !strings.HasPrefix(c.Webhooks.Path, "/") || !strings.HasSuffix(c.Webhooks.Path, "/") {
+			errs = append(errs, fmt.Errorf("webhooks.path %q must start and end with '/'", c.Webhooks.Path))
+		}
+		if c.Server.Port == 0 {
+			errs = append(errs, fmt.Errorf("webhooks require server.port to be set"))
+		}
+		names := make(map[string]bool)
+		for i, source := range c.Webhooks.Sources {
+			if err := source.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("webhooks.sources[%d]: %w", i, err))
+			}
+			if names[source.Name] {
+				errs = append(errs, fmt.Errorf("webhooks.sources[%d]: duplicate source %q", i, source.Name))
+			}
+			names[source.Name] = true
+		}
+	}
+	if c.Paper.Enabled {
+		switch c.Paper.Data {
+		case PaperDataLive:
+		case PaperDataDelayed:
+			if c.Paper.DelaySeconds < 0 {
+				errs = append(errs, fmt.Errorf("paper.delay_seconds must not be negative"))
+			}
+		case PaperDataReplay:
+			if _, err := c.Paper.ReplayStartTime(); err != nil {
+				errs = append(errs, fmt.Errorf("paper.replay_start: %w", err))
+			}
+			if c.Paper.ReplaySpeed <= 0 {
+				errs = append(errs, fmt.Errorf("paper.replay_speed must be positive"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("paper.data %q is invalid", c.Paper.Data))
+		}
+	}
+	if c.Recorder.Enabled {
+		if c.Recorder.Dir == "" {
+			errs = append(errs, fmt.Errorf("recorder.dir is required when the recorder is enabled"))
+		}
+		if c.Recorder.MaxFileMB < 0 || c.Recorder.VerifySamples < 0 || c.Recorder.VerifyDays < 0 {
+			errs = append(errs, fmt.Errorf("recorder.max_file_mb, verify_samples and verify_days must not be negative"))
+		}
+	}
+	if r := c.QuoteRouting; r.Window < 0 || r.ProbeSeconds < 0 || r.SwitchMargin < 0 || r.SwitchMargin >= 1 ||
+		r.MinSuccessRate < 0 || r.MinSuccessRate > 1 {
+		errs = append(errs, fmt.Errorf("quote_routing.window and probe_seconds must not be negative, switch_margin must be in [0, 1) and min_success_rate in [0, 1]"))
+	}
+	if _, err := datasource.ParseAdjustment(c.CorporateActions.Adjustment); err != nil {
+		errs = append(errs, fmt.Errorf("corporate_actions.adjustment: %w", err))
+	}
+	if err := c.Chaos.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("chaos: %w", err))
+	}
+	if c.Compliance.Enabled {
+		if c.Compliance.Dir == "" || c.Compliance.Schedule == "" {
+			errs = append(errs, fmt.Errorf("compliance.dir and schedule are required when the export is enabled"))
+		}
+		if c.Trading.WALPath == "" {
+			errs = append(errs, fmt.Errorf("compliance: trading.wal_path is required to export order events"))
+		}
+	}
+	if c.Statements.Enabled && (c.Statements.Dir == "" || c.Statements.Schedule == "") {
+		errs = append(errs, fmt.Errorf("statements.dir and schedule are required when statements are enabled"))
+	}
+	if calendar, err := c.Schedule.Calendar(); err != nil {
+		errs = append(errs, fmt.Errorf("schedule: %w", err))
+	} else {
+		expressions := map[string]string{"schedule.eod_summary": c.Schedule.EODSummary}
+		if c.Scanner.Enabled {
+			expressions["scanner.schedule"] = c.Scanner.Schedule
+			expressions["scanner.prime"] = c.Scanner.Prime
+		}
+		if c.Symbols.CachePath != "" {
+			expressions["symbols.refresh"] = c.Symbols.Refresh
+		}
+		if c.Recorder.Enabled {
+			expressions["recorder.verify"] = c.Recorder.Verify
+		}
+		if c.Compliance.Enabled {
+			expressions["compliance.schedule"] = c.Compliance.Schedule
+		}
+		if c.Statements.Enabled {
+			expressions["statements.schedule"] = c.Statements.Schedule
+		}
+		if c.Risk.Allocation.Enabled {
+			expressions["risk.allocation.schedule"] = c.Risk.Allocation.Schedule
+		}
+		if c.Trading.Accrual.Enabled {
+			expressions["trading.accrual.schedule"] = c.Trading.Accrual.Schedule
+		}
+		for _, key := range sortedKeys(expressions) {
+			if expr := expressions[key]; expr != "" {
+				if _, err := schedule.Parse(expr, calendar); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				}
+			}
+		}
+	}
+	if c.Shutdown.TimeoutSeconds < 0 {
+		errs = append(errs, fmt.Errorf("shutdown.timeout_seconds must not be negative"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// strategiesHaveUniverses 检查扫描的每个策略是否都设置了自己的股票（universe.symbols 或 universe.index）（内部方法）
+func (c *Config) strategiesHaveUniverses() bool {
+	names := c.Scanner.Strategies
+	if len(names) == 0 {
+		for name, strategy := range c.Strategies {
+			if strategy.Enabled {
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range names {
+		universe := c.Strategies[name].Universe
+		if len(universe.Symbols) == 0 && universe.Index == "" {
+			return false
+		}
+	}
+	return len(names) > 0
+}
+
+// sortedKeys 返回排序后的键名，保证校验错误顺序稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
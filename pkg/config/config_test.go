@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/logger"
+)
+
+func TestLoadExampleConfig(t *testing.T) {
+	data, err := os.ReadFile("../../config.example.yaml")
+	if err != nil {
+		t.Fatalf("读取示例配置失败: %v", err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		t.Fatalf("解析示例配置失败: %v", err)
+	}
+
+	polygon, ok := cfg.DataSources["polygon"]
+	if !ok || polygon.Name != "polygon" || polygon.Type != DataSourceTypePolygon || !polygon.Primary {
+		t.Errorf("polygon数据源配置不正确: %+v", polygon)
+	}
+	if cfg.Trading.Limits.MaxPositions != 20 {
+		t.Errorf("MaxPositions = %d, 期望 20", cfg.Trading.Limits.MaxPositions)
+	}
+	if got := cfg.Strategies["default"].Indicators[2].Type; got != "BollingerBands" {
+		t.Errorf("指标类型 = %q, 期望 BollingerBands", got)
+	}
+	if got := cfg.Strategies["default"].Indicators[0].Type; got != "MACD" {
+		t.Errorf("未指定类型时应使用名称, 得到 %q", got)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg := Default()
+	cfg.DataSources["polygon"] = DataSourceConfig{Type: DataSourceTypePolygon}
+
+	env := map[string]string{
+		"QHFT_LOGGING_LEVEL":                   "debug",
+		"QHFT_TRADING_LIMITS_MAX_POSITIONS":    "7",
+		"QHFT_TRADING_BROKER_IS_PAPER_TRADING": "false",
+		"QHFT_DATASOURCES_POLYGON_API_KEY":     "secret",
+		"QHFT_TRADE_LOG_PARTITION_BY_ACCOUNT":  "true",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	if err := ApplyEnv(&cfg, EnvPrefix, lookup); err != nil {
+		t.Fatalf("应用环境变量失败: %v", err)
+	}
+
+	if cfg.Logging.Level != logger.LogLevelDebug {
+		t.Errorf("Logging.Level = %q, 期望 debug", cfg.Logging.Level)
+	}
+	if cfg.Trading.Limits.MaxPositions != 7 {
+		t.Errorf("MaxPositions = %d, 期望 7", cfg.Trading.Limits.MaxPositions)
+	}
+	if cfg.Trading.Broker.IsPaperTrading {
+		t.Error("IsPaperTrading 应被覆盖为 false")
+	}
+	if cfg.DataSources["polygon"].APIKey != "secret" {
+		t.Errorf("APIKey = %q, 期望 secret", cfg.DataSources["polygon"].APIKey)
+	}
+	if !cfg.TradeLog.PartitionByAccount {
+		t.Error("PartitionByAccount 应被覆盖为 true")
+	}
+
+	env["QHFT_TRADING_LIMITS_MAX_DAILY_TRADES"] = "many"
+	if err := ApplyEnv(&cfg, EnvPrefix, lookup); err == nil {
+		t.Error("无效的整数值应返回错误")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Logging.Level = "verbose"
+	cfg.Trading.Limits.MaxPositionSizePercent = 150
+	cfg.Strategies["smallcap"] = StrategyConfig{Universe: indicators.Universe{Index: "russell2000", Filter: "dividend_yield > 0.03"}}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("无效配置应返回错误")
+	}
+
+	for _, want := range []string{"data source", "logging.level", "max_position_size_percent", "universe.index requires universes.dir", "universe.filter"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("错误信息应包含 %q: %v", want, err)
+		}
+	}
+}
+
+func TestValidatePaper(t *testing.T) {
+	cfg := Default()
+	cfg.DataSources["polygon"] = DataSourceConfig{
+		DataSourceConfig: datasource.DataSourceConfig{Name: "polygon", Enabled: true, APIKey: "key"},
+		Type:             DataSourceTypePolygon,
+	}
+	cfg.Trading.Broker.Name = BrokerFIX
+	cfg.Trading.Broker.IsPaperTrading = false
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("未配置FIX会话的实盘配置应返回错误")
+	}
+
+	cfg.Paper.Enabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("模拟模式不应要求券商配置: %v", err)
+	}
+
+	cfg.Paper.Data = PaperDataReplay
+	cfg.Paper.ReplayStart = "yesterday"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "paper.replay_start") {
+		t.Errorf("无效的回放起始时间应返回错误: %v", err)
+	}
+
+	cfg.Paper.ReplayStart = "2024-03-01"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("日期格式的回放起始时间应有效: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LookupEnvFunc 查找环境变量的函数类型，与os.LookupEnv签名一致
+type LookupEnvFunc func(key string) (string, bool)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ApplyEnv 使用环境变量覆盖配置中的字段
+// 变量名由前缀和YAML字段路径组成，以下划线连接并转为大写，例如：
+//
+//	QHFT_LOGGING_LEVEL=debug
+//	QHFT_TRADING_LIMITS_MAX_POSITIONS=10
+//	QHFT_DATASOURCES_POLYGON_API_KEY=xxx
+//
+// 对于map类型的配置（如datasources），只覆盖配置文件中已存在的条目
+func ApplyEnv(cfg *Config, prefix string, lookup LookupEnvFunc) error {
+	return applyEnv(reflect.ValueOf(cfg).Elem(), strings.ToUpper(prefix), lookup)
+}
+
+// applyEnv 递归处理结构体、map和基本类型字段（内部函数）
+func applyEnv(v reflect.Value, name string, lookup LookupEnvFunc) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			tag := strings.Split(field.Tag.Get("yaml"), ",")
+			if tag[0] == "-" {
+				continue
+			}
+
+			// 内联的嵌入结构体与外层共享前缀
+			fieldName := name
+			if len(tag) < 2 || tag[1] != "inline" {
+				key := tag[0]
+				if key == "" {
+					key = field.Name
+				}
+				fieldName = name + "_" + strings.ToUpper(key)
+			}
+
+			if err := applyEnv(v.Field(i), fieldName, lookup); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			if err := applyEnv(elem, name+"_"+strings.ToUpper(key.String()), lookup); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+		return nil
+	}
+
+	value, ok := lookup(name)
+	if !ok {
+		return nil
+	}
+	if err := setValue(v, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %v", name, err)
+	}
+	return nil
+}
+
+// setValue 将字符串解析为字段对应的类型并赋值（内部函数）
+func setValue(v reflect.Value, value string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	}
+	return nil
+}
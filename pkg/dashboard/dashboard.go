@@ -0,0 +1,388 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// Config 表示终端仪表盘配置
+type Config struct {
+	RefreshInterval time.Duration // 定时刷新间隔，用于更新持仓市值等不产生事件的数据
+	MaxFills        int           // 显示的最近成交数量
+}
+
+// Dashboard 是面向无图形界面服务器的终端仪表盘
+// 显示持仓及盈亏、未完成订单、监控列表状态、最近成交和日志尾部，
+// 由事件总线驱动刷新
+type Dashboard struct {
+	engine     trading.TradingEngine
+	bus        *events.Bus
+	watchlists []*trading.Watchlist
+	logTail    *LogTail
+	config     Config
+
+	app       *tview.Application
+	header    *tview.TextView
+	positions *tview.Table
+	orders    *tview.Table
+	watchlist *tview.Table
+	fills     *tview.Table
+	logs      *tview.TextView
+
+	mu          sync.Mutex
+	recentFills []trading.Execution
+	refreshCh   chan struct{}
+}
+
+// refreshTopics 是触发界面刷新的事件主题（内部变量）
+var refreshTopics = []string{events.TopicOrders, events.TopicFills, events.TopicPositions, events.TopicWatchlist}
+
+// snapshot 表示一次刷新时采集的数据
+type snapshot struct {
+	account   *trading.Account
+	positions []trading.Position
+	orders    []trading.Order
+	items     []trading.WatchlistItem
+	fills     []trading.Execution
+	logs      []string
+	err       error
+}
+
+// NewDashboard 创建一个新的终端仪表盘，logTail 和 watchlists 可以为空
+func NewDashboard(engine trading.TradingEngine, bus *events.Bus, logTail *LogTail, config Config, watchlists ...*trading.Watchlist) *Dashboard {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = time.Second
+	}
+	if config.MaxFills <= 0 {
+		config.MaxFills = 20
+	}
+
+	d := &Dashboard{
+		engine:     engine,
+		bus:        bus,
+		watchlists: watchlists,
+		logTail:    logTail,
+		config:     config,
+		refreshCh:  make(chan struct{}, 1),
+	}
+	d.buildLayout()
+	return d
+}
+
+// buildLayout 构建界面布局（内部方法）
+func (d *Dashboard) buildLayout() {
+	d.app = tview.NewApplication()
+
+	d.header = tview.NewTextView().SetDynamicColors(true)
+	d.positions = newTable("持仓")
+	d.orders = newTable("未完成订单")
+	d.watchlist = newTable("监控列表")
+	d.fills = newTable("最近成交")
+
+	d.logs = tview.NewTextView().SetDynamicColors(false).SetScrollable(true)
+	d.logs.SetBorder(true).SetTitle("日志")
+
+	top := tview.NewFlex().
+		AddItem(d.positions, 0, 3, false).
+		AddItem(d.orders, 0, 2, false)
+	middle := tview.NewFlex().
+		AddItem(d.watchlist, 0, 3, false).
+		AddItem(d.fills, 0, 2, false)
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.header, 1, 0, false).
+		AddItem(top, 0, 2, false).
+		AddItem(middle, 0, 2, false).
+		AddItem(d.logs, 0, 1, false)
+
+	d.app.SetRoot(root, true)
+	d.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'q' || event.Key() == tcell.KeyEscape {
+			d.app.Stop()
+			return nil
+		}
+		return event
+	})
+}
+
+// Run 运行仪表盘直到用户退出或上下文取消
+func (d *Dashboard) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if d.logTail != nil {
+		d.logTail.setOnWrite(d.requestRefresh)
+		defer d.logTail.setOnWrite(nil)
+	}
+
+	if d.bus != nil {
+		sub := d.bus.Subscribe(256, refreshTopics...)
+		defer sub.Close()
+		go d.consumeEvents(ctx, sub)
+	}
+
+	go d.refreshLoop(ctx)
+	go func() {
+		<-ctx.Done()
+		d.app.Stop()
+	}()
+
+	d.requestRefresh()
+	return d.app.Run()
+}
+
+// consumeEvents 处理事件总线上的事件（内部方法）
+func (d *Dashboard) consumeEvents(ctx context.Context, sub *events.Subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if execution, ok := evt.Payload.(trading.Execution); ok {
+				d.addFill(execution)
+			}
+			d.requestRefresh()
+		}
+	}
+}
+
+// addFill 记录一条最近成交（内部方法）
+func (d *Dashboard) addFill(execution trading.Execution) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.recentFills = append([]trading.Execution{execution}, d.recentFills...)
+	if len(d.recentFills) > d.config.MaxFills {
+		d.recentFills = d.recentFills[:d.config.MaxFills]
+	}
+}
+
+// requestRefresh 请求刷新界面，多个请求会被合并（内部方法）
+func (d *Dashboard) requestRefresh() {
+	select {
+	case d.refreshCh <- struct{}{}:
+	default:
+	}
+}
+
+// refreshLoop 定时或按请求刷新界面（内部方法）
+func (d *Dashboard) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(d.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.refreshCh:
+		}
+
+		snap := d.collect(ctx)
+		d.app.QueueUpdateDraw(func() {
+			d.render(snap)
+		})
+	}
+}
+
+// collect 采集当前数据（内部方法）
+func (d *Dashboard) collect(ctx context.Context) snapshot {
+	var snap snapshot
+
+	if d.engine != nil {
+		var err error
+		if snap.account, err = d.engine.GetAccount(ctx); err != nil {
+			snap.err = err
+		}
+		if snap.positions, err = d.engine.GetPositions(ctx); err != nil {
+			snap.err = err
+		}
+		if snap.orders, err = d.engine.GetOpenOrders(ctx); err != nil {
+			snap.err = err
+		}
+	}
+
+	for _, w := range d.watchlists {
+		if w != nil {
+			snap.items = append(snap.items, w.GetAllItems()...)
+		}
+	}
+
+	d.mu.Lock()
+	snap.fills = append(snap.fills, d.recentFills...)
+	d.mu.Unlock()
+
+	if d.logTail != nil {
+		snap.logs = d.logTail.Lines()
+	}
+
+	return snap
+}
+
+// render 将数据渲染到界面，必须在界面线程中调用（内部方法）
+func (d *Dashboard) render(snap snapshot) {
+	d.renderHeader(snap)
+
+	d.positions.Clear()
+	setHeaderRow(d.positions, "代码", "数量", "成本价", "现价", "市值", "浮动盈亏", "盈亏%")
+	for i, p := range snap.positions {
+		row := i + 1
+		color := pnlColor(p.UnrealizedPnL)
+		setRow(d.positions, row, tcell.ColorDefault,
+			p.Symbol,
+			fmt.Sprintf("%d", p.Quantity),
+			fmt.Sprintf("%.2f", p.EntryPrice),
+			fmt.Sprintf("%.2f", p.CurrentPrice),
+			fmt.Sprintf("%.2f", p.MarketValue))
+		setCell(d.positions, row, 5, fmt.Sprintf("%.2f", p.UnrealizedPnL), color)
+		setCell(d.positions, row, 6, fmt.Sprintf("%.2f%%", p.PnLPercent), color)
+	}
+
+	d.orders.Clear()
+	setHeaderRow(d.orders, "代码", "方向", "类型", "数量", "已成交", "价格", "状态")
+	for i, o := range snap.orders {
+		setRow(d.orders, i+1, tcell.ColorDefault,
+			o.Symbol,
+			string(o.Side),
+			string(o.Type),
+			fmt.Sprintf("%d", o.Quantity),
+			fmt.Sprintf("%d", o.FilledQty),
+			fmt.Sprintf("%.2f", o.Price),
+			string(o.Status))
+	}
+
+	d.watchlist.Clear()
+	setHeaderRow(d.watchlist, "代码", "列表", "目标价", "止损", "止盈", "数量", "状态", "策略")
+	for i, item := range snap.items {
+		list := "卖出"
+		if item.IsBuyList {
+			list = "买入"
+		}
+		setRow(d.watchlist, i+1, statusColor(item.Status),
+			item.Symbol,
+			list,
+			fmt.Sprintf("%.2f", item.TargetPrice),
+			fmt.Sprintf("%.2f", item.StopLoss),
+			fmt.Sprintf("%.2f", item.TakeProfit),
+			fmt.Sprintf("%d", item.Quantity),
+			string(item.Status),
+			item.Strategy)
+	}
+
+	d.fills.Clear()
+	setHeaderRow(d.fills, "时间", "代码", "方向", "数量", "价格")
+	for i, f := range snap.fills {
+		setRow(d.fills, i+1, tcell.ColorDefault,
+			f.ExecutedAt.Format("15:04:05"),
+			f.Symbol,
+			string(f.Side),
+			fmt.Sprintf("%d", f.Quantity),
+			fmt.Sprintf("%.2f", f.Price))
+	}
+
+	d.logs.SetText(strings.Join(snap.logs, "\n"))
+	d.logs.ScrollToEnd()
+}
+
+// renderHeader 渲染顶部账户摘要（内部方法）
+func (d *Dashboard) renderHeader(snap snapshot) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[::b]QHFT[::-] %s", time.Now().Format("2006-01-02 15:04:05"))
+
+	if snap.account != nil {
+		a := snap.account
+		fmt.Fprintf(&b, "  权益 %.2f  现金 %.2f  购买力 %.2f", a.Equity, a.Cash, a.BuyingPower)
+	}
+
+	var unrealized float64
+	for _, p := range snap.positions {
+		unrealized += p.UnrealizedPnL
+	}
+	fmt.Fprintf(&b, "  浮动盈亏 [%s]%.2f[-]", colorTag(pnlColor(unrealized)), unrealized)
+
+	if snap.err != nil {
+		fmt.Fprintf(&b, "  [red]%s[-]", tview.Escape(snap.err.Error()))
+	}
+	b.WriteString("  (q 退出)")
+
+	d.header.SetText(b.String())
+}
+
+// newTable 创建带边框的只读表格
+func newTable(title string) *tview.Table {
+	table := tview.NewTable().SetFixed(1, 0)
+	table.SetBorder(true).SetTitle(title)
+	return table
+}
+
+// setHeaderRow 设置表头行
+func setHeaderRow(table *tview.Table, headers ...string) {
+	for col, header := range headers {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+}
+
+// setRow 设置数据行
+func setRow(table *tview.Table, row int, color tcell.Color, values ...string) {
+	for col, value := range values {
+		setCell(table, row, col, value, color)
+	}
+}
+
+// setCell 设置单元格
+func setCell(table *tview.Table, row, col int, value string, color tcell.Color) {
+	table.SetCell(row, col, tview.NewTableCell(value).
+		SetTextColor(color).
+		SetExpansion(1))
+}
+
+// pnlColor 根据盈亏返回显示颜色
+func pnlColor(pnl float64) tcell.Color {
+	switch {
+	case pnl > 0:
+		return tcell.ColorGreen
+	case pnl < 0:
+		return tcell.ColorRed
+	default:
+		return tcell.ColorDefault
+	}
+}
+
+// statusColor 根据监控项状态返回显示颜色
+func statusColor(status trading.WatchlistItemStatus) tcell.Color {
+	switch status {
+	case trading.WatchStatusTriggered:
+		return tcell.ColorGreen
+	case trading.WatchStatusExpired, trading.WatchStatusInvalid:
+		return tcell.ColorGray
+	default:
+		return tcell.ColorDefault
+	}
+}
+
+// colorTag 返回颜色的tview标签名
+func colorTag(color tcell.Color) string {
+	switch color {
+	case tcell.ColorGreen:
+		return "green"
+	case tcell.ColorRed:
+		return "red"
+	default:
+		return "-"
+	}
+}
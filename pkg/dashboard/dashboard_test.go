@@ -0,0 +1,143 @@
+package dashboard
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// rowText 返回表格一行的单元格文本（内部函数）
+func rowText(table *tview.Table, row int) []string {
+	var cells []string
+	for col := 0; col < table.GetColumnCount(); col++ {
+		if cell := table.GetCell(row, col); cell != nil {
+			cells = append(cells, cell.Text)
+		}
+	}
+	return cells
+}
+
+func TestDashboardViews(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := events.NewBus()
+	broker := testutil.NewMockBroker("")
+	engine := trading.NewBaseTradingEngine(nil, trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	logs := NewLogTail(10)
+	d := NewDashboard(engine, bus, logs, Config{MaxFills: 1})
+	sub := bus.Subscribe(256, refreshTopics...)
+	defer sub.Close()
+	go d.consumeEvents(ctx, sub)
+
+	// 两笔成交只保留最近的一笔，挂单显示在未完成订单中
+	broker.SetPrice("AAPL", 100)
+	broker.SetPrice("MSFT", 200)
+	broker.Script(testutil.Fill{}, testutil.Fill{}, testutil.Accept())
+	place := func(req trading.OrderRequest) {
+		t.Helper()
+		if _, err := engine.PlaceOrder(ctx, req); err != nil {
+			t.Fatalf("下单失败: %v", err)
+		}
+	}
+	place(trading.OrderRequest{Symbol: "MSFT", Quantity: 5, Type: trading.OrderTypeMarket, Side: trading.OrderSideBuy})
+	place(trading.OrderRequest{Symbol: "AAPL", Quantity: 10, Type: trading.OrderTypeMarket, Side: trading.OrderSideBuy})
+	place(trading.OrderRequest{Symbol: "TSLA", Quantity: 3, Price: 150, Type: trading.OrderTypeLimit, Side: trading.OrderSideBuy})
+	engine.ApplyMarks(map[string]float64{"AAPL": 103, "MSFT": 198})
+	logs.Write([]byte("INFO 已下单\n"))
+
+	// 等待事件处理完成：最近成交为AAPL
+	deadline := time.After(2 * time.Second)
+	for {
+		d.mu.Lock()
+		done := len(d.recentFills) == 1 && d.recentFills[0].Symbol == "AAPL"
+		d.mu.Unlock()
+		if done {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("成交事件未送达仪表盘: %+v", d.recentFills)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	select {
+	case <-d.refreshCh:
+	default:
+		t.Error("事件应请求刷新界面")
+	}
+
+	snap := d.collect(ctx)
+	if snap.err != nil {
+		t.Fatalf("采集数据失败: %v", snap.err)
+	}
+	d.render(snap)
+
+	// 持仓表：按持仓估值显示市值和浮动盈亏，盈利为绿色、亏损为红色
+	rows := make(map[string][]string)
+	colors := make(map[string]tcell.Color)
+	for row := 1; row < d.positions.GetRowCount(); row++ {
+		cells := rowText(d.positions, row)
+		rows[cells[0]] = cells
+		colors[cells[0]] = d.positions.GetCell(row, 5).Color
+	}
+	if got := strings.Join(rows["AAPL"], " "); got != "AAPL 10 100.00 103.00 1030.00 30.00 3.00%" {
+		t.Errorf("AAPL持仓行不正确: %s", got)
+	}
+	if got := strings.Join(rows["MSFT"], " "); got != "MSFT 5 200.00 198.00 990.00 -10.00 -1.00%" {
+		t.Errorf("MSFT持仓行不正确: %s", got)
+	}
+	if colors["AAPL"] != tcell.ColorGreen || colors["MSFT"] != tcell.ColorRed {
+		t.Errorf("盈亏颜色不正确: %v", colors)
+	}
+
+	// 订单表只显示未完成订单
+	if d.orders.GetRowCount() != 2 {
+		t.Fatalf("应只有一个未完成订单: %d行", d.orders.GetRowCount())
+	}
+	if got := strings.Join(rowText(d.orders, 1), " "); got != "TSLA buy limit 3 0 150.00 accepted" {
+		t.Errorf("订单行不正确: %s", got)
+	}
+
+	// 最近成交和日志
+	if d.fills.GetRowCount() != 2 || !strings.HasSuffix(strings.Join(rowText(d.fills, 1), " "), "AAPL buy 10 100.00") {
+		t.Errorf("成交行不正确: %v", rowText(d.fills, 1))
+	}
+	if d.logs.GetText(true) != "INFO 已下单" {
+		t.Errorf("日志不正确: %q", d.logs.GetText(true))
+	}
+
+	// 顶部摘要：权益为现金加持仓市值，浮动盈亏为各持仓之和
+	header := d.header.GetText(false)
+	if !strings.Contains(header, "权益 100020.00") || !strings.Contains(header, "浮动盈亏 [green]20.00[-]") {
+		t.Errorf("顶部摘要不正确: %s", header)
+	}
+}
+
+func TestLogTail(t *testing.T) {
+	tail := NewLogTail(2)
+	refreshed := 0
+	tail.setOnWrite(func() { refreshed++ })
+
+	// 不完整的行等待换行符，空行被忽略，超过上限时丢弃最旧的行
+	tail.Write([]byte("first\nsec"))
+	tail.Write([]byte("ond\r\n\nthird\n"))
+	if lines := tail.Lines(); strings.Join(lines, ",") != "second,third" {
+		t.Errorf("日志行不正确: %v", lines)
+	}
+	if refreshed != 2 {
+		t.Errorf("每次写入应触发一次刷新: %d", refreshed)
+	}
+}
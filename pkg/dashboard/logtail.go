@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"bytes"
+	"sync"
+)
+
+// LogTail 是一个保存最近N行日志的io.Writer
+// 可以通过 logger.NewWriterLogger(config, tail) 将系统日志同时输出到仪表盘
+type LogTail struct {
+	mu      sync.Mutex
+	lines   []string
+	maxSize int
+	pending []byte // 尚未遇到换行符的部分数据
+	onWrite func()
+}
+
+// NewLogTail 创建一个新的日志尾部缓冲区
+func NewLogTail(maxLines int) *LogTail {
+	if maxLines <= 0 {
+		maxLines = 200
+	}
+
+	return &LogTail{
+		lines:   make([]string, 0, maxLines),
+		maxSize: maxLines,
+	}
+}
+
+// Write 实现io.Writer接口
+func (t *LogTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	t.pending = append(t.pending, p...)
+	for {
+		idx := bytes.IndexByte(t.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		line := string(bytes.TrimRight(t.pending[:idx], "\r"))
+		t.pending = t.pending[idx+1:]
+		if line == "" {
+			continue
+		}
+		if len(t.lines) >= t.maxSize {
+			t.lines = append(t.lines[:0], t.lines[1:]...)
+		}
+		t.lines = append(t.lines, line)
+	}
+	onWrite := t.onWrite
+	t.mu.Unlock()
+
+	if onWrite != nil {
+		onWrite()
+	}
+	return len(p), nil
+}
+
+// Lines 返回缓冲区中的日志行
+func (t *LogTail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := make([]string, len(t.lines))
+	copy(lines, t.lines)
+	return lines
+}
+
+// setOnWrite 设置写入日志后的回调（内部方法）
+func (t *LogTail) setOnWrite(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onWrite = fn
+}
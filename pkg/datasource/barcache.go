@@ -0,0 +1,126 @@
+package datasource
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BarCacheDataSource 包装数据源，按股票和周期在内存中缓存K线：请求范围已被缓存覆盖时直接返回，
+// 只缺少最新部分时从缓存的最后一根K线开始增量获取并合并（最后一根可能尚未走完，重新获取）。
+// 开盘前预先获取扫描所需的K线后，开盘后的扫描只需增量获取最新的K线。遵循上下文中的缓存指令（见 CacheControl）
+type BarCacheDataSource struct {
+	DataSource
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[barCacheKey]barCacheEntry
+}
+
+// barCacheKey 是K线缓存的键（内部类型）
+type barCacheKey struct {
+	symbol    string
+	timeframe string
+}
+
+// barCacheEntry 是一只股票一个周期的缓存K线（内部类型）
+type barCacheEntry struct {
+	from time.Time // 已获取的范围
+	to   time.Time // 不晚于获取时的当前时间，之后的K线尚未产生
+	bars []StockData
+}
+
+// NewBarCacheDataSource 创建缓存K线的数据源
+func NewBarCacheDataSource(source DataSource) *BarCacheDataSource {
+	return &BarCacheDataSource{
+		DataSource: source,
+		now:        time.Now,
+		entries:    make(map[barCacheKey]barCacheEntry),
+	}
+}
+
+// GetStockData 获取 [from, to] 内的K线，优先使用缓存，缓存之后的部分增量获取。
+// 数据源出错时，上下文接受过期数据则返回缓存中已有的K线
+func (c *BarCacheDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	cc := CacheControlFrom(ctx)
+	key := barCacheKey{symbol: symbol, timeframe: timeframe}
+	c.mu.Lock()
+	entry, cached := c.entries[key]
+	c.mu.Unlock()
+
+	if cached && !cc.NoCache && !from.Before(entry.from) {
+		if !to.After(entry.to) {
+			return barsBetween(entry.bars, from, to), nil
+		}
+
+		fetchFrom := entry.to
+		if n := len(entry.bars); n > 0 {
+			fetchFrom = entry.bars[n-1].Timestamp
+		}
+		fresh, err := c.DataSource.GetStockData(ctx, symbol, timeframe, fetchFrom, to)
+		if err != nil {
+			if cc.AcceptStale {
+				return barsBetween(entry.bars, from, to), nil
+			}
+			return nil, err
+		}
+		keep := sort.Search(len(entry.bars), func(i int) bool { return !entry.bars[i].Timestamp.Before(fetchFrom) })
+		merged := make([]StockData, 0, keep+len(fresh))
+		merged = append(merged, entry.bars[:keep]...)
+		merged = append(merged, fresh...)
+		c.store(key, barCacheEntry{from: entry.from, to: to, bars: merged})
+		return barsBetween(merged, from, to), nil
+	}
+
+	data, err := c.DataSource.GetStockData(ctx, symbol, timeframe, from, to)
+	if err != nil {
+		if cached && cc.AcceptStale {
+			return barsBetween(entry.bars, from, to), nil
+		}
+		return nil, err
+	}
+	c.store(key, barCacheEntry{from: from, to: to, bars: append([]StockData(nil), data...)})
+	return data, nil
+}
+
+// GetMultipleStockData 逐个通过缓存获取多只股票的K线并报告进度
+func (c *BarCacheDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]StockData, error) {
+	return FetchStockData(ctx, c.Name(), func(ctx context.Context, symbol string) ([]StockData, error) {
+		return c.GetStockData(ctx, symbol, timeframe, from, to)
+	}, symbols)
+}
+
+// Len 返回缓存的股票和周期组合数
+func (c *BarCacheDataSource) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Clear 清空缓存
+func (c *BarCacheDataSource) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[barCacheKey]barCacheEntry)
+}
+
+// store 写入缓存，缓存的范围不超过当前时间（内部方法）
+func (c *BarCacheDataSource) store(key barCacheKey, entry barCacheEntry) {
+	if now := c.now(); entry.to.After(now) {
+		entry.to = now
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
+
+// barsBetween 返回时间在 [from, to] 内的K线副本（内部函数）
+func barsBetween(bars []StockData, from, to time.Time) []StockData {
+	start := sort.Search(len(bars), func(i int) bool { return !bars[i].Timestamp.Before(from) })
+	end := sort.Search(len(bars), func(i int) bool { return bars[i].Timestamp.After(to) })
+	if start >= end {
+		return []StockData{}
+	}
+	return append([]StockData(nil), bars[start:end]...)
+}
@@ -0,0 +1,86 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// rangeSource 返回 [from, to] 内的预设K线并记录请求的范围
+type rangeSource struct {
+	DataSource
+	bars     []StockData
+	requests [][2]time.Time
+	err      error
+}
+
+func (s *rangeSource) Name() string { return "range" }
+
+func (s *rangeSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	s.requests = append(s.requests, [2]time.Time{from, to})
+	if s.err != nil {
+		return nil, s.err
+	}
+	var data []StockData
+	for _, bar := range s.bars {
+		if !bar.Timestamp.Before(from) && !bar.Timestamp.After(to) {
+			data = append(data, bar)
+		}
+	}
+	return data, nil
+}
+
+func TestBarCacheDataSource(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	bar := func(i int, price float64) StockData {
+		return StockData{Symbol: "AAPL", Timestamp: start.Add(time.Duration(i) * time.Minute), Close: price}
+	}
+	source := &rangeSource{bars: []StockData{bar(0, 100), bar(1, 101), bar(2, 102)}}
+	cache := NewBarCacheDataSource(source)
+	now := start.Add(2 * time.Minute)
+	cache.now = func() time.Time { return now }
+
+	// 预热：获取到当前时间为止的K线
+	if data, err := cache.GetStockData(ctx, "AAPL", "minute", start, now.Add(time.Hour)); err != nil || len(data) != 3 {
+		t.Fatalf("首次获取应返回全部K线: %v %v", data, err)
+	}
+	// 缓存覆盖的范围不再请求数据源
+	if data, _ := cache.GetStockData(ctx, "AAPL", "minute", start.Add(time.Minute), now); len(data) != 2 || len(source.requests) != 1 {
+		t.Errorf("缓存覆盖的请求不应访问数据源: %v, 请求 %d 次", data, len(source.requests))
+	}
+
+	// 最后一根K线更新并产生新K线后，只从缓存的最后一根开始增量获取
+	source.bars = []StockData{bar(0, 100), bar(1, 101), bar(2, 102.5), bar(3, 103)}
+	now = start.Add(3 * time.Minute)
+	data, err := cache.GetStockData(ctx, "AAPL", "minute", start, now)
+	if err != nil || len(data) != 4 || data[2].Close != 102.5 || data[3].Close != 103 {
+		t.Fatalf("增量获取应合并最新K线: %v %v", data, err)
+	}
+	if last := source.requests[len(source.requests)-1]; !last[0].Equal(start.Add(2 * time.Minute)) {
+		t.Errorf("增量请求应从缓存的最后一根K线开始: %v", last)
+	}
+
+	// 数据源出错时，接受过期数据的请求返回缓存
+	source.err = errors.New("timeout")
+	now = start.Add(4 * time.Minute)
+	if _, err := cache.GetStockData(ctx, "AAPL", "minute", start, now); err == nil {
+		t.Error("不接受过期数据时应返回错误")
+	}
+	if data, err := cache.GetStockData(AcceptStale(ctx), "AAPL", "minute", start, now); err != nil || len(data) != 4 {
+		t.Errorf("接受过期数据时应返回缓存: %v %v", data, err)
+	}
+
+	// 强制刷新和更早的起点都重新完整获取
+	source.err = nil
+	requests := len(source.requests)
+	cache.GetStockData(ForceRefresh(ctx), "AAPL", "minute", start, start.Add(time.Minute))
+	cache.GetStockData(ctx, "AAPL", "minute", start.Add(-time.Hour), start)
+	if len(source.requests) != requests+2 || !source.requests[requests+1][0].Equal(start.Add(-time.Hour)) {
+		t.Errorf("强制刷新和缓存范围之外的请求应访问数据源: %v", source.requests[requests:])
+	}
+	if cache.Len() != 1 {
+		t.Errorf("缓存数量 = %d, 期望 1", cache.Len())
+	}
+}
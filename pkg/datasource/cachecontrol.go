@@ -0,0 +1,38 @@
+package datasource
+
+import "context"
+
+// CacheControl 是单次数据读取的缓存指令，通过上下文传给带缓存的数据源
+// 风控等关键路径使用 NoCache 绕过缓存，研究和扫描等路径使用 AcceptStale 在数据源出错时容忍过期数据
+type CacheControl struct {
+	NoCache     bool `json:"no_cache"`     // 不读取缓存，强制从数据源获取，获取结果仍写入缓存
+	AcceptStale bool `json:"accept_stale"` // 数据源出错时返回已过期的缓存数据，而不是错误
+}
+
+// cacheControlKey 是上下文中缓存指令的键（内部类型）
+type cacheControlKey struct{}
+
+// WithCacheControl 返回带有缓存指令的上下文
+func WithCacheControl(ctx context.Context, cc CacheControl) context.Context {
+	return context.WithValue(ctx, cacheControlKey{}, cc)
+}
+
+// ForceRefresh 返回不读取缓存的上下文
+func ForceRefresh(ctx context.Context) context.Context {
+	cc := CacheControlFrom(ctx)
+	cc.NoCache = true
+	return WithCacheControl(ctx, cc)
+}
+
+// AcceptStale 返回在数据源出错时接受过期缓存的上下文
+func AcceptStale(ctx context.Context) context.Context {
+	cc := CacheControlFrom(ctx)
+	cc.AcceptStale = true
+	return WithCacheControl(ctx, cc)
+}
+
+// CacheControlFrom 返回上下文中的缓存指令，没有设置时为零值，即正常使用缓存
+func CacheControlFrom(ctx context.Context) CacheControl {
+	cc, _ := ctx.Value(cacheControlKey{}).(CacheControl)
+	return cc
+}
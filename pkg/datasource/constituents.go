@@ -0,0 +1,139 @@
+package datasource
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Constituent 表示指数或ETF的一只成分股
+type Constituent struct {
+	Symbol string  `json:"symbol"`
+	Name   string  `json:"name,omitempty"`
+	Weight float64 `json:"weight,omitempty"` // 权重百分比，未知时为0
+}
+
+// ConstituentProvider 定义了指数和ETF成分股数据的接口
+type ConstituentProvider interface {
+	// GetConstituents 返回指数或ETF的成分股，名称如 sp500、qqq
+	GetConstituents(ctx context.Context, index string) ([]Constituent, error)
+
+	// Indexes 返回可用的指数和ETF名称
+	Indexes(ctx context.Context) ([]string, error)
+}
+
+// ConstituentSymbols 返回成分股的股票代码
+func ConstituentSymbols(constituents []Constituent) []string {
+	symbols := make([]string, 0, len(constituents))
+	for _, c := range constituents {
+		symbols = append(symbols, c.Symbol)
+	}
+	return symbols
+}
+
+// FileConstituents 从目录中的静态文件读取成分股，每个指数或ETF一个 <名称>.csv 文件
+// 文件第一行为表头时按 symbol、name、weight 列读取，否则只读取第一列的股票代码；# 开头的行为注释
+type FileConstituents struct {
+	dir string
+}
+
+// NewFileConstituents 创建基于文件的成分股数据
+func NewFileConstituents(dir string) *FileConstituents {
+	return &FileConstituents{dir: dir}
+}
+
+// GetConstituents 读取指数或ETF的成分股文件，名称不区分大小写
+func (f *FileConstituents) GetConstituents(ctx context.Context, index string) ([]Constituent, error) {
+	name := strings.ToLower(strings.TrimSpace(index))
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid index name %q", index)
+	}
+
+	file, err := os.Open(filepath.Join(f.dir, name+".csv"))
+	if os.IsNotExist(err) {
+		return nil, &DataSourceError{
+			Source:  "constituents",
+			Code:    "NO_DATA",
+			Message: fmt.Sprintf("no constituents for %s", index),
+			Time:    time.Now(),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open constituents of %s: %v", index, err)
+	}
+	defer file.Close()
+
+	constituents, err := parseConstituents(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse constituents of %s: %v", index, err)
+	}
+	return constituents, nil
+}
+
+// Indexes 返回目录中有成分股文件的指数和ETF名称
+func (f *FileConstituents) Indexes(ctx context.Context) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(f.dir, "*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]string, 0, len(matches))
+	for _, match := range matches {
+		indexes = append(indexes, strings.TrimSuffix(filepath.Base(match), ".csv"))
+	}
+	sort.Strings(indexes)
+	return indexes, nil
+}
+
+// parseConstituents 解析成分股CSV，重复的股票代码只保留第一次出现（内部函数）
+func parseConstituents(r io.Reader) ([]Constituent, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{"symbol": 0, "name": -1, "weight": -1}
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "symbol") {
+		for i, field := range records[0] {
+			columns[strings.ToLower(strings.TrimSpace(field))] = i
+		}
+		records = records[1:]
+	}
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	seen := make(map[string]bool, len(records))
+	constituents := make([]Constituent, 0, len(records))
+	for line, record := range records {
+		symbol := strings.ToUpper(field(record, "symbol"))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+
+		c := Constituent{Symbol: symbol, Name: field(record, "name")}
+		if weight := field(record, "weight"); weight != "" {
+			if c.Weight, err = strconv.ParseFloat(strings.TrimSuffix(weight, "%"), 64); err != nil {
+				return nil, fmt.Errorf("invalid weight %q for %s on record %d", weight, symbol, line+1)
+			}
+		}
+		constituents = append(constituents, c)
+	}
+	return constituents, nil
+}
@@ -0,0 +1,56 @@
+package datasource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileConstituents(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	files := map[string]string{
+		"sp500.csv": "# S&P 500 成分股\nAAPL\nmsft\nAAPL\n\nNVDA\n",
+		"qqq.csv":   "symbol,name,weight\nMSFT,Microsoft Corp,8.9%\nAAPL,Apple Inc,7.5\n",
+		"bad.csv":   "symbol,weight\nAAPL,abc\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	provider := NewFileConstituents(dir)
+
+	sp500, err := provider.GetConstituents(ctx, "SP500")
+	if err != nil {
+		t.Fatalf("读取成分股失败: %v", err)
+	}
+	if got := ConstituentSymbols(sp500); !reflect.DeepEqual(got, []string{"AAPL", "MSFT", "NVDA"}) {
+		t.Errorf("成分股 = %v, 期望去重、转大写并忽略注释", got)
+	}
+
+	qqq, err := provider.GetConstituents(ctx, "qqq")
+	if err != nil {
+		t.Fatalf("读取成分股失败: %v", err)
+	}
+	if len(qqq) != 2 || qqq[0] != (Constituent{Symbol: "MSFT", Name: "Microsoft Corp", Weight: 8.9}) || qqq[1].Weight != 7.5 {
+		t.Errorf("带表头的成分股不正确: %+v", qqq)
+	}
+
+	if _, err := provider.GetConstituents(ctx, "bad"); err == nil {
+		t.Error("无效的权重应返回错误")
+	}
+	if _, err := provider.GetConstituents(ctx, "dow30"); err == nil {
+		t.Error("不存在的指数应返回错误")
+	}
+	if _, err := provider.GetConstituents(ctx, "../sp500"); err == nil {
+		t.Error("包含路径的名称应返回错误")
+	}
+
+	indexes, err := provider.Indexes(ctx)
+	if err != nil || !reflect.DeepEqual(indexes, []string{"bad", "qqq", "sp500"}) {
+		t.Errorf("可用指数 = %v %v", indexes, err)
+	}
+}
@@ -0,0 +1,188 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// CorporateActionsMaxAge 是管理器缓存股票公司行动的时间
+const CorporateActionsMaxAge = 12 * time.Hour
+
+// Split 表示一次拆股或合股，From 股变为 To 股，如4拆1为 From=1 To=4，10合1为 From=10 To=1
+type Split struct {
+	Symbol string    `json:"symbol"`
+	ExDate time.Time `json:"ex_date"` // 除权日零点（UTC），此前的K线按拆股比例调整
+	From   float64   `json:"from"`
+	To     float64   `json:"to"`
+}
+
+// Ratio 返回拆股后每股对应的股数，比例无效时返回1
+func (s Split) Ratio() float64 {
+	if s.From <= 0 || s.To <= 0 {
+		return 1
+	}
+	return s.To / s.From
+}
+
+// CashDividend 表示一次现金分红
+type CashDividend struct {
+	Symbol     string    `json:"symbol"`
+	ExDate     time.Time `json:"ex_date"`            // 除息日零点（UTC）
+	PayDate    time.Time `json:"pay_date,omitempty"` // 派息日，数据源不提供时为零值
+	CashAmount float64   `json:"cash_amount"`        // 每股分红，按除息日当时的股本计算
+}
+
+// CorporateActions 表示股票的拆股和现金分红，均按除权除息日升序排列
+type CorporateActions struct {
+	Symbol    string         `json:"symbol"`
+	Splits    []Split        `json:"splits"`
+	Dividends []CashDividend `json:"dividends"`
+}
+
+// Between 返回除权除息日在[from, to]内的公司行动，from 和 to 为零值时不限制
+func (c *CorporateActions) Between(from, to time.Time) *CorporateActions {
+	in := func(t time.Time) bool {
+		return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+	}
+	result := &CorporateActions{Symbol: c.Symbol, Splits: []Split{}, Dividends: []CashDividend{}}
+	for _, split := range c.Splits {
+		if in(split.ExDate) {
+			result.Splits = append(result.Splits, split)
+		}
+	}
+	for _, dividend := range c.Dividends {
+		if in(dividend.ExDate) {
+			result.Dividends = append(result.Dividends, dividend)
+		}
+	}
+	return result
+}
+
+// sort 将公司行动按除权除息日升序排列（内部方法）
+func (c *CorporateActions) sort() {
+	sort.SliceStable(c.Splits, func(i, j int) bool {
+		return c.Splits[i].ExDate.Before(c.Splits[j].ExDate)
+	})
+	sort.SliceStable(c.Dividends, func(i, j int) bool {
+		return c.Dividends[i].ExDate.Before(c.Dividends[j].ExDate)
+	})
+}
+
+// CorporateActionProvider 提供股票的公司行动，所有数据源和数据源管理器都实现了该接口
+type CorporateActionProvider interface {
+	// GetCorporateActions 获取除权除息日在[from, to]内的拆股和现金分红，from 和 to 为零值时不限制
+	GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error)
+}
+
+// Adjustment 表示K线的复权方式
+type Adjustment string
+
+// 复权方式常量，复权以最新的股本为基准向前调整历史K线，最近一次公司行动之后的K线保持不变
+const (
+	AdjustNone   Adjustment = "none"   // 不复权，返回数据源的原始K线
+	AdjustSplits Adjustment = "splits" // 按拆股调整历史价格和成交量
+	AdjustAll    Adjustment = "all"    // 在拆股的基础上按现金分红调整历史价格
+)
+
+// ParseAdjustment 解析复权方式，空字符串为 AdjustSplits
+func ParseAdjustment(s string) (Adjustment, error) {
+	switch Adjustment(s) {
+	case "":
+		return AdjustSplits, nil
+	case AdjustNone, AdjustSplits, AdjustAll:
+		return Adjustment(s), nil
+	}
+	return "", fmt.Errorf("invalid adjustment %q (want none, splits or all)", s)
+}
+
+// adjustmentKey 是上下文中复权方式的键（内部类型）
+type adjustmentKey struct{}
+
+// WithAdjustment 返回指定复权方式的上下文，管理器的 GetStockData 按该方式调整K线
+func WithAdjustment(ctx context.Context, adjustment Adjustment) context.Context {
+	return context.WithValue(ctx, adjustmentKey{}, adjustment)
+}
+
+// AdjustmentFrom 返回上下文中的复权方式，没有设置时 ok 为false
+func AdjustmentFrom(ctx context.Context) (adjustment Adjustment, ok bool) {
+	adjustment, ok = ctx.Value(adjustmentKey{}).(Adjustment)
+	return adjustment, ok
+}
+
+// AdjustStockData 按公司行动返回复权后的K线副本，输入的K线应按时间升序排列且未经复权。
+// 拆股时除权日之前的价格除以拆股比例、成交量乘以拆股比例；分红时除息日之前的价格乘以
+// 1 - 分红 / 除息日前一根K线的收盘价。没有需要调整的公司行动时原样返回
+func AdjustStockData(data []StockData, actions *CorporateActions, adjustment Adjustment) []StockData {
+	if actions == nil || adjustment == AdjustNone || len(data) == 0 {
+		return data
+	}
+
+	type factor struct {
+		at     time.Time
+		price  float64
+		volume float64
+	}
+	var factors []factor
+	for _, split := range actions.Splits {
+		if ratio := split.Ratio(); ratio != 1 {
+			factors = append(factors, factor{at: split.ExDate, price: 1 / ratio, volume: ratio})
+		}
+	}
+	if adjustment == AdjustAll {
+		for _, dividend := range actions.Dividends {
+			// 除息日前一根K线的收盘价，与分红金额使用同一股本
+			i := sort.Search(len(data), func(i int) bool {
+				return !data[i].Timestamp.Before(dividend.ExDate)
+			})
+			if i == 0 || dividend.CashAmount <= 0 || data[i-1].Close <= dividend.CashAmount {
+				continue
+			}
+			factors = append(factors, factor{at: dividend.ExDate, price: 1 - dividend.CashAmount/data[i-1].Close, volume: 1})
+		}
+	}
+	if len(factors) == 0 {
+		return data
+	}
+	sort.Slice(factors, func(i, j int) bool {
+		return factors[i].at.After(factors[j].at)
+	})
+
+	// 从最新的K线向前累乘除权除息日晚于K线的调整系数
+	adjusted := make([]StockData, len(data))
+	copy(adjusted, data)
+	price, volume := 1.0, 1.0
+	next := 0
+	for i := len(adjusted) - 1; i >= 0; i-- {
+		bar := &adjusted[i]
+		for next < len(factors) && bar.Timestamp.Before(factors[next].at) {
+			price *= factors[next].price
+			volume *= factors[next].volume
+			next++
+		}
+		if price == 1 && volume == 1 {
+			continue
+		}
+		bar.Open *= price
+		bar.High *= price
+		bar.Low *= price
+		bar.Close *= price
+		bar.VWAP *= price
+		bar.Volume = int64(math.Round(float64(bar.Volume) * volume))
+	}
+	return adjusted
+}
+
+// cachedCorporateActions 是管理器缓存的完整公司行动（内部类型）
+type cachedCorporateActions struct {
+	actions   *CorporateActions
+	fetchedAt time.Time
+}
+
+// unsupportedActions 判断错误是否表示数据源没有公司行动数据（内部函数）
+func unsupportedActions(err error) bool {
+	dsErr, ok := err.(*DataSourceError)
+	return ok && (dsErr.Code == "NOT_SUPPORTED" || dsErr.Code == "NO_DATA")
+}
@@ -0,0 +1,155 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// actionSource 返回固定K线和公司行动的数据源
+type actionSource struct {
+	DataSource
+	bars    []StockData
+	actions *CorporateActions
+	err     error
+	calls   int
+}
+
+func (s *actionSource) Name() string    { return "actions" }
+func (s *actionSource) IsEnabled() bool { return true }
+func (s *actionSource) Close() error    { return nil }
+
+func (s *actionSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	return s.bars, nil
+}
+
+func (s *actionSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.actions.Between(from, to), nil
+}
+
+// dailyBars 返回从 start 开始每天一根、收盘价依次为 closes 的K线
+func dailyBars(start time.Time, closes ...float64) []StockData {
+	bars := make([]StockData, len(closes))
+	for i, close := range closes {
+		bars[i] = StockData{Symbol: "AAPL", Timestamp: start.AddDate(0, 0, i).Add(4 * time.Hour), Open: close, High: close, Low: close, Close: close, Volume: 1000}
+	}
+	return bars
+}
+
+func TestAdjustStockData(t *testing.T) {
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	bars := dailyBars(day, 400, 404, 101, 100, 99)
+	actions := &CorporateActions{
+		Symbol:    "AAPL",
+		Splits:    []Split{{Symbol: "AAPL", ExDate: day.AddDate(0, 0, 2), From: 1, To: 4}},
+		Dividends: []CashDividend{{Symbol: "AAPL", ExDate: day.AddDate(0, 0, 4), CashAmount: 1}},
+	}
+
+	split := AdjustStockData(bars, actions, AdjustSplits)
+	if split[0].Close != 100 || split[1].Close != 101 || split[0].Volume != 4000 || split[2].Volume != 1000 {
+		t.Errorf("拆股前的K线应按4拆1调整: %+v", split)
+	}
+	if bars[0].Close != 400 {
+		t.Error("复权不应修改输入的K线")
+	}
+
+	// 除息日前一天收盘100，分红1美元，之前的价格乘以0.99
+	all := AdjustStockData(bars, actions, AdjustAll)
+	if math.Abs(all[0].Close-99) > 1e-9 || math.Abs(all[3].Close-99) > 1e-9 || all[4].Close != 99 || all[0].Volume != 4000 {
+		t.Errorf("分红复权不正确: %+v", all)
+	}
+	if none := AdjustStockData(bars, actions, AdjustNone); none[0].Close != 400 {
+		t.Errorf("不复权时应返回原始K线: %+v", none)
+	}
+}
+
+func TestManagerAdjustsStockData(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	source := &actionSource{
+		bars: dailyBars(day, 200, 100),
+		actions: &CorporateActions{Symbol: "AAPL", Splits: []Split{
+			{Symbol: "AAPL", ExDate: day.AddDate(0, 0, 1), From: 1, To: 2},
+			{Symbol: "AAPL", ExDate: day.AddDate(-5, 0, 0), From: 1, To: 7},
+		}},
+	}
+	m := NewManager()
+	m.AddDataSource(source)
+
+	// 默认按拆股复权
+	data, err := m.GetStockData(ctx, "AAPL", "day", day, day.AddDate(0, 0, 1))
+	if err != nil || data[0].Close != 100 || data[0].Volume != 2000 {
+		t.Fatalf("默认应按拆股复权: %+v %v", data, err)
+	}
+	if data, _ := m.GetStockData(WithAdjustment(ctx, AdjustNone), "AAPL", "day", day, day); data[0].Close != 200 {
+		t.Errorf("上下文指定不复权时应返回原始K线: %+v", data)
+	}
+
+	// 公司行动按股票缓存，查询时按除权日过滤并排序
+	actions, err := m.GetCorporateActions(ctx, "AAPL", day, time.Time{})
+	if err != nil || len(actions.Splits) != 1 || actions.Splits[0].Ratio() != 2 || source.calls != 1 {
+		t.Errorf("公司行动应从缓存中过滤: %+v %v calls=%d", actions, err, source.calls)
+	}
+	if all, _ := m.GetCorporateActions(ctx, "AAPL", time.Time{}, time.Time{}); len(all.Splits) != 2 || all.Splits[0].From != 1 || all.Splits[0].To != 7 {
+		t.Errorf("公司行动应按除权日升序排列: %+v", all)
+	}
+
+	// 数据源不提供公司行动时返回原始K线，其他错误返回错误
+	source.err = &DataSourceError{Code: "NOT_SUPPORTED", Message: "not supported"}
+	if data, err := m.GetStockData(ctx, "MSFT", "day", day, day); err != nil || data[0].Close != 200 {
+		t.Errorf("不提供公司行动时应返回原始K线: %+v %v", data, err)
+	}
+	source.err = fmt.Errorf("timeout")
+	if _, err := m.GetStockData(ctx, "NVDA", "day", day, day); err == nil {
+		t.Error("获取公司行动失败时应返回错误")
+	}
+}
+
+func TestPolygonGetCorporateActions(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("apiKey") != "key" || r.URL.Query().Get("ticker") != "AAPL" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/v3/reference/splits":
+			if r.URL.Query().Get("execution_date.gte") != "2020-01-01" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"status":"OK","results":[{"execution_date":"2020-08-31","split_from":1,"split_to":4,"ticker":"AAPL"}]}`)
+		case "/v3/reference/dividends":
+			if r.URL.Query().Get("cursor") == "" {
+				fmt.Fprintf(w, `{"status":"OK","results":[{"cash_amount":0.24,"ex_dividend_date":"2024-05-10","pay_date":"2024-05-16"}],
+					"next_url":"%s/v3/reference/dividends?cursor=2&ticker=AAPL"}`, server.URL)
+				return
+			}
+			fmt.Fprint(w, `{"status":"OK","results":[{"cash_amount":0.23,"ex_dividend_date":"2024-02-09","pay_date":"2024-02-15"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	source, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL, APIKey: "key"})
+	actions, err := source.GetCorporateActions(context.Background(), "AAPL", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	if err != nil {
+		t.Fatalf("获取公司行动失败: %v", err)
+	}
+	if len(actions.Splits) != 1 || actions.Splits[0].Ratio() != 4 || !actions.Splits[0].ExDate.Equal(time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("拆股不正确: %+v", actions.Splits)
+	}
+	// 分页获取的分红按除息日升序排列
+	if len(actions.Dividends) != 2 || actions.Dividends[0].CashAmount != 0.23 || actions.Dividends[1].PayDate.Day() != 16 {
+		t.Errorf("分红不正确: %+v", actions.Dividends)
+	}
+}
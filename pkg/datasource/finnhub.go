@@ -0,0 +1,422 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// FinnhubDefaultBaseURL 是Finnhub REST API的默认地址
+const FinnhubDefaultBaseURL = "https://finnhub.io/api/v1"
+
+// FinnhubDefaultRequestsPerMinute 是Finnhub免费版每个密钥每分钟的请求限额
+const FinnhubDefaultRequestsPerMinute = 60
+
+// finnhubResolutions 是K线周期到Finnhub分辨率参数的映射
+var finnhubResolutions = map[string]string{
+	"minute": "1",
+	"hour":   "60",
+	"day":    "D",
+	"week":   "W",
+	"month":  "M",
+}
+
+// FinnhubDataSource 实现了Finnhub数据源（K线和实时报价）
+// 所有请求经过限速器，按每分钟的请求限额排队发出，批量获取时放慢速度而不是返回限流错误
+type FinnhubDataSource struct {
+	config     DataSourceConfig
+	keys       *keyPool
+	pacer      *requestPacer
+	httpClient *http.Client
+}
+
+// NewFinnhubDataSource 创建一个新的Finnhub数据源，BaseURL为空时使用 FinnhubDefaultBaseURL
+// 请求限额按密钥数累加：配置多个密钥时每分钟可以发出 RequestsPerMinute × 密钥数 个请求
+func NewFinnhubDataSource(config DataSourceConfig) (*FinnhubDataSource, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = FinnhubDefaultBaseURL
+	}
+	if config.TimeoutSeconds <= 0 {
+		config.TimeoutSeconds = 30 // 默认30秒超时
+	}
+	config.Timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	if config.RequestsPerMinute <= 0 {
+		config.RequestsPerMinute = FinnhubDefaultRequestsPerMinute
+	}
+
+	cooldown := time.Duration(config.KeyCooldownSeconds) * time.Second
+	if cooldown <= 0 {
+		cooldown = DefaultKeyCooldown
+	}
+	keys := newKeyPool(append([]string{config.APIKey}, config.APIKeys...), config.KeySelection, cooldown)
+	limit := config.RequestsPerMinute * max(len(keys.keys), 1)
+	pacer := newRequestPacer(limit, time.Minute)
+	httpClient := &http.Client{
+		Timeout: config.Timeout,
+		Transport: &pacedTransport{
+			pacer:    pacer,
+			cooldown: cooldown,
+			base:     &keyTransport{pool: keys, base: http.DefaultTransport, param: "token"},
+		},
+	}
+
+	return &FinnhubDataSource{
+		config:     config,
+		keys:       keys,
+		pacer:      pacer,
+		httpClient: httpClient,
+	}, nil
+}
+
+// KeyStatus 返回每个API密钥的请求次数和停用情况
+func (f *FinnhubDataSource) KeyStatus() []KeyStatus {
+	return f.keys.status()
+}
+
+// Name 返回数据源名称
+func (f *FinnhubDataSource) Name() string {
+	return "finnhub"
+}
+
+// IsEnabled 检查数据源是否启用
+func (f *FinnhubDataSource) IsEnabled() bool {
+	return f.config.Enabled
+}
+
+// HealthCheck 通过市场状态接口检查Finnhub API的连接状态
+func (f *FinnhubDataSource) HealthCheck(ctx context.Context) (bool, error) {
+	var status struct {
+		Exchange string `json:"exchange"`
+	}
+	if err := f.get(ctx, "/stock/market-status", url.Values{"exchange": {"US"}}, &status); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetStockData 通过 /stock/candle 接口获取K线，支持 minute、hour、day、week 和 month 周期
+func (f *FinnhubDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	resolution, ok := finnhubResolutions[timeframe]
+	if !ok {
+		return nil, &DataSourceError{
+			Source:  f.Name(),
+			Code:    "UNSUPPORTED_TIMEFRAME",
+			Message: fmt.Sprintf("timeframe %q is not supported", timeframe),
+			Time:    time.Now(),
+		}
+	}
+
+	var result struct {
+		Status string    `json:"s"` // ok 或 no_data
+		T      []int64   `json:"t"` // 时间戳（秒）
+		O      []float64 `json:"o"` // 开盘价
+		H      []float64 `json:"h"` // 最高价
+		L      []float64 `json:"l"` // 最低价
+		C      []float64 `json:"c"` // 收盘价
+		V      []float64 `json:"v"` // 成交量
+	}
+	query := url.Values{
+		"symbol":     {symbol},
+		"resolution": {resolution},
+		"from":       {strconv.FormatInt(from.Unix(), 10)},
+		"to":         {strconv.FormatInt(to.Unix(), 10)},
+	}
+	if err := f.get(ctx, "/stock/candle", query, &result); err != nil {
+		return nil, err
+	}
+	if result.Status == "no_data" {
+		return []StockData{}, nil
+	}
+
+	// 各字段是等长的数组，长度不一致时只取共同部分
+	n := min(len(result.T), len(result.O), len(result.H), len(result.L), len(result.C), len(result.V))
+	stockData := make([]StockData, 0, n)
+	for i := 0; i < n; i++ {
+		stockData = append(stockData, StockData{
+			Symbol:        symbol,
+			Timestamp:     time.Unix(result.T[i], 0),
+			Open:          result.O[i],
+			High:          result.H[i],
+			Low:           result.L[i],
+			Close:         result.C[i],
+			Volume:        int64(result.V[i]),
+			TransactionID: fmt.Sprintf("finnhub_%s_%d", symbol, result.T[i]),
+		})
+	}
+	return stockData, nil
+}
+
+// GetMultipleStockData 逐个获取多只股票的K线并报告进度，请求按限额排队，不会触发限流
+func (f *FinnhubDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]StockData, error) {
+	return FetchStockData(ctx, f.Name(), func(ctx context.Context, symbol string) ([]StockData, error) {
+		return f.GetStockData(ctx, symbol, timeframe, from, to)
+	}, symbols)
+}
+
+// GetRealTimeQuote 通过 /quote 接口获取最新价，Finnhub不提供买卖报价
+func (f *FinnhubDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	var result struct {
+		C  float64 `json:"c"`  // 最新价
+		PC float64 `json:"pc"` // 前收盘价
+		T  int64   `json:"t"`  // 时间戳（秒）
+	}
+	if err := f.get(ctx, "/quote", url.Values{"symbol": {symbol}}, &result); err != nil {
+		return nil, err
+	}
+	// 未知的股票返回全部为0的报价
+	if result.C == 0 && result.T == 0 {
+		return nil, &DataSourceError{
+			Source:  f.Name(),
+			Code:    "NO_DATA",
+			Message: fmt.Sprintf("no quote for %s", symbol),
+			Time:    time.Now(),
+		}
+	}
+
+	return &Quote{
+		Symbol:        symbol,
+		Timestamp:     time.Unix(result.T, 0),
+		LastPrice:     result.C,
+		RegularClose:  result.PC,
+		TransactionID: fmt.Sprintf("finnhub_%s_%d", symbol, result.T),
+	}, nil
+}
+
+// GetRealTimeQuotes 逐个获取报价，Finnhub没有批量报价接口，请求按限额排队
+func (f *FinnhubDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	return FetchQuotes(ctx, f.GetRealTimeQuote, symbols, 1)
+}
+
+// GetTrades Finnhub免费版不提供逐笔成交
+func (f *FinnhubDataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]TradePrint, error) {
+	return nil, &DataSourceError{
+		Source:  f.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "finnhub does not provide trade prints",
+		Time:    time.Now(),
+	}
+}
+
+// GetOptionChain Finnhub免费版不提供期权链
+func (f *FinnhubDataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]OptionQuote, error) {
+	return nil, &DataSourceError{
+		Source:  f.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "finnhub does not provide option chains",
+		Time:    time.Now(),
+	}
+}
+
+// GetFundamentals 通过 /stock/profile2 接口获取市值、股本和行业，通过 /stock/metric 接口获取TTM市盈率和每股收益，
+// Finnhub不提供自由流通股，FloatShares 为0
+func (f *FinnhubDataSource) GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	var profile struct {
+		Name              string  `json:"name"`
+		Industry          string  `json:"finnhubIndustry"`
+		MarketCap         float64 `json:"marketCapitalization"` // 百万美元
+		SharesOutstanding float64 `json:"shareOutstanding"`     // 百万股
+	}
+	if err := f.get(ctx, "/stock/profile2", url.Values{"symbol": {symbol}}, &profile); err != nil {
+		return nil, err
+	}
+	// 未知的股票返回空对象
+	if profile.Name == "" && profile.MarketCap == 0 {
+		return nil, &DataSourceError{
+			Source:  f.Name(),
+			Code:    "NO_DATA",
+			Message: fmt.Sprintf("no fundamentals for %s", symbol),
+			Time:    time.Now(),
+		}
+	}
+
+	var metrics struct {
+		Metric struct {
+			PE       float64 `json:"peTTM"`
+			PEBasic  float64 `json:"peBasicExclExtraTTM"`
+			EPS      float64 `json:"epsTTM"`
+			EPSBasic float64 `json:"epsBasicExclExtraItemsTTM"`
+		} `json:"metric"`
+	}
+	if err := f.get(ctx, "/stock/metric", url.Values{"symbol": {symbol}, "metric": {"all"}}, &metrics); err != nil {
+		return nil, err
+	}
+
+	fundamentals := &Fundamentals{
+		Symbol:            symbol,
+		Name:              profile.Name,
+		Sector:            profile.Industry,
+		MarketCap:         profile.MarketCap * 1e6,
+		SharesOutstanding: int64(math.Round(profile.SharesOutstanding * 1e6)),
+		PERatio:           metrics.Metric.PE,
+		EPS:               metrics.Metric.EPS,
+	}
+	if fundamentals.PERatio == 0 {
+		fundamentals.PERatio = metrics.Metric.PEBasic
+	}
+	if fundamentals.EPS == 0 {
+		fundamentals.EPS = metrics.Metric.EPSBasic
+	}
+	if fundamentals.PERatio < 0 {
+		fundamentals.PERatio = 0
+	}
+	return fundamentals, nil
+}
+
+// GetCorporateActions 通过 /stock/split 和 /stock/dividend 接口获取拆股和现金分红，分红接口需要付费版，
+// 没有权限时只返回拆股；from 为零值时从1980年开始，to 为零值时到今天
+func (f *FinnhubDataSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error) {
+	if from.IsZero() {
+		from = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	query := url.Values{"symbol": {symbol}, "from": {from.Format("2006-01-02")}, "to": {to.Format("2006-01-02")}}
+	actions := &CorporateActions{Symbol: symbol, Splits: []Split{}, Dividends: []CashDividend{}}
+
+	var splits []struct {
+		Date       string  `json:"date"`
+		FromFactor float64 `json:"fromFactor"`
+		ToFactor   float64 `json:"toFactor"`
+	}
+	if err := f.get(ctx, "/stock/split", query, &splits); err != nil {
+		return nil, err
+	}
+	for _, item := range splits {
+		exDate, err := time.Parse("2006-01-02", item.Date)
+		if err != nil {
+			continue
+		}
+		actions.Splits = append(actions.Splits, Split{Symbol: symbol, ExDate: exDate, From: item.FromFactor, To: item.ToFactor})
+	}
+
+	var dividends []struct {
+		Date    string  `json:"date"` // 除息日
+		Amount  float64 `json:"amount"`
+		PayDate string  `json:"payDate"`
+	}
+	if err := f.get(ctx, "/stock/dividend", query, &dividends); err == nil {
+		for _, item := range dividends {
+			exDate, err := time.Parse("2006-01-02", item.Date)
+			if err != nil || item.Amount <= 0 {
+				continue
+			}
+			dividend := CashDividend{Symbol: symbol, ExDate: exDate, CashAmount: item.Amount}
+			dividend.PayDate, _ = time.Parse("2006-01-02", item.PayDate)
+			actions.Dividends = append(actions.Dividends, dividend)
+		}
+	}
+
+	actions.sort()
+	return actions, nil
+}
+
+// GetHistoricalQuotes Finnhub免费版不提供NBBO历史报价
+func (f *FinnhubDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]Quote, error) {
+	return nil, &DataSourceError{
+		Source:  f.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "finnhub does not provide historical quotes",
+		Time:    time.Now(),
+	}
+}
+
+// SubscribeQuotes 轮询实时报价，只推送有变化的报价
+// 轮询间隔按股票数放宽，使轮询最多占用一半的请求限额，其余留给K线等请求
+func (f *FinnhubDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	interval := time.Minute * time.Duration(len(uniqueSymbols(symbols))) / time.Duration(max(f.pacer.limit/2, 1))
+	return PollQuotes(ctx, f.GetRealTimeQuotes, symbols, max(interval, DefaultQuotePollInterval))
+}
+
+// GetAllStocks 通过 /stock/symbol 接口获取美股交易所的所有股票
+func (f *FinnhubDataSource) GetAllStocks(ctx context.Context) ([]Stock, error) {
+	var result []struct {
+		Symbol      string `json:"symbol"`
+		Description string `json:"description"`
+		Type        string `json:"type"`
+		Currency    string `json:"currency"`
+		MIC         string `json:"mic"`
+	}
+	if err := f.get(ctx, "/stock/symbol", url.Values{"exchange": {"US"}}, &result); err != nil {
+		return nil, err
+	}
+
+	stocks := make([]Stock, 0, len(result))
+	for _, item := range result {
+		stocks = append(stocks, Stock{
+			Symbol:      item.Symbol,
+			Name:        item.Description,
+			Exchange:    item.MIC,
+			Type:        item.Type,
+			Currency:    item.Currency,
+			IsActive:    true,
+			Description: item.Description,
+		})
+	}
+	return stocks, nil
+}
+
+// Close 关闭数据源连接
+func (f *FinnhubDataSource) Close() error {
+	// HTTP客户端不需要显式关闭
+	return nil
+}
+
+// get 发送GET请求并解析JSON响应，错误转换为数据源错误（内部方法）
+func (f *FinnhubDataSource) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	endpoint := f.config.BaseURL + path + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return &DataSourceError{
+			Source:  f.Name(),
+			Code:    "REQUEST_CREATION_ERROR",
+			Message: fmt.Sprintf("Failed to create request: %v", err),
+			Time:    time.Now(),
+		}
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return &DataSourceError{
+				Source:  f.Name(),
+				Code:    "CONTEXT_CANCELLED",
+				Message: "Request cancelled by context",
+				Time:    time.Now(),
+			}
+		}
+		return &DataSourceError{
+			Source:  f.Name(),
+			Code:    "CONNECTION_ERROR",
+			Message: fmt.Sprintf("Connection failed: %v", err),
+			Time:    time.Now(),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return &DataSourceError{
+			Source:  f.Name(),
+			Code:    "API_ERROR",
+			Message: fmt.Sprintf("API returned status code %d: %s", resp.StatusCode, string(bodyBytes)),
+			Time:    time.Now(),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &DataSourceError{
+			Source:  f.Name(),
+			Code:    "RESPONSE_PARSE_ERROR",
+			Message: fmt.Sprintf("Failed to parse response: %v", err),
+			Time:    time.Now(),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFinnhubDataSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/stock/candle":
+			if r.URL.Query().Get("resolution") != "D" || r.URL.Query().Get("from") != "1709251200" {
+				t.Errorf("K线请求参数不正确: %s", r.URL.RawQuery)
+			}
+			if r.URL.Query().Get("symbol") == "NONE" {
+				w.Write([]byte(`{"s":"no_data"}`))
+				return
+			}
+			w.Write([]byte(`{"s":"ok","t":[1709251200,1709510400],"o":[100,102],"h":[103,104],"l":[99,101],"c":[102,103.5],"v":[1000,2000]}`))
+		case "/quote":
+			if r.URL.Query().Get("symbol") == "NONE" {
+				w.Write([]byte(`{"c":0,"pc":0,"t":0}`))
+				return
+			}
+			w.Write([]byte(`{"c":101.5,"pc":100,"t":1709308800}`))
+		case "/stock/profile2":
+			if r.URL.Query().Get("symbol") == "NONE" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			w.Write([]byte(`{"name":"Apple Inc","finnhubIndustry":"Technology","marketCapitalization":2800000,"shareOutstanding":15500}`))
+		case "/stock/metric":
+			w.Write([]byte(`{"metric":{"peBasicExclExtraTTM":29.5,"epsTTM":6.1}}`))
+		case "/stock/symbol":
+			w.Write([]byte(`[{"symbol":"AAPL","description":"APPLE INC","type":"Common Stock","currency":"USD","mic":"XNAS"}]`))
+		default:
+			w.Write([]byte(`{"exchange":"US","isOpen":true}`))
+		}
+	}))
+	defer server.Close()
+
+	source, _ := NewFinnhubDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL, APIKey: "secret"})
+	ctx := context.Background()
+	if ok, err := source.HealthCheck(ctx); !ok || err != nil {
+		t.Fatalf("健康检查失败: %v", err)
+	}
+
+	from := time.Unix(1709251200, 0)
+	bars, err := source.GetStockData(ctx, "AAPL", "day", from, from.AddDate(0, 0, 7))
+	if err != nil || len(bars) != 2 || bars[1].Close != 103.5 || bars[1].Volume != 2000 || !bars[0].Timestamp.Equal(from) {
+		t.Errorf("K线不正确: %+v %v", bars, err)
+	}
+	if bars, err := source.GetStockData(ctx, "NONE", "day", from, from); err != nil || len(bars) != 0 {
+		t.Errorf("没有数据时应返回空K线: %+v %v", bars, err)
+	}
+	if _, err := source.GetStockData(ctx, "AAPL", "tick", from, from); err == nil {
+		t.Error("不支持的周期应返回错误")
+	}
+
+	quotes, err := source.GetRealTimeQuotes(ctx, []string{"AAPL", "NONE"})
+	if quote := quotes["AAPL"]; quote == nil || quote.LastPrice != 101.5 || quote.RegularClose != 100 || quote.Timestamp.Unix() != 1709308800 {
+		t.Errorf("报价不正确: %+v", quote)
+	}
+	if _, ok := quotes["NONE"]; ok || err == nil {
+		t.Errorf("未知股票应返回错误: %v", err)
+	}
+
+	stocks, err := source.GetAllStocks(ctx)
+	if err != nil || len(stocks) != 1 || stocks[0].Name != "APPLE INC" || stocks[0].Exchange != "XNAS" {
+		t.Errorf("股票列表不正确: %+v %v", stocks, err)
+	}
+	fundamentals, err := source.GetFundamentals(ctx, "AAPL")
+	if err != nil || fundamentals.MarketCap != 2.8e12 || fundamentals.SharesOutstanding != 15500000000 || fundamentals.Sector != "Technology" ||
+		fundamentals.PERatio != 29.5 || fundamentals.EPS != 6.1 || fundamentals.FloatShares != 0 {
+		t.Errorf("基本面数据不正确: %+v %v", fundamentals, err)
+	}
+	if _, err := source.GetFundamentals(ctx, "NONE"); err == nil {
+		t.Error("未知股票的基本面数据应返回错误")
+	}
+	if _, err := source.GetHistoricalQuotes(ctx, "AAPL", from, from); err == nil {
+		t.Error("不支持历史报价时应返回错误")
+	}
+}
+
+func TestRequestPacer(t *testing.T) {
+	pacer := newRequestPacer(3, 200*time.Millisecond)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := pacer.wait(ctx); err != nil {
+			t.Fatalf("等待失败: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("超出限额的请求应等到窗口滑过: %v", elapsed)
+	}
+
+	// 等待期间上下文取消
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	pacer.pause(time.Now().Add(time.Second))
+	if err := pacer.wait(timeout); err != context.DeadlineExceeded {
+		t.Errorf("上下文超时应返回错误: %v", err)
+	}
+}
+
+func TestFinnhubPacesRateLimit(t *testing.T) {
+	var requests, limited int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次请求模拟密钥被其他程序占用
+		if atomic.AddInt32(&requests, 1) == 1 {
+			atomic.AddInt32(&limited, 1)
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"s":"ok","t":[1709251200],"o":[1],"h":[1],"l":[1],"c":[1],"v":[1]}`))
+	}))
+	defer server.Close()
+
+	source, _ := NewFinnhubDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL, APIKey: "secret", RequestsPerMinute: 1000})
+	source.pacer.window = 100 * time.Millisecond
+	source.pacer.limit = 2
+
+	start := time.Now()
+	data, err := source.GetMultipleStockData(context.Background(), []string{"A", "B", "C", "D"}, "day", time.Unix(0, 0), time.Unix(1, 0))
+	if err != nil || len(data) != 4 {
+		t.Fatalf("批量获取应放慢速度而不是返回错误: %d %v", len(data), err)
+	}
+	if limited != 1 || requests != 5 {
+		t.Errorf("被限流的请求应重试一次: %d/%d", limited, requests)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("被限流后应暂停到 Retry-After 之后: %v", elapsed)
+	}
+}
@@ -0,0 +1,399 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IBKRDefaultAddress 是本机TWS模拟账户API的默认地址，IB Gateway模拟账户为 127.0.0.1:4002
+const IBKRDefaultAddress = "127.0.0.1:7497"
+
+// IBKRDefaultClientID 是未配置时使用的API客户端ID
+const IBKRDefaultClientID = 17
+
+// ibkrHistoricalRequestsPer10Min 是IB历史数据每10分钟的请求限额
+const ibkrHistoricalRequestsPer10Min = 60
+
+// ibkrSnapshotConcurrency 是同时请求的报价快照数，快照占用行情线路
+const ibkrSnapshotConcurrency = 10
+
+// ibkrBarSizes 是K线周期到IB K线大小的映射
+var ibkrBarSizes = map[string]string{
+	"minute": "1 min",
+	"hour":   "1 hour",
+	"day":    "1 day",
+	"week":   "1 week",
+	"month":  "1 month",
+}
+
+// IB的tick类型，延迟行情使用另一组编号
+const (
+	ibkrTickBidSize         = 0
+	ibkrTickBid             = 1
+	ibkrTickAsk             = 2
+	ibkrTickAskSize         = 3
+	ibkrTickLast            = 4
+	ibkrTickLastSize        = 5
+	ibkrTickClose           = 9
+	ibkrTickDelayedBid      = 66
+	ibkrTickDelayedAsk      = 67
+	ibkrTickDelayedLast     = 68
+	ibkrTickDelayedBidSize  = 69
+	ibkrTickDelayedAskSize  = 70
+	ibkrTickDelayedLastSize = 71
+	ibkrTickDelayedClose    = 75
+)
+
+// IBKRDataSource 通过TWS或IB Gateway的API获取K线和报价快照，适用于只通过盈透证券订阅行情的用户
+// BaseURL 为TWS的 host:port 地址；连接在首次请求时建立，断开后在下一次请求时重连。
+// 历史数据请求按IB的限额（每10分钟60个）排队发出
+type IBKRDataSource struct {
+	config   DataSourceConfig
+	addr     string
+	clientID int
+	location *time.Location // 日K线日期所在的交易所时区
+	pacer    *requestPacer
+	nextID   atomic.Int64
+
+	mu   sync.Mutex
+	conn *ibkrConn
+}
+
+// NewIBKRDataSource 创建IB数据源，BaseURL为空时连接 IBKRDefaultAddress，ClientID为0时使用 IBKRDefaultClientID
+func NewIBKRDataSource(config DataSourceConfig) (*IBKRDataSource, error) {
+	addr := strings.TrimPrefix(config.BaseURL, "tcp://")
+	if addr == "" {
+		addr = IBKRDefaultAddress
+	}
+	if config.TimeoutSeconds <= 0 {
+		config.TimeoutSeconds = 30 // 默认30秒超时
+	}
+	config.Timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	clientID := config.ClientID
+	if clientID == 0 {
+		clientID = IBKRDefaultClientID
+	}
+
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		location = time.UTC
+	}
+	return &IBKRDataSource{
+		config:   config,
+		addr:     addr,
+		clientID: clientID,
+		location: location,
+		pacer:    newRequestPacer(ibkrHistoricalRequestsPer10Min, 10*time.Minute),
+	}, nil
+}
+
+// Name 返回数据源名称
+func (i *IBKRDataSource) Name() string {
+	return "ibkr"
+}
+
+// IsEnabled 检查数据源是否启用
+func (i *IBKRDataSource) IsEnabled() bool {
+	return i.config.Enabled
+}
+
+// HealthCheck 请求TWS的服务器时间，检查API连接是否可用
+func (i *IBKRDataSource) HealthCheck(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.Timeout)
+	defer cancel()
+	conn, err := i.connection(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, err := conn.currentTime(ctx); err != nil {
+		return false, i.error("CONNECTION_ERROR", fmt.Sprintf("TWS did not respond: %v", err))
+	}
+	return true, nil
+}
+
+// GetStockData 通过 reqHistoricalData 获取成交价K线（包括盘前盘后），支持 minute、hour、day、week 和 month 周期
+func (i *IBKRDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	barSize, ok := ibkrBarSizes[timeframe]
+	if !ok {
+		return nil, i.error("UNSUPPORTED_TIMEFRAME", fmt.Sprintf("timeframe %q is not supported", timeframe))
+	}
+	if err := i.pacer.wait(ctx); err != nil {
+		return nil, i.error("CONTEXT_CANCELLED", "Request cancelled by context")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, i.config.Timeout)
+	defer cancel()
+	fields, err := i.request(ctx, func(conn *ibkrConn, reqID int) error {
+		// 合约字段依次为 conId、代码、类型、到期日、行权价、认购认沽、乘数、交易所、主交易所、币种、本地代码、交易类别
+		return conn.send(ibkrReqHistoricalData, reqID,
+			0, symbol, "STK", "", 0.0, "", "", "SMART", "", "USD", "", "",
+			false, to.UTC().Format("20060102-15:04:05"), barSize, ibkrDuration(from, to),
+			false, "TRADES", 2, false, "")
+	}, func(fields []string) bool {
+		return fieldAt(fields, 0) == strconv.Itoa(ibkrHistoricalData)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 字段：消息ID、请求ID、开始、结束、K线数量，之后每根K线8个字段
+	count, _ := strconv.Atoi(fieldAt(fields, 4))
+	stockData := make([]StockData, 0, count)
+	for n := 0; n < count; n++ {
+		bar := fields[min(5+n*8, len(fields)):]
+		if len(bar) < 8 {
+			break
+		}
+		timestamp, err := i.parseBarTime(bar[0])
+		if err != nil {
+			return nil, i.error("RESPONSE_PARSE_ERROR", fmt.Sprintf("invalid bar time %q", bar[0]))
+		}
+		if timestamp.Before(from) || timestamp.After(to) {
+			continue
+		}
+		volume, _ := strconv.ParseFloat(bar[5], 64)
+		stockData = append(stockData, StockData{
+			Symbol:        symbol,
+			Timestamp:     timestamp,
+			Open:          parseIBKRFloat(bar[1]),
+			High:          parseIBKRFloat(bar[2]),
+			Low:           parseIBKRFloat(bar[3]),
+			Close:         parseIBKRFloat(bar[4]),
+			Volume:        int64(volume),
+			VWAP:          parseIBKRFloat(bar[6]),
+			TransactionID: fmt.Sprintf("ibkr_%s_%d", symbol, timestamp.Unix()),
+		})
+	}
+	return stockData, nil
+}
+
+// GetMultipleStockData 逐个获取多只股票的K线并报告进度，请求按IB的历史数据限额排队
+func (i *IBKRDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]StockData, error) {
+	return FetchStockData(ctx, i.Name(), func(ctx context.Context, symbol string) ([]StockData, error) {
+		return i.GetStockData(ctx, symbol, timeframe, from, to)
+	}, symbols)
+}
+
+// GetRealTimeQuote 通过 reqMktData 快照获取买卖报价和最新价，需要该股票的行情订阅；
+// 没有实时订阅而TWS返回延迟行情时同样使用
+func (i *IBKRDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	ctx, cancel := context.WithTimeout(ctx, i.config.Timeout)
+	defer cancel()
+
+	quote := &Quote{Symbol: symbol}
+	_, err := i.request(ctx, func(conn *ibkrConn, reqID int) error {
+		return conn.send(ibkrReqMktData, 11, reqID,
+			0, symbol, "STK", "", 0.0, "", "", "SMART", "", "USD", "", "",
+			false, "", true, false, "")
+	}, func(fields []string) bool {
+		switch msgID, _ := strconv.Atoi(fieldAt(fields, 0)); msgID {
+		case ibkrTickPrice, ibkrTickSize:
+			applyIBKRTick(quote, fieldAt(fields, 3), fieldAt(fields, 4))
+		case ibkrTickSnapshotEnd:
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if quote.LastPrice <= 0 && quote.BidPrice <= 0 && quote.AskPrice <= 0 {
+		return nil, i.error("NO_DATA", fmt.Sprintf("no quote for %s", symbol))
+	}
+	quote.Timestamp = time.Now()
+	quote.TransactionID = fmt.Sprintf("ibkr_%s_%d", symbol, quote.Timestamp.UnixNano())
+	return quote, nil
+}
+
+// GetRealTimeQuotes 并发获取多只股票的报价快照，同时进行的快照数受行情线路限制
+func (i *IBKRDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	return FetchQuotes(ctx, i.GetRealTimeQuote, symbols, ibkrSnapshotConcurrency)
+}
+
+// GetHistoricalQuotes IB数据源不提供NBBO历史报价
+func (i *IBKRDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]Quote, error) {
+	return nil, i.error("NOT_SUPPORTED", "ibkr does not provide historical quotes")
+}
+
+// GetTrades IB数据源不提供逐笔成交
+func (i *IBKRDataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]TradePrint, error) {
+	return nil, i.error("NOT_SUPPORTED", "ibkr does not provide trade prints")
+}
+
+// GetOptionChain IB数据源不提供期权链
+func (i *IBKRDataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]OptionQuote, error) {
+	return nil, i.error("NOT_SUPPORTED", "ibkr does not provide option chains")
+}
+
+// GetFundamentals IB数据源不提供基本面数据
+func (i *IBKRDataSource) GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	return nil, i.error("NOT_SUPPORTED", "ibkr does not provide fundamentals")
+}
+
+// GetCorporateActions IB数据源不提供公司行动，TWS返回的K线已按拆股调整
+func (i *IBKRDataSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error) {
+	return nil, i.error("NOT_SUPPORTED", "ibkr does not provide corporate actions")
+}
+
+// SubscribeQuotes 轮询报价快照，只推送有变化的报价
+func (i *IBKRDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	return PollQuotes(ctx, i.GetRealTimeQuotes, symbols, DefaultQuotePollInterval)
+}
+
+// GetAllStocks IB的API没有股票列表接口
+func (i *IBKRDataSource) GetAllStocks(ctx context.Context) ([]Stock, error) {
+	return nil, i.error("NOT_SUPPORTED", "ibkr does not provide a stock list")
+}
+
+// Close 断开与TWS的连接
+func (i *IBKRDataSource) Close() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.conn != nil {
+		i.conn.close(nil)
+		i.conn = nil
+	}
+	return nil
+}
+
+// connection 返回可用的连接，尚未连接或已断开时重新连接（内部方法）
+func (i *IBKRDataSource) connection(ctx context.Context) (*ibkrConn, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.conn != nil && i.conn.closedErr() == nil {
+		return i.conn, nil
+	}
+	conn, err := dialIBKR(ctx, i.addr, i.clientID)
+	if err != nil {
+		return nil, i.error("CONNECTION_ERROR", fmt.Sprintf("Connection to TWS at %s failed: %v", i.addr, err))
+	}
+	i.conn = conn
+	return conn, nil
+}
+
+// request 分配请求ID并发送请求，将响应交给 handle 直到其返回 true，返回最后一条响应（内部方法）
+// TWS对该请求返回错误消息时转换为数据源错误，仅供参考的通知（如改用延迟行情）被忽略
+func (i *IBKRDataSource) request(ctx context.Context, send func(conn *ibkrConn, reqID int) error, handle func(fields []string) bool) ([]string, error) {
+	conn, err := i.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reqID := int(i.nextID.Add(1))
+	responses, err := conn.subscribe(reqID)
+	if err != nil {
+		return nil, i.error("CONNECTION_ERROR", err.Error())
+	}
+	defer conn.unsubscribe(reqID)
+
+	if err := send(conn, reqID); err != nil {
+		return nil, i.error("CONNECTION_ERROR", fmt.Sprintf("Failed to send request: %v", err))
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, i.error("TIMEOUT", "TWS did not respond in time")
+			}
+			return nil, i.error("CONTEXT_CANCELLED", "Request cancelled by context")
+		case <-conn.done:
+			return nil, i.error("CONNECTION_ERROR", conn.closedErr().Error())
+		case fields := <-responses:
+			if fieldAt(fields, 0) == strconv.Itoa(ibkrErrMsg) {
+				code, _ := strconv.Atoi(fieldAt(fields, 3))
+				if ibkrWarning(code) {
+					continue
+				}
+				return nil, i.error("API_ERROR", fmt.Sprintf("TWS error %d: %s", code, fieldAt(fields, 4)))
+			}
+			if handle(fields) {
+				return fields, nil
+			}
+		}
+	}
+}
+
+// parseBarTime 解析K线时间：日线及以上为交易所时区的 yyyymmdd，日内K线为Unix秒（内部方法）
+func (i *IBKRDataSource) parseBarTime(value string) (time.Time, error) {
+	if len(value) == 8 {
+		return time.ParseInLocation("20060102", value, i.location)
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// error 创建数据源错误（内部方法）
+func (i *IBKRDataSource) error(code, message string) *DataSourceError {
+	return &DataSourceError{
+		Source:  i.Name(),
+		Code:    code,
+		Message: message,
+		Time:    time.Now(),
+	}
+}
+
+// ibkrDuration 将时间范围转换为IB的持续时间参数，一年以上按年计（内部函数）
+func ibkrDuration(from, to time.Time) string {
+	span := to.Sub(from)
+	switch {
+	case span <= 0:
+		return "1 D"
+	case span < 24*time.Hour:
+		return fmt.Sprintf("%d S", int(math.Ceil(span.Seconds())))
+	case span <= 365*24*time.Hour:
+		return fmt.Sprintf("%d D", int(math.Ceil(span.Hours()/24)))
+	default:
+		years := to.Year() - from.Year()
+		if from.AddDate(years, 0, 0).Before(to) {
+			years++
+		}
+		return fmt.Sprintf("%d Y", years)
+	}
+}
+
+// applyIBKRTick 将价格或数量tick写入报价，未知的tick类型和无效值（-1）被忽略（内部函数）
+func applyIBKRTick(quote *Quote, tickType, value string) {
+	v := parseIBKRFloat(value)
+	if v < 0 {
+		return
+	}
+	tick, _ := strconv.Atoi(tickType)
+	switch tick {
+	case ibkrTickBid, ibkrTickDelayedBid:
+		quote.BidPrice = v
+	case ibkrTickAsk, ibkrTickDelayedAsk:
+		quote.AskPrice = v
+	case ibkrTickLast, ibkrTickDelayedLast:
+		quote.LastPrice = v
+	case ibkrTickClose, ibkrTickDelayedClose:
+		quote.RegularClose = v
+	case ibkrTickBidSize, ibkrTickDelayedBidSize:
+		quote.BidSize = int64(v)
+	case ibkrTickAskSize, ibkrTickDelayedAskSize:
+		quote.AskSize = int64(v)
+	case ibkrTickLastSize, ibkrTickDelayedLastSize:
+		quote.LastSize = int64(v)
+	}
+}
+
+// parseIBKRFloat 解析数值字段，无效时返回-1（内部函数）
+func parseIBKRFloat(value string) float64 {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+// ibkrWarning 检查错误代码是否只是通知：2100-2199为连接和行情服务器状态，10167为改用延迟行情（内部函数）
+func ibkrWarning(code int) bool {
+	return (code >= 2100 && code < 2200) || code == 10167
+}
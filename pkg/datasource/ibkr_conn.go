@@ -0,0 +1,282 @@
+package datasource
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TWS API协议版本，按协商的最高版本 ibkrMaxClientVersion 解析响应
+const (
+	ibkrMinClientVersion = 100
+	ibkrMaxClientVersion = 151
+	ibkrMinServerVersion = 124 // 历史K线响应不再包含版本号字段的最低服务器版本
+)
+
+// TWS API请求消息ID
+const (
+	ibkrReqMktData        = 1
+	ibkrReqHistoricalData = 20
+	ibkrReqCurrentTime    = 49
+	ibkrStartAPI          = 71
+)
+
+// TWS API响应消息ID
+const (
+	ibkrTickPrice       = 1
+	ibkrTickSize        = 2
+	ibkrErrMsg          = 4
+	ibkrHistoricalData  = 17
+	ibkrCurrentTime     = 49
+	ibkrTickSnapshotEnd = 57
+)
+
+// ibkrMaxMessageSize 是单条消息的最大长度，超过时视为协议错误
+const ibkrMaxMessageSize = 16 << 20
+
+// errIBKRClosed 表示与TWS的连接已断开
+var errIBKRClosed = errors.New("connection to TWS closed")
+
+// ibkrConn 是与TWS或IB Gateway的API连接，请求和响应按请求ID对应（内部类型）
+// 消息由4字节大端长度和以 \0 结尾的字段组成
+type ibkrConn struct {
+	conn          net.Conn
+	serverVersion int
+
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	pending  map[int]chan []string // 按请求ID等待响应的请求
+	timeReqs []chan []string       // 等待服务器时间的请求，响应不带请求ID
+	err      error                 // 连接断开的原因
+	done     chan struct{}
+}
+
+// dialIBKR 连接TWS并完成握手，clientID 在同一TWS的所有API连接中必须唯一（内部函数）
+func dialIBKR(ctx context.Context, addr string, clientID int) (*ibkrConn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// 握手："API\0" 后跟支持的版本范围，服务器返回选定的版本和连接时间
+	versions := fmt.Sprintf("v%d..%d", ibkrMinClientVersion, ibkrMaxClientVersion)
+	handshake := binary.BigEndian.AppendUint32([]byte("API\x00"), uint32(len(versions)))
+	handshake = append(handshake, versions...)
+	if _, err := conn.Write(handshake); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	fields, err := readIBKRMessage(reader)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("handshake failed: %v", err)
+	}
+	version, err := strconv.Atoi(fieldAt(fields, 0))
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("handshake failed: invalid server version %q", fieldAt(fields, 0))
+	}
+	if version < ibkrMinServerVersion {
+		conn.Close()
+		return nil, fmt.Errorf("TWS server version %d is too old, at least %d is required", version, ibkrMinServerVersion)
+	}
+	conn.SetDeadline(time.Time{})
+
+	c := &ibkrConn{
+		conn:          conn,
+		serverVersion: version,
+		pending:       make(map[int]chan []string),
+		done:          make(chan struct{}),
+	}
+	if err := c.send(ibkrStartAPI, 2, clientID, ""); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go c.readLoop(reader)
+	return c, nil
+}
+
+// send 编码并发送一条消息，字段支持字符串、整数、浮点数和布尔值（内部方法）
+func (c *ibkrConn) send(msgID int, fields ...interface{}) error {
+	var payload strings.Builder
+	payload.WriteString(strconv.Itoa(msgID))
+	payload.WriteByte(0)
+	for _, field := range fields {
+		switch v := field.(type) {
+		case bool:
+			if v {
+				payload.WriteString("1")
+			} else {
+				payload.WriteString("0")
+			}
+		case float64:
+			payload.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			fmt.Fprint(&payload, v)
+		}
+		payload.WriteByte(0)
+	}
+
+	msg := binary.BigEndian.AppendUint32(nil, uint32(payload.Len()))
+	msg = append(msg, payload.String()...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.conn.Write(msg); err != nil {
+		c.close(err)
+		return err
+	}
+	return nil
+}
+
+// subscribe 注册请求ID，返回接收该请求响应的通道（内部方法）
+func (c *ibkrConn) subscribe(reqID int) (<-chan []string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	ch := make(chan []string, 64)
+	c.pending[reqID] = ch
+	return ch, nil
+}
+
+// unsubscribe 取消请求ID的注册（内部方法）
+func (c *ibkrConn) unsubscribe(reqID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, reqID)
+}
+
+// currentTime 请求服务器时间，用于检查连接是否可用（内部方法）
+func (c *ibkrConn) currentTime(ctx context.Context) (int64, error) {
+	ch := make(chan []string, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return 0, c.err
+	}
+	c.timeReqs = append(c.timeReqs, ch)
+	c.mu.Unlock()
+
+	if err := c.send(ibkrReqCurrentTime, 1); err != nil {
+		return 0, err
+	}
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-c.done:
+		return 0, c.closedErr()
+	case fields := <-ch:
+		return strconv.ParseInt(fieldAt(fields, 2), 10, 64)
+	}
+}
+
+// readLoop 读取消息并按请求ID分发，连接断开时通知所有等待的请求（内部方法）
+func (c *ibkrConn) readLoop(reader *bufio.Reader) {
+	for {
+		fields, err := readIBKRMessage(reader)
+		if err != nil {
+			c.close(err)
+			return
+		}
+		msgID, _ := strconv.Atoi(fieldAt(fields, 0))
+
+		// 各类消息中请求ID的位置：历史K线没有版本号字段
+		var idField int
+		switch msgID {
+		case ibkrHistoricalData:
+			idField = 1
+		case ibkrTickPrice, ibkrTickSize, ibkrErrMsg, ibkrTickSnapshotEnd:
+			idField = 2
+		case ibkrCurrentTime:
+			c.mu.Lock()
+			if len(c.timeReqs) > 0 {
+				c.timeReqs[0] <- fields
+				c.timeReqs = c.timeReqs[1:]
+			}
+			c.mu.Unlock()
+			continue
+		default:
+			continue
+		}
+		reqID, err := strconv.Atoi(fieldAt(fields, idField))
+		if err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		ch := c.pending[reqID]
+		c.mu.Unlock()
+		if ch == nil {
+			// 没有请求ID的错误消息是连接状态通知，如行情服务器已连接
+			continue
+		}
+		// 请求已超时放弃时通道可能不再被读取，缓冲区满时丢弃消息而不是阻塞读取
+		select {
+		case ch <- fields:
+		default:
+		}
+	}
+}
+
+// close 关闭连接并记录原因，只有第一次调用生效（内部方法）
+func (c *ibkrConn) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		err = errIBKRClosed
+	}
+	c.err = err
+	close(c.done)
+	c.conn.Close()
+}
+
+// closedErr 返回连接断开的原因（内部方法）
+func (c *ibkrConn) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// readIBKRMessage 读取一条消息并拆分为字段（内部函数）
+func readIBKRMessage(reader *bufio.Reader) ([]string, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > ibkrMaxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the limit", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(string(payload), "\x00"), "\x00"), nil
+}
+
+// fieldAt 返回第i个字段，不存在时返回空字符串（内部函数）
+func fieldAt(fields []string, i int) string {
+	if i < len(fields) {
+		return fields[i]
+	}
+	return ""
+}
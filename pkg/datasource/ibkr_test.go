@@ -0,0 +1,148 @@
+package datasource
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTWS 模拟TWS的API端口，按请求的消息ID返回固定的响应
+func fakeTWS(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeTWS(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveFakeTWS(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// 握手："API\0" 和带长度的版本范围
+	prefix := make([]byte, 4)
+	if _, err := io.ReadFull(reader, prefix); err != nil || string(prefix) != "API\x00" {
+		return
+	}
+	if _, err := readIBKRMessage(reader); err != nil {
+		return
+	}
+	write := func(fields ...string) {
+		payload := strings.Join(fields, "\x00") + "\x00"
+		conn.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(payload))), payload...))
+	}
+	write("151", "20240102 09:30:00 EST")
+
+	for {
+		fields, err := readIBKRMessage(reader)
+		if err != nil {
+			return
+		}
+		switch fields[0] {
+		case "71": // startApi
+			write("4", "2", "-1", "2104", "Market data farm connection is OK:usfarm")
+		case "49": // reqCurrentTime
+			write("49", "1", "1704205800")
+		case "20": // reqHistoricalData
+			reqID, symbol := fields[1], fields[3]
+			if symbol != "AAPL" {
+				write("4", "2", reqID, "162", "Historical Market Data Service error message:No market data permissions")
+				continue
+			}
+			write("17", reqID, "20231229", "20240104", "3",
+				"20231229", "190", "191", "189", "190.5", "1000", "190.2", "10",
+				"20240102", "187", "188.5", "183.9", "185.6", "8200000", "185.8", "12000",
+				"20240103", "184.2", "185.9", "183.4", "184.3", "5800000", "184.5", "9000")
+		case "1": // reqMktData
+			reqID := fields[2]
+			write("4", "2", reqID, "10167", "Requested market data is not subscribed. Displaying delayed market data.")
+			write("1", "6", reqID, "66", "189.5", "3", "0")
+			write("1", "6", reqID, "67", "189.6", "5", "0")
+			write("1", "6", reqID, "68", "189.55", "1", "0")
+			write("1", "6", reqID, "75", "187.0", "0", "0")
+			write("2", "6", reqID, "69", "300")
+			write("57", "1", reqID)
+		}
+	}
+}
+
+func TestIBKRDataSource(t *testing.T) {
+	source, err := NewIBKRDataSource(DataSourceConfig{Enabled: true, BaseURL: fakeTWS(t), TimeoutSeconds: 5})
+	if err != nil {
+		t.Fatalf("创建数据源失败: %v", err)
+	}
+	defer source.Close()
+	ctx := context.Background()
+
+	if ok, err := source.HealthCheck(ctx); !ok || err != nil {
+		t.Fatalf("健康检查失败: %v", err)
+	}
+
+	ny, _ := time.LoadLocation("America/New_York")
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, ny)
+	to := time.Date(2024, 1, 3, 23, 59, 0, 0, ny)
+	bars, err := source.GetStockData(ctx, "AAPL", "day", from, to)
+	if err != nil {
+		t.Fatalf("获取K线失败: %v", err)
+	}
+	if len(bars) != 2 || !bars[0].Timestamp.Equal(from) || bars[0].Close != 185.6 || bars[1].Volume != 5800000 || bars[1].VWAP != 184.5 {
+		t.Errorf("K线解析不正确或未按时间范围过滤: %+v", bars)
+	}
+
+	_, err = source.GetStockData(ctx, "TSLA", "day", from, to)
+	if dsErr, ok := err.(*DataSourceError); !ok || dsErr.Code != "API_ERROR" || !strings.Contains(dsErr.Message, "162") {
+		t.Errorf("TWS返回的错误应转换为数据源错误: %v", err)
+	}
+	if _, err := source.GetStockData(ctx, "AAPL", "tick", from, to); err == nil {
+		t.Error("不支持的周期应返回错误")
+	}
+
+	quote, err := source.GetRealTimeQuote(ctx, "AAPL")
+	if err != nil {
+		t.Fatalf("获取报价失败: %v", err)
+	}
+	if quote.BidPrice != 189.5 || quote.AskPrice != 189.6 || quote.LastPrice != 189.55 || quote.RegularClose != 187 || quote.BidSize != 300 {
+		t.Errorf("报价快照解析不正确: %+v", quote)
+	}
+
+	// 连接断开后下一次请求重新连接
+	source.conn.close(nil)
+	if _, err := source.GetRealTimeQuotes(ctx, []string{"AAPL", "MSFT"}); err != nil {
+		t.Errorf("断开后应重新连接: %v", err)
+	}
+}
+
+func TestIBKRDuration(t *testing.T) {
+	start := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	cases := []struct {
+		to   time.Time
+		want string
+	}{
+		{start.Add(90 * time.Minute), "5400 S"},
+		{start.Add(36 * time.Hour), "2 D"},
+		{start.AddDate(0, 0, 365), "365 D"},
+		{start.AddDate(2, 0, 0), "2 Y"},
+	}
+	for _, tc := range cases {
+		if got := ibkrDuration(start, tc.to); got != tc.want {
+			t.Errorf("ibkrDuration(%v) = %s, 期望 %s", tc.to.Sub(start), got, tc.want)
+		}
+	}
+}
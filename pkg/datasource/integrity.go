@@ -0,0 +1,266 @@
+package datasource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultIntegritySamples 是每次校验默认抽查的股票和日期组合数
+const DefaultIntegritySamples = 20
+
+// IntegrityOptions 表示录制数据校验的选项
+type IntegrityOptions struct {
+	Timeframe string   // K线周期，为空时为 minute
+	Symbols   []string // 抽查的股票，为空时从录制数据中选取
+	Days      int      // 只抽查最近的若干个交易日，为0时不限制
+	Samples   int      // 抽查的股票和日期组合数，为0时使用 DefaultIntegritySamples
+	Repair    bool     // 是否用数据源的数据替换不一致的录制数据
+}
+
+// IntegrityCheck 表示一只股票一个交易日的校验结果
+type IntegrityCheck struct {
+	Symbol           string `json:"symbol"`
+	Date             string `json:"date"`
+	LocalBars        int    `json:"local_bars"`
+	ProviderBars     int    `json:"provider_bars"`
+	LocalChecksum    string `json:"local_checksum"`
+	ProviderChecksum string `json:"provider_checksum"`
+	CorruptLines     int    `json:"corrupt_lines,omitempty"` // 该日录制文件中无法解析的行数
+	Drift            bool   `json:"drift"`                   // K线数量、校验和不一致或有损坏的行
+	Repaired         bool   `json:"repaired,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// IntegrityReport 表示一次录制数据校验的结果
+type IntegrityReport struct {
+	CheckedAt time.Time        `json:"checked_at"`
+	Timeframe string           `json:"timeframe"`
+	Checks    []IntegrityCheck `json:"checks"`
+	Drifted   int              `json:"drifted"`
+	Repaired  int              `json:"repaired"`
+	Failed    int              `json:"failed"`
+}
+
+// BarsChecksum 返回K线时间和OHLCV的SHA-256校验和，用于比较两份数据是否一致
+func BarsChecksum(bars []StockData) string {
+	h := sha256.New()
+	for _, bar := range bars {
+		fmt.Fprintf(h, "%d|%s|%s|%s|%s|%d\n", bar.Timestamp.UnixNano(),
+			strconv.FormatFloat(bar.Open, 'f', -1, 64), strconv.FormatFloat(bar.High, 'f', -1, 64),
+			strconv.FormatFloat(bar.Low, 'f', -1, 64), strconv.FormatFloat(bar.Close, 'f', -1, 64), bar.Volume)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyRecording 抽查录制的K线与数据源是否一致，比较每只股票每个交易日的K线数量和OHLCV校验和，
+// 开启修复时用数据源的数据替换不一致的录制数据。只校验今天之前的日期，避免与正在写入的录制器冲突
+func VerifyRecording(ctx context.Context, local *RecordedDataSource, provider DataSource, opts IntegrityOptions) (*IntegrityReport, error) {
+	if opts.Timeframe == "" {
+		opts.Timeframe = "minute"
+	}
+	if opts.Samples <= 0 {
+		opts.Samples = DefaultIntegritySamples
+	}
+	kind := recordKindBars(opts.Timeframe)
+
+	days, err := local.days()
+	if err != nil {
+		return nil, err
+	}
+	today := time.Now().Format("2006-01-02")
+	var dates []string
+	for _, day := range days {
+		if date := day.Format("2006-01-02"); date < today {
+			dates = append(dates, date)
+		}
+	}
+	if opts.Days > 0 && len(dates) > opts.Days {
+		dates = dates[len(dates)-opts.Days:]
+	}
+
+	// 读取各日期的录制数据，列出所有股票和日期组合后随机抽查
+	type sample struct{ date, symbol string }
+	var samples []sample
+	dayBars := make(map[string]map[string][]StockData, len(dates))
+	corrupt := make(map[string]int, len(dates))
+	for _, date := range dates {
+		bars, bad, err := local.readDay(kind, date)
+		if err != nil {
+			return nil, err
+		}
+		dayBars[date], corrupt[date] = bars, bad
+		symbols := opts.Symbols
+		if len(symbols) == 0 {
+			for symbol := range bars {
+				symbols = append(symbols, symbol)
+			}
+			sort.Strings(symbols)
+		}
+		for _, symbol := range symbols {
+			samples = append(samples, sample{date, symbol})
+		}
+	}
+	if len(samples) > opts.Samples {
+		rand.Shuffle(len(samples), func(i, j int) { samples[i], samples[j] = samples[j], samples[i] })
+		samples = samples[:opts.Samples]
+		sort.Slice(samples, func(i, j int) bool {
+			if samples[i].date != samples[j].date {
+				return samples[i].date < samples[j].date
+			}
+			return samples[i].symbol < samples[j].symbol
+		})
+	}
+
+	report := &IntegrityReport{CheckedAt: time.Now(), Timeframe: opts.Timeframe}
+	for _, s := range samples {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		check := verifyDay(ctx, local, provider, opts, s.date, s.symbol, dayBars[s.date][s.symbol], corrupt[s.date])
+		if check.Error != "" {
+			report.Failed++
+		}
+		if check.Drift {
+			report.Drifted++
+		}
+		if check.Repaired {
+			report.Repaired++
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// verifyDay 校验一只股票一个交易日的录制数据，需要时修复（内部函数）
+func verifyDay(ctx context.Context, local *RecordedDataSource, provider DataSource, opts IntegrityOptions, date, symbol string, localBars []StockData, corrupt int) IntegrityCheck {
+	check := IntegrityCheck{
+		Symbol:        symbol,
+		Date:          date,
+		LocalBars:     len(localBars),
+		LocalChecksum: BarsChecksum(localBars),
+		CorruptLines:  corrupt,
+	}
+
+	day, _ := time.Parse("2006-01-02", date)
+	// 多取前后各一天，再按录制时的日期筛选，避免数据源和录制数据的时区不同
+	data, err := provider.GetStockData(ctx, symbol, opts.Timeframe, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	var providerBars []StockData
+	for _, bar := range data {
+		if bar.Timestamp.Format("2006-01-02") == date {
+			providerBars = append(providerBars, bar)
+		}
+	}
+	check.ProviderBars = len(providerBars)
+	check.ProviderChecksum = BarsChecksum(providerBars)
+	check.Drift = check.LocalBars != check.ProviderBars || check.LocalChecksum != check.ProviderChecksum || corrupt > 0
+
+	if check.Drift && opts.Repair {
+		if err := local.ReplaceBars(opts.Timeframe, date, symbol, providerBars); err != nil {
+			check.Error = err.Error()
+		} else {
+			check.Repaired = true
+		}
+	}
+	return check
+}
+
+// ReplaceBars 用bars替换录制目录中某日期某只股票的K线，同时丢弃该日无法解析的损坏行
+// 该日的轮转文件会合并为一个文件，先写临时文件再替换
+func (d *RecordedDataSource) ReplaceBars(timeframe, date, symbol string, bars []StockData) error {
+	kind := recordKindBars(timeframe)
+	dir := filepath.Join(d.dir, date)
+	paths, err := recordFiles(dir, kind)
+	if err != nil {
+		return err
+	}
+
+	var lines [][]byte
+	for _, path := range paths {
+		err := scanLines(path, func(line []byte) error {
+			var bar StockData
+			if json.Unmarshal(line, &bar) == nil && bar.Symbol != symbol {
+				lines = append(lines, append([]byte(nil), line...))
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+	}
+	for _, bar := range bars {
+		line, err := json.Marshal(bar)
+		if err != nil {
+			return fmt.Errorf("failed to encode bar: %v", err)
+		}
+		lines = append(lines, append(line, '\n'))
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create record directory: %v", err)
+	}
+	target := filepath.Join(dir, kind+".jsonl")
+	tmp := target + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", tmp, err)
+	}
+	for _, line := range lines {
+		if _, err := file.Write(line); err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("failed to write %s: %v", tmp, err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if path != target {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// readDay 读取某日期某类型的全部录制K线，按股票分组并按时间排序去重，返回无法解析的行数（内部方法）
+func (d *RecordedDataSource) readDay(kind, date string) (map[string][]StockData, int, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, 0, err
+	}
+	bars := make(map[string][]StockData)
+	corrupt := 0
+	err = d.scanDay(kind, day, func(line []byte) error {
+		var bar StockData
+		if err := json.Unmarshal(line, &bar); err != nil {
+			corrupt++
+			return nil
+		}
+		bars[bar.Symbol] = append(bars[bar.Symbol], bar)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	for symbol, data := range bars {
+		bars[symbol] = sortUniqueBars(data)
+	}
+	return bars, corrupt, nil
+}
@@ -0,0 +1,66 @@
+package datasource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// symbolBarSource 按股票返回固定K线的数据源
+type symbolBarSource struct {
+	DataSource
+	bars map[string][]StockData
+}
+
+func (s *symbolBarSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	return append([]StockData(nil), s.bars[symbol]...), nil
+}
+
+func TestVerifyRecording(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	start := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	provider := &symbolBarSource{bars: make(map[string][]StockData)}
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		for i := 0; i < 5; i++ {
+			provider.bars[symbol] = append(provider.bars[symbol], StockData{Symbol: symbol, Timestamp: start.Add(time.Duration(i) * time.Minute), Close: float64(100 + i), Volume: 10})
+		}
+	}
+
+	recorder, _ := NewRecorder(dir, 0)
+	recorder.RecordBars("minute", provider.bars["MSFT"])
+	aapl := append([]StockData(nil), provider.bars["AAPL"]...)
+	aapl[2].Close = 999 // 录制时的数据与数据源不一致
+	recorder.RecordBars("minute", aapl[:4])
+	recorder.Close()
+
+	// 模拟写了一半的损坏行
+	path := filepath.Join(dir, "2024-03-08", "bars-minute.jsonl")
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	file.WriteString("{\"symbol\":\"AAPL\n")
+	file.Close()
+
+	local := NewRecordedDataSource("recorded", dir)
+	report, err := VerifyRecording(ctx, local, provider, IntegrityOptions{Repair: true})
+	if err != nil {
+		t.Fatalf("校验失败: %v", err)
+	}
+	if len(report.Checks) != 2 || report.Repaired != 2 {
+		t.Fatalf("损坏的日期应修复所有抽查的股票: %+v", report)
+	}
+	check := report.Checks[0]
+	if check.Symbol != "AAPL" || check.LocalBars != 4 || check.ProviderBars != 5 || check.CorruptLines != 1 || check.LocalChecksum == check.ProviderChecksum {
+		t.Errorf("AAPL 的校验结果不正确: %+v", check)
+	}
+
+	report, err = VerifyRecording(ctx, local, provider, IntegrityOptions{})
+	if err != nil || report.Drifted != 0 {
+		t.Errorf("修复后应不再有不一致: %+v %v", report, err)
+	}
+	data, _ := local.GetStockData(ctx, "AAPL", "minute", start, start.Add(time.Hour))
+	if len(data) != 5 || data[2].Close != 102 {
+		t.Errorf("修复后的K线不正确: %+v", data)
+	}
+}
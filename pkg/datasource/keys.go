@@ -0,0 +1,166 @@
+package datasource
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 多个API密钥的选择策略
+const (
+	KeySelectionRoundRobin = "round_robin" // 依次轮换
+	KeySelectionLeastUsed  = "least_used"  // 选择请求次数最少的密钥
+)
+
+// DefaultKeyCooldown 是密钥被限流后停用的默认时长，响应带有 Retry-After 时以其为准
+const DefaultKeyCooldown = time.Minute
+
+// KeyStatus 表示一个API密钥的使用情况，密钥只显示最后4位
+type KeyStatus struct {
+	Key           string    `json:"key"`
+	Requests      int64     `json:"requests"`
+	RateLimited   int64     `json:"rate_limited"`
+	DisabledUntil time.Time `json:"disabled_until,omitempty"` // 被限流后停用到该时间
+	Revoked       bool      `json:"revoked,omitempty"`        // 密钥无效，不再使用
+}
+
+// apiKey 是密钥池中的一个密钥（内部类型）
+type apiKey struct {
+	value         string
+	requests      int64
+	rateLimited   int64
+	disabledUntil time.Time
+	revoked       bool
+}
+
+// keyPool 在多个API密钥之间分配请求，并停用额度耗尽或无效的密钥（内部类型）
+type keyPool struct {
+	mu        sync.Mutex
+	keys      []*apiKey
+	selection string
+	cooldown  time.Duration
+	next      int
+}
+
+// newKeyPool 创建密钥池，忽略空的和重复的密钥（内部函数）
+func newKeyPool(keys []string, selection string, cooldown time.Duration) *keyPool {
+	if cooldown <= 0 {
+		cooldown = DefaultKeyCooldown
+	}
+	pool := &keyPool{selection: selection, cooldown: cooldown}
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		pool.keys = append(pool.keys, &apiKey{value: key})
+	}
+	return pool
+}
+
+// acquire 按选择策略返回一个可用的密钥并计入请求次数，没有可用密钥时返回false（内部方法）
+func (p *keyPool) acquire(now time.Time) (*apiKey, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var chosen *apiKey
+	for i := range p.keys {
+		key := p.keys[(p.next+i)%len(p.keys)]
+		if key.revoked || now.Before(key.disabledUntil) {
+			continue
+		}
+		if p.selection != KeySelectionLeastUsed {
+			chosen = key
+			p.next = (p.next + i + 1) % len(p.keys)
+			break
+		}
+		if chosen == nil || key.requests < chosen.requests {
+			chosen = key
+		}
+	}
+	if chosen == nil {
+		return nil, false
+	}
+	chosen.requests++
+	return chosen, true
+}
+
+// report 根据响应状态停用密钥：429表示额度耗尽，停用一段时间；401表示密钥无效，不再使用（内部方法）
+func (p *keyPool) report(key *apiKey, resp *http.Response, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		key.rateLimited++
+		cooldown := p.cooldown
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			cooldown = time.Duration(seconds) * time.Second
+		}
+		key.disabledUntil = now.Add(cooldown)
+	case http.StatusUnauthorized:
+		key.revoked = true
+	}
+}
+
+// status 返回所有密钥的使用情况（内部方法）
+func (p *keyPool) status() []KeyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	statuses := make([]KeyStatus, 0, len(p.keys))
+	for _, key := range p.keys {
+		masked := key.value
+		if len(masked) > 4 {
+			masked = "..." + masked[len(masked)-4:]
+		}
+		statuses = append(statuses, KeyStatus{
+			Key:           masked,
+			Requests:      key.requests,
+			RateLimited:   key.rateLimited,
+			DisabledUntil: key.disabledUntil,
+			Revoked:       key.revoked,
+		})
+	}
+	return statuses
+}
+
+// keyTransport 为每个请求添加密钥池中的密钥参数，被限流时换用下一个可用的密钥重试（内部类型）
+type keyTransport struct {
+	pool  *keyPool
+	base  http.RoundTripper
+	param string // 密钥的查询参数名，为空时为 apiKey（Polygon）
+}
+
+// RoundTrip 发送请求，只用于没有请求体的GET请求。没有配置密钥时原样发送
+func (t *keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.pool.keys) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	for attempt := 0; ; attempt++ {
+		key, ok := t.pool.acquire(time.Now())
+		if !ok {
+			return nil, fmt.Errorf("all API keys are rate limited or revoked")
+		}
+
+		keyed := req.Clone(req.Context())
+		q := keyed.URL.Query()
+		param := t.param
+		if param == "" {
+			param = "apiKey"
+		}
+		q.Set(param, key.value)
+		keyed.URL.RawQuery = q.Encode()
+
+		resp, err := t.base.RoundTrip(keyed)
+		if err != nil {
+			return nil, err
+		}
+		t.pool.report(key, resp, time.Now())
+		if resp.StatusCode != http.StatusTooManyRequests || attempt+1 >= len(t.pool.keys) {
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,78 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestKeyPoolRotation(t *testing.T) {
+	used := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("apiKey")
+		used[key]++
+		switch key {
+		case "exhausted":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.Write([]byte(`{"market":"open"}`))
+		}
+	}))
+	defer server.Close()
+
+	source, _ := NewPolygonDataSource(DataSourceConfig{
+		Enabled: true,
+		BaseURL: server.URL,
+		APIKey:  "exhausted",
+		APIKeys: []string{"good", "revoked"},
+	})
+	ctx := context.Background()
+	// 第一个密钥被限流后立即换用下一个密钥重试
+	if ok, err := source.HealthCheck(ctx); !ok || err != nil {
+		t.Fatalf("被限流时应换用其他密钥: %v", err)
+	}
+	// 下一个是无效的密钥，之后只剩可用的密钥
+	source.HealthCheck(ctx)
+	for i := 0; i < 3; i++ {
+		if ok, err := source.HealthCheck(ctx); !ok || err != nil {
+			t.Fatalf("应只使用可用的密钥: %v", err)
+		}
+	}
+	if used["exhausted"] != 1 || used["revoked"] != 1 || used["good"] != 4 {
+		t.Errorf("密钥使用次数不正确: %v", used)
+	}
+
+	status := source.KeyStatus()
+	if len(status) != 3 || status[0].Key != "...sted" || status[0].RateLimited != 1 || status[0].DisabledUntil.Before(time.Now().Add(time.Minute)) {
+		t.Errorf("被限流的密钥应按 Retry-After 停用: %+v", status)
+	}
+	if !status[2].Revoked {
+		t.Errorf("返回401的密钥应不再使用: %+v", status[2])
+	}
+}
+
+func TestKeyPoolLeastUsed(t *testing.T) {
+	pool := newKeyPool([]string{"a", "b", "b", ""}, KeySelectionLeastUsed, 0)
+	if len(pool.keys) != 2 {
+		t.Fatalf("应忽略空的和重复的密钥: %d", len(pool.keys))
+	}
+	now := time.Now()
+	pool.keys[0].requests = 5
+	for i := 0; i < 3; i++ {
+		if key, _ := pool.acquire(now); key.value != "b" {
+			t.Errorf("应选择请求次数最少的密钥: %s", key.value)
+		}
+	}
+	pool.report(pool.keys[1], &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}, now)
+	if key, _ := pool.acquire(now); key.value != "a" {
+		t.Errorf("被限流的密钥应停用: %s", key.value)
+	}
+	if key, ok := pool.acquire(now.Add(DefaultKeyCooldown)); !ok || key.value != "b" {
+		t.Errorf("停用时间过后应恢复使用: %+v", key)
+	}
+}
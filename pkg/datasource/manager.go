@@ -12,12 +12,25 @@ type Manager struct {
 	mu         sync.RWMutex
 	dataSources map[string]DataSource
 	primary    string // 主数据源名称
+
+	statsMu     sync.Mutex
+	stats       map[string]*sourceStats // 每个数据源最近的请求延迟和结果
+	routing     *QuoteRoutingOptions    // 为nil时实时报价只使用主数据源
+	quoteSource string                  // 开启报价路由时当前选中的数据源
+	lastProbe   time.Time
+
+	actionsMu  sync.Mutex
+	actions    map[string]cachedCorporateActions // 按股票缓存的完整公司行动
+	adjustment Adjustment                        // 上下文未指定时K线的复权方式
 }
 
 // NewManager 创建一个新的数据源管理器
 func NewManager() *Manager {
 	return &Manager{
 		dataSources: make(map[string]DataSource),
+		stats:       make(map[string]*sourceStats),
+		actions:     make(map[string]cachedCorporateActions),
+		adjustment:  AdjustSplits,
 	}
 }
 
@@ -57,6 +70,9 @@ func (m *Manager) RemoveDataSource(name string) error {
 	}
 
 	delete(m.dataSources, name)
+	m.statsMu.Lock()
+	delete(m.stats, name)
+	m.statsMu.Unlock()
 
 	// 如果删除的是主数据源，那么需要选择新的主数据源
 	if m.primary == name {
@@ -135,6 +151,7 @@ func (m *Manager) HealthCheckAll(ctx context.Context) map[string]error {
 	m.mu.RUnlock()
 
 	results := make(map[string]error)
+	var resultsMu sync.Mutex
 	var wg sync.WaitGroup
 
 	// 并行检查所有数据源
@@ -145,7 +162,9 @@ func (m *Manager) HealthCheckAll(ctx context.Context) map[string]error {
 
 			// 检查数据源是否启用
 			if !ds.IsEnabled() {
+				resultsMu.Lock()
 				results[name] = fmt.Errorf("data source is disabled")
+				resultsMu.Unlock()
 				return
 			}
 
@@ -153,9 +172,15 @@ func (m *Manager) HealthCheckAll(ctx context.Context) map[string]error {
 			checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 			defer cancel()
 
-			// 执行健康检查
+			// 执行健康检查，结果同时计入报价路由的统计
+			start := time.Now()
 			_, err := ds.HealthCheck(checkCtx)
+			if ctx.Err() == nil {
+				m.recordLatency(name, time.Since(start), err == nil)
+			}
+			resultsMu.Lock()
 			results[name] = err
+			resultsMu.Unlock()
 		}(name, ds)
 	}
 
@@ -183,7 +208,94 @@ func (m *Manager) Close() error {
 }
 
 // GetStockData 从主数据源获取股票数据，如果失败则尝试备用数据源
+// K线按上下文中的复权方式（WithAdjustment）调整，未指定时使用管理器的默认复权方式
 func (m *Manager) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	data, err := m.fetchStockData(ctx, symbol, timeframe, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment, ok := AdjustmentFrom(ctx)
+	if !ok {
+		m.mu.RLock()
+		adjustment = m.adjustment
+		m.mu.RUnlock()
+	}
+	if adjustment == AdjustNone || len(data) == 0 {
+		return data, nil
+	}
+	actions, err := m.GetCorporateActions(ctx, symbol, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust %s: %v", symbol, err)
+	}
+	return AdjustStockData(data, actions, adjustment), nil
+}
+
+// SetAdjustment 设置上下文未指定复权方式时K线的默认复权方式，默认为 AdjustSplits
+func (m *Manager) SetAdjustment(adjustment Adjustment) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.adjustment = adjustment
+}
+
+// GetCorporateActions 返回股票除权除息日在[from, to]内的拆股和分红，from 和 to 为零值时不限制。
+// 完整的公司行动从主数据源获取，失败时尝试其他数据源，按股票缓存 CorporateActionsMaxAge；
+// 所有数据源都不提供公司行动（NOT_SUPPORTED 或 NO_DATA）时返回空的公司行动
+func (m *Manager) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error) {
+	m.actionsMu.Lock()
+	cached, ok := m.actions[symbol]
+	m.actionsMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < CorporateActionsMaxAge && !CacheControlFrom(ctx).NoCache {
+		return cached.actions.Between(from, to), nil
+	}
+
+	m.mu.RLock()
+	primary := m.primary
+	dataSources := make(map[string]DataSource, len(m.dataSources))
+	for name, ds := range m.dataSources {
+		dataSources[name] = ds
+	}
+	m.mu.RUnlock()
+
+	// 主数据源排在最前
+	names := make([]string, 0, len(dataSources))
+	if ds, exists := dataSources[primary]; exists && ds.IsEnabled() {
+		names = append(names, primary)
+	}
+	for name, ds := range dataSources {
+		if name != primary && ds.IsEnabled() {
+			names = append(names, name)
+		}
+	}
+
+	var actions *CorporateActions
+	var lastErr error
+	for _, name := range names {
+		result, err := dataSources[name].GetCorporateActions(ctx, symbol, time.Time{}, time.Time{})
+		if err == nil {
+			actions = result
+			break
+		}
+		if !unsupportedActions(err) {
+			lastErr = err
+		}
+	}
+	if actions == nil {
+		if lastErr != nil {
+			return nil, fmt.Errorf("all data sources failed, last error: %v", lastErr)
+		}
+		actions = &CorporateActions{Symbol: symbol}
+	}
+	actions.sort()
+
+	m.actionsMu.Lock()
+	m.actions[symbol] = cachedCorporateActions{actions: actions, fetchedAt: time.Now()}
+	m.actionsMu.Unlock()
+	return actions.Between(from, to), nil
+}
+
+// fetchStockData 从主数据源获取未复权的股票数据，如果失败则尝试备用数据源（内部方法）
+func (m *Manager) fetchStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
 	m.mu.RLock()
 	primary := m.primary
 	dataSources := make(map[string]DataSource, len(m.dataSources))
@@ -225,6 +337,135 @@ func (m *Manager) GetStockData(ctx context.Context, symbol string, timeframe str
 	return nil, fmt.Errorf("no data sources available")
 }
 
+// GetTrades 从主数据源获取逐笔成交，如果失败则尝试其他提供逐笔成交的数据源
+func (m *Manager) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]TradePrint, error) {
+	m.mu.RLock()
+	primary := m.primary
+	dataSources := make(map[string]DataSource, len(m.dataSources))
+	for name, ds := range m.dataSources {
+		dataSources[name] = ds
+	}
+	m.mu.RUnlock()
+
+	// 首先尝试主数据源
+	if primaryDS, exists := dataSources[primary]; exists && primaryDS.IsEnabled() {
+		trades, err := primaryDS.GetTrades(ctx, symbol, from, to)
+		if err == nil {
+			return trades, nil
+		}
+
+		// 记录主数据源错误，但继续尝试备用数据源
+		fmt.Printf("Primary data source '%s' failed: %v\n", primary, err)
+	}
+
+	// 尝试其他数据源
+	var lastErr error
+	for name, ds := range dataSources {
+		if name == primary || !ds.IsEnabled() {
+			continue
+		}
+
+		trades, err := ds.GetTrades(ctx, symbol, from, to)
+		if err == nil {
+			return trades, nil
+		}
+
+		lastErr = err
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("all data sources failed, last error: %v", lastErr)
+	}
+
+	return nil, fmt.Errorf("no data sources available")
+}
+
+// GetOptionChain 从主数据源获取期权链，如果失败则尝试其他提供期权链的数据源
+func (m *Manager) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]OptionQuote, error) {
+	m.mu.RLock()
+	primary := m.primary
+	dataSources := make(map[string]DataSource, len(m.dataSources))
+	for name, ds := range m.dataSources {
+		dataSources[name] = ds
+	}
+	m.mu.RUnlock()
+
+	// 首先尝试主数据源
+	if primaryDS, exists := dataSources[primary]; exists && primaryDS.IsEnabled() {
+		chain, err := primaryDS.GetOptionChain(ctx, underlying, expiry)
+		if err == nil {
+			return chain, nil
+		}
+
+		// 记录主数据源错误，但继续尝试备用数据源
+		fmt.Printf("Primary data source '%s' failed: %v\n", primary, err)
+	}
+
+	// 尝试其他数据源
+	var lastErr error
+	for name, ds := range dataSources {
+		if name == primary || !ds.IsEnabled() {
+			continue
+		}
+
+		chain, err := ds.GetOptionChain(ctx, underlying, expiry)
+		if err == nil {
+			return chain, nil
+		}
+
+		lastErr = err
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("all data sources failed, last error: %v", lastErr)
+	}
+
+	return nil, fmt.Errorf("no data sources available")
+}
+
+// GetFundamentals 从主数据源获取基本面数据，如果失败则尝试其他提供基本面数据的数据源
+func (m *Manager) GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	m.mu.RLock()
+	primary := m.primary
+	dataSources := make(map[string]DataSource, len(m.dataSources))
+	for name, ds := range m.dataSources {
+		dataSources[name] = ds
+	}
+	m.mu.RUnlock()
+
+	// 首先尝试主数据源
+	if primaryDS, exists := dataSources[primary]; exists && primaryDS.IsEnabled() {
+		fundamentals, err := primaryDS.GetFundamentals(ctx, symbol)
+		if err == nil {
+			return fundamentals, nil
+		}
+
+		// 记录主数据源错误，但继续尝试备用数据源
+		fmt.Printf("Primary data source '%s' failed: %v\n", primary, err)
+	}
+
+	// 尝试其他数据源
+	var lastErr error
+	for name, ds := range dataSources {
+		if name == primary || !ds.IsEnabled() {
+			continue
+		}
+
+		fundamentals, err := ds.GetFundamentals(ctx, symbol)
+		if err == nil {
+			return fundamentals, nil
+		}
+
+		lastErr = err
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("all data sources failed, last error: %v", lastErr)
+	}
+
+	return nil, fmt.Errorf("no data sources available")
+}
+
 // CreatePolygonDataSource 创建一个Polygon.io数据源并添加到管理器
 func (m *Manager) CreatePolygonDataSource(config DataSourceConfig) error {
 	ds, err := NewPolygonDataSource(config)
@@ -0,0 +1,91 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// requestPacer 限制滑动窗口内的请求数，超出时等待而不是让数据源返回限流错误（内部类型）
+type requestPacer struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	sent        []time.Time // 窗口内已发出请求的时间，按时间排序
+	pausedUntil time.Time   // 数据源仍返回限流时暂停到该时间
+}
+
+// newRequestPacer 创建每个窗口最多limit个请求的限速器（内部函数）
+func newRequestPacer(limit int, window time.Duration) *requestPacer {
+	return &requestPacer{limit: limit, window: window}
+}
+
+// wait 等待到可以发出下一个请求并占用一个名额，上下文取消时返回上下文的错误（内部方法）
+func (p *requestPacer) wait(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		now := time.Now()
+		for len(p.sent) > 0 && !p.sent[0].After(now.Add(-p.window)) {
+			p.sent = p.sent[1:]
+		}
+		var until time.Time
+		switch {
+		case now.Before(p.pausedUntil):
+			until = p.pausedUntil
+		case len(p.sent) >= p.limit:
+			until = p.sent[0].Add(p.window)
+		default:
+			p.sent = append(p.sent, now)
+			p.mu.Unlock()
+			return nil
+		}
+		p.mu.Unlock()
+
+		timer := time.NewTimer(until.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// pause 暂停发出请求直到until（内部方法）
+func (p *requestPacer) pause(until time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until.After(p.pausedUntil) {
+		p.pausedUntil = until
+	}
+}
+
+// pacedTransport 按限速器发出请求；数据源仍返回429时（如同一密钥被其他程序使用），
+// 暂停所有请求到 Retry-After 或 cooldown 之后重试一次（内部类型）
+type pacedTransport struct {
+	pacer    *requestPacer
+	cooldown time.Duration
+	base     http.RoundTripper
+}
+
+// RoundTrip 等待限速器后发送请求，只用于没有请求体的GET请求
+func (t *pacedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.pacer.wait(req.Context()); err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt > 0 {
+			return resp, err
+		}
+		resp.Body.Close()
+
+		cooldown := t.cooldown
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			cooldown = time.Duration(seconds) * time.Second
+		}
+		t.pacer.pause(time.Now().Add(cooldown))
+	}
+}
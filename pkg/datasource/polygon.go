@@ -8,12 +8,26 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// polygonSnapshotBatchSize 是快照接口单次请求的最大股票数量
+const polygonSnapshotBatchSize = 250
+
+// polygonQuotesPageSize 是历史报价接口每页的最大条数
+const polygonQuotesPageSize = 50000
+
+// polygonTradesPageSize 是逐笔成交接口每页的最大条数
+const polygonTradesPageSize = 50000
+
+// polygonOptionsPageSize 是期权链快照接口每页的最大条数
+const polygonOptionsPageSize = 250
+
 // PolygonDataSource 实现了Polygon.io数据源
 type PolygonDataSource struct {
 	config     DataSourceConfig
+	keys       *keyPool
 	httpClient *http.Client
 }
 
@@ -32,16 +46,26 @@ func NewPolygonDataSource(config DataSourceConfig) (*PolygonDataSource, error) {
 		config.RetryDelaySeconds = 5 // 默认延迟5秒
 	}
 
+	// 所有请求经过密钥池添加 apiKey 参数，密钥被限流时自动换用其他密钥
+	keys := newKeyPool(append([]string{config.APIKey}, config.APIKeys...), config.KeySelection,
+		time.Duration(config.KeyCooldownSeconds)*time.Second)
 	httpClient := &http.Client{
-		Timeout: config.Timeout,
+		Timeout:   config.Timeout,
+		Transport: &keyTransport{pool: keys, base: http.DefaultTransport},
 	}
 
 	return &PolygonDataSource{
 		config:     config,
+		keys:       keys,
 		httpClient: httpClient,
 	}, nil
 }
 
+// KeyStatus 返回每个API密钥的请求次数和停用情况
+func (p *PolygonDataSource) KeyStatus() []KeyStatus {
+	return p.keys.status()
+}
+
 // Name 返回数据源名称
 func (p *PolygonDataSource) Name() string {
 	return "polygon"
@@ -55,8 +79,8 @@ func (p *PolygonDataSource) IsEnabled() bool {
 // HealthCheck 检查Polygon.io API的连接状态
 func (p *PolygonDataSource) HealthCheck(ctx context.Context) (bool, error) {
 	// 简单调用一个轻量级API检查连接是否正常
-	endpoint := fmt.Sprintf("%s/v1/marketstatus/now?apiKey=%s", 
-		p.config.BaseURL, p.config.APIKey)
+	endpoint := fmt.Sprintf("%s/v1/marketstatus/now",
+		p.config.BaseURL)
 	
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -94,13 +118,13 @@ func (p *PolygonDataSource) HealthCheck(ctx context.Context) (bool, error) {
 // GetStockData 获取指定股票的价格数据
 func (p *PolygonDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
 	// 构建API URL
-	endpoint := fmt.Sprintf("%s/v2/aggs/ticker/%s/range/1/%s/%s/%s?apiKey=%s",
+	// 获取未复权的K线，复权由管理器按公司行动统一处理
+	endpoint := fmt.Sprintf("%s/v2/aggs/ticker/%s/range/1/%s/%s/%s?adjusted=false",
 		p.config.BaseURL,
 		symbol,
 		timeframe,
 		from.Format("2006-01-02"),
-		to.Format("2006-01-02"),
-		p.config.APIKey)
+		to.Format("2006-01-02"))
 
 	// 发送请求
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
@@ -205,27 +229,19 @@ func (p *PolygonDataSource) GetStockData(ctx context.Context, symbol string, tim
 	return stockData, nil
 }
 
-// GetMultipleStockData 批量获取多只股票的价格数据
+// GetMultipleStockData 批量获取多只股票的价格数据，通过上下文报告进度
 func (p *PolygonDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]StockData, error) {
-	result := make(map[string][]StockData)
-	
 	// Polygon.io API不支持批量获取，所以这里逐个调用
-	for _, symbol := range symbols {
-		data, err := p.GetStockData(ctx, symbol, timeframe, from, to)
-		if err != nil {
-			return result, err
-		}
-		result[symbol] = data
-	}
-	
-	return result, nil
+	return FetchStockData(ctx, p.Name(), func(ctx context.Context, symbol string) ([]StockData, error) {
+		return p.GetStockData(ctx, symbol, timeframe, from, to)
+	}, symbols)
 }
 
 // GetRealTimeQuote 获取实时报价
 func (p *PolygonDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
 	// 构建API URL
-	endpoint := fmt.Sprintf("%s/v2/last/nbbo/%s?apiKey=%s",
-		p.config.BaseURL, symbol, p.config.APIKey)
+	endpoint := fmt.Sprintf("%s/v2/last/nbbo/%s",
+		p.config.BaseURL, symbol)
 
 	// 发送请求
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
@@ -298,17 +314,619 @@ func (p *PolygonDataSource) GetRealTimeQuote(ctx context.Context, symbol string)
 	return quote, nil
 }
 
-// GetAllStocks 获取所有可交易的股票列表
+// GetRealTimeQuotes 通过快照接口批量获取实时报价，每次请求最多250只股票
+// 快照中没有的股票不包含在结果中，并在返回的错误中列出
+func (p *PolygonDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	symbols = uniqueSymbols(symbols)
+	quotes := make(map[string]*Quote, len(symbols))
+
+	for start := 0; start < len(symbols); start += polygonSnapshotBatchSize {
+		end := start + polygonSnapshotBatchSize
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		if err := p.fetchSnapshot(ctx, symbols[start:end], quotes); err != nil {
+			return quotes, err
+		}
+	}
+
+	return quotes, missingQuotes(p.Name(), symbols, quotes)
+}
+
+// fetchSnapshot 获取一批股票的快照并写入quotes（内部方法）
+func (p *PolygonDataSource) fetchSnapshot(ctx context.Context, symbols []string, quotes map[string]*Quote) error {
+	// 构建API URL
+	endpoint := fmt.Sprintf("%s/v2/snapshot/locale/us/markets/stocks/tickers?tickers=%s",
+		p.config.BaseURL, url.QueryEscape(strings.Join(symbols, ",")))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return &DataSourceError{
+			Source:  p.Name(),
+			Code:    "REQUEST_CREATION_ERROR",
+			Message: fmt.Sprintf("Failed to create request: %v", err),
+			Time:    time.Now(),
+		}
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return &DataSourceError{
+			Source:  p.Name(),
+			Code:    "CONNECTION_ERROR",
+			Message: fmt.Sprintf("Connection failed: %v", err),
+			Time:    time.Now(),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return &DataSourceError{
+			Source:  p.Name(),
+			Code:    "API_ERROR",
+			Message: fmt.Sprintf("API returned status code %d: %s", resp.StatusCode, string(bodyBytes)),
+			Time:    time.Now(),
+		}
+	}
+
+	// 解析响应
+	var result struct {
+		Status  string `json:"status"`
+		Tickers []struct {
+			Ticker    string `json:"ticker"`
+			LastQuote struct {
+				AP float64 `json:"P"` // 卖出价
+				AS int64   `json:"S"` // 卖出量
+				BP float64 `json:"p"` // 买入价
+				BS int64   `json:"s"` // 买入量
+				T  int64   `json:"t"` // 时间戳（纳秒）
+			} `json:"lastQuote"`
+			LastTrade struct {
+				P float64 `json:"p"` // 成交价
+				S int64   `json:"s"` // 成交量
+				T int64   `json:"t"` // 时间戳（纳秒）
+			} `json:"lastTrade"`
+		} `json:"tickers"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return &DataSourceError{
+			Source:  p.Name(),
+			Code:    "RESPONSE_PARSE_ERROR",
+			Message: fmt.Sprintf("Failed to parse response: %v", err),
+			Time:    time.Now(),
+		}
+	}
+
+	// 转换为标准格式，时间戳取报价和成交中较新者
+	for _, ticker := range result.Tickers {
+		ts := ticker.LastQuote.T
+		if ticker.LastTrade.T > ts {
+			ts = ticker.LastTrade.T
+		}
+		quotes[ticker.Ticker] = &Quote{
+			Symbol:        ticker.Ticker,
+			Timestamp:     time.Unix(0, ts),
+			AskPrice:      ticker.LastQuote.AP,
+			AskSize:       ticker.LastQuote.AS,
+			BidPrice:      ticker.LastQuote.BP,
+			BidSize:       ticker.LastQuote.BS,
+			LastPrice:     ticker.LastTrade.P,
+			LastSize:      ticker.LastTrade.S,
+			TransactionID: fmt.Sprintf("polygon_%s_%d", ticker.Ticker, ts),
+		}
+	}
+
+	return nil
+}
+
+// GetHistoricalQuotes 通过 /v3/quotes 接口分页获取[from, to]内的NBBO历史报价，按时间升序排列
+// 报价时间使用SIP时间戳
+func (p *PolygonDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]Quote, error) {
+	// 构建API URL，时间范围使用纳秒时间戳
+	endpoint := fmt.Sprintf("%s/v3/quotes/%s?timestamp.gte=%d&timestamp.lte=%d&order=asc&sort=timestamp&limit=%d",
+		p.config.BaseURL, url.PathEscape(symbol), from.UnixNano(), to.UnixNano(), polygonQuotesPageSize)
+
+	var quotes []Quote
+	nextURL := endpoint
+
+	progress := Progress{Operation: OperationGetHistoricalQuotes}
+
+	// 分页获取所有数据，每页结束后报告进度，上下文取消时返回已获取的数据
+	for nextURL != "" {
+		if ctx.Err() != nil {
+			return quotes, cancelledError(p.Name(), progress)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
+		if err != nil {
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "REQUEST_CREATION_ERROR",
+				Message: fmt.Sprintf("Failed to create request: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return quotes, cancelledError(p.Name(), progress)
+			}
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "CONNECTION_ERROR",
+				Message: fmt.Sprintf("Connection failed: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "API_ERROR",
+				Message: fmt.Sprintf("API returned status code %d: %s", resp.StatusCode, string(bodyBytes)),
+				Time:    time.Now(),
+			}
+		}
+
+		// 解析响应
+		var result struct {
+			Status  string `json:"status"`
+			NextURL string `json:"next_url"`
+			Results []struct {
+				AskPrice     float64 `json:"ask_price"`
+				AskSize      int64   `json:"ask_size"`
+				BidPrice     float64 `json:"bid_price"`
+				BidSize      int64   `json:"bid_size"`
+				SIPTimestamp int64   `json:"sip_timestamp"` // 时间戳（纳秒）
+				Sequence     int64   `json:"sequence_number"`
+			} `json:"results"`
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "RESPONSE_PARSE_ERROR",
+				Message: fmt.Sprintf("Failed to parse response: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		// 转换为标准格式
+		for _, item := range result.Results {
+			quotes = append(quotes, Quote{
+				Symbol:        symbol,
+				Timestamp:     time.Unix(0, item.SIPTimestamp),
+				AskPrice:      item.AskPrice,
+				AskSize:       item.AskSize,
+				BidPrice:      item.BidPrice,
+				BidSize:       item.BidSize,
+				TransactionID: fmt.Sprintf("polygon_%s_q%d", symbol, item.Sequence),
+			})
+		}
+
+		progress.Done++
+		progress.Items = len(quotes)
+		reportProgress(ctx, progress)
+
+		nextURL = result.NextURL
+	}
+
+	return quotes, nil
+}
+
+// GetTrades 通过 /v3/trades 接口分页获取[from, to]内的逐笔成交，按时间升序排列
+// 成交时间使用SIP时间戳，零股成交的数量向下取整
+func (p *PolygonDataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]TradePrint, error) {
+	endpoint := fmt.Sprintf("%s/v3/trades/%s?timestamp.gte=%d&timestamp.lte=%d&order=asc&sort=timestamp&limit=%d",
+		p.config.BaseURL, url.PathEscape(symbol), from.UnixNano(), to.UnixNano(), polygonTradesPageSize)
+
+	var trades []TradePrint
+	nextURL := endpoint
+
+	progress := Progress{Operation: OperationGetTrades}
+
+	// 分页获取所有数据，每页结束后报告进度，上下文取消时返回已获取的数据
+	for nextURL != "" {
+		if ctx.Err() != nil {
+			return trades, cancelledError(p.Name(), progress)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
+		if err != nil {
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "REQUEST_CREATION_ERROR",
+				Message: fmt.Sprintf("Failed to create request: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return trades, cancelledError(p.Name(), progress)
+			}
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "CONNECTION_ERROR",
+				Message: fmt.Sprintf("Connection failed: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "API_ERROR",
+				Message: fmt.Sprintf("API returned status code %d: %s", resp.StatusCode, string(bodyBytes)),
+				Time:    time.Now(),
+			}
+		}
+
+		// 解析响应
+		var result struct {
+			Status  string `json:"status"`
+			NextURL string `json:"next_url"`
+			Results []struct {
+				Conditions   []int   `json:"conditions"`
+				Exchange     int     `json:"exchange"`
+				ID           string  `json:"id"`
+				Price        float64 `json:"price"`
+				Size         float64 `json:"size"`
+				SIPTimestamp int64   `json:"sip_timestamp"` // 时间戳（纳秒）
+				Sequence     int64   `json:"sequence_number"`
+			} `json:"results"`
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "RESPONSE_PARSE_ERROR",
+				Message: fmt.Sprintf("Failed to parse response: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		// 转换为标准格式
+		for _, item := range result.Results {
+			trades = append(trades, TradePrint{
+				Symbol:        symbol,
+				Timestamp:     time.Unix(0, item.SIPTimestamp),
+				Price:         item.Price,
+				Size:          int64(item.Size),
+				Exchange:      item.Exchange,
+				Conditions:    item.Conditions,
+				ID:            item.ID,
+				TransactionID: fmt.Sprintf("polygon_%s_t%d", symbol, item.Sequence),
+			})
+		}
+
+		progress.Done++
+		progress.Items = len(trades)
+		reportProgress(ctx, progress)
+
+		nextURL = result.NextURL
+	}
+
+	return trades, nil
+}
+
+// GetOptionChain 通过 /v3/snapshot/options 接口分页获取标的股票的期权链快照，expiry 为零值时返回所有到期日
+// 报价和希腊值来自快照，没有实时期权行情权限时为延迟数据；没有报价的合约买卖价为0
+func (p *PolygonDataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]OptionQuote, error) {
+	endpoint := fmt.Sprintf("%s/v3/snapshot/options/%s?limit=%d", p.config.BaseURL, url.PathEscape(underlying), polygonOptionsPageSize)
+	if !expiry.IsZero() {
+		endpoint += "&expiration_date=" + expiry.Format("2006-01-02")
+	}
+
+	var chain []OptionQuote
+	nextURL := endpoint
+
+	progress := Progress{Operation: OperationGetOptionChain}
+
+	// 分页获取所有合约，每页结束后报告进度，上下文取消时返回已获取的数据
+	for nextURL != "" {
+		if ctx.Err() != nil {
+			return chain, cancelledError(p.Name(), progress)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
+		if err != nil {
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "REQUEST_CREATION_ERROR",
+				Message: fmt.Sprintf("Failed to create request: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return chain, cancelledError(p.Name(), progress)
+			}
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "CONNECTION_ERROR",
+				Message: fmt.Sprintf("Connection failed: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "API_ERROR",
+				Message: fmt.Sprintf("API returned status code %d: %s", resp.StatusCode, string(bodyBytes)),
+				Time:    time.Now(),
+			}
+		}
+
+		// 解析响应
+		var result struct {
+			Status  string `json:"status"`
+			NextURL string `json:"next_url"`
+			Results []struct {
+				Details struct {
+					ContractType      string  `json:"contract_type"`
+					ExerciseStyle     string  `json:"exercise_style"`
+					ExpirationDate    string  `json:"expiration_date"`
+					SharesPerContract int64   `json:"shares_per_contract"`
+					StrikePrice       float64 `json:"strike_price"`
+					Ticker            string  `json:"ticker"`
+				} `json:"details"`
+				LastQuote struct {
+					Ask         float64 `json:"ask"`
+					AskSize     int64   `json:"ask_size"`
+					Bid         float64 `json:"bid"`
+					BidSize     int64   `json:"bid_size"`
+					LastUpdated int64   `json:"last_updated"` // 纳秒
+				} `json:"last_quote"`
+				LastTrade struct {
+					Price float64 `json:"price"`
+				} `json:"last_trade"`
+				Day struct {
+					Volume float64 `json:"volume"`
+				} `json:"day"`
+				Greeks struct {
+					Delta float64 `json:"delta"`
+					Gamma float64 `json:"gamma"`
+					Theta float64 `json:"theta"`
+					Vega  float64 `json:"vega"`
+				} `json:"greeks"`
+				ImpliedVolatility float64 `json:"implied_volatility"`
+				OpenInterest      int64   `json:"open_interest"`
+				UnderlyingAsset   struct {
+					Price float64 `json:"price"`
+				} `json:"underlying_asset"`
+			} `json:"results"`
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, &DataSourceError{
+				Source:  p.Name(),
+				Code:    "RESPONSE_PARSE_ERROR",
+				Message: fmt.Sprintf("Failed to parse response: %v", err),
+				Time:    time.Now(),
+			}
+		}
+
+		// 转换为标准格式，跳过到期日无法解析的合约
+		for _, item := range result.Results {
+			expiration, err := time.Parse("2006-01-02", item.Details.ExpirationDate)
+			if err != nil {
+				continue
+			}
+			quote := OptionQuote{
+				Contract: OptionContract{
+					Symbol:            item.Details.Ticker,
+					Underlying:        underlying,
+					Type:              OptionType(item.Details.ContractType),
+					Strike:            item.Details.StrikePrice,
+					Expiry:            expiration,
+					ExerciseStyle:     item.Details.ExerciseStyle,
+					SharesPerContract: item.Details.SharesPerContract,
+				},
+				BidPrice:          item.LastQuote.Bid,
+				BidSize:           item.LastQuote.BidSize,
+				AskPrice:          item.LastQuote.Ask,
+				AskSize:           item.LastQuote.AskSize,
+				LastPrice:         item.LastTrade.Price,
+				Volume:            int64(item.Day.Volume),
+				OpenInterest:      item.OpenInterest,
+				ImpliedVolatility: item.ImpliedVolatility,
+				Delta:             item.Greeks.Delta,
+				Gamma:             item.Greeks.Gamma,
+				Theta:             item.Greeks.Theta,
+				Vega:              item.Greeks.Vega,
+				UnderlyingPrice:   item.UnderlyingAsset.Price,
+			}
+			if item.LastQuote.LastUpdated > 0 {
+				quote.Timestamp = time.Unix(0, item.LastQuote.LastUpdated)
+			}
+			if quote.Contract.SharesPerContract == 0 {
+				quote.Contract.SharesPerContract = 100
+			}
+			chain = append(chain, quote)
+		}
+
+		progress.Done++
+		progress.Items = len(chain)
+		reportProgress(ctx, progress)
+
+		nextURL = result.NextURL
+	}
+
+	SortOptionChain(chain)
+	return chain, nil
+}
+
+// GetFundamentals 通过 /v3/reference/tickers 接口获取市值、股本和行业（SIC分类），通过 /vX/reference/financials
+// 获取TTM每股收益并按市值折算的股价计算市盈率；自由流通股来自 /stocks/vX/float，该接口需要相应的数据权限，失败时流通股为0
+func (p *PolygonDataSource) GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	var details struct {
+		Results struct {
+			Name                        string  `json:"name"`
+			MarketCap                   float64 `json:"market_cap"`
+			SICDescription              string  `json:"sic_description"`
+			ShareClassSharesOutstanding int64   `json:"share_class_shares_outstanding"`
+			WeightedSharesOutstanding   int64   `json:"weighted_shares_outstanding"`
+		} `json:"results"`
+	}
+	if err := p.get(ctx, fmt.Sprintf("%s/v3/reference/tickers/%s", p.config.BaseURL, url.PathEscape(symbol)), &details); err != nil {
+		return nil, err
+	}
+
+	var financials struct {
+		Results []struct {
+			Financials struct {
+				IncomeStatement struct {
+					DilutedEPS struct {
+						Value float64 `json:"value"`
+					} `json:"diluted_earnings_per_share"`
+					BasicEPS struct {
+						Value float64 `json:"value"`
+					} `json:"basic_earnings_per_share"`
+				} `json:"income_statement"`
+			} `json:"financials"`
+		} `json:"results"`
+	}
+	query := url.Values{"ticker": {symbol}, "timeframe": {"ttm"}, "limit": {"1"}, "order": {"desc"}, "sort": {"period_of_report_date"}}
+	if err := p.get(ctx, p.config.BaseURL+"/vX/reference/financials?"+query.Encode(), &financials); err != nil {
+		return nil, err
+	}
+
+	result := details.Results
+	fundamentals := &Fundamentals{
+		Symbol:            symbol,
+		Name:              result.Name,
+		Sector:            result.SICDescription,
+		MarketCap:         result.MarketCap,
+		SharesOutstanding: result.ShareClassSharesOutstanding,
+	}
+	if fundamentals.SharesOutstanding == 0 {
+		fundamentals.SharesOutstanding = result.WeightedSharesOutstanding
+	}
+	if len(financials.Results) > 0 {
+		income := financials.Results[0].Financials.IncomeStatement
+		fundamentals.EPS = income.DilutedEPS.Value
+		if fundamentals.EPS == 0 {
+			fundamentals.EPS = income.BasicEPS.Value
+		}
+	}
+	if fundamentals.EPS > 0 && fundamentals.SharesOutstanding > 0 {
+		price := fundamentals.MarketCap / float64(fundamentals.SharesOutstanding)
+		fundamentals.PERatio = price / fundamentals.EPS
+	}
+
+	var float struct {
+		Results []struct {
+			FreeFloat int64 `json:"free_float"`
+		} `json:"results"`
+	}
+	if err := p.get(ctx, p.config.BaseURL+"/stocks/vX/float?"+url.Values{"ticker": {symbol}}.Encode(), &float); err == nil && len(float.Results) > 0 {
+		fundamentals.FloatShares = float.Results[0].FreeFloat
+	}
+
+	return fundamentals, nil
+}
+
+// GetCorporateActions 通过 /v3/reference/splits 和 /v3/reference/dividends 接口分页获取拆股和现金分红
+func (p *PolygonDataSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error) {
+	actions := &CorporateActions{Symbol: symbol, Splits: []Split{}, Dividends: []CashDividend{}}
+	query := func(field string) string {
+		values := url.Values{"ticker": {symbol}, "limit": {"1000"}}
+		if !from.IsZero() {
+			values.Set(field+".gte", from.Format("2006-01-02"))
+		}
+		if !to.IsZero() {
+			values.Set(field+".lte", to.Format("2006-01-02"))
+		}
+		return values.Encode()
+	}
+
+	for endpoint := p.config.BaseURL + "/v3/reference/splits?" + query("execution_date"); endpoint != ""; {
+		var page struct {
+			NextURL string `json:"next_url"`
+			Results []struct {
+				ExecutionDate string  `json:"execution_date"`
+				SplitFrom     float64 `json:"split_from"`
+				SplitTo       float64 `json:"split_to"`
+			} `json:"results"`
+		}
+		if err := p.get(ctx, endpoint, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Results {
+			exDate, err := time.Parse("2006-01-02", item.ExecutionDate)
+			if err != nil {
+				continue
+			}
+			actions.Splits = append(actions.Splits, Split{Symbol: symbol, ExDate: exDate, From: item.SplitFrom, To: item.SplitTo})
+		}
+		endpoint = page.NextURL
+	}
+
+	for endpoint := p.config.BaseURL + "/v3/reference/dividends?" + query("ex_dividend_date"); endpoint != ""; {
+		var page struct {
+			NextURL string `json:"next_url"`
+			Results []struct {
+				CashAmount     float64 `json:"cash_amount"`
+				ExDividendDate string  `json:"ex_dividend_date"`
+				PayDate        string  `json:"pay_date"`
+			} `json:"results"`
+		}
+		if err := p.get(ctx, endpoint, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Results {
+			exDate, err := time.Parse("2006-01-02", item.ExDividendDate)
+			if err != nil || item.CashAmount <= 0 {
+				continue
+			}
+			dividend := CashDividend{Symbol: symbol, ExDate: exDate, CashAmount: item.CashAmount}
+			dividend.PayDate, _ = time.Parse("2006-01-02", item.PayDate)
+			actions.Dividends = append(actions.Dividends, dividend)
+		}
+		endpoint = page.NextURL
+	}
+
+	actions.sort()
+	return actions, nil
+}
+
+// GetAllStocks 分页获取所有可交易的股票列表，通过上下文报告进度
 func (p *PolygonDataSource) GetAllStocks(ctx context.Context) ([]Stock, error) {
 	// 构建API URL，Polygon.io的参数允许设置每页数量和市场类型
-	endpoint := fmt.Sprintf("%s/v3/reference/tickers?market=stocks&active=true&limit=1000&apiKey=%s",
-		p.config.BaseURL, p.config.APIKey)
+	endpoint := fmt.Sprintf("%s/v3/reference/tickers?market=stocks&active=true&limit=1000",
+		p.config.BaseURL)
 
 	var allStocks []Stock
 	var nextURL string = endpoint
 
-	// 分页获取所有数据
+	progress := Progress{Operation: OperationGetAllStocks}
+
+	// 分页获取所有数据，每页结束后报告进度，上下文取消时返回已获取的数据
 	for nextURL != "" {
+		if ctx.Err() != nil {
+			return allStocks, cancelledError(p.Name(), progress)
+		}
+
 		// 发送请求
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
 		if err != nil {
@@ -322,6 +940,9 @@ func (p *PolygonDataSource) GetAllStocks(ctx context.Context) ([]Stock, error) {
 
 		resp, err := p.httpClient.Do(req)
 		if err != nil {
+			if ctx.Err() != nil {
+				return allStocks, cancelledError(p.Name(), progress)
+			}
 			return nil, &DataSourceError{
 				Source:  p.Name(),
 				Code:    "CONNECTION_ERROR",
@@ -381,27 +1002,12 @@ func (p *PolygonDataSource) GetAllStocks(ctx context.Context) ([]Stock, error) {
 			})
 		}
 
+		progress.Done++
+		progress.Items = len(allStocks)
+		reportProgress(ctx, progress)
+
 		// 检查是否有下一页
-		if result.NextURL != "" {
-			// 提取next_url并添加API密钥
-			parsedURL, err := url.Parse(result.NextURL)
-			if err != nil {
-				return nil, &DataSourceError{
-					Source:  p.Name(),
-					Code:    "URL_PARSE_ERROR",
-					Message: fmt.Sprintf("Failed to parse next_url: %v", err),
-					Time:    time.Now(),
-				}
-			}
-			
-			q := parsedURL.Query()
-			q.Set("apiKey", p.config.APIKey)
-			parsedURL.RawQuery = q.Encode()
-			
-			nextURL = parsedURL.String()
-		} else {
-			nextURL = ""
-		}
+		nextURL = result.NextURL
 	}
 
 	return allStocks, nil
@@ -411,4 +1017,48 @@ func (p *PolygonDataSource) GetAllStocks(ctx context.Context) ([]Stock, error) {
 func (p *PolygonDataSource) Close() error {
 	// HTTP客户端不需要显式关闭
 	return nil
-} 
\ No newline at end of file
+} 
+
+// get 发送GET请求并解析JSON响应，错误转换为数据源错误（内部方法）
+func (p *PolygonDataSource) get(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return &DataSourceError{
+			Source:  p.Name(),
+			Code:    "REQUEST_CREATION_ERROR",
+			Message: fmt.Sprintf("Failed to create request: %v", err),
+			Time:    time.Now(),
+		}
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return &DataSourceError{
+			Source:  p.Name(),
+			Code:    "CONNECTION_ERROR",
+			Message: fmt.Sprintf("Connection failed: %v", err),
+			Time:    time.Now(),
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return &DataSourceError{
+			Source:  p.Name(),
+			Code:    "API_ERROR",
+			Message: fmt.Sprintf("API returned status code %d: %s", resp.StatusCode, string(bodyBytes)),
+			Time:    time.Now(),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &DataSourceError{
+			Source:  p.Name(),
+			Code:    "RESPONSE_PARSE_ERROR",
+			Message: fmt.Sprintf("Failed to parse response: %v", err),
+			Time:    time.Now(),
+		}
+	}
+	return nil
+}
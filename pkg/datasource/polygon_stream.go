@@ -0,0 +1,286 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// DefaultPolygonStreamURL 是Polygon.io股票实时行情的WebSocket地址
+const DefaultPolygonStreamURL = "wss://socket.polygon.io/stocks"
+
+// 断线重连的等待时间，每次失败加倍直到上限
+const (
+	polygonReconnectMin = time.Second
+	polygonReconnectMax = 30 * time.Second
+)
+
+// polygonStreamMessage 是Polygon.io推送的一条消息，不同事件类型使用其中的部分字段（内部类型）
+type polygonStreamMessage struct {
+	Event     string  `json:"ev"`      // status、Q（报价）、T（成交）或 LULD
+	Status    string  `json:"status"`  // status 事件的状态，如 connected、auth_success、auth_failed
+	Message   string  `json:"message"` // status 事件的说明
+	Symbol    string  `json:"sym"`
+	Ticker    string  `json:"T"` // LULD 事件的股票代码
+	BidPrice  float64 `json:"bp"`
+	BidSize   int64   `json:"bs"`
+	AskPrice  float64 `json:"ap"`
+	AskSize   int64   `json:"as"`
+	Price     float64 `json:"p"`
+	Size      int64   `json:"s"`
+	High      float64 `json:"h"` // LULD 价格区间上限
+	Low       float64 `json:"l"` // LULD 价格区间下限
+	Timestamp int64   `json:"t"` // Unix毫秒
+}
+
+// polygonStream 维护一个WebSocket订阅，合并报价、成交和LULD事件后推送完整的报价（内部类型）
+type polygonStream struct {
+	source  *PolygonDataSource
+	symbols []string
+	out     chan Quote
+	quotes  map[string]*Quote // 每只股票合并后的最新报价
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// SubscribeQuotes 通过Polygon.io的WebSocket订阅股票的报价、成交和LULD价格区间，每次更新推送合并后的报价
+// 首次连接或认证失败时返回错误；之后断线自动重连并重新订阅，通道满时丢弃报价，上下文取消后关闭通道
+func (p *PolygonDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	symbols = uniqueSymbols(symbols)
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols to subscribe")
+	}
+
+	stream := &polygonStream{
+		source:  p,
+		symbols: symbols,
+		out:     make(chan Quote, quoteStreamBuffer),
+		quotes:  make(map[string]*Quote, len(symbols)),
+	}
+	conn, err := stream.connect()
+	if err != nil {
+		return nil, err
+	}
+	stream.setConn(conn)
+
+	// 上下文取消时关闭连接，使阻塞的读取返回
+	go func() {
+		<-ctx.Done()
+		stream.setConn(nil)
+	}()
+	go stream.run(ctx, conn)
+	return stream.out, nil
+}
+
+// streamURL 返回WebSocket地址（内部方法）
+func (p *PolygonDataSource) streamURL() string {
+	if p.config.StreamURL != "" {
+		return p.config.StreamURL
+	}
+	return DefaultPolygonStreamURL
+}
+
+// streamKey 返回用于WebSocket认证的密钥（内部方法）
+func (p *PolygonDataSource) streamKey() (string, error) {
+	if len(p.keys.keys) == 0 {
+		return "", fmt.Errorf("polygon websocket requires an API key")
+	}
+	key, ok := p.keys.acquire(time.Now())
+	if !ok {
+		return "", fmt.Errorf("all API keys are rate limited or revoked")
+	}
+	return key.value, nil
+}
+
+// connect 建立连接、认证并订阅所有股票（内部方法）
+func (s *polygonStream) connect() (*websocket.Conn, error) {
+	p := s.source
+	fail := func(code string, err error) error {
+		return &DataSourceError{Source: p.Name(), Code: code, Message: err.Error(), Time: time.Now()}
+	}
+
+	key, err := p.streamKey()
+	if err != nil {
+		return nil, fail("AUTH_ERROR", err)
+	}
+	config, err := websocket.NewConfig(p.streamURL(), "http://localhost/")
+	if err != nil {
+		return nil, fail("REQUEST_CREATION_ERROR", err)
+	}
+	config.Dialer = &net.Dialer{Timeout: p.config.Timeout}
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, fail("CONNECTION_ERROR", err)
+	}
+
+	// 认证和订阅的响应在超时时间内返回
+	conn.SetReadDeadline(time.Now().Add(p.config.Timeout))
+	if err := websocket.JSON.Send(conn, map[string]string{"action": "auth", "params": key}); err != nil {
+		conn.Close()
+		return nil, fail("CONNECTION_ERROR", err)
+	}
+	if err := s.awaitStatus(conn, "auth_success", "auth_failed"); err != nil {
+		conn.Close()
+		return nil, fail("AUTH_ERROR", err)
+	}
+
+	channels := make([]string, 0, len(s.symbols)*3)
+	for _, symbol := range s.symbols {
+		channels = append(channels, "Q."+symbol, "T."+symbol, "LULD."+symbol)
+	}
+	subscribe := map[string]string{"action": "subscribe", "params": strings.Join(channels, ",")}
+	if err := websocket.JSON.Send(conn, subscribe); err != nil {
+		conn.Close()
+		return nil, fail("CONNECTION_ERROR", err)
+	}
+	conn.SetReadDeadline(time.Time{})
+	return conn, nil
+}
+
+// awaitStatus 读取消息直到收到成功或失败的状态，期间的行情消息照常处理（内部方法）
+func (s *polygonStream) awaitStatus(conn *websocket.Conn, success, failure string) error {
+	for {
+		messages, err := receiveMessages(conn)
+		if err != nil {
+			return err
+		}
+		for _, msg := range messages {
+			if msg.Event != "status" {
+				s.handle(msg)
+				continue
+			}
+			switch msg.Status {
+			case success:
+				return nil
+			case failure:
+				return fmt.Errorf("%s: %s", msg.Status, msg.Message)
+			}
+		}
+	}
+}
+
+// receiveMessages 读取一帧消息，连接断开时返回错误；无法解析的消息忽略，不影响连接（内部函数）
+func receiveMessages(conn *websocket.Conn) ([]polygonStreamMessage, error) {
+	var data []byte
+	if err := websocket.Message.Receive(conn, &data); err != nil {
+		return nil, err
+	}
+	var messages []polygonStreamMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, nil
+	}
+	return messages, nil
+}
+
+// setConn 替换当前连接并关闭旧连接，conn 为空时只关闭（内部方法）
+func (s *polygonStream) setConn(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil && s.conn != conn {
+		s.conn.Close()
+	}
+	s.conn = conn
+}
+
+// run 读取推送直到上下文取消，断线后按退避时间重连（内部方法）
+func (s *polygonStream) run(ctx context.Context, conn *websocket.Conn) {
+	defer close(s.out)
+	defer s.setConn(nil)
+
+	for {
+		s.read(conn)
+		if ctx.Err() != nil {
+			return
+		}
+
+		var err error
+		conn, err = s.reconnect(ctx)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// read 读取并处理推送，直到连接断开（内部方法）
+func (s *polygonStream) read(conn *websocket.Conn) {
+	for {
+		messages, err := receiveMessages(conn)
+		if err != nil {
+			return
+		}
+		for _, msg := range messages {
+			s.handle(msg)
+		}
+	}
+}
+
+// reconnect 按退避时间重连直到成功或上下文取消（内部方法）
+func (s *polygonStream) reconnect(ctx context.Context) (*websocket.Conn, error) {
+	wait := polygonReconnectMin
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		conn, err := s.connect()
+		if err == nil {
+			s.mu.Lock()
+			canceled := ctx.Err() != nil
+			if !canceled {
+				s.conn = conn
+			}
+			s.mu.Unlock()
+			if canceled {
+				conn.Close()
+				return nil, ctx.Err()
+			}
+			return conn, nil
+		}
+		wait = min(wait*2, polygonReconnectMax)
+	}
+}
+
+// handle 将一条行情消息合并到股票的最新报价并推送，通道满时丢弃（内部方法）
+func (s *polygonStream) handle(msg polygonStreamMessage) {
+	symbol := msg.Symbol
+	if msg.Event == "LULD" {
+		symbol = msg.Ticker
+	}
+	quote, ok := s.quotes[symbol]
+	if !ok {
+		if symbol == "" {
+			return
+		}
+		quote = &Quote{Symbol: symbol}
+		s.quotes[symbol] = quote
+	}
+
+	switch msg.Event {
+	case "Q":
+		quote.BidPrice, quote.BidSize = msg.BidPrice, msg.BidSize
+		quote.AskPrice, quote.AskSize = msg.AskPrice, msg.AskSize
+	case "T":
+		quote.LastPrice, quote.LastSize = msg.Price, msg.Size
+	case "LULD":
+		quote.LimitUp, quote.LimitDown = msg.High, msg.Low
+	default:
+		return
+	}
+	if msg.Timestamp > 0 {
+		quote.Timestamp = time.UnixMilli(msg.Timestamp)
+	}
+
+	select {
+	case s.out <- *quote:
+	default:
+	}
+}
@@ -0,0 +1,83 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 报告进度的下载操作
+const (
+	OperationGetAllStocks        = "get_all_stocks"
+	OperationGetMultipleData     = "get_multiple_stock_data"
+	OperationGetHistoricalQuotes = "get_historical_quotes"
+	OperationGetTrades           = "get_trades"
+	OperationGetOptionChain      = "get_option_chain"
+)
+
+// Progress 表示一次分页或逐个股票下载的进度
+type Progress struct {
+	Operation string `json:"operation"`
+	Symbol    string `json:"symbol,omitempty"` // 刚完成的股票，按页下载时为空
+	Done      int    `json:"done"`             // 已完成的页数或股票数
+	Total     int    `json:"total,omitempty"`  // 总页数或股票数，未知时为0
+	Items     int    `json:"items"`            // 已获取的记录数
+}
+
+// ProgressFunc 接收下载进度，在下载所在的goroutine中同步调用，不应阻塞
+type ProgressFunc func(Progress)
+
+// progressKey 是上下文中进度回调的键（内部类型）
+type progressKey struct{}
+
+// WithProgress 返回带有进度回调的上下文，GetAllStocks、GetMultipleStockData、GetHistoricalQuotes、GetTrades 和 GetOptionChain
+// 每完成一页或一只股票调用一次fn。取消上下文会在当前请求结束后停止下载，并返回已获取的数据和 CONTEXT_CANCELLED 错误
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress 调用上下文中的进度回调（内部函数）
+func reportProgress(ctx context.Context, progress Progress) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		fn(progress)
+	}
+}
+
+// cancelledError 返回下载被取消的错误，说明已完成的进度（内部函数）
+func cancelledError(source string, progress Progress) *DataSourceError {
+	return &DataSourceError{
+		Source:  source,
+		Code:    "CONTEXT_CANCELLED",
+		Message: fmt.Sprintf("%s cancelled after %d of %d, %d items fetched", progress.Operation, progress.Done, progress.Total, progress.Items),
+		Time:    time.Now(),
+	}
+}
+
+// StockDataFunc 获取单只股票的价格数据
+type StockDataFunc func(ctx context.Context, symbol string) ([]StockData, error)
+
+// FetchStockData 逐个获取多只股票的价格数据并报告进度，用于没有批量接口的数据源
+// 出错或上下文取消时停止，返回已获取的数据和错误
+func FetchStockData(ctx context.Context, source string, fetch StockDataFunc, symbols []string) (map[string][]StockData, error) {
+	result := make(map[string][]StockData, len(symbols))
+	progress := Progress{Operation: OperationGetMultipleData, Total: len(symbols)}
+	for _, symbol := range symbols {
+		if ctx.Err() != nil {
+			return result, cancelledError(source, progress)
+		}
+		data, err := fetch(ctx, symbol)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, cancelledError(source, progress)
+			}
+			return result, err
+		}
+		result[symbol] = data
+
+		progress.Symbol = symbol
+		progress.Done++
+		progress.Items += len(data)
+		reportProgress(ctx, progress)
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetAllStocksProgress(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		fmt.Fprintf(w, `{"status":"OK","next_url":"%s/v3/reference/tickers?page=%d","results":[{"ticker":"S%d","active":true},{"ticker":"T%d","active":true}]}`,
+			server.URL, page+1, page, page)
+	}))
+	defer server.Close()
+	source, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL})
+
+	// 下载3页后取消，返回已获取的股票
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var reports []Progress
+	ctx = WithProgress(ctx, func(p Progress) {
+		reports = append(reports, p)
+		if p.Done == 3 {
+			cancel()
+		}
+	})
+
+	stocks, err := source.GetAllStocks(ctx)
+	var dsErr *DataSourceError
+	if !errors.As(err, &dsErr) || dsErr.Code != "CONTEXT_CANCELLED" {
+		t.Fatalf("取消后应返回 CONTEXT_CANCELLED 错误: %v", err)
+	}
+	if len(stocks) != 6 {
+		t.Errorf("取消后应返回已获取的 6 只股票, 实际 %d", len(stocks))
+	}
+	if len(reports) != 3 || reports[2] != (Progress{Operation: OperationGetAllStocks, Done: 3, Items: 6}) {
+		t.Errorf("进度报告不正确: %+v", reports)
+	}
+}
+
+func TestFetchStockDataProgress(t *testing.T) {
+	fetch := func(ctx context.Context, symbol string) ([]StockData, error) {
+		if symbol == "BAD" {
+			return nil, errors.New("not found")
+		}
+		return []StockData{{Symbol: symbol, Timestamp: time.Now()}}, nil
+	}
+
+	var reports []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) { reports = append(reports, p) })
+	data, err := FetchStockData(ctx, "test", fetch, []string{"AAPL", "MSFT", "BAD", "NVDA"})
+	if err == nil || len(data) != 2 {
+		t.Errorf("出错时应停止并返回已获取的数据: %d %v", len(data), err)
+	}
+	if len(reports) != 2 || reports[1] != (Progress{Operation: OperationGetMultipleData, Symbol: "MSFT", Done: 2, Total: 4, Items: 2}) {
+		t.Errorf("进度报告不正确: %+v", reports)
+	}
+}
@@ -0,0 +1,104 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultQuoteConcurrency 是没有批量接口的数据源逐个获取报价时的默认并发数
+const DefaultQuoteConcurrency = 8
+
+// QuoteFunc 获取单只股票的实时报价
+type QuoteFunc func(ctx context.Context, symbol string) (*Quote, error)
+
+// FetchQuotes 并发调用fetch获取多只股票的报价，用于没有批量接口的数据源
+// 返回成功获取的报价；部分股票失败时同时返回合并后的错误，调用方可以使用已获取的报价
+func FetchQuotes(ctx context.Context, fetch QuoteFunc, symbols []string, concurrency int) (map[string]*Quote, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultQuoteConcurrency
+	}
+	symbols = uniqueSymbols(symbols)
+
+	quotes := make(map[string]*Quote, len(symbols))
+	var errs []error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, symbol := range symbols {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("%s: %w", symbol, ctx.Err()))
+			mu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(symbol string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			quote, err := fetch(ctx, symbol)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+				return
+			}
+			quotes[symbol] = quote
+		}(symbol)
+	}
+
+	wg.Wait()
+	return quotes, errors.Join(errs...)
+}
+
+// LimitFillQuote 返回after之后第一条使限价单可以成交的NBBO历史报价：买单在卖价不高于限价时成交，
+// 卖单在买价不低于限价时成交。只判断对手价是否触及限价，不考虑排队位置，用于回测中判断挂单能否真实成交
+func LimitFillQuote(quotes []Quote, buy bool, limit float64, after time.Time) (*Quote, bool) {
+	for i := range quotes {
+		q := &quotes[i]
+		if q.Timestamp.Before(after) {
+			continue
+		}
+		if buy && q.AskPrice > 0 && q.AskPrice <= limit || !buy && q.BidPrice > 0 && q.BidPrice >= limit {
+			return q, true
+		}
+	}
+	return nil, false
+}
+
+// missingQuotes 为批量接口没有返回报价的股票生成错误（内部函数）
+func missingQuotes(source string, symbols []string, quotes map[string]*Quote) error {
+	var errs []error
+	for _, symbol := range symbols {
+		if _, ok := quotes[symbol]; !ok {
+			errs = append(errs, &DataSourceError{
+				Source:  source,
+				Code:    "NO_DATA",
+				Message: fmt.Sprintf("no quote for %s", symbol),
+				Time:    time.Now(),
+			})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// uniqueSymbols 去除重复和空的股票代码，保持原有顺序（内部函数）
+func uniqueSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	unique := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		unique = append(unique, symbol)
+	}
+	return unique
+}
@@ -0,0 +1,260 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchQuotes(t *testing.T) {
+	var calls, active, peak int32
+	fetch := func(ctx context.Context, symbol string) (*Quote, error) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		if symbol == "BAD" {
+			return nil, errors.New("not found")
+		}
+		return &Quote{Symbol: symbol, LastPrice: 1}, nil
+	}
+
+	symbols := []string{"AAPL", "MSFT", "AAPL", "BAD", "", "TSLA", "NVDA"}
+	quotes, err := FetchQuotes(context.Background(), fetch, symbols, 2)
+	if err == nil || !strings.Contains(err.Error(), "BAD") {
+		t.Errorf("失败的股票应包含在错误中: %v", err)
+	}
+	if len(quotes) != 4 || quotes["AAPL"] == nil || quotes["BAD"] != nil {
+		t.Errorf("报价不正确: %v", quotes)
+	}
+	if calls != 5 {
+		t.Errorf("重复和空的股票代码不应重复获取: %d 次", calls)
+	}
+	if peak > 2 {
+		t.Errorf("并发数 = %d, 不应超过 2", peak)
+	}
+}
+
+func TestPolygonGetRealTimeQuotes(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/v2/snapshot/locale/us/markets/stocks/tickers" {
+			http.NotFound(w, r)
+			return
+		}
+		var tickers []string
+		for _, symbol := range strings.Split(r.URL.Query().Get("tickers"), ",") {
+			if symbol == "MISSING" {
+				continue
+			}
+			tickers = append(tickers, fmt.Sprintf(`{"ticker":%q,"lastQuote":{"P":10.1,"S":3,"p":9.9,"s":5,"t":100},"lastTrade":{"p":10,"s":7,"t":200}}`, symbol))
+		}
+		fmt.Fprintf(w, `{"status":"OK","tickers":[%s]}`, strings.Join(tickers, ","))
+	}))
+	defer server.Close()
+
+	source, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL})
+	symbols := []string{"MISSING"}
+	for i := 0; i < polygonSnapshotBatchSize; i++ {
+		symbols = append(symbols, fmt.Sprintf("S%d", i))
+	}
+
+	quotes, err := source.GetRealTimeQuotes(context.Background(), symbols)
+	if err == nil || !strings.Contains(err.Error(), "MISSING") {
+		t.Errorf("快照中没有的股票应返回错误: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("请求次数 = %d, 期望按每批 %d 只分成 2 次", requests, polygonSnapshotBatchSize)
+	}
+	if len(quotes) != polygonSnapshotBatchSize {
+		t.Fatalf("报价数量 = %d, 期望 %d", len(quotes), polygonSnapshotBatchSize)
+	}
+	quote := quotes["S0"]
+	if quote.BidPrice != 9.9 || quote.AskPrice != 10.1 || quote.LastPrice != 10 || quote.Timestamp.UnixNano() != 200 {
+		t.Errorf("报价不正确: %+v", quote)
+	}
+}
+
+func TestPolygonGetHistoricalQuotes(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/quotes/AAPL" || r.URL.Query().Get("apiKey") != "key" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			fmt.Fprintf(w, `{"status":"OK","next_url":"%s/v3/quotes/AAPL?cursor=p2","results":[
+				{"ask_price":10.2,"ask_size":1,"bid_price":10.0,"bid_size":2,"sip_timestamp":100,"sequence_number":1},
+				{"ask_price":10.1,"ask_size":1,"bid_price":10.0,"bid_size":2,"sip_timestamp":200,"sequence_number":2}]}`, server.URL)
+			return
+		}
+		fmt.Fprint(w, `{"status":"OK","results":[{"ask_price":9.9,"ask_size":3,"bid_price":9.8,"bid_size":4,"sip_timestamp":300,"sequence_number":3}]}`)
+	}))
+	defer server.Close()
+
+	source, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL, APIKey: "key"})
+	quotes, err := source.GetHistoricalQuotes(context.Background(), "AAPL", time.Unix(0, 0), time.Unix(0, 1000))
+	if err != nil {
+		t.Fatalf("获取历史报价失败: %v", err)
+	}
+	if len(quotes) != 3 || quotes[2].AskPrice != 9.9 || quotes[2].Timestamp.UnixNano() != 300 {
+		t.Fatalf("应分页获取全部历史报价: %+v", quotes)
+	}
+
+	// 限价10.0的买单在卖价降到9.9时才能成交，限价10.0的卖单在第一条报价即可成交
+	if q, ok := LimitFillQuote(quotes, true, 10.0, time.Unix(0, 0)); !ok || q.Timestamp.UnixNano() != 300 {
+		t.Errorf("买单应在卖价触及限价时成交: %+v", q)
+	}
+	if q, ok := LimitFillQuote(quotes, false, 10.0, time.Unix(0, 150)); !ok || q.Timestamp.UnixNano() != 200 {
+		t.Errorf("卖单应在挂单之后买价触及限价时成交: %+v", q)
+	}
+	if _, ok := LimitFillQuote(quotes, true, 9.5, time.Unix(0, 0)); ok {
+		t.Error("卖价从未触及限价时不应成交")
+	}
+}
+
+func TestPolygonGetTrades(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/trades/AAPL" || r.URL.Query().Get("apiKey") != "key" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			if r.URL.Query().Get("timestamp.gte") != "0" || r.URL.Query().Get("order") != "asc" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"status":"OK","next_url":"%s/v3/trades/AAPL?cursor=p2","results":[
+				{"conditions":[12,37],"exchange":4,"id":"52983525029461","price":10.05,"size":100,"sip_timestamp":100,"sequence_number":1},
+				{"exchange":11,"id":"52983525029462","price":10.06,"size":0.5,"sip_timestamp":200,"sequence_number":2}]}`, server.URL)
+			return
+		}
+		fmt.Fprint(w, `{"status":"OK","results":[{"exchange":4,"id":"7","price":10.1,"size":300,"sip_timestamp":300,"sequence_number":3}]}`)
+	}))
+	defer server.Close()
+
+	pages := 0
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		if p.Operation == OperationGetTrades {
+			pages = p.Done
+		}
+	})
+	source, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL, APIKey: "key"})
+	trades, err := source.GetTrades(ctx, "AAPL", time.Unix(0, 0), time.Unix(0, 1000))
+	if err != nil {
+		t.Fatalf("获取逐笔成交失败: %v", err)
+	}
+	if len(trades) != 3 || pages != 2 {
+		t.Fatalf("应分页获取全部逐笔成交: %d页 %+v", pages, trades)
+	}
+	first := trades[0]
+	if first.Symbol != "AAPL" || first.Price != 10.05 || first.Size != 100 || first.Exchange != 4 || len(first.Conditions) != 2 || first.Conditions[1] != 37 || first.ID != "52983525029461" {
+		t.Errorf("成交字段解析不正确: %+v", first)
+	}
+	if trades[1].Size != 0 || trades[2].Timestamp.UnixNano() != 300 {
+		t.Errorf("零股应向下取整，时间戳应为SIP时间: %+v", trades[1:])
+	}
+}
+
+func TestPolygonGetOptionChain(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/snapshot/options/AAPL" || r.URL.Query().Get("apiKey") != "key" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("cursor") == "" {
+			if r.URL.Query().Get("expiration_date") != "2024-01-19" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"status":"OK","next_url":"%s/v3/snapshot/options/AAPL?cursor=p2","results":[
+				{"details":{"contract_type":"put","exercise_style":"american","expiration_date":"2024-01-19","shares_per_contract":100,"strike_price":190,"ticker":"O:AAPL240119P00190000"},
+				 "last_quote":{"ask":2.6,"ask_size":12,"bid":2.5,"bid_size":8,"last_updated":1705000000000000000},
+				 "greeks":{"delta":-0.45,"gamma":0.05,"theta":-0.2,"vega":0.1},"implied_volatility":0.22,"open_interest":5300,
+				 "day":{"volume":1200},"underlying_asset":{"price":191.2,"ticker":"AAPL"}}]}`, server.URL)
+			return
+		}
+		fmt.Fprint(w, `{"status":"OK","results":[
+			{"details":{"contract_type":"call","exercise_style":"american","expiration_date":"2024-01-19","shares_per_contract":100,"strike_price":190,"ticker":"O:AAPL240119C00190000"},
+			 "last_quote":{},"last_trade":{"price":3.1},"open_interest":7100,"day":{"volume":2500}}]}`)
+	}))
+	defer server.Close()
+
+	source, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL, APIKey: "key"})
+	chain, err := source.GetOptionChain(context.Background(), "AAPL", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("获取期权链失败: %v", err)
+	}
+	if len(chain) != 2 || chain[0].Contract.Type != OptionTypeCall || chain[1].Contract.Type != OptionTypePut {
+		t.Fatalf("应分页获取全部合约，同一行权价看涨在前: %+v", chain)
+	}
+	put := chain[1]
+	if put.Contract.Symbol != "O:AAPL240119P00190000" || put.Contract.Strike != 190 || put.Contract.Underlying != "AAPL" ||
+		put.BidPrice != 2.5 || put.AskSize != 12 || put.Delta != -0.45 || put.OpenInterest != 5300 || put.Volume != 1200 || put.UnderlyingPrice != 191.2 {
+		t.Errorf("合约字段解析不正确: %+v", put)
+	}
+	if put.MidPrice() != 2.55 || chain[0].MidPrice() != 3.1 {
+		t.Errorf("没有买卖报价时中间价应使用最新成交价: %v %v", put.MidPrice(), chain[0].MidPrice())
+	}
+	if !chain[0].Contract.Expiry.Equal(time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)) || !chain[0].Timestamp.IsZero() {
+		t.Errorf("到期日或时间戳不正确: %+v", chain[0])
+	}
+}
+
+func TestPolygonGetFundamentals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("apiKey") != "key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v3/reference/tickers/SOFI":
+			fmt.Fprint(w, `{"status":"OK","results":{"name":"SoFi Technologies","market_cap":8000000000,"sic_description":"SERVICES-BUSINESS SERVICES",
+				"share_class_shares_outstanding":1000000000,"weighted_shares_outstanding":990000000}}`)
+		case "/vX/reference/financials":
+			if r.URL.Query().Get("ticker") != "SOFI" || r.URL.Query().Get("timeframe") != "ttm" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"status":"OK","results":[{"financials":{"income_statement":{"diluted_earnings_per_share":{"value":0.4}}}}]}`)
+		case "/stocks/vX/float":
+			// 没有流通股数据权限
+			http.Error(w, "not entitled", http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	source, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, BaseURL: server.URL, APIKey: "key"})
+	fundamentals, err := source.GetFundamentals(context.Background(), "SOFI")
+	if err != nil {
+		t.Fatalf("获取基本面数据失败: %v", err)
+	}
+	// 股价 = 80亿 / 10亿股 = 8，市盈率 = 8 / 0.4
+	if fundamentals.MarketCap != 8e9 || fundamentals.SharesOutstanding != 1e9 || fundamentals.EPS != 0.4 ||
+		math.Abs(fundamentals.PERatio-20) > 1e-9 || fundamentals.Sector != "SERVICES-BUSINESS SERVICES" {
+		t.Errorf("基本面数据不正确: %+v", fundamentals)
+	}
+	if fundamentals.FloatShares != 0 {
+		t.Errorf("没有流通股权限时流通股应为0: %+v", fundamentals)
+	}
+	if _, err := source.GetFundamentals(context.Background(), "NONE"); err == nil {
+		t.Error("未知股票应返回错误")
+	}
+}
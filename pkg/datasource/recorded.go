@@ -0,0 +1,272 @@
+package datasource
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RecordedDataSource 从 Recorder 录制的目录读取K线和报价，通常再用 ReplayDataSource 包装后按模拟时钟回放
+type RecordedDataSource struct {
+	name string
+	dir  string
+}
+
+// NewRecordedDataSource 创建读取录制数据的数据源
+func NewRecordedDataSource(name, dir string) *RecordedDataSource {
+	return &RecordedDataSource{name: name, dir: dir}
+}
+
+// Name 返回数据源名称
+func (d *RecordedDataSource) Name() string {
+	return d.name
+}
+
+// IsEnabled 录制数据源总是启用
+func (d *RecordedDataSource) IsEnabled() bool {
+	return true
+}
+
+// HealthCheck 检查录制目录是否存在
+func (d *RecordedDataSource) HealthCheck(ctx context.Context) (bool, error) {
+	if _, err := os.Stat(d.dir); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetStockData 读取[from, to]内录制的K线，按时间升序排列，重复录制的K线只保留一根
+func (d *RecordedDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	var data []StockData
+	err := d.scan(recordKindBars(timeframe), from, to, func(line []byte) error {
+		var bar StockData
+		if err := json.Unmarshal(line, &bar); err != nil {
+			return err
+		}
+		if bar.Symbol == symbol && !bar.Timestamp.Before(from) && !bar.Timestamp.After(to) {
+			data = append(data, bar)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortUniqueBars(data), nil
+}
+
+// GetMultipleStockData 逐个读取多只股票录制的K线
+func (d *RecordedDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]StockData, error) {
+	return FetchStockData(ctx, d.Name(), func(ctx context.Context, symbol string) ([]StockData, error) {
+		return d.GetStockData(ctx, symbol, timeframe, from, to)
+	}, symbols)
+}
+
+// GetHistoricalQuotes 读取[from, to]内录制的报价，按时间升序排列
+func (d *RecordedDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]Quote, error) {
+	var quotes []Quote
+	err := d.scan(recordKindQuotes, from, to, func(line []byte) error {
+		var quote Quote
+		if err := json.Unmarshal(line, &quote); err != nil {
+			return err
+		}
+		if quote.Symbol == symbol && !quote.Timestamp.Before(from) && !quote.Timestamp.After(to) {
+			quotes = append(quotes, quote)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(quotes, func(i, j int) bool { return quotes[i].Timestamp.Before(quotes[j].Timestamp) })
+	return quotes, nil
+}
+
+// GetRealTimeQuote 返回最近一天录制的该股票的最后一条报价
+func (d *RecordedDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	days, err := d.days()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(days) - 1; i >= 0; i-- {
+		var latest *Quote
+		err := d.scanDay(recordKindQuotes, days[i], func(line []byte) error {
+			var quote Quote
+			if err := json.Unmarshal(line, &quote); err != nil {
+				return err
+			}
+			if quote.Symbol == symbol && (latest == nil || quote.Timestamp.After(latest.Timestamp)) {
+				latest = &quote
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if latest != nil {
+			return latest, nil
+		}
+	}
+	return nil, &DataSourceError{
+		Source:  d.Name(),
+		Code:    "NO_DATA",
+		Message: fmt.Sprintf("no recorded quote for %s", symbol),
+		Time:    time.Now(),
+	}
+}
+
+// GetRealTimeQuotes 逐个读取多只股票最后录制的报价
+func (d *RecordedDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	return FetchQuotes(ctx, d.GetRealTimeQuote, symbols, 1)
+}
+
+// SubscribeQuotes 轮询录制的最新报价，只推送有变化的报价
+func (d *RecordedDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	return PollQuotes(ctx, d.GetRealTimeQuotes, symbols, DefaultQuotePollInterval)
+}
+
+// GetTrades 录制数据不包含逐笔成交
+func (d *RecordedDataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]TradePrint, error) {
+	return nil, &DataSourceError{
+		Source:  d.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "recorded data has no trade prints",
+		Time:    time.Now(),
+	}
+}
+
+// GetOptionChain 录制数据不包含期权链
+func (d *RecordedDataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]OptionQuote, error) {
+	return nil, &DataSourceError{
+		Source:  d.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "recorded data has no option chains",
+		Time:    time.Now(),
+	}
+}
+
+// GetFundamentals 录制数据不包含基本面数据
+func (d *RecordedDataSource) GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	return nil, &DataSourceError{
+		Source:  d.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "recorded data has no fundamentals",
+		Time:    time.Now(),
+	}
+}
+
+// GetCorporateActions 录制数据不包含公司行动
+func (d *RecordedDataSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error) {
+	return nil, &DataSourceError{
+		Source:  d.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "recorded data has no corporate actions",
+		Time:    time.Now(),
+	}
+}
+
+// GetAllStocks 录制数据不包含股票列表
+func (d *RecordedDataSource) GetAllStocks(ctx context.Context) ([]Stock, error) {
+	return nil, &DataSourceError{
+		Source:  d.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "recorded data has no stock list",
+		Time:    time.Now(),
+	}
+}
+
+// Close 录制数据源没有需要关闭的资源
+func (d *RecordedDataSource) Close() error {
+	return nil
+}
+
+// scan 逐行读取[from, to]范围内各日期某类型的录制文件（内部方法）
+// 日期目录按录制时数据所在时区划分，因此前后各多读一天
+func (d *RecordedDataSource) scan(kind string, from, to time.Time, fn func(line []byte) error) error {
+	days, err := d.days()
+	if err != nil {
+		return err
+	}
+	first, last := from.AddDate(0, 0, -1), to.AddDate(0, 0, 1)
+	for _, day := range days {
+		if day.Before(first) || day.After(last) {
+			continue
+		}
+		if err := d.scanDay(kind, day, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanDay 逐行读取某日期某类型的全部录制文件（内部方法）
+func (d *RecordedDataSource) scanDay(kind string, day time.Time, fn func(line []byte) error) error {
+	paths, err := recordFiles(filepath.Join(d.dir, day.Format("2006-01-02")), kind)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := scanLines(path, fn); err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// days 返回录制目录中的日期，按时间升序排列（内部方法）
+func (d *RecordedDataSource) days() ([]time.Time, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record directory: %v", err)
+	}
+	var days []time.Time
+	for _, entry := range entries {
+		if day, err := time.Parse("2006-01-02", entry.Name()); err == nil && entry.IsDir() {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// scanLines 逐行读取文件，跳过写了一半的最后一行（内部函数）
+func scanLines(path string, fn func(line []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// 没有换行结尾的最后一行可能是崩溃时写了一半的记录
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+}
+
+// sortUniqueBars 按时间排序K线，同一时间重复录制的K线只保留最后一根（内部函数）
+func sortUniqueBars(data []StockData) []StockData {
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Timestamp.Before(data[j].Timestamp) })
+	unique := data[:0]
+	for _, bar := range data {
+		if n := len(unique); n > 0 && unique[n-1].Timestamp.Equal(bar.Timestamp) {
+			unique[n-1] = bar
+			continue
+		}
+		unique = append(unique, bar)
+	}
+	return unique
+}
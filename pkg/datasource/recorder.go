@@ -0,0 +1,288 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRecordMaxBytes 是录制文件轮转前的默认最大字节数
+const DefaultRecordMaxBytes = 256 << 20
+
+// maxOpenRecordFiles 是录制时同时打开的最大文件数，超过时关闭全部文件后重新打开
+const maxOpenRecordFiles = 16
+
+// recordFile 是一个正在写入的录制文件（内部类型）
+type recordFile struct {
+	file *os.File
+	seq  int
+	size int64
+}
+
+// Recorder 将报价和K线以JSON行写入目录，供 RecordedDataSource 回放
+// 文件按交易日和类型存放在 <目录>/<日期>/quotes.jsonl 和 <目录>/<日期>/bars-<周期>.jsonl，
+// 超过最大字节数时轮转为 quotes.1.jsonl、quotes.2.jsonl 等；日期按数据时间戳所在时区计算。
+// 同一股票和周期只写入比已录制的更新的数据，轮询重复获取的K线和报价不会重复写入
+type Recorder struct {
+	dir      string
+	maxBytes int64
+
+	mu     sync.Mutex
+	files  map[string]*recordFile // 键为 <日期>/<类型>
+	latest map[string]time.Time   // 键为 <类型>/<股票>
+	closed bool
+}
+
+// NewRecorder 创建录制器，maxBytes不大于0时使用 DefaultRecordMaxBytes
+func NewRecorder(dir string, maxBytes int64) (*Recorder, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultRecordMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create record directory: %v", err)
+	}
+	return &Recorder{
+		dir:      dir,
+		maxBytes: maxBytes,
+		files:    make(map[string]*recordFile),
+		latest:   make(map[string]time.Time),
+	}, nil
+}
+
+// RecordQuote 录制一条报价
+func (r *Recorder) RecordQuote(quote Quote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.write(recordKindQuotes, quote.Symbol, quote.Timestamp, quote)
+}
+
+// RecordBars 录制K线，只写入比该股票已录制的K线更新的部分
+func (r *Recorder) RecordBars(timeframe string, bars []StockData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kind := recordKindBars(timeframe)
+	for _, bar := range bars {
+		if err := r.write(kind, bar.Symbol, bar.Timestamp, bar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 关闭所有录制文件
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return r.closeFiles()
+}
+
+// write 写入一条比已录制数据更新的记录（内部方法，调用方持锁）
+func (r *Recorder) write(kind, symbol string, ts time.Time, record interface{}) error {
+	if r.closed {
+		return fmt.Errorf("recorder is closed")
+	}
+	latestKey := kind + "/" + symbol
+	if last, ok := r.latest[latestKey]; ts.IsZero() || ok && !ts.After(last) {
+		return nil
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %v", err)
+	}
+	line = append(line, '\n')
+
+	day := ts.Format("2006-01-02")
+	f, err := r.file(day, kind)
+	if err != nil {
+		return err
+	}
+	if f.size > 0 && f.size+int64(len(line)) > r.maxBytes {
+		f.file.Close()
+		if f, err = r.openFile(day, kind, f.seq+1); err != nil {
+			delete(r.files, day+"/"+kind)
+			return err
+		}
+		r.files[day+"/"+kind] = f
+	}
+
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write record: %v", err)
+	}
+	r.latest[latestKey] = ts
+	return nil
+}
+
+// file 返回某日某类型正在写入的文件，首次写入时续写该日最后一个文件（内部方法，调用方持锁）
+func (r *Recorder) file(day, kind string) (*recordFile, error) {
+	key := day + "/" + kind
+	if f, ok := r.files[key]; ok {
+		return f, nil
+	}
+	if len(r.files) >= maxOpenRecordFiles {
+		if err := r.closeFiles(); err != nil {
+			return nil, err
+		}
+	}
+
+	seq := 0
+	if paths, err := recordFiles(filepath.Join(r.dir, day), kind); err == nil && len(paths) > 0 {
+		seq = recordSeq(paths[len(paths)-1])
+	}
+	f, err := r.openFile(day, kind, seq)
+	if err != nil {
+		return nil, err
+	}
+	r.files[key] = f
+	return f, nil
+}
+
+// openFile 以追加方式打开录制文件（内部方法）
+func (r *Recorder) openFile(day, kind string, seq int) (*recordFile, error) {
+	dir := filepath.Join(r.dir, day)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create record directory: %v", err)
+	}
+	name := kind + ".jsonl"
+	if seq > 0 {
+		name = fmt.Sprintf("%s.%d.jsonl", kind, seq)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open record file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat record file: %v", err)
+	}
+	return &recordFile{file: file, seq: seq, size: info.Size()}, nil
+}
+
+// closeFiles 关闭所有打开的录制文件（内部方法，调用方持锁）
+func (r *Recorder) closeFiles() error {
+	var firstErr error
+	for key, f := range r.files {
+		if err := f.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(r.files, key)
+	}
+	return firstErr
+}
+
+// recordKindQuotes 是报价录制文件的类型名
+const recordKindQuotes = "quotes"
+
+// recordKindBars 返回某周期K线录制文件的类型名（内部函数）
+func recordKindBars(timeframe string) string {
+	return "bars-" + timeframe
+}
+
+// recordFiles 返回目录中某类型的录制文件，按轮转顺序排列（内部函数）
+func recordFiles(dir, kind string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, kind+"*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	matched := paths[:0]
+	for _, path := range paths {
+		// 排除类型名为前缀的其他类型，如 bars-minute 与 bars-minute5
+		rest := strings.TrimPrefix(filepath.Base(path), kind)
+		if rest == ".jsonl" || rest == fmt.Sprintf(".%d.jsonl", recordSeq(path)) {
+			matched = append(matched, path)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return recordSeq(matched[i]) < recordSeq(matched[j]) })
+	return matched, nil
+}
+
+// recordSeq 返回录制文件的轮转序号，未轮转的文件为0（内部函数）
+func recordSeq(path string) int {
+	parts := strings.Split(strings.TrimSuffix(filepath.Base(path), ".jsonl"), ".")
+	if len(parts) < 2 {
+		return 0
+	}
+	seq, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return 0
+	}
+	return seq
+}
+
+// RecordingDataSource 包装数据源，把获取到的报价和K线交给录制器写入磁盘
+// 录制失败不影响数据返回
+type RecordingDataSource struct {
+	DataSource
+	recorder *Recorder
+}
+
+// NewRecordingDataSource 创建录制数据的数据源
+func NewRecordingDataSource(source DataSource, recorder *Recorder) *RecordingDataSource {
+	return &RecordingDataSource{DataSource: source, recorder: recorder}
+}
+
+// GetStockData 获取价格数据并录制
+func (d *RecordingDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	data, err := d.DataSource.GetStockData(ctx, symbol, timeframe, from, to)
+	if err == nil {
+		d.recordBars(timeframe, data)
+	}
+	return data, err
+}
+
+// GetMultipleStockData 批量获取价格数据并录制
+func (d *RecordingDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]StockData, error) {
+	result, err := d.DataSource.GetMultipleStockData(ctx, symbols, timeframe, from, to)
+	for _, data := range result {
+		d.recordBars(timeframe, data)
+	}
+	return result, err
+}
+
+// GetRealTimeQuote 获取实时报价并录制
+func (d *RecordingDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	quote, err := d.DataSource.GetRealTimeQuote(ctx, symbol)
+	if err == nil && quote != nil {
+		d.recorder.RecordQuote(*quote)
+	}
+	return quote, err
+}
+
+// GetRealTimeQuotes 批量获取实时报价并录制
+func (d *RecordingDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	quotes, err := d.DataSource.GetRealTimeQuotes(ctx, symbols)
+	for _, quote := range quotes {
+		d.recorder.RecordQuote(*quote)
+	}
+	return quotes, err
+}
+
+// SubscribeQuotes 订阅实时报价推送并录制每条报价
+func (d *RecordingDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	quotes, err := d.DataSource.SubscribeQuotes(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	return mapQuotes(ctx, quotes, func(quote *Quote) {
+		d.recorder.RecordQuote(*quote)
+	}), nil
+}
+
+// recordBars 只录制已经走完的K线，未走完的K线之后还会变化（内部方法）
+func (d *RecordingDataSource) recordBars(timeframe string, data []StockData) {
+	if period := BarDuration(timeframe); period > 0 {
+		data = completedBefore(data, period, time.Now())
+	}
+	d.recorder.RecordBars(timeframe, data)
+}
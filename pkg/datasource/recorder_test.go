@@ -0,0 +1,61 @@
+package datasource
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, 512)
+	if err != nil {
+		t.Fatalf("创建录制器失败: %v", err)
+	}
+
+	start := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	var bars []StockData
+	for i := 0; i < 10; i++ {
+		bars = append(bars, StockData{Symbol: "AAPL", Timestamp: start.Add(time.Duration(i) * time.Minute), Close: float64(100 + i)})
+	}
+	source := NewRecordingDataSource(&barSource{bars: bars}, recorder)
+	// 轮询重复获取的K线只录制一次
+	for i := 0; i < 2; i++ {
+		if _, err := source.GetStockData(ctx, "AAPL", "minute", start, start.Add(time.Hour)); err != nil {
+			t.Fatalf("获取K线失败: %v", err)
+		}
+	}
+	if err := recorder.RecordQuote(Quote{Symbol: "AAPL", Timestamp: start.Add(5 * time.Minute), BidPrice: 104.9, AskPrice: 105.1}); err != nil {
+		t.Fatalf("录制报价失败: %v", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("关闭录制器失败: %v", err)
+	}
+
+	files, _ := recordFiles(filepath.Join(dir, "2024-03-08"), recordKindBars("minute"))
+	if len(files) < 2 {
+		t.Errorf("超过最大字节数时应轮转文件: %v", files)
+	}
+
+	recorded := NewRecordedDataSource("recorded", dir)
+	data, err := recorded.GetStockData(ctx, "AAPL", "minute", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("读取录制的K线失败: %v", err)
+	}
+	if len(data) != 10 || data[0].Close != 100 || data[9].Close != 109 {
+		t.Errorf("录制的K线不正确: %d 根 %+v", len(data), data)
+	}
+
+	// 回放数据源按模拟时钟读取录制数据
+	replay := NewReplayDataSource(recorded, start.Add(3*time.Minute), 1)
+	quote, err := replay.GetRealTimeQuote(ctx, "AAPL")
+	if err != nil || quote.LastPrice != 102 {
+		t.Errorf("回放报价应来自模拟时钟之前最后走完的K线: %+v %v", quote, err)
+	}
+	quotes, err := recorded.GetHistoricalQuotes(ctx, "AAPL", start, start.Add(time.Hour))
+	if err != nil || len(quotes) != 1 || quotes[0].AskPrice != 105.1 {
+		t.Errorf("录制的报价不正确: %+v %v", quotes, err)
+	}
+}
@@ -0,0 +1,180 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+)
+
+// replayQuoteLookback 是生成报价时向前查找分钟K线的时间范围，覆盖周末和节假日
+const replayQuoteLookback = 4 * 24 * time.Hour
+
+// ReplayDataSource 包装一个数据源，只返回模拟时钟之前的数据，用于模拟交易和演练
+// 延迟模式下模拟时钟落后真实时间固定时长；回放模式下模拟时钟从历史时间点开始按倍速前进。
+// 报价由模拟时钟之前最近走完的一根分钟K线生成
+type ReplayDataSource struct {
+	DataSource
+	clock clock.Clock
+}
+
+// NewDelayedDataSource 创建延迟数据源，数据落后真实时间delay
+func NewDelayedDataSource(source DataSource, delay time.Duration) *ReplayDataSource {
+	return NewClockDataSource(source, clock.Delayed(delay))
+}
+
+// NewReplayDataSource 创建回放数据源，模拟时钟从start开始按speed倍速前进
+func NewReplayDataSource(source DataSource, start time.Time, speed float64) *ReplayDataSource {
+	return NewClockDataSource(source, clock.NewReplay(start, speed))
+}
+
+// NewClockDataSource 创建按给定模拟时钟返回数据的数据源
+// 多个数据源和交易引擎共用同一个时钟时，它们看到的时间保持一致
+func NewClockDataSource(source DataSource, c clock.Clock) *ReplayDataSource {
+	return &ReplayDataSource{DataSource: source, clock: c}
+}
+
+// Now 返回模拟时钟的当前时间
+func (r *ReplayDataSource) Now() time.Time {
+	return r.clock.Now()
+}
+
+// GetStockData 获取模拟时钟之前已走完的K线
+func (r *ReplayDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	now := r.clock.Now()
+	if to.After(now) {
+		to = now
+	}
+	if from.After(to) {
+		return []StockData{}, nil
+	}
+
+	data, err := r.DataSource.GetStockData(ctx, symbol, timeframe, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return completedBefore(data, BarDuration(timeframe), now), nil
+}
+
+// GetMultipleStockData 批量获取模拟时钟之前的价格数据，通过上下文报告进度
+func (r *ReplayDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]StockData, error) {
+	return FetchStockData(ctx, r.Name(), func(ctx context.Context, symbol string) ([]StockData, error) {
+		return r.GetStockData(ctx, symbol, timeframe, from, to)
+	}, symbols)
+}
+
+// GetRealTimeQuote 根据模拟时钟之前最近走完的分钟K线生成报价
+func (r *ReplayDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	now := r.clock.Now()
+	data, err := r.GetStockData(ctx, symbol, "minute", now.Add(-replayQuoteLookback), now)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, &DataSourceError{
+			Source:  r.Name(),
+			Code:    "NO_DATA",
+			Message: fmt.Sprintf("no data for %s before %s", symbol, now.Format(time.RFC3339)),
+			Time:    time.Now(),
+		}
+	}
+
+	bar := data[len(data)-1]
+	return &Quote{
+		Symbol:    symbol,
+		Timestamp: bar.Timestamp,
+		LastPrice: bar.Close,
+		LastSize:  bar.Volume,
+	}, nil
+}
+
+// GetRealTimeQuotes 并发获取多只股票在模拟时钟之前的报价
+func (r *ReplayDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	return FetchQuotes(ctx, r.GetRealTimeQuote, symbols, DefaultQuoteConcurrency)
+}
+
+// GetHistoricalQuotes 获取模拟时钟之前的NBBO历史报价
+func (r *ReplayDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]Quote, error) {
+	now := r.clock.Now()
+	if to.After(now) {
+		to = now
+	}
+	if from.After(to) {
+		return []Quote{}, nil
+	}
+	return r.DataSource.GetHistoricalQuotes(ctx, symbol, from, to)
+}
+
+// GetTrades 获取模拟时钟之前的逐笔成交
+func (r *ReplayDataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]TradePrint, error) {
+	now := r.clock.Now()
+	if to.After(now) {
+		to = now
+	}
+	if from.After(to) {
+		return []TradePrint{}, nil
+	}
+	return r.DataSource.GetTrades(ctx, symbol, from, to)
+}
+
+// GetOptionChain 期权链只有当前的快照，晚于模拟时钟，返回会泄露未来数据，因此不支持
+func (r *ReplayDataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]OptionQuote, error) {
+	return nil, &DataSourceError{
+		Source:  r.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "option chain snapshots are newer than the replay clock",
+		Time:    time.Now(),
+	}
+}
+
+// GetFundamentals 基本面数据只有最新的值，市值按当前股价计算，晚于模拟时钟，因此不支持
+func (r *ReplayDataSource) GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	return nil, &DataSourceError{
+		Source:  r.Name(),
+		Code:    "NOT_SUPPORTED",
+		Message: "fundamentals are newer than the replay clock",
+		Time:    time.Now(),
+	}
+}
+
+// GetCorporateActions 获取模拟时钟之前除权除息的公司行动，复权以模拟时钟当时的股本为基准
+func (r *ReplayDataSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error) {
+	now := r.clock.Now()
+	if to.IsZero() || to.After(now) {
+		to = now
+	}
+	return r.DataSource.GetCorporateActions(ctx, symbol, from, to)
+}
+
+// SubscribeQuotes 按模拟时钟轮询报价，不使用被包装数据源的推送，推送的报价可能在模拟时钟之后
+func (r *ReplayDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	return PollQuotes(ctx, r.GetRealTimeQuotes, symbols, DefaultQuotePollInterval)
+}
+
+// completedBefore 只保留在now之前已经走完的K线，避免使用未来数据（内部函数）
+func completedBefore(data []StockData, period time.Duration, now time.Time) []StockData {
+	completed := data[:0:0]
+	for _, d := range data {
+		if !d.Timestamp.Add(period).After(now) {
+			completed = append(completed, d)
+		}
+	}
+	return completed
+}
+
+// BarDuration 返回K线周期的时长，未知周期返回0
+func BarDuration(timeframe string) time.Duration {
+	switch timeframe {
+	case "minute":
+		return time.Minute
+	case "hour":
+		return time.Hour
+	case "day":
+		return 24 * time.Hour
+	case "week":
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,319 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 报价路由的默认参数
+const (
+	DefaultRoutingWindow         = 20               // 统计最近的请求数
+	DefaultRoutingSwitchMargin   = 0.2              // 其他数据源比当前数据源快20%以上才切换
+	DefaultRoutingMinSuccessRate = 0.8              // 成功率低于80%视为不健康
+	DefaultRoutingProbeInterval  = 30 * time.Second // 每隔30秒把一次请求发给其他数据源以更新其统计
+)
+
+// QuoteRoutingOptions 表示按延迟选择实时报价数据源的选项，为0的字段使用默认值
+type QuoteRoutingOptions struct {
+	Window         int           // 每个数据源统计最近的请求数
+	SwitchMargin   float64       // 滞后区间，其他数据源的平均延迟比当前数据源低该比例以上才切换，避免来回切换
+	MinSuccessRate float64       // 成功率低于该值的数据源视为不健康，当前数据源不健康时立即切换
+	ProbeInterval  time.Duration // 未被选中的数据源没有新的统计时，每隔该时长用一次真实请求探测
+}
+
+// SourceStats 表示一个数据源最近请求的延迟和成功率
+type SourceStats struct {
+	Name        string        `json:"name"`
+	Samples     int           `json:"samples"`
+	Latency     time.Duration `json:"latency"`      // 成功请求的平均延迟
+	SuccessRate float64       `json:"success_rate"` // 没有统计时为1
+	Selected    bool          `json:"selected"`     // 是否为当前选中的报价数据源
+}
+
+// latencySample 是一次请求的结果（内部类型）
+type latencySample struct {
+	latency time.Duration
+	ok      bool
+}
+
+// sourceStats 保存一个数据源最近的请求结果（内部类型）
+type sourceStats struct {
+	samples  []latencySample
+	next     int
+	lastSeen time.Time
+}
+
+// add 记录一次请求结果，超过窗口大小时覆盖最早的结果（内部方法）
+func (s *sourceStats) add(sample latencySample, window int, now time.Time) {
+	if len(s.samples) < window {
+		s.samples = append(s.samples, sample)
+	} else {
+		s.samples[s.next%len(s.samples)] = sample
+		s.next++
+	}
+	s.lastSeen = now
+}
+
+// summary 返回成功请求的平均延迟和成功率（内部方法）
+func (s *sourceStats) summary() (time.Duration, float64) {
+	if s == nil || len(s.samples) == 0 {
+		return 0, 1
+	}
+	var total time.Duration
+	succeeded := 0
+	for _, sample := range s.samples {
+		if sample.ok {
+			total += sample.latency
+			succeeded++
+		}
+	}
+	if succeeded == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(succeeded), float64(succeeded) / float64(len(s.samples))
+}
+
+// EnableQuoteRouting 开启按延迟选择实时报价数据源：GetRealTimeQuote 和 GetRealTimeQuotes
+// 发送到当前最快的健康数据源，而不是固定的主数据源，失败时依次尝试其他数据源
+func (m *Manager) EnableQuoteRouting(opts QuoteRoutingOptions) {
+	if opts.Window <= 0 {
+		opts.Window = DefaultRoutingWindow
+	}
+	if opts.SwitchMargin <= 0 {
+		opts.SwitchMargin = DefaultRoutingSwitchMargin
+	}
+	if opts.MinSuccessRate <= 0 {
+		opts.MinSuccessRate = DefaultRoutingMinSuccessRate
+	}
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = DefaultRoutingProbeInterval
+	}
+
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	m.routing = &opts
+}
+
+// SourceStats 返回每个数据源最近的延迟和成功率，按名称排序
+func (m *Manager) SourceStats() []SourceStats {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.dataSources))
+	for name := range m.dataSources {
+		names = append(names, name)
+	}
+	primary := m.primary
+	m.mu.RUnlock()
+	sort.Strings(names)
+
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	selected := primary
+	if m.routing != nil && m.quoteSource != "" {
+		selected = m.quoteSource
+	}
+	result := make([]SourceStats, 0, len(names))
+	for _, name := range names {
+		stats := m.stats[name]
+		latency, rate := stats.summary()
+		samples := 0
+		if stats != nil {
+			samples = len(stats.samples)
+		}
+		result = append(result, SourceStats{
+			Name:        name,
+			Samples:     samples,
+			Latency:     latency,
+			SuccessRate: rate,
+			Selected:    name == selected,
+		})
+	}
+	return result
+}
+
+// GetRealTimeQuote 获取实时报价。开启报价路由时发送到当前最快的健康数据源，否则发送到主数据源
+func (m *Manager) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	var quote *Quote
+	err := m.routeQuotes(ctx, func(ds DataSource) error {
+		var err error
+		quote, err = ds.GetRealTimeQuote(ctx, symbol)
+		return err
+	})
+	return quote, err
+}
+
+// GetRealTimeQuotes 批量获取实时报价，数据源的选择同 GetRealTimeQuote。
+// 所有数据源都失败时返回最后一个数据源获取到的部分报价和错误
+func (m *Manager) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	var quotes map[string]*Quote
+	err := m.routeQuotes(ctx, func(ds DataSource) error {
+		var err error
+		quotes, err = ds.GetRealTimeQuotes(ctx, symbols)
+		return err
+	})
+	return quotes, err
+}
+
+// routeQuotes 按路由顺序调用数据源直到成功，并记录每次调用的延迟和结果（内部方法）
+func (m *Manager) routeQuotes(ctx context.Context, fetch func(ds DataSource) error) error {
+	sources := m.quoteOrder(time.Now())
+	if len(sources) == 0 {
+		return fmt.Errorf("no data sources available")
+	}
+
+	var err error
+	for _, name := range sources {
+		m.mu.RLock()
+		ds, exists := m.dataSources[name]
+		m.mu.RUnlock()
+		if !exists {
+			continue
+		}
+
+		start := time.Now()
+		err = fetch(ds)
+		if ctx.Err() != nil {
+			// 调用方取消的请求不计入数据源的统计
+			return err
+		}
+		m.recordLatency(name, time.Since(start), err == nil)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// quoteOrder 返回本次报价请求依次尝试的数据源（内部方法）
+// 未开启路由时只使用主数据源；开启时先按滞后规则更新当前数据源，到探测时间时先尝试最久没有统计的其他数据源
+func (m *Manager) quoteOrder(now time.Time) []string {
+	m.mu.RLock()
+	primary := m.primary
+	var enabled []string
+	for name, ds := range m.dataSources {
+		if ds.IsEnabled() {
+			enabled = append(enabled, name)
+		}
+	}
+	m.mu.RUnlock()
+	sort.Strings(enabled)
+
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	if m.routing == nil {
+		if primary == "" {
+			return nil
+		}
+		return []string{primary}
+	}
+	if len(enabled) == 0 {
+		return nil
+	}
+
+	current := m.selectQuoteSource(enabled, primary)
+	order := []string{current}
+	if now.Sub(m.lastProbe) >= m.routing.ProbeInterval {
+		var probe string
+		for _, name := range enabled {
+			if name == current {
+				continue
+			}
+			if probe == "" || m.lastSeen(name).Before(m.lastSeen(probe)) {
+				probe = name
+			}
+		}
+		if probe != "" {
+			m.lastProbe = now
+			if now.Sub(m.lastSeen(probe)) >= m.routing.ProbeInterval {
+				order = []string{probe, current}
+			}
+		}
+	}
+
+	// 其余数据源按健康程度和延迟排序作为后备
+	var rest []string
+	for _, name := range enabled {
+		if name != order[0] && name != current {
+			rest = append(rest, name)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool { return m.better(rest[i], rest[j]) })
+	return append(order, rest...)
+}
+
+// selectQuoteSource 按滞后规则更新并返回当前的报价数据源，调用方持锁（内部方法）
+// 当前数据源不健康时切换到最好的健康数据源；健康时只有其他数据源快出 SwitchMargin 以上才切换
+func (m *Manager) selectQuoteSource(enabled []string, primary string) string {
+	current := ""
+	for _, name := range enabled {
+		if name == m.quoteSource {
+			current = name
+			break
+		}
+		if name == primary {
+			current = name
+		}
+	}
+	if current == "" {
+		current = enabled[0]
+	}
+
+	best := current
+	for _, name := range enabled {
+		if m.better(name, best) {
+			best = name
+		}
+	}
+	if best != current {
+		bestLatency, bestRate := m.stats[best].summary()
+		currentLatency, currentRate := m.stats[current].summary()
+		switch {
+		case currentRate < m.routing.MinSuccessRate:
+			current = best
+		case bestRate >= m.routing.MinSuccessRate && m.stats[best] != nil && currentLatency > 0 &&
+			float64(bestLatency) < float64(currentLatency)*(1-m.routing.SwitchMargin):
+			current = best
+		}
+	}
+	m.quoteSource = current
+	return current
+}
+
+// better 判断数据源a是否优于b：健康的优先，其次是有统计且平均延迟更低的，调用方持锁（内部方法）
+func (m *Manager) better(a, b string) bool {
+	aLatency, aRate := m.stats[a].summary()
+	bLatency, bRate := m.stats[b].summary()
+	aHealthy, bHealthy := aRate >= m.routing.MinSuccessRate, bRate >= m.routing.MinSuccessRate
+	if aHealthy != bHealthy {
+		return aHealthy
+	}
+	if (aLatency > 0) != (bLatency > 0) {
+		return aLatency > 0
+	}
+	return aLatency < bLatency
+}
+
+// lastSeen 返回数据源最近一次统计的时间，调用方持锁（内部方法）
+func (m *Manager) lastSeen(name string) time.Time {
+	if stats := m.stats[name]; stats != nil {
+		return stats.lastSeen
+	}
+	return time.Time{}
+}
+
+// recordLatency 记录数据源一次请求的延迟和结果（内部方法）
+func (m *Manager) recordLatency(name string, latency time.Duration, ok bool) {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	window := DefaultRoutingWindow
+	if m.routing != nil {
+		window = m.routing.Window
+	}
+	stats := m.stats[name]
+	if stats == nil {
+		stats = &sourceStats{}
+		m.stats[name] = stats
+	}
+	stats.add(latencySample{latency: latency, ok: ok}, window, time.Now())
+}
@@ -0,0 +1,86 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// latencySource 按设置的延迟和错误返回报价的数据源
+type latencySource struct {
+	DataSource
+	name    string
+	latency time.Duration
+	err     error
+	calls   int
+}
+
+func (s *latencySource) Name() string    { return s.name }
+func (s *latencySource) IsEnabled() bool { return true }
+func (s *latencySource) Close() error    { return nil }
+
+func (s *latencySource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	s.calls++
+	time.Sleep(s.latency)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Quote{Symbol: symbol, LastPrice: 100}, nil
+}
+
+func TestManagerQuoteRouting(t *testing.T) {
+	ctx := context.Background()
+	slow := &latencySource{name: "slow", latency: 10 * time.Millisecond}
+	fast := &latencySource{name: "fast", latency: 2 * time.Millisecond}
+	m := NewManager()
+	m.AddDataSource(slow)
+	m.AddDataSource(fast)
+
+	// 未开启路由时只使用主数据源
+	m.GetRealTimeQuote(ctx, "AAPL")
+	if slow.calls != 1 || fast.calls != 0 {
+		t.Fatalf("未开启路由时应使用主数据源: slow=%d fast=%d", slow.calls, fast.calls)
+	}
+
+	m.EnableQuoteRouting(QuoteRoutingOptions{Window: 5, ProbeInterval: time.Hour})
+	// 第一次请求探测没有统计的数据源，之后切换到更快的数据源
+	for i := 0; i < 5; i++ {
+		if _, err := m.GetRealTimeQuote(ctx, "AAPL"); err != nil {
+			t.Fatalf("获取报价失败: %v", err)
+		}
+	}
+	if fast.calls != 5 || !selected(m, "fast") {
+		t.Fatalf("应切换到更快的数据源: slow=%d fast=%d %+v", slow.calls, fast.calls, m.SourceStats())
+	}
+
+	// 当前数据源变慢但差距在滞后区间内时不切换
+	fast.latency = 11 * time.Millisecond
+	for i := 0; i < 5; i++ {
+		m.GetRealTimeQuote(ctx, "AAPL")
+	}
+	if !selected(m, "fast") {
+		t.Errorf("延迟相近时不应来回切换: %+v", m.SourceStats())
+	}
+
+	// 当前数据源失败时由其他数据源兜底，成功率过低后切换
+	fast.err = fmt.Errorf("timeout")
+	for i := 0; i < 3; i++ {
+		if quote, err := m.GetRealTimeQuote(ctx, "AAPL"); err != nil || quote == nil {
+			t.Fatalf("应由其他数据源兜底: %v", err)
+		}
+	}
+	if fast.calls != 12 || !selected(m, "slow") {
+		t.Errorf("成功率过低时应切换数据源: %+v", m.SourceStats())
+	}
+}
+
+// selected 判断数据源是否为当前选中的报价数据源
+func selected(m *Manager, name string) bool {
+	for _, stats := range m.SourceStats() {
+		if stats.Selected {
+			return stats.Name == name
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/schedule"
+)
+
+// 报价所处的交易时段
+const (
+	SessionPre     = "pre"     // 盘前
+	SessionRegular = "regular" // 常规交易
+	SessionPost    = "post"    // 盘后
+	SessionClosed  = "closed"  // 休市，包括非交易日和夜间
+)
+
+// regularCloseLookback 是查找常规交易收盘价时向前获取分钟K线的时间范围，覆盖收盘前短暂停牌
+const regularCloseLookback = 30 * time.Minute
+
+// MarketSession 返回时间t所处的交易时段
+func MarketSession(calendar *schedule.Calendar, t time.Time) string {
+	session, ok := calendar.Session(t)
+	switch {
+	case !ok || t.Before(session.PreMarket) || !t.Before(session.AfterHours):
+		return SessionClosed
+	case t.Before(session.Open):
+		return SessionPre
+	case t.Before(session.Close):
+		return SessionRegular
+	default:
+		return SessionPost
+	}
+}
+
+// regularClose 是缓存的某只股票某个交易日的常规交易收盘价（内部类型）
+type regularClose struct {
+	date  time.Time
+	price float64
+}
+
+// SessionDataSource 包装数据源，为报价标注交易时段，并补充最近一个已收盘的常规交易时段的收盘价
+// 收盘价取该交易日常规交易收盘前最后一根分钟K线的收盘价，不会把盘后或次日盘前的成交误当作收盘价；
+// 每只股票每个交易日只查询一次，遵循上下文中的缓存指令（见 CacheControl）
+type SessionDataSource struct {
+	DataSource
+	calendar *schedule.Calendar
+
+	mu     sync.Mutex
+	closes map[string]regularClose
+}
+
+// NewSessionDataSource 创建标注交易时段的数据源
+func NewSessionDataSource(source DataSource, calendar *schedule.Calendar) *SessionDataSource {
+	return &SessionDataSource{
+		DataSource: source,
+		calendar:   calendar,
+		closes:     make(map[string]regularClose),
+	}
+}
+
+// GetRealTimeQuote 获取实时报价并标注交易时段和最近常规交易收盘价
+// 无法获取收盘价时仍返回报价，RegularClose 为0
+func (s *SessionDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	quote, err := s.DataSource.GetRealTimeQuote(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.annotate(ctx, quote)
+	return quote, nil
+}
+
+// GetRealTimeQuotes 批量获取实时报价并标注交易时段和最近常规交易收盘价
+// 无法获取收盘价的股票仍返回报价，RegularClose 为0，错误合并返回
+func (s *SessionDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	quotes, err := s.DataSource.GetRealTimeQuotes(ctx, symbols)
+	errs := []error{err}
+	for _, quote := range quotes {
+		errs = append(errs, s.annotate(ctx, quote))
+	}
+	return quotes, errors.Join(errs...)
+}
+
+// GetHistoricalQuotes 获取NBBO历史报价并标注每条报价的交易时段
+func (s *SessionDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]Quote, error) {
+	quotes, err := s.DataSource.GetHistoricalQuotes(ctx, symbol, from, to)
+	for i := range quotes {
+		quotes[i].Session = MarketSession(s.calendar, quotes[i].Timestamp)
+	}
+	return quotes, err
+}
+
+// SubscribeQuotes 订阅实时报价推送，并为每条报价标注交易时段和最近常规交易收盘价
+func (s *SessionDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	quotes, err := s.DataSource.SubscribeQuotes(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	return mapQuotes(ctx, quotes, func(quote *Quote) {
+		_ = s.annotate(ctx, quote)
+	}), nil
+}
+
+// annotate 为报价标注交易时段并补充收盘价，报价时间为零值时按当前时间计算（内部方法）
+func (s *SessionDataSource) annotate(ctx context.Context, quote *Quote) error {
+	at := quote.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	quote.Session = MarketSession(s.calendar, at)
+	if quote.RegularClose > 0 {
+		return nil
+	}
+
+	price, err := s.regularClose(ctx, quote.Symbol, at)
+	if err != nil {
+		return err
+	}
+	quote.RegularClose = price
+	return nil
+}
+
+// regularClose 返回股票在t之前最近一个已收盘的常规交易时段的收盘价（内部方法）
+func (s *SessionDataSource) regularClose(ctx context.Context, symbol string, t time.Time) (float64, error) {
+	session, ok := s.calendar.PreviousSession(t)
+	if !ok {
+		return 0, fmt.Errorf("no regular session before %s", t.Format(time.RFC3339))
+	}
+
+	cc := CacheControlFrom(ctx)
+	s.mu.Lock()
+	cached, ok := s.closes[symbol]
+	s.mu.Unlock()
+	if ok && cached.date.Equal(session.Date) && !cc.NoCache {
+		return cached.price, nil
+	}
+
+	bars, err := s.DataSource.GetStockData(ctx, symbol, "minute", session.Close.Add(-regularCloseLookback), session.Close)
+	if err != nil {
+		// 接受过期数据时返回缓存的收盘价，可能是更早交易日的
+		if ok && cc.AcceptStale {
+			return cached.price, nil
+		}
+		return 0, err
+	}
+	// 只取在收盘前开始的K线，数据源可能包含收盘时刻开始的盘后K线
+	var price float64
+	for _, bar := range bars {
+		if bar.Timestamp.Before(session.Close) {
+			price = bar.Close
+		}
+	}
+	if price <= 0 {
+		return 0, &DataSourceError{
+			Source:  s.Name(),
+			Code:    "NO_DATA",
+			Message: fmt.Sprintf("no regular close for %s on %s", symbol, session.Date.Format("2006-01-02")),
+			Time:    time.Now(),
+		}
+	}
+
+	s.mu.Lock()
+	s.closes[symbol] = regularClose{date: session.Date, price: price}
+	s.mu.Unlock()
+	return price, nil
+}
@@ -0,0 +1,107 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/schedule"
+)
+
+// sessionSource 返回固定时间报价和分钟K线的数据源
+type sessionSource struct {
+	DataSource
+	at    time.Time
+	bars  []StockData
+	calls int
+	err   error
+}
+
+func (s *sessionSource) Name() string { return "session" }
+
+func (s *sessionSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	return &Quote{Symbol: symbol, Timestamp: s.at, LastPrice: 105}, nil
+}
+
+func (s *sessionSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	return FetchQuotes(ctx, s.GetRealTimeQuote, symbols, 1)
+}
+
+func (s *sessionSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	var data []StockData
+	for _, bar := range s.bars {
+		if !bar.Timestamp.Before(from) && !bar.Timestamp.After(to) {
+			data = append(data, bar)
+		}
+	}
+	return data, nil
+}
+
+func TestSessionDataSource(t *testing.T) {
+	ctx := context.Background()
+	cal, err := schedule.NewCalendar(schedule.DefaultTimezone, nil, nil)
+	if err != nil {
+		t.Fatalf("创建交易日历失败: %v", err)
+	}
+	et := func(s string) time.Time {
+		t, _ := time.ParseInLocation("2006-01-02 15:04", s, cal.Location())
+		return t
+	}
+
+	// 2024-02-19 总统日休市，周二盘前的收盘价应取上周五常规交易收盘
+	source := &sessionSource{bars: []StockData{
+		{Timestamp: et("2024-02-16 15:59"), Close: 100},
+		{Timestamp: et("2024-02-16 16:00"), Close: 101}, // 盘后
+		{Timestamp: et("2024-02-20 07:30"), Close: 104}, // 盘前
+		{Timestamp: et("2024-02-20 15:59"), Close: 110},
+	}}
+	ds := NewSessionDataSource(source, cal)
+
+	source.at = et("2024-02-20 08:00")
+	quote, err := ds.GetRealTimeQuote(ctx, "AAPL")
+	if err != nil {
+		t.Fatalf("获取报价失败: %v", err)
+	}
+	if quote.Session != SessionPre || quote.RegularClose != 100 || quote.GapPercent() != 5 {
+		t.Errorf("盘前报价 = %+v, 期望收盘价100、跳空5%%", quote)
+	}
+	if _, err := ds.GetRealTimeQuote(ctx, "AAPL"); err != nil || source.calls != 1 {
+		t.Errorf("同一交易日的收盘价应只查询一次, 查询 %d 次", source.calls)
+	}
+
+	source.at = et("2024-02-20 17:00")
+	quotes, err := ds.GetRealTimeQuotes(ctx, []string{"AAPL"})
+	if err != nil || quotes["AAPL"].Session != SessionPost || quotes["AAPL"].RegularClose != 110 {
+		t.Errorf("盘后报价应使用当日收盘价: %+v %v", quotes["AAPL"], err)
+	}
+
+	// 强制刷新时不读取缓存；数据源出错时只有接受过期数据才返回缓存的收盘价
+	calls := source.calls
+	if _, err := ds.GetRealTimeQuotes(ForceRefresh(ctx), []string{"AAPL"}); err != nil || source.calls != calls+1 {
+		t.Errorf("强制刷新应重新查询收盘价: %v", err)
+	}
+	source.err = errors.New("unavailable")
+	if quote, _ := ds.GetRealTimeQuote(ForceRefresh(ctx), "AAPL"); quote.RegularClose != 0 {
+		t.Errorf("数据源出错且不接受过期数据时收盘价应为0: %+v", quote)
+	}
+	if quote, _ := ds.GetRealTimeQuote(AcceptStale(ForceRefresh(ctx)), "AAPL"); quote.RegularClose != 110 {
+		t.Errorf("接受过期数据时应返回缓存的收盘价: %+v", quote)
+	}
+	source.err = nil
+
+	for at, want := range map[string]string{
+		"2024-02-20 10:00": SessionRegular,
+		"2024-02-20 21:00": SessionClosed,
+		"2024-02-19 10:00": SessionClosed,
+		"2024-11-29 14:00": SessionPost, // 感恩节次日13:00提前收盘
+	} {
+		if got := MarketSession(cal, et(at)); got != want {
+			t.Errorf("%s 的交易时段 = %s, 期望 %s", at, got, want)
+		}
+	}
+}
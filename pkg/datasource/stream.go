@@ -0,0 +1,104 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultQuotePollInterval 是不支持推送的数据源轮询报价的默认间隔
+const DefaultQuotePollInterval = time.Second
+
+// quoteStreamBuffer 是报价推送通道的缓冲大小
+const quoteStreamBuffer = 1024
+
+// QuotesFunc 批量获取多只股票的实时报价
+type QuotesFunc func(ctx context.Context, symbols []string) (map[string]*Quote, error)
+
+// PollQuotes 按间隔调用fetch获取报价，只推送有变化的报价，用于不支持推送的数据源实现 SubscribeQuotes
+// 部分股票获取失败时跳过，下一轮继续获取；上下文取消后关闭通道
+func PollQuotes(ctx context.Context, fetch QuotesFunc, symbols []string, interval time.Duration) (<-chan Quote, error) {
+	symbols = uniqueSymbols(symbols)
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols to subscribe")
+	}
+	if interval <= 0 {
+		interval = DefaultQuotePollInterval
+	}
+
+	out := make(chan Quote, quoteStreamBuffer)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := make(map[string]Quote, len(symbols))
+		for {
+			quotes, _ := fetch(ctx, symbols)
+			for _, symbol := range symbols {
+				quote, ok := quotes[symbol]
+				if !ok || quote == nil || last[symbol] == *quote {
+					continue
+				}
+				last[symbol] = *quote
+				select {
+				case out <- *quote:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return out, nil
+}
+
+// mapQuotes 对推送的每条报价调用fn后转发，输入通道关闭或上下文取消后关闭输出通道（内部函数）
+func mapQuotes(ctx context.Context, in <-chan Quote, fn func(quote *Quote)) <-chan Quote {
+	out := make(chan Quote, quoteStreamBuffer)
+	go func() {
+		defer close(out)
+		for quote := range in {
+			fn(&quote)
+			select {
+			case out <- quote:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// SubscribeQuotes 订阅实时报价推送，数据源的选择同 GetRealTimeQuote，订阅失败时依次尝试下一个数据源
+// 订阅建立后不再切换数据源，断线重连由数据源自己处理
+func (m *Manager) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	sources := m.quoteOrder(time.Now())
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no data sources available")
+	}
+
+	var err error
+	for _, name := range sources {
+		m.mu.RLock()
+		ds, exists := m.dataSources[name]
+		m.mu.RUnlock()
+		if !exists {
+			continue
+		}
+
+		var quotes <-chan Quote
+		if quotes, err = ds.SubscribeQuotes(ctx, symbols); err == nil {
+			return quotes, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,163 @@
+package datasource
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// nextQuote 在超时前从通道读取一条报价
+func nextQuote(t *testing.T, quotes <-chan Quote) Quote {
+	t.Helper()
+	select {
+	case quote, ok := <-quotes:
+		if !ok {
+			t.Fatal("报价通道已关闭")
+		}
+		return quote
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待报价超时")
+	}
+	return Quote{}
+}
+
+func TestPollQuotes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	price := 10.0
+	fetch := func(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return map[string]*Quote{"AAPL": {Symbol: "AAPL", LastPrice: price}}, nil
+	}
+
+	quotes, err := PollQuotes(ctx, fetch, []string{"AAPL", "MSFT"}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if quote := nextQuote(t, quotes); quote.LastPrice != 10 {
+		t.Errorf("首次应推送当前报价: %+v", quote)
+	}
+
+	// 报价没有变化时不推送，变化后推送新的报价
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	price = 11
+	mu.Unlock()
+	if quote := nextQuote(t, quotes); quote.LastPrice != 11 {
+		t.Errorf("应推送变化后的报价: %+v", quote)
+	}
+
+	cancel()
+	for range quotes {
+	}
+	if _, err := PollQuotes(context.Background(), fetch, nil, 0); err == nil {
+		t.Error("没有股票时应返回错误")
+	}
+}
+
+func TestManagerSubscribeQuotes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, 0)
+	if err != nil {
+		t.Fatalf("创建录制器失败: %v", err)
+	}
+	recorder.RecordQuote(Quote{Symbol: "AAPL", Timestamp: time.Now(), LastPrice: 10})
+	recorder.Close()
+
+	// 没有推送接口的录制数据源轮询最新报价
+	manager := NewManager()
+	manager.AddDataSource(NewRecordedDataSource("recorded", dir))
+	quotes, err := manager.SubscribeQuotes(ctx, []string{"AAPL"})
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if quote := nextQuote(t, quotes); quote.Symbol != "AAPL" || quote.LastPrice != 10 {
+		t.Errorf("报价不正确: %+v", quote)
+	}
+}
+
+func TestPolygonSubscribeQuotes(t *testing.T) {
+	var connections int32
+	subscribed := make(chan string, 2)
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		n := atomic.AddInt32(&connections, 1)
+		websocket.Message.Send(conn, `[{"ev":"status","status":"connected","message":"Connected Successfully"}]`)
+
+		var auth map[string]string
+		if websocket.JSON.Receive(conn, &auth) != nil {
+			return
+		}
+		if auth["action"] != "auth" || auth["params"] != "key" {
+			websocket.Message.Send(conn, `[{"ev":"status","status":"auth_failed","message":"authentication failed"}]`)
+			return
+		}
+		websocket.Message.Send(conn, `[{"ev":"status","status":"auth_success","message":"authenticated"}]`)
+
+		var sub map[string]string
+		if websocket.JSON.Receive(conn, &sub) != nil {
+			return
+		}
+		subscribed <- sub["params"]
+
+		websocket.Message.Send(conn, `[{"ev":"Q","sym":"AAPL","bp":9.9,"bs":5,"ap":10.1,"as":3,"i":[1],"t":1000}]`)
+		websocket.Message.Send(conn, `not json`)
+		websocket.Message.Send(conn, `[{"ev":"T","sym":"AAPL","p":10,"s":7,"i":"t1","c":[12],"t":2000},{"ev":"LULD","T":"AAPL","h":11,"l":9,"t":3000}]`)
+		if n == 1 {
+			// 第一次连接推送后断开，客户端应重连并重新订阅
+			return
+		}
+		var block []byte
+		websocket.Message.Receive(conn, &block)
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, APIKey: "key", StreamURL: url})
+	quotes, err := source.SubscribeQuotes(ctx, []string{"AAPL", "MSFT", "AAPL"})
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if params := <-subscribed; params != "Q.AAPL,T.AAPL,LULD.AAPL,Q.MSFT,T.MSFT,LULD.MSFT" {
+		t.Errorf("订阅的频道不正确: %s", params)
+	}
+
+	// 报价、成交和LULD合并为完整的报价，无法解析的消息被忽略
+	nextQuote(t, quotes)
+	nextQuote(t, quotes)
+	quote := nextQuote(t, quotes)
+	if quote.BidPrice != 9.9 || quote.AskPrice != 10.1 || quote.LastPrice != 10 || quote.LastSize != 7 ||
+		quote.LimitUp != 11 || quote.LimitDown != 9 || quote.Timestamp.UnixMilli() != 3000 {
+		t.Errorf("合并后的报价不正确: %+v", quote)
+	}
+
+	select {
+	case params := <-subscribed:
+		if !strings.Contains(params, "Q.MSFT") {
+			t.Errorf("重连后应重新订阅所有股票: %s", params)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("断线后没有重连")
+	}
+	nextQuote(t, quotes)
+
+	cancel()
+	for range quotes {
+	}
+
+	// 认证失败时直接返回错误
+	bad, _ := NewPolygonDataSource(DataSourceConfig{Enabled: true, APIKey: "wrong", StreamURL: url, TimeoutSeconds: 5})
+	if _, err := bad.SubscribeQuotes(context.Background(), []string{"AAPL"}); err == nil || !strings.Contains(err.Error(), "auth_failed") {
+		t.Errorf("认证失败应返回错误: %v", err)
+	}
+}
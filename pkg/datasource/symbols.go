@@ -0,0 +1,199 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// EventSymbolsChanged 是股票列表刷新后有新上市、退市或信息变化时发布的事件类型
+const EventSymbolsChanged = "symbols_changed"
+
+// SymbolChanges 表示一次刷新前后股票列表的差异
+type SymbolChanges struct {
+	RefreshedAt time.Time `json:"refreshed_at"`
+	Added       []Stock   `json:"added,omitempty"`   // 新上市
+	Removed     []Stock   `json:"removed,omitempty"` // 退市或不再可交易
+	Changed     []Stock   `json:"changed,omitempty"` // 名称、交易所等信息变化，为变化后的信息
+	Total       int       `json:"total"`
+}
+
+// Empty 检查是否没有变化
+func (c SymbolChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// symbolCacheFile 是缓存文件的格式（内部类型）
+type symbolCacheFile struct {
+	RefreshedAt time.Time `json:"refreshed_at"`
+	Stocks      []Stock   `json:"stocks"`
+}
+
+// SymbolCache 将数据源的股票列表缓存在本地文件中，启动时直接读取缓存，由定时任务刷新
+// 从Polygon分页拉取全部股票需要数分钟，不适合在每次启动时执行
+type SymbolCache struct {
+	mu          sync.RWMutex
+	path        string
+	source      DataSource
+	stocks      map[string]Stock
+	refreshedAt time.Time
+	eventBus    *events.Bus
+}
+
+// NewSymbolCache 创建股票列表缓存，需要调用Load读取已有的缓存文件
+func NewSymbolCache(path string, source DataSource) *SymbolCache {
+	return &SymbolCache{
+		path:   path,
+		source: source,
+		stocks: make(map[string]Stock),
+	}
+}
+
+// SetEventBus 设置事件总线，刷新后有变化时发布 symbols_changed 事件
+func (c *SymbolCache) SetEventBus(bus *events.Bus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eventBus = bus
+}
+
+// Load 读取缓存文件，文件不存在时缓存为空
+func (c *SymbolCache) Load() error {
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read symbol cache: %v", err)
+	}
+
+	var file symbolCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("corrupt symbol cache %s: %v", c.path, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stocks = make(map[string]Stock, len(file.Stocks))
+	for _, stock := range file.Stocks {
+		c.stocks[stock.Symbol] = stock
+	}
+	c.refreshedAt = file.RefreshedAt
+	return nil
+}
+
+// Refresh 从数据源重新获取股票列表，与缓存比较后写入缓存文件，返回新上市、退市和信息变化的股票
+// 获取或写入失败时保留原有缓存；首次刷新时所有股票都视为新上市，但不发布事件
+func (c *SymbolCache) Refresh(ctx context.Context) (*SymbolChanges, error) {
+	stocks, err := c.source.GetAllStocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]Stock, len(stocks))
+	for _, stock := range stocks {
+		if stock.Symbol != "" {
+			latest[stock.Symbol] = stock
+		}
+	}
+
+	c.mu.Lock()
+	first := c.refreshedAt.IsZero()
+	changes := diffSymbols(c.stocks, latest)
+	changes.RefreshedAt = time.Now()
+	if err := c.save(latest, changes.RefreshedAt); err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.stocks = latest
+	c.refreshedAt = changes.RefreshedAt
+	bus := c.eventBus
+	c.mu.Unlock()
+
+	if !changes.Empty() && !first {
+		bus.Publish(events.Event{
+			Topic:     events.TopicSymbols,
+			Type:      EventSymbolsChanged,
+			Timestamp: changes.RefreshedAt,
+			Payload:   changes,
+		})
+	}
+	return &changes, nil
+}
+
+// save 先写入临时文件再重命名，避免写了一半的缓存文件（内部方法，调用方持锁）
+func (c *SymbolCache) save(stocks map[string]Stock, refreshedAt time.Time) error {
+	data, err := json.Marshal(symbolCacheFile{RefreshedAt: refreshedAt, Stocks: sortedStocks(stocks)})
+	if err != nil {
+		return fmt.Errorf("failed to encode symbol cache: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create symbol cache directory: %v", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write symbol cache: %v", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// Stocks 返回按代码排序的缓存股票列表
+func (c *SymbolCache) Stocks() []Stock {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return sortedStocks(c.stocks)
+}
+
+// Lookup 查找股票信息
+func (c *SymbolCache) Lookup(symbol string) (Stock, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	stock, ok := c.stocks[symbol]
+	return stock, ok
+}
+
+// RefreshedAt 返回上次刷新的时间，从未刷新时为零值
+func (c *SymbolCache) RefreshedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.refreshedAt
+}
+
+// diffSymbols 比较刷新前后的股票列表，结果按代码排序（内部函数）
+func diffSymbols(previous, latest map[string]Stock) SymbolChanges {
+	changes := SymbolChanges{Total: len(latest)}
+	for symbol, stock := range latest {
+		old, ok := previous[symbol]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, stock)
+		case old != stock:
+			changes.Changed = append(changes.Changed, stock)
+		}
+	}
+	for symbol, stock := range previous {
+		if _, ok := latest[symbol]; !ok {
+			changes.Removed = append(changes.Removed, stock)
+		}
+	}
+	for _, list := range [][]Stock{changes.Added, changes.Removed, changes.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Symbol < list[j].Symbol })
+	}
+	return changes
+}
+
+// sortedStocks 返回按代码排序的股票列表（内部函数）
+func sortedStocks(stocks map[string]Stock) []Stock {
+	list := make([]Stock, 0, len(stocks))
+	for _, stock := range stocks {
+		list = append(list, stock)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Symbol < list[j].Symbol })
+	return list
+}
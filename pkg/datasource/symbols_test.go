@@ -0,0 +1,76 @@
+package datasource
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// listingSource 返回固定股票列表的数据源
+type listingSource struct {
+	DataSource
+	stocks []Stock
+}
+
+func (s *listingSource) GetAllStocks(ctx context.Context) ([]Stock, error) {
+	return s.stocks, nil
+}
+
+func TestSymbolCache(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "symbols.json")
+	source := &listingSource{stocks: []Stock{
+		{Symbol: "AAPL", Name: "Apple Inc.", Exchange: "XNAS", IsActive: true},
+		{Symbol: "TWTR", Name: "Twitter, Inc.", Exchange: "XNYS", IsActive: true},
+	}}
+
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicSymbols)
+	defer sub.Close()
+
+	cache := NewSymbolCache(path, source)
+	cache.SetEventBus(bus)
+	if err := cache.Load(); err != nil {
+		t.Fatalf("读取不存在的缓存不应失败: %v", err)
+	}
+	changes, err := cache.Refresh(ctx)
+	if err != nil || len(changes.Added) != 2 {
+		t.Fatalf("首次刷新应将所有股票视为新上市: %+v %v", changes, err)
+	}
+	if len(sub.C) != 0 {
+		t.Error("首次刷新不应发布事件")
+	}
+
+	source.stocks = []Stock{
+		{Symbol: "AAPL", Name: "Apple Inc.", Exchange: "XNAS", IsActive: true},
+		{Symbol: "ARM", Name: "Arm Holdings plc", Exchange: "XNAS", IsActive: true},
+	}
+	changes, err = cache.Refresh(ctx)
+	if err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	if len(changes.Added) != 1 || changes.Added[0].Symbol != "ARM" || len(changes.Removed) != 1 || changes.Removed[0].Symbol != "TWTR" || len(changes.Changed) != 0 {
+		t.Errorf("变化不正确: %+v", changes)
+	}
+	evt := <-sub.C
+	if evt.Type != EventSymbolsChanged {
+		t.Errorf("事件类型 = %s, 期望 %s", evt.Type, EventSymbolsChanged)
+	}
+
+	// 新的缓存从文件读取，不访问数据源
+	reloaded := NewSymbolCache(path, nil)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("读取缓存失败: %v", err)
+	}
+	if stocks := reloaded.Stocks(); len(stocks) != 2 || stocks[0].Symbol != "AAPL" || stocks[1].Symbol != "ARM" {
+		t.Errorf("读取的股票列表不正确: %+v", stocks)
+	}
+	if _, ok := reloaded.Lookup("TWTR"); ok {
+		t.Error("退市的股票不应保留在缓存中")
+	}
+	if !reloaded.RefreshedAt().Equal(cache.RefreshedAt()) {
+		t.Errorf("刷新时间 = %v, 期望 %v", reloaded.RefreshedAt(), cache.RefreshedAt())
+	}
+}
@@ -0,0 +1,113 @@
+package datasource
+
+import (
+	"context"
+	"time"
+)
+
+// TimezoneDataSource 包装数据源，将K线和报价的时间戳统一转换到目标时区（通常为交易所时区），
+// 使来自不同数据源的指标序列按同一时钟对齐
+// Polygon等以Unix时间戳返回数据的源只需转换时区；以当地时间记录且不带时区的数据（如文件数据）
+// 设置源时区后按该时区的墙上时间重新解释，夏令时切换前后的偏移由 time 包按日期计算
+type TimezoneDataSource struct {
+	DataSource
+	target *time.Location
+	source *time.Location
+}
+
+// NewTimezoneDataSource 创建转换时区的数据源，source为nil时认为时间戳已包含正确的时区
+func NewTimezoneDataSource(ds DataSource, target, source *time.Location) *TimezoneDataSource {
+	return &TimezoneDataSource{DataSource: ds, target: target, source: source}
+}
+
+// Normalize 将时间戳转换到目标时区
+func (z *TimezoneDataSource) Normalize(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	if z.source != nil {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), z.source)
+	}
+	return t.In(z.target)
+}
+
+// GetStockData 获取价格数据并转换时间戳
+func (z *TimezoneDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	data, err := z.DataSource.GetStockData(ctx, symbol, timeframe, from, to)
+	z.normalizeBars(data)
+	return data, err
+}
+
+// GetMultipleStockData 批量获取价格数据并转换时间戳
+func (z *TimezoneDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]StockData, error) {
+	result, err := z.DataSource.GetMultipleStockData(ctx, symbols, timeframe, from, to)
+	for _, data := range result {
+		z.normalizeBars(data)
+	}
+	return result, err
+}
+
+// GetRealTimeQuote 获取实时报价并转换时间戳
+func (z *TimezoneDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error) {
+	quote, err := z.DataSource.GetRealTimeQuote(ctx, symbol)
+	if quote != nil {
+		quote.Timestamp = z.Normalize(quote.Timestamp)
+	}
+	return quote, err
+}
+
+// GetRealTimeQuotes 批量获取实时报价并转换时间戳
+func (z *TimezoneDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	quotes, err := z.DataSource.GetRealTimeQuotes(ctx, symbols)
+	for _, quote := range quotes {
+		quote.Timestamp = z.Normalize(quote.Timestamp)
+	}
+	return quotes, err
+}
+
+// GetHistoricalQuotes 获取NBBO历史报价并转换时间戳
+func (z *TimezoneDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]Quote, error) {
+	quotes, err := z.DataSource.GetHistoricalQuotes(ctx, symbol, from, to)
+	for i := range quotes {
+		quotes[i].Timestamp = z.Normalize(quotes[i].Timestamp)
+	}
+	return quotes, err
+}
+
+// GetTrades 获取逐笔成交并转换时间戳
+func (z *TimezoneDataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]TradePrint, error) {
+	trades, err := z.DataSource.GetTrades(ctx, symbol, from, to)
+	for i := range trades {
+		trades[i].Timestamp = z.Normalize(trades[i].Timestamp)
+	}
+	return trades, err
+}
+
+// GetOptionChain 获取期权链并转换报价的时间戳，到期日是日期，不做转换
+func (z *TimezoneDataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]OptionQuote, error) {
+	chain, err := z.DataSource.GetOptionChain(ctx, underlying, expiry)
+	for i := range chain {
+		if !chain[i].Timestamp.IsZero() {
+			chain[i].Timestamp = z.Normalize(chain[i].Timestamp)
+		}
+	}
+	return chain, err
+}
+
+// SubscribeQuotes 订阅实时报价推送并转换时间戳
+func (z *TimezoneDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error) {
+	quotes, err := z.DataSource.SubscribeQuotes(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	return mapQuotes(ctx, quotes, func(quote *Quote) {
+		quote.Timestamp = z.Normalize(quote.Timestamp)
+	}), nil
+}
+
+// normalizeBars 原地转换K线的时间戳（内部方法）
+func (z *TimezoneDataSource) normalizeBars(data []StockData) {
+	for i := range data {
+		data[i].Timestamp = z.Normalize(data[i].Timestamp)
+	}
+}
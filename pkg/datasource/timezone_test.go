@@ -0,0 +1,50 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// barSource 返回固定K线的数据源
+type barSource struct {
+	DataSource
+	bars []StockData
+}
+
+func (s *barSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]StockData, error) {
+	return append([]StockData(nil), s.bars...), nil
+}
+
+func TestTimezoneDataSource(t *testing.T) {
+	ctx := context.Background()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("缺少时区数据: %v", err)
+	}
+
+	// 2024-03-10 开始夏令时，开盘时间从 14:30 UTC 变为 13:30 UTC
+	utc := &barSource{bars: []StockData{
+		{Timestamp: time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)},
+		{Timestamp: time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC)},
+	}}
+	// 以当地时间记录、不带时区的文件数据被解析为UTC
+	local := &barSource{bars: []StockData{
+		{Timestamp: time.Date(2024, 3, 8, 9, 30, 0, 0, time.UTC)},
+		{Timestamp: time.Date(2024, 3, 11, 9, 30, 0, 0, time.UTC)},
+	}}
+
+	a, _ := NewTimezoneDataSource(utc, ny, nil).GetStockData(ctx, "AAPL", "minute", time.Time{}, time.Time{})
+	b, _ := NewTimezoneDataSource(local, ny, ny).GetStockData(ctx, "AAPL", "minute", time.Time{}, time.Time{})
+	for i := range a {
+		if !a[i].Timestamp.Equal(b[i].Timestamp) {
+			t.Errorf("第 %d 根K线时间不一致: %v != %v", i, a[i].Timestamp, b[i].Timestamp)
+		}
+		if got := a[i].Timestamp.Format("15:04 MST"); got != "09:30 EST" && got != "09:30 EDT" {
+			t.Errorf("第 %d 根K线应转换为美东开盘时间, 实际 %s", i, got)
+		}
+	}
+	if a[0].Timestamp.Location() != ny || b[1].Timestamp.Format("MST") != "EDT" {
+		t.Errorf("时间戳应转换到目标时区: %v %v", a[0].Timestamp, b[1].Timestamp)
+	}
+}
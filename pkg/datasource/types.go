@@ -2,6 +2,7 @@ package datasource
 
 import (
 	"context"
+	"sort"
 	"time"
 )
 
@@ -25,6 +26,30 @@ type DataSource interface {
 	// GetRealTimeQuote 获取实时报价
 	GetRealTimeQuote(ctx context.Context, symbol string) (*Quote, error)
 	
+	// GetRealTimeQuotes 批量获取多只股票的实时报价，返回以股票代码为键的报价
+	// 部分股票失败时返回已获取的报价和错误
+	GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error)
+	
+	// GetHistoricalQuotes 获取[from, to]内的NBBO历史报价，按时间升序排列
+	GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]Quote, error)
+	
+	// GetTrades 获取[from, to]内的逐笔成交，按时间升序排列，用于K线过于粗糙的扫描
+	GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]TradePrint, error)
+	
+	// GetOptionChain 获取标的股票在指定到期日的期权链报价，expiry 为零值时返回所有到期日，
+	// 按到期日、行权价排序，同一行权价的看涨期权在前
+	GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]OptionQuote, error)
+	
+	// GetFundamentals 获取公司的基本面数据（市值、流通股、市盈率、每股收益和行业）
+	GetFundamentals(ctx context.Context, symbol string) (*Fundamentals, error)
+	
+	// GetCorporateActions 获取除权除息日在[from, to]内的拆股和现金分红，from 和 to 为零值时不限制
+	GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*CorporateActions, error)
+	
+	// SubscribeQuotes 订阅多只股票的实时报价推送，上下文取消后关闭通道
+	// 支持推送的数据源断线后自动重连并重新订阅，不支持推送的数据源可以用 PollQuotes 轮询实现
+	SubscribeQuotes(ctx context.Context, symbols []string) (<-chan Quote, error)
+	
 	// GetAllStocks 获取所有可交易的股票列表
 	GetAllStocks(ctx context.Context) ([]Stock, error)
 	
@@ -56,6 +81,105 @@ type Quote struct {
 	LastPrice     float64   `json:"last_price"`
 	LastSize      int64     `json:"last_size"`
 	TransactionID string    `json:"transaction_id,omitempty"`
+	Session       string    `json:"session,omitempty"`       // 报价所处的交易时段：pre、regular、post 或 closed
+	RegularClose  float64   `json:"regular_close,omitempty"` // 最近一个已收盘的常规交易时段的收盘价
+	Halted        bool      `json:"halted,omitempty"`        // 数据源报告该股票已停牌
+	LimitUp       float64   `json:"limit_up,omitempty"`      // LULD价格区间上限，数据源不提供时为0
+	LimitDown     float64   `json:"limit_down,omitempty"`    // LULD价格区间下限
+	ShortRestricted bool    `json:"short_restricted,omitempty"` // 数据源报告卖空限制（Reg SHO 201）生效中
+}
+
+// TradePrint 表示一笔成交
+type TradePrint struct {
+	Symbol        string    `json:"symbol"`
+	Timestamp     time.Time `json:"timestamp"`
+	Price         float64   `json:"price"`
+	Size          int64     `json:"size"`
+	Exchange      int       `json:"exchange,omitempty"`   // 成交所在交易所的代码
+	Conditions    []int     `json:"conditions,omitempty"` // 成交条件代码（如零股、盘后、开盘集合竞价），含义见数据源文档
+	ID            string    `json:"id,omitempty"`         // 交易所分配的成交ID
+	TransactionID string    `json:"transaction_id,omitempty"`
+}
+
+// OptionType 表示期权类型
+type OptionType string
+
+// 期权类型常量
+const (
+	OptionTypeCall OptionType = "call"
+	OptionTypePut  OptionType = "put"
+)
+
+// OptionContract 表示一个期权合约
+type OptionContract struct {
+	Symbol            string     `json:"symbol"` // 期权代码，如 O:AAPL240119C00190000
+	Underlying        string     `json:"underlying"`
+	Type              OptionType `json:"type"`
+	Strike            float64    `json:"strike"`
+	Expiry            time.Time  `json:"expiry"` // 到期日，UTC零点
+	ExerciseStyle     string     `json:"exercise_style,omitempty"` // american 或 european
+	SharesPerContract int64      `json:"shares_per_contract"`
+}
+
+// OptionQuote 表示期权合约的报价快照，包括成交量、持仓量、隐含波动率和希腊值
+type OptionQuote struct {
+	Contract          OptionContract `json:"contract"`
+	Timestamp         time.Time      `json:"timestamp"`
+	BidPrice          float64        `json:"bid_price"`
+	BidSize           int64          `json:"bid_size"`
+	AskPrice          float64        `json:"ask_price"`
+	AskSize           int64          `json:"ask_size"`
+	LastPrice         float64        `json:"last_price,omitempty"`
+	Volume            int64          `json:"volume"`        // 当日成交量（张）
+	OpenInterest      int64          `json:"open_interest"` // 持仓量（张）
+	ImpliedVolatility float64        `json:"implied_volatility,omitempty"`
+	Delta             float64        `json:"delta,omitempty"`
+	Gamma             float64        `json:"gamma,omitempty"`
+	Theta             float64        `json:"theta,omitempty"`
+	Vega              float64        `json:"vega,omitempty"`
+	UnderlyingPrice   float64        `json:"underlying_price,omitempty"`
+}
+
+// MidPrice 返回买卖中间价，缺少任一边报价时返回最新成交价
+func (q OptionQuote) MidPrice() float64 {
+	if q.BidPrice > 0 && q.AskPrice > 0 {
+		return (q.BidPrice + q.AskPrice) / 2
+	}
+	return q.LastPrice
+}
+
+// SortOptionChain 按到期日、行权价排序期权链，同一行权价的看涨期权在前
+func SortOptionChain(chain []OptionQuote) {
+	sort.SliceStable(chain, func(i, j int) bool {
+		a, b := chain[i].Contract, chain[j].Contract
+		if !a.Expiry.Equal(b.Expiry) {
+			return a.Expiry.Before(b.Expiry)
+		}
+		if a.Strike != b.Strike {
+			return a.Strike < b.Strike
+		}
+		return a.Type == OptionTypeCall && b.Type != OptionTypeCall
+	})
+}
+
+// Fundamentals 表示公司最新的基本面数据，数据源未提供的数值为0
+type Fundamentals struct {
+	Symbol            string  `json:"symbol"`
+	Name              string  `json:"name,omitempty"`
+	Sector            string  `json:"sector,omitempty"` // 行业分类，不同数据源的分类体系不同
+	MarketCap         float64 `json:"market_cap"`       // 市值（美元）
+	SharesOutstanding int64   `json:"shares_outstanding"`
+	FloatShares       int64   `json:"float_shares"` // 自由流通股数
+	PERatio           float64 `json:"pe_ratio"`     // 市盈率（TTM），亏损时为0
+	EPS               float64 `json:"eps"`          // 每股收益（TTM），亏损时为负数
+}
+
+// GapPercent 返回最新价相对最近常规交易收盘价的涨跌幅（百分比），缺少收盘价时返回0
+func (q *Quote) GapPercent() float64 {
+	if q.RegularClose <= 0 || q.LastPrice <= 0 {
+		return 0
+	}
+	return (q.LastPrice - q.RegularClose) / q.RegularClose * 100
 }
 
 // Stock 定义了股票基本信息的结构
@@ -71,14 +195,21 @@ type Stock struct {
 
 // DataSourceConfig 定义了数据源配置的结构
 type DataSourceConfig struct {
-	Name              string        `json:"name" yaml:"name"`
-	Enabled           bool          `json:"enabled" yaml:"enabled"`
-	APIKey            string        `json:"api_key" yaml:"api_key"`
-	BaseURL           string        `json:"base_url" yaml:"base_url"`
-	TimeoutSeconds    int           `json:"timeout_seconds" yaml:"timeout_seconds"`
-	RetryAttempts     int           `json:"retry_attempts" yaml:"retry_attempts"`
-	RetryDelaySeconds int           `json:"retry_delay_seconds" yaml:"retry_delay_seconds"`
-	Timeout           time.Duration `json:"-" yaml:"-"` // 在初始化时根据TimeoutSeconds计算
+	Name               string        `json:"name" yaml:"name"`
+	Enabled            bool          `json:"enabled" yaml:"enabled"`
+	APIKey             string        `json:"api_key" yaml:"api_key"`
+	APIKeys            []string      `json:"api_keys" yaml:"api_keys"`                         // 额外的API密钥，与 APIKey 一起组成密钥池
+	KeySelection       string        `json:"key_selection" yaml:"key_selection"`               // 密钥选择策略：round_robin（默认）或 least_used
+	KeyCooldownSeconds int           `json:"key_cooldown_seconds" yaml:"key_cooldown_seconds"` // 密钥被限流后停用的秒数，为0时使用 DefaultKeyCooldown
+	BaseURL            string        `json:"base_url" yaml:"base_url"`
+	StreamURL          string        `json:"stream_url" yaml:"stream_url"` // 实时报价推送的WebSocket地址，为空时使用数据源的默认地址
+	TimeoutSeconds     int           `json:"timeout_seconds" yaml:"timeout_seconds"`
+	RetryAttempts      int           `json:"retry_attempts" yaml:"retry_attempts"`
+	RetryDelaySeconds  int           `json:"retry_delay_seconds" yaml:"retry_delay_seconds"`
+	Timezone           string        `json:"timezone" yaml:"timezone"` // 不带时区的时间戳所用的当地时区，为空时时间戳已包含时区
+	RequestsPerMinute  int           `json:"requests_per_minute" yaml:"requests_per_minute"` // 每个密钥每分钟的最多请求数，为0时使用数据源的默认值（Finnhub免费版为60）
+	ClientID           int           `json:"client_id" yaml:"client_id"` // TWS API客户端ID，同一TWS的每个连接必须不同（仅IBKR）
+	Timeout            time.Duration `json:"-" yaml:"-"`               // 在初始化时根据TimeoutSeconds计算
 }
 
 // DataSourceError 定义了数据源错误的结构
@@ -0,0 +1,492 @@
+// Package debugger 用实盘的预写日志、录制的行情和模拟时钟重放过去的一个交易时段，
+// 按K线逐步前进，检查每一步的引擎状态，并与当时实盘的实际结果比较
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// ErrEndOfSession 表示模拟时钟已经到达重放区间的终点
+var ErrEndOfSession = errors.New("end of replay session")
+
+// 差异字段常量
+const (
+	FieldQuantity     = "quantity"       // 持仓数量
+	FieldEntryPrice   = "entry_price"    // 持仓成本价
+	FieldCash         = "cash"           // 账户现金
+	FieldRealizedPnL  = "realized_pnl"   // 已实现盈亏
+	FieldOrderStatus  = "status"         // 订单状态
+	FieldFilledQty    = "filled_qty"     // 订单成交数量
+	FieldAvgFillPrice = "avg_fill_price" // 订单成交均价
+)
+
+// StepFunc 在每一步K线走完后调用，可以在重放引擎上下单以验证修改后的策略逻辑
+type StepFunc func(ctx context.Context, step *Step, engine *trading.BaseTradingEngine) error
+
+// Config 表示重放调试的参数，零值字段使用默认值
+type Config struct {
+	WAL       trading.WAL           // 实盘引擎的预写日志，如 trading.OpenFileWAL 打开的文件
+	Data      datasource.DataSource // 录制的行情，如 datasource.NewRecordedDataSource
+	From      time.Time             // 重放区间的起点，之前的日志记录作为初始状态
+	To        time.Time             // 重放区间的终点
+	Timeframe string                // 每一步前进的K线周期，默认为 minute
+	Symbols   []string              // 获取K线的股票，为空时使用区间内下单和起点时持仓的股票
+	Strategy  StepFunc              // 重放引擎的下单逻辑，为空时按实盘日志的时间重新提交实盘的订单
+	Broker    trading.BrokerConfig  // 重放引擎的券商配置，如持仓模式
+	Limits    trading.TradingLimits // 重放引擎的风控限制
+	Tolerance float64               // 价格和金额差异的容差，默认0.01
+}
+
+// withDefaults 返回填充默认值后的参数（内部方法）
+func (c Config) withDefaults() Config {
+	if c.Timeframe == "" {
+		c.Timeframe = "minute"
+	}
+	if c.Tolerance <= 0 {
+		c.Tolerance = 0.01
+	}
+	return c
+}
+
+// Difference 表示某一步实盘与重放结果的一处差异
+type Difference struct {
+	Symbol   string      `json:"symbol,omitempty"`
+	Strategy string      `json:"strategy,omitempty"`
+	OrderID  string      `json:"order_id,omitempty"` // 实盘订单ID，订单差异时设置
+	Field    string      `json:"field"`
+	Live     interface{} `json:"live"`
+	Replay   interface{} `json:"replay"`
+}
+
+// Step 表示重放前进的一步
+type Step struct {
+	Index   int                             `json:"index"`
+	Time    time.Time                       `json:"time"`              // 模拟时钟在这一步结束时的时间
+	Bars    map[string]datasource.StockData `json:"bars"`              // 这一步走完的K线，没有成交的股票不包含在内
+	Records []trading.WALRecord             `json:"records,omitempty"` // 这一步应用到实盘引擎的日志记录
+	Orders  []trading.Order                 `json:"orders,omitempty"`  // 这一步重放引擎提交的订单
+	Errors  []string                        `json:"errors,omitempty"`  // 重放引擎下单或策略的错误
+	Diff    []Difference                    `json:"diff,omitempty"`    // 这一步结束时与实盘的差异
+}
+
+// EngineState 表示一个引擎在某一时刻的状态
+type EngineState struct {
+	Positions  []trading.Position `json:"positions"`
+	OpenOrders []trading.Order    `json:"open_orders"`
+	Account    trading.Account    `json:"account"`
+}
+
+// Snapshot 表示重放过程中某一时刻实盘和重放引擎的状态
+type Snapshot struct {
+	Time   time.Time    `json:"time"`
+	Step   int          `json:"step"`
+	Live   EngineState  `json:"live"`
+	Replay EngineState  `json:"replay"`
+	Diff   []Difference `json:"diff,omitempty"`
+}
+
+// mirroredOrder 表示实盘订单与重放时重新提交的订单的对应关系（内部类型）
+type mirroredOrder struct {
+	liveID   string
+	replayID string // 重放引擎拒绝时为空
+}
+
+// Session 表示一次重放调试，不是并发安全的
+type Session struct {
+	config   Config
+	clock    *clock.Manual
+	records  []trading.WALRecord
+	liveWAL  *sessionWAL
+	data     *datasource.Manager
+	live     *trading.BaseTradingEngine
+	replay   *trading.BaseTradingEngine
+	symbols  []string
+	mirrored []mirroredOrder
+	steps    int
+}
+
+// Open 读取实盘的预写日志，用起点之前的记录恢复实盘和重放引擎的初始状态，并把模拟时钟设置到起点
+func Open(ctx context.Context, config Config) (*Session, error) {
+	config = config.withDefaults()
+	if config.WAL == nil || config.Data == nil {
+		return nil, fmt.Errorf("wal and data source are required")
+	}
+	if config.From.IsZero() || !config.To.After(config.From) {
+		return nil, fmt.Errorf("invalid replay range %s - %s", config.From.Format(time.RFC3339), config.To.Format(time.RFC3339))
+	}
+
+	var records []trading.WALRecord
+	err := config.WAL.Replay(func(record trading.WALRecord) error {
+		if !record.Timestamp.After(config.To) {
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wal: %v", err)
+	}
+	initial := sort.Search(len(records), func(i int) bool {
+		return !records[i].Timestamp.Before(config.From)
+	})
+
+	s := &Session{
+		config:  config,
+		clock:   clock.NewManual(config.From),
+		records: records,
+		liveWAL: &sessionWAL{records: records, upto: initial},
+	}
+
+	s.live = trading.NewBaseTradingEngine(nil, config.Broker, config.Limits)
+	s.live.SetClock(s.clock)
+	s.live.SetWAL(s.liveWAL)
+	if _, err := s.live.Recover(); err != nil {
+		return nil, fmt.Errorf("failed to restore live state: %v", err)
+	}
+
+	s.data = datasource.NewManager()
+	if err := s.data.AddDataSource(datasource.NewClockDataSource(config.Data, s.clock)); err != nil {
+		return nil, fmt.Errorf("failed to add data source: %v", err)
+	}
+	s.replay = trading.NewBaseTradingEngine(s.data, config.Broker, config.Limits)
+	s.replay.SetClock(s.clock)
+	if broker, ok := s.replay.GetBroker().(*trading.SimulatedBroker); ok {
+		broker.SetClock(s.clock)
+	}
+	s.replay.SetWAL(&sessionWAL{records: records, upto: initial})
+	if _, err := s.replay.Recover(); err != nil {
+		return nil, fmt.Errorf("failed to restore replay state: %v", err)
+	}
+	s.replay.SetWAL(nil)
+	s.replay.Enable()
+
+	s.symbols = config.Symbols
+	if len(s.symbols) == 0 {
+		s.symbols, err = s.defaultSymbols(ctx, initial)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// defaultSymbols 返回区间内下单和起点时持仓的股票，按字母排序（内部方法）
+func (s *Session) defaultSymbols(ctx context.Context, initial int) ([]string, error) {
+	seen := make(map[string]bool)
+	positions, err := s.live.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %v", err)
+	}
+	for _, pos := range positions {
+		seen[pos.Symbol] = true
+	}
+	for _, record := range s.records[initial:] {
+		if record.Order != nil {
+			seen[record.Order.Symbol] = true
+		}
+	}
+
+	symbols := make([]string, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// Now 返回模拟时钟的当前时间
+func (s *Session) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Symbols 返回获取K线的股票
+func (s *Session) Symbols() []string {
+	return s.symbols
+}
+
+// Live 返回按日志时间重建的实盘引擎，只用于查询
+func (s *Session) Live() *trading.BaseTradingEngine {
+	return s.live
+}
+
+// Replay 返回使用录制行情和模拟券商的重放引擎
+func (s *Session) Replay() *trading.BaseTradingEngine {
+	return s.replay
+}
+
+// Step 将模拟时钟前进一根K线（不超过终点），把这段时间的实盘日志应用到实盘引擎，
+// 用走完的K线重新估值两个引擎的持仓，运行重放引擎的下单逻辑，并返回与实盘的差异。
+// 到达终点后返回 ErrEndOfSession
+func (s *Session) Step(ctx context.Context) (*Step, error) {
+	prev := s.clock.Now()
+	if !prev.Before(s.config.To) {
+		return nil, ErrEndOfSession
+	}
+	now := prev.Add(datasource.BarDuration(s.config.Timeframe))
+	if now.After(s.config.To) {
+		now = s.config.To
+	}
+	s.clock.Set(now)
+
+	step := &Step{Index: s.steps, Time: now, Bars: make(map[string]datasource.StockData)}
+	s.steps++
+
+	// 实盘日志按时间顺序写入，应用时间不晚于当前时钟的记录
+	start := s.liveWAL.upto
+	for s.liveWAL.upto < len(s.records) && !s.records[s.liveWAL.upto].Timestamp.After(now) {
+		s.liveWAL.upto++
+	}
+	step.Records = s.records[start:s.liveWAL.upto]
+	if len(step.Records) > 0 {
+		if _, err := s.live.Recover(); err != nil {
+			return nil, fmt.Errorf("failed to apply live records: %v", err)
+		}
+	}
+
+	prices := make(map[string]float64)
+	for _, symbol := range s.symbols {
+		bars, err := s.data.GetStockData(ctx, symbol, s.config.Timeframe, prev, now)
+		if err != nil {
+			if dsErr, ok := err.(*datasource.DataSourceError); ok && dsErr.Code == "NO_DATA" {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get bars for %s: %v", symbol, err)
+		}
+		if len(bars) > 0 {
+			bar := bars[len(bars)-1]
+			step.Bars[symbol] = bar
+			prices[symbol] = bar.Close
+		}
+	}
+	s.live.ApplyMarks(prices)
+	s.replay.ApplyMarks(prices)
+
+	if s.config.Strategy != nil {
+		if err := s.config.Strategy(ctx, step, s.replay); err != nil {
+			step.Errors = append(step.Errors, fmt.Sprintf("strategy: %v", err))
+		}
+	} else {
+		s.mirrorOrders(ctx, step)
+	}
+
+	diff, err := s.Diff(ctx)
+	if err != nil {
+		return nil, err
+	}
+	step.Diff = diff
+	return step, nil
+}
+
+// mirrorOrders 在重放引擎上重新提交这一步实盘生成的订单（内部方法）
+func (s *Session) mirrorOrders(ctx context.Context, step *Step) {
+	for _, record := range step.Records {
+		if record.Type != trading.WALOrderSubmitted || record.Order == nil {
+			continue
+		}
+		live := record.Order
+		order, err := s.replay.PlaceOrder(ctx, trading.OrderRequest{
+			Symbol:        live.Symbol,
+			Quantity:      live.Quantity,
+			Price:         live.Price,
+			StopPrice:     live.StopPrice,
+			Type:          live.Type,
+			Side:          live.Side,
+			Strategy:      live.Strategy,
+			ClientOrderID: live.ClientOrderID,
+			Tags:          live.Tags,
+			StopLoss:      live.StopLoss,
+			TakeProfit:    live.TakeProfit,
+			TimeExit:      live.TimeExit,
+			Metadata:      live.Metadata,
+		})
+		mirrored := mirroredOrder{liveID: live.ID}
+		if err != nil {
+			step.Errors = append(step.Errors, fmt.Sprintf("order %s: %v", live.ID, err))
+		}
+		if order != nil {
+			mirrored.replayID = order.ID
+			step.Orders = append(step.Orders, *order)
+		}
+		s.mirrored = append(s.mirrored, mirrored)
+	}
+}
+
+// RunUntil 逐步前进直到模拟时钟到达 t 或重放区间的终点，返回经过的每一步
+func (s *Session) RunUntil(ctx context.Context, t time.Time) ([]*Step, error) {
+	var steps []*Step
+	for s.clock.Now().Before(t) {
+		if err := ctx.Err(); err != nil {
+			return steps, err
+		}
+		step, err := s.Step(ctx)
+		if errors.Is(err, ErrEndOfSession) {
+			break
+		}
+		if err != nil {
+			return steps, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// Inspect 返回当前时刻实盘和重放引擎的持仓、未完成订单、账户及两者的差异
+func (s *Session) Inspect(ctx context.Context) (*Snapshot, error) {
+	live, err := engineState(ctx, s.live)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect live engine: %v", err)
+	}
+	replay, err := engineState(ctx, s.replay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect replay engine: %v", err)
+	}
+	diff, err := s.Diff(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Time: s.clock.Now(), Step: s.steps, Live: *live, Replay: *replay, Diff: diff}, nil
+}
+
+// engineState 读取引擎的状态（内部函数）
+func engineState(ctx context.Context, engine *trading.BaseTradingEngine) (*EngineState, error) {
+	positions, err := engine.GetPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := engine.GetOpenOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	account, err := engine.GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &EngineState{Positions: positions, OpenOrders: orders, Account: *account}, nil
+}
+
+// Diff 比较实盘和重放引擎当前的持仓数量和成本价、账户现金和已实现盈亏，
+// 以及重新提交的订单的状态和成交，按股票和策略排序返回差异
+func (s *Session) Diff(ctx context.Context) ([]Difference, error) {
+	livePositions, err := s.live.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get live positions: %v", err)
+	}
+	replayPositions, err := s.replay.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replay positions: %v", err)
+	}
+
+	type key struct{ symbol, strategy string }
+	byKey := func(positions []trading.Position) map[key]trading.Position {
+		result := make(map[key]trading.Position)
+		for _, pos := range positions {
+			if pos.Quantity != 0 {
+				result[key{pos.Symbol, pos.Strategy}] = pos
+			}
+		}
+		return result
+	}
+	live, replay := byKey(livePositions), byKey(replayPositions)
+	keys := make([]key, 0, len(live)+len(replay))
+	for k := range live {
+		keys = append(keys, k)
+	}
+	for k := range replay {
+		if _, ok := live[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].symbol != keys[j].symbol {
+			return keys[i].symbol < keys[j].symbol
+		}
+		return keys[i].strategy < keys[j].strategy
+	})
+
+	var diff []Difference
+	for _, k := range keys {
+		l, r := live[k], replay[k]
+		switch {
+		case l.Quantity != r.Quantity:
+			diff = append(diff, Difference{Symbol: k.symbol, Strategy: k.strategy, Field: FieldQuantity, Live: l.Quantity, Replay: r.Quantity})
+		case math.Abs(l.EntryPrice-r.EntryPrice) > s.config.Tolerance:
+			diff = append(diff, Difference{Symbol: k.symbol, Strategy: k.strategy, Field: FieldEntryPrice, Live: l.EntryPrice, Replay: r.EntryPrice})
+		}
+	}
+
+	liveAccount, err := s.live.GetAccount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get live account: %v", err)
+	}
+	replayAccount, err := s.replay.GetAccount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replay account: %v", err)
+	}
+	if math.Abs(liveAccount.Cash-replayAccount.Cash) > s.config.Tolerance {
+		diff = append(diff, Difference{Field: FieldCash, Live: liveAccount.Cash, Replay: replayAccount.Cash})
+	}
+	if math.Abs(liveAccount.RealizedPnL-replayAccount.RealizedPnL) > s.config.Tolerance {
+		diff = append(diff, Difference{Field: FieldRealizedPnL, Live: liveAccount.RealizedPnL, Replay: replayAccount.RealizedPnL})
+	}
+
+	for _, mirrored := range s.mirrored {
+		l, err := s.live.GetOrder(ctx, mirrored.liveID)
+		if err != nil {
+			continue
+		}
+		if mirrored.replayID == "" {
+			diff = append(diff, Difference{Symbol: l.Symbol, OrderID: l.ID, Field: FieldOrderStatus, Live: l.Status, Replay: trading.OrderStatusRejected})
+			continue
+		}
+		r, err := s.replay.GetOrder(ctx, mirrored.replayID)
+		if err != nil {
+			continue
+		}
+		switch {
+		case l.Status != r.Status:
+			diff = append(diff, Difference{Symbol: l.Symbol, OrderID: l.ID, Field: FieldOrderStatus, Live: l.Status, Replay: r.Status})
+		case l.FilledQty != r.FilledQty:
+			diff = append(diff, Difference{Symbol: l.Symbol, OrderID: l.ID, Field: FieldFilledQty, Live: l.FilledQty, Replay: r.FilledQty})
+		case math.Abs(l.AvgFillPrice-r.AvgFillPrice) > s.config.Tolerance:
+			diff = append(diff, Difference{Symbol: l.Symbol, OrderID: l.ID, Field: FieldAvgFillPrice, Live: l.AvgFillPrice, Replay: r.AvgFillPrice})
+		}
+	}
+	return diff, nil
+}
+
+// sessionWAL 按模拟时钟逐步交出实盘日志记录，每次 Recover 只回放上次之后新到达的记录（内部类型）
+type sessionWAL struct {
+	records []trading.WALRecord
+	applied int
+	upto    int
+}
+
+// Append 拒绝写入，实盘日志在重放时是只读的
+func (w *sessionWAL) Append(record trading.WALRecord) error {
+	return fmt.Errorf("replay wal is read-only")
+}
+
+// Replay 回放尚未应用且时间已到达的记录
+func (w *sessionWAL) Replay(fn func(record trading.WALRecord) error) error {
+	for w.applied < w.upto {
+		record := w.records[w.applied]
+		w.applied++
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 关闭日志
+func (w *sessionWAL) Close() error {
+	return nil
+}
@@ -0,0 +1,192 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// liveBroker 按设置的价格立即成交，模拟实盘成交价与K线收盘价的偏差
+type liveBroker struct {
+	price float64
+	clock clock.Clock
+}
+
+func (b *liveBroker) Name() string { return "live" }
+
+func (b *liveBroker) SubmitOrder(ctx context.Context, order trading.Order) (*trading.Order, error) {
+	now := b.clock.Now()
+	order.Status = trading.OrderStatusFilled
+	order.FilledQty = order.Quantity
+	order.AvgFillPrice = b.price
+	order.FilledAt = &now
+	return &order, nil
+}
+
+func (b *liveBroker) CancelOrder(ctx context.Context, order trading.Order) error { return nil }
+
+func (b *liveBroker) HealthCheck(ctx context.Context) error { return nil }
+
+// recordSession 录制 14:30 开始收盘价为 100、101…109 的分钟线，并写入一天的实盘日志：
+// 开盘前按99买入10股，14:32:30 按102.5买入20股，14:35:30 按105卖出30股（内部函数）
+func recordSession(t *testing.T) (datasource.DataSource, string) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+	start := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+
+	recorder, err := datasource.NewRecorder(filepath.Join(dir, "recordings"), 0)
+	if err != nil {
+		t.Fatalf("创建录制器失败: %v", err)
+	}
+	var bars []datasource.StockData
+	for i := 0; i < 10; i++ {
+		c := float64(100 + i)
+		bars = append(bars, datasource.StockData{Symbol: "AAPL", Timestamp: start.Add(time.Duration(i) * time.Minute), Open: c, High: c, Low: c, Close: c, Volume: 1000})
+	}
+	if err := recorder.RecordBars("minute", bars); err != nil {
+		t.Fatalf("录制K线失败: %v", err)
+	}
+	recorder.Close()
+
+	path := filepath.Join(dir, "engine.wal")
+	wal, err := trading.OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	sim := clock.NewManual(start.Add(-30 * time.Minute))
+	broker := &liveBroker{price: 99, clock: sim}
+	engine := trading.NewBaseTradingEngine(nil, trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetClock(sim)
+	engine.SetBroker(broker)
+	engine.SetWAL(wal)
+	engine.Enable()
+	trades := []struct {
+		at    time.Duration
+		price float64
+		qty   int64
+		side  trading.OrderSide
+	}{
+		{-30 * time.Minute, 99, 10, trading.OrderSideBuy},
+		{150 * time.Second, 102.5, 20, trading.OrderSideBuy},
+		{330 * time.Second, 105, 30, trading.OrderSideSell},
+	}
+	for _, trade := range trades {
+		sim.Set(start.Add(trade.at))
+		broker.price = trade.price
+		if _, err := engine.PlaceOrder(ctx, trading.OrderRequest{Symbol: "AAPL", Quantity: trade.qty, Type: trading.OrderTypeMarket, Side: trade.side}); err != nil {
+			t.Fatalf("实盘下单失败: %v", err)
+		}
+	}
+	return datasource.NewRecordedDataSource("recorded", filepath.Join(dir, "recordings")), path
+}
+
+func TestSessionMirrorsLiveOrders(t *testing.T) {
+	ctx := context.Background()
+	data, path := recordSession(t)
+	wal, err := trading.OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+
+	start := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	session, err := Open(ctx, Config{WAL: wal, Data: data, From: start, To: start.Add(10 * time.Minute), Limits: trading.TradingLimits{MaxPositions: 10}})
+	if err != nil {
+		t.Fatalf("打开重放失败: %v", err)
+	}
+	if symbols := session.Symbols(); len(symbols) != 1 || symbols[0] != "AAPL" {
+		t.Errorf("默认股票应来自日志: %v", symbols)
+	}
+
+	// 起点之前的持仓在两个引擎中一致
+	step, err := session.Step(ctx)
+	if err != nil {
+		t.Fatalf("前进失败: %v", err)
+	}
+	if !step.Time.Equal(start.Add(time.Minute)) || step.Bars["AAPL"].Close != 100 || len(step.Diff) != 0 {
+		t.Fatalf("第一步不正确: %+v", step)
+	}
+
+	// 实盘在 14:32:30 按102.5成交，重放按 14:32 的K线收盘价102成交
+	steps, err := session.RunUntil(ctx, start.Add(3*time.Minute))
+	if err != nil || len(steps) != 2 {
+		t.Fatalf("应再前进两步: %d %v", len(steps), err)
+	}
+	mirrored := steps[1]
+	if len(mirrored.Records) == 0 || len(mirrored.Orders) != 1 || mirrored.Orders[0].AvgFillPrice != 102 {
+		t.Fatalf("应在实盘下单的那一步重新提交订单: %+v", mirrored)
+	}
+	fields := make(map[string]Difference)
+	for _, d := range mirrored.Diff {
+		fields[d.Field] = d
+	}
+	if d, ok := fields[FieldAvgFillPrice]; !ok || d.Live != 102.5 || d.Replay != 102.0 || d.OrderID == "" {
+		t.Errorf("应报告成交价差异: %+v", mirrored.Diff)
+	}
+	if _, ok := fields[FieldEntryPrice]; !ok {
+		t.Errorf("应报告持仓成本价差异: %+v", mirrored.Diff)
+	}
+	if _, ok := fields[FieldQuantity]; ok {
+		t.Errorf("持仓数量应一致: %+v", mirrored.Diff)
+	}
+
+	// 平仓后持仓一致，现金相差实盘多付的10美元
+	if _, err := session.RunUntil(ctx, start.Add(time.Hour)); err != nil {
+		t.Fatalf("运行到终点失败: %v", err)
+	}
+	if _, err := session.Step(ctx); !errors.Is(err, ErrEndOfSession) {
+		t.Errorf("到达终点后应返回 ErrEndOfSession: %v", err)
+	}
+	snapshot, err := session.Inspect(ctx)
+	if err != nil {
+		t.Fatalf("检查状态失败: %v", err)
+	}
+	if snapshot.Step != 10 || len(snapshot.Live.Positions) != 0 || len(snapshot.Replay.Positions) != 0 {
+		t.Errorf("终点时两个引擎都应已平仓: %+v", snapshot)
+	}
+	if snapshot.Replay.Account.Cash-snapshot.Live.Account.Cash != 10 {
+		t.Errorf("现金差异不正确: live=%v replay=%v", snapshot.Live.Account.Cash, snapshot.Replay.Account.Cash)
+	}
+}
+
+func TestSessionStrategy(t *testing.T) {
+	ctx := context.Background()
+	data, path := recordSession(t)
+	wal, err := trading.OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+
+	// 修改后的策略在第一根K线走完时卖出全部持仓，不再重新提交实盘的订单
+	start := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	calls := 0
+	strategy := func(ctx context.Context, step *Step, engine *trading.BaseTradingEngine) error {
+		calls++
+		if step.Index == 0 {
+			_, err := engine.PlaceOrder(ctx, trading.OrderRequest{Symbol: "AAPL", Quantity: 10, Type: trading.OrderTypeMarket, Side: trading.OrderSideSell})
+			return err
+		}
+		return nil
+	}
+	session, err := Open(ctx, Config{WAL: wal, Data: data, From: start, To: start.Add(5 * time.Minute), Strategy: strategy, Limits: trading.TradingLimits{MaxPositions: 10}})
+	if err != nil {
+		t.Fatalf("打开重放失败: %v", err)
+	}
+	steps, err := session.RunUntil(ctx, start.Add(time.Hour))
+	if err != nil || len(steps) != 5 || calls != 5 {
+		t.Fatalf("应在每一步调用策略: %d %d %v", len(steps), calls, err)
+	}
+	last := steps[len(steps)-1]
+	if len(last.Diff) == 0 || last.Diff[0].Field != FieldQuantity || last.Diff[0].Live != int64(30) || last.Diff[0].Replay != int64(0) {
+		t.Errorf("应报告持仓数量差异: %+v", last.Diff)
+	}
+}
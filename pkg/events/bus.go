@@ -0,0 +1,146 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// 事件主题常量
+const (
+	TopicOrders    = "orders"    // 订单状态变化
+	TopicFills     = "fills"     // 成交回报
+	TopicPositions = "positions" // 持仓变化
+	TopicQuotes    = "quotes"    // 行情报价
+	TopicWatchlist = "watchlist" // 监控列表触发
+	TopicSignals   = "signals"   // 扫描器信号
+	TopicRisk      = "risk"      // 组合风险指标
+	TopicSchedule  = "schedule"  // 定时任务运行结果
+	TopicSymbols   = "symbols"   // 股票列表变化
+	TopicConfig    = "config"    // 运行时配置修改
+)
+
+// Event 表示事件总线上传递的一个事件
+type Event struct {
+	Topic     string      `json:"topic"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Payload   interface{} `json:"payload"`
+}
+
+// Bus 是一个进程内的发布/订阅事件总线
+// 发布操作不会阻塞：订阅者的缓冲区已满时，新事件会被丢弃并计数
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[uint64]*Subscription
+	nextID      uint64
+}
+
+// Subscription 表示一个事件订阅
+type Subscription struct {
+	C <-chan Event
+
+	bus     *Bus
+	id      uint64
+	topics  map[string]bool
+	ch      chan Event
+	mu      sync.Mutex
+	closed  bool
+	dropped uint64
+}
+
+// NewBus 创建一个新的事件总线
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[uint64]*Subscription),
+	}
+}
+
+// Subscribe 订阅指定主题的事件，未指定主题时订阅所有事件
+func (b *Bus) Subscribe(bufferSize int, topics ...string) *Subscription {
+	if bufferSize <= 0 {
+		bufferSize = 100
+	}
+
+	ch := make(chan Event, bufferSize)
+	sub := &Subscription{
+		C:      ch,
+		bus:    b,
+		ch:     ch,
+		topics: make(map[string]bool, len(topics)),
+	}
+	for _, topic := range topics {
+		sub.topics[topic] = true
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	sub.id = b.nextID
+	b.subscribers[sub.id] = sub
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Publish 发布一个事件
+func (b *Bus) Publish(evt Event) {
+	if b == nil {
+		return
+	}
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if len(sub.topics) > 0 && !sub.topics[evt.Topic] {
+			continue
+		}
+		sub.deliver(evt)
+	}
+}
+
+// SubscriberCount 返回当前订阅者数量
+func (b *Bus) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+// deliver 向订阅者投递事件，缓冲区已满时丢弃
+func (s *Subscription) deliver(evt Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- evt:
+	default:
+		s.dropped++
+	}
+}
+
+// Dropped 返回因缓冲区已满而被丢弃的事件数量
+func (s *Subscription) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Close 取消订阅并关闭事件通道
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	delete(s.bus.subscribers, s.id)
+	s.bus.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
@@ -0,0 +1,102 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBusDelivery(t *testing.T) {
+	bus := NewBus()
+	orders := bus.Subscribe(10, TopicOrders)
+	all := bus.Subscribe(10)
+	defer orders.Close()
+	defer all.Close()
+
+	// 按主题投递，未指定主题的订阅者收到所有事件，时间戳为空时使用当前时间
+	at := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	bus.Publish(Event{Topic: TopicOrders, Type: "order_filled", Timestamp: at, Payload: "ord-1"})
+	bus.Publish(Event{Topic: TopicQuotes, Type: "quote"})
+
+	if evt := <-orders.C; evt.Type != "order_filled" || !evt.Timestamp.Equal(at) || evt.Payload != "ord-1" {
+		t.Errorf("订单事件不正确: %+v", evt)
+	}
+	select {
+	case evt := <-orders.C:
+		t.Errorf("不应收到其他主题的事件: %+v", evt)
+	default:
+	}
+	first, second := <-all.C, <-all.C
+	if first.Topic != TopicOrders || second.Topic != TopicQuotes || second.Timestamp.IsZero() {
+		t.Errorf("应按发布顺序收到所有事件: %+v %+v", first, second)
+	}
+}
+
+func TestBusDropsWhenFull(t *testing.T) {
+	bus := NewBus()
+	slow := bus.Subscribe(2, TopicQuotes)
+	fast := bus.Subscribe(10, TopicQuotes)
+	defer slow.Close()
+	defer fast.Close()
+
+	// 缓冲区已满时丢弃新事件并计数，发布不阻塞，也不影响其他订阅者
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Topic: TopicQuotes, Payload: i})
+	}
+	if slow.Dropped() != 3 || fast.Dropped() != 0 {
+		t.Errorf("丢弃数量 = %d/%d, 期望 3/0", slow.Dropped(), fast.Dropped())
+	}
+	if evt := <-slow.C; evt.Payload != 0 {
+		t.Errorf("应保留最早的事件: %+v", evt)
+	}
+	if len(fast.C) != 5 {
+		t.Errorf("其他订阅者应收到全部事件: %d", len(fast.C))
+	}
+}
+
+func TestBusPublishAfterClose(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(10)
+	other := bus.Subscribe(10)
+	defer other.Close()
+
+	// 取消订阅后通道关闭，之后的发布不再投递，重复关闭不会出错
+	sub.Close()
+	sub.Close()
+	bus.Publish(Event{Topic: TopicOrders})
+	if _, ok := <-sub.C; ok {
+		t.Error("取消订阅后通道应已关闭")
+	}
+	if bus.SubscriberCount() != 1 || len(other.C) != 1 {
+		t.Errorf("其他订阅者应不受影响: %d %d", bus.SubscriberCount(), len(other.C))
+	}
+
+	// 空的事件总线发布时不做任何事
+	var none *Bus
+	none.Publish(Event{Topic: TopicOrders})
+}
+
+func TestBusConcurrentClose(t *testing.T) {
+	bus := NewBus()
+
+	// 发布和取消订阅并发进行，使用 -race 运行时不应报告数据竞争，也不应向已关闭的通道发送
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		sub := bus.Subscribe(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				bus.Publish(Event{Topic: TopicQuotes})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			sub.Close()
+		}()
+	}
+	wg.Wait()
+	if n := bus.SubscriberCount(); n != 0 {
+		t.Errorf("所有订阅都应已取消: %d", n)
+	}
+}
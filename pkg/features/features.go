@@ -0,0 +1,272 @@
+// Package features 在一组股票和日期区间上计算可配置的指标，输出按K线对齐的特征矩阵和前瞻收益标签，
+// 写成CSV或Parquet文件，供机器学习研究直接使用指标库
+package features
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+)
+
+// Feature 表示一个特征指标
+type Feature struct {
+	Name       string                     `json:"name" yaml:"name"` // 列名前缀，为空时使用指标类型
+	Type       string                     `json:"type" yaml:"type"`
+	Parameters indicators.IndicatorParams `json:"parameters" yaml:"parameters"`
+	Outputs    []string                   `json:"outputs" yaml:"outputs"` // 导出的指标输出，为空时导出全部
+}
+
+// Spec 表示特征导出的配置，零值字段使用默认值
+type Spec struct {
+	Features       []Feature `json:"features" yaml:"features"`
+	Horizons       []int     `json:"horizons" yaml:"horizons"`               // 前瞻收益的K线数，默认 [1, 5]
+	Timeframe      string    `json:"timeframe" yaml:"timeframe"`             // K线周期，默认为 day
+	WarmupDays     int       `json:"warmup_days" yaml:"warmup_days"`         // 在区间之前多取的天数，用于指标预热，默认100
+	DropIncomplete bool      `json:"drop_incomplete" yaml:"drop_incomplete"` // 丢弃有缺失特征的行（如预热不足）
+}
+
+// withDefaults 返回填充默认值后的配置（内部方法）
+func (s Spec) withDefaults() Spec {
+	if len(s.Horizons) == 0 {
+		s.Horizons = []int{1, 5}
+	}
+	if s.Timeframe == "" {
+		s.Timeframe = "day"
+	}
+	if s.WarmupDays <= 0 {
+		s.WarmupDays = 100
+	}
+	return s
+}
+
+// Validate 检查特征配置是否有效
+func (s Spec) Validate() error {
+	if len(s.Features) == 0 {
+		return errors.New("at least one feature is required")
+	}
+	for i, f := range s.Features {
+		if f.Type == "" {
+			return fmt.Errorf("feature %d: type is required", i)
+		}
+	}
+	for _, h := range s.Horizons {
+		if h <= 0 {
+			return fmt.Errorf("horizon must be positive, got %d", h)
+		}
+	}
+	return nil
+}
+
+// LoadSpecFile 读取YAML格式的特征配置文件
+func LoadSpecFile(path string) (Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, err
+	}
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return Spec{}, fmt.Errorf("failed to parse feature spec %s: %v", path, err)
+	}
+	return spec, spec.Validate()
+}
+
+// Row 表示一只股票在一根K线上的特征和标签，缺失值为NaN
+type Row struct {
+	Symbol    string
+	Timestamp time.Time
+	Features  []float64
+	Labels    []float64
+}
+
+// Matrix 表示特征矩阵，行按股票（输入顺序）和时间排序
+type Matrix struct {
+	FeatureNames []string // <特征名称>.<指标输出>，如 rsi14.rsi
+	LabelNames   []string // fwd_ret_<K线数>
+	Rows         []Row
+	Errors       map[string]string // 获取数据或计算失败的股票
+}
+
+// Extract 计算symbols在 [from, to] 区间每根K线上的特征和前瞻收益标签。
+// 标签为 close[t+h]/close[t]-1，计算时会多取区间结束之后的K线，之后没有足够K线的行标签为NaN。
+// 单只股票失败时记录在 Matrix.Errors 中，所有股票都失败时返回错误
+func Extract(ctx context.Context, dataManager *datasource.Manager, registry *indicators.IndicatorRegistry, symbols []string, from, to time.Time, spec Spec) (*Matrix, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	spec = spec.withDefaults()
+	if registry == nil {
+		registry = indicators.NewIndicatorRegistry()
+	}
+
+	matrix := &Matrix{Errors: make(map[string]string)}
+	for _, h := range spec.Horizons {
+		matrix.LabelNames = append(matrix.LabelNames, fmt.Sprintf("fwd_ret_%d", h))
+	}
+
+	// 标签需要区间结束之后的K线，多取的时长覆盖周末和节假日
+	maxHorizon := 0
+	for _, h := range spec.Horizons {
+		maxHorizon = max(maxHorizon, h)
+	}
+	lookahead := time.Duration(2*maxHorizon+4) * datasource.BarDuration(spec.Timeframe)
+
+	extracted := 0
+	for _, symbol := range symbols {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		bars, err := dataManager.GetStockData(ctx, symbol, spec.Timeframe, from.AddDate(0, 0, -spec.WarmupDays), to.Add(lookahead))
+		if err != nil {
+			matrix.Errors[symbol] = err.Error()
+			continue
+		}
+		names, rows, err := extractSymbol(registry, symbol, bars, from, to, spec)
+		if err != nil {
+			matrix.Errors[symbol] = err.Error()
+			continue
+		}
+		if matrix.FeatureNames == nil {
+			matrix.FeatureNames = names
+		}
+		matrix.Rows = append(matrix.Rows, rows...)
+		extracted++
+	}
+
+	if extracted == 0 && len(symbols) > 0 {
+		return matrix, errors.New("feature extraction failed for all symbols")
+	}
+	return matrix, nil
+}
+
+// extractSymbol 计算一只股票的特征列和标签，只保留区间内的行（内部函数）
+func extractSymbol(registry *indicators.IndicatorRegistry, symbol string, bars []datasource.StockData, from, to time.Time, spec Spec) ([]string, []Row, error) {
+	// 标签可以使用区间之后的K线，特征只使用区间结束之前的K线
+	end := sort.Search(len(bars), func(i int) bool { return bars[i].Timestamp.After(to) })
+	history := bars[:end]
+
+	var names []string
+	var columns [][]float64
+	for _, f := range spec.Features {
+		indicator, err := registry.CreateIndicator(f.Type, f.Parameters)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create indicator '%s': %v", f.Type, err)
+		}
+		result, err := indicator.Calculate(history)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to calculate indicator '%s': %v", f.Type, err)
+		}
+		result.Name = f.Name
+		if result.Name == "" {
+			result.Name = f.Type
+		}
+
+		frame := indicators.NewSeriesFrame(symbol, spec.Timeframe, history, result)
+		selected := make(map[string]bool, len(f.Outputs))
+		for _, output := range f.Outputs {
+			selected[result.Name+"."+output] = true
+		}
+		for _, column := range frame.Columns {
+			if len(selected) == 0 || selected[column.Name] {
+				names = append(names, column.Name)
+				columns = append(columns, column.Values)
+				delete(selected, column.Name)
+			}
+		}
+		for name := range selected {
+			return nil, nil, fmt.Errorf("indicator '%s' has no output %s", f.Type, name)
+		}
+	}
+
+	var rows []Row
+	for i, bar := range history {
+		if bar.Timestamp.Before(from) {
+			continue
+		}
+		row := Row{Symbol: symbol, Timestamp: bar.Timestamp, Features: make([]float64, len(columns)), Labels: make([]float64, len(spec.Horizons))}
+		complete := true
+		for j, column := range columns {
+			row.Features[j] = column[i]
+			if math.IsNaN(column[i]) || math.IsInf(column[i], 0) {
+				complete = false
+			}
+		}
+		if spec.DropIncomplete && !complete {
+			continue
+		}
+		for j, h := range spec.Horizons {
+			row.Labels[j] = math.NaN()
+			if i+h < len(bars) && bar.Close != 0 {
+				row.Labels[j] = bars[i+h].Close/bar.Close - 1
+			}
+		}
+		rows = append(rows, row)
+	}
+	return names, rows, nil
+}
+
+// WriteCSV 写出CSV：symbol、timestamp（RFC3339）、特征列和标签列，缺失值为空
+func (m *Matrix) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := append([]string{"symbol", "timestamp"}, m.FeatureNames...)
+	writer.Write(append(header, m.LabelNames...))
+	for _, row := range m.Rows {
+		record := make([]string, 0, 2+len(row.Features)+len(row.Labels))
+		record = append(record, row.Symbol, row.Timestamp.Format(time.RFC3339))
+		for _, v := range append(append([]float64(nil), row.Features...), row.Labels...) {
+			record = append(record, formatValue(v))
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteParquet 写出Parquet文件：symbol（UTF8）、timestamp（UTC毫秒时间戳）、特征列和标签列（double，缺失值为NaN），
+// 可以用 pandas.read_parquet 或 pyarrow.parquet.read_table 读取
+func (m *Matrix) WriteParquet(w io.Writer) error {
+	n := len(m.Rows)
+	symbols, timestamps := make([]string, n), make([]int64, n)
+	values := make([][]float64, len(m.FeatureNames)+len(m.LabelNames))
+	for j := range values {
+		values[j] = make([]float64, n)
+	}
+	for i, row := range m.Rows {
+		if len(row.Features) != len(m.FeatureNames) || len(row.Labels) != len(m.LabelNames) {
+			return fmt.Errorf("row %d of %s has %d features and %d labels, expected %d and %d",
+				i, row.Symbol, len(row.Features), len(row.Labels), len(m.FeatureNames), len(m.LabelNames))
+		}
+		symbols[i], timestamps[i] = row.Symbol, row.Timestamp.UnixMilli()
+		for j, v := range row.Features {
+			values[j][i] = v
+		}
+		for j, v := range row.Labels {
+			values[len(row.Features)+j][i] = v
+		}
+	}
+
+	columns := []parquetColumn{stringColumn("symbol", symbols), timestampColumn("timestamp", timestamps)}
+	for j, name := range append(append([]string(nil), m.FeatureNames...), m.LabelNames...) {
+		columns = append(columns, doubleColumn(name, values[j]))
+	}
+	return writeParquetFile(w, n, columns)
+}
+
+// formatValue 格式化CSV中的数值，NaN和无穷大为空（内部函数）
+func formatValue(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,141 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+func TestExtract(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := testutil.NewMockDataSource("")
+	// 收盘价 100, 101, ..., 119
+	source.SetBars("AAPL", "day", testutil.GenerateBars("AAPL", start, 24*time.Hour, 20, 100, 1))
+
+	path := filepath.Join(t.TempDir(), "features.yaml")
+	content := "features:\n  - name: sma3\n    type: SMA\n    parameters: {period: 3}\n    outputs: [sma]\nhorizons: [1, 2]\nwarmup_days: 5\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := LoadSpecFile(path)
+	if err != nil {
+		t.Fatalf("读取特征配置失败: %v", err)
+	}
+
+	// 区间为第6到第18根K线，最后一行的2根前瞻标签需要区间之后的K线
+	from, to := start.AddDate(0, 0, 5), start.AddDate(0, 0, 17)
+	matrix, err := Extract(context.Background(), testutil.NewManager(source), nil, []string{"AAPL", "MSFT"}, from, to, spec)
+	if err != nil {
+		t.Fatalf("导出特征失败: %v", err)
+	}
+	if _, ok := matrix.Errors["MSFT"]; !ok {
+		t.Error("没有数据的股票应记录在错误中")
+	}
+	if len(matrix.FeatureNames) != 1 || matrix.FeatureNames[0] != "sma3.sma" || strings.Join(matrix.LabelNames, ",") != "fwd_ret_1,fwd_ret_2" {
+		t.Fatalf("列名 = %v %v", matrix.FeatureNames, matrix.LabelNames)
+	}
+	if len(matrix.Rows) != 13 {
+		t.Fatalf("行数 = %d, 期望 13", len(matrix.Rows))
+	}
+
+	first, last := matrix.Rows[0], matrix.Rows[len(matrix.Rows)-1]
+	// 第6根K线收盘105，SMA(3) = (103+104+105)/3
+	if !first.Timestamp.Equal(from) || first.Features[0] != 104 || math.Abs(first.Labels[0]-1.0/105) > 1e-12 {
+		t.Errorf("第一行 = %+v", first)
+	}
+	if !last.Timestamp.Equal(to) || last.Features[0] != 116 || math.Abs(last.Labels[1]-2.0/117) > 1e-12 {
+		t.Errorf("最后一行应使用区间之后的K线计算标签: %+v", last)
+	}
+
+	var buf bytes.Buffer
+	if err := matrix.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 14 || lines[0] != "symbol,timestamp,sma3.sma,fwd_ret_1,fwd_ret_2" || !strings.HasPrefix(lines[1], "AAPL,2024-01-06T00:00:00Z,104,") {
+		t.Errorf("CSV = %s", buf.String())
+	}
+
+	if _, err := Extract(context.Background(), testutil.NewManager(source), nil, []string{"AAPL"}, from, to,
+		Spec{Features: []Feature{{Type: indicators.IndicatorTypeSMA, Outputs: []string{"ema"}}}}); err == nil {
+		t.Error("不存在的指标输出应返回错误")
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	matrix := &Matrix{FeatureNames: []string{"rsi.rsi"}, LabelNames: []string{"fwd_ret_1"}}
+	symbols := []string{"AAPL", "MSFT", "AAPL"}
+	labels := []float64{0.01, math.NaN(), -0.02}
+	for i := 0; i < 3; i++ {
+		matrix.Rows = append(matrix.Rows, Row{
+			Symbol:    symbols[i],
+			Timestamp: time.Date(2024, 1, 2+i, 0, 0, 0, 0, time.UTC),
+			Features:  []float64{50 + float64(i)},
+			Labels:    []float64{labels[i]},
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := matrix.WriteParquet(&buf); err != nil {
+		t.Fatalf("写出Parquet失败: %v", err)
+	}
+	file := readParquet(t, buf.Bytes())
+	if file.rows != 3 || fieldString(t, file.meta, 6) != "qhft-system" {
+		t.Errorf("文件元数据不正确: %v", file.meta)
+	}
+
+	// schema依次为symbol、timestamp、特征列和标签列
+	want := []decodedColumn{
+		{name: "symbol", typ: parquetByteArray, converted: parquetUTF8},
+		{name: "timestamp", typ: parquetInt64, converted: parquetTimestampMillis},
+		{name: "rsi.rsi", typ: parquetDouble, converted: parquetNoConverted},
+		{name: "fwd_ret_1", typ: parquetDouble, converted: parquetNoConverted},
+	}
+	if len(file.columns) != len(want) {
+		t.Fatalf("列数量 = %d, 期望 %d", len(file.columns), len(want))
+	}
+	for i, column := range file.columns {
+		if column.name != want[i].name || column.typ != want[i].typ || column.converted != want[i].converted {
+			t.Errorf("第%d列 = %s(%d,%d), 期望 %s(%d,%d)", i, column.name, column.typ, column.converted, want[i].name, want[i].typ, want[i].converted)
+		}
+	}
+
+	// 读回的值与输入一致，NaN原样保留
+	for i, row := range matrix.Rows {
+		if got := file.columns[0].strings[i]; got != row.Symbol {
+			t.Errorf("第%d行symbol = %s, 期望 %s", i, got, row.Symbol)
+		}
+		if got := file.columns[1].ints[i]; got != row.Timestamp.UnixMilli() {
+			t.Errorf("第%d行timestamp = %d, 期望 %d", i, got, row.Timestamp.UnixMilli())
+		}
+		if got := file.columns[2].doubles[i]; got != row.Features[0] {
+			t.Errorf("第%d行rsi.rsi = %v, 期望 %v", i, got, row.Features[0])
+		}
+		if got := file.columns[3].doubles[i]; got != row.Labels[0] && !(math.IsNaN(got) && math.IsNaN(row.Labels[0])) {
+			t.Errorf("第%d行fwd_ret_1 = %v, 期望 %v", i, got, row.Labels[0])
+		}
+	}
+
+	// 没有行时仍写出合法的文件
+	empty := &Matrix{FeatureNames: matrix.FeatureNames, LabelNames: matrix.LabelNames}
+	buf.Reset()
+	if err := empty.WriteParquet(&buf); err != nil {
+		t.Fatalf("写出空Parquet失败: %v", err)
+	}
+	if file := readParquet(t, buf.Bytes()); file.rows != 0 || len(file.columns) != 4 {
+		t.Errorf("空文件应有4列0行: %d列 %d行", len(file.columns), file.rows)
+	}
+
+	matrix.Rows[0].Features = nil
+	if err := matrix.WriteParquet(&buf); err == nil {
+		t.Error("特征数量不一致时应返回错误")
+	}
+}
@@ -0,0 +1,226 @@
+package features
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// 本文件实现写出Parquet文件所需的最小子集：所有列为REQUIRED、PLAIN编码、不压缩，
+// 一个行组，每列一个数据页。元数据按Thrift Compact协议编码
+
+// parquetMagic 是Parquet文件首尾的魔数
+const parquetMagic = "PAR1"
+
+// Parquet物理类型和转换类型
+const (
+	parquetInt64           = 2
+	parquetDouble          = 5
+	parquetByteArray       = 6
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+	parquetNoConverted     = -1
+)
+
+// Thrift Compact协议的字段类型
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetColumn 表示一个要写出的列（内部类型）
+type parquetColumn struct {
+	name      string
+	typ       int32 // parquetInt64、parquetDouble 或 parquetByteArray
+	converted int32 // 转换类型，没有时为 parquetNoConverted
+	data      []byte
+	count     int
+}
+
+// doubleColumn 创建一个double列（内部函数）
+func doubleColumn(name string, values []float64) parquetColumn {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return parquetColumn{name: name, typ: parquetDouble, converted: parquetNoConverted, data: data, count: len(values)}
+}
+
+// timestampColumn 创建一个UTC毫秒时间戳列（内部函数）
+func timestampColumn(name string, millis []int64) parquetColumn {
+	data := make([]byte, 8*len(millis))
+	for i, v := range millis {
+		binary.LittleEndian.PutUint64(data[8*i:], uint64(v))
+	}
+	return parquetColumn{name: name, typ: parquetInt64, converted: parquetTimestampMillis, data: data, count: len(millis)}
+}
+
+// stringColumn 创建一个UTF-8字符串列（内部函数）
+func stringColumn(name string, values []string) parquetColumn {
+	var data []byte
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(v)))
+		data = append(data, v...)
+	}
+	return parquetColumn{name: name, typ: parquetByteArray, converted: parquetUTF8, data: data, count: len(values)}
+}
+
+// writeParquetFile 把行数为rows的列写成Parquet文件（内部函数）
+func writeParquetFile(w io.Writer, rows int, columns []parquetColumn) error {
+	out := []byte(parquetMagic)
+	offsets := make([]int64, len(columns))
+	sizes := make([]int64, len(columns))
+	for i, column := range columns {
+		header := &compactWriter{}
+		header.begin()
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(len(column.data)))
+		header.i32(3, int32(len(column.data)))
+		header.structField(5)
+		header.i32(1, int32(column.count))
+		header.i32(2, 0) // PLAIN
+		header.i32(3, 3) // RLE，REQUIRED列没有定义级别
+		header.i32(4, 3)
+		header.end()
+		header.end()
+
+		offsets[i] = int64(len(out))
+		sizes[i] = int64(len(header.buf) + len(column.data))
+		out = append(out, header.buf...)
+		out = append(out, column.data...)
+	}
+
+	meta := &compactWriter{}
+	meta.begin()
+	meta.i32(1, 1)
+	// schema：根节点加每列一个叶子节点
+	meta.list(2, thriftStruct, len(columns)+1)
+	meta.begin()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(columns)))
+	meta.end()
+	for _, column := range columns {
+		meta.begin()
+		meta.i32(1, column.typ)
+		meta.i32(3, 0) // REQUIRED
+		meta.binary(4, column.name)
+		if column.converted != parquetNoConverted {
+			meta.i32(6, column.converted)
+		}
+		meta.end()
+	}
+	meta.i64(3, int64(rows))
+	// 一个行组
+	meta.list(4, thriftStruct, 1)
+	meta.begin()
+	meta.list(1, thriftStruct, len(columns))
+	var total int64
+	for i, column := range columns {
+		meta.begin()
+		meta.i64(2, offsets[i])
+		meta.structField(3)
+		meta.i32(1, column.typ)
+		meta.list(2, thriftI32, 1)
+		meta.varint(0) // PLAIN
+		meta.list(3, thriftBinary, 1)
+		meta.varint(uint64(len(column.name)))
+		meta.buf = append(meta.buf, column.name...)
+		meta.i32(4, 0) // UNCOMPRESSED
+		meta.i64(5, int64(column.count))
+		meta.i64(6, sizes[i])
+		meta.i64(7, sizes[i])
+		meta.i64(9, offsets[i])
+		meta.end()
+		meta.end()
+		total += sizes[i]
+	}
+	meta.i64(2, total)
+	meta.i64(3, int64(rows))
+	meta.end()
+	meta.binary(6, "qhft-system")
+	meta.end()
+
+	out = append(out, meta.buf...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(meta.buf)))
+	out = append(out, parquetMagic...)
+	_, err := w.Write(out)
+	return err
+}
+
+// compactWriter 按Thrift Compact协议编码结构体（内部类型）
+type compactWriter struct {
+	buf  []byte
+	last []int16 // 每层结构体上一个字段的编号
+}
+
+// begin 开始一个结构体，用于顶层结构体和列表元素（内部方法）
+func (w *compactWriter) begin() {
+	w.last = append(w.last, 0)
+}
+
+// end 结束当前结构体（内部方法）
+func (w *compactWriter) end() {
+	w.buf = append(w.buf, 0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// field 写出字段头，与上一个字段编号相差1到15时使用短格式（内部方法）
+func (w *compactWriter) field(id int16, typ byte) {
+	top := &w.last[len(w.last)-1]
+	if delta := id - *top; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.varint(zigzag(int64(id)))
+	}
+	*top = id
+}
+
+// i32 写出i32字段（内部方法）
+func (w *compactWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.varint(zigzag(int64(v)))
+}
+
+// i64 写出i64字段（内部方法）
+func (w *compactWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.varint(zigzag(v))
+}
+
+// binary 写出字符串字段（内部方法）
+func (w *compactWriter) binary(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.varint(uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// structField 开始一个结构体字段，之后需要调用 end（内部方法）
+func (w *compactWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.begin()
+}
+
+// list 写出列表字段头，之后依次写出n个元素（内部方法）
+func (w *compactWriter) list(id int16, elem byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|elem)
+		return
+	}
+	w.buf = append(w.buf, 0xF0|elem)
+	w.varint(uint64(n))
+}
+
+// varint 写出无符号变长整数（内部方法）
+func (w *compactWriter) varint(v uint64) {
+	w.buf = binary.AppendUvarint(w.buf, v)
+}
+
+// zigzag 把有符号整数映射为无符号整数（内部函数）
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
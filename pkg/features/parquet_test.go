@@ -0,0 +1,286 @@
+package features
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// parquetFile 是按元数据解码出的Parquet文件（内部类型）
+type parquetFile struct {
+	meta    map[int16]any
+	rows    int64
+	columns []decodedColumn
+}
+
+// decodedColumn 是从数据页读回的一列（内部类型）
+type decodedColumn struct {
+	name      string
+	typ       int64
+	converted int64 // 没有转换类型时为 parquetNoConverted
+	strings   []string
+	ints      []int64
+	doubles   []float64
+}
+
+// readParquet 解码文件尾部的FileMetaData，校验schema与列块的偏移和大小，
+// 并从每列的数据页读回PLAIN编码的值（内部函数）
+func readParquet(t *testing.T, data []byte) parquetFile {
+	t.Helper()
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatal("文件首尾应为PAR1")
+	}
+	footer := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	metaStart := len(data) - 8 - footer
+	reader := &thriftReader{data: data[metaStart : len(data)-8]}
+	meta := reader.readStruct()
+	if reader.err != nil || reader.pos != footer {
+		t.Fatalf("解码FileMetaData失败: %v，读取%d/%d字节", reader.err, reader.pos, footer)
+	}
+	file := parquetFile{meta: meta, rows: fieldInt(t, meta, 3)}
+
+	// schema：根节点的子节点数等于叶子节点数
+	schema := fieldList(t, meta, 2)
+	root := asStruct(t, schema[0])
+	if fieldString(t, root, 4) != "schema" || fieldInt(t, root, 5) != int64(len(schema)-1) {
+		t.Fatalf("schema根节点不正确: %v", root)
+	}
+	for _, elem := range schema[1:] {
+		leaf := asStruct(t, elem)
+		if fieldInt(t, leaf, 3) != 0 {
+			t.Errorf("列应为REQUIRED: %v", leaf)
+		}
+		column := decodedColumn{name: fieldString(t, leaf, 4), typ: fieldInt(t, leaf, 1), converted: parquetNoConverted}
+		if _, ok := leaf[6]; ok {
+			column.converted = fieldInt(t, leaf, 6)
+		}
+		file.columns = append(file.columns, column)
+	}
+
+	// 一个行组，列块按schema顺序紧接在文件头之后，最后一个列块紧接着元数据
+	groups := fieldList(t, meta, 4)
+	if len(groups) != 1 {
+		t.Fatalf("应有1个行组: %d", len(groups))
+	}
+	group := asStruct(t, groups[0])
+	if fieldInt(t, group, 3) != file.rows {
+		t.Errorf("行组行数 = %d, 期望 %d", fieldInt(t, group, 3), file.rows)
+	}
+	chunks := fieldList(t, group, 1)
+	if len(chunks) != len(file.columns) {
+		t.Fatalf("列块数量 = %d, 期望 %d", len(chunks), len(file.columns))
+	}
+	offset, total := int64(len(parquetMagic)), int64(0)
+	for i, elem := range chunks {
+		column := &file.columns[i]
+		chunk := asStruct(t, elem)
+		md := asStruct(t, chunk[3])
+		path := fieldList(t, md, 3)
+		if len(path) != 1 || path[0] != column.name || fieldInt(t, md, 1) != column.typ {
+			t.Errorf("列块%d的路径或类型与schema不一致: %v", i, md)
+		}
+		if codec := fieldInt(t, md, 4); codec != 0 {
+			t.Errorf("列%s不应压缩: %d", column.name, codec)
+		}
+		if encodings := fieldList(t, md, 2); len(encodings) != 1 || encodings[0] != int64(0) {
+			t.Errorf("列%s应只使用PLAIN编码: %v", column.name, encodings)
+		}
+		if fieldInt(t, md, 5) != file.rows {
+			t.Errorf("列%s的值数量 = %d, 期望 %d", column.name, fieldInt(t, md, 5), file.rows)
+		}
+		pageOffset, size := fieldInt(t, md, 9), fieldInt(t, md, 7)
+		if fieldInt(t, chunk, 2) != offset || pageOffset != offset || fieldInt(t, md, 6) != size || offset+size > int64(metaStart) {
+			t.Fatalf("列%s的偏移或大小不正确: 文件偏移%d 数据页偏移%d，期望%d", column.name, fieldInt(t, chunk, 2), pageOffset, offset)
+		}
+
+		// 数据页头之后是PLAIN编码的值，页头与值的大小之和等于列块大小
+		page := &thriftReader{data: data[pageOffset : pageOffset+size]}
+		header := page.readStruct()
+		if page.err != nil {
+			t.Fatalf("解码列%s的数据页头失败: %v", column.name, page.err)
+		}
+		length := fieldInt(t, header, 3)
+		dataPage := asStruct(t, header[5])
+		if fieldInt(t, header, 1) != 0 || fieldInt(t, header, 2) != length || int64(page.pos)+length != size {
+			t.Fatalf("列%s的数据页头不正确: %v", column.name, header)
+		}
+		if fieldInt(t, dataPage, 1) != file.rows || fieldInt(t, dataPage, 2) != 0 {
+			t.Errorf("列%s的数据页应有%d个PLAIN值: %v", column.name, file.rows, dataPage)
+		}
+		values := data[pageOffset+int64(page.pos) : pageOffset+size]
+		if err := column.decode(values, int(file.rows)); err != nil {
+			t.Fatalf("读取列%s的值失败: %v", column.name, err)
+		}
+		offset += size
+		total += size
+	}
+	if offset != int64(metaStart) || fieldInt(t, group, 2) != total {
+		t.Errorf("列块应连续排列到元数据之前: 结束于%d，元数据从%d开始，行组大小%d", offset, metaStart, fieldInt(t, group, 2))
+	}
+	return file
+}
+
+// decode 按物理类型解码n个PLAIN值（内部方法）
+func (c *decodedColumn) decode(data []byte, n int) error {
+	for i := 0; i < n; i++ {
+		switch c.typ {
+		case parquetByteArray:
+			if len(data) < 4 || len(data)-4 < int(binary.LittleEndian.Uint32(data)) {
+				return fmt.Errorf("第%d个字符串越界", i)
+			}
+			size := int(binary.LittleEndian.Uint32(data))
+			c.strings = append(c.strings, string(data[4:4+size]))
+			data = data[4+size:]
+		case parquetInt64, parquetDouble:
+			if len(data) < 8 {
+				return fmt.Errorf("第%d个值越界", i)
+			}
+			bits := binary.LittleEndian.Uint64(data)
+			if c.typ == parquetInt64 {
+				c.ints = append(c.ints, int64(bits))
+			} else {
+				c.doubles = append(c.doubles, math.Float64frombits(bits))
+			}
+			data = data[8:]
+		default:
+			return fmt.Errorf("未知的物理类型 %d", c.typ)
+		}
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("数据页剩余%d字节", len(data))
+	}
+	return nil
+}
+
+// thriftReader 按Thrift Compact协议解码结构体，只支持写出时用到的字段类型（内部类型）
+type thriftReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+// readStruct 解码一个结构体，字段值为int64、string、[]any或map[int16]any（内部方法）
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for r.err == nil {
+		b := r.byte()
+		if b == 0 {
+			break
+		}
+		if delta := b >> 4; delta != 0 {
+			last += int16(delta)
+		} else {
+			last = int16(unzigzag(r.uvarint()))
+		}
+		fields[last] = r.readValue(b & 0x0F)
+	}
+	return fields
+}
+
+// readValue 解码一个给定类型的值（内部方法）
+func (r *thriftReader) readValue(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return unzigzag(r.uvarint())
+	case thriftBinary:
+		n := int(r.uvarint())
+		if r.err == nil && n > len(r.data)-r.pos {
+			r.err = fmt.Errorf("字符串越界: 偏移%d 长度%d", r.pos, n)
+		}
+		if r.err != nil {
+			return ""
+		}
+		r.pos += n
+		return string(r.data[r.pos-n : r.pos])
+	case thriftList:
+		header := r.byte()
+		n := int(header >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]any, 0, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			list = append(list, r.readValue(header&0x0F))
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	if r.err == nil {
+		r.err = fmt.Errorf("不支持的字段类型 %d，偏移%d", typ, r.pos)
+	}
+	return nil
+}
+
+// byte 读取一个字节（内部方法）
+func (r *thriftReader) byte() byte {
+	if r.err == nil && r.pos >= len(r.data) {
+		r.err = fmt.Errorf("数据在偏移%d处截断", r.pos)
+	}
+	if r.err != nil {
+		return 0
+	}
+	r.pos++
+	return r.data[r.pos-1]
+}
+
+// uvarint 读取无符号变长整数（内部方法）
+func (r *thriftReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.err = fmt.Errorf("变长整数在偏移%d处无效", r.pos)
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+// unzigzag 是 zigzag 的逆映射（内部函数）
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// fieldInt 返回整数字段（内部函数）
+func fieldInt(t *testing.T, fields map[int16]any, id int16) int64 {
+	t.Helper()
+	v, ok := fields[id].(int64)
+	if !ok {
+		t.Fatalf("字段%d应为整数: %v", id, fields)
+	}
+	return v
+}
+
+// fieldString 返回字符串字段（内部函数）
+func fieldString(t *testing.T, fields map[int16]any, id int16) string {
+	t.Helper()
+	v, ok := fields[id].(string)
+	if !ok {
+		t.Fatalf("字段%d应为字符串: %v", id, fields)
+	}
+	return v
+}
+
+// fieldList 返回列表字段（内部函数）
+func fieldList(t *testing.T, fields map[int16]any, id int16) []any {
+	t.Helper()
+	v, ok := fields[id].([]any)
+	if !ok {
+		t.Fatalf("字段%d应为列表: %v", id, fields)
+	}
+	return v
+}
+
+// asStruct 把值断言为结构体（内部函数）
+func asStruct(t *testing.T, v any) map[int16]any {
+	t.Helper()
+	fields, ok := v.(map[int16]any)
+	if !ok {
+		t.Fatalf("应为结构体: %v", v)
+	}
+	return fields
+}
@@ -0,0 +1,413 @@
+package fix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	filestore "github.com/quickfixgo/quickfix/store/file"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// ErrNotLoggedOn 表示FIX会话尚未登录
+var ErrNotLoggedOn = errors.New("fix session is not logged on")
+
+// pendingResult 表示等待中的请求收到的首个回报（内部类型）
+type pendingResult struct {
+	order trading.Order
+	err   error
+}
+
+// Broker 是基于quickfixgo的FIX券商适配器，实现 trading.Broker 接口
+// 提交订单后等待券商的首个执行回报，之后的成交通过 Executions() 推送，订单状态变化通过 OrderUpdates() 推送
+// 会话序号由quickfix存储在 StorePath 中，断线重连后由quickfix完成重发和补缺，
+// 登录成功后还会对所有未完成订单发送状态查询，以补齐断线期间可能遗漏的回报
+type Broker struct {
+	cfg       Config
+	sessionID quickfix.SessionID
+	initiator *quickfix.Initiator
+
+	mu         sync.Mutex
+	loggedOn   bool
+	lastLogout string
+	orders     map[string]trading.Order      // 按ClOrdID索引的订单
+	aliases    map[string]string             // 撤单ClOrdID到原订单ID的映射
+	pending    map[string]chan pendingResult // 等待首个回报的请求
+	seenExecs  map[string]map[string]bool    // 按订单ID索引的已处理ExecID，用于过滤重发的回报
+	cancelSeq  int64
+	executions *outbox[trading.Execution]
+	updates    *outbox[trading.OrderUpdate]
+	wantExecs  bool // 调用过 Executions 后才推送成交，避免没有消费者时回报无限积压
+}
+
+// NewBroker 创建FIX券商适配器，调用Start后开始连接
+func NewBroker(cfg Config) (*Broker, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.SubmitTimeout == 0 {
+		cfg.SubmitTimeout = 5 * time.Second
+	}
+
+	b := &Broker{
+		cfg:        cfg,
+		sessionID:  cfg.sessionID(),
+		orders:     make(map[string]trading.Order),
+		aliases:    make(map[string]string),
+		pending:    make(map[string]chan pendingResult),
+		seenExecs:  make(map[string]map[string]bool),
+		executions: newOutbox[trading.Execution]("成交回报", defaultExecutionBufferSize),
+		updates:    newOutbox[trading.OrderUpdate]("订单状态", defaultExecutionBufferSize),
+	}
+
+	settings, err := cfg.settings()
+	if err != nil {
+		return nil, err
+	}
+
+	storeFactory := quickfix.NewMemoryStoreFactory()
+	if cfg.StorePath != "" {
+		storeFactory = filestore.NewStoreFactory(settings)
+	}
+	logFactory := quickfix.NewNullLogFactory()
+	if cfg.LogPath != "" {
+		if logFactory, err = quickfix.NewFileLogFactory(settings); err != nil {
+			return nil, fmt.Errorf("failed to create fix log: %v", err)
+		}
+	}
+
+	if b.initiator, err = quickfix.NewInitiator(b, storeFactory, settings, logFactory); err != nil {
+		return nil, fmt.Errorf("failed to create fix initiator: %v", err)
+	}
+	return b, nil
+}
+
+// Name 返回券商名称
+func (b *Broker) Name() string {
+	return fmt.Sprintf("fix:%s", b.cfg.TargetCompID)
+}
+
+// Start 启动FIX会话，连接和登录在后台进行，断线后自动重连
+func (b *Broker) Start() error {
+	if err := b.initiator.Start(); err != nil {
+		return fmt.Errorf("failed to start fix session: %v", err)
+	}
+	return nil
+}
+
+// Stop 注销并关闭FIX会话
+func (b *Broker) Stop() {
+	b.initiator.Stop()
+}
+
+// Executions 返回成交回报通道，只推送首次调用之后收到的成交；通道满时回报排队等待读取，不会丢弃
+func (b *Broker) Executions() <-chan trading.Execution {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.wantExecs = true
+	return b.executions.ch
+}
+
+// OrderUpdates 返回订单状态变化通道，实现 trading.ExecutionStream 接口；通道满时变化排队等待读取，不会丢弃
+func (b *Broker) OrderUpdates() <-chan trading.OrderUpdate {
+	return b.updates.ch
+}
+
+// IsLoggedOn 返回会话是否已登录
+func (b *Broker) IsLoggedOn() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.loggedOn
+}
+
+// SubmitOrder 发送新订单并等待券商的首个执行回报
+// 超时未收到回报时返回已提交状态的订单，后续状态通过回报更新
+func (b *Broker) SubmitOrder(ctx context.Context, order trading.Order) (*trading.Order, error) {
+	msg, err := newOrderSingle(b.cfg, order)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	if !b.loggedOn {
+		b.mu.Unlock()
+		return nil, ErrNotLoggedOn
+	}
+	order.Status = trading.OrderStatusSubmitted
+	b.orders[order.ID] = order
+	wait := make(chan pendingResult, 1)
+	b.pending[order.ID] = wait
+	b.mu.Unlock()
+
+	if err := quickfix.SendToTarget(msg, b.sessionID); err != nil {
+		b.mu.Lock()
+		delete(b.orders, order.ID)
+		delete(b.pending, order.ID)
+		b.mu.Unlock()
+		return nil, fmt.Errorf("failed to send order: %v", err)
+	}
+
+	result, ok := b.await(ctx, order.ID, wait)
+	if !ok {
+		return &order, nil
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &result.order, nil
+}
+
+// CancelOrder 发送撤单请求并等待券商确认或拒绝
+func (b *Broker) CancelOrder(ctx context.Context, order trading.Order) error {
+	b.mu.Lock()
+	if !b.loggedOn {
+		b.mu.Unlock()
+		return ErrNotLoggedOn
+	}
+	if tracked, ok := b.orders[order.ID]; ok {
+		order.BrokerOrderID = tracked.BrokerOrderID
+	}
+	b.cancelSeq++
+	clOrdID := fmt.Sprintf("%s-cxl-%d", order.ID, b.cancelSeq)
+	b.aliases[clOrdID] = order.ID
+	wait := make(chan pendingResult, 1)
+	b.pending[clOrdID] = wait
+	b.mu.Unlock()
+
+	msg, err := orderCancelRequest(b.cfg, order, clOrdID)
+	if err == nil {
+		err = quickfix.SendToTarget(msg, b.sessionID)
+	}
+	if err != nil {
+		b.mu.Lock()
+		delete(b.aliases, clOrdID)
+		delete(b.pending, clOrdID)
+		b.mu.Unlock()
+		return fmt.Errorf("failed to send cancel request: %v", err)
+	}
+
+	result, ok := b.await(ctx, clOrdID, wait)
+	if !ok {
+		return fmt.Errorf("cancel request %s not acknowledged within %s", clOrdID, b.cfg.SubmitTimeout)
+	}
+	return result.err
+}
+
+// HealthCheck 检查FIX会话是否已登录
+func (b *Broker) HealthCheck(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.loggedOn {
+		if b.lastLogout != "" {
+			return fmt.Errorf("%w: %s", ErrNotLoggedOn, b.lastLogout)
+		}
+		return ErrNotLoggedOn
+	}
+	return nil
+}
+
+// await 等待请求的首个回报，超时或上下文取消时返回false（内部方法）
+func (b *Broker) await(ctx context.Context, clOrdID string, wait chan pendingResult) (pendingResult, bool) {
+	timer := time.NewTimer(b.cfg.SubmitTimeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-wait:
+		return result, true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	b.mu.Lock()
+	delete(b.pending, clOrdID)
+	b.mu.Unlock()
+	return pendingResult{}, false
+}
+
+// resolve 将回报交给等待中的请求（调用方需持有锁）
+func (b *Broker) resolve(clOrdID string, result pendingResult) {
+	if wait, ok := b.pending[clOrdID]; ok {
+		delete(b.pending, clOrdID)
+		wait <- result
+	}
+}
+
+// OnCreate 实现 quickfix.Application 接口
+func (b *Broker) OnCreate(sessionID quickfix.SessionID) {}
+
+// OnLogon 会话登录后对未完成订单发送状态查询
+func (b *Broker) OnLogon(sessionID quickfix.SessionID) {
+	b.mu.Lock()
+	b.loggedOn = true
+	b.lastLogout = ""
+	var open []trading.Order
+	for _, order := range b.orders {
+		if isOpen(order.Status) {
+			open = append(open, order)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, order := range open {
+		quickfix.SendToTarget(orderStatusRequest(b.cfg, order), sessionID)
+	}
+}
+
+// OnLogout 会话注销或断开时，等待中的请求立即失败
+func (b *Broker) OnLogout(sessionID quickfix.SessionID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.loggedOn = false
+	for clOrdID := range b.pending {
+		b.resolve(clOrdID, pendingResult{err: ErrNotLoggedOn})
+	}
+}
+
+// ToAdmin 实现 quickfix.Application 接口
+func (b *Broker) ToAdmin(message *quickfix.Message, sessionID quickfix.SessionID) {}
+
+// ToApp 实现 quickfix.Application 接口
+func (b *Broker) ToApp(message *quickfix.Message, sessionID quickfix.SessionID) error {
+	return nil
+}
+
+// FromAdmin 记录券商发送的注销原因，如序号不一致
+func (b *Broker) FromAdmin(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	if message.IsMsgTypeOf("5") {
+		text, _ := getString(message.Body.FieldMap, tagText, false)
+		b.mu.Lock()
+		b.lastLogout = text
+		b.mu.Unlock()
+	}
+	return nil
+}
+
+// FromApp 处理执行回报、撤单拒绝和业务拒绝
+func (b *Broker) FromApp(message *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	msgType, rejectErr := message.MsgType()
+	if rejectErr != nil {
+		return rejectErr
+	}
+
+	switch msgType {
+	case msgTypeExecutionReport:
+		report, err := parseExecutionReport(message)
+		if err != nil {
+			return quickfix.NewMessageRejectError(err.Error(), 5, nil)
+		}
+		b.handleExecutionReport(report)
+	case msgTypeOrderCancelReject:
+		clOrdID, _ := getString(message.Body.FieldMap, tagClOrdID, false)
+		reason, _ := getString(message.Body.FieldMap, tagText, false)
+		b.mu.Lock()
+		delete(b.aliases, clOrdID)
+		b.resolve(clOrdID, pendingResult{err: fmt.Errorf("cancel rejected: %s", reason)})
+		b.mu.Unlock()
+	case msgTypeBusinessReject, msgTypeReject:
+		// 业务拒绝的引用ID通常为ClOrdID
+		refID, _ := getString(message.Body.FieldMap, tagBusinessRefID, false)
+		reason, _ := getString(message.Body.FieldMap, tagText, false)
+		b.mu.Lock()
+		if refID != "" {
+			b.resolve(refID, pendingResult{err: fmt.Errorf("message rejected: %s", reason)})
+		}
+		b.mu.Unlock()
+	}
+	return nil
+}
+
+// handleExecutionReport 将执行回报应用到订单，并推送新成交（内部方法）
+func (b *Broker) handleExecutionReport(report executionReport) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// 撤单相关回报使用撤单ClOrdID，需要映射回原订单
+	clOrdID := report.ClOrdID
+	orderID := clOrdID
+	if origID, ok := b.aliases[clOrdID]; ok {
+		orderID = origID
+	} else if report.OrigClOrdID != "" {
+		if _, ok := b.orders[report.OrigClOrdID]; ok {
+			orderID = report.OrigClOrdID
+		}
+	}
+
+	order, known := b.orders[orderID]
+	if !known {
+		// 未知订单（例如进程重启前提交的订单），仍推送成交供上层对账
+		order = trading.Order{ID: orderID, Symbol: report.Symbol, Side: report.Side}
+	}
+
+	duplicate := report.ExecID != "" && b.seenExecs[orderID][report.ExecID]
+	if !duplicate {
+		if report.ExecID != "" {
+			if b.seenExecs[orderID] == nil {
+				b.seenExecs[orderID] = make(map[string]bool)
+			}
+			b.seenExecs[orderID][report.ExecID] = true
+		}
+		report.apply(&order)
+		b.orders[orderID] = order
+
+		update := trading.OrderUpdate{Order: order}
+		if report.isFill() {
+			execution := report.execution(orderID)
+			update.Execution = &execution
+			if b.wantExecs {
+				b.executions.push(execution)
+			}
+		}
+		b.updates.push(update)
+	}
+
+	switch {
+	case clOrdID != orderID:
+		// 撤单请求在订单撤销、撤单被拒或订单已终结时完成，挂起撤单状态继续等待
+		switch {
+		case order.Status == trading.OrderStatusCanceled:
+			delete(b.aliases, clOrdID)
+			b.resolve(clOrdID, pendingResult{order: order})
+		case report.OrdStatus == ordStatusRejected || !isOpen(order.Status):
+			delete(b.aliases, clOrdID)
+			b.resolve(clOrdID, pendingResult{err: fmt.Errorf("cancel rejected: order is %s %s", order.Status, report.Text)})
+		}
+	case order.Status == trading.OrderStatusRejected:
+		b.resolve(orderID, pendingResult{err: fmt.Errorf("order rejected: %s", report.Text)})
+	default:
+		b.resolve(orderID, pendingResult{order: order})
+	}
+
+	// 订单终结后不再有新的回报，释放订单和已处理的ExecID
+	// 断线期间遗漏的回报由quickfix按序号补发，不会在订单终结后重复到达
+	b.forget(orderID)
+}
+
+// forget 在订单终结且没有等待中的撤单时删除订单和已处理的ExecID（内部方法，调用方需持有锁）
+func (b *Broker) forget(orderID string) {
+	order, ok := b.orders[orderID]
+	if !ok || isOpen(order.Status) {
+		return
+	}
+	for _, origID := range b.aliases {
+		if origID == orderID {
+			return
+		}
+	}
+	delete(b.orders, orderID)
+	delete(b.seenExecs, orderID)
+}
+
+// isOpen 判断订单是否仍可能收到回报（内部方法）
+func isOpen(status trading.OrderStatus) bool {
+	switch status {
+	case trading.OrderStatusFilled, trading.OrderStatusCanceled, trading.OrderStatusRejected, trading.OrderStatusExpired:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package fix
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// newTestBroker 创建不连接会话的券商，用于测试回报处理
+func newTestBroker() *Broker {
+	return &Broker{
+		cfg:        Config{BeginString: quickfix.BeginStringFIX44, SenderCompID: "QHFT", TargetCompID: "BROKER"},
+		orders:     make(map[string]trading.Order),
+		aliases:    make(map[string]string),
+		pending:    make(map[string]chan pendingResult),
+		seenExecs:  make(map[string]map[string]bool),
+		executions: newOutbox[trading.Execution]("成交回报", 10),
+		updates:    newOutbox[trading.OrderUpdate]("订单状态", 10),
+		wantExecs:  true,
+	}
+}
+
+// executionReportMessage 构造执行回报消息
+func executionReportMessage(cfg Config, fields map[quickfix.Tag]string) *quickfix.Message {
+	msg := newMessage(cfg, msgTypeExecutionReport)
+	for tag, value := range fields {
+		msg.Body.SetString(tag, value)
+	}
+	return msg
+}
+
+func TestExecutionReportMapping(t *testing.T) {
+	b := newTestBroker()
+	order := trading.Order{ID: "order-1", Symbol: "AAPL", Quantity: 100, Side: trading.OrderSideBuy, Type: trading.OrderTypeLimit, Price: 150}
+	b.orders[order.ID] = order
+	wait := make(chan pendingResult, 1)
+	b.pending[order.ID] = wait
+
+	feed := func(fields map[quickfix.Tag]string) {
+		t.Helper()
+		report, err := parseExecutionReport(executionReportMessage(b.cfg, fields))
+		if err != nil {
+			t.Fatalf("解析回报失败: %v", err)
+		}
+		b.handleExecutionReport(report)
+	}
+
+	// 确认
+	feed(map[quickfix.Tag]string{tagClOrdID: "order-1", tagOrderID: "B1", tagExecID: "E1", tagExecType: "0", tagOrdStatus: ordStatusNew, tagSymbol: "AAPL", tagSide: "1"})
+	result := <-wait
+	if result.err != nil || result.order.Status != trading.OrderStatusAccepted || result.order.BrokerOrderID != "B1" {
+		t.Fatalf("确认回报映射不正确: %+v", result)
+	}
+
+	// 部分成交，以及一条重发的重复回报
+	partial := map[quickfix.Tag]string{tagClOrdID: "order-1", tagOrderID: "B1", tagExecID: "E2", tagExecType: execTypeTrade, tagOrdStatus: ordStatusPartiallyFilled,
+		tagSymbol: "AAPL", tagSide: "1", tagLastShares: "40", tagLastPx: "149.5", tagCumQty: "40", tagAvgPx: "149.5", tagCommission: "0.4"}
+	feed(partial)
+	feed(partial)
+
+	// FIX 4.2 风格的全部成交，ExecType=2
+	feed(map[quickfix.Tag]string{tagClOrdID: "order-1", tagOrderID: "B1", tagExecID: "E3", tagExecType: execTypeFillFIX42, tagOrdStatus: ordStatusFilled,
+		tagSymbol: "AAPL", tagSide: "1", tagLastShares: "60", tagLastPx: "150", tagCumQty: "100", tagAvgPx: "149.8", tagCommission: "0.6"})
+
+	if got := len(b.executions.ch); got != 2 {
+		t.Fatalf("成交数量 = %d, 期望 2（重复回报应被过滤）", got)
+	}
+	if got := len(b.updates.ch); got != 3 {
+		t.Fatalf("订单状态变化数量 = %d, 期望 3", got)
+	}
+	first := <-b.executions.ch
+	if first.OrderID != "order-1" || first.Quantity != 40 || first.Price != 149.5 || first.BrokerExecID != "E2" {
+		t.Errorf("成交记录不正确: %+v", first)
+	}
+
+	var filled trading.Order
+	for len(b.updates.ch) > 0 {
+		filled = (<-b.updates.ch).Order
+	}
+	if filled.Status != trading.OrderStatusFilled || filled.FilledQty != 100 || filled.AvgFillPrice != 149.8 || filled.FilledAt == nil {
+		t.Errorf("订单状态不正确: %+v", filled)
+	}
+	if filled.Commission != 1.0 {
+		t.Errorf("佣金 = %v, 期望 1.0", filled.Commission)
+	}
+
+	// 订单终结后释放订单和已处理的ExecID
+	if _, ok := b.orders["order-1"]; ok || len(b.seenExecs) != 0 {
+		t.Errorf("全部成交后应释放订单: %+v %v", b.orders, b.seenExecs)
+	}
+}
+
+func TestExecutionReportBacklog(t *testing.T) {
+	b := newTestBroker()
+	b.executions = newOutbox[trading.Execution]("成交回报", 1)
+	b.updates = newOutbox[trading.OrderUpdate]("订单状态", 1)
+	b.orders["order-3"] = trading.Order{ID: "order-3", Symbol: "AAPL", Quantity: 5, Side: trading.OrderSideBuy, Status: trading.OrderStatusAccepted}
+
+	// 消费者未及时读取时回报排队，不丢弃任何成交
+	for i := 1; i <= 5; i++ {
+		status := ordStatusPartiallyFilled
+		if i == 5 {
+			status = ordStatusFilled
+		}
+		report, err := parseExecutionReport(executionReportMessage(b.cfg, map[quickfix.Tag]string{tagClOrdID: "order-3", tagOrderID: "B3", tagExecID: fmt.Sprintf("E%d", i),
+			tagExecType: execTypeTrade, tagOrdStatus: status, tagSymbol: "AAPL", tagSide: "1", tagLastShares: "1", tagLastPx: "100", tagCumQty: strconv.Itoa(i), tagAvgPx: "100"}))
+		if err != nil {
+			t.Fatalf("解析回报失败: %v", err)
+		}
+		b.handleExecutionReport(report)
+	}
+	if got := b.updates.backlog(); got != 4 {
+		t.Errorf("排队的订单状态变化 = %d, 期望 4", got)
+	}
+
+	for i := 1; i <= 5; i++ {
+		select {
+		case update := <-b.updates.ch:
+			if update.Order.FilledQty != int64(i) || update.Execution == nil || update.Execution.BrokerExecID != fmt.Sprintf("E%d", i) {
+				t.Errorf("第%d条订单状态变化不正确: %+v", i, update)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("第%d条订单状态变化未送达", i)
+		}
+		select {
+		case execution := <-b.executions.ch:
+			if execution.BrokerExecID != fmt.Sprintf("E%d", i) {
+				t.Errorf("第%d条成交不正确: %+v", i, execution)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("第%d条成交未送达", i)
+		}
+	}
+}
+
+func TestCancelReportMapping(t *testing.T) {
+	b := newTestBroker()
+	b.orders["order-2"] = trading.Order{ID: "order-2", Symbol: "MSFT", Quantity: 10, Side: trading.OrderSideSell, Status: trading.OrderStatusAccepted}
+	b.aliases["order-2-cxl-1"] = "order-2"
+	wait := make(chan pendingResult, 1)
+	b.pending["order-2-cxl-1"] = wait
+
+	feed := func(fields map[quickfix.Tag]string) {
+		t.Helper()
+		report, err := parseExecutionReport(executionReportMessage(b.cfg, fields))
+		if err != nil {
+			t.Fatalf("解析回报失败: %v", err)
+		}
+		b.handleExecutionReport(report)
+	}
+
+	// 挂起撤单时继续等待
+	feed(map[quickfix.Tag]string{tagClOrdID: "order-2-cxl-1", tagOrigClOrdID: "order-2", tagExecID: "E10", tagExecType: "6", tagOrdStatus: ordStatusPendingCancel})
+	select {
+	case result := <-wait:
+		t.Fatalf("挂起撤单不应完成撤单请求: %+v", result)
+	default:
+	}
+
+	feed(map[quickfix.Tag]string{tagClOrdID: "order-2-cxl-1", tagOrigClOrdID: "order-2", tagExecID: "E11", tagExecType: "4", tagOrdStatus: ordStatusCanceled})
+	result := <-wait
+	if result.err != nil || result.order.Status != trading.OrderStatusCanceled {
+		t.Fatalf("撤单回报映射不正确: %+v", result)
+	}
+	if _, ok := b.aliases["order-2-cxl-1"]; ok {
+		t.Error("撤单完成后应移除ClOrdID映射")
+	}
+}
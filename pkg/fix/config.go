@@ -0,0 +1,102 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// Config 表示FIX会话配置
+type Config struct {
+	BeginString      string        `json:"begin_string" yaml:"begin_string"` // FIX.4.2 或 FIX.4.4
+	SenderCompID     string        `json:"sender_comp_id" yaml:"sender_comp_id"`
+	TargetCompID     string        `json:"target_comp_id" yaml:"target_comp_id"`
+	Host             string        `json:"host" yaml:"host"`
+	Port             int           `json:"port" yaml:"port"`
+	Account          string        `json:"account" yaml:"account"` // 订单中携带的账户(Tag 1)，为空时不发送
+	HeartbeatSeconds int           `json:"heartbeat_seconds" yaml:"heartbeat_seconds"`
+	ReconnectSeconds int           `json:"reconnect_seconds" yaml:"reconnect_seconds"`
+	StorePath        string        `json:"store_path" yaml:"store_path"` // 序号和已发消息的存储目录，为空时存于内存，重启后序号从1开始
+	LogPath          string        `json:"log_path" yaml:"log_path"`     // FIX消息日志目录，为空时不记录
+	ResetOnLogon     bool          `json:"reset_on_logon" yaml:"reset_on_logon"`
+	UseTLS           bool          `json:"use_tls" yaml:"use_tls"`
+	SubmitTimeout    time.Duration `json:"submit_timeout" yaml:"submit_timeout"` // 等待券商首个回报的时间
+}
+
+// Validate 校验FIX会话配置
+func (c Config) Validate() error {
+	var errs []error
+	if c.BeginString != quickfix.BeginStringFIX42 && c.BeginString != quickfix.BeginStringFIX44 {
+		errs = append(errs, fmt.Errorf("begin_string %q is not supported, use FIX.4.2 or FIX.4.4", c.BeginString))
+	}
+	if c.SenderCompID == "" || c.TargetCompID == "" {
+		errs = append(errs, errors.New("sender_comp_id and target_comp_id are required"))
+	}
+	if c.Host == "" || c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, errors.New("host and a valid port are required"))
+	}
+	if c.HeartbeatSeconds < 0 || c.ReconnectSeconds < 0 || c.SubmitTimeout < 0 {
+		errs = append(errs, errors.New("intervals and timeouts must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// sessionID 返回配置对应的会话标识（内部方法）
+func (c Config) sessionID() quickfix.SessionID {
+	return quickfix.SessionID{
+		BeginString:  c.BeginString,
+		SenderCompID: c.SenderCompID,
+		TargetCompID: c.TargetCompID,
+	}
+}
+
+// settings 将配置转换为quickfix会话设置（内部方法）
+func (c Config) settings() (*quickfix.Settings, error) {
+	heartbeat := c.HeartbeatSeconds
+	if heartbeat == 0 {
+		heartbeat = 30
+	}
+	reconnect := c.ReconnectSeconds
+	if reconnect == 0 {
+		reconnect = 5
+	}
+
+	settings := quickfix.NewSettings()
+	global := settings.GlobalSettings()
+	if c.StorePath != "" {
+		global.Set(config.FileStorePath, c.StorePath)
+		global.Set(config.FileStoreSync, "Y")
+	}
+	if c.LogPath != "" {
+		global.Set(config.FileLogPath, c.LogPath)
+	}
+
+	session := quickfix.NewSessionSettings()
+	session.Set(config.BeginString, c.BeginString)
+	session.Set(config.SenderCompID, c.SenderCompID)
+	session.Set(config.TargetCompID, c.TargetCompID)
+	session.Set(config.SocketConnectHost, c.Host)
+	session.Set(config.SocketConnectPort, strconv.Itoa(c.Port))
+	session.Set(config.HeartBtInt, strconv.Itoa(heartbeat))
+	session.Set(config.ReconnectInterval, strconv.Itoa(reconnect))
+	session.Set(config.ResetOnLogon, yesNo(c.ResetOnLogon))
+	session.Set(config.SocketUseSSL, yesNo(c.UseTLS))
+	// 不设置StartTime/EndTime，会话全天有效，交易时段由券商控制
+
+	if _, err := settings.AddSession(session); err != nil {
+		return nil, fmt.Errorf("invalid fix session settings: %v", err)
+	}
+	return settings, nil
+}
+
+// yesNo 将布尔值转换为quickfix设置使用的Y/N（内部方法）
+func yesNo(v bool) string {
+	if v {
+		return "Y"
+	}
+	return "N"
+}
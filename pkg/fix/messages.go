@@ -0,0 +1,365 @@
+package fix
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// 使用到的FIX标签
+const (
+	tagAccount       quickfix.Tag = 1
+	tagAvgPx         quickfix.Tag = 6
+	tagClOrdID       quickfix.Tag = 11
+	tagCommission    quickfix.Tag = 12
+	tagCumQty        quickfix.Tag = 14
+	tagExecID        quickfix.Tag = 17
+	tagHandlInst     quickfix.Tag = 21
+	tagLastPx        quickfix.Tag = 31
+	tagLastShares    quickfix.Tag = 32
+	tagMsgType       quickfix.Tag = 35
+	tagOrderID       quickfix.Tag = 37
+	tagOrderQty      quickfix.Tag = 38
+	tagOrdStatus     quickfix.Tag = 39
+	tagOrdType       quickfix.Tag = 40
+	tagOrigClOrdID   quickfix.Tag = 41
+	tagPrice         quickfix.Tag = 44
+	tagSide          quickfix.Tag = 54
+	tagSymbol        quickfix.Tag = 55
+	tagText          quickfix.Tag = 58
+	tagTransactTime  quickfix.Tag = 60
+	tagStopPx        quickfix.Tag = 99
+	tagExecType      quickfix.Tag = 150
+	tagBusinessRefID quickfix.Tag = 379
+)
+
+// 使用到的FIX消息类型
+const (
+	msgTypeReject              = "3"
+	msgTypeExecutionReport     = "8"
+	msgTypeOrderCancelReject   = "9"
+	msgTypeNewOrderSingle      = "D"
+	msgTypeOrderCancelRequest  = "F"
+	msgTypeOrderStatusRequest  = "H"
+	msgTypeBusinessReject      = "j"
+	execTypeTrade              = "F" // FIX 4.4 成交
+	execTypePartialFillFIX42   = "1"
+	execTypeFillFIX42          = "2"
+	handlInstAutomated         = "1"
+	ordStatusNew               = "0"
+	ordStatusPartiallyFilled   = "1"
+	ordStatusFilled            = "2"
+	ordStatusCanceled          = "4"
+	ordStatusPendingCancel     = "6"
+	ordStatusRejected          = "8"
+	ordStatusPendingNew        = "A"
+	ordStatusExpired           = "C"
+	ordStatusPendingReplace    = "E"
+	defaultExecutionBufferSize = 256
+)
+
+// executionReport 表示解析后的执行回报（内部类型）
+type executionReport struct {
+	ClOrdID     string
+	OrigClOrdID string
+	OrderID     string
+	ExecID      string
+	ExecType    string
+	OrdStatus   string
+	Symbol      string
+	Side        trading.OrderSide
+	LastQty     int64
+	LastPx      float64
+	CumQty      int64
+	AvgPx       float64
+	Commission  float64
+	Text        string
+	TransactAt  time.Time
+}
+
+// isFill 判断回报是否包含一笔新成交（内部方法）
+func (r executionReport) isFill() bool {
+	if r.LastQty <= 0 {
+		return false
+	}
+	switch r.ExecType {
+	case execTypeTrade, execTypePartialFillFIX42, execTypeFillFIX42:
+		return true
+	}
+	return false
+}
+
+// orderStatus 将FIX订单状态(Tag 39)映射为系统订单状态（内部方法）
+func (r executionReport) orderStatus() (trading.OrderStatus, bool) {
+	switch r.OrdStatus {
+	case ordStatusPendingNew:
+		return trading.OrderStatusSubmitted, true
+	case ordStatusNew, ordStatusPendingCancel, ordStatusPendingReplace:
+		return trading.OrderStatusAccepted, true
+	case ordStatusPartiallyFilled:
+		return trading.OrderStatusPartial, true
+	case ordStatusFilled:
+		return trading.OrderStatusFilled, true
+	case ordStatusCanceled:
+		return trading.OrderStatusCanceled, true
+	case ordStatusRejected:
+		return trading.OrderStatusRejected, true
+	case ordStatusExpired:
+		return trading.OrderStatusExpired, true
+	}
+	return "", false
+}
+
+// apply 将回报应用到订单上（内部方法）
+func (r executionReport) apply(order *trading.Order) {
+	if status, ok := r.orderStatus(); ok {
+		// 部分成交后的挂起撤单仍视为部分成交
+		if !(status == trading.OrderStatusAccepted && order.FilledQty > 0) {
+			order.Status = status
+		}
+	}
+	if r.OrderID != "" && r.OrderID != "NONE" {
+		order.BrokerOrderID = r.OrderID
+	}
+	if r.CumQty > 0 {
+		order.FilledQty = r.CumQty
+		order.AvgFillPrice = r.AvgPx
+	}
+	if r.isFill() {
+		order.Commission += r.Commission
+	}
+	if order.Status == trading.OrderStatusRejected {
+		order.RejectReason = r.Text
+	}
+	if order.Status == trading.OrderStatusFilled && order.FilledAt == nil {
+		filledAt := r.TransactAt
+		order.FilledAt = &filledAt
+	}
+	order.UpdatedAt = r.TransactAt
+}
+
+// execution 将成交回报转换为成交记录（内部方法）
+func (r executionReport) execution(orderID string) trading.Execution {
+	return trading.Execution{
+		ID:           fmt.Sprintf("fix-%s", r.ExecID),
+		OrderID:      orderID,
+		Symbol:       r.Symbol,
+		Quantity:     r.LastQty,
+		Price:        r.LastPx,
+		Side:         r.Side,
+		ExecutedAt:   r.TransactAt,
+		Commission:   r.Commission,
+		BrokerExecID: r.ExecID,
+	}
+}
+
+// parseExecutionReport 解析执行回报(35=8)，FIX 4.2和4.4的字段差异在这里统一（内部方法）
+func parseExecutionReport(msg *quickfix.Message) (executionReport, error) {
+	var r executionReport
+	var err error
+
+	if r.ClOrdID, err = getString(msg.Body.FieldMap, tagClOrdID, true); err != nil {
+		return r, err
+	}
+	if r.OrdStatus, err = getString(msg.Body.FieldMap, tagOrdStatus, true); err != nil {
+		return r, err
+	}
+	r.ExecType, _ = getString(msg.Body.FieldMap, tagExecType, false)
+	r.OrigClOrdID, _ = getString(msg.Body.FieldMap, tagOrigClOrdID, false)
+	r.OrderID, _ = getString(msg.Body.FieldMap, tagOrderID, false)
+	r.ExecID, _ = getString(msg.Body.FieldMap, tagExecID, false)
+	r.Symbol, _ = getString(msg.Body.FieldMap, tagSymbol, false)
+	r.Text, _ = getString(msg.Body.FieldMap, tagText, false)
+
+	side, _ := getString(msg.Body.FieldMap, tagSide, false)
+	r.Side = fromFIXSide(side)
+
+	if r.LastQty, err = getQty(msg.Body.FieldMap, tagLastShares); err != nil {
+		return r, err
+	}
+	if r.CumQty, err = getQty(msg.Body.FieldMap, tagCumQty); err != nil {
+		return r, err
+	}
+	if r.LastPx, err = getFloat(msg.Body.FieldMap, tagLastPx); err != nil {
+		return r, err
+	}
+	if r.AvgPx, err = getFloat(msg.Body.FieldMap, tagAvgPx); err != nil {
+		return r, err
+	}
+	if r.Commission, err = getFloat(msg.Body.FieldMap, tagCommission); err != nil {
+		return r, err
+	}
+
+	r.TransactAt = time.Now()
+	if msg.Body.Has(tagTransactTime) {
+		if t, ferr := msg.Body.GetTime(tagTransactTime); ferr == nil {
+			r.TransactAt = t
+		}
+	}
+
+	// FIX 4.2 没有唯一成交类型，ExecType为空时按OrdStatus推断
+	if r.ExecType == "" {
+		r.ExecType = r.OrdStatus
+	}
+	return r, nil
+}
+
+// newOrderSingle 构造新订单消息(35=D)（内部方法）
+func newOrderSingle(cfg Config, order trading.Order) (*quickfix.Message, error) {
+	ordType, err := toFIXOrdType(order.Type)
+	if err != nil {
+		return nil, err
+	}
+	side, err := toFIXSide(order.Side)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := newMessage(cfg, msgTypeNewOrderSingle)
+	msg.Body.SetString(tagClOrdID, order.ID)
+	msg.Body.SetString(tagHandlInst, handlInstAutomated)
+	msg.Body.SetString(tagSymbol, order.Symbol)
+	msg.Body.SetString(tagSide, side)
+	msg.Body.SetField(tagTransactTime, quickfix.FIXUTCTimestamp{Time: time.Now().UTC()})
+	msg.Body.SetString(tagOrderQty, strconv.FormatInt(order.Quantity, 10))
+	msg.Body.SetString(tagOrdType, ordType)
+	if cfg.Account != "" {
+		msg.Body.SetString(tagAccount, cfg.Account)
+	}
+	if order.Type == trading.OrderTypeLimit {
+		msg.Body.SetString(tagPrice, formatPrice(order.Price))
+	}
+	if order.Type == trading.OrderTypeStop {
+		stop := order.StopPrice
+		if stop == 0 {
+			stop = order.Price
+		}
+		msg.Body.SetString(tagStopPx, formatPrice(stop))
+	}
+	return msg, nil
+}
+
+// orderCancelRequest 构造撤单请求(35=F)（内部方法）
+func orderCancelRequest(cfg Config, order trading.Order, clOrdID string) (*quickfix.Message, error) {
+	side, err := toFIXSide(order.Side)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := newMessage(cfg, msgTypeOrderCancelRequest)
+	msg.Body.SetString(tagOrigClOrdID, order.ID)
+	msg.Body.SetString(tagClOrdID, clOrdID)
+	msg.Body.SetString(tagSymbol, order.Symbol)
+	msg.Body.SetString(tagSide, side)
+	msg.Body.SetField(tagTransactTime, quickfix.FIXUTCTimestamp{Time: time.Now().UTC()})
+	msg.Body.SetString(tagOrderQty, strconv.FormatInt(order.Quantity, 10))
+	if order.BrokerOrderID != "" {
+		msg.Body.SetString(tagOrderID, order.BrokerOrderID)
+	}
+	if cfg.Account != "" {
+		msg.Body.SetString(tagAccount, cfg.Account)
+	}
+	return msg, nil
+}
+
+// orderStatusRequest 构造订单状态查询(35=H)，重新登录后用于补齐断线期间的回报（内部方法）
+func orderStatusRequest(cfg Config, order trading.Order) *quickfix.Message {
+	side, _ := toFIXSide(order.Side)
+
+	msg := newMessage(cfg, msgTypeOrderStatusRequest)
+	msg.Body.SetString(tagClOrdID, order.ID)
+	msg.Body.SetString(tagSymbol, order.Symbol)
+	msg.Body.SetString(tagSide, side)
+	if order.BrokerOrderID != "" {
+		msg.Body.SetString(tagOrderID, order.BrokerOrderID)
+	}
+	return msg
+}
+
+// newMessage 创建带有会话头的消息（内部方法）
+func newMessage(cfg Config, msgType string) *quickfix.Message {
+	msg := quickfix.NewMessage()
+	msg.Header.SetString(quickfix.Tag(8), cfg.BeginString)
+	msg.Header.SetString(tagMsgType, msgType)
+	msg.Header.SetString(quickfix.Tag(49), cfg.SenderCompID)
+	msg.Header.SetString(quickfix.Tag(56), cfg.TargetCompID)
+	return msg
+}
+
+// toFIXSide 将订单方向转换为FIX Side(Tag 54)（内部方法）
+func toFIXSide(side trading.OrderSide) (string, error) {
+	switch side {
+	case trading.OrderSideBuy:
+		return "1", nil
+	case trading.OrderSideSell:
+		return "2", nil
+	}
+	return "", fmt.Errorf("unsupported order side %q", side)
+}
+
+// fromFIXSide 将FIX Side转换为订单方向，卖空(5/6)视为卖出（内部方法）
+func fromFIXSide(side string) trading.OrderSide {
+	switch side {
+	case "1":
+		return trading.OrderSideBuy
+	case "2", "5", "6":
+		return trading.OrderSideSell
+	}
+	return ""
+}
+
+// toFIXOrdType 将订单类型转换为FIX OrdType(Tag 40)（内部方法）
+func toFIXOrdType(orderType trading.OrderType) (string, error) {
+	switch orderType {
+	case trading.OrderTypeMarket:
+		return "1", nil
+	case trading.OrderTypeLimit:
+		return "2", nil
+	case trading.OrderTypeStop:
+		return "3", nil
+	}
+	return "", fmt.Errorf("unsupported order type %q", orderType)
+}
+
+// formatPrice 格式化价格字段（内部方法）
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+// getString 读取字符串字段（内部方法）
+func getString(fields quickfix.FieldMap, tag quickfix.Tag, required bool) (string, error) {
+	if !fields.Has(tag) {
+		if required {
+			return "", fmt.Errorf("missing required fix tag %d", tag)
+		}
+		return "", nil
+	}
+	value, err := fields.GetString(tag)
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// getFloat 读取数值字段，字段不存在时返回0（内部方法）
+func getFloat(fields quickfix.FieldMap, tag quickfix.Tag) (float64, error) {
+	value, err := getString(fields, tag, false)
+	if err != nil || value == "" {
+		return 0, err
+	}
+	f, perr := strconv.ParseFloat(value, 64)
+	if perr != nil {
+		return 0, fmt.Errorf("invalid fix tag %d value %q", tag, value)
+	}
+	return f, nil
+}
+
+// getQty 读取数量字段，FIX 4.4的数量可能带小数（内部方法）
+func getQty(fields quickfix.FieldMap, tag quickfix.Tag) (int64, error) {
+	f, err := getFloat(fields, tag)
+	return int64(f), err
+}
@@ -0,0 +1,70 @@
+package fix
+
+import (
+	"sync"
+
+	"github.com/yourusername/qhft-system/pkg/logger"
+)
+
+// outboxWarnInterval 是积压的回报每增加多少条记录一次警告（内部常量）
+const outboxWarnInterval = 1000
+
+// outbox 按顺序向通道推送回报，通道满时回报在内存中排队，由后台协程在消费者读取后继续推送，不会丢弃（内部类型）
+type outbox[T any] struct {
+	name     string
+	ch       chan T
+	mu       sync.Mutex
+	queue    []T
+	draining bool
+}
+
+// newOutbox 创建推送到缓冲区大小为size的通道的队列（内部函数）
+func newOutbox[T any](name string, size int) *outbox[T] {
+	return &outbox[T]{name: name, ch: make(chan T, size)}
+}
+
+// push 推送一条回报，不会阻塞；通道满时排队并记录警告（内部方法）
+func (o *outbox[T]) push(v T) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.queue) == 0 {
+		select {
+		case o.ch <- v:
+			return
+		default:
+		}
+	}
+	o.queue = append(o.queue, v)
+	if n := len(o.queue); n == 1 || n%outboxWarnInterval == 0 {
+		logger.Warn("FIX%s通道已满，%d条回报排队等待消费者读取", o.name, n)
+	}
+	if !o.draining {
+		o.draining = true
+		go o.drain()
+	}
+}
+
+// drain 依次将排队的回报写入通道，队列清空后退出（内部方法）
+// 回报在写入通道后才移出队列，期间新的回报继续排在队尾，保证顺序
+func (o *outbox[T]) drain() {
+	o.mu.Lock()
+	for len(o.queue) > 0 {
+		v := o.queue[0]
+		o.mu.Unlock()
+		o.ch <- v
+		o.mu.Lock()
+		var zero T
+		o.queue[0] = zero
+		o.queue = o.queue[1:]
+	}
+	o.draining = false
+	o.mu.Unlock()
+}
+
+// backlog 返回排队中的回报数量（内部方法）
+func (o *outbox[T]) backlog() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.queue)
+}
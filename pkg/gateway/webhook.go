@@ -0,0 +1,396 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// Webhook处理模式常量
+const (
+	WebhookModeWatchlist = "watchlist" // 告警转换为监控项
+	WebhookModeOrder     = "order"     // 告警直接下单
+)
+
+// EventWebhookAlert 表示收到并通过校验的外部告警
+const EventWebhookAlert = "webhook_alert"
+
+// DefaultSignatureHeader 是HMAC签名默认使用的请求头
+const DefaultSignatureHeader = "X-Signature"
+
+// WebhookSource 表示一个外部信号来源的配置
+type WebhookSource struct {
+	Name            string            `json:"name" yaml:"name"`
+	Mode            string            `json:"mode" yaml:"mode"`                         // watchlist 或 order
+	Secret          string            `json:"secret" yaml:"secret"`                     // HMAC-SHA256密钥，签名为请求体的十六进制摘要
+	SignatureHeader string            `json:"signature_header" yaml:"signature_header"` // 为空时使用 X-Signature
+	Passphrase      string            `json:"passphrase" yaml:"passphrase"`             // 无法签名的来源（如TradingView）在请求体中携带的口令
+	RatePerMinute   int               `json:"rate_per_minute" yaml:"rate_per_minute"`   // 为0时不限速
+	Burst           int               `json:"burst" yaml:"burst"`
+	SymbolMap       map[string]string `json:"symbol_map" yaml:"symbol_map"` // 外部代码到内部代码的映射，如 NASDAQ:AAPL -> AAPL
+	DefaultQuantity int64             `json:"default_quantity" yaml:"default_quantity"`
+	Expiry          time.Duration     `json:"expiry" yaml:"expiry"` // 监控项有效期，为0时不过期
+}
+
+// Validate 校验来源配置
+func (s WebhookSource) Validate() error {
+	if s.Name == "" {
+		return errors.New("webhook source name is required")
+	}
+	if s.Mode != WebhookModeWatchlist && s.Mode != WebhookModeOrder {
+		return fmt.Errorf("webhook source '%s' has invalid mode %q", s.Name, s.Mode)
+	}
+	if s.Secret == "" && s.Passphrase == "" {
+		return fmt.Errorf("webhook source '%s' requires a secret or passphrase", s.Name)
+	}
+	if s.RatePerMinute < 0 || s.Burst < 0 {
+		return fmt.Errorf("webhook source '%s' has negative rate limit", s.Name)
+	}
+	if s.DefaultQuantity < 0 {
+		return fmt.Errorf("webhook source '%s' has negative default quantity", s.Name)
+	}
+	return nil
+}
+
+// WebhookAlert 表示TradingView风格的告警内容
+// TradingView中可使用 {"ticker":"{{ticker}}","action":"{{strategy.order.action}}","quantity":{{strategy.order.contracts}},"price":{{close}}} 等模板
+type WebhookAlert struct {
+	Ticker     string  `json:"ticker"`
+	Exchange   string  `json:"exchange,omitempty"`
+	Action     string  `json:"action"` // buy 或 sell
+	Quantity   float64 `json:"quantity,omitempty"`
+	Price      float64 `json:"price,omitempty"`
+	OrderType  string  `json:"order_type,omitempty"` // market 或 limit，为空时市价
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	TakeProfit float64 `json:"take_profit,omitempty"`
+	Strategy   string  `json:"strategy,omitempty"`
+	Comment    string  `json:"comment,omitempty"`
+	Passphrase string  `json:"passphrase,omitempty"`
+
+	// Metadata 原样附加到订单或监控项，例如告警来源系统的关联ID
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// WebhookResult 表示Webhook的处理结果
+type WebhookResult struct {
+	Source  string `json:"source"`
+	Symbol  string `json:"symbol,omitempty"`
+	ItemID  string `json:"item_id,omitempty"`
+	OrderID string `json:"order_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// WebhookGateway 接收外部告警，校验签名和限速后转换为监控项或订单
+// 告警通过 POST <prefix>/<source> 提交，例如 /webhooks/tradingview
+type WebhookGateway struct {
+	engine       trading.TradingEngine
+	watchlist    *trading.Watchlist
+	bus          *events.Bus
+	maxBodyBytes int64
+
+	mu       sync.Mutex
+	sources  map[string]WebhookSource
+	limiters map[string]*tokenBucket
+	now      func() time.Time
+}
+
+// NewWebhookGateway 创建一个新的Webhook网关，watchlist为空时只支持order模式的来源
+func NewWebhookGateway(engine trading.TradingEngine, watchlist *trading.Watchlist, bus *events.Bus) *WebhookGateway {
+	return &WebhookGateway{
+		engine:       engine,
+		watchlist:    watchlist,
+		bus:          bus,
+		maxBodyBytes: 64 << 10,
+		sources:      make(map[string]WebhookSource),
+		limiters:     make(map[string]*tokenBucket),
+		now:          time.Now,
+	}
+}
+
+// AddSource 添加信号来源
+func (g *WebhookGateway) AddSource(source WebhookSource) error {
+	if err := source.Validate(); err != nil {
+		return err
+	}
+	if source.Mode == WebhookModeWatchlist && g.watchlist == nil {
+		return fmt.Errorf("webhook source '%s' requires a watchlist", source.Name)
+	}
+	if source.SignatureHeader == "" {
+		source.SignatureHeader = DefaultSignatureHeader
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.sources[source.Name]; exists {
+		return fmt.Errorf("webhook source '%s' already exists", source.Name)
+	}
+	g.sources[source.Name] = source
+	if source.RatePerMinute > 0 {
+		g.limiters[source.Name] = newTokenBucket(source.RatePerMinute, source.Burst, g.now())
+	}
+	return nil
+}
+
+// Handler 返回处理告警的HTTP处理器，prefix为挂载路径，如 /webhooks/
+func (g *WebhookGateway) Handler(prefix string) http.Handler {
+	return http.StripPrefix(prefix, http.HandlerFunc(g.serveHTTP))
+}
+
+// serveHTTP 处理单个告警请求（内部方法）
+func (g *WebhookGateway) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(r.URL.Path, "/")
+	result := WebhookResult{Source: name}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		g.writeResult(w, http.StatusMethodNotAllowed, result, errors.New("method not allowed"))
+		return
+	}
+
+	g.mu.Lock()
+	source, exists := g.sources[name]
+	limiter := g.limiters[name]
+	g.mu.Unlock()
+	if !exists {
+		g.writeResult(w, http.StatusNotFound, result, fmt.Errorf("unknown webhook source '%s'", name))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, g.maxBodyBytes))
+	if err != nil {
+		g.writeResult(w, http.StatusRequestEntityTooLarge, result, fmt.Errorf("failed to read body: %v", err))
+		return
+	}
+
+	if source.Secret != "" && !verifySignature(body, r.Header.Get(source.SignatureHeader), source.Secret) {
+		g.writeResult(w, http.StatusUnauthorized, result, errors.New("invalid signature"))
+		return
+	}
+
+	var alert WebhookAlert
+	if err := json.Unmarshal(body, &alert); err != nil {
+		g.writeResult(w, http.StatusBadRequest, result, fmt.Errorf("invalid alert payload: %v", err))
+		return
+	}
+
+	if source.Passphrase != "" && subtle.ConstantTimeCompare([]byte(alert.Passphrase), []byte(source.Passphrase)) != 1 {
+		g.writeResult(w, http.StatusUnauthorized, result, errors.New("invalid passphrase"))
+		return
+	}
+
+	// 通过认证后再计入限速，避免伪造请求耗尽配额
+	if limiter != nil {
+		g.mu.Lock()
+		allowed := limiter.allow(g.now())
+		g.mu.Unlock()
+		if !allowed {
+			g.writeResult(w, http.StatusTooManyRequests, result, errors.New("rate limit exceeded"))
+			return
+		}
+	}
+
+	req, err := source.orderRequest(alert)
+	if err != nil {
+		g.writeResult(w, http.StatusUnprocessableEntity, result, err)
+		return
+	}
+	result.Symbol = req.Symbol
+
+	switch source.Mode {
+	case WebhookModeWatchlist:
+		item := source.watchlistItem(alert, req, g.now())
+		if err := g.watchlist.AddItem(item); err != nil {
+			g.writeResult(w, http.StatusUnprocessableEntity, result, err)
+			return
+		}
+		result.ItemID = item.ID
+	case WebhookModeOrder:
+		order, err := g.engine.PlaceOrder(r.Context(), req)
+		if err != nil {
+			status := http.StatusUnprocessableEntity
+			if trading.GetRejectCode(err) == trading.RejectCodeInternal {
+				status = http.StatusInternalServerError
+			}
+			g.writeResult(w, status, result, err)
+			return
+		}
+		result.OrderID = order.ID
+	}
+
+	g.bus.Publish(events.Event{
+		Topic:     events.TopicSignals,
+		Type:      EventWebhookAlert,
+		Timestamp: g.now(),
+		Payload:   result,
+	})
+
+	g.writeResult(w, http.StatusOK, result, nil)
+}
+
+// writeResult 写入JSON格式的处理结果（内部方法）
+func (g *WebhookGateway) writeResult(w http.ResponseWriter, status int, result WebhookResult, err error) {
+	if err != nil {
+		result.Error = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// mapSymbol 将外部代码映射为内部代码，未配置映射时去掉交易所前缀（内部方法）
+func (s WebhookSource) mapSymbol(alert WebhookAlert) string {
+	ticker := strings.ToUpper(strings.TrimSpace(alert.Ticker))
+	if alert.Exchange != "" && !strings.Contains(ticker, ":") {
+		qualified := strings.ToUpper(alert.Exchange) + ":" + ticker
+		if symbol, ok := s.SymbolMap[qualified]; ok {
+			return symbol
+		}
+	}
+	if symbol, ok := s.SymbolMap[ticker]; ok {
+		return symbol
+	}
+	if i := strings.LastIndex(ticker, ":"); i >= 0 {
+		ticker = ticker[i+1:]
+	}
+	return ticker
+}
+
+// orderRequest 将告警转换为经过校验的下单请求（内部方法）
+func (s WebhookSource) orderRequest(alert WebhookAlert) (trading.OrderRequest, error) {
+	req := trading.OrderRequest{
+		Symbol:   s.mapSymbol(alert),
+		Price:    alert.Price,
+		Type:     trading.OrderTypeMarket,
+		Strategy: alert.Strategy,
+		Tags:     []string{"webhook", s.Name},
+		Metadata: alert.Metadata,
+	}
+	if req.Symbol == "" {
+		return req, errors.New("ticker is required")
+	}
+	if err := trading.ValidateMetadata(req.Metadata); err != nil {
+		return req, err
+	}
+
+	switch strings.ToLower(alert.Action) {
+	case "buy", "long":
+		req.Side = trading.OrderSideBuy
+	case "sell", "short":
+		req.Side = trading.OrderSideSell
+	default:
+		return req, fmt.Errorf("invalid action %q", alert.Action)
+	}
+
+	switch strings.ToLower(alert.OrderType) {
+	case "", "market":
+	case "limit":
+		req.Type = trading.OrderTypeLimit
+	default:
+		return req, fmt.Errorf("invalid order type %q", alert.OrderType)
+	}
+
+	if alert.Quantity < 0 || alert.Price < 0 || alert.StopLoss < 0 || alert.TakeProfit < 0 {
+		return req, errors.New("quantity and prices must not be negative")
+	}
+	if alert.Quantity != math.Trunc(alert.Quantity) {
+		return req, fmt.Errorf("quantity %v is not a whole number", alert.Quantity)
+	}
+	req.Quantity = int64(alert.Quantity)
+	if req.Quantity == 0 {
+		req.Quantity = s.DefaultQuantity
+	}
+	if req.Quantity <= 0 {
+		return req, errors.New("quantity is required")
+	}
+	if req.Type == trading.OrderTypeLimit && req.Price <= 0 {
+		return req, errors.New("limit order requires a price")
+	}
+
+	return req, nil
+}
+
+// watchlistItem 将告警转换为监控项，买入告警进入买入表，卖出告警进入卖出表（内部方法）
+func (s WebhookSource) watchlistItem(alert WebhookAlert, req trading.OrderRequest, now time.Time) trading.WatchlistItem {
+	item := trading.WatchlistItem{
+		ID:         trading.DefaultIDGenerator.NewID("webhook-" + s.Name),
+		Symbol:     req.Symbol,
+		StopLoss:   alert.StopLoss,
+		TakeProfit: alert.TakeProfit,
+		Quantity:   req.Quantity,
+		AddedAt:    now,
+		Strategy:   req.Strategy,
+		Notes:      alert.Comment,
+		Tags:       req.Tags,
+		IsBuyList:  req.Side == trading.OrderSideBuy,
+		Metadata:   req.Metadata,
+	}
+	if item.IsBuyList {
+		item.TargetPrice = alert.Price
+	}
+	if s.Expiry > 0 {
+		expiresAt := now.Add(s.Expiry)
+		item.ExpiresAt = &expiresAt
+	}
+	return item
+}
+
+// verifySignature 校验请求体的HMAC-SHA256签名，支持 sha256= 前缀（内部方法）
+func verifySignature(body []byte, signature, secret string) bool {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// tokenBucket 是按来源限速使用的令牌桶（内部类型）
+type tokenBucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64 // 每秒补充的令牌数
+	last     time.Time
+}
+
+// newTokenBucket 创建令牌桶，burst为0时容量等于每分钟速率（内部方法）
+func newTokenBucket(perMinute, burst int, now time.Time) *tokenBucket {
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &tokenBucket{
+		tokens:   float64(burst),
+		capacity: float64(burst),
+		rate:     float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+// allow 尝试取出一个令牌（内部方法）
+func (b *tokenBucket) allow(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+func TestWebhookGateway(t *testing.T) {
+	watchlist := trading.NewWatchlist(nil, nil)
+	gw := NewWebhookGateway(nil, watchlist, nil)
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	gw.now = func() time.Time { return now }
+
+	if err := gw.AddSource(WebhookSource{
+		Name:            "tradingview",
+		Mode:            WebhookModeWatchlist,
+		Passphrase:      "pass",
+		RatePerMinute:   60,
+		Burst:           2,
+		DefaultQuantity: 100,
+		SymbolMap:       map[string]string{"NYSE:BRK.B": "BRK-B"},
+	}); err != nil {
+		t.Fatalf("添加来源失败: %v", err)
+	}
+	if err := gw.AddSource(WebhookSource{Name: "signed", Mode: WebhookModeWatchlist, Secret: "secret"}); err != nil {
+		t.Fatalf("添加来源失败: %v", err)
+	}
+
+	handler := gw.Handler("/webhooks/")
+	post := func(source, body, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/"+source, strings.NewReader(body))
+		if signature != "" {
+			req.Header.Set(DefaultSignatureHeader, signature)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post("tradingview", `{"ticker":"BRK.B","exchange":"NYSE","action":"buy","price":350,"passphrase":"pass"}`, ""); rec.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d, 期望 200: %s", rec.Code, rec.Body.String())
+	}
+	items := watchlist.GetActiveItems()
+	if len(items) != 1 || items[0].Symbol != "BRK-B" || !items[0].IsBuyList || items[0].Quantity != 100 || items[0].TargetPrice != 350 {
+		t.Fatalf("监控项不正确: %+v", items)
+	}
+
+	if rec := post("tradingview", `{"ticker":"AAPL","action":"buy","passphrase":"wrong"}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("错误口令状态码 = %d, 期望 401", rec.Code)
+	}
+	if rec := post("tradingview", `{"ticker":"NASDAQ:AAPL","action":"hold","passphrase":"pass"}`, ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("无效动作状态码 = %d, 期望 422", rec.Code)
+	}
+	// 令牌桶容量为2，第三个通过认证的请求被限速
+	if rec := post("tradingview", `{"ticker":"AAPL","action":"sell","passphrase":"pass"}`, ""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("限速状态码 = %d, 期望 429", rec.Code)
+	}
+	now = now.Add(time.Second)
+	if rec := post("tradingview", `{"ticker":"NASDAQ:AAPL","action":"sell","quantity":5,"passphrase":"pass"}`, ""); rec.Code != http.StatusOK {
+		t.Errorf("补充令牌后状态码 = %d, 期望 200: %s", rec.Code, rec.Body.String())
+	}
+
+	body := `{"ticker":"MSFT","action":"buy","quantity":10}`
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(body))
+	if rec := post("signed", body, "sha256="+hex.EncodeToString(mac.Sum(nil))); rec.Code != http.StatusOK {
+		t.Errorf("签名请求状态码 = %d, 期望 200: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("signed", body, "deadbeef"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("错误签名状态码 = %d, 期望 401", rec.Code)
+	}
+	if rec := post("unknown", body, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("未知来源状态码 = %d, 期望 404", rec.Code)
+	}
+
+	if got := len(watchlist.GetActiveItems()); got != 3 {
+		t.Errorf("监控项数量 = %d, 期望 3", got)
+	}
+}
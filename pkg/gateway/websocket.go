@@ -0,0 +1,259 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// 客户端消息动作常量
+const (
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+// 网关消息类型常量
+const (
+	MessageTypeHeartbeat  = "heartbeat"
+	MessageTypeSubscribed = "subscribed"
+	MessageTypeError      = "error"
+)
+
+// ClientMessage 表示客户端发送的订阅控制消息
+type ClientMessage struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+}
+
+// ServerMessage 表示网关推送给客户端的消息
+type ServerMessage struct {
+	Topic     string      `json:"topic,omitempty"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Payload   interface{} `json:"payload,omitempty"`
+}
+
+// WebSocketConfig 表示WebSocket网关配置
+type WebSocketConfig struct {
+	AllowedOrigins    []string      `json:"allowed_origins" yaml:"allowed_origins"` // 为空时允许所有来源
+	HeartbeatInterval time.Duration `json:"heartbeat_interval" yaml:"heartbeat_interval"`
+	BufferSize        int           `json:"buffer_size" yaml:"buffer_size"` // 每个连接的事件缓冲区大小
+}
+
+// WebSocketGateway 将事件总线上的事件以JSON消息推送给浏览器等WebSocket客户端
+// 客户端通过URL参数 ?topics=orders,fills 或发送 {"action":"subscribe","topics":[...]} 订阅主题
+type WebSocketGateway struct {
+	bus     *events.Bus
+	config  WebSocketConfig
+	mu      sync.Mutex
+	clients int
+}
+
+// NewWebSocketGateway 创建一个新的WebSocket网关
+func NewWebSocketGateway(bus *events.Bus, config WebSocketConfig) *WebSocketGateway {
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = 30 * time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 256
+	}
+
+	return &WebSocketGateway{
+		bus:    bus,
+		config: config,
+	}
+}
+
+// Handler 返回处理WebSocket连接的HTTP处理器
+func (g *WebSocketGateway) Handler() http.Handler {
+	return websocket.Server{
+		Handshake: g.handshake,
+		Handler:   g.serveConn,
+	}
+}
+
+// ClientCount 返回当前连接的客户端数量
+func (g *WebSocketGateway) ClientCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.clients
+}
+
+// handshake 检查连接来源是否被允许
+func (g *WebSocketGateway) handshake(config *websocket.Config, req *http.Request) error {
+	if len(g.config.AllowedOrigins) == 0 {
+		return nil
+	}
+
+	origin, err := websocket.Origin(config, req)
+	if err != nil || origin == nil {
+		return fmt.Errorf("missing origin")
+	}
+
+	for _, allowed := range g.config.AllowedOrigins {
+		if strings.EqualFold(allowed, origin.Scheme+"://"+origin.Host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %s is not allowed", origin)
+}
+
+// serveConn 处理单个WebSocket连接
+func (g *WebSocketGateway) serveConn(conn *websocket.Conn) {
+	defer conn.Close()
+
+	g.mu.Lock()
+	g.clients++
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.clients--
+		g.mu.Unlock()
+	}()
+
+	topics := newTopicSet(topicsFromQuery(conn.Request().URL))
+	sub := g.bus.Subscribe(g.config.BufferSize)
+	defer sub.Close()
+
+	var sendMu sync.Mutex
+	send := func(msg ServerMessage) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return websocket.JSON.Send(conn, msg)
+	}
+
+	// 读取客户端的订阅控制消息
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var msg ClientMessage
+			if err := websocket.JSON.Receive(conn, &msg); err != nil {
+				return
+			}
+
+			switch msg.Action {
+			case ActionSubscribe:
+				topics.add(msg.Topics...)
+			case ActionUnsubscribe:
+				topics.remove(msg.Topics...)
+			default:
+				send(ServerMessage{
+					Type:      MessageTypeError,
+					Timestamp: time.Now(),
+					Payload:   fmt.Sprintf("unknown action %q", msg.Action),
+				})
+				continue
+			}
+
+			send(ServerMessage{
+				Type:      MessageTypeSubscribed,
+				Timestamp: time.Now(),
+				Payload:   topics.list(),
+			})
+		}
+	}()
+
+	heartbeat := time.NewTicker(g.config.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-heartbeat.C:
+			if err := send(ServerMessage{Type: MessageTypeHeartbeat, Timestamp: time.Now()}); err != nil {
+				return
+			}
+		case evt, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if !topics.has(evt.Topic) {
+				continue
+			}
+			msg := ServerMessage{
+				Topic:     evt.Topic,
+				Type:      evt.Type,
+				Timestamp: evt.Timestamp,
+				Payload:   evt.Payload,
+			}
+			if err := send(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// topicsFromQuery 从URL参数中解析订阅主题
+func topicsFromQuery(u *url.URL) []string {
+	if u == nil {
+		return nil
+	}
+
+	var topics []string
+	for _, value := range u.Query()["topics"] {
+		for _, topic := range strings.Split(value, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, topic)
+			}
+		}
+	}
+	return topics
+}
+
+// topicSet 是一个并发安全的主题集合
+type topicSet struct {
+	mu     sync.RWMutex
+	topics map[string]bool
+}
+
+// newTopicSet 创建主题集合
+func newTopicSet(topics []string) *topicSet {
+	set := &topicSet{topics: make(map[string]bool)}
+	set.add(topics...)
+	return set
+}
+
+// add 添加主题
+func (s *topicSet) add(topics ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, topic := range topics {
+		s.topics[topic] = true
+	}
+}
+
+// remove 移除主题
+func (s *topicSet) remove(topics ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, topic := range topics {
+		delete(s.topics, topic)
+	}
+}
+
+// has 检查是否订阅了主题
+func (s *topicSet) has(topic string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.topics[topic]
+}
+
+// list 返回已订阅的主题列表
+func (s *topicSet) list() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+	return topics
+}
@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// dialGateway 连接测试服务器上的网关，返回的连接在测试结束时关闭（内部函数）
+func dialGateway(t *testing.T, server *httptest.Server, query, origin string) *websocket.Conn {
+	t.Helper()
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/"+query, "", origin)
+	if err != nil {
+		t.Fatalf("连接网关失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// receive 读取下一条网关消息，超时时测试失败（内部函数）
+func receive(t *testing.T, conn *websocket.Conn) ServerMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg ServerMessage
+	if err := websocket.JSON.Receive(conn, &msg); err != nil {
+		t.Fatalf("读取网关消息失败: %v", err)
+	}
+	return msg
+}
+
+// control 发送订阅控制消息并等待确认，返回确认后订阅的主题（内部函数）
+func control(t *testing.T, conn *websocket.Conn, action string, topics ...string) []interface{} {
+	t.Helper()
+	if err := websocket.JSON.Send(conn, ClientMessage{Action: action, Topics: topics}); err != nil {
+		t.Fatalf("发送订阅消息失败: %v", err)
+	}
+	msg := receive(t, conn)
+	list, _ := msg.Payload.([]interface{})
+	if msg.Type != MessageTypeSubscribed {
+		t.Fatalf("应确认订阅: %+v", msg)
+	}
+	return list
+}
+
+func TestWebSocketGatewayTopics(t *testing.T) {
+	bus := events.NewBus()
+	gw := NewWebSocketGateway(bus, WebSocketConfig{HeartbeatInterval: time.Hour})
+	server := httptest.NewServer(gw.Handler())
+	defer server.Close()
+
+	// URL参数订阅orders，确认消息返回后连接已订阅事件总线
+	conn := dialGateway(t, server, "?topics=orders", "http://localhost/")
+	if topics := control(t, conn, ActionSubscribe); len(topics) != 1 || topics[0] != events.TopicOrders {
+		t.Fatalf("URL参数订阅的主题不正确: %v", topics)
+	}
+	if gw.ClientCount() != 1 {
+		t.Errorf("客户端数量 = %d, 期望 1", gw.ClientCount())
+	}
+
+	// 未订阅的主题被过滤，订阅的主题按原样推送
+	bus.Publish(events.Event{Topic: events.TopicFills, Type: "fill", Timestamp: time.Now(), Payload: "skip"})
+	bus.Publish(events.Event{Topic: events.TopicOrders, Type: "order_accepted", Timestamp: time.Now(), Payload: map[string]string{"id": "o1"}})
+	msg := receive(t, conn)
+	payload, _ := msg.Payload.(map[string]interface{})
+	if msg.Topic != events.TopicOrders || msg.Type != "order_accepted" || payload["id"] != "o1" {
+		t.Fatalf("推送的事件不正确: %+v", msg)
+	}
+
+	// 通过控制消息改为订阅fills
+	control(t, conn, ActionSubscribe, events.TopicFills)
+	if topics := control(t, conn, ActionUnsubscribe, events.TopicOrders); len(topics) != 1 || topics[0] != events.TopicFills {
+		t.Fatalf("取消订阅后的主题不正确: %v", topics)
+	}
+	bus.Publish(events.Event{Topic: events.TopicOrders, Type: "order_accepted", Timestamp: time.Now()})
+	bus.Publish(events.Event{Topic: events.TopicFills, Type: "fill", Timestamp: time.Now()})
+	if msg := receive(t, conn); msg.Topic != events.TopicFills || msg.Type != "fill" {
+		t.Fatalf("应只推送新订阅的主题: %+v", msg)
+	}
+
+	// 未知动作返回错误消息
+	websocket.JSON.Send(conn, ClientMessage{Action: "bogus"})
+	if msg := receive(t, conn); msg.Type != MessageTypeError {
+		t.Errorf("未知动作应返回错误消息: %+v", msg)
+	}
+}
+
+func TestWebSocketGatewayOrigin(t *testing.T) {
+	gw := NewWebSocketGateway(events.NewBus(), WebSocketConfig{AllowedOrigins: []string{"https://app.example.com"}})
+	server := httptest.NewServer(gw.Handler())
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/"
+	if _, err := websocket.Dial(url, "", "https://evil.example.com"); err == nil {
+		t.Error("不允许的来源应被拒绝")
+	}
+	dialGateway(t, server, "", "https://app.example.com")
+}
@@ -0,0 +1,297 @@
+package indicators
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// 本文件实现写出 Apache Arrow IPC 文件格式（即 Feather V2）所需的最小子集：
+// 没有空值的定长列（float64、int64 和毫秒时间戳），一个记录批次，元数据版本V5。
+// 元数据按FlatBuffers编码，子对象写在引用它的对象之后，偏移量都指向后方
+
+// arrowMagic 是Arrow文件首尾的魔数
+const arrowMagic = "ARROW1"
+
+// Arrow元数据中的枚举值
+const (
+	arrowMetadataV5       = 4
+	arrowHeaderSchema     = 1
+	arrowHeaderBatch      = 3
+	arrowTypeInt          = 2
+	arrowTypeFloat        = 3
+	arrowTypeTimestamp    = 10
+	arrowPrecisionDouble  = 2
+	arrowUnitMillisecond  = 1
+	arrowContinuationMark = 0xFFFFFFFF
+)
+
+// arrowColumn 表示一个要写出的定长列（内部类型）
+type arrowColumn struct {
+	name     string
+	typ      uint8 // arrowTypeInt、arrowTypeFloat 或 arrowTypeTimestamp
+	values   []uint64
+	timezone string // 时间戳列的时区
+}
+
+// float64Column 创建一个float64列（内部函数）
+func float64Column(name string, values []float64) arrowColumn {
+	bits := make([]uint64, len(values))
+	for i, v := range values {
+		bits[i] = math.Float64bits(v)
+	}
+	return arrowColumn{name: name, typ: arrowTypeFloat, values: bits}
+}
+
+// int64Column 创建一个int64列，timestamp为true时为UTC毫秒时间戳（内部函数）
+func int64Column(name string, values []int64, timestamp bool) arrowColumn {
+	bits := make([]uint64, len(values))
+	for i, v := range values {
+		bits[i] = uint64(v)
+	}
+	if timestamp {
+		return arrowColumn{name: name, typ: arrowTypeTimestamp, values: bits, timezone: "UTC"}
+	}
+	return arrowColumn{name: name, typ: arrowTypeInt, values: bits}
+}
+
+// writeArrowFile 把长度相同的列作为一个记录批次写成Arrow IPC文件（内部函数）
+func writeArrowFile(w io.Writer, columns []arrowColumn) error {
+	rows := 0
+	if len(columns) > 0 {
+		rows = len(columns[0].values)
+	}
+
+	// 记录批次的消息体：每列一个长度为0的有效位缓冲区和一个数据缓冲区，数据缓冲区按8字节对齐
+	body := make([]byte, 0, len(columns)*rows*8)
+	nodes := make([]byte, 0, len(columns)*16)
+	buffers := make([]byte, 0, len(columns)*32)
+	for _, col := range columns {
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(rows))
+		nodes = binary.LittleEndian.AppendUint64(nodes, 0)
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body)))
+		buffers = binary.LittleEndian.AppendUint64(buffers, 0)
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body)))
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(rows*8))
+		for _, v := range col.values {
+			body = binary.LittleEndian.AppendUint64(body, v)
+		}
+	}
+	batch := fbTable{
+		fbInt64(int64(rows)),
+		fbRef(fbStructs{count: len(columns), data: nodes}),
+		fbRef(fbStructs{count: 2 * len(columns), data: buffers}),
+	}
+
+	out := []byte(arrowMagic + "\x00\x00")
+	out = appendArrowMessage(out, arrowHeaderSchema, arrowSchema(columns), nil)
+	batchOffset := len(out)
+	out = appendArrowMessage(out, arrowHeaderBatch, batch, body)
+	batchMeta := len(out) - batchOffset - len(body)
+	// 流结束标记
+	out = binary.LittleEndian.AppendUint32(out, arrowContinuationMark)
+	out = binary.LittleEndian.AppendUint32(out, 0)
+
+	block := binary.LittleEndian.AppendUint64(nil, uint64(batchOffset))
+	block = binary.LittleEndian.AppendUint32(block, uint32(batchMeta))
+	block = binary.LittleEndian.AppendUint32(block, 0)
+	block = binary.LittleEndian.AppendUint64(block, uint64(len(body)))
+	footer := fbFinish(fbTable{
+		fbInt16(arrowMetadataV5),
+		fbRef(arrowSchema(columns)),
+		fbRef(fbStructs{}),
+		fbRef(fbStructs{count: 1, data: block}),
+	})
+	out = append(out, footer...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(footer)))
+	out = append(out, arrowMagic...)
+
+	_, err := w.Write(out)
+	return err
+}
+
+// appendArrowMessage 追加一个封装的消息：继续标记、元数据长度、按8字节对齐的元数据和消息体（内部函数）
+func appendArrowMessage(out []byte, headerType uint8, header fbTable, body []byte) []byte {
+	meta := fbFinish(fbTable{
+		fbInt16(arrowMetadataV5),
+		fbUint8(headerType),
+		fbRef(header),
+		fbInt64(int64(len(body))),
+	})
+	out = binary.LittleEndian.AppendUint32(out, arrowContinuationMark)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(meta)))
+	out = append(out, meta...)
+	return append(out, body...)
+}
+
+// arrowSchema 返回列的Schema表（内部函数）
+func arrowSchema(columns []arrowColumn) fbTable {
+	fields := make(fbTables, len(columns))
+	for i, col := range columns {
+		var typ fbTable
+		switch col.typ {
+		case arrowTypeTimestamp:
+			typ = fbTable{fbInt16(arrowUnitMillisecond), fbRef(fbString(col.timezone))}
+		case arrowTypeInt:
+			typ = fbTable{fbInt32(64), fbBool(true)}
+		default:
+			typ = fbTable{fbInt16(arrowPrecisionDouble)}
+		}
+		// Arrow读取端要求children向量存在，即使为空
+		fields[i] = fbTable{
+			fbRef(fbString(col.name)),
+			fbBool(false),
+			fbUint8(col.typ),
+			fbRef(typ),
+			{},
+			fbRef(fbTables{}),
+		}
+	}
+	// 字节序为小端（0）
+	return fbTable{fbInt16(0), fbRef(fields)}
+}
+
+// fbWriter 按从前到后的顺序写出FlatBuffers缓冲区（内部类型）
+type fbWriter struct {
+	buf []byte
+}
+
+// align 填充0直到 len(buf)+offset 是n的倍数（内部方法）
+func (w *fbWriter) align(n, offset int) {
+	for (len(w.buf)+offset)%n != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// fbObject 是可以写入缓冲区、被偏移量引用的FlatBuffers对象，write返回对象的位置（内部类型）
+type fbObject interface {
+	write(w *fbWriter) int
+}
+
+// fbField 是表中的一个字段：size大于0时为标量，ref不为空时为对象引用，两者都为零值时字段缺省（内部类型）
+type fbField struct {
+	size   int
+	scalar uint64
+	ref    fbObject
+}
+
+// fbUint8、fbBool、fbInt16、fbInt32、fbInt64 和 fbRef 创建标量和引用字段（内部函数）
+func fbUint8(v uint8) fbField { return fbField{size: 1, scalar: uint64(v)} }
+func fbBool(v bool) fbField {
+	if v {
+		return fbUint8(1)
+	}
+	return fbUint8(0)
+}
+func fbInt16(v int16) fbField  { return fbField{size: 2, scalar: uint64(uint16(v))} }
+func fbInt32(v int32) fbField  { return fbField{size: 4, scalar: uint64(uint32(v))} }
+func fbInt64(v int64) fbField  { return fbField{size: 8, scalar: uint64(v)} }
+func fbRef(v fbObject) fbField { return fbField{ref: v} }
+
+// fbTable 是按字段ID排列的表（内部类型）
+type fbTable []fbField
+
+// write 先写vtable，再写按8字节对齐的表，最后写引用的子对象并回填偏移量（内部方法）
+func (t fbTable) write(w *fbWriter) int {
+	offsets := make([]int, len(t))
+	size := 4 // 表开头是指向vtable的soffset
+	for i, f := range t {
+		n := f.size
+		if f.ref != nil {
+			n = 4
+		}
+		if n == 0 {
+			continue
+		}
+		for size%n != 0 {
+			size++
+		}
+		offsets[i] = size
+		size += n
+	}
+
+	w.align(2, 0)
+	vtable := len(w.buf)
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(4+2*len(t)))
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(size))
+	for _, off := range offsets {
+		w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(off))
+	}
+
+	w.align(8, 0)
+	pos := len(w.buf)
+	w.buf = append(w.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(w.buf[pos:], uint32(int32(pos-vtable)))
+	for i, f := range t {
+		at := pos + offsets[i]
+		switch f.size {
+		case 1:
+			w.buf[at] = byte(f.scalar)
+		case 2:
+			binary.LittleEndian.PutUint16(w.buf[at:], uint16(f.scalar))
+		case 4:
+			binary.LittleEndian.PutUint32(w.buf[at:], uint32(f.scalar))
+		case 8:
+			binary.LittleEndian.PutUint64(w.buf[at:], f.scalar)
+		}
+	}
+	for i, f := range t {
+		if f.ref != nil {
+			at := pos + offsets[i]
+			child := f.ref.write(w)
+			binary.LittleEndian.PutUint32(w.buf[at:], uint32(child-at))
+		}
+	}
+	return pos
+}
+
+// fbString 是以0结尾的字符串（内部类型）
+type fbString string
+
+func (s fbString) write(w *fbWriter) int {
+	w.align(4, 0)
+	pos := len(w.buf)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(len(s)))
+	w.buf = append(w.buf, s...)
+	w.buf = append(w.buf, 0)
+	return pos
+}
+
+// fbTables 是表的向量（内部类型）
+type fbTables []fbObject
+
+func (v fbTables) write(w *fbWriter) int {
+	w.align(4, 0)
+	pos := len(w.buf)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(len(v)))
+	w.buf = append(w.buf, make([]byte, 4*len(v))...)
+	for i, item := range v {
+		slot := pos + 4 + 4*i
+		child := item.write(w)
+		binary.LittleEndian.PutUint32(w.buf[slot:], uint32(child-slot))
+	}
+	return pos
+}
+
+// fbStructs 是按8字节对齐的结构体向量，data为已编码的元素（内部类型）
+type fbStructs struct {
+	count int
+	data  []byte
+}
+
+func (v fbStructs) write(w *fbWriter) int {
+	w.align(8, 4)
+	pos := len(w.buf)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(v.count))
+	w.buf = append(w.buf, v.data...)
+	return pos
+}
+
+// fbFinish 写出以root为根表的缓冲区，长度填充到8字节的倍数（内部函数）
+func fbFinish(root fbObject) []byte {
+	w := &fbWriter{buf: make([]byte, 4)}
+	pos := root.write(w)
+	binary.LittleEndian.PutUint32(w.buf, uint32(pos))
+	w.align(8, 0)
+	return w.buf
+}
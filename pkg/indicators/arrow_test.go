@@ -0,0 +1,253 @@
+package indicators
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// arrowFile 是按元数据解码出的Arrow IPC文件（内部类型）
+type arrowFile struct {
+	fields  []arrowField
+	rows    int64
+	columns [][]uint64 // 每列按小端读回的8字节值
+}
+
+// arrowField 是Schema中的一个字段及其类型参数（内部类型）
+type arrowField struct {
+	name      string
+	typ       uint8
+	bitWidth  int32
+	signed    bool
+	precision int16
+	unit      int16
+	timezone  string
+}
+
+// readArrow 解码文件中的Schema消息、记录批次消息和页脚，校验消息封装、块位置和缓冲区布局，
+// 并从消息体读回每列的值（内部函数）
+func readArrow(t *testing.T, data []byte) arrowFile {
+	t.Helper()
+	if len(data) < 18 || string(data[:8]) != arrowMagic+"\x00\x00" || string(data[len(data)-6:]) != arrowMagic {
+		t.Fatal("Arrow文件首尾应为魔数")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-10:]))
+	footerStart := len(data) - 10 - footerLen
+	if footerLen <= 0 || footerLen%8 != 0 || footerStart < 8 {
+		t.Fatalf("页脚长度不正确: %d", footerLen)
+	}
+
+	// 页脚：元数据版本、Schema、没有字典，一个记录批次块
+	footer := fbReader{t: t, buf: data[footerStart : len(data)-10]}
+	root := footer.root()
+	if v := footer.scalar(root, 0, 2); v != arrowMetadataV5 {
+		t.Errorf("页脚元数据版本 = %d, 期望 %d", v, arrowMetadataV5)
+	}
+	fields := decodeArrowSchema(footer, footer.ref(root, 1))
+	if _, n := footer.vector(root, 2); n != 0 {
+		t.Errorf("不应有字典块: %d", n)
+	}
+	blocks, n := footer.vector(root, 3)
+	if n != 1 || blocks%8 != 0 {
+		t.Fatalf("应有1个按8字节对齐的记录批次块: %d个，位置%d", n, blocks)
+	}
+	block := footer.bytes(blocks, 24)
+	batchOffset := int(binary.LittleEndian.Uint64(block))
+	batchMeta := int(binary.LittleEndian.Uint32(block[8:]))
+	bodyLen := int64(binary.LittleEndian.Uint64(block[16:]))
+
+	// Schema消息紧跟在文件头之后，没有消息体，内容与页脚中的Schema一致
+	msg, meta, end := readArrowMessage(t, data, 8)
+	if typ := msg.scalar(meta, 1, 1); typ != arrowHeaderSchema || msg.scalar(meta, 3, 8) != 0 {
+		t.Fatalf("第一条消息应为没有消息体的Schema: 类型%d", typ)
+	}
+	if got := decodeArrowSchema(msg, msg.ref(meta, 2)); !reflect.DeepEqual(got, fields) {
+		t.Errorf("Schema消息与页脚不一致:\n%+v\n%+v", got, fields)
+	}
+	if end != batchOffset {
+		t.Fatalf("记录批次块偏移 = %d, 期望紧跟Schema消息之后的 %d", batchOffset, end)
+	}
+
+	// 记录批次消息：块中的元数据长度包含封装前缀，消息体紧跟元数据
+	msg, meta, bodyStart := readArrowMessage(t, data, batchOffset)
+	if bodyStart-batchOffset != batchMeta {
+		t.Errorf("块中的元数据长度 = %d, 期望 %d", batchMeta, bodyStart-batchOffset)
+	}
+	if typ := msg.scalar(meta, 1, 1); typ != arrowHeaderBatch || int64(msg.scalar(meta, 3, 8)) != bodyLen {
+		t.Fatalf("第二条消息应为记录批次，消息体长度%d: 类型%d 长度%d", bodyLen, typ, msg.scalar(meta, 3, 8))
+	}
+	bodyEnd := bodyStart + int(bodyLen)
+	if bodyEnd+8 > footerStart {
+		t.Fatalf("消息体越界: %d-%d，页脚从%d开始", bodyStart, bodyEnd, footerStart)
+	}
+	body := data[bodyStart:bodyEnd]
+	batch := msg.ref(meta, 2)
+	file := arrowFile{fields: fields, rows: int64(msg.scalar(batch, 0, 8))}
+
+	// 每列一个FieldNode，两个缓冲区：长度为0的有效位缓冲区和按8字节对齐的数据缓冲区
+	nodes, nodeCount := msg.vector(batch, 1)
+	buffers, bufferCount := msg.vector(batch, 2)
+	if nodeCount != len(fields) || bufferCount != 2*len(fields) || nodes%8 != 0 || buffers%8 != 0 {
+		t.Fatalf("应有%d个FieldNode和%d个缓冲区: %d %d", len(fields), 2*len(fields), nodeCount, bufferCount)
+	}
+	for i, field := range fields {
+		node := msg.bytes(nodes+16*i, 16)
+		if int64(binary.LittleEndian.Uint64(node)) != file.rows || binary.LittleEndian.Uint64(node[8:]) != 0 {
+			t.Errorf("列%s的FieldNode不正确: %v", field.name, node)
+		}
+		validity := msg.bytes(buffers+32*i, 16)
+		if binary.LittleEndian.Uint64(validity[8:]) != 0 {
+			t.Errorf("列%s没有空值，有效位缓冲区长度应为0", field.name)
+		}
+		buffer := msg.bytes(buffers+32*i+16, 16)
+		offset, length := binary.LittleEndian.Uint64(buffer), binary.LittleEndian.Uint64(buffer[8:])
+		if offset%8 != 0 || int64(length) != 8*file.rows || offset+length > uint64(len(body)) {
+			t.Fatalf("列%s的数据缓冲区不正确: 偏移%d 长度%d，消息体%d字节", field.name, offset, length, len(body))
+		}
+		values := make([]uint64, file.rows)
+		for j := range values {
+			values[j] = binary.LittleEndian.Uint64(body[int(offset)+8*j:])
+		}
+		file.columns = append(file.columns, values)
+	}
+
+	// 消息体之后是流结束标记，然后是页脚
+	if binary.LittleEndian.Uint32(data[bodyEnd:]) != arrowContinuationMark || binary.LittleEndian.Uint32(data[bodyEnd+4:]) != 0 || bodyEnd+8 != footerStart {
+		t.Errorf("消息体之后应是流结束标记和页脚: %v", data[bodyEnd:bodyEnd+8])
+	}
+	return file
+}
+
+// readArrowMessage 读取offset处封装的消息，返回元数据、Message根表和元数据结束位置（内部函数）
+func readArrowMessage(t *testing.T, data []byte, offset int) (fbReader, int, int) {
+	t.Helper()
+	if offset+8 > len(data) || binary.LittleEndian.Uint32(data[offset:]) != arrowContinuationMark {
+		t.Fatalf("偏移%d处应为消息继续标记", offset)
+	}
+	length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+	start := offset + 8
+	if length%8 != 0 || start+length > len(data) {
+		t.Fatalf("偏移%d处的消息元数据长度不正确: %d", offset, length)
+	}
+	msg := fbReader{t: t, buf: data[start : start+length]}
+	root := msg.root()
+	if v := msg.scalar(root, 0, 2); v != arrowMetadataV5 {
+		t.Errorf("消息元数据版本 = %d, 期望 %d", v, arrowMetadataV5)
+	}
+	return msg, root, start + length
+}
+
+// decodeArrowSchema 解码Schema表中的字段（内部函数）
+func decodeArrowSchema(r fbReader, schema int) []arrowField {
+	r.t.Helper()
+	if endianness := r.scalar(schema, 0, 2); endianness != 0 {
+		r.t.Errorf("字节序应为小端: %d", endianness)
+	}
+	vector, n := r.vector(schema, 1)
+	fields := make([]arrowField, n)
+	for i := range fields {
+		slot := vector + 4*i
+		table := slot + int(binary.LittleEndian.Uint32(r.bytes(slot, 4)))
+		field := &fields[i]
+		field.name = r.str(r.ref(table, 0))
+		field.typ = uint8(r.scalar(table, 2, 1))
+		if nullable := r.scalar(table, 1, 1); nullable != 0 {
+			r.t.Errorf("字段%s不应可空", field.name)
+		}
+		if _, children := r.vector(table, 5); children != 0 {
+			r.t.Errorf("字段%s不应有子字段: %d", field.name, children)
+		}
+		typ := r.ref(table, 3)
+		switch field.typ {
+		case arrowTypeInt:
+			field.bitWidth = int32(r.scalar(typ, 0, 4))
+			field.signed = r.scalar(typ, 1, 1) == 1
+		case arrowTypeFloat:
+			field.precision = int16(r.scalar(typ, 0, 2))
+		case arrowTypeTimestamp:
+			field.unit = int16(r.scalar(typ, 0, 2))
+			field.timezone = r.str(r.ref(typ, 1))
+		default:
+			r.t.Errorf("字段%s的类型未知: %d", field.name, field.typ)
+		}
+	}
+	return fields
+}
+
+// fbReader 读取FlatBuffers缓冲区，位置均为缓冲区内的绝对偏移（内部类型）
+type fbReader struct {
+	t   *testing.T
+	buf []byte
+}
+
+// bytes 返回pos处的n个字节，越界时测试失败（内部方法）
+func (r fbReader) bytes(pos, n int) []byte {
+	r.t.Helper()
+	if pos < 0 || n < 0 || pos+n > len(r.buf) {
+		r.t.Fatalf("读取%d-%d越界，缓冲区%d字节", pos, pos+n, len(r.buf))
+	}
+	return r.buf[pos : pos+n]
+}
+
+// root 返回根表的位置（内部方法）
+func (r fbReader) root() int {
+	r.t.Helper()
+	return int(binary.LittleEndian.Uint32(r.bytes(0, 4)))
+}
+
+// field 返回表中字段的位置，字段缺省时返回0（内部方法）
+func (r fbReader) field(table, id int) int {
+	r.t.Helper()
+	vtable := table - int(int32(binary.LittleEndian.Uint32(r.bytes(table, 4))))
+	size := int(binary.LittleEndian.Uint16(r.bytes(vtable, 2)))
+	if 4+2*id >= size {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(r.bytes(vtable+4+2*id, 2)))
+	if off == 0 {
+		return 0
+	}
+	return table + off
+}
+
+// scalar 读取size字节的标量字段，缺省时为0（内部方法）
+func (r fbReader) scalar(table, id, size int) uint64 {
+	r.t.Helper()
+	pos := r.field(table, id)
+	if pos == 0 {
+		return 0
+	}
+	var v uint64
+	for i, b := range r.bytes(pos, size) {
+		v |= uint64(b) << (8 * i)
+	}
+	return v
+}
+
+// ref 返回引用字段指向的对象位置，字段缺省时测试失败（内部方法）
+func (r fbReader) ref(table, id int) int {
+	r.t.Helper()
+	pos := r.field(table, id)
+	if pos == 0 {
+		r.t.Fatalf("位置%d的表缺少引用字段%d", table, id)
+	}
+	return pos + int(binary.LittleEndian.Uint32(r.bytes(pos, 4)))
+}
+
+// vector 返回向量字段第一个元素的位置和元素个数（内部方法）
+func (r fbReader) vector(table, id int) (int, int) {
+	r.t.Helper()
+	pos := r.ref(table, id)
+	return pos + 4, int(binary.LittleEndian.Uint32(r.bytes(pos, 4)))
+}
+
+// str 读取pos处以0结尾的字符串（内部方法）
+func (r fbReader) str(pos int) string {
+	r.t.Helper()
+	n := int(binary.LittleEndian.Uint32(r.bytes(pos, 4)))
+	s := r.bytes(pos+4, n+1)
+	if s[n] != 0 {
+		r.t.Errorf("位置%d的字符串应以0结尾", pos)
+	}
+	return string(s[:n])
+}
@@ -0,0 +1,77 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// ATR 平均真实波幅指标结构体，按Wilder平滑
+type ATR struct {
+	period int
+}
+
+// NewATR 创建一个新的ATR指标
+func NewATR(params IndicatorParams) (Indicator, error) {
+	period := params.GetInt("period", 14)
+
+	// 验证参数
+	if period <= 0 {
+		return nil, fmt.Errorf("period must be a positive integer")
+	}
+
+	return &ATR{
+		period: period,
+	}, nil
+}
+
+// Name 返回指标名称
+func (a *ATR) Name() string {
+	return IndicatorTypeATR
+}
+
+// Calculate 计算ATR指标值
+func (a *ATR) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	_, dates, err := sourceSeries(data, SourceClose, a.period+1, "ATR")
+	if err != nil {
+		return IndicatorResult{}, err
+	}
+
+	return IndicatorResult{
+		Name: a.Name(),
+		Values: map[string][]float64{
+			"atr": AverageTrueRange(data, a.period),
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估ATR指标条件，阈值为ATR的绝对值
+func (a *ATR) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	return evaluateLevel("ATR", result.Values["atr"], condition, threshold)
+}
+
+// AverageTrueRange 计算每根K线的平均真实波幅：前period根真实波幅的简单平均作为初值，之后按Wilder平滑。
+// 真实波幅为 max(最高价, 前收盘价) - min(最低价, 前收盘价)，前period根K线的值为0
+func AverageTrueRange(data []datasource.StockData, period int) []float64 {
+	atr := make([]float64, len(data))
+	if period <= 0 || len(data) < period+1 {
+		return atr
+	}
+
+	trueRange := func(i int) float64 {
+		prevClose := data[i-1].Close
+		return math.Max(data[i].High, prevClose) - math.Min(data[i].Low, prevClose)
+	}
+
+	var sum float64
+	for i := 1; i <= period; i++ {
+		sum += trueRange(i)
+	}
+	atr[period] = sum / float64(period)
+	for i := period + 1; i < len(data); i++ {
+		atr[i] = (atr[i-1]*float64(period-1) + trueRange(i)) / float64(period)
+	}
+	return atr
+}
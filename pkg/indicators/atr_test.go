@@ -0,0 +1,23 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestATR(t *testing.T) {
+	// 收盘价10、12、11、15，最高/最低价为收盘价±1，真实波幅为3、2、5
+	data := flatBars([]float64{10, 12, 11, 15}, make([]int64, 4))
+	atr, _ := NewATR(IndicatorParams{"period": 2})
+	result, err := atr.Calculate(data)
+	if err != nil {
+		t.Fatalf("计算ATR失败: %v", err)
+	}
+	values := result.Values["atr"]
+	if values[1] != 0 || values[2] != 2.5 || math.Abs(values[3]-3.75) > 1e-9 {
+		t.Errorf("ATR = %v", values)
+	}
+	if _, err := atr.Calculate(data[:2]); err == nil {
+		t.Error("K线不足时应返回错误")
+	}
+}
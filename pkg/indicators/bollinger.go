@@ -12,6 +12,7 @@ import (
 type BollingerBands struct {
 	period int
 	stdDev float64
+	source string
 }
 
 // NewBollingerBands 创建一个新的布林带指标
@@ -28,9 +29,15 @@ func NewBollingerBands(params IndicatorParams) (Indicator, error) {
 		return nil, fmt.Errorf("standard deviation must be positive")
 	}
 
+	source, err := params.GetSource()
+	if err != nil {
+		return nil, err
+	}
+
 	return &BollingerBands{
 		period: period,
 		stdDev: stdDev,
+		source: source,
 	}, nil
 }
 
@@ -46,11 +53,11 @@ func (b *BollingerBands) Calculate(data []datasource.StockData) (IndicatorResult
 			b.period, len(data))
 	}
 
-	// 提取收盘价
+	// 提取输入序列，默认为收盘价
 	prices := make([]float64, len(data))
 	dates := make([]string, len(data))
 	for i, bar := range data {
-		prices[i] = bar.Close
+		prices[i] = SourceValue(bar, b.source)
 		dates[i] = bar.Timestamp.Format(time.RFC3339)
 	}
 
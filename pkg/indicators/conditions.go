@@ -0,0 +1,36 @@
+package indicators
+
+import (
+	"fmt"
+)
+
+// evaluateLevel 评估振荡类指标相对阈值的常用条件：高于或低于阈值、上穿或下穿阈值、增加或减少（内部函数）
+// 用于零轴穿越时阈值为0
+func evaluateLevel(name string, values []float64, condition string, threshold float64) (bool, error) {
+	if len(values) == 0 {
+		return false, fmt.Errorf("%s result is empty", name)
+	}
+	idx := len(values) - 1
+	prevIdx := idx - 1
+	if prevIdx < 0 {
+		return false, fmt.Errorf("not enough data points for %s condition evaluation", name)
+	}
+
+	value, prev := values[idx], values[prevIdx]
+	switch condition {
+	case ConditionAboveThreshold:
+		return value > threshold, nil
+	case ConditionBelowThreshold:
+		return value < threshold, nil
+	case ConditionCrossAbove:
+		return prev <= threshold && value > threshold, nil
+	case ConditionCrossBelow:
+		return prev >= threshold && value < threshold, nil
+	case ConditionIncreasing:
+		return value > prev, nil
+	case ConditionDecreasing:
+		return value < prev, nil
+	default:
+		return false, fmt.Errorf("unsupported condition for %s: %s", name, condition)
+	}
+}
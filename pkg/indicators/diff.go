@@ -0,0 +1,166 @@
+package indicators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DiffOptions 表示策略对比的选项，零值字段使用默认值
+type DiffOptions struct {
+	Timeframe  string // 为空时使用扫描器的默认周期
+	WarmupDays int    // 在区间之前多取的天数，用于指标预热，默认100
+	LatestOnly bool   // 只比较区间内最后一根K线，与一轮实时扫描相同
+}
+
+// Trigger 表示一个触发的条件
+type Trigger struct {
+	Symbol    string    `json:"symbol"`
+	Timestamp time.Time `json:"timestamp"`
+	Indicator string    `json:"indicator"`
+	Condition string    `json:"condition"`
+	Side      string    `json:"side"` // buy 或 sell
+	Score     float64   `json:"score"`
+}
+
+// StrategyDiff 表示当前策略和修改后的策略在相同数据上的信号差异
+type StrategyDiff struct {
+	Current   string            `json:"current"`
+	Proposed  string            `json:"proposed"`
+	Symbols   int               `json:"symbols"`   // 成功对比的股票数
+	Bars      int               `json:"bars"`      // 对比的K线数
+	Added     []Trigger         `json:"added"`     // 只有修改后的策略触发，按时间和股票排序
+	Removed   []Trigger         `json:"removed"`   // 只有当前策略触发
+	Unchanged int               `json:"unchanged"` // 两者都触发的条件数
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// Changed 检查两个策略的信号是否有差异
+func (d *StrategyDiff) Changed() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0
+}
+
+// DiffStrategies 以试运行方式在相同的K线上并排评估当前策略和修改后的策略，返回新增和消失的触发条件，
+// 用于在上线前审查策略修改。逐根评估区间内的K线（LatestOnly 时只评估最后一根），不发布事件，
+// 与回测相同，不评估市场过滤条件和股票范围的过滤表达式。单只股票失败时记录在 Errors 中，所有股票都失败时返回错误
+func (s *Scanner) DiffStrategies(ctx context.Context, current, proposed Strategy, symbols []string, from, to time.Time, opts DiffOptions) (*StrategyDiff, error) {
+	if opts.Timeframe == "" {
+		opts.Timeframe = s.defaultTimeframe
+	}
+	if opts.WarmupDays <= 0 {
+		opts.WarmupDays = 100
+	}
+
+	diff := &StrategyDiff{Current: current.Name, Proposed: proposed.Name, Errors: make(map[string]string)}
+	for _, symbol := range symbols {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		bars, err := s.dataManager.GetStockData(ctx, symbol, opts.Timeframe, from.AddDate(0, 0, -opts.WarmupDays), to)
+		if err != nil {
+			diff.Errors[symbol] = err.Error()
+			continue
+		}
+		start := sort.Search(len(bars), func(i int) bool { return !bars[i].Timestamp.Before(from) })
+		if start >= len(bars) {
+			diff.Errors[symbol] = "no bars in diff period"
+			continue
+		}
+		if opts.LatestOnly {
+			start = len(bars) - 1
+		}
+
+		// 先在全部K线上评估一次，尽早发现指标配置错误；之后K线不足导致的错误视为预热期
+		if _, err := s.EvaluateStrategy(symbol, current, bars); err != nil {
+			diff.Errors[symbol] = fmt.Sprintf("current strategy: %v", err)
+			continue
+		}
+		if _, err := s.EvaluateStrategy(symbol, proposed, bars); err != nil {
+			diff.Errors[symbol] = fmt.Sprintf("proposed strategy: %v", err)
+			continue
+		}
+
+		for i := start; i < len(bars); i++ {
+			before, _ := s.EvaluateStrategy(symbol, current, bars[:i+1])
+			after, _ := s.EvaluateStrategy(symbol, proposed, bars[:i+1])
+			added, removed, unchanged := diffTriggers(triggersOf(before), triggersOf(after))
+			diff.Added = append(diff.Added, added...)
+			diff.Removed = append(diff.Removed, removed...)
+			diff.Unchanged += unchanged
+			diff.Bars++
+		}
+		diff.Symbols++
+	}
+
+	if diff.Symbols == 0 && len(symbols) > 0 {
+		return diff, errors.New("strategy diff failed for all symbols")
+	}
+	sortTriggers(diff.Added)
+	sortTriggers(diff.Removed)
+	return diff, nil
+}
+
+// triggersOf 把扫描结果转换为触发的条件（内部函数）
+func triggersOf(results []ScanResult) []Trigger {
+	triggers := make([]Trigger, 0, len(results))
+	for _, result := range results {
+		side := "buy"
+		if result.IsSellSignal {
+			side = "sell"
+		}
+		triggers = append(triggers, Trigger{
+			Symbol:    result.Symbol,
+			Timestamp: result.Timestamp,
+			Indicator: result.IndicatorName,
+			Condition: result.Condition,
+			Side:      side,
+			Score:     result.Score,
+		})
+	}
+	return triggers
+}
+
+// diffTriggers 比较同一根K线上的触发条件，按指标、条件和方向匹配（内部函数）
+func diffTriggers(before, after []Trigger) (added, removed []Trigger, unchanged int) {
+	type key struct{ indicator, condition, side string }
+	remaining := make(map[key]int, len(before))
+	for _, t := range before {
+		remaining[key{t.Indicator, t.Condition, t.Side}]++
+	}
+	for _, t := range after {
+		k := key{t.Indicator, t.Condition, t.Side}
+		if remaining[k] > 0 {
+			remaining[k]--
+			unchanged++
+			continue
+		}
+		added = append(added, t)
+	}
+	for _, t := range before {
+		k := key{t.Indicator, t.Condition, t.Side}
+		if remaining[k] > 0 {
+			remaining[k]--
+			removed = append(removed, t)
+		}
+	}
+	return added, removed, unchanged
+}
+
+// sortTriggers 按时间、股票、指标和条件排序（内部函数）
+func sortTriggers(triggers []Trigger) {
+	sort.SliceStable(triggers, func(i, j int) bool {
+		a, b := triggers[i], triggers[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		if a.Indicator != b.Indicator {
+			return a.Indicator < b.Indicator
+		}
+		return a.Condition < b.Condition
+	})
+}
@@ -0,0 +1,62 @@
+package indicators_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+func TestDiffStrategies(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	from, to := start, start.AddDate(0, 0, 40)
+
+	source := testutil.NewMockDataSource("")
+	source.SetBars("AAPL", "day", testutil.GenerateBars("AAPL", start, 24*time.Hour, 30, 100, 1))
+	scanner := indicators.NewScanner(indicators.NewIndicatorRegistry(), testutil.NewManager(source))
+
+	// 收盘价为 100+i，第i根K线的5日ROC为 500/(95+i)
+	current := indicators.Strategy{Name: "trend", Indicators: []indicators.IndicatorConfig{
+		{Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5},
+			BuyCondition: indicators.ConditionAboveThreshold},
+	}}
+	proposed := indicators.Strategy{Name: "trend_v2", Indicators: []indicators.IndicatorConfig{
+		{Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5},
+			BuyCondition: indicators.ConditionAboveThreshold, BuyThreshold: 4.5,
+			SellCondition: indicators.ConditionBelowThreshold, SellThreshold: 4.6},
+	}}
+
+	diff, err := scanner.DiffStrategies(ctx, current, proposed, []string{"AAPL", "MSFT"}, from, to, indicators.DiffOptions{})
+	if err != nil {
+		t.Fatalf("对比失败: %v", err)
+	}
+	// 买入：当前在第5-29根触发，修改后只在第5-16根；卖出：修改后在第14-29根触发
+	if diff.Symbols != 1 || diff.Bars != 30 || diff.Unchanged != 12 || len(diff.Removed) != 13 || len(diff.Added) != 16 {
+		t.Fatalf("差异不正确: symbols=%d bars=%d unchanged=%d removed=%d added=%d",
+			diff.Symbols, diff.Bars, diff.Unchanged, len(diff.Removed), len(diff.Added))
+	}
+	if !diff.Changed() || diff.Removed[0].Side != "buy" || !diff.Removed[0].Timestamp.Equal(start.AddDate(0, 0, 17)) {
+		t.Errorf("第一个消失的触发应为第17根K线的买入: %+v", diff.Removed[0])
+	}
+	if diff.Added[0].Side != "sell" || !diff.Added[0].Timestamp.Equal(start.AddDate(0, 0, 14)) {
+		t.Errorf("第一个新增的触发应为第14根K线的卖出: %+v", diff.Added[0])
+	}
+	if _, ok := diff.Errors["MSFT"]; !ok {
+		t.Error("没有K线的股票应记录错误")
+	}
+
+	latest, err := scanner.DiffStrategies(ctx, current, current, []string{"AAPL"}, from, to, indicators.DiffOptions{LatestOnly: true})
+	if err != nil || latest.Bars != 1 || latest.Changed() || latest.Unchanged != 1 {
+		t.Errorf("相同策略只比较最后一根K线时应没有差异: %+v %v", latest, err)
+	}
+
+	broken := indicators.Strategy{Name: "broken", Indicators: []indicators.IndicatorConfig{
+		{Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5}, BuyCondition: "sideways"},
+	}}
+	if _, err := scanner.DiffStrategies(ctx, current, broken, []string{"AAPL"}, from, to, indicators.DiffOptions{}); err == nil {
+		t.Error("修改后的策略配置错误时应返回错误")
+	}
+}
@@ -0,0 +1,73 @@
+package indicators
+
+import (
+	"math"
+	"sort"
+)
+
+// SignalExplanation 说明信号为什么触发：评估时的最新价格、策略中每个指标的最新值和各条件的评估结果。
+// 同一只股票一次评估产生的信号共享同一个说明
+type SignalExplanation struct {
+	Price      float64                `json:"price"`
+	Indicators []IndicatorExplanation `json:"indicators"`
+}
+
+// IndicatorExplanation 表示一个指标在最新K线上的值和条件评估结果
+type IndicatorExplanation struct {
+	Name       string             `json:"name"` // 指标配置的名称，为空时为类型
+	Type       string             `json:"type"`
+	Weight     float64            `json:"weight"`
+	Values     map[string]float64 `json:"values"` // 各输出的最新值，尚无有效值（如预热不足）的输出不包含在内
+	Conditions []ConditionCheck   `json:"conditions"`
+}
+
+// ConditionCheck 表示一个买入或卖出条件的评估结果
+type ConditionCheck struct {
+	Side      string  `json:"side"` // buy 或 sell
+	Condition string  `json:"condition"`
+	Threshold float64 `json:"threshold"`
+	Passed    bool    `json:"passed"`
+}
+
+// Passed 返回通过的条件，格式为 <指标名称>.<条件>
+func (e *SignalExplanation) Passed() []string {
+	var passed []string
+	for _, ind := range e.Indicators {
+		for _, check := range ind.Conditions {
+			if check.Passed {
+				passed = append(passed, ind.Name+"."+check.Condition)
+			}
+		}
+	}
+	return passed
+}
+
+// newIndicatorExplanation 记录指标各输出的最新有效值（内部函数）
+func newIndicatorExplanation(config IndicatorConfig, result IndicatorResult) IndicatorExplanation {
+	name := config.Name
+	if name == "" {
+		name = config.Type
+	}
+	explanation := IndicatorExplanation{
+		Name:   name,
+		Type:   config.Type,
+		Weight: config.Weight,
+		Values: make(map[string]float64, len(result.Values)),
+	}
+	keys := make([]string, 0, len(result.Values))
+	for key := range result.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		series := result.Values[key]
+		if len(series) == 0 {
+			continue
+		}
+		// NaN和无穷大不能编码为JSON
+		if v := series[len(series)-1]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+			explanation.Values[key] = v
+		}
+	}
+	return explanation
+}
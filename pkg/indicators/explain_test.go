@@ -0,0 +1,61 @@
+package indicators
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSignalExplanation(t *testing.T) {
+	closes := make([]float64, 30)
+	volumes := make([]int64, 30)
+	for i := range closes {
+		closes[i] = 100 + float64(i)
+		volumes[i] = 1000
+	}
+	data := flatBars(closes, volumes)
+
+	scanner := NewScanner(NewIndicatorRegistry(), nil)
+	strategy := Strategy{
+		Name: "trend",
+		Indicators: []IndicatorConfig{
+			{Name: "roc5", Type: IndicatorTypeROC, Parameters: IndicatorParams{"period": 5},
+				BuyCondition: ConditionAboveThreshold, SellCondition: ConditionBelowThreshold, Weight: 2},
+			{Type: IndicatorTypeRSI, Parameters: IndicatorParams{"period": 14}, BuyCondition: ConditionBelowThreshold, BuyThreshold: 30},
+		},
+	}
+	results, err := scanner.EvaluateStrategy("AAPL", strategy, data)
+	if err != nil {
+		t.Fatalf("评估策略失败: %v", err)
+	}
+	if len(results) != 1 || results[0].Explanation == nil {
+		t.Fatalf("应只有ROC的买入信号并附带说明: %+v", results)
+	}
+
+	explanation := results[0].Explanation
+	if explanation.Price != 129 || len(explanation.Indicators) != 2 {
+		t.Fatalf("说明应包含最新价格和所有指标: %+v", explanation)
+	}
+	roc, rsi := explanation.Indicators[0], explanation.Indicators[1]
+	if roc.Name != "roc5" || roc.Weight != 2 || len(roc.Conditions) != 2 || !roc.Conditions[0].Passed || roc.Conditions[1].Passed {
+		t.Errorf("ROC的条件评估不正确: %+v", roc)
+	}
+	if v, ok := roc.Values["roc"]; !ok || v <= 0 {
+		t.Errorf("ROC的最新值不正确: %v", roc.Values)
+	}
+	if rsi.Name != IndicatorTypeRSI || len(rsi.Conditions) != 1 || rsi.Conditions[0].Passed || rsi.Conditions[0].Threshold != 30 {
+		t.Errorf("未触发的RSI条件也应包含在说明中: %+v", rsi)
+	}
+	if passed := explanation.Passed(); len(passed) != 1 || passed[0] != "roc5."+ConditionAboveThreshold {
+		t.Errorf("通过的条件 = %v", passed)
+	}
+
+	// 说明随扫描结果编码为JSON
+	encoded, err := json.Marshal(results[0])
+	if err != nil {
+		t.Fatalf("编码扫描结果失败: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"explanation":{"price":129`) {
+		t.Errorf("JSON应包含说明: %s", encoded)
+	}
+}
@@ -0,0 +1,170 @@
+package indicators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// SeriesColumn 表示一个指标输出序列，与K线一一对应，没有值的位置为NaN
+type SeriesColumn struct {
+	Name   string    `json:"name"` // <指标名称>.<输出名称>，如 RSI.rsi
+	Values []float64 `json:"values"`
+}
+
+// SeriesFrame 表示一只股票的K线和指标输出序列，按列存储，
+// 可以写成JSON或Arrow IPC文件（Feather V2），供外部的notebook和图表前端按扫描器的计算结果绘图
+type SeriesFrame struct {
+	Symbol    string
+	Timeframe string
+	Bars      []datasource.StockData
+	Columns   []SeriesColumn
+}
+
+// NewSeriesFrame 用K线和指标计算结果创建序列表，输出按名称排序。
+// 比K线短的输出序列按最后一根K线右对齐，前面补NaN
+func NewSeriesFrame(symbol, timeframe string, bars []datasource.StockData, results ...IndicatorResult) SeriesFrame {
+	frame := SeriesFrame{Symbol: symbol, Timeframe: timeframe, Bars: bars}
+	for _, result := range results {
+		keys := make([]string, 0, len(result.Values))
+		for key := range result.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			column := alignRight(result.Values[key], len(bars))
+			frame.Columns = append(frame.Columns, SeriesColumn{Name: result.Name + "." + key, Values: column})
+		}
+	}
+	return frame
+}
+
+// alignRight 把输出序列按最后一个值右对齐到n个位置，前面补NaN（内部函数）
+func alignRight(values []float64, n int) []float64 {
+	column := make([]float64, n)
+	shift := n - len(values)
+	for i := range column {
+		if j := i - shift; j >= 0 && j < len(values) {
+			column[i] = values[j]
+		} else {
+			column[i] = math.NaN()
+		}
+	}
+	return column
+}
+
+// seriesJSON 是序列表的JSON格式，NaN输出为null（内部类型）
+type seriesJSON struct {
+	Symbol    string                `json:"symbol"`
+	Timeframe string                `json:"timeframe,omitempty"`
+	Timestamp []time.Time           `json:"timestamp"`
+	Open      []float64             `json:"open"`
+	High      []float64             `json:"high"`
+	Low       []float64             `json:"low"`
+	Close     []float64             `json:"close"`
+	Volume    []int64               `json:"volume"`
+	Series    map[string][]*float64 `json:"series"`
+}
+
+// WriteJSON 按列写出JSON：symbol、timeframe、timestamp、open、high、low、close、volume，
+// 以及 series 中按列名索引的指标输出，NaN为null
+func (f SeriesFrame) WriteJSON(w io.Writer) error {
+	out := seriesJSON{
+		Symbol:    f.Symbol,
+		Timeframe: f.Timeframe,
+		Timestamp: make([]time.Time, len(f.Bars)),
+		Open:      make([]float64, len(f.Bars)),
+		High:      make([]float64, len(f.Bars)),
+		Low:       make([]float64, len(f.Bars)),
+		Close:     make([]float64, len(f.Bars)),
+		Volume:    make([]int64, len(f.Bars)),
+		Series:    make(map[string][]*float64, len(f.Columns)),
+	}
+	for i, bar := range f.Bars {
+		out.Timestamp[i] = bar.Timestamp
+		out.Open[i], out.High[i], out.Low[i], out.Close[i] = bar.Open, bar.High, bar.Low, bar.Close
+		out.Volume[i] = bar.Volume
+	}
+	for _, column := range f.Columns {
+		values := make([]*float64, len(column.Values))
+		for i := range column.Values {
+			if !math.IsNaN(column.Values[i]) && !math.IsInf(column.Values[i], 0) {
+				values[i] = &column.Values[i]
+			}
+		}
+		out.Series[column.Name] = values
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+// WriteArrow 写出Arrow IPC文件（Feather V2），可以用 pyarrow.feather.read_feather 或 pandas.read_feather 读取。
+// 列依次为 timestamp（UTC毫秒时间戳）、open、high、low、close、volume（int64）和指标输出（float64，缺失值为NaN）
+func (f SeriesFrame) WriteArrow(w io.Writer) error {
+	n := len(f.Bars)
+	timestamps, volumes := make([]int64, n), make([]int64, n)
+	open, high, low, close := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	for i, bar := range f.Bars {
+		timestamps[i] = bar.Timestamp.UnixMilli()
+		open[i], high[i], low[i], close[i] = bar.Open, bar.High, bar.Low, bar.Close
+		volumes[i] = bar.Volume
+	}
+
+	columns := []arrowColumn{
+		int64Column("timestamp", timestamps, true),
+		float64Column("open", open),
+		float64Column("high", high),
+		float64Column("low", low),
+		float64Column("close", close),
+		int64Column("volume", volumes, false),
+	}
+	for _, column := range f.Columns {
+		if len(column.Values) != n {
+			return fmt.Errorf("column %s has %d values, expected %d", column.Name, len(column.Values), n)
+		}
+		columns = append(columns, float64Column(column.Name, column.Values))
+	}
+	return writeArrowFile(w, columns)
+}
+
+// SeriesFrame 按策略在symbol上计算所有指标，返回与扫描时相同的K线和指标输出，用于排查信号。
+// 列名使用指标配置的名称，为空时使用指标类型
+func (s *Scanner) SeriesFrame(ctx context.Context, symbol string, strategyName string, from, to time.Time, timeframe string) (SeriesFrame, error) {
+	if timeframe == "" {
+		timeframe = s.defaultTimeframe
+	}
+
+	strategy, err := s.lookupStrategy(strategyName)
+	if err != nil {
+		return SeriesFrame{}, err
+	}
+
+	stockData, err := s.dataManager.GetStockData(ctx, symbol, timeframe, from, to)
+	if err != nil {
+		return SeriesFrame{}, fmt.Errorf("failed to get stock data: %v", err)
+	}
+
+	results := make([]IndicatorResult, 0, len(strategy.Indicators))
+	for _, indConfig := range strategy.Indicators {
+		indicator, err := s.registry.CreateIndicator(indConfig.Type, indConfig.Parameters)
+		if err != nil {
+			return SeriesFrame{}, fmt.Errorf("failed to create indicator '%s': %v", indConfig.Type, err)
+		}
+		result, err := indicator.Calculate(stockData)
+		if err != nil {
+			return SeriesFrame{}, fmt.Errorf("failed to calculate indicator '%s': %v", indConfig.Type, err)
+		}
+		if indConfig.Name != "" {
+			result.Name = indConfig.Name
+		}
+		results = append(results, result)
+	}
+
+	return NewSeriesFrame(symbol, timeframe, stockData, results...), nil
+}
@@ -0,0 +1,75 @@
+package indicators
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSeriesFrame(t *testing.T) {
+	bars := flatBars([]float64{10, 11, 12, 13}, []int64{100, 200, 300, 400})
+	frame := NewSeriesFrame("AAPL", "day", bars, IndicatorResult{
+		Name:   "ROC",
+		Values: map[string][]float64{"roc": {10, 9.09}},
+	})
+	if len(frame.Columns) != 1 || frame.Columns[0].Name != "ROC.roc" || !math.IsNaN(frame.Columns[0].Values[1]) || frame.Columns[0].Values[3] != 9.09 {
+		t.Fatalf("短序列应右对齐: %+v", frame.Columns)
+	}
+
+	var buf bytes.Buffer
+	if err := frame.WriteJSON(&buf); err != nil {
+		t.Fatalf("写出JSON失败: %v", err)
+	}
+	var decoded struct {
+		Close  []float64             `json:"close"`
+		Series map[string][]*float64 `json:"series"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("解析JSON失败: %v", err)
+	}
+	roc := decoded.Series["ROC.roc"]
+	if len(decoded.Close) != 4 || len(roc) != 4 || roc[0] != nil || roc[2] == nil || *roc[2] != 10 {
+		t.Errorf("JSON内容不正确: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := frame.WriteArrow(&buf); err != nil {
+		t.Fatalf("写出Arrow失败: %v", err)
+	}
+	file := readArrow(t, buf.Bytes())
+	want := []arrowField{
+		{name: "timestamp", typ: arrowTypeTimestamp, unit: arrowUnitMillisecond, timezone: "UTC"},
+		{name: "open", typ: arrowTypeFloat, precision: arrowPrecisionDouble},
+		{name: "high", typ: arrowTypeFloat, precision: arrowPrecisionDouble},
+		{name: "low", typ: arrowTypeFloat, precision: arrowPrecisionDouble},
+		{name: "close", typ: arrowTypeFloat, precision: arrowPrecisionDouble},
+		{name: "volume", typ: arrowTypeInt, bitWidth: 64, signed: true},
+		{name: "ROC.roc", typ: arrowTypeFloat, precision: arrowPrecisionDouble},
+	}
+	if !reflect.DeepEqual(file.fields, want) {
+		t.Fatalf("Arrow字段不正确:\n%+v\n%+v", file.fields, want)
+	}
+	if file.rows != 4 {
+		t.Fatalf("记录批次行数 = %d, 期望 4", file.rows)
+	}
+
+	// 读回的值与K线和指标列一致，NaN按位保留
+	for i, bar := range bars {
+		row := []uint64{
+			uint64(bar.Timestamp.UnixMilli()),
+			math.Float64bits(bar.Open),
+			math.Float64bits(bar.High),
+			math.Float64bits(bar.Low),
+			math.Float64bits(bar.Close),
+			uint64(bar.Volume),
+			math.Float64bits(frame.Columns[0].Values[i]),
+		}
+		for j, v := range row {
+			if file.columns[j][i] != v {
+				t.Errorf("第%d行%s = %#x, 期望 %#x", i, want[j].name, file.columns[j][i], v)
+			}
+		}
+	}
+}
@@ -23,6 +23,18 @@ func NewIndicatorRegistry() *IndicatorRegistry {
 	registry.RegisterIndicator(IndicatorTypeBollinger, NewBollingerBands)
 	registry.RegisterIndicator(IndicatorTypeEMA, NewEMA)
 	registry.RegisterIndicator(IndicatorTypeSMA, NewSMA)
+	registry.RegisterIndicator(IndicatorTypeVWAP, NewVWAP)
+	registry.RegisterIndicator(IndicatorTypeMFI, NewMFI)
+	registry.RegisterIndicator(IndicatorTypeChaikin, NewChaikinOscillator)
+	registry.RegisterIndicator(IndicatorTypeROC, NewROC)
+	registry.RegisterIndicator(IndicatorTypeMomentum, NewMomentum)
+	registry.RegisterIndicator(IndicatorTypeWilliamsR, NewWilliamsR)
+	registry.RegisterIndicator(IndicatorTypeCCI, NewCCI)
+	registry.RegisterIndicator(IndicatorTypeUltimate, NewUltimateOscillator)
+	registry.RegisterIndicator(IndicatorTypePivotPoints, NewPivotPoints)
+	registry.RegisterIndicator(IndicatorTypeSwing, NewSwing)
+	registry.RegisterIndicator(IndicatorTypeATR, NewATR)
+	registry.RegisterIndicator(IndicatorTypeModel, NewModelFactory(registry))
 	
 	return registry
 }
@@ -0,0 +1,120 @@
+package indicators
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// 市场过滤条件作用的信号
+const (
+	FilterSignalsAll  = ""     // 买入和卖出信号
+	FilterSignalsBuy  = "buy"  // 只过滤买入信号
+	FilterSignalsSell = "sell" // 只过滤卖出信号
+)
+
+// MarketFilter 表示引用其他股票的条件（如"SPY高于其200日均线"），作为策略所有信号的全局开关：
+// 条件不满足时，该策略在所有股票上的相应信号都被丢弃
+type MarketFilter struct {
+	Symbol     string          `json:"symbol" yaml:"symbol"`
+	Type       string          `json:"type" yaml:"type"`
+	Parameters IndicatorParams `json:"parameters" yaml:"parameters"`
+	Condition  string          `json:"condition" yaml:"condition"`
+	// Threshold 为条件的阈值；SMA和EMA按阈值作为价格比较，为0时使用过滤股票的最新收盘价
+	Threshold    float64 `json:"threshold" yaml:"threshold"`
+	Timeframe    string  `json:"timeframe" yaml:"timeframe"`         // 为空时使用扫描的周期
+	LookbackDays int     `json:"lookback_days" yaml:"lookback_days"` // 获取数据的天数，为0时使用扫描的时间范围
+	Signals      string  `json:"signals" yaml:"signals"`             // buy、sell，为空时过滤所有信号
+}
+
+// filterGate 表示一轮扫描中市场过滤条件允许的信号和策略股票范围的过滤条件（内部类型）
+type filterGate struct {
+	buy          bool
+	sell         bool
+	universe     *UniverseFilter // 为空时不过滤股票
+	sectors      Universe        // 只使用 Sectors
+	fundamentals bool            // 过滤条件需要基本面数据
+}
+
+// openGate 是没有过滤条件时的开关（内部变量）
+var openGate = filterGate{buy: true, sell: true}
+
+// allows 检查扫描结果是否被允许（内部方法）
+func (g filterGate) allows(result ScanResult) bool {
+	return (result.IsBuySignal && g.buy) || (result.IsSellSignal && g.sell)
+}
+
+// includes 检查股票的K线和基本面数据是否满足策略股票范围的过滤表达式和行业（内部方法）
+func (g filterGate) includes(data []datasource.StockData, fundamentals *datasource.Fundamentals) bool {
+	if len(g.sectors.Sectors) > 0 && (fundamentals == nil || !g.sectors.MatchSector(fundamentals.Sector)) {
+		return false
+	}
+	return g.universe == nil || g.universe.MatchFundamentals(data, fundamentals)
+}
+
+// evaluateFilters 评估策略的所有市场过滤条件，每个过滤股票只获取一次数据，并解析股票范围的过滤表达式（内部方法）
+func (s *Scanner) evaluateFilters(ctx context.Context, strategy Strategy, from, to time.Time, timeframe string) (filterGate, error) {
+	gate := openGate
+	if strategy.Universe.Filter != "" {
+		universe, err := ParseUniverseFilter(strategy.Universe.Filter)
+		if err != nil {
+			return filterGate{}, err
+		}
+		gate.universe = universe
+	}
+	gate.sectors = Universe{Sectors: strategy.Universe.Sectors}
+	gate.fundamentals = strategy.Universe.NeedsFundamentals()
+	for _, filter := range strategy.Filters {
+		passed, err := s.evaluateFilter(ctx, filter, from, to, timeframe)
+		if err != nil {
+			return filterGate{}, fmt.Errorf("market filter %s %s: %v", filter.Symbol, filter.Type, err)
+		}
+		if passed {
+			continue
+		}
+		switch filter.Signals {
+		case FilterSignalsBuy:
+			gate.buy = false
+		case FilterSignalsSell:
+			gate.sell = false
+		default:
+			gate.buy, gate.sell = false, false
+		}
+	}
+	return gate, nil
+}
+
+// evaluateFilter 获取过滤股票的数据并评估条件（内部方法）
+func (s *Scanner) evaluateFilter(ctx context.Context, filter MarketFilter, from, to time.Time, timeframe string) (bool, error) {
+	if filter.Timeframe != "" {
+		timeframe = filter.Timeframe
+	}
+	if filter.LookbackDays > 0 {
+		from = to.AddDate(0, 0, -filter.LookbackDays)
+	}
+
+	data, err := s.dataManager.GetStockData(ctx, filter.Symbol, timeframe, from, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to get stock data: %v", err)
+	}
+	if len(data) == 0 {
+		return false, fmt.Errorf("no stock data available")
+	}
+
+	indicator, err := s.registry.CreateIndicator(filter.Type, filter.Parameters)
+	if err != nil {
+		return false, err
+	}
+	result, err := indicator.Calculate(data)
+	if err != nil {
+		return false, err
+	}
+
+	threshold := filter.Threshold
+	if threshold == 0 && (filter.Type == IndicatorTypeSMA || filter.Type == IndicatorTypeEMA) {
+		threshold = data[len(data)-1].Close
+	}
+	return indicator.EvaluateCondition(result, filter.Condition, threshold)
+}
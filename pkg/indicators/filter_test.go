@@ -0,0 +1,59 @@
+package indicators_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+func TestMarketFilter(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	from, to := start, start.AddDate(0, 0, 30)
+
+	source := testutil.NewMockDataSource("")
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		source.SetBars(symbol, "day", testutil.GenerateBars(symbol, start, 24*time.Hour, 20, 100, 1))
+	}
+	source.SetBars("SPY", "day", testutil.GenerateBars("SPY", start, 24*time.Hour, 20, 500, -1))
+
+	scanner := indicators.NewScanner(indicators.NewIndicatorRegistry(), testutil.NewManager(source))
+	scanner.AddStrategy(indicators.Strategy{
+		Name:    "trend",
+		Enabled: true,
+		Indicators: []indicators.IndicatorConfig{
+			{Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5},
+				BuyCondition: indicators.ConditionAboveThreshold, SellCondition: indicators.ConditionAboveThreshold},
+		},
+		Filters: []indicators.MarketFilter{
+			{Symbol: "SPY", Type: indicators.IndicatorTypeSMA, Parameters: indicators.IndicatorParams{"period": 10},
+				Condition: indicators.ConditionAboveThreshold, Signals: indicators.FilterSignalsBuy},
+		},
+	})
+
+	// SPY低于其均线，只丢弃买入信号
+	results, err := scanner.ScanMultipleSymbols(ctx, []string{"AAPL", "MSFT"}, "trend", from, to, "day")
+	if err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("两只股票都应有卖出信号: %+v", results)
+	}
+	for symbol, signals := range results {
+		if len(signals) != 1 || !signals[0].IsSellSignal {
+			t.Errorf("%s 的买入信号应被过滤: %+v", symbol, signals)
+		}
+	}
+	if calls := source.Calls("GetStockData"); calls != 3 {
+		t.Errorf("获取数据次数 = %d, 期望 3（过滤股票只获取一次）", calls)
+	}
+
+	source.SetBars("SPY", "day", testutil.GenerateBars("SPY", start, 24*time.Hour, 20, 500, 1))
+	signals, err := scanner.ScanSymbol(ctx, "AAPL", "trend", from, to, "day")
+	if err != nil || len(signals) != 2 {
+		t.Errorf("SPY高于均线时应输出所有信号: %+v %v", signals, err)
+	}
+}
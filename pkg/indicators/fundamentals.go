@@ -0,0 +1,38 @@
+package indicators
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// FundamentalsMaxAge 是扫描器缓存基本面数据的时长，基本面数据按季度更新，市值随股价变化，每天刷新一次足够用于选股
+const FundamentalsMaxAge = 24 * time.Hour
+
+// cachedFundamentals 是缓存的基本面数据（内部类型）
+type cachedFundamentals struct {
+	fundamentals datasource.Fundamentals
+	fetchedAt    time.Time
+}
+
+// getFundamentals 获取股票的基本面数据，缓存未超过 FundamentalsMaxAge 时直接返回缓存（内部方法）
+func (s *Scanner) getFundamentals(ctx context.Context, symbol string) (*datasource.Fundamentals, error) {
+	now := time.Now()
+	s.fundMu.Lock()
+	cached, ok := s.fundamentals[symbol]
+	s.fundMu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < FundamentalsMaxAge {
+		fundamentals := cached.fundamentals
+		return &fundamentals, nil
+	}
+
+	fundamentals, err := s.dataManager.GetFundamentals(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	s.fundMu.Lock()
+	s.fundamentals[symbol] = cachedFundamentals{fundamentals: *fundamentals, fetchedAt: now}
+	s.fundMu.Unlock()
+	return fundamentals, nil
+}
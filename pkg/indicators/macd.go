@@ -12,6 +12,7 @@ type MACD struct {
 	fastPeriod   int
 	slowPeriod   int
 	signalPeriod int
+	source       string
 }
 
 // NewMACD 创建一个新的MACD指标
@@ -30,10 +31,16 @@ func NewMACD(params IndicatorParams) (Indicator, error) {
 		return nil, fmt.Errorf("fast period must be less than slow period")
 	}
 
+	source, err := params.GetSource()
+	if err != nil {
+		return nil, err
+	}
+
 	return &MACD{
 		fastPeriod:   fastPeriod,
 		slowPeriod:   slowPeriod,
 		signalPeriod: signalPeriod,
+		source:       source,
 	}, nil
 }
 
@@ -49,11 +56,11 @@ func (m *MACD) Calculate(data []datasource.StockData) (IndicatorResult, error) {
 			m.slowPeriod+m.signalPeriod, len(data))
 	}
 
-	// 提取收盘价
+	// 提取输入序列，默认为收盘价
 	prices := make([]float64, len(data))
 	dates := make([]string, len(data))
 	for i, bar := range data {
-		prices[i] = bar.Close
+		prices[i] = SourceValue(bar, m.source)
 		dates[i] = bar.Timestamp.Format(time.RFC3339)
 	}
 
@@ -0,0 +1,219 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// DefaultModelThreshold 是模型买入/卖出得分的默认阈值
+const DefaultModelThreshold = 0.5
+
+// Model 是基于ONNX模型推理的信号指标：把特征指标在每根K线上的值组成特征向量输入模型，
+// 输出买入和卖出得分。与规则指标一样配置在策略中，按权重与其他指标的信号合并
+type Model struct {
+	model    *onnxModel
+	output   string
+	features []modelFeature
+}
+
+// modelFeature 表示模型的一个输入特征（内部类型）
+type modelFeature struct {
+	name      string // <指标类型>.<输出名称>
+	indicator Indicator
+	output    string
+}
+
+// NewModelFactory 返回模型指标的工厂，特征指标由registry创建，因此可以使用自定义注册的指标。参数：
+//   - model：ONNX模型文件路径
+//   - features：特征列表，按模型输入的顺序，每项包含 type、parameters 和 output（指标输出名称）
+//   - output：使用的模型输出名称，默认为第一个输出
+//
+// 模型输入形状为 [1, 特征数]。输出只有一个值时视为上涨概率，买入得分为该值、卖出得分为1减该值；
+// 有多个值时（如二分类或 [跌, 平, 涨] 三分类的概率）第一个为卖出得分、最后一个为买入得分
+func NewModelFactory(registry *IndicatorRegistry) IndicatorFactory {
+	return func(params IndicatorParams) (Indicator, error) {
+		path := params.GetString("model", "")
+		if path == "" {
+			return nil, fmt.Errorf("model path is required")
+		}
+		model, err := loadONNXModel(path)
+		if err != nil {
+			return nil, err
+		}
+
+		output := params.GetString("output", model.outputs[0])
+		known := false
+		for _, name := range model.outputs {
+			known = known || name == output
+		}
+		if !known {
+			return nil, fmt.Errorf("model has no output %s", output)
+		}
+
+		items, ok := params["features"].([]interface{})
+		if !ok || len(items) == 0 {
+			return nil, fmt.Errorf("model features are required")
+		}
+		features := make([]modelFeature, 0, len(items))
+		for i, item := range items {
+			feature, err := parseModelFeature(registry, item)
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %v", i, err)
+			}
+			features = append(features, feature)
+		}
+		if dims := model.inputDims; len(dims) > 0 && dims[len(dims)-1] > 0 && int(dims[len(dims)-1]) != len(features) {
+			return nil, fmt.Errorf("model expects %d features, got %d", dims[len(dims)-1], len(features))
+		}
+
+		return &Model{model: model, output: output, features: features}, nil
+	}
+}
+
+// parseModelFeature 解析一项特征配置并创建特征指标（内部函数）
+func parseModelFeature(registry *IndicatorRegistry, item interface{}) (modelFeature, error) {
+	config, ok := toStringMap(item)
+	if !ok {
+		return modelFeature{}, fmt.Errorf("feature must be a map with type, parameters and output")
+	}
+	typ, _ := config["type"].(string)
+	output, _ := config["output"].(string)
+	if typ == "" || output == "" {
+		return modelFeature{}, fmt.Errorf("type and output are required")
+	}
+	params := IndicatorParams{}
+	if raw, ok := toStringMap(config["parameters"]); ok {
+		params = raw
+	}
+
+	indicator, err := registry.CreateIndicator(typ, params)
+	if err != nil {
+		return modelFeature{}, err
+	}
+	return modelFeature{name: typ + "." + output, indicator: indicator, output: output}, nil
+}
+
+// toStringMap 把YAML或JSON解码得到的映射转换为 map[string]interface{}（内部函数）
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case IndicatorParams:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, val := range m {
+			out[fmt.Sprint(k)] = val
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// Name 返回指标名称
+func (m *Model) Name() string {
+	return IndicatorTypeModel
+}
+
+// Calculate 在每根K线上计算特征并运行模型，输出 buy、sell 得分和各特征值（键为 <指标类型>.<输出名称>）。
+// 特征不完整（如预热不足）的K线得分为NaN
+func (m *Model) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	if len(data) == 0 {
+		return IndicatorResult{}, fmt.Errorf("not enough data points for model inference")
+	}
+
+	n := len(data)
+	values := make(map[string][]float64, len(m.features)+2)
+	columns := make([][]float64, len(m.features))
+	for i, f := range m.features {
+		result, err := f.indicator.Calculate(data)
+		if err != nil {
+			return IndicatorResult{}, fmt.Errorf("failed to calculate feature %s: %v", f.name, err)
+		}
+		series, ok := result.Values[f.output]
+		if !ok {
+			return IndicatorResult{}, fmt.Errorf("feature indicator %s has no output %s", f.indicator.Name(), f.output)
+		}
+		columns[i] = alignRight(series, n)
+		values[f.name] = columns[i]
+	}
+
+	buy, sell := make([]float64, n), make([]float64, n)
+	dates := make([]string, n)
+	input := &onnxTensor{shape: []int{1, len(columns)}, data: make([]float64, len(columns))}
+	for i, bar := range data {
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+		buy[i], sell[i] = math.NaN(), math.NaN()
+
+		complete := true
+		for j, column := range columns {
+			input.data[j] = column[i]
+			complete = complete && !math.IsNaN(column[i]) && !math.IsInf(column[i], 0)
+		}
+		if !complete {
+			continue
+		}
+		out, err := m.model.run(input, m.output)
+		if err != nil {
+			return IndicatorResult{}, fmt.Errorf("model inference failed: %v", err)
+		}
+		switch len(out.data) {
+		case 0:
+			return IndicatorResult{}, fmt.Errorf("model output %s is empty", m.output)
+		case 1:
+			buy[i], sell[i] = out.data[0], 1-out.data[0]
+		default:
+			buy[i], sell[i] = out.data[len(out.data)-1], out.data[0]
+		}
+	}
+	values["buy"], values["sell"] = buy, sell
+
+	return IndicatorResult{
+		Name:   m.Name(),
+		Values: values,
+		Dates:  dates,
+	}, nil
+}
+
+// EvaluateCondition 评估模型条件，model_buy 和 model_sell 为最新K线的买入或卖出得分不低于阈值，阈值为0时使用0.5
+func (m *Model) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	score, err := m.score(result, condition)
+	if err != nil {
+		return false, err
+	}
+	if threshold <= 0 {
+		threshold = DefaultModelThreshold
+	}
+	return !math.IsNaN(score) && score >= threshold, nil
+}
+
+// SignalStrength 返回最新K线的买入或卖出得分，扫描器用它按模型置信度缩放信号权重
+func (m *Model) SignalStrength(result IndicatorResult, condition string) float64 {
+	score, err := m.score(result, condition)
+	if err != nil || math.IsNaN(score) {
+		return 0
+	}
+	return math.Max(0, math.Min(1, score))
+}
+
+// score 返回条件对应的最新得分（内部方法）
+func (m *Model) score(result IndicatorResult, condition string) (float64, error) {
+	var key string
+	switch condition {
+	case ConditionModelBuy:
+		key = "buy"
+	case ConditionModelSell:
+		key = "sell"
+	default:
+		return 0, fmt.Errorf("unsupported condition for model: %s", condition)
+	}
+	series := result.Values[key]
+	if len(series) == 0 {
+		return 0, fmt.Errorf("model result is empty")
+	}
+	return series[len(series)-1], nil
+}
@@ -0,0 +1,185 @@
+package indicators
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 以下函数按ONNX的protobuf定义手工编码测试模型（内部函数）
+
+func protoBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func protoVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// floatTensor 编码float张量，packed为true时写入 float_data，否则写入 raw_data
+func floatTensor(name string, dims []int64, values []float32, packed bool) []byte {
+	var b []byte
+	for _, d := range dims {
+		b = protoVarint(b, 1, uint64(d))
+	}
+	b = protoVarint(b, 2, onnxFloat)
+	var data []byte
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(v))
+	}
+	if packed {
+		b = protoBytes(b, 4, data)
+	} else {
+		b = protoBytes(b, 9, data)
+	}
+	return protoBytes(b, 8, []byte(name))
+}
+
+func onnxNodeProto(op string, inputs, outputs []string, attrs ...[]byte) []byte {
+	var b []byte
+	for _, in := range inputs {
+		b = protoBytes(b, 1, []byte(in))
+	}
+	for _, out := range outputs {
+		b = protoBytes(b, 2, []byte(out))
+	}
+	b = protoBytes(b, 4, []byte(op))
+	for _, attr := range attrs {
+		b = protoBytes(b, 5, attr)
+	}
+	return b
+}
+
+func intAttrProto(name string, v int64) []byte {
+	b := protoBytes(nil, 1, []byte(name))
+	b = protoVarint(b, 3, uint64(v))
+	return protoVarint(b, 20, 2)
+}
+
+func valueInfoProto(name string, dims ...int64) []byte {
+	var shape []byte
+	for _, d := range dims {
+		shape = protoBytes(shape, 1, protoVarint(nil, 1, uint64(d)))
+	}
+	tensorType := protoVarint(nil, 1, onnxFloat)
+	tensorType = protoBytes(tensorType, 2, shape)
+	b := protoBytes(nil, 1, []byte(name))
+	return protoBytes(b, 2, protoBytes(nil, 1, tensorType))
+}
+
+// writeTestModel 写出一个两层网络：(x-mean) -> Gemm -> Relu -> MatMul -> Add -> Softmax
+func writeTestModel(t *testing.T, op string) string {
+	var graph []byte
+	graph = protoBytes(graph, 1, onnxNodeProto("Sub", []string{"x", "mean"}, []string{"centered"}))
+	graph = protoBytes(graph, 1, onnxNodeProto("Gemm", []string{"centered", "w1", "b1"}, []string{"h"}, intAttrProto("transB", 1)))
+	graph = protoBytes(graph, 1, onnxNodeProto(op, []string{"h"}, []string{"a"}))
+	graph = protoBytes(graph, 1, onnxNodeProto("MatMul", []string{"a", "w2"}, []string{"logits0"}))
+	graph = protoBytes(graph, 1, onnxNodeProto("Add", []string{"logits0", "b2"}, []string{"logits"}))
+	graph = protoBytes(graph, 1, onnxNodeProto("Softmax", []string{"logits"}, []string{"probs"}, intAttrProto("axis", 1)))
+	graph = protoBytes(graph, 2, []byte("test"))
+	graph = protoBytes(graph, 5, floatTensor("mean", []int64{2}, []float32{100, 0}, true))
+	graph = protoBytes(graph, 5, floatTensor("w1", []int64{3, 2}, []float32{0.5, -1, -0.25, 1, 1, 0}, false))
+	graph = protoBytes(graph, 5, floatTensor("b1", []int64{3}, []float32{0, 0.5, -1}, true))
+	graph = protoBytes(graph, 5, floatTensor("w2", []int64{3, 2}, []float32{-1, 1, 0.5, -0.5, 0, 2}, false))
+	graph = protoBytes(graph, 5, floatTensor("b2", []int64{2}, []float32{0.1, -0.1}, false))
+	graph = protoBytes(graph, 11, valueInfoProto("x", 1, 2))
+	graph = protoBytes(graph, 12, valueInfoProto("probs", 1, 2))
+
+	model := protoVarint(nil, 1, 8)
+	model = protoBytes(model, 8, protoVarint(nil, 2, 13))
+	model = protoBytes(model, 7, graph)
+
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	if err := os.WriteFile(path, model, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// referenceProbs 按测试模型的权重直接计算 [卖出, 买入] 概率
+func referenceProbs(x0, x1 float64) (float64, float64) {
+	x0 -= 100
+	h := []float64{0.5*x0 - x1, -0.25*x0 + x1 + 0.5, x0 - 1}
+	for i := range h {
+		h[i] = math.Max(h[i], 0)
+	}
+	l0 := -h[0] + 0.5*h[1] + 0.1
+	l1 := h[0] - 0.5*h[1] + 2*h[2] - 0.1
+	e0, e1 := math.Exp(l0), math.Exp(l1)
+	return e0 / (e0 + e1), e1 / (e0 + e1)
+}
+
+func TestModelIndicator(t *testing.T) {
+	path := writeTestModel(t, "Relu")
+	registry := NewIndicatorRegistry()
+	params := IndicatorParams{
+		"model": path,
+		"features": []interface{}{
+			map[string]interface{}{"type": IndicatorTypeSMA, "parameters": map[string]interface{}{"period": 1}, "output": "sma"},
+			map[string]interface{}{"type": IndicatorTypeMomentum, "parameters": map[string]interface{}{"period": 1}, "output": "momentum"},
+		},
+	}
+	model, err := registry.CreateIndicator(IndicatorTypeModel, params)
+	if err != nil {
+		t.Fatalf("创建模型指标失败: %v", err)
+	}
+
+	data := flatBars([]float64{100, 101, 103, 102}, []int64{100, 100, 100, 100})
+	result, err := model.Calculate(data)
+	if err != nil {
+		t.Fatalf("模型推理失败: %v", err)
+	}
+	momentum := result.Values["Momentum.momentum"]
+	for i := range data {
+		sell, buy := referenceProbs(data[i].Close, momentum[i])
+		if math.Abs(result.Values["buy"][i]-buy) > 1e-6 || math.Abs(result.Values["sell"][i]-sell) > 1e-6 {
+			t.Errorf("第%d根K线得分 = %v/%v, 期望 %v/%v", i, result.Values["buy"][i], result.Values["sell"][i], buy, sell)
+		}
+	}
+
+	// 扫描器按模型得分缩放信号权重
+	_, buy := referenceProbs(102, momentum[3])
+	scanner := NewScanner(registry, nil)
+	strategy := Strategy{Name: "ml", Indicators: []IndicatorConfig{
+		{Type: IndicatorTypeModel, Parameters: params, BuyCondition: ConditionModelBuy, BuyThreshold: buy - 0.01, SellCondition: ConditionModelSell, SellThreshold: 0.99},
+	}}
+	signals, err := scanner.EvaluateStrategy("AAPL", strategy, data)
+	if err != nil {
+		t.Fatalf("评估策略失败: %v", err)
+	}
+	if len(signals) != 1 || !signals[0].IsBuySignal || math.Abs(signals[0].Score-buy) > 1e-6 {
+		t.Errorf("信号 = %+v, 期望得分为 %v 的买入信号", signals, buy)
+	}
+
+	params["features"] = params["features"].([]interface{})[:1]
+	if _, err := registry.CreateIndicator(IndicatorTypeModel, params); err == nil {
+		t.Error("特征数量与模型输入不一致时应返回错误")
+	}
+	params["model"] = writeTestModel(t, "Selu")
+	if _, err := registry.CreateIndicator(IndicatorTypeModel, params); err == nil {
+		t.Error("不支持的算子应返回错误")
+	}
+}
+
+func TestONNXBroadcast(t *testing.T) {
+	a := &onnxTensor{shape: []int{2, 3}, data: []float64{1, 2, 3, 4, 5, 6}}
+	b := &onnxTensor{shape: []int{2, 1}, data: []float64{10, 20}}
+	out, err := broadcast(a, b, func(x, y float64) float64 { return x + y })
+	if err != nil || out.shape[0] != 2 || out.shape[1] != 3 || out.data[2] != 13 || out.data[3] != 24 {
+		t.Errorf("广播结果 = %+v %v", out, err)
+	}
+	if _, err := broadcast(a, &onnxTensor{shape: []int{2}, data: []float64{1, 2}}, func(x, y float64) float64 { return x }); err == nil {
+		t.Error("不兼容的形状应返回错误")
+	}
+
+	shape := &onnxTensor{shape: []int{2}, data: []float64{-1, 2}}
+	if out, err := (&onnxTensor{shape: []int{1, 6}, data: a.data}).reshape(shape); err != nil || out.shape[0] != 3 {
+		t.Errorf("Reshape结果 = %+v %v", out, err)
+	}
+}
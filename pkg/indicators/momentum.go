@@ -0,0 +1,193 @@
+package indicators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// ROC 变动率指标结构体，为输入序列相对period根K线之前的百分比变化
+type ROC struct {
+	period int
+	source string
+}
+
+// NewROC 创建一个新的ROC指标
+func NewROC(params IndicatorParams) (Indicator, error) {
+	period := params.GetInt("period", 12)
+
+	// 验证参数
+	if period <= 0 {
+		return nil, fmt.Errorf("period must be a positive integer")
+	}
+
+	source, err := params.GetSource()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ROC{
+		period: period,
+		source: source,
+	}, nil
+}
+
+// Name 返回指标名称
+func (r *ROC) Name() string {
+	return IndicatorTypeROC
+}
+
+// Calculate 计算ROC指标值，前period根K线和基准为0时的值为0
+func (r *ROC) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	prices, dates, err := sourceSeries(data, r.source, r.period+1, "ROC")
+	if err != nil {
+		return IndicatorResult{}, err
+	}
+
+	rocValues := make([]float64, len(prices))
+	for i := r.period; i < len(prices); i++ {
+		if base := prices[i-r.period]; base != 0 {
+			rocValues[i] = (prices[i] - base) / base * 100
+		}
+	}
+
+	return IndicatorResult{
+		Name: r.Name(),
+		Values: map[string][]float64{
+			"roc": rocValues,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估ROC指标条件，阈值为百分比，为0时 cross_above 和 cross_below 为零轴穿越
+func (r *ROC) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	return evaluateLevel("ROC", result.Values["roc"], condition, threshold)
+}
+
+// Momentum 动量指标结构体，为输入序列与period根K线之前的差值
+type Momentum struct {
+	period int
+	source string
+}
+
+// NewMomentum 创建一个新的动量指标
+func NewMomentum(params IndicatorParams) (Indicator, error) {
+	period := params.GetInt("period", 10)
+
+	// 验证参数
+	if period <= 0 {
+		return nil, fmt.Errorf("period must be a positive integer")
+	}
+
+	source, err := params.GetSource()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Momentum{
+		period: period,
+		source: source,
+	}, nil
+}
+
+// Name 返回指标名称
+func (m *Momentum) Name() string {
+	return IndicatorTypeMomentum
+}
+
+// Calculate 计算动量指标值，前period根K线的值为0
+func (m *Momentum) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	prices, dates, err := sourceSeries(data, m.source, m.period+1, "momentum")
+	if err != nil {
+		return IndicatorResult{}, err
+	}
+
+	momentumValues := make([]float64, len(prices))
+	for i := m.period; i < len(prices); i++ {
+		momentumValues[i] = prices[i] - prices[i-m.period]
+	}
+
+	return IndicatorResult{
+		Name: m.Name(),
+		Values: map[string][]float64{
+			"momentum": momentumValues,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估动量指标条件，阈值为价格差，为0时 cross_above 和 cross_below 为零轴穿越
+func (m *Momentum) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	return evaluateLevel("momentum", result.Values["momentum"], condition, threshold)
+}
+
+// WilliamsR 威廉指标结构体，取值-100到0，常以-20和-80作为超买和超卖
+type WilliamsR struct {
+	period int
+}
+
+// NewWilliamsR 创建一个新的威廉指标
+func NewWilliamsR(params IndicatorParams) (Indicator, error) {
+	period := params.GetInt("period", 14)
+
+	// 验证参数
+	if period <= 0 {
+		return nil, fmt.Errorf("period must be a positive integer")
+	}
+
+	return &WilliamsR{
+		period: period,
+	}, nil
+}
+
+// Name 返回指标名称
+func (w *WilliamsR) Name() string {
+	return IndicatorTypeWilliamsR
+}
+
+// Calculate 计算威廉指标值 -100×(最高价-收盘价)/(最高价-最低价)，前period-1根K线的值为0
+func (w *WilliamsR) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	if len(data) < w.period {
+		return IndicatorResult{}, fmt.Errorf("not enough data points for Williams %%R calculation (minimum: %d, got: %d)",
+			w.period, len(data))
+	}
+
+	dates := make([]string, len(data))
+	wrValues := make([]float64, len(data))
+	for i, bar := range data {
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+		if i < w.period-1 {
+			continue
+		}
+		highest, lowest := bar.High, bar.Low
+		for _, prev := range data[i-w.period+1 : i] {
+			if prev.High > highest {
+				highest = prev.High
+			}
+			if prev.Low < lowest {
+				lowest = prev.Low
+			}
+		}
+		if highest > lowest {
+			wrValues[i] = -100 * (highest - bar.Close) / (highest - lowest)
+		} else {
+			// 区间内价格没有变化时取中值
+			wrValues[i] = -50
+		}
+	}
+
+	return IndicatorResult{
+		Name: w.Name(),
+		Values: map[string][]float64{
+			"williams_r": wrValues,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估威廉指标条件，阈值为负数，如 above_threshold -20 表示超买
+func (w *WilliamsR) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	return evaluateLevel("Williams %R", result.Values["williams_r"], condition, threshold)
+}
@@ -0,0 +1,39 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMomentumIndicators(t *testing.T) {
+	data := flatBars([]float64{100, 102, 105, 99, 110}, []int64{1, 1, 1, 1, 1})
+
+	roc, _ := NewROC(IndicatorParams{"period": 2})
+	result, err := roc.Calculate(data)
+	if err != nil {
+		t.Fatalf("计算ROC失败: %v", err)
+	}
+	if got, want := result.Values["roc"][4], (110-105)/105.0*100; math.Abs(got-want) > 1e-9 {
+		t.Errorf("ROC = %v, 期望 %v", got, want)
+	}
+	if ok, _ := roc.EvaluateCondition(result, ConditionCrossAbove, 0); !ok {
+		t.Errorf("ROC从负值变为正值时应上穿零轴: %v", result.Values["roc"])
+	}
+
+	momentum, _ := NewMomentum(IndicatorParams{"period": 1})
+	result, _ = momentum.Calculate(data)
+	if got := result.Values["momentum"][3]; got != -6 {
+		t.Errorf("动量 = %v, 期望 -6", got)
+	}
+
+	// 最近3根的最高价111、最低价98，收盘价110
+	wr, _ := NewWilliamsR(IndicatorParams{"period": 3})
+	result, _ = wr.Calculate(data)
+	want := -100 * (111 - 110) / (111 - 98.0)
+	if got := result.Values["williams_r"][4]; math.Abs(got-want) > 1e-9 {
+		t.Errorf("威廉指标 = %v, 期望 %v", got, want)
+	}
+	if ok, _ := wr.EvaluateCondition(result, ConditionAboveThreshold, -20); !ok {
+		t.Error("收盘价接近区间最高价时应超买")
+	}
+}
@@ -0,0 +1,168 @@
+package indicators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// MFI 资金流量指标结构体，按典型价格和成交量计算的RSI，常以80和20作为超买和超卖
+type MFI struct {
+	period int
+}
+
+// NewMFI 创建一个新的MFI指标
+func NewMFI(params IndicatorParams) (Indicator, error) {
+	period := params.GetInt("period", 14)
+
+	// 验证参数
+	if period <= 0 {
+		return nil, fmt.Errorf("period must be a positive integer")
+	}
+
+	return &MFI{
+		period: period,
+	}, nil
+}
+
+// Name 返回指标名称
+func (m *MFI) Name() string {
+	return IndicatorTypeMFI
+}
+
+// Calculate 计算MFI指标值，前period根K线的值为0
+func (m *MFI) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	if len(data) < m.period+1 {
+		return IndicatorResult{}, fmt.Errorf("not enough data points for MFI calculation (minimum: %d, got: %d)",
+			m.period+1, len(data))
+	}
+
+	// 典型价格上涨的K线计入正资金流，下跌的计入负资金流
+	dates := make([]string, len(data))
+	positive := make([]float64, len(data))
+	negative := make([]float64, len(data))
+	for i, bar := range data {
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+		if i == 0 {
+			continue
+		}
+		typical := SourceValue(bar, SourceTypical)
+		prevTypical := SourceValue(data[i-1], SourceTypical)
+		flow := typical * float64(bar.Volume)
+		if typical > prevTypical {
+			positive[i] = flow
+		} else if typical < prevTypical {
+			negative[i] = flow
+		}
+	}
+
+	mfiValues := make([]float64, len(data))
+	var sumPositive, sumNegative float64
+	for i := 1; i < len(data); i++ {
+		sumPositive += positive[i]
+		sumNegative += negative[i]
+		if i > m.period {
+			sumPositive -= positive[i-m.period]
+			sumNegative -= negative[i-m.period]
+		}
+		if i < m.period {
+			continue
+		}
+
+		switch {
+		case sumPositive+sumNegative == 0:
+			mfiValues[i] = 50
+		case sumNegative == 0:
+			mfiValues[i] = 100
+		default:
+			mfiValues[i] = 100 - 100/(1+sumPositive/sumNegative)
+		}
+	}
+
+	return IndicatorResult{
+		Name: m.Name(),
+		Values: map[string][]float64{
+			"mfi": mfiValues,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估MFI指标条件，阈值为超买或超卖水平
+func (m *MFI) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	return evaluateLevel("MFI", result.Values["mfi"], condition, threshold)
+}
+
+// ChaikinOscillator 蔡金振荡指标结构体，为累积/派发线的快速EMA与慢速EMA之差
+type ChaikinOscillator struct {
+	fastPeriod int
+	slowPeriod int
+}
+
+// NewChaikinOscillator 创建一个新的蔡金振荡指标
+func NewChaikinOscillator(params IndicatorParams) (Indicator, error) {
+	fastPeriod := params.GetInt("fast_period", 3)
+	slowPeriod := params.GetInt("slow_period", 10)
+
+	// 验证参数
+	if fastPeriod <= 0 || slowPeriod <= 0 {
+		return nil, fmt.Errorf("periods must be positive integers")
+	}
+
+	if fastPeriod >= slowPeriod {
+		return nil, fmt.Errorf("fast period must be less than slow period")
+	}
+
+	return &ChaikinOscillator{
+		fastPeriod: fastPeriod,
+		slowPeriod: slowPeriod,
+	}, nil
+}
+
+// Name 返回指标名称
+func (c *ChaikinOscillator) Name() string {
+	return IndicatorTypeChaikin
+}
+
+// Calculate 计算累积/派发线和蔡金振荡值，前slowPeriod-1根K线的振荡值为0
+func (c *ChaikinOscillator) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	if len(data) < c.slowPeriod {
+		return IndicatorResult{}, fmt.Errorf("not enough data points for Chaikin oscillator calculation (minimum: %d, got: %d)",
+			c.slowPeriod, len(data))
+	}
+
+	// 资金流乘数 = ((收盘价-最低价)-(最高价-收盘价))/(最高价-最低价)，乘以成交量后累加
+	dates := make([]string, len(data))
+	adl := make([]float64, len(data))
+	var cumulative float64
+	for i, bar := range data {
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+		if spread := bar.High - bar.Low; spread > 0 {
+			multiplier := ((bar.Close - bar.Low) - (bar.High - bar.Close)) / spread
+			cumulative += multiplier * float64(bar.Volume)
+		}
+		adl[i] = cumulative
+	}
+
+	fastEMA := calculateEMA(adl, c.fastPeriod)
+	slowEMA := calculateEMA(adl, c.slowPeriod)
+	oscillator := make([]float64, len(data))
+	for i := c.slowPeriod - 1; i < len(data); i++ {
+		oscillator[i] = fastEMA[i] - slowEMA[i]
+	}
+
+	return IndicatorResult{
+		Name: c.Name(),
+		Values: map[string][]float64{
+			"chaikin": oscillator,
+			"adl":     adl,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估蔡金振荡指标条件，阈值为0时 cross_above 和 cross_below 为零轴穿越
+func (c *ChaikinOscillator) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	return evaluateLevel("Chaikin oscillator", result.Values["chaikin"], condition, threshold)
+}
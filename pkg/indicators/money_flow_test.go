@@ -0,0 +1,61 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// flatBars 生成最高价和最低价为收盘价±1的K线
+func flatBars(closes []float64, volumes []int64) []datasource.StockData {
+	start := time.Date(2024, 3, 8, 9, 30, 0, 0, time.UTC)
+	data := make([]datasource.StockData, len(closes))
+	for i, c := range closes {
+		data[i] = datasource.StockData{Timestamp: start.Add(time.Duration(i) * time.Minute), Open: c, High: c + 1, Low: c - 1, Close: c, Volume: volumes[i]}
+	}
+	return data
+}
+
+func TestMFI(t *testing.T) {
+	data := flatBars([]float64{10, 11, 10, 12}, []int64{100, 100, 200, 100})
+	mfi, _ := NewMFI(IndicatorParams{"period": 3})
+	result, err := mfi.Calculate(data)
+	if err != nil {
+		t.Fatalf("计算MFI失败: %v", err)
+	}
+	// 正资金流 = 11×100+12×100 = 2300，负资金流 = 10×200 = 2000
+	want := 100 - 100/(1+2300.0/2000.0)
+	if got := result.Values["mfi"][3]; math.Abs(got-want) > 1e-9 {
+		t.Errorf("MFI = %v, 期望 %v", got, want)
+	}
+	if ok, _ := mfi.EvaluateCondition(result, ConditionAboveThreshold, 80); ok {
+		t.Error("MFI未超买时不应满足条件")
+	}
+}
+
+func TestChaikinOscillator(t *testing.T) {
+	// 收盘价在最高价时资金流入，在最低价时资金流出
+	var data []datasource.StockData
+	start := time.Date(2024, 3, 8, 9, 30, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		bar := datasource.StockData{Timestamp: start.Add(time.Duration(i) * time.Minute), High: 11, Low: 9, Close: 9, Volume: 100}
+		if i >= 10 {
+			bar.Close = 11
+			bar.Volume = 1000
+		}
+		data = append(data, bar)
+	}
+	chaikin, _ := NewChaikinOscillator(IndicatorParams{})
+	result, err := chaikin.Calculate(data)
+	if err != nil {
+		t.Fatalf("计算蔡金振荡失败: %v", err)
+	}
+	if result.Values["adl"][9] != -1000 || result.Values["adl"][11] != 1000 {
+		t.Errorf("累积/派发线不正确: %v", result.Values["adl"])
+	}
+	if ok, _ := chaikin.EvaluateCondition(result, ConditionCrossAbove, 0); !ok {
+		t.Errorf("资金大量流入时应上穿零轴: %v", result.Values["chaikin"])
+	}
+}
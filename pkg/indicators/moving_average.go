@@ -10,6 +10,7 @@ import (
 // SMA 简单移动平均指标结构体
 type SMA struct {
 	period int
+	source string
 }
 
 // NewSMA 创建一个新的SMA指标
@@ -21,8 +22,14 @@ func NewSMA(params IndicatorParams) (Indicator, error) {
 		return nil, fmt.Errorf("period must be a positive integer")
 	}
 
+	source, err := params.GetSource()
+	if err != nil {
+		return nil, err
+	}
+
 	return &SMA{
 		period: period,
+		source: source,
 	}, nil
 }
 
@@ -38,11 +45,11 @@ func (s *SMA) Calculate(data []datasource.StockData) (IndicatorResult, error) {
 			s.period, len(data))
 	}
 
-	// 提取收盘价
+	// 提取输入序列，默认为收盘价
 	prices := make([]float64, len(data))
 	dates := make([]string, len(data))
 	for i, bar := range data {
-		prices[i] = bar.Close
+		prices[i] = SourceValue(bar, s.source)
 		dates[i] = bar.Timestamp.Format(time.RFC3339)
 	}
 
@@ -118,6 +125,7 @@ func (s *SMA) EvaluateCondition(result IndicatorResult, condition string, thresh
 // EMA 指数移动平均指标结构体
 type EMA struct {
 	period int
+	source string
 }
 
 // NewEMA 创建一个新的EMA指标
@@ -129,8 +137,14 @@ func NewEMA(params IndicatorParams) (Indicator, error) {
 		return nil, fmt.Errorf("period must be a positive integer")
 	}
 
+	source, err := params.GetSource()
+	if err != nil {
+		return nil, err
+	}
+
 	return &EMA{
 		period: period,
+		source: source,
 	}, nil
 }
 
@@ -146,11 +160,11 @@ func (e *EMA) Calculate(data []datasource.StockData) (IndicatorResult, error) {
 			e.period, len(data))
 	}
 
-	// 提取收盘价
+	// 提取输入序列，默认为收盘价
 	prices := make([]float64, len(data))
 	dates := make([]string, len(data))
 	for i, bar := range data {
-		prices[i] = bar.Close
+		prices[i] = SourceValue(bar, e.source)
 		dates[i] = bar.Timestamp.Format(time.RFC3339)
 	}
 
@@ -0,0 +1,732 @@
+package indicators
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 本文件实现ONNX模型推理所需的最小子集：解析ModelProto中的计算图和初始值，
+// 按节点顺序解释执行全连接网络和线性模型常用的算子（Gemm、MatMul、逐元素运算、激活函数、Softmax、Flatten、Reshape）。
+// 张量统一以float64存储，不支持 ai.onnx.ml 等扩展域的算子
+
+// ONNX TensorProto 的数据类型
+const (
+	onnxFloat  = 1
+	onnxInt32  = 6
+	onnxInt64  = 7
+	onnxDouble = 11
+)
+
+// onnxOps 是支持的算子（内部变量）
+var onnxOps = map[string]bool{
+	"Gemm": true, "MatMul": true, "Add": true, "Sub": true, "Mul": true, "Div": true,
+	"Relu": true, "LeakyRelu": true, "Sigmoid": true, "Tanh": true, "Exp": true, "Identity": true,
+	"Softmax": true, "Flatten": true, "Reshape": true, "Constant": true,
+}
+
+// onnxTensor 表示一个按行优先存储的张量（内部类型）
+type onnxTensor struct {
+	shape []int
+	data  []float64
+}
+
+// onnxAttr 表示节点属性（内部类型）
+type onnxAttr struct {
+	f      float64
+	i      int64
+	s      string
+	ints   []int64
+	floats []float64
+	t      *onnxTensor
+}
+
+// onnxNode 表示计算图中的一个节点（内部类型）
+type onnxNode struct {
+	op      string
+	domain  string
+	inputs  []string
+	outputs []string
+	attrs   map[string]onnxAttr
+}
+
+// onnxModel 表示解析后的模型，推理时只读，可以并发使用（内部类型）
+type onnxModel struct {
+	nodes        []onnxNode
+	initializers map[string]*onnxTensor
+	input        string  // 第一个不是初始值的图输入
+	inputDims    []int64 // 图输入声明的维度，未知维度为0
+	outputs      []string
+}
+
+// modelCache 按路径缓存已加载的模型，文件修改后重新加载（内部变量）
+var modelCache = struct {
+	sync.Mutex
+	models map[string]cachedModel
+}{models: make(map[string]cachedModel)}
+
+// cachedModel 表示缓存的模型及加载时的文件状态（内部类型）
+type cachedModel struct {
+	modTime time.Time
+	size    int64
+	model   *onnxModel
+}
+
+// loadONNXModel 读取并解析ONNX模型文件，同一文件只解析一次（内部函数）
+func loadONNXModel(path string) (*onnxModel, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	modelCache.Lock()
+	defer modelCache.Unlock()
+	if cached, ok := modelCache.models[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.model, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	model, err := parseONNXModel(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ONNX model %s: %v", path, err)
+	}
+	modelCache.models[path] = cachedModel{modTime: info.ModTime(), size: info.Size(), model: model}
+	return model, nil
+}
+
+// parseONNXModel 解析ModelProto，检查所有算子都受支持（内部函数）
+func parseONNXModel(data []byte) (*onnxModel, error) {
+	model := &onnxModel{initializers: make(map[string]*onnxTensor)}
+	var graph []byte
+	err := protoFields(data, func(num protowire.Number, v []byte, _ uint64) error {
+		if num == 7 {
+			graph = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if graph == nil {
+		return nil, fmt.Errorf("model has no graph")
+	}
+
+	type valueInfo struct {
+		name string
+		dims []int64
+	}
+	var inputs []valueInfo
+	err = protoFields(graph, func(num protowire.Number, v []byte, _ uint64) error {
+		switch num {
+		case 1:
+			node, err := parseONNXNode(v)
+			if err != nil {
+				return err
+			}
+			model.nodes = append(model.nodes, node)
+		case 5:
+			name, tensor, err := parseONNXTensor(v)
+			if err != nil {
+				return err
+			}
+			model.initializers[name] = tensor
+		case 11:
+			name, dims, err := parseValueInfo(v)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, valueInfo{name, dims})
+		case 12:
+			name, _, err := parseValueInfo(v)
+			if err != nil {
+				return err
+			}
+			model.outputs = append(model.outputs, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 旧版本导出的模型会把初始值也列为图输入
+	for _, in := range inputs {
+		if _, ok := model.initializers[in.name]; !ok {
+			model.input, model.inputDims = in.name, in.dims
+			break
+		}
+	}
+	if model.input == "" {
+		return nil, fmt.Errorf("model has no input")
+	}
+	if len(model.outputs) == 0 {
+		return nil, fmt.Errorf("model has no output")
+	}
+	for _, node := range model.nodes {
+		if (node.domain != "" && node.domain != "ai.onnx") || !onnxOps[node.op] {
+			return nil, fmt.Errorf("unsupported operator %s", node.op)
+		}
+	}
+	return model, nil
+}
+
+// parseONNXNode 解析NodeProto（内部函数）
+func parseONNXNode(data []byte) (onnxNode, error) {
+	node := onnxNode{attrs: make(map[string]onnxAttr)}
+	err := protoFields(data, func(num protowire.Number, v []byte, _ uint64) error {
+		switch num {
+		case 1:
+			node.inputs = append(node.inputs, string(v))
+		case 2:
+			node.outputs = append(node.outputs, string(v))
+		case 4:
+			node.op = string(v)
+		case 5:
+			name, attr, err := parseONNXAttr(v)
+			if err != nil {
+				return err
+			}
+			node.attrs[name] = attr
+		case 7:
+			node.domain = string(v)
+		}
+		return nil
+	})
+	return node, err
+}
+
+// parseONNXAttr 解析AttributeProto（内部函数）
+func parseONNXAttr(data []byte) (string, onnxAttr, error) {
+	var name string
+	var attr onnxAttr
+	err := protoFields(data, func(num protowire.Number, v []byte, x uint64) error {
+		switch num {
+		case 1:
+			name = string(v)
+		case 2:
+			attr.f = float64(math.Float32frombits(uint32(x)))
+		case 3:
+			attr.i = int64(x)
+		case 4:
+			attr.s = string(v)
+		case 5:
+			_, t, err := parseONNXTensor(v)
+			if err != nil {
+				return err
+			}
+			attr.t = t
+		case 7:
+			attr.floats = appendFloat32s(attr.floats, v, x)
+		case 8:
+			attr.ints = appendVarints(attr.ints, v, x)
+		}
+		return nil
+	})
+	return name, attr, err
+}
+
+// parseValueInfo 解析ValueInfoProto的名称和张量维度（内部函数）
+func parseValueInfo(data []byte) (string, []int64, error) {
+	var name string
+	var dims []int64
+	err := protoFields(data, func(num protowire.Number, v []byte, _ uint64) error {
+		switch num {
+		case 1:
+			name = string(v)
+		case 2:
+			// TypeProto.tensor_type.shape.dim.dim_value
+			return protoFields(v, func(num protowire.Number, v []byte, _ uint64) error {
+				if num != 1 {
+					return nil
+				}
+				return protoFields(v, func(num protowire.Number, v []byte, _ uint64) error {
+					if num != 2 {
+						return nil
+					}
+					return protoFields(v, func(num protowire.Number, v []byte, _ uint64) error {
+						if num != 1 {
+							return nil
+						}
+						var dim int64
+						err := protoFields(v, func(num protowire.Number, _ []byte, x uint64) error {
+							if num == 1 {
+								dim = int64(x)
+							}
+							return nil
+						})
+						dims = append(dims, dim)
+						return err
+					})
+				})
+			})
+		}
+		return nil
+	})
+	return name, dims, err
+}
+
+// parseONNXTensor 解析TensorProto，支持float、double、int32和int64（内部函数）
+func parseONNXTensor(data []byte) (string, *onnxTensor, error) {
+	var name string
+	var dims []int64
+	var dataType uint64
+	var raw []byte
+	var values []float64
+	err := protoFields(data, func(num protowire.Number, v []byte, x uint64) error {
+		switch num {
+		case 1:
+			dims = appendVarints(dims, v, x)
+		case 2:
+			dataType = x
+		case 4:
+			values = appendFloat32s(values, v, x)
+		case 5, 7:
+			for _, i := range appendVarints(nil, v, x) {
+				if num == 5 {
+					i = int64(int32(i))
+				}
+				values = append(values, float64(i))
+			}
+		case 8:
+			name = string(v)
+		case 9:
+			raw = v
+		case 10:
+			values = appendFloat64s(values, v, x)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	if raw != nil {
+		values = nil
+		switch dataType {
+		case onnxFloat:
+			for i := 0; i+4 <= len(raw); i += 4 {
+				values = append(values, float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[i:]))))
+			}
+		case onnxDouble:
+			for i := 0; i+8 <= len(raw); i += 8 {
+				values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(raw[i:])))
+			}
+		case onnxInt32:
+			for i := 0; i+4 <= len(raw); i += 4 {
+				values = append(values, float64(int32(binary.LittleEndian.Uint32(raw[i:]))))
+			}
+		case onnxInt64:
+			for i := 0; i+8 <= len(raw); i += 8 {
+				values = append(values, float64(int64(binary.LittleEndian.Uint64(raw[i:]))))
+			}
+		default:
+			return "", nil, fmt.Errorf("tensor %s has unsupported data type %d", name, dataType)
+		}
+	}
+
+	shape := make([]int, len(dims))
+	size := 1
+	for i, d := range dims {
+		shape[i] = int(d)
+		size *= int(d)
+	}
+	if len(values) != size {
+		return "", nil, fmt.Errorf("tensor %s has %d values, expected %d", name, len(values), size)
+	}
+	return name, &onnxTensor{shape: shape, data: values}, nil
+}
+
+// protoFields 依次回调消息中的每个字段：长度分隔的字段传入v，其他字段的值传入x（内部函数）
+func protoFields(data []byte, fn func(num protowire.Number, v []byte, x uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var v []byte
+		var x uint64
+		switch typ {
+		case protowire.VarintType:
+			x, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed32Type:
+			var u uint32
+			u, n = protowire.ConsumeFixed32(data)
+			x = uint64(u)
+		case protowire.Fixed64Type:
+			x, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := fn(num, v, x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendVarints 追加重复的整数字段，v不为空时为打包编码（内部函数）
+func appendVarints(dst []int64, v []byte, x uint64) []int64 {
+	if v == nil {
+		return append(dst, int64(x))
+	}
+	for len(v) > 0 {
+		u, n := protowire.ConsumeVarint(v)
+		if n < 0 {
+			break
+		}
+		dst = append(dst, int64(u))
+		v = v[n:]
+	}
+	return dst
+}
+
+// appendFloat32s 追加重复的float字段，v不为空时为打包编码（内部函数）
+func appendFloat32s(dst []float64, v []byte, x uint64) []float64 {
+	if v == nil {
+		return append(dst, float64(math.Float32frombits(uint32(x))))
+	}
+	for i := 0; i+4 <= len(v); i += 4 {
+		dst = append(dst, float64(math.Float32frombits(binary.LittleEndian.Uint32(v[i:]))))
+	}
+	return dst
+}
+
+// appendFloat64s 追加重复的double字段，v不为空时为打包编码（内部函数）
+func appendFloat64s(dst []float64, v []byte, x uint64) []float64 {
+	if v == nil {
+		return append(dst, math.Float64frombits(x))
+	}
+	for i := 0; i+8 <= len(v); i += 8 {
+		dst = append(dst, math.Float64frombits(binary.LittleEndian.Uint64(v[i:])))
+	}
+	return dst
+}
+
+// run 以input作为图输入执行模型，返回名为output的张量（内部方法）
+func (m *onnxModel) run(input *onnxTensor, output string) (*onnxTensor, error) {
+	values := make(map[string]*onnxTensor, len(m.initializers)+len(m.nodes)+1)
+	for name, t := range m.initializers {
+		values[name] = t
+	}
+	values[m.input] = input
+
+	for _, node := range m.nodes {
+		args := make([]*onnxTensor, len(node.inputs))
+		for i, name := range node.inputs {
+			if name == "" {
+				continue // 省略的可选输入
+			}
+			t, ok := values[name]
+			if !ok {
+				return nil, fmt.Errorf("%s: input %s is not available", node.op, name)
+			}
+			args[i] = t
+		}
+		out, err := node.eval(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", node.op, err)
+		}
+		if len(node.outputs) > 0 {
+			values[node.outputs[0]] = out
+		}
+	}
+
+	t, ok := values[output]
+	if !ok {
+		return nil, fmt.Errorf("model output %s was not computed", output)
+	}
+	return t, nil
+}
+
+// eval 执行一个节点，输入张量不会被修改（内部方法）
+func (n onnxNode) eval(args []*onnxTensor) (*onnxTensor, error) {
+	arg := func(i int) (*onnxTensor, error) {
+		if i >= len(args) || args[i] == nil {
+			return nil, fmt.Errorf("missing input %d", i)
+		}
+		return args[i], nil
+	}
+	if n.op == "Constant" {
+		if t := n.attrs["value"].t; t != nil {
+			return t, nil
+		}
+		return nil, fmt.Errorf("only tensor constants are supported")
+	}
+
+	x, err := arg(0)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "Identity":
+		return x, nil
+	case "Relu":
+		return x.apply(func(v float64) float64 { return math.Max(v, 0) }), nil
+	case "LeakyRelu":
+		alpha := 0.01
+		if a, ok := n.attrs["alpha"]; ok {
+			alpha = a.f
+		}
+		return x.apply(func(v float64) float64 {
+			if v < 0 {
+				return alpha * v
+			}
+			return v
+		}), nil
+	case "Sigmoid":
+		return x.apply(func(v float64) float64 { return 1 / (1 + math.Exp(-v)) }), nil
+	case "Tanh":
+		return x.apply(math.Tanh), nil
+	case "Exp":
+		return x.apply(math.Exp), nil
+	case "Softmax":
+		return x.softmax(n.intAttr("axis", -1))
+	case "Flatten":
+		return x.flatten(n.intAttr("axis", 1))
+	}
+
+	y, err := arg(1)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "Add":
+		return broadcast(x, y, func(a, b float64) float64 { return a + b })
+	case "Sub":
+		return broadcast(x, y, func(a, b float64) float64 { return a - b })
+	case "Mul":
+		return broadcast(x, y, func(a, b float64) float64 { return a * b })
+	case "Div":
+		return broadcast(x, y, func(a, b float64) float64 { return a / b })
+	case "MatMul":
+		return matmul(x, y, false, false)
+	case "Reshape":
+		return x.reshape(y)
+	case "Gemm":
+		alpha, beta := 1.0, 1.0
+		if a, ok := n.attrs["alpha"]; ok {
+			alpha = a.f
+		}
+		if b, ok := n.attrs["beta"]; ok {
+			beta = b.f
+		}
+		out, err := matmul(x, y, n.intAttr("transA", 0) != 0, n.intAttr("transB", 0) != 0)
+		if err != nil {
+			return nil, err
+		}
+		out = out.apply(func(v float64) float64 { return alpha * v })
+		if len(args) > 2 && args[2] != nil {
+			return broadcast(out, args[2], func(a, c float64) float64 { return a + beta*c })
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported operator")
+}
+
+// intAttr 返回整数属性，没有时返回def（内部方法）
+func (n onnxNode) intAttr(name string, def int) int {
+	if a, ok := n.attrs[name]; ok {
+		return int(a.i)
+	}
+	return def
+}
+
+// apply 返回对每个元素应用f后的新张量（内部方法）
+func (t *onnxTensor) apply(f func(float64) float64) *onnxTensor {
+	out := &onnxTensor{shape: t.shape, data: make([]float64, len(t.data))}
+	for i, v := range t.data {
+		out.data[i] = f(v)
+	}
+	return out
+}
+
+// softmax 沿axis计算Softmax（内部方法）
+func (t *onnxTensor) softmax(axis int) (*onnxTensor, error) {
+	if axis < 0 {
+		axis += len(t.shape)
+	}
+	if axis < 0 || axis >= len(t.shape) {
+		return nil, fmt.Errorf("invalid axis %d for rank %d", axis, len(t.shape))
+	}
+	inner := 1
+	for _, d := range t.shape[axis+1:] {
+		inner *= d
+	}
+	size := t.shape[axis]
+	out := &onnxTensor{shape: t.shape, data: make([]float64, len(t.data))}
+	for outer := 0; outer < len(t.data); outer += size * inner {
+		for k := 0; k < inner; k++ {
+			maxValue := math.Inf(-1)
+			for j := 0; j < size; j++ {
+				maxValue = math.Max(maxValue, t.data[outer+j*inner+k])
+			}
+			sum := 0.0
+			for j := 0; j < size; j++ {
+				e := math.Exp(t.data[outer+j*inner+k] - maxValue)
+				out.data[outer+j*inner+k] = e
+				sum += e
+			}
+			for j := 0; j < size; j++ {
+				out.data[outer+j*inner+k] /= sum
+			}
+		}
+	}
+	return out, nil
+}
+
+// flatten 把axis之前和之后的维度分别合并为二维张量（内部方法）
+func (t *onnxTensor) flatten(axis int) (*onnxTensor, error) {
+	if axis < 0 {
+		axis += len(t.shape)
+	}
+	if axis < 0 || axis > len(t.shape) {
+		return nil, fmt.Errorf("invalid axis %d for rank %d", axis, len(t.shape))
+	}
+	rows := 1
+	for _, d := range t.shape[:axis] {
+		rows *= d
+	}
+	return &onnxTensor{shape: []int{rows, len(t.data) / max(rows, 1)}, data: t.data}, nil
+}
+
+// reshape 按形状张量改变形状，0表示保留原维度，-1表示由其他维度推算（内部方法）
+func (t *onnxTensor) reshape(shape *onnxTensor) (*onnxTensor, error) {
+	dims := make([]int, len(shape.data))
+	known, infer := 1, -1
+	for i, v := range shape.data {
+		switch d := int(v); {
+		case d == 0 && i < len(t.shape):
+			dims[i] = t.shape[i]
+		case d == -1 && infer < 0:
+			infer = i
+			continue
+		case d > 0:
+			dims[i] = d
+		default:
+			return nil, fmt.Errorf("invalid shape %v", shape.data)
+		}
+		known *= dims[i]
+	}
+	if infer >= 0 && known > 0 {
+		dims[infer] = len(t.data) / known
+		known *= dims[infer]
+	}
+	if known != len(t.data) {
+		return nil, fmt.Errorf("cannot reshape %v to %v", t.shape, shape.data)
+	}
+	return &onnxTensor{shape: dims, data: t.data}, nil
+}
+
+// matmul 计算二维矩阵乘法，一维的a视为行向量（内部函数）
+func matmul(a, b *onnxTensor, transA, transB bool) (*onnxTensor, error) {
+	vector := len(a.shape) == 1
+	ashape := a.shape
+	if vector {
+		ashape = []int{1, a.shape[0]}
+	}
+	if len(ashape) != 2 || len(b.shape) != 2 {
+		return nil, fmt.Errorf("only 2-D matrix multiplication is supported, got %v x %v", a.shape, b.shape)
+	}
+
+	m, k := ashape[0], ashape[1]
+	at := func(i, j int) float64 { return a.data[i*k+j] }
+	if transA {
+		m, k = k, m
+		at = func(i, j int) float64 { return a.data[j*m+i] }
+	}
+	kb, n := b.shape[0], b.shape[1]
+	bt := func(i, j int) float64 { return b.data[i*n+j] }
+	if transB {
+		kb, n = n, kb
+		bt = func(i, j int) float64 { return b.data[j*kb+i] }
+	}
+	if k != kb {
+		return nil, fmt.Errorf("shape mismatch %v x %v", a.shape, b.shape)
+	}
+
+	out := &onnxTensor{shape: []int{m, n}, data: make([]float64, m*n)}
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			sum := 0.0
+			for p := 0; p < k; p++ {
+				sum += at(i, p) * bt(p, j)
+			}
+			out.data[i*n+j] = sum
+		}
+	}
+	if vector && !transA {
+		out.shape = []int{n}
+	}
+	return out, nil
+}
+
+// broadcast 按NumPy广播规则对两个张量逐元素计算（内部函数）
+func broadcast(a, b *onnxTensor, f func(x, y float64) float64) (*onnxTensor, error) {
+	rank := max(len(a.shape), len(b.shape))
+	shape := make([]int, rank)
+	for i := range shape {
+		da, db := dimFromEnd(a.shape, rank-i), dimFromEnd(b.shape, rank-i)
+		switch {
+		case da == db || db == 1:
+			shape[i] = da
+		case da == 1:
+			shape[i] = db
+		default:
+			return nil, fmt.Errorf("cannot broadcast %v and %v", a.shape, b.shape)
+		}
+	}
+
+	size := 1
+	for _, d := range shape {
+		size *= d
+	}
+	out := &onnxTensor{shape: shape, data: make([]float64, size)}
+	index := make([]int, rank)
+	for k := range out.data {
+		rem := k
+		for i := rank - 1; i >= 0; i-- {
+			index[i] = rem % shape[i]
+			rem /= shape[i]
+		}
+		out.data[k] = f(a.data[a.offset(index)], b.data[b.offset(index)])
+	}
+	return out, nil
+}
+
+// dimFromEnd 返回倒数第k个维度，不存在时为1（内部函数）
+func dimFromEnd(shape []int, k int) int {
+	if k > len(shape) {
+		return 1
+	}
+	return shape[len(shape)-k]
+}
+
+// offset 返回广播后的下标在张量中的位置，index按尾部对齐（内部方法）
+func (t *onnxTensor) offset(index []int) int {
+	off := 0
+	skip := len(index) - len(t.shape)
+	for j, d := range t.shape {
+		i := index[skip+j]
+		if d == 1 {
+			i = 0
+		}
+		off = off*d + i
+	}
+	return off
+}
@@ -0,0 +1,174 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// DefaultCCILevel 是CCI超买和超卖的默认水平
+const DefaultCCILevel = 100
+
+// CCI 顺势指标结构体，为典型价格偏离其均值的程度除以0.015倍平均绝对偏差
+type CCI struct {
+	period int
+}
+
+// NewCCI 创建一个新的CCI指标
+func NewCCI(params IndicatorParams) (Indicator, error) {
+	period := params.GetInt("period", 20)
+
+	// 验证参数
+	if period <= 0 {
+		return nil, fmt.Errorf("period must be a positive integer")
+	}
+
+	return &CCI{
+		period: period,
+	}, nil
+}
+
+// Name 返回指标名称
+func (c *CCI) Name() string {
+	return IndicatorTypeCCI
+}
+
+// Calculate 计算CCI指标值，前period-1根K线和平均绝对偏差为0时的值为0
+func (c *CCI) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	typical, dates, err := sourceSeries(data, SourceTypical, c.period, "CCI")
+	if err != nil {
+		return IndicatorResult{}, err
+	}
+
+	cciValues := make([]float64, len(typical))
+	for i := c.period - 1; i < len(typical); i++ {
+		window := typical[i-c.period+1 : i+1]
+		var sum float64
+		for _, tp := range window {
+			sum += tp
+		}
+		mean := sum / float64(c.period)
+		var deviation float64
+		for _, tp := range window {
+			deviation += math.Abs(tp - mean)
+		}
+		deviation /= float64(c.period)
+		if deviation > 0 {
+			cciValues[i] = (typical[i] - mean) / (0.015 * deviation)
+		}
+	}
+
+	return IndicatorResult{
+		Name: c.Name(),
+		Values: map[string][]float64{
+			"cci": cciValues,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估CCI指标条件
+// overbought 和 oversold 为高于+阈值和低于-阈值，阈值为0时使用 DefaultCCILevel（±100）；其他条件与阈值比较
+func (c *CCI) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	level := math.Abs(threshold)
+	if level == 0 {
+		level = DefaultCCILevel
+	}
+	switch condition {
+	case ConditionOverbought:
+		return evaluateLevel("CCI", result.Values["cci"], ConditionAboveThreshold, level)
+	case ConditionOversold:
+		return evaluateLevel("CCI", result.Values["cci"], ConditionBelowThreshold, -level)
+	default:
+		return evaluateLevel("CCI", result.Values["cci"], condition, threshold)
+	}
+}
+
+// UltimateOscillator 终极振荡指标结构体，按4:2:1加权短、中、长三个周期的买压与真实波幅之比
+type UltimateOscillator struct {
+	shortPeriod  int
+	mediumPeriod int
+	longPeriod   int
+}
+
+// NewUltimateOscillator 创建一个新的终极振荡指标
+func NewUltimateOscillator(params IndicatorParams) (Indicator, error) {
+	shortPeriod := params.GetInt("short_period", 7)
+	mediumPeriod := params.GetInt("medium_period", 14)
+	longPeriod := params.GetInt("long_period", 28)
+
+	// 验证参数
+	if shortPeriod <= 0 || mediumPeriod <= 0 || longPeriod <= 0 {
+		return nil, fmt.Errorf("periods must be positive integers")
+	}
+
+	if shortPeriod >= mediumPeriod || mediumPeriod >= longPeriod {
+		return nil, fmt.Errorf("periods must satisfy short < medium < long")
+	}
+
+	return &UltimateOscillator{
+		shortPeriod:  shortPeriod,
+		mediumPeriod: mediumPeriod,
+		longPeriod:   longPeriod,
+	}, nil
+}
+
+// Name 返回指标名称
+func (u *UltimateOscillator) Name() string {
+	return IndicatorTypeUltimate
+}
+
+// Calculate 计算终极振荡指标值（0–100），前longPeriod根K线的值为0
+func (u *UltimateOscillator) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	if len(data) < u.longPeriod+1 {
+		return IndicatorResult{}, fmt.Errorf("not enough data points for Ultimate Oscillator calculation (minimum: %d, got: %d)",
+			u.longPeriod+1, len(data))
+	}
+
+	// 买压 = 收盘价 - min(最低价, 前收盘价)，真实波幅 = max(最高价, 前收盘价) - min(最低价, 前收盘价)
+	dates := make([]string, len(data))
+	buying := make([]float64, len(data))
+	ranges := make([]float64, len(data))
+	for i, bar := range data {
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+		if i == 0 {
+			continue
+		}
+		prevClose := data[i-1].Close
+		low := math.Min(bar.Low, prevClose)
+		buying[i] = bar.Close - low
+		ranges[i] = math.Max(bar.High, prevClose) - low
+	}
+
+	average := func(i, period int) float64 {
+		var sumBuying, sumRange float64
+		for j := i - period + 1; j <= i; j++ {
+			sumBuying += buying[j]
+			sumRange += ranges[j]
+		}
+		if sumRange == 0 {
+			return 0.5
+		}
+		return sumBuying / sumRange
+	}
+
+	uoValues := make([]float64, len(data))
+	for i := u.longPeriod; i < len(data); i++ {
+		uoValues[i] = 100 * (4*average(i, u.shortPeriod) + 2*average(i, u.mediumPeriod) + average(i, u.longPeriod)) / 7
+	}
+
+	return IndicatorResult{
+		Name: u.Name(),
+		Values: map[string][]float64{
+			"uo": uoValues,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估终极振荡指标条件，常用70和30作为超买和超卖阈值
+func (u *UltimateOscillator) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	return evaluateLevel("Ultimate Oscillator", result.Values["uo"], condition, threshold)
+}
@@ -0,0 +1,49 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCCI(t *testing.T) {
+	data := flatBars([]float64{10, 10, 10, 13}, []int64{1, 1, 1, 1})
+	cci, _ := NewCCI(IndicatorParams{"period": 4})
+	result, err := cci.Calculate(data)
+	if err != nil {
+		t.Fatalf("计算CCI失败: %v", err)
+	}
+	// 典型价格均值10.75，平均绝对偏差1.125，CCI = 2.25/(0.015×1.125) = 133.33
+	if got := result.Values["cci"][3]; math.Abs(got-2.25/(0.015*1.125)) > 1e-9 {
+		t.Errorf("CCI = %v", got)
+	}
+	if ok, _ := cci.EvaluateCondition(result, ConditionOverbought, 0); !ok {
+		t.Error("CCI高于+100时应超买")
+	}
+	if ok, _ := cci.EvaluateCondition(result, ConditionOversold, 0); ok {
+		t.Error("CCI高于-100时不应超卖")
+	}
+}
+
+func TestUltimateOscillator(t *testing.T) {
+	// 每根都收于最高价时买压等于真实波幅
+	closes := make([]float64, 30)
+	volumes := make([]int64, 30)
+	for i := range closes {
+		closes[i] = 100 + float64(i)
+	}
+	data := flatBars(closes, volumes)
+	for i := range data {
+		data[i].Close = data[i].High
+	}
+	uo, _ := NewUltimateOscillator(IndicatorParams{})
+	result, err := uo.Calculate(data)
+	if err != nil {
+		t.Fatalf("计算终极振荡失败: %v", err)
+	}
+	if got := result.Values["uo"][29]; math.Abs(got-100) > 1e-9 {
+		t.Errorf("终极振荡 = %v, 期望 100", got)
+	}
+	if _, err := NewUltimateOscillator(IndicatorParams{"short_period": 14}); err == nil {
+		t.Error("短周期不小于中周期时应返回错误")
+	}
+}
@@ -0,0 +1,151 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// EventStrategyChanged 是运行时修改策略配置后发布到 config 主题的审计事件类型
+const EventStrategyChanged = "strategy_changed"
+
+// StrategyChange 表示运行时对策略配置的一次修改
+type StrategyChange struct {
+	Strategy  string      `json:"strategy"`
+	Indicator string      `json:"indicator,omitempty"` // 修改指标参数时为指标名称
+	Field     string      `json:"field"`               // enabled 或参数名称
+	OldValue  interface{} `json:"old_value"`           // 修改前未设置的参数为空
+	NewValue  interface{} `json:"new_value"`
+	Source    string      `json:"source,omitempty"` // 修改来源，如 rpc、dashboard
+}
+
+// SetStrategyEnabled 在运行时启用或禁用策略，source 为修改来源（如 rpc、dashboard），状态变化时发布 strategy_changed 事件。
+// 正在进行的扫描使用开始时的策略，下一轮扫描生效
+func (s *Scanner) SetStrategyEnabled(name string, enabled bool, source string) error {
+	err := s.updateStrategies(func(strategies map[string]Strategy) error {
+		strategy, exists := strategies[name]
+		if !exists {
+			return fmt.Errorf("strategy '%s' does not exist", name)
+		}
+		if strategy.Enabled == enabled {
+			return errUnchanged
+		}
+		strategy.Enabled = enabled
+		strategies[name] = strategy
+		return nil
+	})
+	if err == errUnchanged {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.publishStrategyChange(StrategyChange{Strategy: name, Field: "enabled", OldValue: !enabled, NewValue: enabled, Source: source})
+	return nil
+}
+
+// SetIndicatorParameter 在运行时修改策略中一个指标的参数，indicator 为指标配置的名称，没有名称的指标按类型匹配。
+// param 为 buy_threshold、sell_threshold、weight 时修改条件阈值或权重，否则修改指标参数；
+// 修改后的参数无法创建指标时返回错误且不修改策略。值变化时发布 strategy_changed 事件
+func (s *Scanner) SetIndicatorParameter(strategyName, indicator, param string, value interface{}, source string) error {
+	change, err := s.patchIndicator(strategyName, indicator, param, value)
+	if err != nil || change == nil {
+		return err
+	}
+	change.Source = source
+	s.publishStrategyChange(*change)
+	return nil
+}
+
+// patchIndicator 修改指标参数，值没有变化时返回空（内部方法）
+func (s *Scanner) patchIndicator(strategyName, indicator, param string, value interface{}) (*StrategyChange, error) {
+	var change *StrategyChange
+	err := s.updateStrategies(func(strategies map[string]Strategy) error {
+		var err error
+		change, err = patchStrategy(s.registry, strategies, strategyName, indicator, param, value)
+		if err == nil && change == nil {
+			return errUnchanged
+		}
+		return err
+	})
+	if err == errUnchanged {
+		return nil, nil
+	}
+	return change, err
+}
+
+// patchStrategy 在策略快照的副本中修改指标参数，值没有变化时返回空（内部函数）
+func patchStrategy(registry *IndicatorRegistry, strategies map[string]Strategy, strategyName, indicator, param string, value interface{}) (*StrategyChange, error) {
+	strategy, exists := strategies[strategyName]
+	if !exists {
+		return nil, fmt.Errorf("strategy '%s' does not exist", strategyName)
+	}
+	idx := -1
+	for i, ind := range strategy.Indicators {
+		if ind.Name == indicator || (ind.Name == "" && ind.Type == indicator) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("indicator '%s' not found in strategy '%s'", indicator, strategyName)
+	}
+
+	// 复制指标配置，旧的快照中的策略不受影响
+	ind := strategy.Indicators[idx]
+	var old interface{}
+	switch param {
+	case "buy_threshold", "sell_threshold", "weight":
+		v := IndicatorParams{param: value}.GetFloat(param, math.NaN())
+		if math.IsNaN(v) {
+			return nil, fmt.Errorf("%s must be a number, got %v", param, value)
+		}
+		switch param {
+		case "buy_threshold":
+			old, ind.BuyThreshold = ind.BuyThreshold, v
+		case "sell_threshold":
+			old, ind.SellThreshold = ind.SellThreshold, v
+		default:
+			if v < 0 {
+				return nil, fmt.Errorf("weight must not be negative")
+			}
+			old, ind.Weight = ind.Weight, v
+		}
+		value = v
+	default:
+		params := make(IndicatorParams, len(ind.Parameters)+1)
+		for k, v := range ind.Parameters {
+			params[k] = v
+		}
+		old = params[param]
+		params[param] = value
+		if registry != nil {
+			if _, err := registry.CreateIndicator(ind.Type, params); err != nil {
+				return nil, fmt.Errorf("invalid parameter %s for indicator '%s': %v", param, indicator, err)
+			}
+		}
+		ind.Parameters = params
+	}
+	if reflect.DeepEqual(old, value) {
+		return nil, nil
+	}
+
+	configs := append([]IndicatorConfig(nil), strategy.Indicators...)
+	configs[idx] = ind
+	strategy.Indicators = configs
+	strategies[strategyName] = strategy
+	return &StrategyChange{Strategy: strategyName, Indicator: indicator, Field: param, OldValue: old, NewValue: value}, nil
+}
+
+// publishStrategyChange 发布策略修改的审计事件（内部方法）
+func (s *Scanner) publishStrategyChange(change StrategyChange) {
+	s.eventBus.Publish(events.Event{
+		Topic:     events.TopicConfig,
+		Type:      EventStrategyChanged,
+		Timestamp: time.Now(),
+		Payload:   change,
+	})
+}
@@ -0,0 +1,104 @@
+package indicators
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+func TestStrategyOverrides(t *testing.T) {
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicConfig)
+	defer sub.Close()
+
+	scanner := NewScanner(NewIndicatorRegistry(), nil)
+	scanner.SetEventBus(bus)
+	scanner.AddStrategy(Strategy{
+		Name:    "reversion",
+		Enabled: true,
+		Indicators: []IndicatorConfig{
+			{Name: "fast_rsi", Type: IndicatorTypeRSI, Parameters: IndicatorParams{"period": 14},
+				BuyCondition: ConditionBelowThreshold, BuyThreshold: 30},
+			{Type: IndicatorTypeROC, Parameters: IndicatorParams{"period": 10}, BuyCondition: ConditionAboveThreshold},
+		},
+	})
+	before, _ := scanner.GetStrategy("reversion")
+
+	if err := scanner.SetStrategyEnabled("reversion", false, "test"); err != nil {
+		t.Fatalf("禁用策略失败: %v", err)
+	}
+	if strategy, _ := scanner.GetStrategy("reversion"); strategy.Enabled {
+		t.Error("策略应被禁用")
+	}
+	evt := <-sub.C
+	change, ok := evt.Payload.(StrategyChange)
+	if evt.Type != EventStrategyChanged || !ok || change.Field != "enabled" || change.NewValue != false || change.Source != "test" {
+		t.Errorf("审计事件不正确: %+v", evt)
+	}
+	if err := scanner.SetStrategyEnabled("reversion", false, "test"); err != nil || len(sub.C) != 0 {
+		t.Errorf("状态不变时不应发布事件: %v", err)
+	}
+	if err := scanner.SetStrategyEnabled("missing", true, "test"); err == nil {
+		t.Error("不存在的策略应返回错误")
+	}
+
+	if err := scanner.SetIndicatorParameter("reversion", "fast_rsi", "period", 7, "test"); err != nil {
+		t.Fatalf("修改参数失败: %v", err)
+	}
+	if err := scanner.SetIndicatorParameter("reversion", "fast_rsi", "buy_threshold", 25, "test"); err != nil {
+		t.Fatalf("修改阈值失败: %v", err)
+	}
+	if err := scanner.SetIndicatorParameter("reversion", IndicatorTypeROC, "period", 5, "test"); err != nil {
+		t.Fatalf("没有名称的指标应按类型匹配: %v", err)
+	}
+	strategy, _ := scanner.GetStrategy("reversion")
+	if rsi := strategy.Indicators[0]; rsi.Parameters["period"] != 7 || rsi.BuyThreshold != 25 || strategy.Indicators[1].Parameters["period"] != 5 {
+		t.Errorf("参数未修改: %+v", strategy.Indicators)
+	}
+	if before.Indicators[0].Parameters["period"] != 14 || before.Indicators[0].BuyThreshold != 30 {
+		t.Errorf("之前取得的策略不应被修改: %+v", before.Indicators[0])
+	}
+	evt = <-sub.C
+	if change := evt.Payload.(StrategyChange); change.Indicator != "fast_rsi" || change.Field != "period" || change.OldValue != 14 || change.NewValue != 7 {
+		t.Errorf("参数修改事件不正确: %+v", change)
+	}
+	if len(sub.C) != 2 {
+		t.Errorf("应发布 3 个参数修改事件")
+	}
+
+	for _, tt := range []struct{ indicator, param string }{
+		{"fast_rsi", "period"},        // 无法创建指标
+		{"fast_rsi", "buy_threshold"}, // 不是数值
+		{"slow_rsi", "period"},        // 指标不存在
+	} {
+		value := interface{}(-1)
+		if tt.param == "buy_threshold" {
+			value = "low"
+		}
+		if err := scanner.SetIndicatorParameter("reversion", tt.indicator, tt.param, value, "test"); err == nil {
+			t.Errorf("%s.%s = %v 应返回错误", tt.indicator, tt.param, value)
+		}
+	}
+	if strategy, _ := scanner.GetStrategy("reversion"); strategy.Indicators[0].Parameters["period"] != 7 {
+		t.Error("修改失败时策略应保持不变")
+	}
+
+	// 并发修改和读取策略
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			scanner.SetStrategyEnabled("reversion", i%2 == 0, "test")
+			scanner.SetIndicatorParameter("reversion", "fast_rsi", "period", 10+i, "test")
+		}(i)
+		go func() {
+			defer wg.Done()
+			for name := range scanner.GetAllStrategies() {
+				scanner.GetStrategy(name)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,194 @@
+package indicators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// 枢轴点计算方法
+const (
+	PivotMethodClassic   = "classic"
+	PivotMethodFibonacci = "fibonacci"
+	PivotMethodCamarilla = "camarilla"
+)
+
+// 枢轴点的时段，按上一个交易日或上一周的OHLC计算
+const (
+	PivotTimeframeDaily  = "daily"
+	PivotTimeframeWeekly = "weekly"
+)
+
+// PivotPoints 枢轴点指标结构体，用上一个时段的最高价、最低价和收盘价计算本时段的支撑位和阻力位
+type PivotPoints struct {
+	method    string
+	timeframe string
+	level     string // 条件判断使用的价位，如 pp、r1、s2
+}
+
+// NewPivotPoints 创建一个新的枢轴点指标
+func NewPivotPoints(params IndicatorParams) (Indicator, error) {
+	method := params.GetString("method", PivotMethodClassic)
+	timeframe := params.GetString("timeframe", PivotTimeframeDaily)
+	level := params.GetString("level", "pp")
+
+	// 验证参数
+	switch method {
+	case PivotMethodClassic, PivotMethodFibonacci, PivotMethodCamarilla:
+	default:
+		return nil, fmt.Errorf("unsupported pivot method: %s", method)
+	}
+
+	if timeframe != PivotTimeframeDaily && timeframe != PivotTimeframeWeekly {
+		return nil, fmt.Errorf("unsupported pivot timeframe: %s", timeframe)
+	}
+
+	valid := false
+	for _, name := range pivotLevels(method) {
+		if name == level {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("unsupported pivot level for %s method: %s", method, level)
+	}
+
+	return &PivotPoints{
+		method:    method,
+		timeframe: timeframe,
+		level:     level,
+	}, nil
+}
+
+// Name 返回指标名称
+func (p *PivotPoints) Name() string {
+	return IndicatorTypePivotPoints
+}
+
+// Calculate 计算每根K线所在时段的枢轴价位，第一个时段没有上一个时段，价位为0
+func (p *PivotPoints) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	if len(data) == 0 {
+		return IndicatorResult{}, fmt.Errorf("not enough data points for pivot points calculation (minimum: 1, got: 0)")
+	}
+
+	names := pivotLevels(p.method)
+	values := make(map[string][]float64, len(names)+3)
+	for _, name := range names {
+		values[name] = make([]float64, len(data))
+	}
+	dates := make([]string, len(data))
+	closes := make([]float64, len(data))
+	highs := make([]float64, len(data))
+	lows := make([]float64, len(data))
+
+	// 累计当前时段的OHLC，时段切换时用刚结束的时段计算新价位
+	var levels map[string]float64
+	var high, low, close float64
+	for i, bar := range data {
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+		closes[i], highs[i], lows[i] = bar.Close, bar.High, bar.Low
+
+		if i == 0 || !p.sameSession(data[i-1].Timestamp, bar.Timestamp) {
+			if i > 0 {
+				levels = pivotValues(p.method, high, low, close)
+			}
+			high, low = bar.High, bar.Low
+		}
+		high = max(high, bar.High)
+		low = min(low, bar.Low)
+		close = bar.Close
+
+		for name, value := range levels {
+			values[name][i] = value
+		}
+	}
+
+	values["close"] = closes
+	values["high"] = highs
+	values["low"] = lows
+	return IndicatorResult{
+		Name:   p.Name(),
+		Values: values,
+		Dates:  dates,
+	}, nil
+}
+
+// EvaluateCondition 评估价格相对参数 level 指定价位的条件，阈值不使用
+// cross_above 和 cross_below 为收盘价穿越该价位，touch_level 为最新K线的区间包含该价位
+func (p *PivotPoints) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	levels := result.Values[p.level]
+	closes := result.Values["close"]
+	if len(levels) == 0 || len(closes) != len(levels) {
+		return false, fmt.Errorf("pivot level %s is missing from result", p.level)
+	}
+	idx := len(levels) - 1
+	if levels[idx] == 0 {
+		// 还没有完整的上一个时段
+		return false, nil
+	}
+
+	switch condition {
+	case ConditionCrossAbove, ConditionCrossBelow:
+		if idx == 0 || levels[idx-1] == 0 {
+			return false, nil
+		}
+		if condition == ConditionCrossAbove {
+			return closes[idx-1] <= levels[idx-1] && closes[idx] > levels[idx], nil
+		}
+		return closes[idx-1] >= levels[idx-1] && closes[idx] < levels[idx], nil
+	case ConditionPriceAboveLevel:
+		return closes[idx] > levels[idx], nil
+	case ConditionPriceBelowLevel:
+		return closes[idx] < levels[idx], nil
+	case ConditionTouchLevel:
+		return result.Values["low"][idx] <= levels[idx] && result.Values["high"][idx] >= levels[idx], nil
+	default:
+		return false, fmt.Errorf("unsupported condition for pivot points: %s", condition)
+	}
+}
+
+// sameSession 判断两根K线是否属于同一个时段，周时段按ISO周划分（内部方法）
+func (p *PivotPoints) sameSession(a, b time.Time) bool {
+	if p.timeframe == PivotTimeframeWeekly {
+		ay, aw := a.ISOWeek()
+		by, bw := b.ISOWeek()
+		return ay == by && aw == bw
+	}
+	return sameDay(a, b)
+}
+
+// pivotLevels 返回计算方法输出的价位名称（内部函数）
+func pivotLevels(method string) []string {
+	if method == PivotMethodCamarilla {
+		return []string{"pp", "r1", "r2", "r3", "r4", "s1", "s2", "s3", "s4"}
+	}
+	return []string{"pp", "r1", "r2", "r3", "s1", "s2", "s3"}
+}
+
+// pivotValues 按计算方法用上一个时段的最高价、最低价和收盘价计算各价位（内部函数）
+func pivotValues(method string, high, low, close float64) map[string]float64 {
+	pp := (high + low + close) / 3
+	r := high - low
+	switch method {
+	case PivotMethodFibonacci:
+		return map[string]float64{
+			"pp": pp,
+			"r1": pp + 0.382*r, "r2": pp + 0.618*r, "r3": pp + r,
+			"s1": pp - 0.382*r, "s2": pp - 0.618*r, "s3": pp - r,
+		}
+	case PivotMethodCamarilla:
+		return map[string]float64{
+			"pp": pp,
+			"r1": close + r*1.1/12, "r2": close + r*1.1/6, "r3": close + r*1.1/4, "r4": close + r*1.1/2,
+			"s1": close - r*1.1/12, "s2": close - r*1.1/6, "s3": close - r*1.1/4, "s4": close - r*1.1/2,
+		}
+	default:
+		return map[string]float64{
+			"pp": pp,
+			"r1": 2*pp - low, "r2": pp + r, "r3": high + 2*(pp-low),
+			"s1": 2*pp - high, "s2": pp - r, "s3": low - 2*(high-pp),
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+func TestPivotPoints(t *testing.T) {
+	day1 := time.Date(2024, 3, 7, 14, 30, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	data := []datasource.StockData{
+		{Timestamp: day1, High: 110, Low: 100, Close: 104},
+		{Timestamp: day1.Add(time.Hour), High: 112, Low: 103, Close: 105},
+		{Timestamp: day2, High: 106, Low: 104, Close: 105},
+		{Timestamp: day2.Add(time.Hour), High: 108, Low: 105, Close: 107.5},
+	}
+
+	pivots, err := NewPivotPoints(IndicatorParams{"level": "r1"})
+	if err != nil {
+		t.Fatalf("创建枢轴点失败: %v", err)
+	}
+	result, err := pivots.Calculate(data)
+	if err != nil {
+		t.Fatalf("计算枢轴点失败: %v", err)
+	}
+	// 上一交易日 H=112 L=100 C=105：PP=105.67，R1=111.33，S1=99.33
+	if result.Values["pp"][1] != 0 {
+		t.Error("第一个交易日不应有枢轴价位")
+	}
+	if got := result.Values["pp"][3]; math.Abs(got-317.0/3) > 1e-9 {
+		t.Errorf("PP = %v", got)
+	}
+	if got := result.Values["r1"][3]; math.Abs(got-(2*317.0/3-100)) > 1e-9 {
+		t.Errorf("R1 = %v", got)
+	}
+	if ok, _ := pivots.EvaluateCondition(result, ConditionPriceBelowLevel, 0); !ok {
+		t.Error("收盘价应低于R1")
+	}
+
+	pp, _ := NewPivotPoints(IndicatorParams{})
+	if ok, _ := pp.EvaluateCondition(result, ConditionCrossAbove, 0); !ok {
+		t.Error("收盘价应向上穿越PP")
+	}
+
+	camarilla, _ := NewPivotPoints(IndicatorParams{"method": PivotMethodCamarilla, "level": "s4"})
+	result, _ = camarilla.Calculate(data)
+	if got := result.Values["s4"][2]; math.Abs(got-(105-12*1.1/2)) > 1e-9 {
+		t.Errorf("Camarilla S4 = %v", got)
+	}
+	if _, err := NewPivotPoints(IndicatorParams{"level": "r4"}); err == nil {
+		t.Error("经典方法没有R4，应返回错误")
+	}
+}
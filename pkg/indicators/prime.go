@@ -0,0 +1,125 @@
+package indicators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PrimeReport 表示一次K线预取的结果
+type PrimeReport struct {
+	Requests int               `json:"requests"` // 预取的股票和周期组合数
+	Bars     int               `json:"bars"`     // 获取到的K线总数
+	Failed   map[string]string `json:"failed"`   // 失败的请求，键为 <股票>/<周期>
+	Duration time.Duration     `json:"duration"`
+}
+
+// primeRequest 表示一个预取请求（内部类型）
+type primeRequest struct {
+	symbol    string
+	timeframe string
+	from      time.Time
+}
+
+// Prime 按扫描的参数预先获取各策略扫描的股票（symbols或策略自己的股票范围）和市场过滤条件引用的股票的K线，使带缓存的数据源在扫描前就绪，
+// 开盘后的第一轮扫描不必等待冷数据下载。strategyNames为空时使用所有启用的策略。
+// 与扫描相同，最多10个并发请求；单个请求失败记录在 PrimeReport.Failed 中，所有请求都失败时返回错误
+func (s *Scanner) Prime(ctx context.Context, symbols []string, strategyNames []string, from, to time.Time, timeframe string) (PrimeReport, error) {
+	startedAt := time.Now()
+	if timeframe == "" {
+		timeframe = s.defaultTimeframe
+	}
+
+	var strategies []Strategy
+	if len(strategyNames) == 0 {
+		for _, strategy := range s.loadStrategies() {
+			if strategy.Enabled {
+				strategies = append(strategies, strategy)
+			}
+		}
+	} else {
+		for _, name := range strategyNames {
+			strategy, err := s.lookupStrategy(name)
+			if err != nil {
+				return PrimeReport{}, err
+			}
+			strategies = append(strategies, strategy)
+		}
+	}
+
+	// 按股票、周期和起点去重，过滤条件的范围与 evaluateFilter 相同
+	seen := make(map[primeRequest]bool)
+	var requests []primeRequest
+	add := func(req primeRequest) {
+		if req.symbol != "" && !seen[req] {
+			seen[req] = true
+			requests = append(requests, req)
+		}
+	}
+	if len(strategies) == 0 {
+		for _, symbol := range symbols {
+			add(primeRequest{symbol: symbol, timeframe: timeframe, from: from})
+		}
+	}
+	for _, strategy := range strategies {
+		strategySymbols, err := s.universeSymbols(ctx, strategy, symbols)
+		if err != nil {
+			return PrimeReport{}, err
+		}
+		for _, symbol := range strategySymbols {
+			add(primeRequest{symbol: symbol, timeframe: timeframe, from: from})
+		}
+		for _, filter := range strategy.Filters {
+			req := primeRequest{symbol: filter.Symbol, timeframe: timeframe, from: from}
+			if filter.Timeframe != "" {
+				req.timeframe = filter.Timeframe
+			}
+			if filter.LookbackDays > 0 {
+				req.from = to.AddDate(0, 0, -filter.LookbackDays)
+			}
+			add(req)
+		}
+	}
+
+	report := PrimeReport{Requests: len(requests), Failed: make(map[string]string)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, 10)
+	for _, req := range requests {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(req primeRequest) {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			data, err := s.dataManager.GetStockData(ctx, req.symbol, req.timeframe, req.from, to)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Failed[req.symbol+"/"+req.timeframe] = err.Error()
+				return
+			}
+			report.Bars += len(data)
+		}(req)
+	}
+	wg.Wait()
+	report.Duration = time.Since(startedAt)
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if report.Requests > 0 && len(report.Failed) == report.Requests {
+		return report, errors.New("failed to prime all requests")
+	}
+	return report, nil
+}
+
+// String 返回预取结果的摘要
+func (r PrimeReport) String() string {
+	return fmt.Sprintf("%d requests, %d bars, %d failed in %s", r.Requests, r.Bars, len(r.Failed), r.Duration.Round(time.Millisecond))
+}
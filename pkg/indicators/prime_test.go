@@ -0,0 +1,64 @@
+package indicators_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+func TestScannerPrime(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	from, to := start, start.AddDate(0, 0, 30)
+
+	source := testutil.NewMockDataSource("")
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		source.SetBars(symbol, "day", testutil.GenerateBars(symbol, start, 24*time.Hour, 20, 100, 1))
+	}
+	source.SetBars("SPY", "hour", testutil.GenerateBars("SPY", start, time.Hour, 50, 500, 1))
+
+	scanner := indicators.NewScanner(indicators.NewIndicatorRegistry(), testutil.NewManager(source))
+	for _, name := range []string{"trend", "trend_copy"} {
+		scanner.AddStrategy(indicators.Strategy{
+			Name:    name,
+			Enabled: true,
+			Indicators: []indicators.IndicatorConfig{
+				{Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5},
+					BuyCondition: indicators.ConditionAboveThreshold},
+			},
+			Filters: []indicators.MarketFilter{
+				{Symbol: "SPY", Timeframe: "hour", Type: indicators.IndicatorTypeSMA, Parameters: indicators.IndicatorParams{"period": 10},
+					Condition: indicators.ConditionAboveThreshold},
+			},
+		})
+	}
+
+	report, err := scanner.Prime(ctx, []string{"AAPL", "MSFT", "AAPL"}, nil, from, to, "day")
+	if err != nil {
+		t.Fatalf("预取失败: %v", err)
+	}
+	// 重复的股票和两个策略引用的同一过滤股票只获取一次，过滤股票使用过滤条件的周期
+	if report.Requests != 3 || source.Calls("GetStockData") != 3 {
+		t.Errorf("请求数 = %d, 获取次数 = %d, 期望都为 3", report.Requests, source.Calls("GetStockData"))
+	}
+	if report.Bars != 90 || len(report.Failed) != 0 {
+		t.Errorf("K线数 = %d, 失败 = %v, 期望 90 根且没有失败", report.Bars, report.Failed)
+	}
+
+	if _, err := scanner.Prime(ctx, []string{"AAPL"}, []string{"missing"}, from, to, "day"); err == nil {
+		t.Error("不存在的策略应返回错误")
+	}
+
+	source.FailWith("GetStockData", errors.New("connection refused"))
+	report, err = scanner.Prime(ctx, []string{"AAPL"}, []string{"trend"}, from, to, "day")
+	if err == nil {
+		t.Error("所有请求都失败时应返回错误")
+	}
+	if _, ok := report.Failed["SPY/hour"]; !ok || len(report.Failed) != 2 {
+		t.Errorf("失败的请求 = %v, 期望 AAPL/day 和 SPY/hour", report.Failed)
+	}
+}
@@ -10,6 +10,7 @@ import (
 // RSI 指标结构体
 type RSI struct {
 	period int
+	source string
 }
 
 // NewRSI 创建一个新的RSI指标
@@ -21,8 +22,14 @@ func NewRSI(params IndicatorParams) (Indicator, error) {
 		return nil, fmt.Errorf("period must be a positive integer")
 	}
 
+	source, err := params.GetSource()
+	if err != nil {
+		return nil, err
+	}
+
 	return &RSI{
 		period: period,
+		source: source,
 	}, nil
 }
 
@@ -38,11 +45,11 @@ func (r *RSI) Calculate(data []datasource.StockData) (IndicatorResult, error) {
 			r.period+1, len(data))
 	}
 
-	// 提取收盘价
+	// 提取输入序列，默认为收盘价
 	prices := make([]float64, len(data))
 	dates := make([]string, len(data))
 	for i, bar := range data {
-		prices[i] = bar.Close
+		prices[i] = SourceValue(bar, r.source)
 		dates[i] = bar.Timestamp.Format(time.RFC3339)
 	}
 
@@ -4,11 +4,29 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
 )
 
+// 扫描器事件类型常量
+const (
+	EventScanSignal    = "scan_signal"    // 扫描器产生信号
+	EventScanCompleted = "scan_completed" // 一轮批量扫描完成
+)
+
+// ScanCycle 表示一轮批量扫描的统计信息
+type ScanCycle struct {
+	Strategy  string        `json:"strategy"`
+	Symbols   int           `json:"symbols"`
+	Matched   int           `json:"matched"` // 产生信号的股票数量
+	Errors    int           `json:"errors"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+}
+
 // ScanResult 表示扫描结果
 type ScanResult struct {
 	Symbol        string    `json:"symbol"`
@@ -20,14 +38,20 @@ type ScanResult struct {
 	IsBuySignal   bool      `json:"is_buy_signal"`
 	IsSellSignal  bool      `json:"is_sell_signal"`
 	Score         float64   `json:"score"` // 组合策略中的得分
+	Explanation   *SignalExplanation `json:"explanation,omitempty"` // 信号触发时各指标的值和条件评估结果
 }
 
 // Scanner 指标扫描器
 type Scanner struct {
 	registry         *IndicatorRegistry
 	dataManager      *datasource.Manager
-	strategies       map[string]Strategy
+	mu               sync.Mutex                         // 串行化策略的修改，读取策略不加锁
+	strategies       atomic.Pointer[map[string]Strategy] // 策略的写时复制快照，扫描期间可以在运行时修改策略
 	defaultTimeframe string
+	eventBus         *events.Bus
+	constituents     datasource.ConstituentProvider // 策略股票范围使用的成分股数据
+	fundMu           sync.Mutex
+	fundamentals     map[string]cachedFundamentals // 按股票缓存的基本面数据
 }
 
 // NewScanner 创建一个新的指标扫描器
@@ -35,44 +59,62 @@ func NewScanner(registry *IndicatorRegistry, dataManager *datasource.Manager) *S
 	return &Scanner{
 		registry:         registry,
 		dataManager:      dataManager,
-		strategies:       make(map[string]Strategy),
 		defaultTimeframe: "day",
+		fundamentals:     make(map[string]cachedFundamentals),
 	}
 }
 
-// AddStrategy 添加策略
+// AddStrategy 添加策略的拷贝，策略股票范围的过滤表达式无效时返回错误
 func (s *Scanner) AddStrategy(strategy Strategy) error {
-	if _, exists := s.strategies[strategy.Name]; exists {
-		return fmt.Errorf("strategy '%s' already exists", strategy.Name)
+	if strategy.Universe.Filter != "" {
+		if _, err := ParseUniverseFilter(strategy.Universe.Filter); err != nil {
+			return fmt.Errorf("strategy '%s': %v", strategy.Name, err)
+		}
 	}
 
-	s.strategies[strategy.Name] = strategy
-	return nil
+	strategy = strategy.Clone()
+	return s.updateStrategies(func(strategies map[string]Strategy) error {
+		if _, exists := strategies[strategy.Name]; exists {
+			return fmt.Errorf("strategy '%s' already exists", strategy.Name)
+		}
+		strategies[strategy.Name] = strategy
+		return nil
+	})
 }
 
-// RemoveStrategy 移除策略
+// RemoveStrategy 移除策略，正在进行的扫描不受影响
 func (s *Scanner) RemoveStrategy(name string) error {
-	if _, exists := s.strategies[name]; !exists {
-		return fmt.Errorf("strategy '%s' does not exist", name)
-	}
-
-	delete(s.strategies, name)
-	return nil
+	return s.updateStrategies(func(strategies map[string]Strategy) error {
+		if _, exists := strategies[name]; !exists {
+			return fmt.Errorf("strategy '%s' does not exist", name)
+		}
+		delete(strategies, name)
+		return nil
+	})
 }
 
-// GetStrategy 获取指定名称的策略
+// GetStrategy 获取指定名称的策略的拷贝，修改返回的策略不影响扫描器
 func (s *Scanner) GetStrategy(name string) (Strategy, error) {
-	strategy, exists := s.strategies[name]
-	if !exists {
-		return Strategy{}, fmt.Errorf("strategy '%s' does not exist", name)
+	strategy, err := s.lookupStrategy(name)
+	if err != nil {
+		return Strategy{}, err
 	}
-
-	return strategy, nil
+	return strategy.Clone(), nil
 }
 
-// GetAllStrategies 获取所有策略
+// GetAllStrategies 获取所有策略的快照，返回的映射和策略都是拷贝，可以与扫描和运行时修改并发使用
 func (s *Scanner) GetAllStrategies() map[string]Strategy {
-	return s.strategies
+	current := s.loadStrategies()
+	strategies := make(map[string]Strategy, len(current))
+	for name, strategy := range current {
+		strategies[name] = strategy.Clone()
+	}
+	return strategies
+}
+
+// SetEventBus 设置事件总线，扫描产生的信号会发布到总线上
+func (s *Scanner) SetEventBus(bus *events.Bus) {
+	s.eventBus = bus
 }
 
 // SetDefaultTimeframe 设置默认时间周期
@@ -80,14 +122,14 @@ func (s *Scanner) SetDefaultTimeframe(timeframe string) {
 	s.defaultTimeframe = timeframe
 }
 
-// ScanSymbol 扫描单个股票
+// ScanSymbol 扫描单个股票，策略有市场过滤条件时先评估过滤条件
 func (s *Scanner) ScanSymbol(ctx context.Context, symbol string, strategyName string, from, to time.Time, timeframe string) ([]ScanResult, error) {
 	if timeframe == "" {
 		timeframe = s.defaultTimeframe
 	}
 
 	// 获取策略
-	strategy, err := s.GetStrategy(strategyName)
+	strategy, err := s.lookupStrategy(strategyName)
 	if err != nil {
 		return nil, err
 	}
@@ -96,12 +138,65 @@ func (s *Scanner) ScanSymbol(ctx context.Context, symbol string, strategyName st
 		return nil, fmt.Errorf("strategy '%s' is disabled", strategyName)
 	}
 
+	gate, err := s.evaluateFilters(ctx, strategy, from, to, timeframe)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.scanSymbol(ctx, symbol, strategy, gate, from, to, timeframe)
+}
+
+// scanSymbol 按策略扫描单个股票，丢弃市场过滤条件不允许的信号；股票不满足策略股票范围的过滤表达式时没有信号（内部方法）
+func (s *Scanner) scanSymbol(ctx context.Context, symbol string, strategy Strategy, gate filterGate, from, to time.Time, timeframe string) ([]ScanResult, error) {
 	// 获取股票数据
 	stockData, err := s.dataManager.GetStockData(ctx, symbol, timeframe, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stock data: %v", err)
 	}
 
+	if len(stockData) == 0 {
+		return nil, fmt.Errorf("no stock data available for symbol '%s'", symbol)
+	}
+	var fundamentals *datasource.Fundamentals
+	if gate.fundamentals {
+		if fundamentals, err = s.getFundamentals(ctx, symbol); err != nil {
+			return nil, fmt.Errorf("failed to get fundamentals: %v", err)
+		}
+	}
+	if !gate.includes(stockData, fundamentals) {
+		return nil, nil
+	}
+
+	results, err := s.EvaluateStrategy(symbol, strategy, stockData)
+	if err != nil {
+		return nil, err
+	}
+
+	// 市场过滤条件不满足时丢弃相应的信号
+	allowed := results[:0]
+	for _, result := range results {
+		if gate.allows(result) {
+			allowed = append(allowed, result)
+		}
+	}
+	results = allowed
+
+	// 发布扫描信号
+	for _, result := range results {
+		s.eventBus.Publish(events.Event{
+			Topic:     events.TopicSignals,
+			Type:      EventScanSignal,
+			Timestamp: time.Now(),
+			Payload:   result,
+		})
+	}
+
+	return results, nil
+}
+
+// EvaluateStrategy 在给定的K线上评估策略的所有指标，返回最后一根K线上触发的信号，不发布事件，也不评估市场过滤条件。
+// 回测按K线逐根调用，与扫描使用相同的信号逻辑
+func (s *Scanner) EvaluateStrategy(symbol string, strategy Strategy, stockData []datasource.StockData) ([]ScanResult, error) {
 	if len(stockData) == 0 {
 		return nil, fmt.Errorf("no stock data available for symbol '%s'", symbol)
 	}
@@ -115,15 +210,21 @@ func (s *Scanner) ScanSymbol(ctx context.Context, symbol string, strategyName st
 	}
 
 	// 如果总权重为0，平均分配权重
-	if totalWeight == 0 {
-		for i := range strategy.Indicators {
-			strategy.Indicators[i].Weight = 1.0 / float64(len(strategy.Indicators))
-		}
-		totalWeight = 1.0
+	equalWeight := totalWeight == 0
+	if equalWeight {
+		totalWeight = float64(len(strategy.Indicators))
 	}
 
+	// 所有指标的最新值和条件评估结果，附在本次评估的每个信号上
+	explanation := &SignalExplanation{Price: stockData[len(stockData)-1].Close}
+
 	// 评估每个指标
 	for _, indConfig := range strategy.Indicators {
+		weight := indConfig.Weight
+		if equalWeight {
+			weight = 1
+		}
+
 		// 创建指标
 		indicator, err := s.registry.CreateIndicator(indConfig.Type, indConfig.Parameters)
 		if err != nil {
@@ -138,6 +239,7 @@ func (s *Scanner) ScanSymbol(ctx context.Context, symbol string, strategyName st
 
 		// 最新价格用于评估条件
 		latestPrice := stockData[len(stockData)-1].Close
+		indExplanation := newIndicatorExplanation(indConfig, result)
 
 		// 评估买入条件
 		if indConfig.BuyCondition != "" {
@@ -145,8 +247,15 @@ func (s *Scanner) ScanSymbol(ctx context.Context, symbol string, strategyName st
 			if err != nil {
 				return nil, fmt.Errorf("failed to evaluate buy condition for indicator '%s': %v", indConfig.Type, err)
 			}
+			indExplanation.Conditions = append(indExplanation.Conditions, ConditionCheck{
+				Side: "buy", Condition: indConfig.BuyCondition, Threshold: indConfig.BuyThreshold, Passed: isBuySignal,
+			})
 
 			if isBuySignal {
+				score := weight / totalWeight
+				if scored, ok := indicator.(ScoredIndicator); ok {
+					score *= scored.SignalStrength(result, indConfig.BuyCondition)
+				}
 				scanResult := ScanResult{
 					Symbol:        symbol,
 					Timestamp:     stockData[len(stockData)-1].Timestamp,
@@ -156,7 +265,8 @@ func (s *Scanner) ScanSymbol(ctx context.Context, symbol string, strategyName st
 					Threshold:     indConfig.BuyThreshold,
 					IsBuySignal:   true,
 					IsSellSignal:  false,
-					Score:         indConfig.Weight / totalWeight,
+					Score:         score,
+					Explanation:   explanation,
 				}
 				results = append(results, scanResult)
 			}
@@ -168,8 +278,15 @@ func (s *Scanner) ScanSymbol(ctx context.Context, symbol string, strategyName st
 			if err != nil {
 				return nil, fmt.Errorf("failed to evaluate sell condition for indicator '%s': %v", indConfig.Type, err)
 			}
+			indExplanation.Conditions = append(indExplanation.Conditions, ConditionCheck{
+				Side: "sell", Condition: indConfig.SellCondition, Threshold: indConfig.SellThreshold, Passed: isSellSignal,
+			})
 
 			if isSellSignal {
+				score := weight / totalWeight
+				if scored, ok := indicator.(ScoredIndicator); ok {
+					score *= scored.SignalStrength(result, indConfig.SellCondition)
+				}
 				scanResult := ScanResult{
 					Symbol:        symbol,
 					Timestamp:     stockData[len(stockData)-1].Timestamp,
@@ -179,21 +296,45 @@ func (s *Scanner) ScanSymbol(ctx context.Context, symbol string, strategyName st
 					Threshold:     indConfig.SellThreshold,
 					IsBuySignal:   false,
 					IsSellSignal:  true,
-					Score:         indConfig.Weight / totalWeight,
+					Score:         score,
+					Explanation:   explanation,
 				}
 				results = append(results, scanResult)
 			}
 		}
+		explanation.Indicators = append(explanation.Indicators, indExplanation)
 	}
 
 	return results, nil
 }
 
-// ScanMultipleSymbols 批量扫描多个股票
+// ScanMultipleSymbols 批量扫描多个股票，策略设置了自己的股票范围时扫描策略的股票而不是symbols（见 StrategySymbols）
 func (s *Scanner) ScanMultipleSymbols(ctx context.Context, symbols []string, strategyName string, from, to time.Time, timeframe string) (map[string][]ScanResult, error) {
+	startedAt := time.Now()
 	results := make(map[string][]ScanResult)
+	errorCount := 0
 	var mu sync.Mutex
 	var wg sync.WaitGroup
+
+	if timeframe == "" {
+		timeframe = s.defaultTimeframe
+	}
+
+	// 市场过滤条件每轮只评估一次，过滤股票的数据在所有股票间共享
+	strategy, err := s.lookupStrategy(strategyName)
+	if err != nil {
+		return results, err
+	}
+	if !strategy.Enabled {
+		return results, fmt.Errorf("strategy '%s' is disabled", strategyName)
+	}
+	if symbols, err = s.universeSymbols(ctx, strategy, symbols); err != nil {
+		return results, err
+	}
+	gate, err := s.evaluateFilters(ctx, strategy, from, to, timeframe)
+	if err != nil {
+		return results, err
+	}
 	errorsChan := make(chan error, len(symbols))
 
 	// 创建一个工作池
@@ -208,8 +349,11 @@ func (s *Scanner) ScanMultipleSymbols(ctx context.Context, symbols []string, str
 			defer func() { <-workers }() // 释放工作槽
 			
 			// 扫描单个股票
-			symbolResults, err := s.ScanSymbol(ctx, symbol, strategyName, from, to, timeframe)
+			symbolResults, err := s.scanSymbol(ctx, symbol, strategy, gate, from, to, timeframe)
 			if err != nil {
+				mu.Lock()
+				errorCount++
+				mu.Unlock()
 				select {
 				case errorsChan <- fmt.Errorf("failed to scan symbol '%s': %v", symbol, err):
 				default:
@@ -228,6 +372,21 @@ func (s *Scanner) ScanMultipleSymbols(ctx context.Context, symbols []string, str
 	
 	wg.Wait()
 	close(errorsChan)
+
+	// 发布扫描完成事件
+	s.eventBus.Publish(events.Event{
+		Topic:     events.TopicSignals,
+		Type:      EventScanCompleted,
+		Timestamp: time.Now(),
+		Payload: ScanCycle{
+			Strategy:  strategyName,
+			Symbols:   len(symbols),
+			Matched:   len(results),
+			Errors:    errorCount,
+			StartedAt: startedAt,
+			Duration:  time.Since(startedAt),
+		},
+	})
 	
 	// 检查是否有错误发生
 	select {
@@ -0,0 +1,69 @@
+package indicators
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SeriesWarmupBars 是 GetIndicatorSeries 在请求区间之前大约多取的K线数，用于指标预热
+const SeriesWarmupBars = 200
+
+// GetIndicatorSeries 计算单个指标在 [from, to] 内的输出，一次返回对齐的K线和指标序列，供图表前端绘图。
+// 在区间之前多取约 SeriesWarmupBars 根K线预热，返回的序列只包含区间内的K线，区间开头的值与扫描时一致。
+// K线通过数据管理器获取，数据源启用K线缓存时已缓存的范围不再请求数据源；timeframe 为空时使用扫描器的默认周期
+func (s *Scanner) GetIndicatorSeries(ctx context.Context, symbol string, config IndicatorConfig, from, to time.Time, timeframe string) (SeriesFrame, error) {
+	if timeframe == "" {
+		timeframe = s.defaultTimeframe
+	}
+	if !from.Before(to) {
+		return SeriesFrame{}, fmt.Errorf("from must be before to")
+	}
+
+	indicator, err := s.registry.CreateIndicator(config.Type, config.Parameters)
+	if err != nil {
+		return SeriesFrame{}, fmt.Errorf("failed to create indicator '%s': %v", config.Type, err)
+	}
+
+	bars, err := s.dataManager.GetStockData(ctx, symbol, timeframe, warmupStart(from, timeframe), to)
+	if err != nil {
+		return SeriesFrame{}, fmt.Errorf("failed to get stock data: %v", err)
+	}
+	start := sort.Search(len(bars), func(i int) bool { return !bars[i].Timestamp.Before(from) })
+	if start >= len(bars) {
+		return SeriesFrame{Symbol: symbol, Timeframe: timeframe}, nil
+	}
+
+	result, err := indicator.Calculate(bars)
+	if err != nil {
+		return SeriesFrame{}, fmt.Errorf("failed to calculate indicator '%s': %v", config.Type, err)
+	}
+	if config.Name != "" {
+		result.Name = config.Name
+	}
+
+	// 在全部K线上对齐后截掉预热部分
+	frame := NewSeriesFrame(symbol, timeframe, bars, result)
+	frame.Bars = frame.Bars[start:]
+	for i := range frame.Columns {
+		frame.Columns[i].Values = frame.Columns[i].Values[start:]
+	}
+	return frame, nil
+}
+
+// warmupStart 返回覆盖约 SeriesWarmupBars 根K线的起始时间，按日历时间估算，包括周末和非交易时段（内部函数）
+func warmupStart(from time.Time, timeframe string) time.Time {
+	switch timeframe {
+	case "minute":
+		return from.AddDate(0, 0, -4) // 一个交易日有390根分钟K线，跨过周末
+	case "hour":
+		return from.AddDate(0, 0, -45)
+	case "week":
+		return from.AddDate(0, 0, -7*SeriesWarmupBars)
+	case "month":
+		return from.AddDate(0, -SeriesWarmupBars, 0)
+	default:
+		return from.AddDate(0, 0, -SeriesWarmupBars*3/2) // 每周5个交易日，另加节假日
+	}
+}
@@ -0,0 +1,44 @@
+package indicators_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+func TestGetIndicatorSeries(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := testutil.NewMockDataSource("")
+	source.SetBars("AAPL", "day", testutil.GenerateBars("AAPL", start, 24*time.Hour, 400, 100, 1))
+	scanner := indicators.NewScanner(indicators.NewIndicatorRegistry(), testutil.NewManager(source))
+
+	config := indicators.IndicatorConfig{Name: "roc5", Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5}}
+	from, to := start.AddDate(0, 0, 350), start.AddDate(0, 0, 359)
+	frame, err := scanner.GetIndicatorSeries(ctx, "AAPL", config, from, to, "")
+	if err != nil {
+		t.Fatalf("获取指标序列失败: %v", err)
+	}
+	if frame.Timeframe != "day" || len(frame.Bars) != 10 || !frame.Bars[0].Timestamp.Equal(from) {
+		t.Fatalf("只应返回区间内的K线: %d %+v", len(frame.Bars), frame.Bars)
+	}
+	if len(frame.Columns) != 1 || frame.Columns[0].Name != "roc5.roc" || len(frame.Columns[0].Values) != 10 {
+		t.Fatalf("指标输出应与K线对齐: %+v", frame.Columns)
+	}
+	// 收盘价为 100+i，预热后区间第一根K线的5日ROC为 500/(95+350)
+	if got := frame.Columns[0].Values[0]; math.IsNaN(got) || math.Abs(got-500.0/445) > 1e-9 {
+		t.Errorf("区间开头的值应已预热: %v", got)
+	}
+
+	empty, err := scanner.GetIndicatorSeries(ctx, "AAPL", config, start.AddDate(2, 0, 0), start.AddDate(2, 1, 0), "day")
+	if err != nil || len(empty.Bars) != 0 || len(empty.Columns) != 0 {
+		t.Errorf("区间内没有K线时应返回空序列: %+v %v", empty, err)
+	}
+	if _, err := scanner.GetIndicatorSeries(ctx, "AAPL", indicators.IndicatorConfig{Type: "Unknown"}, from, to, ""); err == nil {
+		t.Error("未注册的指标应返回错误")
+	}
+}
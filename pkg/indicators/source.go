@@ -0,0 +1,68 @@
+package indicators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// 指标输入序列，通过指标参数 "source" 设置，默认为收盘价
+const (
+	SourceClose   = "close"
+	SourceOpen    = "open"
+	SourceHigh    = "high"
+	SourceLow     = "low"
+	SourceHL2     = "hl2"     // (最高价+最低价)/2
+	SourceHLC3    = "hlc3"    // (最高价+最低价+收盘价)/3
+	SourceTypical = "typical" // 典型价格，同 hlc3
+	SourceOHLC4   = "ohlc4"   // (开盘价+最高价+最低价+收盘价)/4
+	SourceVolume  = "volume"
+)
+
+// GetSource 读取参数 "source"，为空时为收盘价，不支持的输入序列返回错误
+func (p IndicatorParams) GetSource() (string, error) {
+	source := p.GetString("source", SourceClose)
+	switch source {
+	case SourceClose, SourceOpen, SourceHigh, SourceLow, SourceHL2, SourceHLC3, SourceTypical, SourceOHLC4, SourceVolume:
+		return source, nil
+	default:
+		return "", fmt.Errorf("unsupported source: %s", source)
+	}
+}
+
+// SourceValue 返回K线在输入序列source上的值，source需已通过 GetSource 校验
+func SourceValue(bar datasource.StockData, source string) float64 {
+	switch source {
+	case SourceOpen:
+		return bar.Open
+	case SourceHigh:
+		return bar.High
+	case SourceLow:
+		return bar.Low
+	case SourceHL2:
+		return (bar.High + bar.Low) / 2
+	case SourceHLC3, SourceTypical:
+		return (bar.High + bar.Low + bar.Close) / 3
+	case SourceOHLC4:
+		return (bar.Open + bar.High + bar.Low + bar.Close) / 4
+	case SourceVolume:
+		return float64(bar.Volume)
+	default:
+		return bar.Close
+	}
+}
+
+// sourceSeries 提取输入序列和日期，K线少于minimum根时返回错误（内部函数）
+func sourceSeries(data []datasource.StockData, source string, minimum int, name string) ([]float64, []string, error) {
+	if len(data) < minimum {
+		return nil, nil, fmt.Errorf("not enough data points for %s calculation (minimum: %d, got: %d)", name, minimum, len(data))
+	}
+	values := make([]float64, len(data))
+	dates := make([]string, len(data))
+	for i, bar := range data {
+		values[i] = SourceValue(bar, source)
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+	}
+	return values, dates, nil
+}
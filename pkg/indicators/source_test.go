@@ -0,0 +1,52 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+func TestIndicatorSource(t *testing.T) {
+	start := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	var data []datasource.StockData
+	for i := 0; i < 3; i++ {
+		price := float64(100 + i)
+		data = append(data, datasource.StockData{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Open:      price - 1, High: price + 2, Low: price - 2, Close: price, Volume: int64(1000 * (i + 1)),
+		})
+	}
+
+	cases := map[string]float64{
+		"":           101,
+		SourceClose:  101,
+		SourceHL2:    101,
+		SourceHLC3:   101,
+		SourceOHLC4:  100.75,
+		SourceOpen:   100,
+		SourceVolume: 2000,
+	}
+	for source, want := range cases {
+		params := IndicatorParams{"period": 3}
+		if source != "" {
+			params["source"] = source
+		}
+		sma, err := NewSMA(params)
+		if err != nil {
+			t.Fatalf("创建SMA失败 (%s): %v", source, err)
+		}
+		result, err := sma.Calculate(data)
+		if err != nil {
+			t.Fatalf("计算SMA失败 (%s): %v", source, err)
+		}
+		if got := result.Values["sma"][2]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("source=%q 的SMA = %v, 期望 %v", source, got, want)
+		}
+	}
+
+	if _, err := NewRSI(IndicatorParams{"source": "median"}); err == nil {
+		t.Error("不支持的输入序列应返回错误")
+	}
+}
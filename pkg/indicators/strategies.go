@@ -0,0 +1,83 @@
+package indicators
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errUnchanged 表示修改没有改变策略，updateStrategies 收到后不替换快照（内部变量）
+var errUnchanged = errors.New("strategy unchanged")
+
+// Clone 返回策略的深拷贝，修改拷贝的指标、过滤条件、参数和股票范围不影响原策略
+func (st Strategy) Clone() Strategy {
+	if st.Indicators != nil {
+		indicators := make([]IndicatorConfig, len(st.Indicators))
+		for i, ind := range st.Indicators {
+			ind.Parameters = ind.Parameters.clone()
+			indicators[i] = ind
+		}
+		st.Indicators = indicators
+	}
+	if st.Filters != nil {
+		filters := make([]MarketFilter, len(st.Filters))
+		for i, filter := range st.Filters {
+			filter.Parameters = filter.Parameters.clone()
+			filters[i] = filter
+		}
+		st.Filters = filters
+	}
+	if st.Universe.Symbols != nil {
+		st.Universe.Symbols = append([]string(nil), st.Universe.Symbols...)
+	}
+	if st.Universe.Sectors != nil {
+		st.Universe.Sectors = append([]string(nil), st.Universe.Sectors...)
+	}
+	return st
+}
+
+// clone 返回参数的浅拷贝（内部方法）
+func (p IndicatorParams) clone() IndicatorParams {
+	if p == nil {
+		return nil
+	}
+	params := make(IndicatorParams, len(p))
+	for k, v := range p {
+		params[k] = v
+	}
+	return params
+}
+
+// loadStrategies 返回当前的策略快照，快照创建后不再修改，读取时不需要加锁（内部方法）
+func (s *Scanner) loadStrategies() map[string]Strategy {
+	if strategies := s.strategies.Load(); strategies != nil {
+		return *strategies
+	}
+	return nil
+}
+
+// lookupStrategy 从当前快照中查找策略，返回的策略与快照共享指标和过滤条件，调用方不得修改（内部方法）
+func (s *Scanner) lookupStrategy(name string) (Strategy, error) {
+	strategy, exists := s.loadStrategies()[name]
+	if !exists {
+		return Strategy{}, fmt.Errorf("strategy '%s' does not exist", name)
+	}
+	return strategy, nil
+}
+
+// updateStrategies 复制当前快照后交给 fn 修改，fn 返回错误时丢弃修改，否则原子替换快照（内部方法）
+// 修改之间由 mu 串行化，正在进行的扫描和查询继续使用旧的快照
+func (s *Scanner) updateStrategies(fn func(strategies map[string]Strategy) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.loadStrategies()
+	strategies := make(map[string]Strategy, len(current)+1)
+	for name, strategy := range current {
+		strategies[name] = strategy
+	}
+	if err := fn(strategies); err != nil {
+		return err
+	}
+	s.strategies.Store(&strategies)
+	return nil
+}
@@ -0,0 +1,89 @@
+package indicators
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestStrategyClone(t *testing.T) {
+	scanner := NewScanner(NewIndicatorRegistry(), nil)
+	strategy := Strategy{
+		Name:       "momentum",
+		Enabled:    true,
+		Indicators: []IndicatorConfig{{Type: IndicatorTypeRSI, Parameters: IndicatorParams{"period": 14}}},
+		Filters:    []MarketFilter{{Symbol: "SPY", Type: IndicatorTypeSMA, Parameters: IndicatorParams{"period": 50}}},
+		Universe:   Universe{Symbols: []string{"AAPL"}},
+	}
+	if err := scanner.AddStrategy(strategy); err != nil {
+		t.Fatalf("添加策略失败: %v", err)
+	}
+
+	// 修改添加时传入的策略和查询返回的策略都不影响扫描器中的策略
+	strategy.Indicators[0].Parameters["period"] = 2
+	got, _ := scanner.GetStrategy("momentum")
+	got.Indicators[0].BuyThreshold = 99
+	got.Filters[0].Parameters["period"] = 5
+	got.Universe.Symbols[0] = "MSFT"
+	s := scanner.GetAllStrategies()["momentum"]
+	if s.Indicators[0].Parameters["period"] != 14 || s.Indicators[0].BuyThreshold != 0 ||
+		s.Filters[0].Parameters["period"] != 50 || s.Universe.Symbols[0] != "AAPL" {
+		t.Fatalf("扫描器中的策略被修改: %+v", s)
+	}
+
+	// 运行时修改只替换快照，之前取得的快照保持不变
+	snapshot := scanner.GetAllStrategies()
+	if err := scanner.SetIndicatorParameter("momentum", IndicatorTypeRSI, "period", 7, "test"); err != nil {
+		t.Fatalf("修改参数失败: %v", err)
+	}
+	scanner.RemoveStrategy("momentum")
+	if snapshot["momentum"].Indicators[0].Parameters["period"] != 14 {
+		t.Errorf("之前取得的快照不应改变: %+v", snapshot["momentum"])
+	}
+	if _, err := scanner.GetStrategy("momentum"); err == nil {
+		t.Error("策略应已移除")
+	}
+}
+
+func TestConcurrentStrategyEdits(t *testing.T) {
+	scanner := NewScanner(NewIndicatorRegistry(), nil)
+	scanner.AddStrategy(Strategy{Name: "base", Enabled: true, Indicators: []IndicatorConfig{{Type: IndicatorTypeRSI, Parameters: IndicatorParams{"period": 14}}}})
+
+	// 添加、移除、修改和查询并发进行，使用 -race 运行时不应报告数据竞争
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("s%d", i)
+			for j := 0; j < 50; j++ {
+				scanner.AddStrategy(Strategy{Name: name})
+				scanner.RemoveStrategy(name)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				scanner.SetStrategyEnabled("base", j%2 == 0, "test")
+				scanner.SetIndicatorParameter("base", IndicatorTypeRSI, "period", 10+i, "test")
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				for _, strategy := range scanner.GetAllStrategies() {
+					_ = strategy.Indicators
+				}
+				if strategy, err := scanner.GetStrategy("base"); err != nil || len(strategy.Indicators) != 1 {
+					t.Errorf("查询策略失败: %+v %v", strategy, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if all := scanner.GetAllStrategies(); len(all) != 1 {
+		t.Errorf("并发添加和移除后应只剩下一个策略: %v", all)
+	}
+}
@@ -0,0 +1,258 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// SwingPoint 表示一个摆动高点或低点
+type SwingPoint struct {
+	Index int // 在K线序列中的位置
+	Time  time.Time
+	Price float64
+	High  bool // true为摆动高点，false为摆动低点
+}
+
+// SRZone 表示由价格相近的摆动点聚合成的支撑/阻力区
+type SRZone struct {
+	Low     float64
+	High    float64
+	Center  float64 // 摆动点价格的均值
+	Touches int     // 区内摆动点的数量
+	Last    int     // 最近一个摆动点的K线位置
+}
+
+// FindSwings 查找摆动点：最高价高于前left根、且不低于后right根K线最高价的为摆动高点，低点同理。
+// 最后right根K线还没有确认，不会产生摆动点。结果按K线顺序排列
+func FindSwings(data []datasource.StockData, left, right int) []SwingPoint {
+	var swings []SwingPoint
+	for i := left; i+right < len(data); i++ {
+		isHigh, isLow := true, true
+		for j := i - left; j <= i+right && (isHigh || isLow); j++ {
+			if j == i {
+				continue
+			}
+			if j < i {
+				isHigh = isHigh && data[i].High > data[j].High
+				isLow = isLow && data[i].Low < data[j].Low
+			} else {
+				isHigh = isHigh && data[i].High >= data[j].High
+				isLow = isLow && data[i].Low <= data[j].Low
+			}
+		}
+		if isHigh {
+			swings = append(swings, SwingPoint{Index: i, Time: data[i].Timestamp, Price: data[i].High, High: true})
+		}
+		if isLow {
+			swings = append(swings, SwingPoint{Index: i, Time: data[i].Timestamp, Price: data[i].Low})
+		}
+	}
+	return swings
+}
+
+// ClusterZones 把价格相差不超过tolerance（比例，如0.005）的摆动点聚合为支撑/阻力区，
+// 高点和低点一起聚合（突破后阻力转为支撑），结果按价格从低到高排列
+func ClusterZones(swings []SwingPoint, tolerance float64) []SRZone {
+	if len(swings) == 0 {
+		return nil
+	}
+	sorted := append([]SwingPoint(nil), swings...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Price < sorted[j].Price })
+
+	var zones []SRZone
+	var sum float64
+	for _, s := range sorted {
+		n := len(zones)
+		if n > 0 && s.Price <= zones[n-1].Low*(1+tolerance) {
+			zone := &zones[n-1]
+			zone.High = s.Price
+			zone.Touches++
+			zone.Last = max(zone.Last, s.Index)
+			sum += s.Price
+			zone.Center = sum / float64(zone.Touches)
+			continue
+		}
+		zones = append(zones, SRZone{Low: s.Price, High: s.Price, Center: s.Price, Touches: 1, Last: s.Index})
+		sum = s.Price
+	}
+	return zones
+}
+
+// Swing 摆动点和支撑/阻力指标结构体，只使用到每根K线为止已经确认的摆动点，没有未来函数
+type Swing struct {
+	left       int
+	right      int
+	lookback   int     // 聚合支撑/阻力区使用的K线数
+	tolerance  float64 // 聚合和接近判断的价格容差（比例）
+	minTouches int     // 成为支撑/阻力区所需的摆动点数量
+}
+
+// NewSwing 创建一个新的摆动点指标
+func NewSwing(params IndicatorParams) (Indicator, error) {
+	left := params.GetInt("left", 3)
+	right := params.GetInt("right", 3)
+	lookback := params.GetInt("lookback", 100)
+	tolerance := params.GetFloat("tolerance", 0.5)
+	minTouches := params.GetInt("min_touches", 2)
+
+	// 验证参数
+	if left <= 0 || right <= 0 {
+		return nil, fmt.Errorf("left and right must be positive integers")
+	}
+
+	if lookback <= left+right {
+		return nil, fmt.Errorf("lookback must be greater than left + right")
+	}
+
+	if tolerance <= 0 {
+		return nil, fmt.Errorf("tolerance must be positive")
+	}
+
+	if minTouches <= 0 {
+		return nil, fmt.Errorf("min_touches must be a positive integer")
+	}
+
+	return &Swing{
+		left:       left,
+		right:      right,
+		lookback:   lookback,
+		tolerance:  tolerance / 100,
+		minTouches: minTouches,
+	}, nil
+}
+
+// Name 返回指标名称
+func (s *Swing) Name() string {
+	return IndicatorTypeSwing
+}
+
+// Calculate 计算每根K线时最近确认的摆动高低点、最近的支撑/阻力区中心和最近一次结构突破的价位，没有时为0
+func (s *Swing) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	minimum := s.left + s.right + 1
+	if len(data) < minimum {
+		return IndicatorResult{}, fmt.Errorf("not enough data points for Swing calculation (minimum: %d, got: %d)",
+			minimum, len(data))
+	}
+
+	n := len(data)
+	dates := make([]string, n)
+	closes, highs, lows := make([]float64, n), make([]float64, n), make([]float64, n)
+	swingHigh, swingLow := make([]float64, n), make([]float64, n)
+	support, resistance := make([]float64, n), make([]float64, n)
+	brokenHigh, brokenLow := make([]float64, n), make([]float64, n)
+
+	swings := FindSwings(data, s.left, s.right)
+	next := 0 // swings中下一个还没确认的摆动点
+	var lastHigh, lastLow, upLevel, downLevel float64
+	for i, bar := range data {
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+		closes[i], highs[i], lows[i] = bar.Close, bar.High, bar.Low
+
+		// 摆动点在其后right根K线收盘时确认
+		for next < len(swings) && swings[next].Index+s.right <= i {
+			if swings[next].High {
+				lastHigh = swings[next].Price
+			} else {
+				lastLow = swings[next].Price
+			}
+			next++
+		}
+
+		// 收盘价突破最近的摆动点即为结构突破，突破失败（收回容差之外）后清除
+		if i > 0 && lastHigh > 0 && closes[i-1] <= swingHigh[i-1] && bar.Close > lastHigh {
+			upLevel = lastHigh
+		} else if upLevel > 0 && bar.Close < upLevel*(1-s.tolerance) {
+			upLevel = 0
+		}
+		if i > 0 && lastLow > 0 && closes[i-1] >= swingLow[i-1] && bar.Close < lastLow {
+			downLevel = lastLow
+		} else if downLevel > 0 && bar.Close > downLevel*(1+s.tolerance) {
+			downLevel = 0
+		}
+
+		swingHigh[i], swingLow[i] = lastHigh, lastLow
+		brokenHigh[i], brokenLow[i] = upLevel, downLevel
+		support[i], resistance[i] = s.nearestZones(swings[:next], i, bar.Close)
+	}
+
+	return IndicatorResult{
+		Name: s.Name(),
+		Values: map[string][]float64{
+			"swing_high":  swingHigh,
+			"swing_low":   swingLow,
+			"support":     support,
+			"resistance":  resistance,
+			"broken_high": brokenHigh,
+			"broken_low":  brokenLow,
+			"close":       closes,
+			"high":        highs,
+			"low":         lows,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// nearestZones 用lookback内已确认的摆动点聚合支撑/阻力区，返回收盘价下方最近的支撑区和上方最近的阻力区中心（内部方法）
+func (s *Swing) nearestZones(confirmed []SwingPoint, i int, close float64) (float64, float64) {
+	start := len(confirmed)
+	for start > 0 && confirmed[start-1].Index > i-s.lookback {
+		start--
+	}
+
+	var support, resistance float64
+	for _, zone := range ClusterZones(confirmed[start:], s.tolerance) {
+		if zone.Touches < s.minTouches {
+			continue
+		}
+		if zone.Center <= close {
+			support = zone.Center
+		} else if resistance == 0 {
+			resistance = zone.Center
+		}
+	}
+	return support, resistance
+}
+
+// EvaluateCondition 评估摆动点指标条件，阈值不使用
+// break_of_structure_up/down 为收盘价突破最近的摆动高点/跌破最近的摆动低点；
+// retest_breakout/breakdown 为突破后回踩（反抽）被突破的价位且收盘仍在其外侧；near_support/near_resistance 为收盘价在支撑/阻力区容差内
+func (s *Swing) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	closes := result.Values["close"]
+	if len(closes) == 0 {
+		return false, fmt.Errorf("Swing result is empty")
+	}
+	idx := len(closes) - 1
+	prevIdx := idx - 1
+	if prevIdx < 0 {
+		return false, fmt.Errorf("not enough data points for Swing condition evaluation")
+	}
+	value := func(name string) float64 { return result.Values[name][idx] }
+	near := func(level float64) bool {
+		return level > 0 && math.Abs(closes[idx]-level) <= level*s.tolerance
+	}
+
+	switch condition {
+	case ConditionBreakOfStructureUp:
+		return value("broken_high") > 0 && result.Values["broken_high"][prevIdx] != value("broken_high"), nil
+	case ConditionBreakOfStructureDown:
+		return value("broken_low") > 0 && result.Values["broken_low"][prevIdx] != value("broken_low"), nil
+	case ConditionRetestBreakout:
+		level := value("broken_high")
+		return level > 0 && result.Values["broken_high"][prevIdx] == level &&
+			value("low") <= level*(1+s.tolerance) && closes[idx] >= level, nil
+	case ConditionRetestBreakdown:
+		level := value("broken_low")
+		return level > 0 && result.Values["broken_low"][prevIdx] == level &&
+			value("high") >= level*(1-s.tolerance) && closes[idx] <= level, nil
+	case ConditionNearSupport:
+		return near(value("support")), nil
+	case ConditionNearResistance:
+		return near(value("resistance")), nil
+	default:
+		return false, fmt.Errorf("unsupported condition for Swing: %s", condition)
+	}
+}
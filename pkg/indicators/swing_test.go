@@ -0,0 +1,53 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSwingBreakAndRetest(t *testing.T) {
+	closes := []float64{100, 102, 104, 106, 108, 106, 104, 102, 103, 105, 107, 109, 111, 110}
+	data := flatBars(closes, make([]int64, len(closes)))
+
+	swings := FindSwings(data, 2, 2)
+	if len(swings) != 2 || !swings[0].High || swings[0].Price != 109 || swings[1].High || swings[1].Price != 101 {
+		t.Fatalf("摆动点 = %+v", swings)
+	}
+
+	swing, err := NewSwing(IndicatorParams{"left": 2, "right": 2, "lookback": 20})
+	if err != nil {
+		t.Fatalf("创建摆动点指标失败: %v", err)
+	}
+	result, err := swing.Calculate(data[:13])
+	if err != nil {
+		t.Fatalf("计算摆动点指标失败: %v", err)
+	}
+	if result.Values["swing_high"][5] != 0 || result.Values["swing_high"][6] != 109 {
+		t.Error("摆动高点应在其后第2根K线确认")
+	}
+	if ok, _ := swing.EvaluateCondition(result, ConditionBreakOfStructureUp, 0); !ok {
+		t.Error("收盘价突破摆动高点应为结构突破")
+	}
+
+	result, _ = swing.Calculate(data)
+	if ok, _ := swing.EvaluateCondition(result, ConditionBreakOfStructureUp, 0); ok {
+		t.Error("结构突破只在突破的K线上触发")
+	}
+	if ok, _ := swing.EvaluateCondition(result, ConditionRetestBreakout, 0); !ok {
+		t.Error("突破后回踩被突破的价位应触发回踩条件")
+	}
+}
+
+func TestClusterZones(t *testing.T) {
+	zones := ClusterZones([]SwingPoint{
+		{Index: 1, Price: 105, High: true},
+		{Index: 3, Price: 100},
+		{Index: 8, Price: 100.3, High: true},
+	}, 0.005)
+	if len(zones) != 2 {
+		t.Fatalf("支撑/阻力区数量 = %d, 期望 2", len(zones))
+	}
+	if zones[0].Touches != 2 || zones[0].Last != 8 || math.Abs(zones[0].Center-100.15) > 1e-9 {
+		t.Errorf("聚合的区域 = %+v", zones[0])
+	}
+}
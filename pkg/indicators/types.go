@@ -15,6 +15,16 @@ const (
 	IndicatorTypeKDJ      = "KDJ"
 	IndicatorTypeATR      = "ATR"
 	IndicatorTypeVWAP     = "VWAP"
+	IndicatorTypeMFI      = "MFI"
+	IndicatorTypeChaikin  = "ChaikinOscillator"
+	IndicatorTypeROC      = "ROC"
+	IndicatorTypeMomentum = "Momentum"
+	IndicatorTypeWilliamsR = "WilliamsR"
+	IndicatorTypeCCI      = "CCI"
+	IndicatorTypeUltimate = "UltimateOscillator"
+	IndicatorTypePivotPoints = "PivotPoints"
+	IndicatorTypeSwing    = "Swing"
+	IndicatorTypeModel    = "Model"
 )
 
 // 条件类型常量
@@ -28,6 +38,23 @@ const (
 	ConditionPriceWithinBands = "price_within_bands"
 	ConditionIncreasing      = "increasing"
 	ConditionDecreasing      = "decreasing"
+	ConditionTouchUpper      = "touch_upper"       // 最高价触及上轨
+	ConditionTouchLower      = "touch_lower"       // 最低价触及下轨
+	ConditionRevertFromUpper = "revert_from_upper" // 收盘价从上轨之外收回轨内
+	ConditionRevertFromLower = "revert_from_lower" // 收盘价从下轨之外收回轨内
+	ConditionOverbought      = "overbought"        // 高于超买水平
+	ConditionOversold        = "oversold"          // 低于超卖水平
+	ConditionPriceAboveLevel = "price_above_level" // 收盘价高于指定价位
+	ConditionPriceBelowLevel = "price_below_level" // 收盘价低于指定价位
+	ConditionTouchLevel      = "touch_level"       // K线区间包含指定价位
+	ConditionBreakOfStructureUp   = "break_of_structure_up"   // 收盘价突破最近的摆动高点
+	ConditionBreakOfStructureDown = "break_of_structure_down" // 收盘价跌破最近的摆动低点
+	ConditionRetestBreakout       = "retest_breakout"         // 突破后回踩被突破的摆动高点
+	ConditionRetestBreakdown      = "retest_breakdown"        // 跌破后反抽被跌破的摆动低点
+	ConditionNearSupport          = "near_support"            // 收盘价接近支撑区
+	ConditionNearResistance       = "near_resistance"         // 收盘价接近阻力区
+	ConditionModelBuy             = "model_buy"               // 模型买入得分不低于阈值
+	ConditionModelSell            = "model_sell"              // 模型卖出得分不低于阈值
 )
 
 // Indicator 定义了一个技术指标的接口
@@ -42,6 +69,12 @@ type Indicator interface {
 	EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error)
 }
 
+// ScoredIndicator 由输出连续得分的指标（如模型）实现，扫描器把信号权重乘以得分（0到1）
+type ScoredIndicator interface {
+	// SignalStrength 返回条件在最新K线上的得分
+	SignalStrength(result IndicatorResult, condition string) float64
+}
+
 // IndicatorResult 表示指标计算结果
 type IndicatorResult struct {
 	Name   string             `json:"name"`
@@ -117,6 +150,8 @@ type Strategy struct {
 	Name       string            `json:"name" yaml:"name"`
 	Enabled    bool              `json:"enabled" yaml:"enabled"`
 	Indicators []IndicatorConfig `json:"indicators" yaml:"indicators"`
+	Filters    []MarketFilter    `json:"filters,omitempty" yaml:"filters"` // 市场过滤条件，全部满足时才输出信号
+	Universe   Universe          `json:"universe" yaml:"universe"`         // 策略自己的股票范围，为空时扫描调用方传入的股票
 }
 
 // IndicatorFactory 创建指标的工厂函数类型
@@ -0,0 +1,272 @@
+package indicators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// Universe 表示策略自己的股票范围，使同一个扫描器可以在不同的股票上运行不同的策略
+// （如小盘股动量和大盘股均值回归）。Symbols 和 Index 都为空时扫描调用方传入的股票
+type Universe struct {
+	Symbols []string `json:"symbols,omitempty" yaml:"symbols"`
+	Index   string   `json:"index,omitempty" yaml:"index"` // 指数或ETF名称，成分股由 SetConstituents 设置的数据提供
+	// Filter 为过滤表达式，扫描时在每只股票的K线和基本面数据上求值，不满足的股票不产生信号，见 ParseUniverseFilter
+	Filter string `json:"filter,omitempty" yaml:"filter"`
+	// Sectors 为允许的行业（不区分大小写），为空时不限制；行业名称取决于数据源的分类体系
+	Sectors []string `json:"sectors,omitempty" yaml:"sectors"`
+}
+
+// NeedsFundamentals 检查股票范围是否需要基本面数据
+func (u Universe) NeedsFundamentals() bool {
+	if len(u.Sectors) > 0 {
+		return true
+	}
+	filter, err := ParseUniverseFilter(u.Filter)
+	return err == nil && filter.NeedsFundamentals()
+}
+
+// MatchSector 检查行业是否在 Sectors 中，Sectors 为空时总是满足
+func (u Universe) MatchSector(sector string) bool {
+	if len(u.Sectors) == 0 {
+		return true
+	}
+	for _, allowed := range u.Sectors {
+		if strings.EqualFold(strings.TrimSpace(allowed), strings.TrimSpace(sector)) {
+			return true
+		}
+	}
+	return false
+}
+
+// UniverseFilter 是解析后的股票范围过滤表达式
+type UniverseFilter struct {
+	clauses []universeClause
+}
+
+// universeClause 是过滤表达式中的一个比较（内部类型）
+type universeClause struct {
+	metric string
+	period int
+	op     string
+	value  float64
+}
+
+// universeClausePattern 匹配 <指标>[(<K线数>)] <比较符> <数值>（内部变量）
+var universeClausePattern = regexp.MustCompile(`^([a-z_]+)\s*(?:\(\s*(\d+)\s*\))?\s*(>=|<=|>|<)\s*([-+]?[0-9.]+(?:e[-+]?\d+)?)([kmb]?)$`)
+
+// universeClauseSeparator 分隔过滤表达式中的比较（内部变量）
+var universeClauseSeparator = regexp.MustCompile(`\s+and\s+`)
+
+// ParseUniverseFilter 解析股票范围过滤表达式，多个比较用 and 连接，全部满足时股票在范围内，例如：
+//
+//	close < 20 and avg_dollar_volume(20) > 5m
+//
+// 支持的技术指标（基于最新K线，括号中为平均的K线数，默认20，K线不足时使用全部K线）：
+// close、open、high、low、volume、avg_volume(n)、avg_dollar_volume(n)、change(n)（n根K线的涨跌幅，0.1表示10%）。
+// 支持的基本面指标：market_cap（市值）、float（自由流通股数）、pe（TTM市盈率）、eps（TTM每股收益），
+// 数据源未提供的市值、流通股和市盈率（包括亏损公司的市盈率）不满足任何比较。
+// 数值可以带 k、m、b 后缀表示千、百万、十亿
+func ParseUniverseFilter(expr string) (*UniverseFilter, error) {
+	expr = strings.TrimSpace(strings.ToLower(expr))
+	if expr == "" {
+		return nil, fmt.Errorf("empty universe filter")
+	}
+
+	filter := &UniverseFilter{}
+	for _, part := range universeClauseSeparator.Split(expr, -1) {
+		match := universeClausePattern.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return nil, fmt.Errorf("invalid universe filter clause %q", part)
+		}
+		clause := universeClause{metric: match[1], op: match[3]}
+		switch clause.metric {
+		case "close", "open", "high", "low", "volume", "market_cap", "float", "pe", "eps":
+			if match[2] != "" {
+				return nil, fmt.Errorf("%s does not take a period", clause.metric)
+			}
+		case "avg_volume", "avg_dollar_volume", "change":
+			clause.period = 20
+			if match[2] != "" {
+				clause.period, _ = strconv.Atoi(match[2])
+			}
+			if clause.period <= 0 {
+				return nil, fmt.Errorf("%s period must be positive", clause.metric)
+			}
+		default:
+			return nil, fmt.Errorf("unknown universe filter metric %q", clause.metric)
+		}
+
+		value, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number in universe filter clause %q", part)
+		}
+		switch match[5] {
+		case "k":
+			value *= 1e3
+		case "m":
+			value *= 1e6
+		case "b":
+			value *= 1e9
+		}
+		clause.value = value
+		filter.clauses = append(filter.clauses, clause)
+	}
+	return filter, nil
+}
+
+// NeedsFundamentals 检查过滤表达式是否引用基本面指标
+func (f *UniverseFilter) NeedsFundamentals() bool {
+	for _, clause := range f.clauses {
+		if clause.fundamental() {
+			return true
+		}
+	}
+	return false
+}
+
+// Match 检查股票的K线是否满足过滤表达式，没有K线时不满足；引用基本面指标的表达式总是不满足，应使用 MatchFundamentals
+func (f *UniverseFilter) Match(data []datasource.StockData) bool {
+	return f.MatchFundamentals(data, nil)
+}
+
+// MatchFundamentals 检查股票的K线和基本面数据是否满足过滤表达式，没有K线时不满足，
+// fundamentals 为空时引用基本面指标的比较不满足
+func (f *UniverseFilter) MatchFundamentals(data []datasource.StockData, fundamentals *datasource.Fundamentals) bool {
+	if len(data) == 0 {
+		return false
+	}
+	for _, clause := range f.clauses {
+		v, known := clause.evaluate(data, fundamentals)
+		if !known {
+			return false
+		}
+		var ok bool
+		switch clause.op {
+		case ">":
+			ok = v > clause.value
+		case ">=":
+			ok = v >= clause.value
+		case "<":
+			ok = v < clause.value
+		case "<=":
+			ok = v <= clause.value
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// fundamental 检查比较是否引用基本面指标（内部方法）
+func (c universeClause) fundamental() bool {
+	switch c.metric {
+	case "market_cap", "float", "pe", "eps":
+		return true
+	}
+	return false
+}
+
+// evaluate 计算比较左侧的指标值，基本面数据缺失时返回false（内部方法）
+func (c universeClause) evaluate(data []datasource.StockData, fundamentals *datasource.Fundamentals) (float64, bool) {
+	if c.fundamental() {
+		if fundamentals == nil {
+			return 0, false
+		}
+		var v float64
+		switch c.metric {
+		case "market_cap":
+			v = fundamentals.MarketCap
+		case "float":
+			v = float64(fundamentals.FloatShares)
+		case "pe":
+			v = fundamentals.PERatio
+		case "eps":
+			return fundamentals.EPS, true
+		}
+		return v, v > 0
+	}
+
+	last := data[len(data)-1]
+	recent := data[max(0, len(data)-c.period):]
+	switch c.metric {
+	case "close":
+		return last.Close, true
+	case "open":
+		return last.Open, true
+	case "high":
+		return last.High, true
+	case "low":
+		return last.Low, true
+	case "volume":
+		return float64(last.Volume), true
+	case "avg_volume", "avg_dollar_volume":
+		var sum float64
+		for _, bar := range recent {
+			if c.metric == "avg_volume" {
+				sum += float64(bar.Volume)
+			} else {
+				sum += float64(bar.Volume) * bar.Close
+			}
+		}
+		return sum / float64(len(recent)), true
+	case "change":
+		// 与 period 根K线之前的收盘价比较
+		base := data[max(0, len(data)-1-c.period)].Close
+		if base == 0 {
+			return 0, true
+		}
+		return last.Close/base - 1, true
+	}
+	return 0, true
+}
+
+// SetConstituents 设置指数和ETF成分股数据，策略的 Universe.Index 使用它获取成分股
+func (s *Scanner) SetConstituents(provider datasource.ConstituentProvider) {
+	s.constituents = provider
+}
+
+// StrategySymbols 返回策略扫描的股票：策略设置了 Universe.Symbols 或 Universe.Index 时为二者的并集（去重，保持顺序），
+// 否则为symbols。Universe.Filter 不在这里求值，而是在扫描每只股票时使用获取到的K线求值
+func (s *Scanner) StrategySymbols(ctx context.Context, strategyName string, symbols []string) ([]string, error) {
+	strategy, err := s.lookupStrategy(strategyName)
+	if err != nil {
+		return nil, err
+	}
+	return s.universeSymbols(ctx, strategy, symbols)
+}
+
+// universeSymbols 解析策略的股票范围（内部方法）
+func (s *Scanner) universeSymbols(ctx context.Context, strategy Strategy, symbols []string) ([]string, error) {
+	universe := strategy.Universe
+	if len(universe.Symbols) == 0 && universe.Index == "" {
+		return symbols, nil
+	}
+
+	resolved := append([]string(nil), universe.Symbols...)
+	if universe.Index != "" {
+		if s.constituents == nil {
+			return nil, fmt.Errorf("strategy '%s' universe index %s requires constituents data", strategy.Name, universe.Index)
+		}
+		constituents, err := s.constituents.GetConstituents(ctx, universe.Index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get constituents of %s for strategy '%s': %v", universe.Index, strategy.Name, err)
+		}
+		resolved = append(resolved, datasource.ConstituentSymbols(constituents)...)
+	}
+
+	seen := make(map[string]bool, len(resolved))
+	unique := resolved[:0]
+	for _, symbol := range resolved {
+		if !seen[symbol] {
+			seen[symbol] = true
+			unique = append(unique, symbol)
+		}
+	}
+	return unique, nil
+}
@@ -0,0 +1,196 @@
+package indicators_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+// staticConstituents 返回固定成分股的测试数据
+type staticConstituents map[string][]string
+
+func (c staticConstituents) GetConstituents(ctx context.Context, index string) ([]datasource.Constituent, error) {
+	var constituents []datasource.Constituent
+	for _, symbol := range c[index] {
+		constituents = append(constituents, datasource.Constituent{Symbol: symbol})
+	}
+	return constituents, nil
+}
+
+func (c staticConstituents) Indexes(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestParseUniverseFilter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := testutil.GenerateBars("AAPL", start, 24*time.Hour, 30, 10, 1) // 收盘价 10..39，成交量 1000
+
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{"close < 40", true},
+		{"CLOSE >= 40", false},
+		{"avg_volume(10) >= 1k and close > 30", true},
+		{"avg_dollar_volume(2) > 38k", true},    // (38+39)/2*1000
+		{"avg_dollar_volume(2) > 0.04m", false}, // 38500 < 40000
+		{"change(9) > 0.29", true},              // 39/30-1
+		{"change(9) > 0.31", false},
+	}
+	for _, tt := range tests {
+		filter, err := indicators.ParseUniverseFilter(tt.expr)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", tt.expr, err)
+			continue
+		}
+		if got := filter.Match(bars); got != tt.match {
+			t.Errorf("%q 匹配结果 = %v, 期望 %v", tt.expr, got, tt.match)
+		}
+	}
+
+	for _, expr := range []string{"", "rsi < 30", "pe(4) < 20", "close(5) > 1", "close = 5", "close > 5 or volume > 1"} {
+		if _, err := indicators.ParseUniverseFilter(expr); err == nil {
+			t.Errorf("%q 应解析失败", expr)
+		}
+	}
+}
+
+func TestStrategyUniverse(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	from, to := start, start.AddDate(0, 0, 30)
+
+	source := testutil.NewMockDataSource("")
+	prices := map[string]float64{"AAPL": 180, "MSFT": 400, "SMCI": 8, "PLUG": 3, "SOFI": 12}
+	for symbol, price := range prices {
+		source.SetBars(symbol, "day", testutil.GenerateBars(symbol, start, 24*time.Hour, 20, price, price/100))
+	}
+
+	scanner := indicators.NewScanner(indicators.NewIndicatorRegistry(), testutil.NewManager(source))
+	scanner.SetConstituents(staticConstituents{"megacap": {"AAPL", "MSFT"}})
+	roc := []indicators.IndicatorConfig{
+		{Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5}, BuyCondition: indicators.ConditionAboveThreshold},
+	}
+	scanner.AddStrategy(indicators.Strategy{Name: "mega", Enabled: true, Indicators: roc,
+		Universe: indicators.Universe{Index: "megacap", Symbols: []string{"MSFT"}}})
+	scanner.AddStrategy(indicators.Strategy{Name: "small", Enabled: true, Indicators: roc,
+		Universe: indicators.Universe{Symbols: []string{"SMCI", "PLUG", "SOFI"}, Filter: "close < 10"}})
+	scanner.AddStrategy(indicators.Strategy{Name: "all", Enabled: true, Indicators: roc})
+
+	if err := scanner.AddStrategy(indicators.Strategy{Name: "bad", Universe: indicators.Universe{Filter: "dividend_yield > 0.03"}}); err == nil {
+		t.Error("无效的过滤表达式应返回错误")
+	}
+
+	symbols, err := scanner.StrategySymbols(ctx, "mega", []string{"SOFI"})
+	if err != nil || len(symbols) != 2 || symbols[0] != "MSFT" || symbols[1] != "AAPL" {
+		t.Errorf("mega 的股票 = %v %v, 期望 [MSFT AAPL]", symbols, err)
+	}
+
+	// 同一个扫描器上各策略扫描自己的股票，传入的股票只用于没有股票范围的策略
+	expected := map[string][]string{
+		"mega":  {"AAPL", "MSFT"},
+		"small": {"PLUG", "SMCI"}, // SOFI 收盘价高于10
+		"all":   {"SOFI"},
+	}
+	for name, want := range expected {
+		results, err := scanner.ScanMultipleSymbols(ctx, []string{"SOFI"}, name, from, to, "day")
+		if err != nil {
+			t.Fatalf("扫描 %s 失败: %v", name, err)
+		}
+		var got []string
+		for symbol := range results {
+			got = append(got, symbol)
+		}
+		sort.Strings(got)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s 产生信号的股票 = %v, 期望 %v", name, got, want)
+		}
+	}
+
+	scanner.SetConstituents(nil)
+	if _, err := scanner.ScanMultipleSymbols(ctx, nil, "mega", from, to, "day"); err == nil {
+		t.Error("没有成分股数据时按指数扫描应返回错误")
+	}
+}
+
+func TestFundamentalUniverseFilter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bars := testutil.GenerateBars("SOFI", start, 24*time.Hour, 30, 10, 0.1)
+	sofi := &datasource.Fundamentals{Symbol: "SOFI", MarketCap: 8e9, FloatShares: 950e6, PERatio: 20, EPS: 0.4}
+
+	tests := []struct {
+		expr         string
+		fundamentals *datasource.Fundamentals
+		match        bool
+	}{
+		{"market_cap < 10b and close > 5", sofi, true},
+		{"market_cap < 2b", sofi, false},
+		{"float < 1b and pe <= 20 and eps > 0", sofi, true},
+		{"market_cap < 10b", nil, false},                        // 缺少基本面数据
+		{"pe < 30", &datasource.Fundamentals{EPS: -1.2}, false}, // 亏损公司没有市盈率
+		{"eps < 0", &datasource.Fundamentals{EPS: -1.2}, true},
+		{"float > 0", &datasource.Fundamentals{MarketCap: 8e9}, false}, // 数据源未提供流通股
+	}
+	for _, tt := range tests {
+		filter, err := indicators.ParseUniverseFilter(tt.expr)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", tt.expr, err)
+			continue
+		}
+		if got := filter.MatchFundamentals(bars, tt.fundamentals); got != tt.match {
+			t.Errorf("%q 匹配结果 = %v, 期望 %v", tt.expr, got, tt.match)
+		}
+	}
+	if filter, _ := indicators.ParseUniverseFilter("close > 5 and pe < 30"); !filter.NeedsFundamentals() || filter.Match(bars) {
+		t.Error("引用基本面指标的表达式应需要基本面数据")
+	}
+}
+
+func TestScanFundamentalUniverse(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	from, to := start, start.AddDate(0, 0, 30)
+
+	source := testutil.NewMockDataSource("")
+	for _, symbol := range []string{"AAPL", "SOFI", "PLUG", "SPY"} {
+		source.SetBars(symbol, "day", testutil.GenerateBars(symbol, start, 24*time.Hour, 20, 10, 0.1))
+	}
+	source.SetFundamentals(datasource.Fundamentals{Symbol: "AAPL", Sector: "Technology", MarketCap: 2.8e12, PERatio: 29, EPS: 6.1})
+	source.SetFundamentals(datasource.Fundamentals{Symbol: "SOFI", Sector: "Financial Services", MarketCap: 8e9, PERatio: 20, EPS: 0.4})
+	source.SetFundamentals(datasource.Fundamentals{Symbol: "PLUG", Sector: "Technology", MarketCap: 2e9, EPS: -1.2})
+
+	scanner := indicators.NewScanner(indicators.NewIndicatorRegistry(), testutil.NewManager(source))
+	roc := []indicators.IndicatorConfig{
+		{Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5}, BuyCondition: indicators.ConditionAboveThreshold},
+	}
+	scanner.AddStrategy(indicators.Strategy{Name: "small_value", Enabled: true, Indicators: roc,
+		Universe: indicators.Universe{Filter: "market_cap < 50b and pe < 25"}})
+	scanner.AddStrategy(indicators.Strategy{Name: "tech", Enabled: true, Indicators: roc,
+		Universe: indicators.Universe{Sectors: []string{"technology"}}})
+
+	symbols := []string{"AAPL", "SOFI", "PLUG"}
+	if results, err := scanner.ScanMultipleSymbols(ctx, symbols, "small_value", from, to, "day"); err != nil || len(results) != 1 || results["SOFI"] == nil {
+		t.Errorf("只有 SOFI 满足市值和市盈率条件: %v %v", results, err)
+	}
+	if results, err := scanner.ScanMultipleSymbols(ctx, symbols, "tech", from, to, "day"); err != nil || len(results) != 2 || results["SOFI"] != nil {
+		t.Errorf("行业过滤应不区分大小写: %v %v", results, err)
+	}
+
+	// 基本面数据被缓存，重复扫描不再请求数据源
+	calls := source.Calls("GetFundamentals")
+	scanner.ScanMultipleSymbols(ctx, symbols, "tech", from, to, "day")
+	if source.Calls("GetFundamentals") != calls {
+		t.Errorf("重复扫描不应重新获取基本面数据: %d -> %d", calls, source.Calls("GetFundamentals"))
+	}
+
+	// 没有基本面数据的股票扫描出错
+	if _, err := scanner.ScanSymbol(ctx, "SPY", "tech", from, to, "day"); err == nil {
+		t.Error("缺少基本面数据时应返回错误")
+	}
+}
@@ -0,0 +1,175 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// VWAP 成交量加权平均价指标结构体，同时输出成交量加权的±1σ和±2σ标准差带
+type VWAP struct {
+	period int    // 滚动窗口的K线数，为0时每个交易日从开盘重新累计
+	source string // 加权的价格序列，默认为典型价格
+}
+
+// NewVWAP 创建一个新的VWAP指标
+func NewVWAP(params IndicatorParams) (Indicator, error) {
+	period := params.GetInt("period", 0)
+	if period < 0 {
+		return nil, fmt.Errorf("period must not be negative")
+	}
+
+	// VWAP默认按典型价格加权
+	source := SourceTypical
+	if _, ok := params["source"]; ok {
+		var err error
+		if source, err = params.GetSource(); err != nil {
+			return nil, err
+		}
+	}
+	if source == SourceVolume {
+		return nil, fmt.Errorf("VWAP source must be a price")
+	}
+
+	return &VWAP{
+		period: period,
+		source: source,
+	}, nil
+}
+
+// Name 返回指标名称
+func (v *VWAP) Name() string {
+	return IndicatorTypeVWAP
+}
+
+// Calculate 计算VWAP和标准差带，标准差为价格相对VWAP的成交量加权标准差
+func (v *VWAP) Calculate(data []datasource.StockData) (IndicatorResult, error) {
+	if len(data) == 0 || len(data) < v.period {
+		return IndicatorResult{}, fmt.Errorf("not enough data points for VWAP calculation (minimum: %d, got: %d)",
+			max(v.period, 1), len(data))
+	}
+
+	n := len(data)
+	dates := make([]string, n)
+	vwap := make([]float64, n)
+	stdDev := make([]float64, n)
+	closes := make([]float64, n)
+	highs := make([]float64, n)
+	lows := make([]float64, n)
+
+	// 累计成交量、价格×成交量和价格²×成交量，滚动窗口时减去移出窗口的K线
+	var sumV, sumPV, sumP2V float64
+	start := 0
+	for i, bar := range data {
+		dates[i] = bar.Timestamp.Format(time.RFC3339)
+		closes[i], highs[i], lows[i] = bar.Close, bar.High, bar.Low
+
+		if v.period == 0 && i > 0 && !sameDay(data[i-1].Timestamp, bar.Timestamp) {
+			sumV, sumPV, sumP2V = 0, 0, 0
+			start = i
+		}
+		price, volume := SourceValue(bar, v.source), float64(bar.Volume)
+		sumV += volume
+		sumPV += price * volume
+		sumP2V += price * price * volume
+		if v.period > 0 && i-start >= v.period {
+			old := data[start]
+			oldPrice, oldVolume := SourceValue(old, v.source), float64(old.Volume)
+			sumV -= oldVolume
+			sumPV -= oldPrice * oldVolume
+			sumP2V -= oldPrice * oldPrice * oldVolume
+			start++
+		}
+
+		if sumV <= 0 {
+			// 没有成交量时以价格本身为VWAP
+			vwap[i] = price
+			continue
+		}
+		vwap[i] = sumPV / sumV
+		// E[p²]-E[p]² 可能因浮点误差略小于0
+		stdDev[i] = math.Sqrt(math.Max(sumP2V/sumV-vwap[i]*vwap[i], 0))
+	}
+
+	upper1, lower1 := make([]float64, n), make([]float64, n)
+	upper2, lower2 := make([]float64, n), make([]float64, n)
+	for i := range vwap {
+		upper1[i], lower1[i] = vwap[i]+stdDev[i], vwap[i]-stdDev[i]
+		upper2[i], lower2[i] = vwap[i]+2*stdDev[i], vwap[i]-2*stdDev[i]
+	}
+
+	return IndicatorResult{
+		Name: v.Name(),
+		Values: map[string][]float64{
+			"vwap":    vwap,
+			"std_dev": stdDev,
+			"upper1":  upper1,
+			"lower1":  lower1,
+			"upper2":  upper2,
+			"lower2":  lower2,
+			"close":   closes,
+			"high":    highs,
+			"low":     lows,
+		},
+		Dates: dates,
+	}, nil
+}
+
+// EvaluateCondition 评估VWAP指标条件，使用最新K线的价格
+// 标准差带条件的阈值为标准差倍数（1或2），为0时使用2σ；cross_above 和 cross_below 为收盘价穿越VWAP
+func (v *VWAP) EvaluateCondition(result IndicatorResult, condition string, threshold float64) (bool, error) {
+	vwap := result.Values["vwap"]
+	if len(vwap) == 0 {
+		return false, fmt.Errorf("VWAP result is empty")
+	}
+	idx := len(vwap) - 1
+	prevIdx := idx - 1
+	if prevIdx < 0 {
+		return false, fmt.Errorf("not enough data points for VWAP condition evaluation")
+	}
+
+	band := threshold
+	if band <= 0 {
+		band = 2
+	}
+	stdDev := result.Values["std_dev"]
+	upper := func(i int) float64 { return vwap[i] + band*stdDev[i] }
+	lower := func(i int) float64 { return vwap[i] - band*stdDev[i] }
+	closes, highs, lows := result.Values["close"], result.Values["high"], result.Values["low"]
+
+	switch condition {
+	case ConditionCrossAbove:
+		return closes[prevIdx] <= vwap[prevIdx] && closes[idx] > vwap[idx], nil
+	case ConditionCrossBelow:
+		return closes[prevIdx] >= vwap[prevIdx] && closes[idx] < vwap[idx], nil
+	case ConditionPriceAboveUpper:
+		return closes[idx] > upper(idx), nil
+	case ConditionPriceBelowLower:
+		return closes[idx] < lower(idx), nil
+	case ConditionPriceWithinBands:
+		return closes[idx] >= lower(idx) && closes[idx] <= upper(idx), nil
+	case ConditionTouchUpper:
+		// 最高价触及上轨
+		return highs[idx] >= upper(idx), nil
+	case ConditionTouchLower:
+		// 最低价触及下轨
+		return lows[idx] <= lower(idx), nil
+	case ConditionRevertFromUpper:
+		// 上一根收于上轨之外，本根收回上轨之内，均值回归的卖出信号
+		return closes[prevIdx] > upper(prevIdx) && closes[idx] <= upper(idx), nil
+	case ConditionRevertFromLower:
+		// 上一根收于下轨之外，本根收回下轨之内，均值回归的买入信号
+		return closes[prevIdx] < lower(prevIdx) && closes[idx] >= lower(idx), nil
+	default:
+		return false, fmt.Errorf("unsupported condition for VWAP: %s", condition)
+	}
+}
+
+// sameDay 判断两个时间是否在同一天（按时间自身的时区）（内部函数）
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
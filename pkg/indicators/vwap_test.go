@@ -0,0 +1,56 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+func TestVWAPBands(t *testing.T) {
+	start := time.Date(2024, 3, 8, 9, 30, 0, 0, time.UTC)
+	bar := func(i int, price float64, volume int64) datasource.StockData {
+		return datasource.StockData{Timestamp: start.Add(time.Duration(i) * time.Minute), Open: price, High: price, Low: price, Close: price, Volume: volume}
+	}
+	data := []datasource.StockData{bar(0, 100, 100), bar(1, 102, 100), bar(2, 98, 200)}
+
+	vwap, err := NewVWAP(IndicatorParams{})
+	if err != nil {
+		t.Fatalf("创建VWAP失败: %v", err)
+	}
+	result, err := vwap.Calculate(data)
+	if err != nil {
+		t.Fatalf("计算VWAP失败: %v", err)
+	}
+	// VWAP = (100×100+102×100+98×200)/400 = 99.5，方差 = (0.25×100+6.25×100+2.25×200)/400 = 2.75
+	sigma := math.Sqrt(2.75)
+	if got := result.Values["vwap"][2]; math.Abs(got-99.5) > 1e-9 {
+		t.Errorf("VWAP = %v, 期望 99.5", got)
+	}
+	if got := result.Values["upper2"][2]; math.Abs(got-(99.5+2*sigma)) > 1e-9 {
+		t.Errorf("2σ上轨 = %v, 期望 %v", got, 99.5+2*sigma)
+	}
+	if ok, _ := vwap.EvaluateCondition(result, ConditionTouchLower, 1); ok {
+		t.Error("最低价98未触及1σ下轨时不应满足条件")
+	}
+
+	// 滚动窗口只统计最近的K线
+	rolling, _ := NewVWAP(IndicatorParams{"period": 2})
+	result, _ = rolling.Calculate(data)
+	if got := result.Values["vwap"][2]; math.Abs(got-(102*100+98*200)/300.0) > 1e-9 {
+		t.Errorf("滚动VWAP = %v", got)
+	}
+
+	// 跌破2σ下轨后收回轨内为均值回归的买入信号
+	bands := IndicatorResult{Values: map[string][]float64{
+		"vwap": {100, 100}, "std_dev": {1, 1},
+		"close": {97.5, 98.5}, "high": {98, 99}, "low": {97, 98},
+	}}
+	if ok, _ := vwap.EvaluateCondition(bands, ConditionRevertFromLower, 2); !ok {
+		t.Error("收回2σ下轨之内应满足均值回归条件")
+	}
+	if ok, _ := vwap.EvaluateCondition(bands, ConditionRevertFromLower, 1); ok {
+		t.Error("仍在1σ下轨之外时不应满足均值回归条件")
+	}
+}
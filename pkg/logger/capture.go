@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CaptureSink 是一个内存日志输出目标，用于在测试中断言日志内容，断言辅助函数见 loggertest 包
+// 它按行解析JSON格式的日志，非JSON行会作为无级别的原始消息保存
+type CaptureSink struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	pending []byte // 尚未遇到换行符的部分数据
+}
+
+// NewCaptureSink 创建一个新的内存日志输出目标
+func NewCaptureSink() *CaptureSink {
+	return &CaptureSink{}
+}
+
+// NewCaptureLogger 创建一个输出到内存的日志记录器，返回日志记录器及其输出目标
+func NewCaptureLogger(level LogLevel) (Logger, *CaptureSink) {
+	sink := NewCaptureSink()
+	logger, _ := NewWriterLogger(LogConfig{
+		Level:  level,
+		Format: LogFormatJSON,
+	}, sink)
+	return logger, sink
+}
+
+// Write 实现io.Writer接口
+func (s *CaptureSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, p...)
+	for {
+		idx := bytes.IndexByte(s.pending, '\n')
+		if idx < 0 {
+			break
+		}
+
+		line := bytes.TrimSpace(s.pending[:idx])
+		s.pending = s.pending[idx+1:]
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry LogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			entry = LogEntry{Message: string(line)}
+		}
+		s.entries = append(s.entries, entry)
+	}
+
+	return len(p), nil
+}
+
+// Entries 返回已捕获的所有日志条目
+func (s *CaptureSink) Entries() []LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]LogEntry, len(s.entries))
+	copy(entries, s.entries)
+	return entries
+}
+
+// EntriesAt 返回指定级别的日志条目
+func (s *CaptureSink) EntriesAt(level LogLevel) []LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []LogEntry
+	for _, entry := range s.entries {
+		if entry.Level == level {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Contains 检查是否存在指定级别且消息或上下文包含指定文本的日志
+// level为空时匹配所有级别
+func (s *CaptureSink) Contains(level LogLevel, substr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.entries {
+		if matchEntry(entry, level, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Count 返回匹配指定级别和文本的日志条数
+func (s *CaptureSink) Count(level LogLevel, substr string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, entry := range s.entries {
+		if matchEntry(entry, level, substr) {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset 清空已捕获的日志
+func (s *CaptureSink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = nil
+	s.pending = nil
+}
+
+// matchEntry 检查日志条目是否匹配级别和文本
+func matchEntry(entry LogEntry, level LogLevel, substr string) bool {
+	if level != "" && entry.Level != level {
+		return false
+	}
+	if strings.Contains(entry.Message, substr) {
+		return true
+	}
+	for key, value := range entry.Context {
+		if strings.Contains(key, substr) || strings.Contains(fmt.Sprint(value), substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Excel导出可用的字段常量
+const (
+	ExcelFieldTimestamp   = "timestamp"
+	ExcelFieldType        = "type"
+	ExcelFieldSymbol      = "symbol"
+	ExcelFieldQuantity    = "quantity"
+	ExcelFieldPrice       = "price"
+	ExcelFieldAmount      = "amount"
+	ExcelFieldCommission  = "commission"
+	ExcelFieldPnL         = "pnl"
+	ExcelFieldPnLPercent  = "pnl_percent"
+	ExcelFieldPosition    = "position"
+	ExcelFieldEntryPrice  = "entry_price"
+	ExcelFieldHoldTime    = "hold_time"
+	ExcelFieldStrategy    = "strategy"
+	ExcelFieldAccount     = "account"
+	ExcelFieldOrderID     = "order_id"
+	ExcelFieldExecutionID = "execution_id"
+	ExcelFieldNotes       = "notes"
+	ExcelFieldTags        = "tags"
+)
+
+// 汇总表可用的指标常量
+const (
+	ExcelSummaryDate        = "date"
+	ExcelSummaryTotalTrades = "total_trades"
+	ExcelSummaryBuyTrades   = "buy_trades"
+	ExcelSummarySellTrades  = "sell_trades"
+	ExcelSummaryWinning     = "winning_trades"
+	ExcelSummaryLosing      = "losing_trades"
+	ExcelSummaryWinRate     = "win_rate"
+	ExcelSummaryTotalAmount = "total_amount"
+	ExcelSummaryCommission  = "total_commission"
+	ExcelSummaryNetPnL      = "net_pnl"
+)
+
+// ExcelColumn 表示Excel导出中的一列
+type ExcelColumn struct {
+	Field        string  `json:"field" yaml:"field"`                           // 字段名，见ExcelField*常量
+	Header       string  `json:"header" yaml:"header"`                         // 表头文字
+	Width        float64 `json:"width,omitempty" yaml:"width"`                 // 列宽，0表示使用默认宽度
+	NumberFormat string  `json:"number_format,omitempty" yaml:"number_format"` // 数字格式，如"0.00"、"#,##0.00"
+}
+
+// ExcelTemplate 表示Excel导出模板
+type ExcelTemplate struct {
+	SheetName        string            `json:"sheet_name" yaml:"sheet_name"`
+	Columns          []ExcelColumn     `json:"columns" yaml:"columns"`
+	TimeFormat       string            `json:"time_format,omitempty" yaml:"time_format"`
+	IncludeSummary   bool              `json:"include_summary" yaml:"include_summary"`
+	SummarySheetName string            `json:"summary_sheet_name,omitempty" yaml:"summary_sheet_name"`
+	SummaryLabels    map[string]string `json:"summary_labels,omitempty" yaml:"summary_labels"` // 汇总表指标名称，键见ExcelSummary*常量
+}
+
+// 默认的中文表头
+var defaultExcelHeaders = map[string]string{
+	ExcelFieldTimestamp:   "时间",
+	ExcelFieldType:        "类型",
+	ExcelFieldSymbol:      "股票代码",
+	ExcelFieldQuantity:    "数量",
+	ExcelFieldPrice:       "价格",
+	ExcelFieldAmount:      "金额",
+	ExcelFieldCommission:  "手续费",
+	ExcelFieldPnL:         "盈亏",
+	ExcelFieldPnLPercent:  "盈亏%",
+	ExcelFieldPosition:    "持仓",
+	ExcelFieldEntryPrice:  "成本",
+	ExcelFieldHoldTime:    "持有时间",
+	ExcelFieldStrategy:    "策略",
+	ExcelFieldAccount:     "账户",
+	ExcelFieldOrderID:     "订单ID",
+	ExcelFieldExecutionID: "执行ID",
+	ExcelFieldNotes:       "备注",
+	ExcelFieldTags:        "标签",
+}
+
+// EnglishExcelHeaders 是英文表头，可配合WithHeaders使用
+var EnglishExcelHeaders = map[string]string{
+	ExcelFieldTimestamp:   "Time",
+	ExcelFieldType:        "Type",
+	ExcelFieldSymbol:      "Symbol",
+	ExcelFieldQuantity:    "Quantity",
+	ExcelFieldPrice:       "Price",
+	ExcelFieldAmount:      "Amount",
+	ExcelFieldCommission:  "Commission",
+	ExcelFieldPnL:         "PnL",
+	ExcelFieldPnLPercent:  "PnL %",
+	ExcelFieldPosition:    "Position",
+	ExcelFieldEntryPrice:  "Cost Basis",
+	ExcelFieldHoldTime:    "Hold Time (h)",
+	ExcelFieldStrategy:    "Strategy",
+	ExcelFieldAccount:     "Account",
+	ExcelFieldOrderID:     "Order ID",
+	ExcelFieldExecutionID: "Execution ID",
+	ExcelFieldNotes:       "Notes",
+	ExcelFieldTags:        "Tags",
+}
+
+// 默认的中文汇总指标名称
+var defaultSummaryLabels = map[string]string{
+	ExcelSummaryDate:        "日期",
+	ExcelSummaryTotalTrades: "交易笔数",
+	ExcelSummaryBuyTrades:   "买入笔数",
+	ExcelSummarySellTrades:  "卖出笔数",
+	ExcelSummaryWinning:     "盈利笔数",
+	ExcelSummaryLosing:      "亏损笔数",
+	ExcelSummaryWinRate:     "胜率%",
+	ExcelSummaryTotalAmount: "成交金额",
+	ExcelSummaryCommission:  "手续费合计",
+	ExcelSummaryNetPnL:      "净盈亏",
+}
+
+// EnglishSummaryLabels 是英文汇总指标名称
+var EnglishSummaryLabels = map[string]string{
+	ExcelSummaryDate:        "Date",
+	ExcelSummaryTotalTrades: "Total Trades",
+	ExcelSummaryBuyTrades:   "Buy Trades",
+	ExcelSummarySellTrades:  "Sell Trades",
+	ExcelSummaryWinning:     "Winning Trades",
+	ExcelSummaryLosing:      "Losing Trades",
+	ExcelSummaryWinRate:     "Win Rate %",
+	ExcelSummaryTotalAmount: "Total Amount",
+	ExcelSummaryCommission:  "Total Commission",
+	ExcelSummaryNetPnL:      "Net PnL",
+}
+
+// 汇总表指标的输出顺序
+var summaryOrder = []string{
+	ExcelSummaryDate,
+	ExcelSummaryTotalTrades,
+	ExcelSummaryBuyTrades,
+	ExcelSummarySellTrades,
+	ExcelSummaryWinning,
+	ExcelSummaryLosing,
+	ExcelSummaryWinRate,
+	ExcelSummaryTotalAmount,
+	ExcelSummaryCommission,
+	ExcelSummaryNetPnL,
+}
+
+// DefaultExcelTemplate 返回默认的导出模板（与原有的15列中文表头一致）
+func DefaultExcelTemplate() ExcelTemplate {
+	fields := []struct {
+		field string
+		width float64
+	}{
+		{ExcelFieldTimestamp, 20},
+		{ExcelFieldType, 12},
+		{ExcelFieldSymbol, 12},
+		{ExcelFieldQuantity, 12},
+		{ExcelFieldPrice, 12},
+		{ExcelFieldAmount, 12},
+		{ExcelFieldCommission, 12},
+		{ExcelFieldPnL, 12},
+		{ExcelFieldPnLPercent, 12},
+		{ExcelFieldPosition, 12},
+		{ExcelFieldEntryPrice, 12},
+		{ExcelFieldHoldTime, 12},
+		{ExcelFieldStrategy, 20},
+		{ExcelFieldOrderID, 20},
+		{ExcelFieldNotes, 20},
+	}
+
+	columns := make([]ExcelColumn, 0, len(fields))
+	for _, f := range fields {
+		columns = append(columns, ExcelColumn{
+			Field:  f.field,
+			Header: defaultExcelHeaders[f.field],
+			Width:  f.width,
+		})
+	}
+
+	return ExcelTemplate{
+		SheetName:        "交易记录",
+		Columns:          columns,
+		TimeFormat:       "2006-01-02 15:04:05",
+		SummarySheetName: "汇总",
+	}
+}
+
+// WithHeaders 返回替换了表头文字的模板副本，键为字段名
+func (t ExcelTemplate) WithHeaders(headers map[string]string) ExcelTemplate {
+	columns := make([]ExcelColumn, len(t.Columns))
+	copy(columns, t.Columns)
+	for i, col := range columns {
+		if header, ok := headers[col.Field]; ok {
+			columns[i].Header = header
+		}
+	}
+	t.Columns = columns
+	return t
+}
+
+// Validate 检查模板是否有效
+func (t ExcelTemplate) Validate() error {
+	if t.SheetName == "" {
+		return fmt.Errorf("模板缺少表格名称")
+	}
+	if len(t.Columns) == 0 {
+		return fmt.Errorf("模板至少需要一列")
+	}
+	for _, col := range t.Columns {
+		if _, ok := defaultExcelHeaders[col.Field]; !ok {
+			return fmt.Errorf("不支持的导出字段: %s", col.Field)
+		}
+	}
+	if t.IncludeSummary && t.SummarySheetName == t.SheetName {
+		return fmt.Errorf("汇总表名称不能与交易表名称相同")
+	}
+	return nil
+}
+
+// header 返回列的表头，未设置时使用默认中文表头
+func (c ExcelColumn) header() string {
+	if c.Header != "" {
+		return c.Header
+	}
+	return defaultExcelHeaders[c.Field]
+}
+
+// summaryLabel 返回汇总指标名称，未设置时使用默认中文名称
+func (t ExcelTemplate) summaryLabel(key string) string {
+	if label, ok := t.SummaryLabels[key]; ok && label != "" {
+		return label
+	}
+	return defaultSummaryLabels[key]
+}
+
+// excelFieldValue 返回日志条目中指定字段的值
+func excelFieldValue(entry TradeLogEntry, field string, timeFormat string) interface{} {
+	switch field {
+	case ExcelFieldTimestamp:
+		return entry.Timestamp.Format(timeFormat)
+	case ExcelFieldType:
+		return entry.Type
+	case ExcelFieldSymbol:
+		return entry.Symbol
+	case ExcelFieldQuantity:
+		return entry.Quantity
+	case ExcelFieldPrice:
+		return entry.Price
+	case ExcelFieldAmount:
+		return entry.Amount
+	case ExcelFieldCommission:
+		return entry.Commission
+	case ExcelFieldPnL:
+		return entry.PnL
+	case ExcelFieldPnLPercent:
+		return entry.PnLPercent
+	case ExcelFieldPosition:
+		return entry.Position
+	case ExcelFieldEntryPrice:
+		return entry.EntryPrice
+	case ExcelFieldHoldTime:
+		return entry.HoldTime
+	case ExcelFieldStrategy:
+		return entry.Strategy
+	case ExcelFieldAccount:
+		return entry.Account
+	case ExcelFieldOrderID:
+		return entry.OrderID
+	case ExcelFieldExecutionID:
+		return entry.ExecutionID
+	case ExcelFieldNotes:
+		return entry.Notes
+	case ExcelFieldTags:
+		return strings.Join(entry.Tags, ",")
+	default:
+		return nil
+	}
+}
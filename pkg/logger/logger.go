@@ -8,9 +8,9 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
-	"time"
 
 	"github.com/natefinch/lumberjack"
+	"github.com/yourusername/qhft-system/pkg/clock"
 )
 
 // defaultLogger 是默认的日志实现
@@ -64,6 +64,27 @@ func NewLogger(config LogConfig) (Logger, error) {
 	return logger, nil
 }
 
+// NewWriterLogger 创建一个输出到任意io.Writer的日志记录器
+// 配置中的Output和文件相关字段会被忽略，多个writer会同时写入
+func NewWriterLogger(config LogConfig, writers ...io.Writer) (Logger, error) {
+	if len(writers) == 0 {
+		return nil, fmt.Errorf("至少需要一个日志输出目标")
+	}
+
+	logger := &defaultLogger{
+		config:  config,
+		context: make(LogContext),
+	}
+
+	if len(writers) == 1 {
+		logger.writer = writers[0]
+	} else {
+		logger.writer = io.MultiWriter(writers...)
+	}
+
+	return logger, nil
+}
+
 // log 输出日志
 func (l *defaultLogger) log(level LogLevel, msg string, args ...interface{}) {
 	if !l.shouldLog(level) {
@@ -82,7 +103,7 @@ func (l *defaultLogger) log(level LogLevel, msg string, args ...interface{}) {
 	entry := LogEntry{
 		Level:     level,
 		Message:   msg,
-		Timestamp: time.Now(),
+		Timestamp: clock.Or(l.config.Clock).Now(),
 		Context:   l.context,
 	}
 
@@ -102,9 +123,13 @@ func (l *defaultLogger) log(level LogLevel, msg string, args ...interface{}) {
 		l.writeTextLog(entry)
 	}
 
-	// 如果是fatal级别，程序终止
+	// 如果是fatal级别，程序终止（嵌入场景可通过ExitFunc自定义）
 	if level == LogLevelFatal {
-		os.Exit(1)
+		exit := l.config.ExitFunc
+		if exit == nil {
+			exit = os.Exit
+		}
+		exit(1)
 	}
 }
 
@@ -272,12 +297,23 @@ func (l *defaultLogger) Close() error {
 // 全局默认日志记录器
 var (
 	defaultLoggerInstance Logger
-	once                  sync.Once
+	defaultLoggerMu       sync.RWMutex
 )
 
 // GetDefaultLogger 获取全局默认日志记录器
+// 如果尚未通过InitDefaultLogger初始化，则创建一个输出到控制台的日志记录器
 func GetDefaultLogger() Logger {
-	once.Do(func() {
+	defaultLoggerMu.RLock()
+	instance := defaultLoggerInstance
+	defaultLoggerMu.RUnlock()
+	if instance != nil {
+		return instance
+	}
+
+	defaultLoggerMu.Lock()
+	defer defaultLoggerMu.Unlock()
+
+	if defaultLoggerInstance == nil {
 		config := LogConfig{
 			Level:      LogLevelInfo,
 			Format:     LogFormatText,
@@ -291,23 +327,34 @@ func GetDefaultLogger() Logger {
 
 		logger, err := NewLogger(config)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "初始化默认日志记录器失败: %v\n", err)
-			os.Exit(1)
+			// 控制台日志不应失败，这里退化为直接输出到标准错误
+			logger, _ = NewWriterLogger(config, os.Stderr)
 		}
 		defaultLoggerInstance = logger
-	})
+	}
 
 	return defaultLoggerInstance
 }
 
 // InitDefaultLogger 初始化全局默认日志记录器
-func InitDefaultLogger(config LogConfig) {
+func InitDefaultLogger(config LogConfig) error {
 	logger, err := NewLogger(config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "初始化默认日志记录器失败: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("初始化默认日志记录器失败: %v", err)
 	}
+
+	defaultLoggerMu.Lock()
 	defaultLoggerInstance = logger
+	defaultLoggerMu.Unlock()
+
+	return nil
+}
+
+// MustInitDefaultLogger 初始化全局默认日志记录器，失败时panic
+func MustInitDefaultLogger(config LogConfig) {
+	if err := InitDefaultLogger(config); err != nil {
+		panic(err)
+	}
 }
 
 // Debug 记录debug级别日志（使用默认日志记录器）
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/xuri/excelize/v2"
 )
 
 func TestDefaultLogger(t *testing.T) {
@@ -137,4 +139,185 @@ func TestTradeLogger(t *testing.T) {
 	if entries[0].Symbol != "AAPL" || entries[1].Symbol != "AAPL" {
 		t.Fatalf("交易股票代码不匹配")
 	}
-} 
\ No newline at end of file
+} 
+func TestPartitionedTradeLogger(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sysLogger, err := NewLogger(LogConfig{
+		Level:    LogLevelError,
+		Format:   LogFormatText,
+		Output:   LogOutputFile,
+		FilePath: filepath.Join(tempDir, "system.log"),
+	})
+	if err != nil {
+		t.Fatalf("创建系统日志记录器失败: %v", err)
+	}
+	defer sysLogger.Close()
+
+	tradeLogger, err := NewTradeLoggerWithOptions(filepath.Join(tempDir, "trades"), sysLogger, TradeLoggerOptions{
+		PartitionByAccount:  true,
+		PartitionByStrategy: true,
+	})
+	if err != nil {
+		t.Fatalf("创建交易日志记录器失败: %v", err)
+	}
+	defer tradeLogger.Close()
+
+	now := time.Now()
+	entries := []TradeLogEntry{
+		{Timestamp: now, Symbol: "AAPL", Quantity: 10, Account: "acct-1", Strategy: "momentum"},
+		{Timestamp: now.Add(time.Millisecond), Symbol: "MSFT", Quantity: 20, Account: "acct-1", Strategy: "reversion"},
+		{Timestamp: now.Add(2 * time.Millisecond), Symbol: "TSLA", Quantity: 30, Account: "acct-2", Strategy: "momentum"},
+	}
+	for _, entry := range entries {
+		if err := tradeLogger.LogBuy(entry); err != nil {
+			t.Fatalf("记录买入交易失败: %v", err)
+		}
+	}
+
+	// 分区文件应按账户和策略分开存放
+	partitionPath := filepath.Join(tempDir, "trades", "accounts", "acct-1", "strategies", "momentum",
+		now.Format("2006/01"), "trades_"+now.Format("2006-01-02")+".json")
+	if _, err := os.Stat(partitionPath); err != nil {
+		t.Fatalf("分区日志文件不存在: %v", err)
+	}
+
+	all, err := tradeLogger.GetDailyLogs(now)
+	if err != nil {
+		t.Fatalf("获取交易日志失败: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("预期有3条交易记录，实际有%d条", len(all))
+	}
+
+	byAccount, err := tradeLogger.GetFilteredLogs(now, now, TradeLogFilter{Account: "acct-1"})
+	if err != nil {
+		t.Fatalf("按账户查询交易日志失败: %v", err)
+	}
+	if len(byAccount) != 2 {
+		t.Fatalf("预期账户acct-1有2条交易记录，实际有%d条", len(byAccount))
+	}
+
+	byStrategy, err := tradeLogger.GetFilteredLogs(now, now, TradeLogFilter{Strategy: "momentum"})
+	if err != nil {
+		t.Fatalf("按策略查询交易日志失败: %v", err)
+	}
+	if len(byStrategy) != 2 || byStrategy[0].Symbol != "AAPL" || byStrategy[1].Symbol != "TSLA" {
+		t.Fatalf("按策略查询结果不匹配: %+v", byStrategy)
+	}
+}
+
+func TestCaptureLogger(t *testing.T) {
+	logger, sink := NewCaptureLogger(LogLevelInfo)
+
+	logger.Debug("不应被记录")
+	logger.Info("订单已提交")
+	logger.WithField("symbol", "AAPL").Error("获取报价失败: %s", "超时")
+
+	if len(sink.Entries()) != 2 {
+		t.Fatalf("预期捕获2条日志，实际捕获%d条", len(sink.Entries()))
+	}
+
+	for _, substr := range []string{"AAPL", "获取报价失败"} {
+		if !sink.Contains(LogLevelError, substr) {
+			t.Errorf("未找到包含%q的错误日志", substr)
+		}
+	}
+	if !sink.Contains("", "订单已提交") {
+		t.Error("未找到任意级别的提交日志")
+	}
+	if sink.Contains(LogLevelDebug, "不应被记录") {
+		t.Error("低于日志级别的日志不应被记录")
+	}
+
+	if sink.Count(LogLevelError, "") != 1 {
+		t.Fatalf("预期有1条错误日志")
+	}
+
+	sink.Reset()
+	if len(sink.Entries()) != 0 {
+		t.Fatalf("重置后不应有日志")
+	}
+}
+
+func TestExportToExcelWithTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+
+	sysLogger, sink := NewCaptureLogger(LogLevelError)
+	tradeLogger, err := NewTradeLogger(filepath.Join(tempDir, "trades"), sysLogger)
+	if err != nil {
+		t.Fatalf("创建交易日志记录器失败: %v", err)
+	}
+	defer tradeLogger.Close()
+
+	now := time.Now()
+	tradeLogger.LogBuy(TradeLogEntry{Timestamp: now, Symbol: "AAPL", Quantity: 100, Price: 150.25, Amount: 15025})
+	tradeLogger.LogSell(TradeLogEntry{Timestamp: now, Symbol: "AAPL", Quantity: 100, Price: 155.5, Amount: 15550, PnL: 525})
+
+	tmpl := ExcelTemplate{
+		SheetName: "Trades",
+		Columns: []ExcelColumn{
+			{Field: ExcelFieldSymbol},
+			{Field: ExcelFieldPrice, NumberFormat: "0.00"},
+			{Field: ExcelFieldType},
+		},
+		IncludeSummary:   true,
+		SummarySheetName: "Summary",
+		SummaryLabels:    EnglishSummaryLabels,
+	}.WithHeaders(EnglishExcelHeaders)
+
+	filePath := filepath.Join(tempDir, "export.xlsx")
+	if err := tradeLogger.ExportToExcelWithTemplate(now, filePath, tmpl); err != nil {
+		t.Fatalf("导出Excel失败: %v", err)
+	}
+
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		t.Fatalf("打开导出文件失败: %v", err)
+	}
+	defer f.Close()
+
+	if header, _ := f.GetCellValue("Trades", "B1"); header != "Price" {
+		t.Fatalf("预期表头为Price，实际为%q", header)
+	}
+	if symbol, _ := f.GetCellValue("Trades", "A3"); symbol != "AAPL" {
+		t.Fatalf("预期股票代码为AAPL，实际为%q", symbol)
+	}
+	if label, _ := f.GetCellValue("Summary", "A10"); label != "Net PnL" {
+		t.Fatalf("预期汇总指标为Net PnL，实际为%q", label)
+	}
+	if pnl, _ := f.GetCellValue("Summary", "B10"); pnl != "525" {
+		t.Fatalf("预期净盈亏为525，实际为%q", pnl)
+	}
+
+	// 无效字段应返回错误
+	bad := ExcelTemplate{SheetName: "Trades", Columns: []ExcelColumn{{Field: "unknown"}}}
+	if err := tradeLogger.ExportToExcelWithTemplate(now, filePath, bad); err == nil {
+		t.Fatalf("预期无效模板返回错误")
+	}
+	if sink.Contains(LogLevelError, "") {
+		t.Errorf("导出时不应记录错误日志: %+v", sink.Entries())
+	}
+}
+
+func TestFatalExitFunc(t *testing.T) {
+	sink := NewCaptureSink()
+	exitCode := -1
+	logger, err := NewWriterLogger(LogConfig{
+		Level:    LogLevelInfo,
+		Format:   LogFormatJSON,
+		ExitFunc: func(code int) { exitCode = code },
+	}, sink)
+	if err != nil {
+		t.Fatalf("创建日志记录器失败: %v", err)
+	}
+
+	logger.Fatal("致命错误")
+
+	if exitCode != 1 {
+		t.Fatalf("预期退出码为1，实际为%d", exitCode)
+	}
+	if !sink.Contains(LogLevelFatal, "致命错误") {
+		t.Errorf("未找到致命错误日志: %+v", sink.Entries())
+	}
+}
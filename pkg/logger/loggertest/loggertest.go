@@ -0,0 +1,42 @@
+// Package loggertest 提供在测试中断言 logger.CaptureSink 所捕获日志的辅助函数
+package loggertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/logger"
+)
+
+// AssertLogged 断言存在匹配的日志，否则标记测试失败
+// level为空时匹配所有级别
+func AssertLogged(t testing.TB, sink *logger.CaptureSink, level logger.LogLevel, substr string) {
+	t.Helper()
+	if !sink.Contains(level, substr) {
+		t.Errorf("未找到级别为%q且包含%q的日志，已捕获的日志:\n%s", level, substr, dump(sink))
+	}
+}
+
+// AssertNotLogged 断言不存在匹配的日志，否则标记测试失败
+func AssertNotLogged(t testing.TB, sink *logger.CaptureSink, level logger.LogLevel, substr string) {
+	t.Helper()
+	if sink.Contains(level, substr) {
+		t.Errorf("不应存在级别为%q且包含%q的日志，已捕获的日志:\n%s", level, substr, dump(sink))
+	}
+}
+
+// dump 将已捕获的日志格式化为便于阅读的文本（内部函数）
+func dump(sink *logger.CaptureSink) string {
+	var sb strings.Builder
+	for _, entry := range sink.Entries() {
+		fmt.Fprintf(&sb, "  [%s] %s", entry.Level, entry.Message)
+		if len(entry.Context) > 0 {
+			contextStr, _ := json.Marshal(entry.Context)
+			fmt.Fprintf(&sb, " %s", contextStr)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
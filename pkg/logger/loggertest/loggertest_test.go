@@ -0,0 +1,37 @@
+package loggertest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/logger"
+)
+
+// recordingTB 记录断言失败信息而不终止测试
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
+
+func TestAssertions(t *testing.T) {
+	log, sink := logger.NewCaptureLogger(logger.LogLevelInfo)
+	log.WithField("symbol", "AAPL").Error("获取报价失败")
+
+	// 匹配时不报告失败
+	AssertLogged(t, sink, logger.LogLevelError, "AAPL")
+	AssertNotLogged(t, sink, logger.LogLevelWarn, "")
+
+	// 不匹配时报告失败
+	tb := &recordingTB{TB: t}
+	AssertLogged(tb, sink, logger.LogLevelWarn, "获取报价失败")
+	AssertNotLogged(tb, sink, "", "获取报价失败")
+	if len(tb.errors) != 2 || !strings.Contains(tb.errors[0], "未找到") || !strings.Contains(tb.errors[1], "不应存在") {
+		t.Errorf("断言失败信息不正确: %v", tb.errors)
+	}
+}
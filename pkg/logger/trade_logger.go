@@ -5,23 +5,32 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/xuri/excelize/v2"
+	"github.com/yourusername/qhft-system/pkg/clock"
 )
 
 // defaultTradeLogger 是默认的交易日志实现
 type defaultTradeLogger struct {
 	mu         sync.Mutex
 	baseDir    string
+	options    TradeLoggerOptions
 	currentDay time.Time
-	jsonFile   *os.File
+	files      map[string]*os.File // 当日已打开的日志文件，键为文件路径
 	logger     Logger
 }
 
 // NewTradeLogger 创建一个新的交易日志记录器
 func NewTradeLogger(baseDir string, logger Logger) (TradeLogger, error) {
+	return NewTradeLoggerWithOptions(baseDir, logger, TradeLoggerOptions{})
+}
+
+// NewTradeLoggerWithOptions 使用指定选项创建交易日志记录器
+func NewTradeLoggerWithOptions(baseDir string, logger Logger, options TradeLoggerOptions) (TradeLogger, error) {
 	// 确保日志目录存在
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return nil, fmt.Errorf("创建交易日志目录失败: %v", err)
@@ -33,59 +42,118 @@ func NewTradeLogger(baseDir string, logger Logger) (TradeLogger, error) {
 
 	tl := &defaultTradeLogger{
 		baseDir: baseDir,
+		options: options,
+		files:   make(map[string]*os.File),
 		logger:  logger,
 	}
 
 	// 初始化为今天的日志
-	if err := tl.setCurrentDay(time.Now()); err != nil {
+	if err := tl.setCurrentDay(tl.now()); err != nil {
 		return nil, err
 	}
 
 	return tl, nil
 }
 
-// setCurrentDay 设置当前日期并打开相应的日志文件
+// now 返回选项中时钟的当前时间（内部方法）
+func (tl *defaultTradeLogger) now() time.Time {
+	return clock.Or(tl.options.Clock).Now()
+}
+
+// setCurrentDay 设置当前日期，日期变化时关闭前一天的日志文件
 func (tl *defaultTradeLogger) setCurrentDay(day time.Time) error {
 	tl.mu.Lock()
 	defer tl.mu.Unlock()
 
-	// 如果日期没变且文件已打开，不做任何操作
-	if tl.currentDay.Format("2006-01-02") == day.Format("2006-01-02") && tl.jsonFile != nil {
+	// 如果日期没变，不做任何操作
+	if tl.currentDay.Format("2006-01-02") == day.Format("2006-01-02") {
 		return nil
 	}
 
 	// 关闭旧的文件（如果有）
-	if tl.jsonFile != nil {
-		if err := tl.jsonFile.Close(); err != nil {
+	tl.closeFiles()
+
+	// 更新当前日期
+	tl.currentDay = day
+
+	return nil
+}
+
+// closeFiles 关闭所有已打开的日志文件，调用方需持有锁
+func (tl *defaultTradeLogger) closeFiles() error {
+	var firstErr error
+	for path, file := range tl.files {
+		if err := file.Close(); err != nil {
 			tl.logger.Error("关闭交易日志文件失败: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
-		tl.jsonFile = nil
+		delete(tl.files, path)
 	}
+	return firstErr
+}
 
-	// 更新当前日期
-	tl.currentDay = day
+// partitionDir 返回指定账户和策略对应的分区目录
+func (tl *defaultTradeLogger) partitionDir(account, strategy string) string {
+	dir := tl.baseDir
+	if tl.options.PartitionByAccount {
+		dir = filepath.Join(dir, "accounts", partitionName(account))
+	}
+	if tl.options.PartitionByStrategy {
+		dir = filepath.Join(dir, "strategies", partitionName(strategy))
+	}
+	return dir
+}
 
-	// 创建新的日志文件
-	logDir := filepath.Join(tl.baseDir, tl.currentDay.Format("2006/01"))
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("创建交易日志目录失败: %v", err)
+// dailyLogPaths 返回指定日期下符合过滤条件的所有分区日志文件
+func (tl *defaultTradeLogger) dailyLogPaths(date time.Time, filter TradeLogFilter) ([]string, error) {
+	account, strategy := "*", "*"
+	if filter.Account != "" {
+		account = partitionName(filter.Account)
+	}
+	if filter.Strategy != "" {
+		strategy = partitionName(filter.Strategy)
+	}
+
+	dir := tl.baseDir
+	if tl.options.PartitionByAccount {
+		dir = filepath.Join(dir, "accounts", account)
+	}
+	if tl.options.PartitionByStrategy {
+		dir = filepath.Join(dir, "strategies", strategy)
+	}
+
+	return filepath.Glob(dailyLogPath(dir, date))
+}
+
+// openFile 获取指定路径的日志文件，不存在时创建，调用方需持有锁
+func (tl *defaultTradeLogger) openFile(logPath string) (*os.File, error) {
+	if file, ok := tl.files[logPath]; ok {
+		return file, nil
 	}
 
-	logPath := filepath.Join(logDir, fmt.Sprintf("trades_%s.json", tl.currentDay.Format("2006-01-02")))
-	var err error
-	tl.jsonFile, err = os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return nil, fmt.Errorf("创建交易日志目录失败: %v", err)
+	}
+
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("打开交易日志文件失败: %v", err)
+		return nil, fmt.Errorf("打开交易日志文件失败: %v", err)
 	}
 
-	return nil
+	tl.files[logPath] = file
+	return file, nil
 }
 
 // logEntry 记录一条交易日志
 func (tl *defaultTradeLogger) logEntry(entry TradeLogEntry) error {
 	// 确保日期被设置
 	if entry.Timestamp.IsZero() {
-		entry.Timestamp = time.Now()
+		entry.Timestamp = tl.now()
+	}
+	if tl.options.Simulated {
+		entry.Tags = withTag(entry.Tags, SimulatedTag)
 	}
 
 	// 确保使用正确的日期日志文件
@@ -96,16 +164,23 @@ func (tl *defaultTradeLogger) logEntry(entry TradeLogEntry) error {
 	tl.mu.Lock()
 	defer tl.mu.Unlock()
 
+	// 按账户和策略选择分区文件
+	logPath := dailyLogPath(tl.partitionDir(entry.Account, entry.Strategy), entry.Timestamp)
+	jsonFile, err := tl.openFile(logPath)
+	if err != nil {
+		return err
+	}
+
 	// 序列化并写入日志
 	jsonBytes, err := json.Marshal(entry)
 	if err != nil {
 		return fmt.Errorf("序列化交易日志失败: %v", err)
 	}
 
-	if _, err := tl.jsonFile.Write(jsonBytes); err != nil {
+	if _, err := jsonFile.Write(jsonBytes); err != nil {
 		return fmt.Errorf("写入交易日志失败: %v", err)
 	}
-	if _, err := tl.jsonFile.WriteString("\n"); err != nil {
+	if _, err := jsonFile.WriteString("\n"); err != nil {
 		return fmt.Errorf("写入交易日志失败: %v", err)
 	}
 
@@ -180,33 +255,74 @@ func (tl *defaultTradeLogger) LogSummary(summary DailySummary) error {
 	return tl.logEntry(entry)
 }
 
-// GetDailyLogs 获取特定日期的交易日志
-func (tl *defaultTradeLogger) GetDailyLogs(date time.Time) ([]TradeLogEntry, error) {
-	logDir := filepath.Join(tl.baseDir, date.Format("2006/01"))
-	logPath := filepath.Join(logDir, fmt.Sprintf("trades_%s.json", date.Format("2006-01-02")))
+// LogRejection 记录一条拒单信息
+func (tl *defaultTradeLogger) LogRejection(entry RejectionEntry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = tl.now()
+	}
+	if tl.options.Simulated {
+		entry.Tags = withTag(entry.Tags, SimulatedTag)
+	}
+
+	jsonBytes, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化拒单日志失败: %v", err)
+	}
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	// 拒单日志单独存放，避免与成交记录混在一起
+	rejectDir := filepath.Join(tl.baseDir, "rejections", entry.Timestamp.Format("2006/01"))
+	if err := os.MkdirAll(rejectDir, 0755); err != nil {
+		return fmt.Errorf("创建拒单日志目录失败: %v", err)
+	}
+
+	rejectPath := filepath.Join(rejectDir, fmt.Sprintf("rejections_%s.json", entry.Timestamp.Format("2006-01-02")))
+	file, err := os.OpenFile(rejectPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开拒单日志文件失败: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(jsonBytes, '\n')); err != nil {
+		return fmt.Errorf("写入拒单日志失败: %v", err)
+	}
+
+	// 同时记录到标准日志
+	tl.logger.WithFields(map[string]interface{}{
+		"code":   entry.Code,
+		"source": entry.Source,
+	}).Warn("拒单: %s %s %s 数量:%d 价格:%.2f 原因:%s",
+		entry.Side, entry.Symbol, entry.OrderType, entry.Quantity, entry.Price, entry.Reason)
+
+	return nil
+}
+
+// GetRejections 获取特定日期的拒单记录
+func (tl *defaultTradeLogger) GetRejections(date time.Time) ([]RejectionEntry, error) {
+	rejectDir := filepath.Join(tl.baseDir, "rejections", date.Format("2006/01"))
+	rejectPath := filepath.Join(rejectDir, fmt.Sprintf("rejections_%s.json", date.Format("2006-01-02")))
 
 	// 检查文件是否存在
-	if _, err := os.Stat(logPath); os.IsNotExist(err) {
-		return []TradeLogEntry{}, nil
+	if _, err := os.Stat(rejectPath); os.IsNotExist(err) {
+		return []RejectionEntry{}, nil
 	}
 
-	// 读取文件内容
-	content, err := os.ReadFile(logPath)
+	content, err := os.ReadFile(rejectPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取交易日志失败: %v", err)
+		return nil, fmt.Errorf("读取拒单日志失败: %v", err)
 	}
 
-	// 解析每一行为一个日志条目
-	var entries []TradeLogEntry
-	lines := splitLines(string(content))
-	for _, line := range lines {
+	var entries []RejectionEntry
+	for _, line := range splitLines(string(content)) {
 		if line == "" {
 			continue
 		}
 
-		var entry TradeLogEntry
+		var entry RejectionEntry
 		if err := json.Unmarshal([]byte(line), &entry); err != nil {
-			tl.logger.Error("解析交易日志条目失败: %v", err)
+			tl.logger.Error("解析拒单日志条目失败: %v", err)
 			continue
 		}
 		entries = append(entries, entry)
@@ -215,13 +331,23 @@ func (tl *defaultTradeLogger) GetDailyLogs(date time.Time) ([]TradeLogEntry, err
 	return entries, nil
 }
 
+// GetDailyLogs 获取特定日期的交易日志（包含所有分区）
+func (tl *defaultTradeLogger) GetDailyLogs(date time.Time) ([]TradeLogEntry, error) {
+	return tl.getDailyLogs(date, TradeLogFilter{})
+}
+
 // GetDateRange 获取日期范围内的所有交易日志
 func (tl *defaultTradeLogger) GetDateRange(start, end time.Time) ([]TradeLogEntry, error) {
+	return tl.GetFilteredLogs(start, end, TradeLogFilter{})
+}
+
+// GetFilteredLogs 获取日期范围内符合账户和策略过滤条件的交易日志
+func (tl *defaultTradeLogger) GetFilteredLogs(start, end time.Time, filter TradeLogFilter) ([]TradeLogEntry, error) {
 	var allEntries []TradeLogEntry
 
 	// 遍历日期范围
 	for d := truncateToDay(start); !d.After(truncateToDay(end)); d = d.AddDate(0, 0, 1) {
-		entries, err := tl.GetDailyLogs(d)
+		entries, err := tl.getDailyLogs(d, filter)
 		if err != nil {
 			tl.logger.Error("获取日期 %s 的交易日志失败: %v", d.Format("2006-01-02"), err)
 			continue
@@ -232,8 +358,64 @@ func (tl *defaultTradeLogger) GetDateRange(start, end time.Time) ([]TradeLogEntr
 	return allEntries, nil
 }
 
+// getDailyLogs 读取特定日期下符合过滤条件的交易日志
+func (tl *defaultTradeLogger) getDailyLogs(date time.Time, filter TradeLogFilter) ([]TradeLogEntry, error) {
+	logPaths, err := tl.dailyLogPaths(date, filter)
+	if err != nil {
+		return nil, fmt.Errorf("查找交易日志文件失败: %v", err)
+	}
+
+	entries := []TradeLogEntry{}
+	for _, logPath := range logPaths {
+		// 读取文件内容
+		content, err := os.ReadFile(logPath)
+		if err != nil {
+			return nil, fmt.Errorf("读取交易日志失败: %v", err)
+		}
+
+		// 解析每一行为一个日志条目
+		lines := splitLines(string(content))
+		for _, line := range lines {
+			if line == "" {
+				continue
+			}
+
+			var entry TradeLogEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				tl.logger.Error("解析交易日志条目失败: %v", err)
+				continue
+			}
+			if !filter.Match(entry) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	// 多个分区合并后按时间排序
+	if len(logPaths) > 1 {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Timestamp.Before(entries[j].Timestamp)
+		})
+	}
+
+	return entries, nil
+}
+
 // ExportToExcel 将特定日期的交易日志导出为Excel文件
 func (tl *defaultTradeLogger) ExportToExcel(date time.Time, filePath string) error {
+	return tl.ExportToExcelWithTemplate(date, filePath, DefaultExcelTemplate())
+}
+
+// ExportToExcelWithTemplate 按指定模板将特定日期的交易日志导出为Excel文件
+func (tl *defaultTradeLogger) ExportToExcelWithTemplate(date time.Time, filePath string, tmpl ExcelTemplate) error {
+	if err := tmpl.Validate(); err != nil {
+		return err
+	}
+	if tmpl.TimeFormat == "" {
+		tmpl.TimeFormat = "2006-01-02 15:04:05"
+	}
+
 	// 获取日志数据
 	entries, err := tl.GetDailyLogs(date)
 	if err != nil {
@@ -253,7 +435,7 @@ func (tl *defaultTradeLogger) ExportToExcel(date time.Time, filePath string) err
 	}()
 
 	// 创建交易表格
-	sheetName := "交易记录"
+	sheetName := tmpl.SheetName
 	index, err := f.NewSheet(sheetName)
 	if err != nil {
 		return fmt.Errorf("创建Excel表格失败: %v", err)
@@ -261,37 +443,44 @@ func (tl *defaultTradeLogger) ExportToExcel(date time.Time, filePath string) err
 	f.SetActiveSheet(index)
 
 	// 设置表头
-	headers := []string{"时间", "类型", "股票代码", "数量", "价格", "金额", "手续费", "盈亏", "盈亏%", "持仓", "成本", "持有时间", "策略", "订单ID", "备注"}
-	for i, header := range headers {
+	for i, col := range tmpl.Columns {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-		f.SetCellValue(sheetName, cell, header)
+		f.SetCellValue(sheetName, cell, col.header())
 	}
 
 	// 填充数据
 	for i, entry := range entries {
 		row := i + 2
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), entry.Timestamp.Format("2006-01-02 15:04:05"))
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), entry.Type)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), entry.Symbol)
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), entry.Quantity)
-		f.SetCellValue(sheetName, fmt.Sprintf("E%d", row), entry.Price)
-		f.SetCellValue(sheetName, fmt.Sprintf("F%d", row), entry.Amount)
-		f.SetCellValue(sheetName, fmt.Sprintf("G%d", row), entry.Commission)
-		f.SetCellValue(sheetName, fmt.Sprintf("H%d", row), entry.PnL)
-		f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), entry.PnLPercent)
-		f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), entry.Position)
-		f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), entry.EntryPrice)
-		f.SetCellValue(sheetName, fmt.Sprintf("L%d", row), entry.HoldTime)
-		f.SetCellValue(sheetName, fmt.Sprintf("M%d", row), entry.Strategy)
-		f.SetCellValue(sheetName, fmt.Sprintf("N%d", row), entry.OrderID)
-		f.SetCellValue(sheetName, fmt.Sprintf("O%d", row), entry.Notes)
-	}
-
-	// 设置列宽
-	f.SetColWidth(sheetName, "A", "A", 20)
-	f.SetColWidth(sheetName, "B", "C", 12)
-	f.SetColWidth(sheetName, "D", "L", 12)
-	f.SetColWidth(sheetName, "M", "O", 20)
+		for j, col := range tmpl.Columns {
+			cell, _ := excelize.CoordinatesToCellName(j+1, row)
+			f.SetCellValue(sheetName, cell, excelFieldValue(entry, col.Field, tmpl.TimeFormat))
+		}
+	}
+
+	// 设置列宽和数字格式
+	lastRow := len(entries) + 1
+	for i, col := range tmpl.Columns {
+		colName, _ := excelize.ColumnNumberToName(i + 1)
+		if col.Width > 0 {
+			f.SetColWidth(sheetName, colName, colName, col.Width)
+		}
+
+		if col.NumberFormat != "" {
+			numFmt := col.NumberFormat
+			style, err := f.NewStyle(&excelize.Style{CustomNumFmt: &numFmt})
+			if err != nil {
+				return fmt.Errorf("创建数字格式失败: %v", err)
+			}
+			f.SetCellStyle(sheetName, fmt.Sprintf("%s2", colName), fmt.Sprintf("%s%d", colName, lastRow), style)
+		}
+	}
+
+	// 添加汇总表
+	if tmpl.IncludeSummary {
+		if err := writeSummarySheet(f, tmpl, date, entries); err != nil {
+			return err
+		}
+	}
 
 	// 保存Excel文件
 	if err := f.SaveAs(filePath); err != nil {
@@ -301,15 +490,72 @@ func (tl *defaultTradeLogger) ExportToExcel(date time.Time, filePath string) err
 	return nil
 }
 
+// writeSummarySheet 根据交易日志生成汇总表
+func writeSummarySheet(f *excelize.File, tmpl ExcelTemplate, date time.Time, entries []TradeLogEntry) error {
+	sheetName := tmpl.SummarySheetName
+	if sheetName == "" {
+		sheetName = "汇总"
+	}
+	if _, err := f.NewSheet(sheetName); err != nil {
+		return fmt.Errorf("创建汇总表失败: %v", err)
+	}
+
+	var buyTrades, sellTrades, winning, losing int
+	var totalAmount, totalCommission, netPnL float64
+	for _, entry := range entries {
+		switch entry.Type {
+		case "buy":
+			buyTrades++
+		case "sell":
+			sellTrades++
+			if entry.PnL > 0 {
+				winning++
+			} else if entry.PnL < 0 {
+				losing++
+			}
+			netPnL += entry.PnL
+		default:
+			continue
+		}
+		totalAmount += entry.Amount
+		totalCommission += entry.Commission
+	}
+
+	var winRate float64
+	if sellTrades > 0 {
+		winRate = float64(winning) / float64(sellTrades) * 100
+	}
+
+	values := map[string]interface{}{
+		ExcelSummaryDate:        date.Format("2006-01-02"),
+		ExcelSummaryTotalTrades: buyTrades + sellTrades,
+		ExcelSummaryBuyTrades:   buyTrades,
+		ExcelSummarySellTrades:  sellTrades,
+		ExcelSummaryWinning:     winning,
+		ExcelSummaryLosing:      losing,
+		ExcelSummaryWinRate:     winRate,
+		ExcelSummaryTotalAmount: totalAmount,
+		ExcelSummaryCommission:  totalCommission,
+		ExcelSummaryNetPnL:      netPnL - totalCommission,
+	}
+
+	for i, key := range summaryOrder {
+		row := i + 1
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), tmpl.summaryLabel(key))
+		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), values[key])
+	}
+	f.SetColWidth(sheetName, "A", "A", 16)
+	f.SetColWidth(sheetName, "B", "B", 16)
+
+	return nil
+}
+
 // Close 关闭交易日志记录器
 func (tl *defaultTradeLogger) Close() error {
 	tl.mu.Lock()
 	defer tl.mu.Unlock()
 
-	if tl.jsonFile != nil {
-		return tl.jsonFile.Close()
-	}
-	return nil
+	return tl.closeFiles()
 }
 
 // 辅助函数
@@ -332,6 +578,29 @@ func splitLines(s string) []string {
 	return lines
 }
 
+// withTag 返回追加了标签的新切片，标签已存在时原样返回（内部函数）
+func withTag(tags []string, tag string) []string {
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(append([]string(nil), tags...), tag)
+}
+
+// dailyLogPath 返回分区目录下指定日期的日志文件路径
+func dailyLogPath(dir string, date time.Time) string {
+	return filepath.Join(dir, date.Format("2006/01"), fmt.Sprintf("trades_%s.json", date.Format("2006-01-02")))
+}
+
+// partitionName 将账户或策略名称转换为安全的目录名
+func partitionName(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return strings.NewReplacer("/", "_", "\\", "_", "*", "_", "?", "_", "[", "_", "]", "_", ":", "_").Replace(name)
+}
+
 // truncateToDay 将时间截断至日期
 func truncateToDay(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
@@ -340,29 +609,52 @@ func truncateToDay(t time.Time) time.Time {
 // 全局默认交易日志记录器
 var (
 	defaultTradeLoggerInstance TradeLogger
-	tradeLoggerOnce            sync.Once
+	defaultTradeLoggerMu       sync.Mutex
 )
 
 // GetDefaultTradeLogger 获取全局默认交易日志记录器
-func GetDefaultTradeLogger() TradeLogger {
-	tradeLoggerOnce.Do(func() {
-		logger, err := NewTradeLogger("logs/trades", GetDefaultLogger())
+// 如果尚未通过InitDefaultTradeLogger初始化，则在logs/trades目录下创建
+func GetDefaultTradeLogger() (TradeLogger, error) {
+	defaultTradeLoggerMu.Lock()
+	defer defaultTradeLoggerMu.Unlock()
+
+	if defaultTradeLoggerInstance == nil {
+		tradeLogger, err := NewTradeLogger("logs/trades", GetDefaultLogger())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "初始化默认交易日志记录器失败: %v\n", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("初始化默认交易日志记录器失败: %v", err)
 		}
-		defaultTradeLoggerInstance = logger
-	})
+		defaultTradeLoggerInstance = tradeLogger
+	}
+
+	return defaultTradeLoggerInstance, nil
+}
 
-	return defaultTradeLoggerInstance
+// MustGetDefaultTradeLogger 获取全局默认交易日志记录器，失败时panic
+func MustGetDefaultTradeLogger() TradeLogger {
+	tradeLogger, err := GetDefaultTradeLogger()
+	if err != nil {
+		panic(err)
+	}
+	return tradeLogger
 }
 
 // InitDefaultTradeLogger 初始化全局默认交易日志记录器
-func InitDefaultTradeLogger(baseDir string, logger Logger) {
+func InitDefaultTradeLogger(baseDir string, logger Logger) error {
 	tradeLogger, err := NewTradeLogger(baseDir, logger)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "初始化默认交易日志记录器失败: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("初始化默认交易日志记录器失败: %v", err)
 	}
+
+	defaultTradeLoggerMu.Lock()
 	defaultTradeLoggerInstance = tradeLogger
-} 
\ No newline at end of file
+	defaultTradeLoggerMu.Unlock()
+
+	return nil
+}
+
+// MustInitDefaultTradeLogger 初始化全局默认交易日志记录器，失败时panic
+func MustInitDefaultTradeLogger(baseDir string, logger Logger) {
+	if err := InitDefaultTradeLogger(baseDir, logger); err != nil {
+		panic(err)
+	}
+}
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
 )
 
 // LogLevel 表示日志级别
@@ -60,6 +62,13 @@ type LogConfig struct {
 	MaxBackups int       `json:"max_backups" yaml:"max_backups"`
 	MaxAgeDays int       `json:"max_age_days" yaml:"max_age_days"`
 	Compress   bool      `json:"compress" yaml:"compress"`
+
+	// ExitFunc 在记录Fatal日志后调用，为空时使用os.Exit
+	// 嵌入到其他应用时可替换为自定义处理，避免直接终止宿主进程
+	ExitFunc func(code int) `json:"-" yaml:"-"`
+
+	// Clock 提供日志时间戳，为空时使用系统时间；回测和回放时使用模拟时钟
+	Clock clock.Clock `json:"-" yaml:"-"`
 }
 
 // TradeLogEntry 表示交易日志记录
@@ -77,10 +86,60 @@ type TradeLogEntry struct {
 	EntryPrice     float64   `json:"entry_price,omitempty"`   // 平均成本
 	HoldTime       float64   `json:"hold_time,omitempty"`     // 持仓时间（小时）
 	Strategy       string    `json:"strategy,omitempty"`      // 交易策略
+	Account        string    `json:"account,omitempty"`       // 交易账户
 	OrderID        string    `json:"order_id,omitempty"`      // 订单ID
 	ExecutionID    string    `json:"execution_id,omitempty"`  // 执行ID
 	Notes          string    `json:"notes,omitempty"`         // 备注
 	Tags           []string  `json:"tags,omitempty"`          // 标签
+	Metadata       map[string]string `json:"metadata,omitempty"` // 订单附加的自定义元数据
+}
+
+// TradeLoggerOptions 表示交易日志记录器的选项
+type TradeLoggerOptions struct {
+	PartitionByAccount  bool `json:"partition_by_account" yaml:"partition_by_account"`   // 按账户分区存储
+	PartitionByStrategy bool `json:"partition_by_strategy" yaml:"partition_by_strategy"` // 按策略分区存储
+	Simulated           bool `json:"-" yaml:"-"`                                         // 模拟模式，所有记录附加 SimulatedTag 标签
+
+	// Clock 提供未设置时间的记录的时间戳，为空时使用系统时间
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+// SimulatedTag 是模拟模式下附加到交易日志和拒单日志的标签
+const SimulatedTag = "simulated"
+
+// TradeLogFilter 表示交易日志查询的过滤条件，空字段表示不过滤
+type TradeLogFilter struct {
+	Account  string `json:"account,omitempty"`
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// Match 检查日志条目是否符合过滤条件
+func (f TradeLogFilter) Match(entry TradeLogEntry) bool {
+	if f.Account != "" && entry.Account != f.Account {
+		return false
+	}
+	if f.Strategy != "" && entry.Strategy != f.Strategy {
+		return false
+	}
+	return true
+}
+
+// RejectionEntry 表示一条拒单日志记录
+type RejectionEntry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Code          string    `json:"code"`                      // 机器可读的拒单原因代码
+	Reason        string    `json:"reason"`                    // 拒单原因描述
+	Source        string    `json:"source,omitempty"`          // 拒单来源，如"engine", "broker"
+	Symbol        string    `json:"symbol,omitempty"`
+	Quantity      int64     `json:"quantity,omitempty"`
+	Price         float64   `json:"price,omitempty"`
+	StopPrice     float64   `json:"stop_price,omitempty"`
+	OrderType     string    `json:"order_type,omitempty"`
+	Side          string    `json:"side,omitempty"`
+	Strategy      string    `json:"strategy,omitempty"`
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"` // 下单请求附加的自定义元数据
 }
 
 // DailySummary 表示每日交易汇总
@@ -131,10 +190,14 @@ type TradeLogger interface {
 	LogSell(entry TradeLogEntry) error
 	LogPosition(entry TradeLogEntry) error
 	LogSummary(summary DailySummary) error
+	LogRejection(entry RejectionEntry) error
 	
 	GetDailyLogs(date time.Time) ([]TradeLogEntry, error)
+	GetRejections(date time.Time) ([]RejectionEntry, error)
 	GetDateRange(start, end time.Time) ([]TradeLogEntry, error)
+	GetFilteredLogs(start, end time.Time, filter TradeLogFilter) ([]TradeLogEntry, error)
 	ExportToExcel(date time.Time, filePath string) error
+	ExportToExcelWithTemplate(date time.Time, filePath string, tmpl ExcelTemplate) error
 	
 	Close() error
 } 
\ No newline at end of file
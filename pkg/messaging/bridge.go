@@ -0,0 +1,201 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// BridgeConfig 表示事件总线与消息总线之间的桥接配置
+type BridgeConfig struct {
+	Source       string   // 本进程标识，为空时使用主机名和进程号
+	Prefix       string   // 消息主题前缀，如 "qhft" 对应 qhft.orders、qhft.fills
+	ExportTopics []string // 转发到消息总线的本地事件主题，为空时转发所有主题
+	ImportTopics []string // 从消息总线导入到本地事件总线的主题
+}
+
+// Bridge 将本地事件总线上的事件发布到NATS/Kafka，并将其他进程发布的事件导入本地总线，
+// 使扫描器、交易引擎和界面可以运行在不同的进程中
+type Bridge struct {
+	bus       *events.Bus
+	transport Transport
+	config    BridgeConfig
+}
+
+// NewBridge 创建一个新的事件桥接
+func NewBridge(bus *events.Bus, transport Transport, config BridgeConfig) *Bridge {
+	if config.Source == "" {
+		host, _ := os.Hostname()
+		config.Source = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if config.Prefix == "" {
+		config.Prefix = "qhft"
+	}
+
+	return &Bridge{
+		bus:       bus,
+		transport: transport,
+		config:    config,
+	}
+}
+
+// Run 启动导出和导入，阻塞直到上下文取消或传输层出错
+func (b *Bridge) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(b.config.ImportTopics)+1)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errCh <- b.export(ctx)
+	}()
+
+	for _, topic := range b.config.ImportTopics {
+		wg.Add(1)
+		go func(topic string) {
+			defer wg.Done()
+			errCh <- b.transport.Subscribe(ctx, subjectFor(b.config.Prefix, topic), b.importMessage)
+		}(topic)
+	}
+
+	// 任一方向出错时停止全部
+	var errs []error
+	if err := <-errCh; err != nil {
+		errs = append(errs, err)
+	}
+	cancel()
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// export 将本地事件发布到消息总线（内部方法）
+func (b *Bridge) export(ctx context.Context) error {
+	sub := b.bus.Subscribe(1024, b.config.ExportTopics...)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case evt, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+
+			// 从其他进程导入的事件不再转发
+			if _, imported := evt.Payload.(json.RawMessage); imported {
+				continue
+			}
+
+			data, err := encodeEvent(b.config.Source, evt)
+			if err != nil {
+				fmt.Printf("Error encoding event %s: %v\n", evt.Type, err)
+				continue
+			}
+			if err := b.transport.Publish(ctx, subjectFor(b.config.Prefix, evt.Topic), data); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Printf("Error publishing event %s: %v\n", evt.Type, err)
+			}
+		}
+	}
+}
+
+// importMessage 将其他进程发布的事件导入本地事件总线（内部方法）
+// 导入事件的Payload为json.RawMessage，订阅者可按事件类型解码
+func (b *Bridge) importMessage(ctx context.Context, msg Message) error {
+	var env Envelope
+	if err := json.Unmarshal(msg.Data, &env); err != nil {
+		return fmt.Errorf("invalid event envelope: %v", err)
+	}
+	if env.Source == b.config.Source {
+		return nil
+	}
+
+	b.bus.Publish(events.Event{
+		Topic:     env.Topic,
+		Type:      env.Type,
+		Timestamp: env.Timestamp,
+		Payload:   env.Payload,
+	})
+	return nil
+}
+
+// OrderResult 表示通过消息总线提交订单的处理结果
+type OrderResult struct {
+	ClientOrderID string             `json:"client_order_id,omitempty"`
+	Order         *trading.Order     `json:"order,omitempty"`
+	Code          trading.RejectCode `json:"code,omitempty"`
+	Error         string             `json:"error,omitempty"`
+	Timestamp     time.Time          `json:"timestamp"`
+}
+
+// OrderConsumer 从消息总线接收下单请求并通过交易引擎执行，处理结果发布到结果主题
+type OrderConsumer struct {
+	engine        trading.TradingEngine
+	transport     Transport
+	subject       string
+	resultSubject string
+}
+
+// NewOrderConsumer 创建下单请求消费者，请求主题为 <prefix>.orders.requests，结果主题为 <prefix>.orders.results
+func NewOrderConsumer(engine trading.TradingEngine, transport Transport, prefix string) *OrderConsumer {
+	if prefix == "" {
+		prefix = "qhft"
+	}
+
+	return &OrderConsumer{
+		engine:        engine,
+		transport:     transport,
+		subject:       subjectFor(prefix, "orders.requests"),
+		resultSubject: subjectFor(prefix, "orders.results"),
+	}
+}
+
+// Run 开始消费下单请求，阻塞直到上下文取消
+func (c *OrderConsumer) Run(ctx context.Context) error {
+	return c.transport.Subscribe(ctx, c.subject, c.handle)
+}
+
+// handle 处理单个下单请求（内部方法）
+func (c *OrderConsumer) handle(ctx context.Context, msg Message) error {
+	var req trading.OrderRequest
+	result := OrderResult{Timestamp: time.Now()}
+
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		result.Code = trading.RejectCodeInvalidParams
+		result.Error = fmt.Sprintf("invalid order request: %v", err)
+	} else {
+		result.ClientOrderID = req.ClientOrderID
+		order, err := c.engine.PlaceOrder(ctx, req)
+		if err != nil {
+			result.Code = trading.GetRejectCode(err)
+			result.Error = err.Error()
+		} else {
+			result.Order = order
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return c.transport.Publish(ctx, c.resultSubject, data)
+}
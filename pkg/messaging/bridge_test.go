@@ -0,0 +1,228 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// memTransport 是内存中的传输层：发布的消息按主题投递给订阅者，并记录所有发布的消息（内部类型）
+type memTransport struct {
+	mu        sync.Mutex
+	subs      map[string][]chan Message
+	published []Message
+	notify    chan struct{}
+}
+
+// newMemTransport 创建内存传输层（内部函数）
+func newMemTransport() *memTransport {
+	return &memTransport{subs: make(map[string][]chan Message), notify: make(chan struct{}, 1)}
+}
+
+func (t *memTransport) Publish(ctx context.Context, subject string, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	msg := Message{Subject: subject, Data: append([]byte(nil), data...)}
+	t.published = append(t.published, msg)
+	for _, ch := range t.subs[subject] {
+		ch <- msg
+	}
+	select {
+	case t.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (t *memTransport) Subscribe(ctx context.Context, subject string, handler Handler) error {
+	ch := make(chan Message, 100)
+	t.mu.Lock()
+	t.subs[subject] = append(t.subs[subject], ch)
+	t.mu.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ch:
+			handler(ctx, msg)
+		}
+	}
+}
+
+func (t *memTransport) Close() error { return nil }
+
+// waitSubscribed 等待主题有订阅者（内部方法）
+func (t *memTransport) waitSubscribed(tb testing.TB, subject string) {
+	tb.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		t.mu.Lock()
+		n := len(t.subs[subject])
+		t.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	tb.Fatalf("主题%s没有订阅者", subject)
+}
+
+// waitPublished 等待主题上发布了n条消息并返回这些消息（内部方法）
+func (t *memTransport) waitPublished(tb testing.TB, subject string, n int) []Message {
+	tb.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		var msgs []Message
+		t.mu.Lock()
+		for _, msg := range t.published {
+			if msg.Subject == subject {
+				msgs = append(msgs, msg)
+			}
+		}
+		t.mu.Unlock()
+		if len(msgs) >= n {
+			return msgs
+		}
+		select {
+		case <-t.notify:
+		case <-timeout:
+			tb.Fatalf("主题%s上应发布%d条消息，实际%d条", subject, n, len(msgs))
+		}
+	}
+}
+
+// subjects 返回已发布消息的主题（内部方法）
+func (t *memTransport) subjects() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var subjects []string
+	for _, msg := range t.published {
+		subjects = append(subjects, msg.Subject)
+	}
+	return subjects
+}
+
+func TestBridgeTopics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := events.NewBus()
+	transport := newMemTransport()
+	bridge := NewBridge(bus, transport, BridgeConfig{
+		Source:       "engine-1",
+		ExportTopics: []string{events.TopicOrders},
+		ImportTopics: []string{events.TopicSignals},
+	})
+	local := bus.Subscribe(10, events.TopicSignals)
+	defer local.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- bridge.Run(ctx) }()
+	transport.waitSubscribed(t, "qhft.signals")
+	for bus.SubscriberCount() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 导出主题上的事件以 <prefix>.<topic> 发布，其他主题不导出
+	now := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	bus.Publish(events.Event{Topic: events.TopicWatchlist, Type: "watchlist.added", Timestamp: now, Payload: "AAPL"})
+	bus.Publish(events.Event{Topic: events.TopicOrders, Type: "order.filled", Timestamp: now, Payload: trading.Order{ID: "ord-1", Symbol: "AAPL"}})
+	msgs := transport.waitPublished(t, "qhft.orders", 1)
+	var env Envelope
+	if err := json.Unmarshal(msgs[0].Data, &env); err != nil {
+		t.Fatalf("解码消息失败: %v", err)
+	}
+	var order trading.Order
+	json.Unmarshal(env.Payload, &order)
+	if env.Source != "engine-1" || env.Topic != events.TopicOrders || env.Type != "order.filled" || !env.Timestamp.Equal(now) || order.ID != "ord-1" {
+		t.Errorf("导出的消息不正确: %+v %+v", env, order)
+	}
+
+	// 其他进程发布的事件导入本地总线，Payload为原始JSON；本进程发布的事件被忽略
+	own, _ := encodeEvent("engine-1", events.Event{Topic: events.TopicSignals, Type: "scan.own", Timestamp: now})
+	transport.Publish(ctx, "qhft.signals", own)
+	remote, _ := encodeEvent("scanner-1", events.Event{Topic: events.TopicSignals, Type: "scan.completed", Timestamp: now, Payload: map[string]string{"symbol": "MSFT"}})
+	transport.Publish(ctx, "qhft.signals", remote)
+	select {
+	case evt := <-local.C:
+		raw, ok := evt.Payload.(json.RawMessage)
+		if evt.Type != "scan.completed" || evt.Topic != events.TopicSignals || !ok || string(raw) != `{"symbol":"MSFT"}` {
+			t.Errorf("导入的事件不正确: %+v", evt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("应导入其他进程发布的事件")
+	}
+	select {
+	case evt := <-local.C:
+		t.Errorf("本进程发布的事件不应导入: %+v", evt)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("上下文取消后应正常退出: %v", err)
+	}
+	for _, subject := range transport.subjects() {
+		if subject == "qhft.watchlist" {
+			t.Errorf("未配置导出的主题不应发布: %v", transport.subjects())
+		}
+	}
+}
+
+func TestOrderConsumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := testutil.NewMockBroker("")
+	broker.SetPrice("AAPL", 150)
+	engine := trading.NewBaseTradingEngine(nil, trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	transport := newMemTransport()
+	consumer := NewOrderConsumer(engine, transport, "desk")
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+	transport.waitSubscribed(t, "desk.orders.requests")
+
+	// 有效请求通过交易引擎下单，结果带回客户端订单号
+	req, _ := json.Marshal(trading.OrderRequest{Symbol: "AAPL", Quantity: 10, Type: trading.OrderTypeMarket, Side: trading.OrderSideBuy, ClientOrderID: "c-1"})
+	transport.Publish(ctx, "desk.orders.requests", req)
+	transport.Publish(ctx, "desk.orders.requests", []byte("{not json"))
+	results := transport.waitPublished(t, "desk.orders.results", 2)
+
+	var filled, invalid OrderResult
+	json.Unmarshal(results[0].Data, &filled)
+	json.Unmarshal(results[1].Data, &invalid)
+	if filled.ClientOrderID != "c-1" || filled.Order == nil || filled.Order.Status != trading.OrderStatusFilled || filled.Error != "" {
+		t.Errorf("下单结果不正确: %+v", filled)
+	}
+	if pos, err := engine.GetPosition(ctx, "AAPL"); err != nil || pos.Quantity != 10 {
+		t.Errorf("应通过交易引擎建立持仓: %+v %v", pos, err)
+	}
+	if invalid.Code != trading.RejectCodeInvalidParams || invalid.Error == "" {
+		t.Errorf("无效请求应返回参数错误: %+v", invalid)
+	}
+
+	// 交易引擎拒绝的请求返回拒绝代码
+	engine.Disable()
+	transport.Publish(ctx, "desk.orders.requests", req)
+	results = transport.waitPublished(t, "desk.orders.results", 3)
+	var rejected OrderResult
+	json.Unmarshal(results[2].Data, &rejected)
+	if rejected.Code != trading.RejectCodeTradingDisabled || rejected.Order != nil || rejected.ClientOrderID != "c-1" {
+		t.Errorf("应返回拒绝代码: %+v", rejected)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("上下文取消后应正常退出: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaTransport 是基于Kafka的传输层实现
+type KafkaTransport struct {
+	brokers []string
+	group   string
+	writer  *kafka.Writer
+
+	mu      sync.Mutex
+	readers []*kafka.Reader
+}
+
+// NewKafkaTransport 创建Kafka传输层，group 为消费组ID，为空时使用 "qhft"
+func NewKafkaTransport(brokers []string, group string) (*KafkaTransport, error) {
+	if len(brokers) == 0 || brokers[0] == "" {
+		return nil, fmt.Errorf("kafka brokers are required")
+	}
+	if group == "" {
+		group = "qhft"
+	}
+
+	return &KafkaTransport{
+		brokers: brokers,
+		group:   group,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.LeastBytes{},
+			AllowAutoTopicCreation: true,
+		},
+	}, nil
+}
+
+// Publish 发布消息
+func (t *KafkaTransport) Publish(ctx context.Context, subject string, data []byte) error {
+	return t.writer.WriteMessages(ctx, kafka.Message{Topic: subject, Value: data})
+}
+
+// Subscribe 订阅主题并处理消息，直到上下文取消
+// 每条消息处理后提交偏移量，处理失败的消息记录错误后跳过
+func (t *KafkaTransport) Subscribe(ctx context.Context, subject string, handler Handler) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: t.brokers,
+		GroupID: t.group,
+		Topic:   subject,
+	})
+	t.mu.Lock()
+	t.readers = append(t.readers, reader)
+	t.mu.Unlock()
+	defer reader.Close()
+
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("failed to read kafka topic %s: %v", subject, err)
+		}
+
+		if err := handler(ctx, Message{Subject: msg.Topic, Data: msg.Value}); err != nil {
+			fmt.Printf("Error handling kafka message on %s: %v\n", msg.Topic, err)
+		}
+		if err := reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed to commit kafka offset: %v", err)
+		}
+	}
+}
+
+// Close 关闭所有读写连接
+func (t *KafkaTransport) Close() error {
+	t.mu.Lock()
+	readers := t.readers
+	t.readers = nil
+	t.mu.Unlock()
+
+	errs := []error{t.writer.Close()}
+	for _, reader := range readers {
+		errs = append(errs, reader.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,85 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// 消息总线驱动常量
+const (
+	DriverNATS  = "nats"
+	DriverKafka = "kafka"
+)
+
+// Message 表示从消息总线收到的一条消息
+type Message struct {
+	Subject string
+	Data    []byte
+}
+
+// Handler 处理收到的消息，返回错误时由具体实现决定是否重试
+type Handler func(ctx context.Context, msg Message) error
+
+// Transport 定义了消息总线传输层的接口，NATS和Kafka各有一个实现
+// subject 在NATS中对应主题(subject)，在Kafka中对应topic
+type Transport interface {
+	// Publish 发布消息
+	Publish(ctx context.Context, subject string, data []byte) error
+
+	// Subscribe 订阅主题并阻塞处理消息，直到上下文取消
+	Subscribe(ctx context.Context, subject string, handler Handler) error
+
+	// Close 关闭连接
+	Close() error
+}
+
+// Envelope 是跨进程传递事件时使用的消息格式
+type Envelope struct {
+	Source    string          `json:"source"` // 发布进程的标识，用于避免回环
+	Topic     string          `json:"topic"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// NewTransport 根据驱动名称创建传输层
+// NATS 使用 url（如 nats://localhost:4222），Kafka 使用逗号分隔的broker列表和消费组
+func NewTransport(driver, url, group string) (Transport, error) {
+	switch driver {
+	case DriverNATS:
+		return NewNATSTransport(url)
+	case DriverKafka:
+		return NewKafkaTransport(strings.Split(url, ","), group)
+	default:
+		return nil, fmt.Errorf("unsupported messaging driver %q", driver)
+	}
+}
+
+// encodeEvent 将事件编码为消息（内部函数）
+func encodeEvent(source string, evt events.Event) ([]byte, error) {
+	payload, err := json.Marshal(evt.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event payload: %v", err)
+	}
+
+	return json.Marshal(Envelope{
+		Source:    source,
+		Topic:     evt.Topic,
+		Type:      evt.Type,
+		Timestamp: evt.Timestamp,
+		Payload:   payload,
+	})
+}
+
+// subjectFor 返回事件主题对应的消息主题（内部函数）
+func subjectFor(prefix, topic string) string {
+	if prefix == "" {
+		return topic
+	}
+	return prefix + "." + topic
+}
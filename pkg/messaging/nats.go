@@ -0,0 +1,61 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSTransport 是基于NATS的传输层实现
+type NATSTransport struct {
+	conn *nats.Conn
+}
+
+// NewNATSTransport 连接NATS服务器
+func NewNATSTransport(url string, options ...nats.Option) (*NATSTransport, error) {
+	if url == "" {
+		url = nats.DefaultURL
+	}
+
+	conn, err := nats.Connect(url, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %v", err)
+	}
+
+	return &NATSTransport{conn: conn}, nil
+}
+
+// Publish 发布消息
+func (t *NATSTransport) Publish(ctx context.Context, subject string, data []byte) error {
+	return t.conn.Publish(subject, data)
+}
+
+// Subscribe 订阅主题并处理消息，直到上下文取消
+func (t *NATSTransport) Subscribe(ctx context.Context, subject string, handler Handler) error {
+	ch := make(chan *nats.Msg, 256)
+	sub, err := t.conn.ChanSubscribe(subject, ch)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %v", subject, err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ch:
+			if err := handler(ctx, Message{Subject: msg.Subject, Data: msg.Data}); err != nil {
+				fmt.Printf("Error handling nats message on %s: %v\n", msg.Subject, err)
+			}
+		}
+	}
+}
+
+// Close 关闭连接，等待已发布的消息发送完成
+func (t *NATSTransport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.Drain()
+}
@@ -0,0 +1,121 @@
+package monitoring
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// instrumentedDataSource 为数据源记录请求数、错误数和耗时
+type instrumentedDataSource struct {
+	datasource.DataSource
+	metrics *Metrics
+}
+
+// InstrumentDataSource 包装数据源，使其请求被记录到指标中
+// 包装后的数据源可以直接添加到 datasource.Manager
+func (m *Metrics) InstrumentDataSource(ds datasource.DataSource) datasource.DataSource {
+	return &instrumentedDataSource{DataSource: ds, metrics: m}
+}
+
+// observe 记录一次请求（内部方法）
+func (d *instrumentedDataSource) observe(method string, start time.Time, err error) {
+	d.metrics.observeDataSource(d.Name(), method, time.Since(start).Seconds(), err)
+}
+
+// HealthCheck 检查数据源的健康状态
+func (d *instrumentedDataSource) HealthCheck(ctx context.Context) (bool, error) {
+	start := time.Now()
+	ok, err := d.DataSource.HealthCheck(ctx)
+	d.observe("health_check", start, err)
+	return ok, err
+}
+
+// GetStockData 获取指定股票的价格数据
+func (d *instrumentedDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]datasource.StockData, error) {
+	start := time.Now()
+	data, err := d.DataSource.GetStockData(ctx, symbol, timeframe, from, to)
+	d.observe("get_stock_data", start, err)
+	return data, err
+}
+
+// GetMultipleStockData 批量获取多只股票的价格数据
+func (d *instrumentedDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]datasource.StockData, error) {
+	start := time.Now()
+	data, err := d.DataSource.GetMultipleStockData(ctx, symbols, timeframe, from, to)
+	d.observe("get_multiple_stock_data", start, err)
+	return data, err
+}
+
+// GetRealTimeQuote 获取实时报价
+func (d *instrumentedDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*datasource.Quote, error) {
+	start := time.Now()
+	quote, err := d.DataSource.GetRealTimeQuote(ctx, symbol)
+	d.observe("get_realtime_quote", start, err)
+	return quote, err
+}
+
+// GetRealTimeQuotes 批量获取实时报价
+func (d *instrumentedDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*datasource.Quote, error) {
+	start := time.Now()
+	quotes, err := d.DataSource.GetRealTimeQuotes(ctx, symbols)
+	d.observe("get_realtime_quotes", start, err)
+	return quotes, err
+}
+
+// GetHistoricalQuotes 获取NBBO历史报价
+func (d *instrumentedDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]datasource.Quote, error) {
+	start := time.Now()
+	quotes, err := d.DataSource.GetHistoricalQuotes(ctx, symbol, from, to)
+	d.observe("get_historical_quotes", start, err)
+	return quotes, err
+}
+
+// GetTrades 获取逐笔成交
+func (d *instrumentedDataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]datasource.TradePrint, error) {
+	start := time.Now()
+	trades, err := d.DataSource.GetTrades(ctx, symbol, from, to)
+	d.observe("get_trades", start, err)
+	return trades, err
+}
+
+// GetOptionChain 获取期权链
+func (d *instrumentedDataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]datasource.OptionQuote, error) {
+	start := time.Now()
+	chain, err := d.DataSource.GetOptionChain(ctx, underlying, expiry)
+	d.observe("get_option_chain", start, err)
+	return chain, err
+}
+
+// GetFundamentals 获取基本面数据
+func (d *instrumentedDataSource) GetFundamentals(ctx context.Context, symbol string) (*datasource.Fundamentals, error) {
+	start := time.Now()
+	fundamentals, err := d.DataSource.GetFundamentals(ctx, symbol)
+	d.observe("get_fundamentals", start, err)
+	return fundamentals, err
+}
+
+// GetCorporateActions 获取公司行动
+func (d *instrumentedDataSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*datasource.CorporateActions, error) {
+	start := time.Now()
+	actions, err := d.DataSource.GetCorporateActions(ctx, symbol, from, to)
+	d.observe("get_corporate_actions", start, err)
+	return actions, err
+}
+
+// SubscribeQuotes 订阅实时报价推送，只记录建立订阅的请求
+func (d *instrumentedDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan datasource.Quote, error) {
+	start := time.Now()
+	quotes, err := d.DataSource.SubscribeQuotes(ctx, symbols)
+	d.observe("subscribe_quotes", start, err)
+	return quotes, err
+}
+
+// GetAllStocks 获取所有可交易的股票列表
+func (d *instrumentedDataSource) GetAllStocks(ctx context.Context) ([]datasource.Stock, error) {
+	start := time.Now()
+	stocks, err := d.DataSource.GetAllStocks(ctx)
+	d.observe("get_all_stocks", start, err)
+	return stocks, err
+}
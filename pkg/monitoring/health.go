@@ -0,0 +1,282 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// CheckStatus 表示检查结果状态
+type CheckStatus string
+
+// 检查结果状态常量
+const (
+	StatusOK       CheckStatus = "ok"       // 正常
+	StatusDegraded CheckStatus = "degraded" // 部分可用，不影响探针结果
+	StatusFail     CheckStatus = "fail"     // 失败
+)
+
+// CheckResult 表示单项检查的结果
+type CheckResult struct {
+	Name     string                 `json:"name"`
+	Status   CheckStatus            `json:"status"`
+	Message  string                 `json:"message,omitempty"`
+	Duration time.Duration          `json:"duration_ns"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthReport 表示健康检查汇总报告
+type HealthReport struct {
+	Status    CheckStatus   `json:"status"`
+	Timestamp time.Time     `json:"timestamp"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// CheckFunc 执行单项检查，返回结果中的名称和耗时由HealthChecker填充
+type CheckFunc func(ctx context.Context) CheckResult
+
+// namedCheck 表示已注册的检查项
+type namedCheck struct {
+	name      string
+	check     CheckFunc
+	readiness bool // 仅用于就绪探针
+}
+
+// HealthChecker 汇总各子系统状态，提供 /healthz 和 /readyz 探针
+// 存活检查失败时两个探针都失败；就绪检查只影响 /readyz
+type HealthChecker struct {
+	mu      sync.RWMutex
+	checks  []namedCheck
+	timeout time.Duration
+}
+
+// NewHealthChecker 创建一个新的健康检查器，timeout 为单项检查超时时间
+func NewHealthChecker(timeout time.Duration) *HealthChecker {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &HealthChecker{timeout: timeout}
+}
+
+// AddLivenessCheck 添加存活检查，同时作用于 /healthz 和 /readyz
+func (h *HealthChecker) AddLivenessCheck(name string, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck 添加就绪检查，仅作用于 /readyz
+func (h *HealthChecker) AddReadinessCheck(name string, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedCheck{name: name, check: check, readiness: true})
+}
+
+// Liveness 执行存活检查
+func (h *HealthChecker) Liveness(ctx context.Context) HealthReport {
+	return h.run(ctx, false)
+}
+
+// Readiness 执行存活检查和就绪检查
+func (h *HealthChecker) Readiness(ctx context.Context) HealthReport {
+	return h.run(ctx, true)
+}
+
+// run 并行执行检查并汇总结果（内部方法）
+func (h *HealthChecker) run(ctx context.Context, readiness bool) HealthReport {
+	h.mu.RLock()
+	var checks []namedCheck
+	for _, c := range h.checks {
+		if readiness || !c.readiness {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			result := c.check(checkCtx)
+			result.Name = c.name
+			result.Duration = time.Since(start)
+			if result.Status == "" {
+				result.Status = StatusOK
+			}
+			results[i] = result
+		}(i, c)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	report := HealthReport{
+		Status:    StatusOK,
+		Timestamp: time.Now(),
+		Checks:    results,
+	}
+	for _, r := range results {
+		if r.Status == StatusFail {
+			report.Status = StatusFail
+			break
+		}
+		if r.Status == StatusDegraded {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// LivenessHandler 返回 /healthz 的HTTP处理器
+func (h *HealthChecker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Liveness(r.Context()))
+	})
+}
+
+// ReadinessHandler 返回 /readyz 的HTTP处理器
+func (h *HealthChecker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Readiness(r.Context()))
+	})
+}
+
+// Register 在HTTP多路复用器上注册 /healthz 和 /readyz
+func (h *HealthChecker) Register(mux *http.ServeMux) {
+	mux.Handle("/healthz", h.LivenessHandler())
+	mux.Handle("/readyz", h.ReadinessHandler())
+}
+
+// writeReport 以JSON格式输出报告，失败时返回503
+func writeReport(w http.ResponseWriter, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusFail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// DataSourceCheck 检查所有数据源，全部失败时为失败，部分失败时为降级
+func DataSourceCheck(manager *datasource.Manager) CheckFunc {
+	return func(ctx context.Context) CheckResult {
+		results := manager.HealthCheckAll(ctx)
+		if len(results) == 0 {
+			return CheckResult{Status: StatusFail, Message: "no data sources configured"}
+		}
+
+		details := make(map[string]interface{}, len(results))
+		failed := 0
+		for name, err := range results {
+			if err != nil {
+				failed++
+				details[name] = err.Error()
+			} else {
+				details[name] = string(StatusOK)
+			}
+		}
+
+		switch {
+		case failed == len(results):
+			return CheckResult{Status: StatusFail, Message: "all data sources are unhealthy", Details: details}
+		case failed > 0:
+			return CheckResult{Status: StatusDegraded, Message: fmt.Sprintf("%d of %d data sources are unhealthy", failed, len(results)), Details: details}
+		default:
+			return CheckResult{Status: StatusOK, Details: details}
+		}
+	}
+}
+
+// BrokerCheck 检查与券商的连接
+func BrokerCheck(broker trading.Broker) CheckFunc {
+	return func(ctx context.Context) CheckResult {
+		details := map[string]interface{}{"broker": broker.Name()}
+		if err := broker.HealthCheck(ctx); err != nil {
+			return CheckResult{Status: StatusFail, Message: err.Error(), Details: details}
+		}
+		return CheckResult{Status: StatusOK, Details: details}
+	}
+}
+
+// EngineEnabledCheck 检查交易引擎是否已启用
+func EngineEnabledCheck(engine trading.TradingEngine) CheckFunc {
+	return func(ctx context.Context) CheckResult {
+		if !engine.IsEnabled() {
+			return CheckResult{Status: StatusFail, Message: "trading engine is disabled"}
+		}
+		return CheckResult{Status: StatusOK}
+	}
+}
+
+// ClockSource 返回参考时间，用于检查本机时钟偏差
+type ClockSource func(ctx context.Context) (time.Time, error)
+
+// HTTPDateClock 使用HTTP响应的Date头作为参考时间
+func HTTPDateClock(client *http.Client, url string) ClockSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context) (time.Time, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return time.Time{}, err
+		}
+		resp.Body.Close()
+
+		return http.ParseTime(resp.Header.Get("Date"))
+	}
+}
+
+// ClockSkewCheck 检查本机时钟与参考时间的偏差是否超过 maxSkew
+// 无法获取参考时间时为降级而非失败
+func ClockSkewCheck(source ClockSource, maxSkew time.Duration) CheckFunc {
+	return func(ctx context.Context) CheckResult {
+		before := time.Now()
+		reference, err := source(ctx)
+		if err != nil {
+			return CheckResult{Status: StatusDegraded, Message: fmt.Sprintf("failed to get reference time: %v", err)}
+		}
+
+		// 以请求往返的中点作为本机时间
+		local := before.Add(time.Since(before) / 2)
+		skew := local.Sub(reference)
+		if skew < 0 {
+			skew = -skew
+		}
+
+		details := map[string]interface{}{
+			"skew_ms":     skew.Milliseconds(),
+			"max_skew_ms": maxSkew.Milliseconds(),
+		}
+		if skew > maxSkew {
+			return CheckResult{Status: StatusFail, Message: fmt.Sprintf("clock skew %v exceeds %v", skew, maxSkew), Details: details}
+		}
+		return CheckResult{Status: StatusOK, Details: details}
+	}
+}
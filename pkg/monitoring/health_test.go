@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+func TestReadinessChecks(t *testing.T) {
+	broker := testutil.NewMockBroker("mock")
+	primary := testutil.NewMockDataSource("primary")
+	backup := testutil.NewMockDataSource("backup")
+	manager := datasource.NewManager()
+	manager.AddDataSource(primary)
+	manager.AddDataSource(backup)
+	engine := trading.NewBaseTradingEngine(manager, trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.Enable()
+
+	checker := NewHealthChecker(time.Second)
+	checker.AddLivenessCheck("engine", EngineEnabledCheck(engine))
+	checker.AddReadinessCheck("broker", BrokerCheck(broker))
+	checker.AddReadinessCheck("datasources", DataSourceCheck(manager))
+	mux := http.NewServeMux()
+	checker.Register(mux)
+	probe := func(path string) int {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	if code := probe("/readyz"); code != http.StatusOK {
+		t.Fatalf("全部正常时就绪探针 = %d, 期望 200", code)
+	}
+
+	// 券商连接失败时不再就绪，存活探针不受影响
+	broker.SetHealth(errors.New("session logged out"))
+	report := checker.Readiness(context.Background())
+	if report.Status != StatusFail || report.Checks[0].Name != "broker" || report.Checks[0].Message != "session logged out" {
+		t.Errorf("券商失败时就绪检查应失败: %+v", report)
+	}
+	if code := probe("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("券商失败时就绪探针 = %d, 期望 503", code)
+	}
+	if code := probe("/healthz"); code != http.StatusOK {
+		t.Errorf("券商失败时存活探针 = %d, 期望 200", code)
+	}
+	broker.SetHealth(nil)
+
+	// 部分数据源失败时降级但仍就绪，全部失败时不再就绪
+	primary.FailWith("HealthCheck", errors.New("timeout"))
+	if report := checker.Readiness(context.Background()); report.Status != StatusDegraded || probe("/readyz") != http.StatusOK {
+		t.Errorf("部分数据源失败时应降级: %+v", report)
+	}
+	backup.FailWith("HealthCheck", errors.New("timeout"))
+	if code := probe("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("全部数据源失败时就绪探针 = %d, 期望 503", code)
+	}
+
+	// 引擎停用时两个探针都失败
+	engine.Disable()
+	if code := probe("/healthz"); code != http.StatusServiceUnavailable {
+		t.Errorf("引擎停用时存活探针 = %d, 期望 503", code)
+	}
+}
@@ -0,0 +1,319 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/risk"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// MetricsNamespace 是所有系统指标名称的前缀
+const MetricsNamespace = "qhft"
+
+// Metrics 是系统的Prometheus指标注册表
+// 订单、成交、拒绝、监控列表、扫描和组合风险指标由事件总线驱动，
+// 持仓和盈亏指标在每次抓取时从交易引擎读取
+type Metrics struct {
+	registry *prometheus.Registry
+
+	orders          *prometheus.CounterVec
+	rejections      *prometheus.CounterVec
+	fillLatency     prometheus.Histogram
+	watchlistEvents *prometheus.CounterVec
+	scanSignals     *prometheus.CounterVec
+	scanDuration    *prometheus.HistogramVec
+	scanErrors      *prometheus.CounterVec
+	dsRequests      *prometheus.CounterVec
+	dsErrors        *prometheus.CounterVec
+	dsLatency       *prometheus.HistogramVec
+	riskGauges      *prometheus.GaugeVec
+	riskBreaches    prometheus.Counter
+	accountAlerts   *prometheus.CounterVec
+	droppedEvents   prometheus.Counter
+}
+
+// NewMetrics 创建一个新的指标注册表，并注册Go运行时和进程指标
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		orders: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "orders_total",
+			Help:      "Number of order state events by event type, side and order type.",
+		}, []string{"event", "side", "type"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "order_rejections_total",
+			Help:      "Number of rejected orders by reject code.",
+		}, []string{"code"}),
+		fillLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Name:      "fill_latency_seconds",
+			Help:      "Time from order creation to fill.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5, 30},
+		}),
+		watchlistEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "watchlist_events_total",
+			Help:      "Number of watchlist triggers, expirations and executions.",
+		}, []string{"event", "list"}),
+		scanSignals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "scanner_signals_total",
+			Help:      "Number of scanner signals by indicator and side.",
+		}, []string{"indicator", "side"}),
+		scanDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Name:      "scanner_cycle_duration_seconds",
+			Help:      "Duration of batch scanner cycles.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"strategy"}),
+		scanErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "scanner_symbol_errors_total",
+			Help:      "Number of symbols that failed to scan.",
+		}, []string{"strategy"}),
+		dsRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "datasource_requests_total",
+			Help:      "Number of data source requests by source and method.",
+		}, []string{"source", "method"}),
+		dsErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "datasource_errors_total",
+			Help:      "Number of failed data source requests by source and method.",
+		}, []string{"source", "method"}),
+		dsLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Name:      "datasource_request_duration_seconds",
+			Help:      "Duration of data source requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"source", "method"}),
+		riskGauges: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: MetricsNamespace,
+			Name:      "portfolio_risk",
+			Help:      "Latest portfolio risk metrics (VaR and exposures in account currency, concentration as a fraction of equity).",
+		}, []string{"metric"}),
+		riskBreaches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "risk_limit_breaches_total",
+			Help:      "Number of risk refreshes that found a limit breach.",
+		}),
+		accountAlerts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "account_alerts_total",
+			Help:      "Number of account snapshot alerts by alert type.",
+		}, []string{"type"}),
+		droppedEvents: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Name:      "metrics_dropped_events_total",
+			Help:      "Number of bus events dropped by the metrics subscriber.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.orders,
+		m.rejections,
+		m.fillLatency,
+		m.watchlistEvents,
+		m.scanSignals,
+		m.scanDuration,
+		m.scanErrors,
+		m.dsRequests,
+		m.dsErrors,
+		m.dsLatency,
+		m.riskGauges,
+		m.riskBreaches,
+		m.accountAlerts,
+		m.droppedEvents,
+	)
+
+	return m
+}
+
+// Registry 返回底层的Prometheus注册表，可用于注册自定义指标
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler 返回 /metrics 的HTTP处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// WatchEngine 注册从交易引擎读取持仓和盈亏的指标
+func (m *Metrics) WatchEngine(engine trading.TradingEngine) error {
+	return m.registry.Register(newEngineCollector(engine))
+}
+
+// Run 订阅事件总线并更新指标，直到上下文取消
+func (m *Metrics) Run(ctx context.Context, bus *events.Bus) {
+	sub := bus.Subscribe(1024, events.TopicOrders, events.TopicWatchlist, events.TopicSignals, events.TopicRisk)
+	defer sub.Close()
+
+	var dropped uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			m.ObserveEvent(evt)
+
+			if d := sub.Dropped(); d > dropped {
+				m.droppedEvents.Add(float64(d - dropped))
+				dropped = d
+			}
+		}
+	}
+}
+
+// ObserveEvent 根据单个事件更新指标
+func (m *Metrics) ObserveEvent(evt events.Event) {
+	switch payload := evt.Payload.(type) {
+	case trading.Order:
+		m.orders.WithLabelValues(evt.Type, string(payload.Side), string(payload.Type)).Inc()
+		if evt.Type == trading.EventOrderFilled && payload.FilledAt != nil {
+			m.fillLatency.Observe(payload.FilledAt.Sub(payload.CreatedAt).Seconds())
+		}
+
+	case trading.OrderRejection:
+		m.orders.WithLabelValues(evt.Type, string(payload.Request.Side), string(payload.Request.Type)).Inc()
+		m.rejections.WithLabelValues(string(payload.Code)).Inc()
+
+	case trading.WatchlistItem:
+		list := "sell"
+		if payload.IsBuyList {
+			list = "buy"
+		}
+		m.watchlistEvents.WithLabelValues(evt.Type, list).Inc()
+
+	case indicators.ScanResult:
+		side := "sell"
+		if payload.IsBuySignal {
+			side = "buy"
+		}
+		m.scanSignals.WithLabelValues(payload.IndicatorName, side).Inc()
+
+	case indicators.ScanCycle:
+		m.scanDuration.WithLabelValues(payload.Strategy).Observe(payload.Duration.Seconds())
+		m.scanErrors.WithLabelValues(payload.Strategy).Add(float64(payload.Errors))
+
+	case risk.Snapshot:
+		if evt.Type == risk.EventRiskLimitBreached {
+			m.riskBreaches.Inc()
+			return
+		}
+		m.riskGauges.WithLabelValues("historical_var").Set(payload.HistoricalVaR)
+		m.riskGauges.WithLabelValues("parametric_var").Set(payload.ParametricVaR)
+		m.riskGauges.WithLabelValues("gross_exposure").Set(payload.GrossExposure)
+		m.riskGauges.WithLabelValues("net_exposure").Set(payload.NetExposure)
+		m.riskGauges.WithLabelValues("beta_exposure").Set(payload.BetaExposure)
+		m.riskGauges.WithLabelValues("concentration").Set(payload.Concentration)
+
+	case trading.AccountAlert:
+		m.accountAlerts.WithLabelValues(payload.Type).Inc()
+	}
+}
+
+// observeDataSource 记录一次数据源请求（内部方法）
+func (m *Metrics) observeDataSource(source, method string, seconds float64, err error) {
+	m.dsRequests.WithLabelValues(source, method).Inc()
+	m.dsLatency.WithLabelValues(source, method).Observe(seconds)
+	if err != nil {
+		m.dsErrors.WithLabelValues(source, method).Inc()
+	}
+}
+
+// engineCollector 在抓取时从交易引擎读取持仓和账户指标
+type engineCollector struct {
+	engine trading.TradingEngine
+
+	openPositions  *prometheus.Desc
+	positionPnL    *prometheus.Desc
+	positionValue  *prometheus.Desc
+	unrealizedPnL  *prometheus.Desc
+	realizedPnL    *prometheus.Desc
+	equity         *prometheus.Desc
+	cash           *prometheus.Desc
+	openOrders     *prometheus.Desc
+	tradingEnabled *prometheus.Desc
+}
+
+// newEngineCollector 创建交易引擎指标收集器
+func newEngineCollector(engine trading.TradingEngine) *engineCollector {
+	name := func(n string) string {
+		return prometheus.BuildFQName(MetricsNamespace, "", n)
+	}
+
+	return &engineCollector{
+		engine:         engine,
+		openPositions:  prometheus.NewDesc(name("open_positions"), "Number of open positions.", nil, nil),
+		positionPnL:    prometheus.NewDesc(name("position_unrealized_pnl"), "Unrealized PnL per position.", []string{"symbol"}, nil),
+		positionValue:  prometheus.NewDesc(name("position_market_value"), "Market value per position.", []string{"symbol"}, nil),
+		unrealizedPnL:  prometheus.NewDesc(name("unrealized_pnl"), "Total unrealized PnL.", nil, nil),
+		realizedPnL:    prometheus.NewDesc(name("realized_pnl"), "Total realized PnL.", nil, nil),
+		equity:         prometheus.NewDesc(name("account_equity"), "Account equity.", nil, nil),
+		cash:           prometheus.NewDesc(name("account_cash"), "Account cash.", nil, nil),
+		openOrders:     prometheus.NewDesc(name("open_orders"), "Number of open orders.", nil, nil),
+		tradingEnabled: prometheus.NewDesc(name("trading_enabled"), "Whether the trading engine is enabled.", nil, nil),
+	}
+}
+
+// Describe 实现prometheus.Collector接口
+func (c *engineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openPositions
+	ch <- c.positionPnL
+	ch <- c.positionValue
+	ch <- c.unrealizedPnL
+	ch <- c.realizedPnL
+	ch <- c.equity
+	ch <- c.cash
+	ch <- c.openOrders
+	ch <- c.tradingEnabled
+}
+
+// Collect 实现prometheus.Collector接口
+func (c *engineCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	enabled := 0.0
+	if c.engine.IsEnabled() {
+		enabled = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.tradingEnabled, prometheus.GaugeValue, enabled)
+
+	if positions, err := c.engine.GetPositions(ctx); err == nil {
+		var unrealized float64
+		for _, p := range positions {
+			symbol := strings.ToUpper(p.Symbol)
+			ch <- prometheus.MustNewConstMetric(c.positionPnL, prometheus.GaugeValue, p.UnrealizedPnL, symbol)
+			ch <- prometheus.MustNewConstMetric(c.positionValue, prometheus.GaugeValue, p.MarketValue, symbol)
+			unrealized += p.UnrealizedPnL
+		}
+		ch <- prometheus.MustNewConstMetric(c.openPositions, prometheus.GaugeValue, float64(len(positions)))
+		ch <- prometheus.MustNewConstMetric(c.unrealizedPnL, prometheus.GaugeValue, unrealized)
+	}
+
+	if orders, err := c.engine.GetOpenOrders(ctx); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.openOrders, prometheus.GaugeValue, float64(len(orders)))
+	}
+
+	if account, err := c.engine.GetAccount(ctx); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.realizedPnL, prometheus.GaugeValue, account.RealizedPnL)
+		ch <- prometheus.MustNewConstMetric(c.equity, prometheus.GaugeValue, account.Equity)
+		ch <- prometheus.MustNewConstMetric(c.cash, prometheus.GaugeValue, account.Cash)
+	}
+}
@@ -0,0 +1,101 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// scrape 抓取一次指标，返回按指标名称（含标签）索引的取值（内部函数）
+func scrape(t *testing.T, m *Metrics) map[string]float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != 200 {
+		t.Fatalf("抓取指标失败: %d %s", rec.Code, rec.Body.String())
+	}
+
+	samples := make(map[string]float64)
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			continue
+		}
+		if value, err := strconv.ParseFloat(line[i+1:], 64); err == nil {
+			samples[line[:i]] = value
+		}
+	}
+	return samples
+}
+
+func TestMetricsTrackOrderEvents(t *testing.T) {
+	ctx := context.Background()
+	broker := testutil.NewMockBroker("mock")
+	broker.SetPrice("AAPL", 150)
+	bus := events.NewBus()
+	sub := bus.Subscribe(100, events.TopicOrders)
+	defer sub.Close()
+
+	engine := trading.NewBaseTradingEngine(nil, trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	// 所有指标注册在同一个注册表中，名称不冲突，抓取不报错
+	m := NewMetrics()
+	if err := m.WatchEngine(engine); err != nil {
+		t.Fatalf("注册引擎指标失败: %v", err)
+	}
+	if _, err := m.Registry().Gather(); err != nil {
+		t.Fatalf("收集指标失败: %v", err)
+	}
+	if err := m.WatchEngine(engine); err == nil {
+		t.Error("重复注册引擎指标应返回错误")
+	}
+
+	before := scrape(t, m)
+	if before["qhft_open_positions"] != 0 || before["qhft_trading_enabled"] != 1 {
+		t.Errorf("初始指标不正确: positions=%v enabled=%v", before["qhft_open_positions"], before["qhft_trading_enabled"])
+	}
+
+	// 成交和拒单事件使计数增加，持仓指标在抓取时读取引擎
+	if _, err := engine.PlaceOrder(ctx, trading.OrderRequest{Symbol: "AAPL", Quantity: 10, Type: trading.OrderTypeMarket, Side: trading.OrderSideBuy}); err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	if _, err := engine.PlaceOrder(ctx, trading.OrderRequest{Symbol: "AAPL", Quantity: 0, Type: trading.OrderTypeMarket, Side: trading.OrderSideBuy}); err == nil {
+		t.Fatal("数量为0的订单应被拒绝")
+	}
+	for len(sub.C) > 0 {
+		m.ObserveEvent(<-sub.C)
+	}
+
+	after := scrape(t, m)
+	for _, event := range []string{trading.EventOrderAccepted, trading.EventOrderFilled, trading.EventOrderRejected} {
+		name := fmt.Sprintf(`qhft_orders_total{event=%q,side="buy",type="market"}`, event)
+		if after[name] != 1 {
+			t.Errorf("%s = %v, 期望 1", name, after[name])
+		}
+	}
+	if name := fmt.Sprintf(`qhft_order_rejections_total{code=%q}`, trading.RejectCodeInvalidParams); after[name] != 1 {
+		t.Errorf("%s = %v, 期望 1", name, after[name])
+	}
+	if after["qhft_fill_latency_seconds_count"] != 1 {
+		t.Errorf("成交延迟应记录一次: %v", after["qhft_fill_latency_seconds_count"])
+	}
+	if after["qhft_open_positions"] != 1 || after[`qhft_position_market_value{symbol="AAPL"}`] != 1500 {
+		t.Errorf("持仓指标不正确: positions=%v value=%v", after["qhft_open_positions"], after[`qhft_position_market_value{symbol="AAPL"}`])
+	}
+}
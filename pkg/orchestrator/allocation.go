@@ -0,0 +1,192 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// Allocation 表示分配给单个策略的资金、股票池和风险预算
+type Allocation struct {
+	Strategy           string   `json:"strategy" yaml:"strategy"`                         // 扫描器中的策略名称
+	Capital            float64  `json:"capital" yaml:"capital"`                           // 分配的资金
+	Symbols            []string `json:"symbols" yaml:"symbols"`                           // 股票池
+	MaxPositions       int      `json:"max_positions" yaml:"max_positions"`               // 最大持仓数量，0表示不限制
+	MaxPositionPercent float64  `json:"max_position_percent" yaml:"max_position_percent"` // 单个持仓占分配资金的最大比例，0表示不限制
+	MaxDailyLoss       float64  `json:"max_daily_loss" yaml:"max_daily_loss"`             // 每日最大亏损，达到后停止开仓，0表示不限制
+	MinScore           float64  `json:"min_score" yaml:"min_score"`                       // 下单所需的最低信号得分
+	Priority           int      `json:"priority" yaml:"priority"`                         // 冲突时的优先级，数值越大越优先
+	Timeframe          string   `json:"timeframe" yaml:"timeframe"`                       // 为空时使用扫描器默认周期
+	LookbackDays       int      `json:"lookback_days" yaml:"lookback_days"`               // 扫描使用的历史数据天数
+}
+
+// Validate 校验资金分配
+func (a Allocation) Validate() error {
+	if a.Strategy == "" {
+		return fmt.Errorf("allocation strategy is required")
+	}
+	if a.Capital <= 0 {
+		return fmt.Errorf("allocation %s: capital must be positive", a.Strategy)
+	}
+	if len(a.Symbols) == 0 {
+		return fmt.Errorf("allocation %s: symbols are required", a.Strategy)
+	}
+	if a.MaxPositions < 0 || a.MaxDailyLoss < 0 || a.MinScore < 0 || a.LookbackDays < 0 {
+		return fmt.Errorf("allocation %s: limits must not be negative", a.Strategy)
+	}
+	if a.MaxPositionPercent < 0 || a.MaxPositionPercent > 100 {
+		return fmt.Errorf("allocation %s: max_position_percent must be between 0 and 100", a.Strategy)
+	}
+	return nil
+}
+
+// StrategyPosition 表示策略在共享账户中的虚拟持仓
+type StrategyPosition struct {
+	Symbol     string  `json:"symbol"`
+	Quantity   int64   `json:"quantity"`
+	EntryPrice float64 `json:"entry_price"`
+	Cost       float64 `json:"cost"`
+}
+
+// StrategyState 表示策略资金使用情况的快照
+type StrategyState struct {
+	Allocation  Allocation                  `json:"allocation"`
+	UsedCapital float64                     `json:"used_capital"`
+	Available   float64                     `json:"available"`
+	RealizedPnL float64                     `json:"realized_pnl"` // 当日已实现盈亏
+	Positions   map[string]StrategyPosition `json:"positions"`
+}
+
+// book 记录单个策略的虚拟持仓和资金占用
+type book struct {
+	mu          sync.Mutex
+	allocation  Allocation
+	positions   map[string]StrategyPosition
+	realizedPnL float64
+	day         time.Time
+}
+
+// newBook 创建策略账本
+func newBook(allocation Allocation) *book {
+	return &book{
+		allocation: allocation,
+		positions:  make(map[string]StrategyPosition),
+	}
+}
+
+// resetDay 在日期变化时重置当日盈亏（内部方法，调用方持锁）
+func (b *book) resetDay(now time.Time) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !day.Equal(b.day) {
+		b.day = day
+		b.realizedPnL = 0
+	}
+}
+
+// usedCapital 返回持仓占用的资金（内部方法，调用方持锁）
+func (b *book) usedCapital() float64 {
+	var used float64
+	for _, pos := range b.positions {
+		used += pos.Cost
+	}
+	return used
+}
+
+// position 返回策略在某只股票上的持仓
+func (b *book) position(symbol string) (StrategyPosition, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pos, ok := b.positions[symbol]
+	return pos, ok
+}
+
+// buyBudget 返回策略在某只股票上可用于开仓的资金，以及不能开仓的原因
+func (b *book) buyBudget(symbol string, now time.Time) (float64, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resetDay(now)
+
+	a := b.allocation
+	if a.MaxDailyLoss > 0 && -b.realizedPnL >= a.MaxDailyLoss {
+		return 0, "daily loss budget exhausted"
+	}
+	if _, holding := b.positions[symbol]; !holding && a.MaxPositions > 0 && len(b.positions) >= a.MaxPositions {
+		return 0, "maximum positions reached"
+	}
+
+	available := a.Capital - b.usedCapital()
+	if a.MaxPositionPercent > 0 {
+		limit := a.Capital * a.MaxPositionPercent / 100
+		if pos, ok := b.positions[symbol]; ok {
+			limit -= pos.Cost
+		}
+		if limit < available {
+			available = limit
+		}
+	}
+	if available <= 0 {
+		return 0, "allocation fully used"
+	}
+	return available, ""
+}
+
+// applyFill 根据成交订单更新策略账本
+func (b *book) applyFill(order trading.Order) {
+	if order.FilledQty <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resetDay(time.Now())
+
+	pos := b.positions[order.Symbol]
+	pos.Symbol = order.Symbol
+
+	if order.Side == trading.OrderSideBuy {
+		pos.Quantity += order.FilledQty
+		pos.Cost += float64(order.FilledQty) * order.AvgFillPrice
+		pos.EntryPrice = pos.Cost / float64(pos.Quantity)
+		b.positions[order.Symbol] = pos
+		return
+	}
+
+	if pos.Quantity <= 0 {
+		return
+	}
+	qty := order.FilledQty
+	if qty > pos.Quantity {
+		qty = pos.Quantity
+	}
+	b.realizedPnL += float64(qty) * (order.AvgFillPrice - pos.EntryPrice)
+	pos.Quantity -= qty
+	pos.Cost = float64(pos.Quantity) * pos.EntryPrice
+	if pos.Quantity == 0 {
+		delete(b.positions, order.Symbol)
+	} else {
+		b.positions[order.Symbol] = pos
+	}
+}
+
+// state 返回账本快照
+func (b *book) state() StrategyState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resetDay(time.Now())
+
+	positions := make(map[string]StrategyPosition, len(b.positions))
+	for symbol, pos := range b.positions {
+		positions[symbol] = pos
+	}
+	used := b.usedCapital()
+
+	return StrategyState{
+		Allocation:  b.allocation,
+		UsedCapital: used,
+		Available:   b.allocation.Capital - used,
+		RealizedPnL: b.realizedPnL,
+		Positions:   positions,
+	}
+}
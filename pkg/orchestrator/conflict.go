@@ -0,0 +1,94 @@
+package orchestrator
+
+import (
+	"sort"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// ConflictPolicy 表示多个策略对同一股票给出相反信号时的处理方式
+type ConflictPolicy string
+
+// 冲突处理策略常量
+const (
+	ConflictPriority ConflictPolicy = "priority"  // 优先级最高的策略胜出，同优先级按得分
+	ConflictNetScore ConflictPolicy = "net_score" // 买入得分与卖出得分相抵，保留占优一方
+	ConflictSkip     ConflictPolicy = "skip"      // 存在相反信号时全部放弃
+)
+
+// Intent 表示策略希望执行的交易意图
+type Intent struct {
+	Strategy string            `json:"strategy"`
+	Symbol   string            `json:"symbol"`
+	Side     trading.OrderSide `json:"side"`
+	Score    float64           `json:"score"`
+	Priority int               `json:"priority"`
+}
+
+// resolveConflicts 按股票分组处理冲突，返回保留的意图和被丢弃的意图
+// 同一方向的多个意图不视为冲突，各策略在自己的资金范围内执行
+func resolveConflicts(intents []Intent, policy ConflictPolicy) (kept, dropped []Intent) {
+	bySymbol := make(map[string][]Intent)
+	var symbols []string
+	for _, intent := range intents {
+		if _, ok := bySymbol[intent.Symbol]; !ok {
+			symbols = append(symbols, intent.Symbol)
+		}
+		bySymbol[intent.Symbol] = append(bySymbol[intent.Symbol], intent)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		group := bySymbol[symbol]
+		var buys, sells []Intent
+		var buyScore, sellScore float64
+		for _, intent := range group {
+			if intent.Side == trading.OrderSideBuy {
+				buys = append(buys, intent)
+				buyScore += intent.Score
+			} else {
+				sells = append(sells, intent)
+				sellScore += intent.Score
+			}
+		}
+
+		if len(buys) == 0 || len(sells) == 0 {
+			kept = append(kept, group...)
+			continue
+		}
+
+		switch policy {
+		case ConflictSkip:
+			dropped = append(dropped, group...)
+
+		case ConflictNetScore:
+			switch {
+			case buyScore > sellScore:
+				kept = append(kept, buys...)
+				dropped = append(dropped, sells...)
+			case sellScore > buyScore:
+				kept = append(kept, sells...)
+				dropped = append(dropped, buys...)
+			default:
+				dropped = append(dropped, group...)
+			}
+
+		default:
+			winner := group[0]
+			for _, intent := range group[1:] {
+				if intent.Priority > winner.Priority || (intent.Priority == winner.Priority && intent.Score > winner.Score) {
+					winner = intent
+				}
+			}
+			for _, intent := range group {
+				if intent.Side == winner.Side {
+					kept = append(kept, intent)
+				} else {
+					dropped = append(dropped, intent)
+				}
+			}
+		}
+	}
+
+	return kept, dropped
+}
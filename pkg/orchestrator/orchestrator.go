@@ -0,0 +1,350 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// Config 表示编排器配置
+type Config struct {
+	Interval       time.Duration  // 每轮运行的间隔
+	ConflictPolicy ConflictPolicy // 冲突处理策略
+}
+
+// Decision 表示一轮运行中对单个交易意图的处理结果
+type Decision struct {
+	Intent   Intent         `json:"intent"`
+	Quantity int64          `json:"quantity,omitempty"`
+	Order    *trading.Order `json:"order,omitempty"`
+	Skipped  string         `json:"skipped,omitempty"` // 未下单的原因
+	Err      error          `json:"-"`
+}
+
+// Orchestrator 在同一个交易引擎和账户内并行运行多个策略
+// 每个策略拥有独立的资金分配、股票池和风险预算，编排器负责解决策略间的信号冲突，
+// 并按策略的虚拟账本控制下单数量
+type Orchestrator struct {
+	engine      trading.TradingEngine
+	scanner     *indicators.Scanner
+	dataManager *datasource.Manager
+	config      Config
+
+	mu         sync.RWMutex
+	books      map[string]*book
+	strategies map[string]*codeStrategy // 代码实现的策略
+	pending    map[string]string        // 未成交订单ID到策略名称的映射
+}
+
+// NewOrchestrator 创建一个新的策略编排器
+func NewOrchestrator(engine trading.TradingEngine, scanner *indicators.Scanner, dataManager *datasource.Manager, config Config) *Orchestrator {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.ConflictPolicy == "" {
+		config.ConflictPolicy = ConflictPriority
+	}
+
+	return &Orchestrator{
+		engine:      engine,
+		scanner:     scanner,
+		dataManager: dataManager,
+		config:      config,
+		books:       make(map[string]*book),
+		strategies:  make(map[string]*codeStrategy),
+		pending:     make(map[string]string),
+	}
+}
+
+// AddAllocation 添加一个策略的资金分配
+func (o *Orchestrator) AddAllocation(allocation Allocation) error {
+	if err := allocation.Validate(); err != nil {
+		return err
+	}
+	if _, err := o.scanner.GetStrategy(allocation.Strategy); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, exists := o.books[allocation.Strategy]; exists {
+		return fmt.Errorf("allocation for strategy '%s' already exists", allocation.Strategy)
+	}
+	if _, exists := o.strategies[allocation.Strategy]; exists {
+		return fmt.Errorf("strategy '%s' is a code strategy", allocation.Strategy)
+	}
+	o.books[allocation.Strategy] = newBook(allocation)
+	return nil
+}
+
+// RemoveAllocation 移除策略的资金分配，已有的虚拟持仓不会被平仓
+func (o *Orchestrator) RemoveAllocation(strategy string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, exists := o.books[strategy]; !exists {
+		return fmt.Errorf("allocation for strategy '%s' not found", strategy)
+	}
+	delete(o.books, strategy)
+	return nil
+}
+
+// GetStrategyStates 返回所有策略的资金使用情况
+func (o *Orchestrator) GetStrategyStates() []StrategyState {
+	o.mu.RLock()
+	books := make([]*book, 0, len(o.books))
+	for _, b := range o.books {
+		books = append(books, b)
+	}
+	o.mu.RUnlock()
+
+	states := make([]StrategyState, 0, len(books))
+	for _, b := range books {
+		states = append(states, b.state())
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Allocation.Strategy < states[j].Allocation.Strategy
+	})
+	return states
+}
+
+// Run 按配置的间隔运行编排器，直到上下文取消
+// 事件总线不为空时，异步成交的订单会通过总线更新策略账本，代码策略的OnFill也依赖事件总线
+func (o *Orchestrator) Run(ctx context.Context, bus *events.Bus) error {
+	strategiesDone := make(chan struct{})
+	go func() {
+		defer close(strategiesDone)
+		o.runStrategies(ctx)
+	}()
+	defer func() { <-strategiesDone }()
+
+	if bus != nil {
+		sub := bus.Subscribe(256, events.TopicOrders)
+		defer sub.Close()
+		go func() {
+			for evt := range sub.C {
+				o.HandleEvent(evt)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(o.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := o.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			fmt.Printf("Error running orchestrator: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandleEvent 处理订单事件，用于更新异步成交的订单
+func (o *Orchestrator) HandleEvent(evt events.Event) {
+	order, ok := evt.Payload.(trading.Order)
+	if !ok {
+		return
+	}
+
+	switch evt.Type {
+	case trading.EventOrderFilled:
+		o.handleStrategyFill(order)
+
+		o.mu.Lock()
+		strategy, pending := o.pending[order.ID]
+		delete(o.pending, order.ID)
+		b := o.books[strategy]
+		o.mu.Unlock()
+
+		if pending && b != nil {
+			b.applyFill(order)
+		}
+	case trading.EventOrderCanceled:
+		o.mu.Lock()
+		delete(o.pending, order.ID)
+		o.mu.Unlock()
+	}
+}
+
+// RunOnce 执行一轮编排：并行扫描各策略的股票池，解决冲突后按资金分配下单
+// 代码策略由各自的轮询驱动，不参与RunOnce
+func (o *Orchestrator) RunOnce(ctx context.Context) ([]Decision, error) {
+	o.mu.RLock()
+	books := make([]*book, 0, len(o.books))
+	for _, b := range o.books {
+		books = append(books, b)
+	}
+	o.mu.RUnlock()
+
+	// 并行收集各策略的交易意图
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		intents []Intent
+		errs    []error
+	)
+	for _, b := range books {
+		wg.Add(1)
+		go func(b *book) {
+			defer wg.Done()
+			found, err := o.collectIntents(ctx, b)
+			mu.Lock()
+			defer mu.Unlock()
+			intents = append(intents, found...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}(b)
+	}
+	wg.Wait()
+
+	kept, dropped := resolveConflicts(intents, o.config.ConflictPolicy)
+
+	var decisions []Decision
+	for _, intent := range dropped {
+		decisions = append(decisions, Decision{Intent: intent, Skipped: "conflicting signal from another strategy"})
+	}
+
+	// 先卖后买，释放的资金可以在同一轮中使用
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].Side == trading.OrderSideSell && kept[j].Side == trading.OrderSideBuy
+	})
+	for _, intent := range kept {
+		decisions = append(decisions, o.execute(ctx, intent))
+	}
+
+	return decisions, errors.Join(errs...)
+}
+
+// collectIntents 扫描策略的股票池并生成交易意图（内部方法）
+func (o *Orchestrator) collectIntents(ctx context.Context, b *book) ([]Intent, error) {
+	a := b.allocation
+
+	lookback := a.LookbackDays
+	if lookback <= 0 {
+		lookback = 120
+	}
+	to := time.Now()
+	from := to.AddDate(0, 0, -lookback)
+
+	results, err := o.scanner.ScanMultipleSymbols(ctx, a.Symbols, a.Strategy, from, to, a.Timeframe)
+
+	var intents []Intent
+	for _, symbol := range a.Symbols {
+		symbolResults, ok := results[symbol]
+		if !ok {
+			continue
+		}
+
+		buyScore := o.scanner.CalculateStrategyScore(symbolResults, true)
+		sellScore := o.scanner.CalculateStrategyScore(symbolResults, false)
+
+		switch {
+		case buyScore > sellScore && buyScore >= a.MinScore:
+			intents = append(intents, Intent{Strategy: a.Strategy, Symbol: symbol, Side: trading.OrderSideBuy, Score: buyScore, Priority: a.Priority})
+		case sellScore > buyScore && sellScore >= a.MinScore:
+			// 只有持有该股票的策略才能卖出
+			if _, holding := b.position(symbol); holding {
+				intents = append(intents, Intent{Strategy: a.Strategy, Symbol: symbol, Side: trading.OrderSideSell, Score: sellScore, Priority: a.Priority})
+			}
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("strategy %s: %w", a.Strategy, err)
+	}
+	return intents, err
+}
+
+// execute 按策略账本计算数量并下单（内部方法）
+func (o *Orchestrator) execute(ctx context.Context, intent Intent) Decision {
+	decision := Decision{Intent: intent}
+
+	o.mu.RLock()
+	b := o.books[intent.Strategy]
+	o.mu.RUnlock()
+	if b == nil {
+		decision.Skipped = "allocation removed"
+		return decision
+	}
+
+	if intent.Side == trading.OrderSideSell {
+		pos, ok := b.position(intent.Symbol)
+		if !ok {
+			decision.Skipped = "no position to sell"
+			return decision
+		}
+		decision.Quantity = pos.Quantity
+	} else {
+		budget, reason := b.buyBudget(intent.Symbol, time.Now())
+		if reason != "" {
+			decision.Skipped = reason
+			return decision
+		}
+
+		price, err := o.lastPrice(ctx, intent.Symbol)
+		if err != nil {
+			decision.Err = err
+			decision.Skipped = "no price available"
+			return decision
+		}
+
+		decision.Quantity = int64(math.Floor(budget / price))
+		if decision.Quantity <= 0 {
+			decision.Skipped = "budget below one share"
+			return decision
+		}
+	}
+
+	order, err := o.engine.PlaceOrder(ctx, trading.OrderRequest{
+		Symbol:   intent.Symbol,
+		Quantity: decision.Quantity,
+		Type:     trading.OrderTypeMarket,
+		Side:     intent.Side,
+		Strategy: intent.Strategy,
+		Tags:     []string{"orchestrator"},
+	})
+	if err != nil {
+		decision.Err = err
+		decision.Skipped = "order rejected"
+		return decision
+	}
+	decision.Order = order
+
+	if order.Status == trading.OrderStatusFilled {
+		b.applyFill(*order)
+	} else {
+		o.mu.Lock()
+		o.pending[order.ID] = intent.Strategy
+		o.mu.Unlock()
+	}
+
+	return decision
+}
+
+// lastPrice 获取股票的最新价格（内部方法）
+func (o *Orchestrator) lastPrice(ctx context.Context, symbol string) (float64, error) {
+	quote, err := o.dataManager.GetRealTimeQuote(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	if quote.LastPrice <= 0 {
+		return 0, fmt.Errorf("invalid last price for %s", symbol)
+	}
+	return quote.LastPrice, nil
+}
@@ -0,0 +1,80 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+func TestResolveConflicts(t *testing.T) {
+	intents := []Intent{
+		{Strategy: "momentum", Symbol: "AAPL", Side: trading.OrderSideBuy, Score: 0.6, Priority: 1},
+		{Strategy: "reversion", Symbol: "AAPL", Side: trading.OrderSideSell, Score: 0.9, Priority: 2},
+		{Strategy: "momentum", Symbol: "MSFT", Side: trading.OrderSideBuy, Score: 0.7, Priority: 1},
+		{Strategy: "reversion", Symbol: "MSFT", Side: trading.OrderSideBuy, Score: 0.4, Priority: 2},
+	}
+
+	tests := []struct {
+		policy      ConflictPolicy
+		wantKept    int
+		wantAAPLBuy bool
+	}{
+		{ConflictPriority, 3, false},
+		{ConflictNetScore, 3, false},
+		{ConflictSkip, 2, false},
+	}
+
+	for _, tt := range tests {
+		kept, dropped := resolveConflicts(intents, tt.policy)
+		if len(kept) != tt.wantKept || len(kept)+len(dropped) != len(intents) {
+			t.Errorf("%s: kept=%d dropped=%d, 期望保留 %d", tt.policy, len(kept), len(dropped), tt.wantKept)
+		}
+		for _, intent := range kept {
+			if intent.Symbol == "AAPL" && intent.Side == trading.OrderSideBuy && !tt.wantAAPLBuy {
+				t.Errorf("%s: AAPL买入意图不应被保留", tt.policy)
+			}
+		}
+	}
+
+	// 同方向的意图不视为冲突
+	kept, _ := resolveConflicts(intents[2:], ConflictSkip)
+	if len(kept) != 2 {
+		t.Errorf("同方向意图应全部保留, 得到 %d", len(kept))
+	}
+}
+
+func TestBookBudget(t *testing.T) {
+	b := newBook(Allocation{
+		Strategy:           "momentum",
+		Capital:            10000,
+		Symbols:            []string{"AAPL", "MSFT"},
+		MaxPositions:       1,
+		MaxPositionPercent: 50,
+		MaxDailyLoss:       100,
+	})
+	now := time.Now()
+
+	budget, reason := b.buyBudget("AAPL", now)
+	if reason != "" || budget != 5000 {
+		t.Fatalf("budget=%.2f reason=%q, 期望 5000", budget, reason)
+	}
+
+	b.applyFill(trading.Order{Symbol: "AAPL", Side: trading.OrderSideBuy, FilledQty: 20, AvgFillPrice: 100})
+	if budget, _ := b.buyBudget("AAPL", now); budget != 3000 {
+		t.Errorf("加仓预算=%.2f, 期望 3000", budget)
+	}
+	if _, reason := b.buyBudget("MSFT", now); reason != "maximum positions reached" {
+		t.Errorf("超过最大持仓数时应拒绝开仓, 得到 %q", reason)
+	}
+
+	// 亏损卖出后达到每日亏损上限
+	b.applyFill(trading.Order{Symbol: "AAPL", Side: trading.OrderSideSell, FilledQty: 20, AvgFillPrice: 94})
+	state := b.state()
+	if state.RealizedPnL != -120 || len(state.Positions) != 0 {
+		t.Errorf("RealizedPnL=%.2f positions=%d, 期望 -120 和 0", state.RealizedPnL, len(state.Positions))
+	}
+	if _, reason := b.buyBudget("MSFT", now); reason != "daily loss budget exhausted" {
+		t.Errorf("达到每日亏损上限时应拒绝开仓, 得到 %q", reason)
+	}
+}
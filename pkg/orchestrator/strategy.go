@@ -0,0 +1,409 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// Strategy 是用Go代码实现的策略，用于声明式指标配置难以表达的复杂策略
+// 编排器从数据源管理器获取数据驱动回调，同一策略的回调不会并发执行，策略内部无需加锁
+// 回调返回的错误只记录在策略状态中，不会停止策略
+type Strategy interface {
+	// Name 返回策略名称，订单的Strategy字段使用该名称
+	Name() string
+
+	// OnStart 在策略开始运行时调用一次，返回错误时策略不会运行
+	OnStart(sc *StrategyContext) error
+
+	// OnBar 在股票池中的股票有新走完的K线时调用，按时间顺序逐根推送
+	OnBar(sc *StrategyContext, bar datasource.StockData) error
+
+	// OnQuote 在每轮轮询获取到最新报价时调用
+	OnQuote(sc *StrategyContext, quote datasource.Quote) error
+
+	// OnFill 在策略的订单成交时调用
+	OnFill(sc *StrategyContext, order trading.Order) error
+
+	// OnTimer 在每轮轮询结束时调用
+	OnTimer(sc *StrategyContext, now time.Time) error
+
+	// OnStop 在编排器停止时调用一次
+	OnStop(sc *StrategyContext) error
+}
+
+// BaseStrategy 提供Strategy中各回调的空实现，嵌入后只需实现关心的回调
+type BaseStrategy struct{}
+
+// OnStart 空实现
+func (BaseStrategy) OnStart(sc *StrategyContext) error { return nil }
+
+// OnBar 空实现
+func (BaseStrategy) OnBar(sc *StrategyContext, bar datasource.StockData) error { return nil }
+
+// OnQuote 空实现
+func (BaseStrategy) OnQuote(sc *StrategyContext, quote datasource.Quote) error { return nil }
+
+// OnFill 空实现
+func (BaseStrategy) OnFill(sc *StrategyContext, order trading.Order) error { return nil }
+
+// OnTimer 空实现
+func (BaseStrategy) OnTimer(sc *StrategyContext, now time.Time) error { return nil }
+
+// OnStop 空实现
+func (BaseStrategy) OnStop(sc *StrategyContext) error { return nil }
+
+// StrategyConfig 表示代码策略的运行配置
+type StrategyConfig struct {
+	Symbols    []string      `json:"symbols" yaml:"symbols"`         // 股票池
+	Timeframe  string        `json:"timeframe" yaml:"timeframe"`     // 推送K线的周期，为空时使用minute
+	Interval   time.Duration `json:"interval" yaml:"interval"`       // 轮询间隔，为空时使用编排器的间隔
+	WarmupBars int           `json:"warmup_bars" yaml:"warmup_bars"` // 启动时推送的历史K线数量
+	Quotes     bool          `json:"quotes" yaml:"quotes"`           // 是否轮询报价并调用OnQuote
+}
+
+// StrategyStatus 表示代码策略的运行状态
+type StrategyStatus struct {
+	Name      string         `json:"name"`
+	Config    StrategyConfig `json:"config"`
+	Running   bool           `json:"running"`
+	Bars      int            `json:"bars"`
+	Quotes    int            `json:"quotes"`
+	Fills     int            `json:"fills"`
+	Errors    int            `json:"errors"`
+	LastError string         `json:"last_error,omitempty"`
+}
+
+// StrategyContext 是传给策略回调的上下文，提供数据查询和下单接口
+// 下单时自动填写策略名称，订单通过交易引擎执行
+type StrategyContext struct {
+	context.Context
+
+	name        string
+	engine      trading.TradingEngine
+	dataManager *datasource.Manager
+}
+
+// Name 返回策略名称
+func (sc *StrategyContext) Name() string {
+	return sc.name
+}
+
+// PlaceOrder 通过交易引擎下单，Strategy字段固定为当前策略
+func (sc *StrategyContext) PlaceOrder(req trading.OrderRequest) (*trading.Order, error) {
+	req.Strategy = sc.name
+	req.Tags = append(req.Tags, "strategy")
+	return sc.engine.PlaceOrder(sc, req)
+}
+
+// Buy 以市价买入
+func (sc *StrategyContext) Buy(symbol string, quantity int64) (*trading.Order, error) {
+	return sc.PlaceOrder(trading.OrderRequest{Symbol: symbol, Quantity: quantity, Type: trading.OrderTypeMarket, Side: trading.OrderSideBuy})
+}
+
+// Sell 以市价卖出
+func (sc *StrategyContext) Sell(symbol string, quantity int64) (*trading.Order, error) {
+	return sc.PlaceOrder(trading.OrderRequest{Symbol: symbol, Quantity: quantity, Type: trading.OrderTypeMarket, Side: trading.OrderSideSell})
+}
+
+// CancelOrder 取消订单
+func (sc *StrategyContext) CancelOrder(orderID string) error {
+	return sc.engine.CancelOrder(sc, orderID)
+}
+
+// Position 返回策略在某只股票上的持仓，没有持仓时返回nil
+// 净额模式下返回账户在该股票上的持仓
+func (sc *StrategyContext) Position(symbol string) *trading.Position {
+	pos, err := sc.engine.GetStrategyPosition(sc, symbol, sc.name)
+	if err != nil {
+		return nil
+	}
+	return pos
+}
+
+// Bars 从主数据源获取历史K线
+func (sc *StrategyContext) Bars(symbol, timeframe string, from, to time.Time) ([]datasource.StockData, error) {
+	source, err := sc.dataManager.GetPrimaryDataSource()
+	if err != nil {
+		return nil, err
+	}
+	return source.GetStockData(sc, symbol, timeframe, from, to)
+}
+
+// Quote 获取最新报价，开启报价路由时来自当前最快的数据源
+func (sc *StrategyContext) Quote(symbol string) (*datasource.Quote, error) {
+	return sc.dataManager.GetRealTimeQuote(sc, symbol)
+}
+
+// codeStrategy 记录一个代码策略的运行状态（内部类型）
+type codeStrategy struct {
+	strategy Strategy
+	config   StrategyConfig
+
+	mu      sync.Mutex // 串行化策略回调
+	lastBar map[string]time.Time
+	status  StrategyStatus
+}
+
+// AddStrategy 注册一个代码策略，必须在Run之前调用
+func (o *Orchestrator) AddStrategy(strategy Strategy, config StrategyConfig) error {
+	name := strategy.Name()
+	if name == "" {
+		return fmt.Errorf("strategy name is required")
+	}
+	if config.Interval < 0 {
+		return fmt.Errorf("strategy %s: interval must not be negative", name)
+	}
+	if config.WarmupBars < 0 {
+		return fmt.Errorf("strategy %s: warmup_bars must not be negative", name)
+	}
+	if config.Timeframe == "" {
+		config.Timeframe = "minute"
+	}
+	if config.Interval <= 0 {
+		config.Interval = o.config.Interval
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, exists := o.books[name]; exists {
+		return fmt.Errorf("strategy '%s' already has an allocation", name)
+	}
+	if _, exists := o.strategies[name]; exists {
+		return fmt.Errorf("strategy '%s' already exists", name)
+	}
+	o.strategies[name] = &codeStrategy{
+		strategy: strategy,
+		config:   config,
+		lastBar:  make(map[string]time.Time),
+		status:   StrategyStatus{Name: name, Config: config},
+	}
+	return nil
+}
+
+// GetStrategyStatuses 返回所有代码策略的运行状态
+func (o *Orchestrator) GetStrategyStatuses() []StrategyStatus {
+	o.mu.RLock()
+	strategies := make([]*codeStrategy, 0, len(o.strategies))
+	for _, cs := range o.strategies {
+		strategies = append(strategies, cs)
+	}
+	o.mu.RUnlock()
+
+	statuses := make([]StrategyStatus, 0, len(strategies))
+	for _, cs := range strategies {
+		cs.mu.Lock()
+		statuses = append(statuses, cs.status)
+		cs.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// runStrategies 为每个代码策略启动轮询，阻塞直到上下文取消后所有策略停止（内部方法）
+func (o *Orchestrator) runStrategies(ctx context.Context) {
+	o.mu.RLock()
+	strategies := make([]*codeStrategy, 0, len(o.strategies))
+	for _, cs := range o.strategies {
+		strategies = append(strategies, cs)
+	}
+	o.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, cs := range strategies {
+		wg.Add(1)
+		go func(cs *codeStrategy) {
+			defer wg.Done()
+			o.runStrategy(ctx, cs)
+		}(cs)
+	}
+	wg.Wait()
+}
+
+// runStrategy 运行单个代码策略的生命周期（内部方法）
+func (o *Orchestrator) runStrategy(ctx context.Context, cs *codeStrategy) {
+	if err := o.callStrategy(ctx, cs, cs.strategy.OnStart); err != nil {
+		return
+	}
+	cs.mu.Lock()
+	cs.status.Running = true
+	cs.mu.Unlock()
+
+	ticker := time.NewTicker(cs.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := o.PollStrategy(ctx, cs.strategy.Name()); err != nil && ctx.Err() == nil {
+			cs.mu.Lock()
+			cs.status.LastError = err.Error()
+			cs.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			// 上下文已取消，OnStop使用独立的上下文以便完成清理
+			o.callStrategy(context.Background(), cs, cs.strategy.OnStop)
+			cs.mu.Lock()
+			cs.status.Running = false
+			cs.mu.Unlock()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollStrategy 对代码策略执行一轮轮询：推送新K线和报价，最后调用OnTimer
+func (o *Orchestrator) PollStrategy(ctx context.Context, name string) error {
+	o.mu.RLock()
+	cs, ok := o.strategies[name]
+	o.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("strategy '%s' not found", name)
+	}
+
+	source, err := o.dataManager.GetPrimaryDataSource()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	now := time.Now()
+	for _, symbol := range cs.config.Symbols {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		bars, err := o.newBars(ctx, source, cs, symbol, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+		}
+		for _, bar := range bars {
+			bar := bar
+			o.callStrategy(ctx, cs, func(sc *StrategyContext) error {
+				cs.status.Bars++
+				return cs.strategy.OnBar(sc, bar)
+			})
+		}
+
+		if cs.config.Quotes {
+			quote, err := source.GetRealTimeQuote(ctx, symbol)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+				continue
+			}
+			o.callStrategy(ctx, cs, func(sc *StrategyContext) error {
+				cs.status.Quotes++
+				return cs.strategy.OnQuote(sc, *quote)
+			})
+		}
+	}
+
+	o.callStrategy(ctx, cs, func(sc *StrategyContext) error {
+		return cs.strategy.OnTimer(sc, now)
+	})
+
+	return errors.Join(errs...)
+}
+
+// newBars 获取上次推送之后新走完的K线，首次获取时只保留预热数量的历史K线（内部方法）
+func (o *Orchestrator) newBars(ctx context.Context, source datasource.DataSource, cs *codeStrategy, symbol string, now time.Time) ([]datasource.StockData, error) {
+	cs.mu.Lock()
+	last, seen := cs.lastBar[symbol]
+	cs.mu.Unlock()
+
+	from := last
+	if !seen {
+		from = now.AddDate(0, 0, -warmupDays(cs.config.Timeframe, cs.config.WarmupBars))
+	}
+	data, err := source.GetStockData(ctx, symbol, cs.config.Timeframe, from, now)
+	if err != nil {
+		return nil, err
+	}
+
+	period := datasource.BarDuration(cs.config.Timeframe)
+	bars := make([]datasource.StockData, 0, len(data))
+	for _, bar := range data {
+		if seen && !bar.Timestamp.After(last) {
+			continue
+		}
+		// 尚未走完的K线留到下一轮推送
+		if period > 0 && bar.Timestamp.Add(period).After(now) {
+			continue
+		}
+		bars = append(bars, bar)
+	}
+	if len(bars) == 0 {
+		return nil, nil
+	}
+
+	cs.mu.Lock()
+	cs.lastBar[symbol] = bars[len(bars)-1].Timestamp
+	cs.mu.Unlock()
+
+	if !seen && len(bars) > cs.config.WarmupBars {
+		bars = bars[len(bars)-cs.config.WarmupBars:]
+	}
+	return bars, nil
+}
+
+// handleStrategyFill 将成交订单推送给下单的代码策略（内部方法）
+func (o *Orchestrator) handleStrategyFill(order trading.Order) {
+	o.mu.RLock()
+	cs, ok := o.strategies[order.Strategy]
+	o.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	o.callStrategy(context.Background(), cs, func(sc *StrategyContext) error {
+		cs.status.Fills++
+		return cs.strategy.OnFill(sc, order)
+	})
+}
+
+// callStrategy 持有策略锁调用回调，并记录错误和panic（内部方法）
+func (o *Orchestrator) callStrategy(ctx context.Context, cs *codeStrategy, fn func(sc *StrategyContext) error) (err error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("strategy panicked: %v", r)
+		}
+		if err != nil {
+			cs.status.Errors++
+			cs.status.LastError = err.Error()
+		}
+	}()
+
+	return fn(&StrategyContext{
+		Context:     ctx,
+		name:        cs.strategy.Name(),
+		engine:      o.engine,
+		dataManager: o.dataManager,
+	})
+}
+
+// warmupDays 估算获取预热K线需要回溯的自然日天数（内部函数）
+func warmupDays(timeframe string, bars int) int {
+	switch timeframe {
+	case "minute":
+		// 每个交易日约390根分钟K线，多留几天覆盖周末
+		return bars/390 + 4
+	case "hour":
+		return bars/7 + 4
+	case "week":
+		return bars*7 + 7
+	default:
+		// 日线按每周5个交易日估算
+		return bars*7/5 + 7
+	}
+}
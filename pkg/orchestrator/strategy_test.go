@@ -0,0 +1,157 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// stubSource 返回固定K线和报价的数据源
+type stubSource struct {
+	bars []datasource.StockData
+}
+
+func (s *stubSource) Name() string                                                 { return "stub" }
+func (s *stubSource) IsEnabled() bool                                              { return true }
+func (s *stubSource) HealthCheck(ctx context.Context) (bool, error)                { return true, nil }
+func (s *stubSource) GetAllStocks(ctx context.Context) ([]datasource.Stock, error) { return nil, nil }
+func (s *stubSource) Close() error                                                 { return nil }
+
+func (s *stubSource) GetStockData(ctx context.Context, symbol, timeframe string, from, to time.Time) ([]datasource.StockData, error) {
+	var data []datasource.StockData
+	for _, bar := range s.bars {
+		if !bar.Timestamp.Before(from) && !bar.Timestamp.After(to) {
+			data = append(data, bar)
+		}
+	}
+	return data, nil
+}
+
+func (s *stubSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]datasource.StockData, error) {
+	return nil, nil
+}
+
+func (s *stubSource) GetRealTimeQuote(ctx context.Context, symbol string) (*datasource.Quote, error) {
+	last := s.bars[len(s.bars)-1]
+	return &datasource.Quote{Symbol: symbol, Timestamp: last.Timestamp, LastPrice: last.Close}, nil
+}
+
+func (s *stubSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*datasource.Quote, error) {
+	return datasource.FetchQuotes(ctx, s.GetRealTimeQuote, symbols, 1)
+}
+
+func (s *stubSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]datasource.Quote, error) {
+	return nil, nil
+}
+
+func (s *stubSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]datasource.TradePrint, error) {
+	return nil, nil
+}
+
+func (s *stubSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]datasource.OptionQuote, error) {
+	return nil, nil
+}
+
+func (s *stubSource) GetFundamentals(ctx context.Context, symbol string) (*datasource.Fundamentals, error) {
+	return nil, nil
+}
+
+func (s *stubSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*datasource.CorporateActions, error) {
+	return &datasource.CorporateActions{Symbol: symbol}, nil
+}
+
+func (s *stubSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan datasource.Quote, error) {
+	return datasource.PollQuotes(ctx, s.GetRealTimeQuotes, symbols, time.Second)
+}
+
+// breakoutStrategy 在收盘价创新高时买入
+type breakoutStrategy struct {
+	BaseStrategy
+	high   float64
+	bars   int
+	quotes int
+	timers int
+	orders []*trading.Order
+}
+
+func (s *breakoutStrategy) Name() string { return "breakout" }
+
+func (s *breakoutStrategy) OnBar(sc *StrategyContext, bar datasource.StockData) error {
+	s.bars++
+	if s.high > 0 && bar.Close > s.high {
+		order, err := sc.Buy(bar.Symbol, 10)
+		if err != nil {
+			return err
+		}
+		s.orders = append(s.orders, order)
+	}
+	if bar.Close > s.high {
+		s.high = bar.Close
+	}
+	return nil
+}
+
+func (s *breakoutStrategy) OnQuote(sc *StrategyContext, quote datasource.Quote) error {
+	s.quotes++
+	return nil
+}
+
+func (s *breakoutStrategy) OnTimer(sc *StrategyContext, now time.Time) error {
+	s.timers++
+	return nil
+}
+
+func TestPollStrategy(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	source := &stubSource{}
+	for i, price := range []float64{100, 101, 99, 103} {
+		ts := now.Add(time.Duration(i-5) * time.Minute)
+		source.bars = append(source.bars, datasource.StockData{Symbol: "AAPL", Timestamp: ts, Close: price})
+	}
+
+	manager := datasource.NewManager()
+	if err := manager.AddDataSource(source); err != nil {
+		t.Fatalf("添加数据源失败: %v", err)
+	}
+	engine := trading.NewBaseTradingEngine(manager, trading.BrokerConfig{IsPaperTrading: true}, trading.TradingLimits{MaxPositions: 10, MaxDailyTrades: 10})
+	if err := engine.Enable(); err != nil {
+		t.Fatalf("启用引擎失败: %v", err)
+	}
+
+	o := NewOrchestrator(engine, nil, manager, Config{})
+	strategy := &breakoutStrategy{}
+	if err := o.AddStrategy(strategy, StrategyConfig{Symbols: []string{"AAPL"}, WarmupBars: 3, Quotes: true}); err != nil {
+		t.Fatalf("注册策略失败: %v", err)
+	}
+	if err := o.AddStrategy(&breakoutStrategy{}, StrategyConfig{}); err == nil {
+		t.Error("重复的策略名称应返回错误")
+	}
+
+	if err := o.PollStrategy(context.Background(), "breakout"); err != nil {
+		t.Fatalf("轮询策略失败: %v", err)
+	}
+
+	// 预热只推送最近3根K线：101、99、103，最后一根创新高后买入
+	if strategy.bars != 3 || strategy.quotes != 1 || strategy.timers != 1 {
+		t.Errorf("bars=%d quotes=%d timers=%d, 期望 3 1 1", strategy.bars, strategy.quotes, strategy.timers)
+	}
+	if len(strategy.orders) != 1 || strategy.orders[0].Strategy != "breakout" {
+		t.Fatalf("应以策略名称下单一次: %+v", strategy.orders)
+	}
+
+	// 没有新K线时不再推送
+	if err := o.PollStrategy(context.Background(), "breakout"); err != nil {
+		t.Fatalf("轮询策略失败: %v", err)
+	}
+	if strategy.bars != 3 || strategy.timers != 2 {
+		t.Errorf("bars=%d timers=%d, 期望 3 2", strategy.bars, strategy.timers)
+	}
+
+	statuses := o.GetStrategyStatuses()
+	if len(statuses) != 1 || statuses[0].Bars != 3 || statuses[0].Errors != 0 {
+		t.Errorf("策略状态不正确: %+v", statuses)
+	}
+}
@@ -0,0 +1,171 @@
+// Package report 生成面向投资人和合伙人的账户对账单：
+// 由交易记录和账户权益历史汇总月末持仓、已实现交易、费用和业绩，输出为PDF
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// MonthlyInput 表示生成月度对账单所需的数据
+type MonthlyInput struct {
+	Account   string                   // 对账单上显示的账户名称
+	Month     time.Time                // 对账月份内的任意时间
+	Location  *time.Location           // 划分月份和显示时间使用的时区，为空时使用 Month 的时区
+	Positions []trading.Position       // 月末持仓，通常在月末最后一个交易日收盘后取自引擎
+	Trades    []trading.Trade          // 交易记录，只统计当月平仓的交易
+	Equity    []trading.EquitySnapshot // 账户权益快照，只使用当月的快照
+	Ledger    []trading.LedgerEntry    // 现金账簿分录，只统计当月的股息和利息
+}
+
+// Performance 表示对账月份的业绩
+type Performance struct {
+	StartEquity    float64 `json:"start_equity"`     // 当月第一个权益快照
+	EndEquity      float64 `json:"end_equity"`       // 当月最后一个权益快照
+	Change         float64 `json:"change"`           // 权益变化
+	ReturnPercent  float64 `json:"return_percent"`   // 当月收益率
+	MaxDrawdown    float64 `json:"max_drawdown"`     // 当月权益从高点的最大回撤（百分比）
+	RealizedPnL    float64 `json:"realized_pnl"`     // 当月平仓交易的已实现盈亏
+	Fees           float64 `json:"fees"`             // 当月平仓交易的佣金
+	Dividends      float64 `json:"dividends"`        // 当月收到的股息，空头支付的股息为负
+	Interest       float64 `json:"interest"`         // 当月的现金利息，融资利息为负
+	UnrealizedPnL  float64 `json:"unrealized_pnl"`   // 月末持仓的浮动盈亏
+	Trades         int     `json:"trades"`           // 当月平仓的交易数
+	WinningTrades  int     `json:"winning_trades"`   // 盈利的交易数
+	WinRate        float64 `json:"win_rate"`         // 胜率（百分比）
+	TradingDays    int     `json:"trading_days"`     // 有权益快照的天数
+	AverageDailyPL float64 `json:"average_daily_pl"` // 平均每日权益变化
+}
+
+// DailyEquity 表示某一天收盘时的账户权益
+type DailyEquity struct {
+	Date   time.Time `json:"date"`
+	Equity float64   `json:"equity"`
+	Change float64   `json:"change"` // 相对前一天的变化，第一天相对月初权益
+}
+
+// Monthly 表示一份月度对账单
+type Monthly struct {
+	Account     string             `json:"account"`
+	Start       time.Time          `json:"start"` // 对账月份第一天零点
+	End         time.Time          `json:"end"`   // 下个月第一天零点
+	GeneratedAt time.Time          `json:"generated_at"`
+	Positions   []trading.Position `json:"positions"` // 按股票代码排序
+	Trades      []trading.Trade    `json:"trades"`    // 按平仓时间排序
+	Daily       []DailyEquity      `json:"daily"`
+	Performance Performance        `json:"performance"`
+}
+
+// BuildMonthly 汇总月度对账单，generatedAt 为对账单上显示的生成时间
+func BuildMonthly(input MonthlyInput, generatedAt time.Time) *Monthly {
+	loc := input.Location
+	if loc == nil {
+		loc = input.Month.Location()
+	}
+	month := input.Month.In(loc)
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 1, 0)
+
+	statement := &Monthly{Account: input.Account, Start: start, End: end, GeneratedAt: generatedAt}
+	perf := &statement.Performance
+
+	for _, position := range input.Positions {
+		if position.Quantity == 0 {
+			continue
+		}
+		statement.Positions = append(statement.Positions, position)
+		perf.UnrealizedPnL += position.UnrealizedPnL
+	}
+	sort.Slice(statement.Positions, func(i, j int) bool {
+		return statement.Positions[i].Symbol < statement.Positions[j].Symbol
+	})
+
+	for _, trade := range input.Trades {
+		if trade.ClosedAt == nil || trade.ClosedAt.Before(start) || !trade.ClosedAt.Before(end) {
+			continue
+		}
+		statement.Trades = append(statement.Trades, trade)
+		perf.RealizedPnL += trade.RealizedPnL
+		perf.Fees += trade.Commission
+		if trade.RealizedPnL > 0 {
+			perf.WinningTrades++
+		}
+	}
+	sort.SliceStable(statement.Trades, func(i, j int) bool {
+		return statement.Trades[i].ClosedAt.Before(*statement.Trades[j].ClosedAt)
+	})
+	perf.Trades = len(statement.Trades)
+	if perf.Trades > 0 {
+		perf.WinRate = float64(perf.WinningTrades) / float64(perf.Trades) * 100
+	}
+
+	for _, entry := range input.Ledger {
+		if entry.Timestamp.Before(start) || !entry.Timestamp.Before(end) {
+			continue
+		}
+		switch entry.Kind {
+		case trading.LedgerKindDividend:
+			perf.Dividends += entry.CashDelta()
+		case trading.LedgerKindInterest:
+			perf.Interest += entry.CashDelta()
+		}
+	}
+
+	statement.Daily = dailyEquity(input.Equity, start, end, perf)
+	return statement
+}
+
+// dailyEquity 取每天最后一个权益快照作为当天收盘权益，并计算权益变化、收益率和最大回撤（内部函数）
+func dailyEquity(snapshots []trading.EquitySnapshot, start, end time.Time, perf *Performance) []DailyEquity {
+	var daily []DailyEquity
+	peak := 0.0
+	for _, snapshot := range snapshots {
+		if snapshot.Timestamp.Before(start) || !snapshot.Timestamp.Before(end) {
+			continue
+		}
+		if perf.StartEquity == 0 {
+			perf.StartEquity = snapshot.Equity
+		}
+		perf.EndEquity = snapshot.Equity
+
+		if snapshot.Equity > peak {
+			peak = snapshot.Equity
+		}
+		if peak > 0 {
+			if drawdown := (peak - snapshot.Equity) / peak * 100; drawdown > perf.MaxDrawdown {
+				perf.MaxDrawdown = drawdown
+			}
+		}
+
+		local := snapshot.Timestamp.In(start.Location())
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, start.Location())
+		if n := len(daily); n > 0 && daily[n-1].Date.Equal(date) {
+			daily[n-1].Equity = snapshot.Equity
+			continue
+		}
+		daily = append(daily, DailyEquity{Date: date, Equity: snapshot.Equity})
+	}
+
+	previous := perf.StartEquity
+	for i := range daily {
+		daily[i].Change = daily[i].Equity - previous
+		previous = daily[i].Equity
+	}
+
+	perf.Change = perf.EndEquity - perf.StartEquity
+	if perf.StartEquity > 0 {
+		perf.ReturnPercent = perf.Change / perf.StartEquity * 100
+	}
+	perf.TradingDays = len(daily)
+	if perf.TradingDays > 0 {
+		perf.AverageDailyPL = perf.Change / float64(perf.TradingDays)
+	}
+	return daily
+}
+
+// FileName 返回月度对账单的文件名，如 statement-2024-03.pdf
+func FileName(month time.Time) string {
+	return "statement-" + month.Format("2006-01") + ".pdf"
+}
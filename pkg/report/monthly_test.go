@@ -0,0 +1,126 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// closedAt 返回指向时间的指针
+func closedAt(t time.Time) *time.Time {
+	return &t
+}
+
+func TestBuildMonthly(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+	march := time.Date(2024, 3, 15, 0, 0, 0, 0, ny)
+	trades := []trading.Trade{
+		{ID: "t1", Symbol: "MSFT", Quantity: 5, EntryPrice: 400, ExitPrice: 390, RealizedPnL: -50, Commission: 1, ClosedAt: closedAt(time.Date(2024, 3, 20, 15, 0, 0, 0, ny))},
+		{ID: "t2", Symbol: "AAPL", Quantity: 10, EntryPrice: 100, ExitPrice: 110, RealizedPnL: 100, Commission: 2, ClosedAt: closedAt(time.Date(2024, 3, 4, 10, 0, 0, 0, ny))},
+		// 2月平仓和4月1日零点平仓的交易不计入3月
+		{ID: "t3", Symbol: "TSLA", Quantity: 1, RealizedPnL: 10, ClosedAt: closedAt(time.Date(2024, 2, 29, 15, 0, 0, 0, ny))},
+		{ID: "t4", Symbol: "TSLA", Quantity: 1, RealizedPnL: 10, ClosedAt: closedAt(time.Date(2024, 4, 1, 0, 0, 0, 0, ny))},
+	}
+	equity := []trading.EquitySnapshot{
+		{Timestamp: time.Date(2024, 2, 29, 16, 0, 0, 0, ny), Equity: 9000},
+		{Timestamp: time.Date(2024, 3, 1, 10, 0, 0, 0, ny), Equity: 10000},
+		{Timestamp: time.Date(2024, 3, 1, 16, 0, 0, 0, ny), Equity: 10200},
+		{Timestamp: time.Date(2024, 3, 4, 16, 0, 0, 0, ny), Equity: 9690},
+		{Timestamp: time.Date(2024, 3, 29, 16, 0, 0, 0, ny), Equity: 10500},
+	}
+	positions := []trading.Position{
+		{Symbol: "NVDA", Quantity: 3, EntryPrice: 800, CurrentPrice: 900, MarketValue: 2700, UnrealizedPnL: 300},
+		{Symbol: "AMD", Quantity: 10, EntryPrice: 180, CurrentPrice: 170, MarketValue: 1700, UnrealizedPnL: -100},
+		{Symbol: "IBM", Quantity: 0},
+	}
+
+	ledger := []trading.LedgerEntry{
+		{Timestamp: time.Date(2024, 3, 8, 9, 0, 0, 0, ny), Kind: trading.LedgerKindDividend, Debit: trading.LedgerAccountCash, Credit: trading.LedgerAccountDividends, Amount: 24},
+		{Timestamp: time.Date(2024, 3, 12, 9, 0, 0, 0, ny), Kind: trading.LedgerKindDividend, Debit: trading.LedgerAccountDividends, Credit: trading.LedgerAccountCash, Amount: 4},
+		{Timestamp: time.Date(2024, 3, 29, 9, 0, 0, 0, ny), Kind: trading.LedgerKindInterest, Debit: trading.LedgerAccountInterest, Credit: trading.LedgerAccountCash, Amount: 7.5},
+		// 上月的利息和成交分录不计入
+		{Timestamp: time.Date(2024, 2, 29, 9, 0, 0, 0, ny), Kind: trading.LedgerKindInterest, Debit: trading.LedgerAccountCash, Credit: trading.LedgerAccountInterest, Amount: 3},
+		{Timestamp: time.Date(2024, 3, 4, 10, 0, 0, 0, ny), Kind: trading.LedgerKindFill, Debit: trading.LedgerAccountCash, Credit: trading.LedgerAccountSecurities, Amount: 1100},
+	}
+
+	statement := BuildMonthly(MonthlyInput{Account: "Fund I", Month: march, Location: ny, Positions: positions, Trades: trades, Equity: equity, Ledger: ledger}, march)
+	if !statement.Start.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, ny)) || !statement.End.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, ny)) {
+		t.Errorf("对账期间不正确: %v - %v", statement.Start, statement.End)
+	}
+	if len(statement.Trades) != 2 || statement.Trades[0].ID != "t2" || statement.Trades[1].ID != "t1" {
+		t.Errorf("应只包含当月平仓的交易并按平仓时间排序: %+v", statement.Trades)
+	}
+	if len(statement.Positions) != 2 || statement.Positions[0].Symbol != "AMD" {
+		t.Errorf("月末持仓应排除空仓并按代码排序: %+v", statement.Positions)
+	}
+
+	p := statement.Performance
+	expected := Performance{
+		StartEquity: 10000, EndEquity: 10500, Change: 500, ReturnPercent: 5, MaxDrawdown: 5,
+		RealizedPnL: 50, Fees: 3, Dividends: 20, Interest: -7.5, UnrealizedPnL: 200, Trades: 2, WinningTrades: 1, WinRate: 50,
+		TradingDays: 3, AverageDailyPL: 500.0 / 3,
+	}
+	if p != expected {
+		t.Errorf("业绩不正确:\n实际 %+v\n期望 %+v", p, expected)
+	}
+	if len(statement.Daily) != 3 || statement.Daily[0].Equity != 10200 || statement.Daily[0].Change != 200 || statement.Daily[1].Change != -510 {
+		t.Errorf("每日权益应取每天最后一个快照: %+v", statement.Daily)
+	}
+	if FileName(statement.Start) != "statement-2024-03.pdf" {
+		t.Errorf("文件名不正确: %s", FileName(statement.Start))
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	var trades []trading.Trade
+	for i := 0; i < 120; i++ {
+		trades = append(trades, trading.Trade{Symbol: fmt.Sprintf("S%03d", i), Quantity: 10, EntryPrice: 10, ExitPrice: 11, RealizedPnL: 10,
+			ClosedAt: closedAt(start.Add(time.Duration(i) * time.Hour)), Strategy: "动量"})
+	}
+	statement := BuildMonthly(MonthlyInput{Account: "Fund (I)", Month: start, Trades: trades}, start)
+
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, statement); err != nil {
+		t.Fatalf("生成PDF失败: %v", err)
+	}
+	data := buf.String()
+	if !strings.HasPrefix(data, "%PDF-1.4\n") || !strings.HasSuffix(data, "%%EOF\n") {
+		t.Fatal("PDF文件头或文件尾不正确")
+	}
+	for _, text := range []string{"(Monthly Account Statement)", `(Account: Fund \(I\))`, "S119", "(No open positions.)", "(Page 1 of 3)"} {
+		if !strings.Contains(data, text) {
+			t.Errorf("PDF应包含 %s", text)
+		}
+	}
+	if strings.Contains(data, "动量") {
+		t.Error("非ASCII字符应被替换")
+	}
+	// 分页后重复表头
+	if n := strings.Count(data, "(Closed     Symbol"); n != 3 {
+		t.Errorf("交易表的表头应在分页后重复: %d", n)
+	}
+
+	// 交叉引用表中的偏移量指向对应的对象
+	xref := strings.LastIndex(data, "\nxref\n") + 1
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(data)
+	if startxref == nil || startxref[1] != strconv.Itoa(xref) {
+		t.Fatalf("startxref 不正确: %v", startxref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(data[xref:], -1)
+	if len(entries) != 5+2*3 {
+		t.Fatalf("对象数不正确: %d", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if !strings.HasPrefix(data[offset:], fmt.Sprintf("%d 0 obj\n", i+1)) {
+			t.Errorf("对象%d的偏移量不正确", i+1)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PDF页面尺寸和版式（单位为点，US Letter）
+const (
+	pageWidth    = 612.0
+	pageHeight   = 792.0
+	marginLeft   = 50.0
+	marginTop    = 60.0
+	marginBottom = 60.0
+)
+
+// PDF使用的标准字体，阅读器内置，不需要嵌入字体文件
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+	fontMono    = "F3" // Courier，用于按列对齐的表格
+)
+
+// pdfDocument 是只支持文本和直线的最小PDF生成器，内容按从上到下的顺序排版，
+// 超出页面底部时自动分页（内部类型）
+type pdfDocument struct {
+	pages  []*bytes.Buffer
+	y      float64  // 当前行的基线位置
+	header []string // 分页后在新页面顶部重复的表头
+}
+
+// newPDFDocument 创建只有一个空白页面的文档（内部函数）
+func newPDFDocument() *pdfDocument {
+	d := &pdfDocument{}
+	d.newPage()
+	return d
+}
+
+// newPage 开始新页面，有表头时先写入表头（内部方法）
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - marginTop
+	for _, line := range d.header {
+		d.text(fontMono, 8, line)
+	}
+	if len(d.header) > 0 {
+		d.rule()
+	}
+}
+
+// page 返回当前页面的内容流（内部方法）
+func (d *pdfDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// ensure 剩余空间不足height时分页（内部方法）
+func (d *pdfDocument) ensure(height float64) {
+	if d.y-height < marginBottom {
+		d.newPage()
+	}
+}
+
+// text 在当前位置写入一行文本并移到下一行（内部方法）
+func (d *pdfDocument) text(font string, size float64, s string) {
+	d.ensure(size * 1.4)
+	d.textAt(font, size, marginLeft, d.y, s)
+	d.y -= size * 1.4
+}
+
+// textAt 在指定位置写入文本，不移动当前位置（内部方法）
+func (d *pdfDocument) textAt(font string, size, x, y float64, s string) {
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// rule 在当前位置画一条水平线（内部方法）
+func (d *pdfDocument) rule() {
+	y := d.y + 4
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", marginLeft, y, pageWidth-marginLeft, y)
+	d.y -= 6
+}
+
+// space 留出空白（内部方法）
+func (d *pdfDocument) space(height float64) {
+	d.y -= height
+}
+
+// table 写入等宽字体的表格，分页时在新页面重复表头（内部方法）
+func (d *pdfDocument) table(header string, rows []string) {
+	d.ensure(40)
+	d.header = []string{header}
+	d.text(fontMono, 8, header)
+	d.rule()
+	for _, row := range rows {
+		d.text(fontMono, 8, row)
+	}
+	d.header = nil
+}
+
+// WriteTo 输出PDF文件，每页底部写入页码（内部方法）
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	// 对象1到5：目录、页面树和三种字体；之后每页依次为页面对象和内容流
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 6+2*i))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, font := range []string{"Helvetica", "Helvetica-Bold", "Courier"} {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font))
+	}
+	for i, page := range d.pages {
+		content := page.String() + fmt.Sprintf("BT /%s 8.0 Tf %.2f %.2f Td (Page %d of %d) Tj ET\n",
+			fontRegular, pageWidth-marginLeft-60, marginBottom/2, i+1, len(d.pages))
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 7+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.WriteTo(w)
+}
+
+// pdfEscape 转义PDF字符串中的特殊字符，标准字体无法显示的非ASCII字符替换为问号（内部函数）
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WritePDF 将月度对账单输出为PDF：账户概要、业绩、月末持仓、当月平仓交易和每日权益。
+// 使用PDF内置的标准字体，账户名称和策略等字段中的非ASCII字符显示为问号
+func WritePDF(w io.Writer, statement *Monthly) error {
+	d := newPDFDocument()
+	loc := statement.Start.Location()
+
+	d.text(fontBold, 16, "Monthly Account Statement")
+	d.text(fontRegular, 10, "Period: "+statement.Start.Format("January 2006")+
+		" ("+statement.Start.Format("2006-01-02")+" to "+statement.End.AddDate(0, 0, -1).Format("2006-01-02")+")")
+	if statement.Account != "" {
+		d.text(fontRegular, 10, "Account: "+statement.Account)
+	}
+	d.text(fontRegular, 10, "Generated: "+statement.GeneratedAt.In(loc).Format("2006-01-02 15:04 MST"))
+	d.space(10)
+
+	p := statement.Performance
+	d.text(fontBold, 12, "Performance")
+	d.table(fmt.Sprintf("%-28s %18s", "", "Amount"), []string{
+		summaryRow("Starting equity", money(p.StartEquity)),
+		summaryRow("Ending equity", money(p.EndEquity)),
+		summaryRow("Change in equity", money(p.Change)),
+		summaryRow("Return", percent(p.ReturnPercent)),
+		summaryRow("Max drawdown", percent(p.MaxDrawdown)),
+		summaryRow("Realized P&L", money(p.RealizedPnL)),
+		summaryRow("Fees and commissions", money(-p.Fees)),
+		summaryRow("Dividends", money(p.Dividends)),
+		summaryRow("Interest", money(p.Interest)),
+		summaryRow("Unrealized P&L (month end)", money(p.UnrealizedPnL)),
+		summaryRow("Closed trades", strconv.Itoa(p.Trades)),
+		summaryRow("Win rate", percent(p.WinRate)),
+		summaryRow("Average daily P&L", money(p.AverageDailyPL)),
+	})
+	d.space(10)
+
+	d.text(fontBold, 12, "Positions at Month End")
+	if len(statement.Positions) == 0 {
+		d.text(fontRegular, 10, "No open positions.")
+	} else {
+		var rows []string
+		for _, position := range statement.Positions {
+			rows = append(rows, fmt.Sprintf("%-8s %9d %11s %11s %14s %14s %8s",
+				clip(position.Symbol, 8), position.Quantity, price(position.EntryPrice), price(position.CurrentPrice),
+				money(position.MarketValue), money(position.UnrealizedPnL), percent(position.PnLPercent)))
+		}
+		d.table(fmt.Sprintf("%-8s %9s %11s %11s %14s %14s %8s",
+			"Symbol", "Quantity", "Avg Cost", "Price", "Market Value", "Unrealized", "P&L %"), rows)
+	}
+	d.space(10)
+
+	d.text(fontBold, 12, "Realized Trades")
+	if len(statement.Trades) == 0 {
+		d.text(fontRegular, 10, "No trades were closed this month.")
+	} else {
+		var rows []string
+		for _, trade := range statement.Trades {
+			rows = append(rows, fmt.Sprintf("%-10s %-8s %7d %10s %10s %9s %12s %8s %-10s",
+				trade.ClosedAt.In(loc).Format("2006-01-02"), clip(trade.Symbol, 8), trade.Quantity,
+				price(trade.EntryPrice), price(trade.ExitPrice), money(trade.Commission), money(trade.RealizedPnL),
+				percent(trade.RealizedPnLPercent), clip(trade.Strategy, 10)))
+		}
+		d.table(fmt.Sprintf("%-10s %-8s %7s %10s %10s %9s %12s %8s %-10s",
+			"Closed", "Symbol", "Qty", "Entry", "Exit", "Fees", "P&L", "P&L %", "Strategy"), rows)
+	}
+	d.space(10)
+
+	d.text(fontBold, 12, "Daily Equity")
+	if len(statement.Daily) == 0 {
+		d.text(fontRegular, 10, "No equity history was recorded this month.")
+	} else {
+		var rows []string
+		for _, day := range statement.Daily {
+			rows = append(rows, fmt.Sprintf("%-12s %16s %14s", day.Date.Format("2006-01-02"), money(day.Equity), money(day.Change)))
+		}
+		d.table(fmt.Sprintf("%-12s %16s %14s", "Date", "Equity", "Change"), rows)
+	}
+
+	_, err := d.WriteTo(w)
+	return err
+}
+
+// summaryRow 格式化业绩表的一行（内部函数）
+func summaryRow(label, value string) string {
+	return fmt.Sprintf("%-28s %18s", label, value)
+}
+
+// money 格式化金额，带千位分隔符和两位小数（内部函数）
+func money(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if sign == "-" && whole == "0" && strings.Trim(frac, "0") == "" {
+		sign = ""
+	}
+	return sign + b.String() + "." + frac
+}
+
+// price 格式化价格（内部函数）
+func price(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// percent 格式化百分比（内部函数）
+func percent(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64) + "%"
+}
+
+// clip 截断过长的文本，避免破坏表格的列对齐（内部函数）
+func clip(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,346 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// EventAllocationUpdated 表示策略资金分配已调整
+const EventAllocationUpdated = "allocation_updated"
+
+// AllocationPolicy 表示按策略近期业绩调整资金分配的规则，权重均为占总资金的百分比
+type AllocationPolicy struct {
+	Strategies         []string `json:"strategies" yaml:"strategies"`                     // 参与分配的策略，为空时使用引擎中已配置策略限制的所有策略
+	Capital            float64  `json:"capital" yaml:"capital"`                           // 分配的总资金，为0时使用账户权益
+	LookbackDays       int      `json:"lookback_days" yaml:"lookback_days"`               // 统计业绩的自然日数
+	MinTradingDays     int      `json:"min_trading_days" yaml:"min_trading_days"`         // 有平仓交易的天数少于该值时使用其他策略的平均得分
+	MinWeightPercent   float64  `json:"min_weight_percent" yaml:"min_weight_percent"`     // 单个策略的最低权重
+	MaxWeightPercent   float64  `json:"max_weight_percent" yaml:"max_weight_percent"`     // 单个策略的最高权重，为0时不限制
+	MaxDrawdownPercent float64  `json:"max_drawdown_percent" yaml:"max_drawdown_percent"` // 回撤达到分配资金的该比例时得分降为0，为0时不按回撤扣减
+	DailyLossPercent   float64  `json:"daily_loss_percent" yaml:"daily_loss_percent"`     // 按分配资金的比例设置每日亏损上限，为0时不修改
+}
+
+// Validate 检查分配规则是否有效
+func (p AllocationPolicy) Validate() error {
+	if p.Capital < 0 || p.LookbackDays < 0 || p.MinTradingDays < 0 || p.MaxDrawdownPercent < 0 || p.DailyLossPercent < 0 {
+		return fmt.Errorf("allocation policy values must not be negative")
+	}
+	if p.MinWeightPercent < 0 || p.MinWeightPercent > 100 || p.MaxWeightPercent < 0 || p.MaxWeightPercent > 100 {
+		return fmt.Errorf("allocation weights must be between 0 and 100")
+	}
+	if p.MaxWeightPercent > 0 && p.MinWeightPercent > p.MaxWeightPercent {
+		return fmt.Errorf("allocation min_weight_percent %v exceeds max_weight_percent %v", p.MinWeightPercent, p.MaxWeightPercent)
+	}
+	if n := len(p.Strategies); n > 0 {
+		if p.MinWeightPercent*float64(n) > 100 {
+			return fmt.Errorf("allocation min_weight_percent %v is too large for %d strategies", p.MinWeightPercent, n)
+		}
+		if p.MaxWeightPercent > 0 && p.MaxWeightPercent*float64(n) < 100 {
+			return fmt.Errorf("allocation max_weight_percent %v is too small for %d strategies", p.MaxWeightPercent, n)
+		}
+	}
+	return nil
+}
+
+// StrategyAllocation 表示单个策略的业绩评估和分配结果
+type StrategyAllocation struct {
+	Strategy    string  `json:"strategy"`
+	TradingDays int     `json:"trading_days"` // 统计期内有平仓交易的天数
+	PnL         float64 `json:"pnl"`          // 统计期内的已实现盈亏
+	Sharpe      float64 `json:"sharpe"`       // 按日盈亏年化的夏普比率
+	MaxDrawdown float64 `json:"max_drawdown"` // 累计盈亏从高点回落的最大金额
+	Score       float64 `json:"score"`
+	Weight      float64 `json:"weight"`   // 占总资金的百分比
+	Capital     float64 `json:"capital"`  // 新的持仓市值上限
+	Previous    float64 `json:"previous"` // 调整前的持仓市值上限
+}
+
+// AllocationResult 表示一次资金分配调整
+type AllocationResult struct {
+	Timestamp  time.Time            `json:"timestamp"`
+	Capital    float64              `json:"capital"`
+	Strategies []StrategyAllocation `json:"strategies"`
+}
+
+// Allocator 按策略近期的夏普比率和回撤在策略之间重新分配资金，
+// 并将分配结果写入交易引擎的策略限制（持仓市值上限和每日亏损上限）
+type Allocator struct {
+	engine trading.TradingEngine
+	policy AllocationPolicy
+
+	mu       sync.RWMutex
+	clock    clock.Clock
+	eventBus *events.Bus
+	latest   *AllocationResult
+}
+
+// NewAllocator 创建资金分配器
+func NewAllocator(engine trading.TradingEngine, policy AllocationPolicy) *Allocator {
+	if policy.LookbackDays <= 0 {
+		policy.LookbackDays = 90
+	}
+	if policy.MinTradingDays <= 0 {
+		policy.MinTradingDays = 5
+	}
+	return &Allocator{
+		engine: engine,
+		policy: policy,
+	}
+}
+
+// SetClock 设置时钟，为空时使用系统时钟
+func (a *Allocator) SetClock(c clock.Clock) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clock = c
+}
+
+// SetEventBus 设置事件总线，分配调整后发布到风险主题
+func (a *Allocator) SetEventBus(bus *events.Bus) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.eventBus = bus
+}
+
+// Latest 返回最近一次的分配结果
+func (a *Allocator) Latest() (AllocationResult, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.latest == nil {
+		return AllocationResult{}, false
+	}
+	return *a.latest, true
+}
+
+// Rebalance 根据统计期内的平仓交易重新计算各策略的权重，并更新交易引擎的策略限制
+func (a *Allocator) Rebalance(ctx context.Context) (*AllocationResult, error) {
+	a.mu.RLock()
+	now := clock.Or(a.clock).Now()
+	a.mu.RUnlock()
+
+	limits := a.engine.GetLimits()
+	strategies := a.policy.Strategies
+	if len(strategies) == 0 {
+		for name := range limits.Strategies {
+			strategies = append(strategies, name)
+		}
+		sort.Strings(strategies)
+	}
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("no strategies to allocate")
+	}
+
+	capital := a.policy.Capital
+	if capital <= 0 {
+		account, err := a.engine.GetAccount(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account: %v", err)
+		}
+		capital = account.Equity
+	}
+	if capital <= 0 {
+		return nil, fmt.Errorf("allocation capital must be positive, got %v", capital)
+	}
+
+	// 交易按开仓时间过滤，统计期前开仓、期内平仓的交易也要计入
+	trades, err := a.engine.GetTrades(ctx, "", time.Time{}, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %v", err)
+	}
+
+	previous := make(map[string]float64, len(strategies))
+	for _, name := range strategies {
+		previous[name] = limits.Strategies[name].MaxNotional
+	}
+	result := Allocate(a.policy, strategies, trades, previous, capital, now)
+
+	updated := limits
+	updated.Strategies = make(map[string]trading.StrategyLimits, len(limits.Strategies)+len(strategies))
+	for name, sl := range limits.Strategies {
+		updated.Strategies[name] = sl
+	}
+	for _, allocation := range result.Strategies {
+		sl := updated.Strategies[allocation.Strategy]
+		sl.MaxNotional = allocation.Capital
+		if a.policy.DailyLossPercent > 0 {
+			sl.MaxDailyLoss = allocation.Capital * a.policy.DailyLossPercent / 100
+		}
+		updated.Strategies[allocation.Strategy] = sl
+	}
+	if err := a.engine.SetLimits(updated); err != nil {
+		return nil, fmt.Errorf("failed to update strategy limits: %v", err)
+	}
+
+	a.mu.Lock()
+	a.latest = &result
+	bus := a.eventBus
+	a.mu.Unlock()
+
+	bus.Publish(events.Event{
+		Topic:     events.TopicRisk,
+		Type:      EventAllocationUpdated,
+		Timestamp: result.Timestamp,
+		Payload:   result,
+	})
+	return &result, nil
+}
+
+// Allocate 按统计期内的日盈亏计算各策略的得分和权重，不修改交易引擎
+// 得分为年化夏普比率（负数按0计）乘以回撤折扣 1-回撤/MaxDrawdownPercent，
+// 回撤按调整前分配的资金计算；数据不足的策略使用其他策略的平均得分，
+// 所有得分为0时平均分配。权重按得分比例分配后限制在最低和最高权重之间
+func Allocate(policy AllocationPolicy, strategies []string, trades []trading.Trade, previous map[string]float64, capital float64, now time.Time) AllocationResult {
+	from := now.AddDate(0, 0, -policy.LookbackDays)
+	daily := make(map[string]map[string]float64, len(strategies))
+	for _, name := range strategies {
+		daily[name] = make(map[string]float64)
+	}
+	for _, trade := range trades {
+		if trade.ClosedAt == nil || trade.ClosedAt.Before(from) || trade.ClosedAt.After(now) {
+			continue
+		}
+		if days, ok := daily[trade.Strategy]; ok {
+			days[trade.ClosedAt.In(now.Location()).Format("2006-01-02")] += trade.RealizedPnL
+		}
+	}
+
+	result := AllocationResult{Timestamp: now, Capital: capital}
+	equalShare := capital / float64(len(strategies))
+	var scoreSum float64
+	var scored int
+	for _, name := range strategies {
+		allocation := strategyPerformance(name, daily[name])
+		allocation.Previous = previous[name]
+		if allocation.TradingDays >= policy.MinTradingDays {
+			allocation.Score = math.Max(allocation.Sharpe, 0)
+			base := allocation.Previous
+			if base <= 0 {
+				base = equalShare
+			}
+			if policy.MaxDrawdownPercent > 0 {
+				allocation.Score *= math.Max(0, 1-allocation.MaxDrawdown/base*100/policy.MaxDrawdownPercent)
+			}
+			scoreSum += allocation.Score
+			scored++
+		} else {
+			allocation.Score = -1
+		}
+		result.Strategies = append(result.Strategies, allocation)
+	}
+
+	// 数据不足的策略使用平均得分，不因为新上线而被分到最低权重
+	average := 0.0
+	if scored > 0 {
+		average = scoreSum / float64(scored)
+	}
+	scores := make([]float64, len(result.Strategies))
+	for i := range result.Strategies {
+		if result.Strategies[i].Score < 0 {
+			result.Strategies[i].Score = average
+		}
+		scores[i] = result.Strategies[i].Score
+	}
+
+	weights := allocationWeights(scores, policy.MinWeightPercent, policy.MaxWeightPercent)
+	for i := range result.Strategies {
+		result.Strategies[i].Weight = weights[i]
+		result.Strategies[i].Capital = capital * weights[i] / 100
+	}
+	return result
+}
+
+// strategyPerformance 按日盈亏计算策略的夏普比率和累计盈亏的最大回撤（内部函数）
+func strategyPerformance(name string, daily map[string]float64) StrategyAllocation {
+	days := make([]string, 0, len(daily))
+	for day := range daily {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	allocation := StrategyAllocation{Strategy: name, TradingDays: len(days)}
+	pnl := make([]float64, len(days))
+	var peak float64
+	for i, day := range days {
+		pnl[i] = daily[day]
+		allocation.PnL += pnl[i]
+		peak = math.Max(peak, allocation.PnL)
+		allocation.MaxDrawdown = math.Max(allocation.MaxDrawdown, peak-allocation.PnL)
+	}
+	if len(pnl) >= 2 {
+		mean, stddev := meanStdDev(pnl)
+		if stddev > 0 {
+			allocation.Sharpe = mean / stddev * math.Sqrt(252)
+		}
+	}
+	return allocation
+}
+
+// allocationWeights 按得分比例分配100%的权重，并限制在最低和最高权重之间，
+// 超出限制的策略固定在边界上，剩余权重在其他策略之间重新按得分分配（内部函数）
+func allocationWeights(scores []float64, minWeight, maxWeight float64) []float64 {
+	n := len(scores)
+	weights := make([]float64, n)
+	if n == 0 {
+		return weights
+	}
+	// 策略数量与边界冲突时放宽边界
+	minWeight = math.Min(minWeight, 100/float64(n))
+	if maxWeight <= 0 {
+		maxWeight = 100
+	}
+	maxWeight = math.Max(maxWeight, 100/float64(n))
+
+	fixed := make([]bool, n)
+	for {
+		remaining := 100.0
+		var scoreSum float64
+		var free int
+		for i := range scores {
+			if fixed[i] {
+				remaining -= weights[i]
+			} else {
+				scoreSum += scores[i]
+				free++
+			}
+		}
+		if free == 0 {
+			return weights
+		}
+		for i := range scores {
+			if fixed[i] {
+				continue
+			}
+			if scoreSum > 0 {
+				weights[i] = remaining * scores[i] / scoreSum
+			} else {
+				weights[i] = remaining / float64(free)
+			}
+		}
+
+		// 先处理超过上限的策略，没有时再处理低于下限的策略
+		changed := false
+		for i := range scores {
+			if !fixed[i] && weights[i] > maxWeight+1e-9 {
+				weights[i], fixed[i], changed = maxWeight, true, true
+			}
+		}
+		if !changed {
+			for i := range scores {
+				if !fixed[i] && weights[i] < minWeight-1e-9 {
+					weights[i], fixed[i], changed = minWeight, true, true
+				}
+			}
+		}
+		if !changed {
+			return weights
+		}
+	}
+}
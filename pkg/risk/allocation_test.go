@@ -0,0 +1,140 @@
+package risk
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// dailyTrades 为策略生成每天一笔平仓交易
+func dailyTrades(strategy string, start time.Time, pnl ...float64) []trading.Trade {
+	var trades []trading.Trade
+	for i, p := range pnl {
+		closed := start.AddDate(0, 0, i)
+		trades = append(trades, trading.Trade{Strategy: strategy, Symbol: "SPY", RealizedPnL: p, OpenedAt: closed.Add(-time.Hour), ClosedAt: &closed})
+	}
+	return trades
+}
+
+func TestAllocate(t *testing.T) {
+	now := time.Date(2024, 6, 28, 16, 0, 0, 0, time.UTC)
+	start := now.AddDate(0, 0, -20)
+	var trades []trading.Trade
+	trades = append(trades, dailyTrades("steady", start, 100, 120, 80, 110, 90, 100)...)
+	trades = append(trades, dailyTrades("volatile", start, 300, -250, 280, -260, 310, -200)...)
+	trades = append(trades, dailyTrades("losing", start, -50, -60, -40, -55, -45, -50)...)
+	trades = append(trades, dailyTrades("new", start, 100, 100)...)
+	// 统计期之前的交易不计入
+	trades = append(trades, dailyTrades("losing", now.AddDate(0, 0, -100), 5000, 5000, 5000, 5000, 5000, 5000)...)
+
+	policy := AllocationPolicy{LookbackDays: 30, MinTradingDays: 5, MinWeightPercent: 5, MaxWeightPercent: 40}
+	strategies := []string{"steady", "volatile", "losing", "new"}
+	result := Allocate(policy, strategies, trades, nil, 100000, now)
+
+	byName := make(map[string]StrategyAllocation)
+	var total float64
+	for _, allocation := range result.Strategies {
+		byName[allocation.Strategy] = allocation
+		total += allocation.Weight
+	}
+	if math.Abs(total-100) > 1e-6 {
+		t.Errorf("权重之和应为100: %v", total)
+	}
+
+	steady, volatile, losing, fresh := byName["steady"], byName["volatile"], byName["losing"], byName["new"]
+	if steady.TradingDays != 6 || steady.PnL != 600 || steady.Sharpe <= volatile.Sharpe {
+		t.Errorf("业绩统计不正确: steady %+v volatile %+v", steady, volatile)
+	}
+	if losing.Score != 0 || losing.Weight != 5 || losing.Capital != 5000 {
+		t.Errorf("亏损策略应只保留最低权重: %+v", losing)
+	}
+	if steady.Weight != 40 {
+		t.Errorf("表现最好的策略应受最高权重限制: %+v", steady)
+	}
+	if fresh.TradingDays != 2 || math.Abs(fresh.Score-(steady.Score+volatile.Score)/3) > 1e-9 {
+		t.Errorf("数据不足的策略应使用平均得分: %+v", fresh)
+	}
+	if volatile.Weight <= losing.Weight || volatile.MaxDrawdown != 260 {
+		t.Errorf("波动策略的权重或回撤不正确: %+v", volatile)
+	}
+
+	// 回撤达到上限时得分降为0
+	policy.MaxDrawdownPercent, policy.MaxWeightPercent = 1, 0
+	result = Allocate(policy, []string{"steady", "volatile"}, trades, map[string]float64{"volatile": 20000}, 100000, now)
+	if result.Strategies[1].Score != 0 || result.Strategies[0].Weight != 95 || result.Strategies[1].Weight != 5 {
+		t.Errorf("回撤超限的策略应降到最低权重: %+v", result.Strategies)
+	}
+
+	// 所有策略都没有数据时平均分配
+	result = Allocate(AllocationPolicy{MinTradingDays: 5}, []string{"a", "b", "c", "d"}, nil, nil, 1000, now)
+	for _, allocation := range result.Strategies {
+		if allocation.Weight != 25 || allocation.Capital != 250 {
+			t.Errorf("没有数据时应平均分配: %+v", allocation)
+		}
+	}
+}
+
+func TestAllocationWeights(t *testing.T) {
+	cases := []struct {
+		name     string
+		scores   []float64
+		min, max float64
+		want     []float64
+	}{
+		{"按得分比例", []float64{1, 3}, 0, 0, []float64{25, 75}},
+		{"上限和下限", []float64{10, 1, 0}, 10, 60, []float64{60, 30, 10}},
+		{"下限超出可行范围时平均分配", []float64{1, 0}, 80, 0, []float64{50, 50}},
+		{"上限过小时放宽", []float64{5, 1, 1, 1}, 0, 10, []float64{25, 25, 25, 25}},
+	}
+	for _, tc := range cases {
+		got := allocationWeights(tc.scores, tc.min, tc.max)
+		for i := range got {
+			if math.Abs(got[i]-tc.want[i]) > 1e-9 {
+				t.Errorf("%s: 权重 %v, 期望 %v", tc.name, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestAllocatorRebalance(t *testing.T) {
+	engine := trading.NewBaseTradingEngine(nil, trading.BrokerConfig{}, trading.TradingLimits{
+		MaxPositions: 10,
+		Strategies: map[string]trading.StrategyLimits{
+			"momentum":  {MaxPositions: 3, MaxNotional: 10000},
+			"reversion": {MaxNotional: 10000},
+		},
+	})
+	original := engine.GetLimits()
+
+	allocator := NewAllocator(engine, AllocationPolicy{Capital: 50000, DailyLossPercent: 2})
+	allocator.SetClock(clock.NewManual(time.Date(2024, 6, 28, 16, 0, 0, 0, time.UTC)))
+	result, err := allocator.Rebalance(context.Background())
+	if err != nil {
+		t.Fatalf("调整分配失败: %v", err)
+	}
+	if len(result.Strategies) != 2 || result.Strategies[0].Strategy != "momentum" || result.Strategies[0].Previous != 10000 {
+		t.Fatalf("应使用引擎中配置的策略: %+v", result.Strategies)
+	}
+
+	limits := engine.GetLimits()
+	momentum := limits.Strategies["momentum"]
+	if momentum.MaxNotional != 25000 || momentum.MaxDailyLoss != 500 || momentum.MaxPositions != 3 || limits.MaxPositions != 10 {
+		t.Errorf("策略限制未更新或其他限制被修改: %+v", limits)
+	}
+	if original.Strategies["momentum"].MaxNotional != 10000 {
+		t.Error("调整分配不应修改原来的限制")
+	}
+	if latest, ok := allocator.Latest(); !ok || latest.Capital != 50000 {
+		t.Errorf("最近一次分配结果不正确: %+v", latest)
+	}
+
+	empty := NewAllocator(trading.NewBaseTradingEngine(nil, trading.BrokerConfig{}, trading.TradingLimits{}), AllocationPolicy{Capital: 1000})
+	if _, err := empty.Rebalance(context.Background()); err == nil {
+		t.Error("没有策略时应返回错误")
+	}
+}
@@ -0,0 +1,147 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// minCorrelationObservations 是计算相关系数所需的最少共同交易日数，不足时视为不相关
+const minCorrelationObservations = 20
+
+// CorrelationLimits 表示新开仓的相关性集中度限制
+// 与新开仓股票的日收益率相关系数达到 Threshold 的同向持仓视为同一相关组，
+// 相关组（包括新开仓本身）的总市值占权益的比例不能超过 MaxExposurePercent
+type CorrelationLimits struct {
+	Threshold          float64 `json:"threshold" yaml:"threshold"`                       // 相关系数阈值，如0.7
+	MaxExposurePercent float64 `json:"max_exposure_percent" yaml:"max_exposure_percent"` // 相关组总市值占权益的上限，为0时不限制
+	Downsize           bool    `json:"downsize" yaml:"downsize"`                         // 超限时缩减订单数量而不是拒绝，需要注册 CorrelationMiddleware
+}
+
+// Validate 检查相关性限制是否有效
+func (l CorrelationLimits) Validate() error {
+	if l.MaxExposurePercent < 0 {
+		return fmt.Errorf("correlation max_exposure_percent must not be negative")
+	}
+	if l.MaxExposurePercent > 0 && (l.Threshold <= 0 || l.Threshold > 1) {
+		return fmt.Errorf("correlation threshold must be in (0, 1], got %v", l.Threshold)
+	}
+	return nil
+}
+
+// CorrelationMiddleware 返回下单中间件，开仓或加仓会使相关组超出限制时把订单数量缩减到允许的最大值
+// 未设置 Downsize 或允许的数量为0时不修改订单，由 CheckOrder 拒绝
+// 可通过 engine.Use(service.CorrelationMiddleware()) 注册到交易引擎
+func (s *Service) CorrelationMiddleware() trading.OrderMiddleware {
+	return func(next trading.OrderHandler) trading.OrderHandler {
+		return func(ctx context.Context, req trading.OrderRequest) (*trading.Order, error) {
+			if !s.config.Limits.Correlation.Downsize {
+				return next(ctx, req)
+			}
+			snapshot, ok := s.Latest()
+			if !ok {
+				return next(ctx, req)
+			}
+			current, _ := snapshot.position(req.Symbol)
+			price := req.Price
+			if price <= 0 {
+				price = current.Price
+			}
+			if price <= 0 {
+				price = s.lastPrice(ctx, req.Symbol)
+			}
+			if allowed, _, limited := s.correlationAllowance(ctx, snapshot, current, req, price); limited && allowed > 0 {
+				req.Quantity = allowed
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// correlationAllowance 计算订单在相关性集中度限制内允许的最大数量（内部方法）
+// limited 为 false 表示订单不受限制；为 true 时 detail 说明超限的相关组
+func (s *Service) correlationAllowance(ctx context.Context, snapshot Snapshot, current PositionExposure, req trading.OrderRequest, price float64) (allowed int64, detail string, limited bool) {
+	limits := s.config.Limits.Correlation
+	if limits.MaxExposurePercent <= 0 || price <= 0 || snapshot.Equity <= 0 {
+		return req.Quantity, "", false
+	}
+
+	direction := 1.0
+	if req.Side == trading.OrderSideSell {
+		direction = -1
+	}
+	newValue := current.MarketValue + direction*float64(req.Quantity)*price
+	if math.Abs(newValue) <= math.Abs(current.MarketValue) {
+		return req.Quantity, "", false
+	}
+
+	candidate := s.candidateReturns(ctx, req.Symbol)
+	s.mu.RLock()
+	held := s.symbolReturns
+	s.mu.RUnlock()
+
+	// 与新开仓方向相同且高度相关的其他持仓
+	var others float64
+	var cluster []string
+	for _, pos := range snapshot.Positions {
+		if pos.Symbol == req.Symbol || pos.MarketValue == 0 || (pos.MarketValue > 0) != (newValue > 0) {
+			continue
+		}
+		a, b := alignReturns(candidate, held[pos.Symbol])
+		if len(a) < minCorrelationObservations || Correlation(a, b) < limits.Threshold {
+			continue
+		}
+		others += math.Abs(pos.MarketValue)
+		cluster = append(cluster, pos.Symbol)
+	}
+	if len(cluster) == 0 {
+		return req.Quantity, "", false
+	}
+
+	limit := snapshot.Equity * limits.MaxExposurePercent / 100
+	if others+math.Abs(newValue) <= limit {
+		return req.Quantity, "", false
+	}
+
+	// 下单后该股票的市值不能超过 limit-others：|current + direction×数量×价格| <= limit-others
+	allowed = int64(math.Floor((limit - others - direction*current.MarketValue) / price))
+	allowed = max(min(allowed, req.Quantity), 0)
+	sort.Strings(cluster)
+	detail = fmt.Sprintf("exposure correlated with %s would be %.2f%%, limit %.2f%%",
+		strings.Join(cluster, ", "), snapshot.percent(others+math.Abs(newValue)), limits.MaxExposurePercent)
+	return allowed, detail, true
+}
+
+// candidateReturns 返回股票的日收益率，持仓股票使用最近一次刷新时的数据，其他股票获取后缓存到下一次刷新（内部方法）
+func (s *Service) candidateReturns(ctx context.Context, symbol string) map[string]float64 {
+	s.mu.RLock()
+	returns, ok := s.symbolReturns[symbol]
+	if !ok {
+		returns, ok = s.candidates[symbol]
+	}
+	s.mu.RUnlock()
+	if ok {
+		return returns
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -(s.config.LookbackDays*7/5 + 10))
+	fetched, err := s.returns(ctx, symbol, from, to)
+	if err != nil {
+		// 获取失败时视为不相关，不缓存以便下次重试
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.candidates == nil {
+		s.candidates = make(map[string]map[string]float64)
+	}
+	s.candidates[symbol] = fetched.values
+	return fetched.values
+}
@@ -0,0 +1,131 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// returnSeries 按给定的收益率函数生成 n 个交易日的收益率
+func returnSeries(n int, f func(i int) float64) map[string]float64 {
+	values := make(map[string]float64, n)
+	for i := 0; i < n; i++ {
+		values[fmt.Sprintf("2024-%03d", i)] = f(i)
+	}
+	return values
+}
+
+func TestCorrelation(t *testing.T) {
+	a := []float64{0.01, -0.02, 0.03, 0, 0.01}
+	b := []float64{0.02, -0.04, 0.06, 0, 0.02}
+	if got := Correlation(a, b); math.Abs(got-1) > 1e-9 {
+		t.Errorf("同向序列的相关系数 = %v, 期望 1", got)
+	}
+	negated := make([]float64, len(a))
+	for i, v := range a {
+		negated[i] = -v
+	}
+	if got := Correlation(a, negated); math.Abs(got+1) > 1e-9 {
+		t.Errorf("反向序列的相关系数 = %v, 期望 -1", got)
+	}
+	if got := Correlation(a, []float64{1, 1, 1, 1, 1}); got != 0 {
+		t.Errorf("方差为0时相关系数应为0: %v", got)
+	}
+}
+
+func newCorrelationService(limits CorrelationLimits) *Service {
+	service := NewService(nil, nil, Config{Limits: Limits{Correlation: limits}})
+	snapshot := Snapshot{
+		Equity: 100000,
+		Positions: []PositionExposure{
+			{Symbol: "AMD", Quantity: 100, Price: 150, MarketValue: 15000, Beta: 1},
+			{Symbol: "NVDA", Quantity: 10, Price: 1000, MarketValue: 10000, Beta: 1},
+			{Symbol: "XOM", Quantity: 100, Price: 100, MarketValue: 10000, Beta: 1},
+			{Symbol: "SMH", Quantity: -20, Price: 250, MarketValue: -5000, Beta: 1},
+		},
+	}
+	service.applyExposure(&snapshot)
+	service.latest = &snapshot
+
+	chips := func(i int) float64 { return 0.01 * math.Sin(float64(i)) }
+	service.symbolReturns = map[string]map[string]float64{
+		"AMD":  returnSeries(60, chips),
+		"NVDA": returnSeries(60, func(i int) float64 { return 1.5 * chips(i) }),
+		"XOM":  returnSeries(60, func(i int) float64 { return 0.01 * math.Cos(float64(i)) }),
+		"SMH":  returnSeries(60, chips),
+	}
+	service.candidates = map[string]map[string]float64{
+		"INTC": returnSeries(60, func(i int) float64 { return chips(i) + 0.001*math.Cos(float64(i)) }),
+		"NEW":  returnSeries(5, chips), // 数据不足时视为不相关
+	}
+	return service
+}
+
+func TestCorrelationGuard(t *testing.T) {
+	service := newCorrelationService(CorrelationLimits{Threshold: 0.7, MaxExposurePercent: 30})
+	ctx := context.Background()
+
+	cases := []struct {
+		name    string
+		req     trading.OrderRequest
+		allowed bool
+	}{
+		{"相关组加新开仓在限制内", trading.OrderRequest{Symbol: "INTC", Quantity: 100, Price: 40, Side: trading.OrderSideBuy}, true},
+		{"相关组超出限制", trading.OrderRequest{Symbol: "INTC", Quantity: 200, Price: 40, Side: trading.OrderSideBuy}, false},
+		{"加仓同样检查", trading.OrderRequest{Symbol: "AMD", Quantity: 50, Side: trading.OrderSideBuy}, false},
+		{"不相关的持仓不计入", trading.OrderRequest{Symbol: "XOM", Quantity: 100, Side: trading.OrderSideBuy}, true},
+		{"反向持仓不计入", trading.OrderRequest{Symbol: "INTC", Quantity: 200, Price: 40, Side: trading.OrderSideSell}, true},
+		{"数据不足时不检查", trading.OrderRequest{Symbol: "NEW", Quantity: 200, Price: 40, Side: trading.OrderSideBuy}, true},
+		{"减仓始终允许", trading.OrderRequest{Symbol: "NVDA", Quantity: 10, Side: trading.OrderSideSell}, true},
+	}
+	for _, tc := range cases {
+		err := service.CheckOrder(ctx, tc.req)
+		if tc.allowed && err != nil {
+			t.Errorf("%s: 期望允许, 实际 %v", tc.name, err)
+		}
+		if !tc.allowed && !errors.Is(err, trading.ErrTradeLimitExceeded) {
+			t.Errorf("%s: 期望拒绝, 实际 %v", tc.name, err)
+		}
+	}
+
+	err := service.CheckOrder(ctx, trading.OrderRequest{Symbol: "INTC", Quantity: 200, Price: 40, Side: trading.OrderSideBuy})
+	if err == nil || !strings.Contains(err.Error(), "AMD, NVDA") || !strings.Contains(err.Error(), "at most 125 shares") {
+		t.Errorf("拒绝原因应包含相关组和允许的数量: %v", err)
+	}
+}
+
+func TestCorrelationMiddleware(t *testing.T) {
+	var submitted int64
+	next := func(ctx context.Context, req trading.OrderRequest) (*trading.Order, error) {
+		submitted = req.Quantity
+		return &trading.Order{Symbol: req.Symbol, Quantity: req.Quantity}, nil
+	}
+	ctx := context.Background()
+	req := trading.OrderRequest{Symbol: "INTC", Quantity: 200, Price: 40, Side: trading.OrderSideBuy}
+
+	service := newCorrelationService(CorrelationLimits{Threshold: 0.7, MaxExposurePercent: 30, Downsize: true})
+	handler := service.CorrelationMiddleware()(next)
+	if _, err := handler(ctx, req); err != nil || submitted != 125 {
+		t.Errorf("超限的订单应缩减到允许的数量: %d %v", submitted, err)
+	}
+	if err := service.CheckOrder(ctx, trading.OrderRequest{Symbol: "INTC", Quantity: submitted, Price: 40, Side: trading.OrderSideBuy}); err != nil {
+		t.Errorf("缩减后的订单应通过检查: %v", err)
+	}
+
+	// 相关组已满时不修改订单，由 CheckOrder 拒绝
+	full := newCorrelationService(CorrelationLimits{Threshold: 0.7, MaxExposurePercent: 20, Downsize: true})
+	if _, err := full.CorrelationMiddleware()(next)(ctx, req); err != nil || submitted != 200 {
+		t.Errorf("没有可用额度时不应修改订单: %d %v", submitted, err)
+	}
+
+	// 未启用缩减时不修改订单
+	blocking := newCorrelationService(CorrelationLimits{Threshold: 0.7, MaxExposurePercent: 30})
+	if _, err := blocking.CorrelationMiddleware()(next)(ctx, req); err != nil || submitted != 200 {
+		t.Errorf("未启用缩减时不应修改订单: %d %v", submitted, err)
+	}
+}
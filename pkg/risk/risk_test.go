@@ -0,0 +1,84 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+func TestVaRAndBeta(t *testing.T) {
+	returns := []float64{-0.05, -0.03, -0.02, -0.01, 0, 0.01, 0.01, 0.02, 0.02, 0.03,
+		0.01, -0.01, 0.02, 0, 0.01, -0.02, 0.03, 0.01, 0, 0.02}
+
+	// 20个样本，95%置信度取最差的第1个
+	if got := HistoricalVaR(returns, 0.95); got != 0.03 {
+		t.Errorf("HistoricalVaR = %v, 期望 0.03", got)
+	}
+	if got := HistoricalVaR(returns, 0.99); got != 0.05 {
+		t.Errorf("HistoricalVaR(99%%) = %v, 期望 0.05", got)
+	}
+
+	mean, stddev := meanStdDev(returns)
+	want := 1.6448536269514722*stddev - mean
+	if got := ParametricVaR(returns, 0.95); math.Abs(got-want) > 1e-9 {
+		t.Errorf("ParametricVaR = %v, 期望 %v", got, want)
+	}
+
+	doubled := make([]float64, len(returns))
+	for i, r := range returns {
+		doubled[i] = 2 * r
+	}
+	if got := Beta(doubled, returns); math.Abs(got-2) > 1e-9 {
+		t.Errorf("Beta = %v, 期望 2", got)
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	service := NewService(nil, nil, Config{Limits: Limits{
+		MaxGrossExposurePercent: 100,
+		MaxConcentrationPercent: 30,
+		MaxBetaExposurePercent:  120,
+	}})
+
+	snapshot := Snapshot{
+		Equity: 100000,
+		Positions: []PositionExposure{
+			{Symbol: "AAPL", Quantity: 100, Price: 200, MarketValue: 20000, Beta: 1.2},
+			{Symbol: "MSFT", Quantity: 100, Price: 400, MarketValue: 40000, Beta: 0.9},
+		},
+	}
+	service.applyExposure(&snapshot)
+	service.latest = &snapshot
+
+	if snapshot.GrossExposure != 60000 || snapshot.Concentration != 0.4 || snapshot.LargestSymbol != "MSFT" {
+		t.Fatalf("敞口计算不正确: %+v", snapshot)
+	}
+	if math.Abs(snapshot.BetaExposure-60000) > 1e-6 {
+		t.Errorf("BetaExposure = %v, 期望 60000", snapshot.BetaExposure)
+	}
+
+	ctx := context.Background()
+	cases := []struct {
+		name    string
+		req     trading.OrderRequest
+		allowed bool
+	}{
+		{"加仓在限制内", trading.OrderRequest{Symbol: "AAPL", Quantity: 50, Side: trading.OrderSideBuy}, true},
+		{"超出单一持仓集中度", trading.OrderRequest{Symbol: "AAPL", Quantity: 100, Side: trading.OrderSideBuy}, false},
+		{"新开仓超出总敞口", trading.OrderRequest{Symbol: "NVDA", Quantity: 500, Price: 90, Side: trading.OrderSideBuy}, false},
+		{"新开仓价格未知时不检查", trading.OrderRequest{Symbol: "NVDA", Quantity: 500, Side: trading.OrderSideBuy}, true},
+		{"减仓始终允许", trading.OrderRequest{Symbol: "MSFT", Quantity: 100, Side: trading.OrderSideSell}, true},
+	}
+	for _, tc := range cases {
+		err := service.CheckOrder(ctx, tc.req)
+		if tc.allowed && err != nil {
+			t.Errorf("%s: 期望允许, 实际 %v", tc.name, err)
+		}
+		if !tc.allowed && !errors.Is(err, trading.ErrTradeLimitExceeded) {
+			t.Errorf("%s: 期望因风险限制拒绝, 实际 %v", tc.name, err)
+		}
+	}
+}
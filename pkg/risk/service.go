@@ -0,0 +1,482 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// 风险事件类型常量
+const (
+	EventRiskUpdated       = "risk_updated"        // 风险指标已刷新
+	EventRiskLimitBreached = "risk_limit_breached" // 风险指标超出限制
+)
+
+// Limits 表示组合风险限制，均为占账户权益的百分比，为0时不限制
+type Limits struct {
+	MaxVaRPercent           float64           `json:"max_var_percent" yaml:"max_var_percent"`
+	MaxGrossExposurePercent float64           `json:"max_gross_exposure_percent" yaml:"max_gross_exposure_percent"`
+	MaxNetExposurePercent   float64           `json:"max_net_exposure_percent" yaml:"max_net_exposure_percent"`
+	MaxBetaExposurePercent  float64           `json:"max_beta_exposure_percent" yaml:"max_beta_exposure_percent"`
+	MaxConcentrationPercent float64           `json:"max_concentration_percent" yaml:"max_concentration_percent"` // 单一持仓上限
+	Correlation             CorrelationLimits `json:"correlation" yaml:"correlation"`                             // 新开仓的相关性集中度限制
+}
+
+// Config 表示风险服务配置
+type Config struct {
+	Interval     time.Duration
+	Benchmark    string  // 计算贝塔使用的基准，如 SPY
+	LookbackDays int     // 历史收益率的交易日数量
+	Confidence   float64 // VaR置信度，如 0.95
+	HorizonDays  int     // VaR持有期（天），按平方根法则缩放
+	Limits       Limits
+}
+
+// PositionExposure 表示单个持仓的风险敞口
+type PositionExposure struct {
+	Symbol      string  `json:"symbol"`
+	Quantity    int64   `json:"quantity"`
+	Price       float64 `json:"price"`
+	MarketValue float64 `json:"market_value"`
+	Weight      float64 `json:"weight"` // 市值占权益比例
+	Beta        float64 `json:"beta"`
+}
+
+// Snapshot 表示一次计算得到的组合风险指标，金额单位与账户一致
+type Snapshot struct {
+	Timestamp     time.Time          `json:"timestamp"`
+	Equity        float64            `json:"equity"`
+	LongExposure  float64            `json:"long_exposure"`
+	ShortExposure float64            `json:"short_exposure"`
+	GrossExposure float64            `json:"gross_exposure"`
+	NetExposure   float64            `json:"net_exposure"`
+	BetaExposure  float64            `json:"beta_exposure"` // 贝塔调整后的净敞口
+	HistoricalVaR float64            `json:"historical_var"`
+	ParametricVaR float64            `json:"parametric_var"`
+	Confidence    float64            `json:"confidence"`
+	HorizonDays   int                `json:"horizon_days"`
+	Concentration float64            `json:"concentration"` // 最大单一持仓市值占权益比例
+	LargestSymbol string             `json:"largest_symbol,omitempty"`
+	HHI           float64            `json:"hhi"` // 按总敞口权重计算的赫芬达尔指数
+	Observations  int                `json:"observations"`
+	Positions     []PositionExposure `json:"positions"`
+	Breaches      []string           `json:"breaches,omitempty"`
+}
+
+// VaR 返回历史法和参数法中较大的VaR
+func (s Snapshot) VaR() float64 {
+	return math.Max(s.HistoricalVaR, s.ParametricVaR)
+}
+
+// percent 计算金额占权益的百分比（内部方法）
+func (s Snapshot) percent(amount float64) float64 {
+	if s.Equity <= 0 {
+		return 0
+	}
+	return amount / s.Equity * 100
+}
+
+// position 返回指定股票的敞口（内部方法）
+func (s Snapshot) position(symbol string) (PositionExposure, bool) {
+	for _, pos := range s.Positions {
+		if pos.Symbol == symbol {
+			return pos, true
+		}
+	}
+	return PositionExposure{}, false
+}
+
+// breaches 返回超出限制的指标说明（内部方法）
+func (l Limits) breaches(s Snapshot) []string {
+	var breaches []string
+	check := func(name string, amount, limit float64) {
+		if limit > 0 && s.percent(amount) > limit {
+			breaches = append(breaches, fmt.Sprintf("%s %.2f%% exceeds limit %.2f%%", name, s.percent(amount), limit))
+		}
+	}
+	check("VaR", s.VaR(), l.MaxVaRPercent)
+	check("gross exposure", s.GrossExposure, l.MaxGrossExposurePercent)
+	check("net exposure", math.Abs(s.NetExposure), l.MaxNetExposurePercent)
+	check("beta exposure", math.Abs(s.BetaExposure), l.MaxBetaExposurePercent)
+	check("concentration", s.Concentration*s.Equity, l.MaxConcentrationPercent)
+	return breaches
+}
+
+// Service 定期计算组合VaR、敞口、贝塔调整敞口和集中度，并作为交易引擎的下单前检查执行风险限制
+type Service struct {
+	engine      trading.TradingEngine
+	dataManager *datasource.Manager
+	config      Config
+	eventBus    *events.Bus
+
+	mu            sync.RWMutex
+	latest        *Snapshot
+	symbolReturns map[string]map[string]float64 // 最近一次计算时各持仓的日收益率
+	candidates    map[string]map[string]float64 // 检查新开仓时获取的日收益率，每次刷新后清空
+}
+
+// NewService 创建风险服务
+func NewService(engine trading.TradingEngine, dataManager *datasource.Manager, config Config) *Service {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	if config.Benchmark == "" {
+		config.Benchmark = "SPY"
+	}
+	if config.LookbackDays <= 0 {
+		config.LookbackDays = 250
+	}
+	if config.Confidence <= 0 || config.Confidence >= 1 {
+		config.Confidence = 0.95
+	}
+	if config.HorizonDays <= 0 {
+		config.HorizonDays = 1
+	}
+
+	return &Service{
+		engine:      engine,
+		dataManager: dataManager,
+		config:      config,
+	}
+}
+
+// SetEventBus 设置事件总线，刷新后的风险指标和超限会发布到总线上
+func (s *Service) SetEventBus(bus *events.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventBus = bus
+}
+
+// Latest 返回最近一次计算的风险指标
+func (s *Service) Latest() (Snapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.latest == nil {
+		return Snapshot{}, false
+	}
+	return *s.latest, true
+}
+
+// Run 立即计算一次风险指标，之后按配置的间隔刷新，阻塞直到上下文取消
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+			fmt.Printf("Error refreshing risk metrics: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh 根据当前持仓和历史行情重新计算风险指标，失败时保留上一次的结果
+func (s *Service) Refresh(ctx context.Context) (*Snapshot, error) {
+	positions, err := s.engine.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %v", err)
+	}
+	account, err := s.engine.GetAccount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %v", err)
+	}
+
+	// 交易日换算为自然日，并留出节假日余量
+	to := time.Now()
+	from := to.AddDate(0, 0, -(s.config.LookbackDays*7/5 + 10))
+
+	benchmark, err := s.returns(ctx, s.config.Benchmark, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := Snapshot{
+		Timestamp:   to,
+		Equity:      account.Equity,
+		Confidence:  s.config.Confidence,
+		HorizonDays: s.config.HorizonDays,
+	}
+
+	symbolReturns := make(map[string]map[string]float64)
+	for _, pos := range positions {
+		if pos.Quantity == 0 {
+			continue
+		}
+
+		returns, err := s.returns(ctx, pos.Symbol, from, to)
+		if err != nil {
+			return nil, err
+		}
+		symbolReturns[pos.Symbol] = returns.values
+
+		price := returns.lastClose
+		if price <= 0 {
+			price = pos.CurrentPrice
+		}
+		exposure := PositionExposure{
+			Symbol:      pos.Symbol,
+			Quantity:    pos.Quantity,
+			Price:       price,
+			MarketValue: float64(pos.Quantity) * price,
+			Beta:        Beta(alignReturns(returns.values, benchmark.values)),
+		}
+		snapshot.Positions = append(snapshot.Positions, exposure)
+	}
+	sort.Slice(snapshot.Positions, func(i, j int) bool {
+		return snapshot.Positions[i].Symbol < snapshot.Positions[j].Symbol
+	})
+
+	if snapshot.Equity <= 0 {
+		// 账户权益未知时以持仓总市值代替
+		for _, pos := range snapshot.Positions {
+			snapshot.Equity += math.Abs(pos.MarketValue)
+		}
+	}
+
+	s.applyExposure(&snapshot)
+
+	portfolio := portfolioReturns(snapshot, symbolReturns)
+	scale := snapshot.Equity * math.Sqrt(float64(s.config.HorizonDays))
+	snapshot.Observations = len(portfolio)
+	snapshot.HistoricalVaR = HistoricalVaR(portfolio, s.config.Confidence) * scale
+	snapshot.ParametricVaR = ParametricVaR(portfolio, s.config.Confidence) * scale
+	snapshot.Breaches = s.config.Limits.breaches(snapshot)
+
+	s.mu.Lock()
+	s.latest = &snapshot
+	s.symbolReturns = symbolReturns
+	s.candidates = nil
+	bus := s.eventBus
+	s.mu.Unlock()
+
+	bus.Publish(events.Event{
+		Topic:     events.TopicRisk,
+		Type:      EventRiskUpdated,
+		Timestamp: snapshot.Timestamp,
+		Payload:   snapshot,
+	})
+	if len(snapshot.Breaches) > 0 {
+		bus.Publish(events.Event{
+			Topic:     events.TopicRisk,
+			Type:      EventRiskLimitBreached,
+			Timestamp: snapshot.Timestamp,
+			Payload:   snapshot,
+		})
+	}
+
+	return &snapshot, nil
+}
+
+// CheckOrder 按最近一次的风险指标估算下单后的敞口和集中度，超出限制时拒绝
+// 只检查增加风险的订单，减仓始终允许；尚未计算风险指标时不检查
+// 可通过 engine.AddPreTradeCheck(service.CheckOrder) 注册到交易引擎
+func (s *Service) CheckOrder(ctx context.Context, req trading.OrderRequest) error {
+	snapshot, ok := s.Latest()
+	if !ok {
+		return nil
+	}
+
+	current, held := snapshot.position(req.Symbol)
+	if !held {
+		// 新开仓的贝塔按1估算
+		current.Beta = 1
+	}
+	price := req.Price
+	if price <= 0 {
+		price = current.Price
+	}
+	if price <= 0 {
+		price = s.lastPrice(ctx, req.Symbol)
+	}
+	if price <= 0 {
+		return nil
+	}
+
+	delta := float64(req.Quantity) * price
+	if req.Side == trading.OrderSideSell {
+		delta = -delta
+	}
+	newValue := current.MarketValue + delta
+	if math.Abs(newValue) <= math.Abs(current.MarketValue) {
+		return nil
+	}
+
+	limits := s.config.Limits
+	if limits.MaxVaRPercent > 0 && snapshot.percent(snapshot.VaR()) > limits.MaxVaRPercent {
+		return fmt.Errorf("%w: portfolio VaR %.2f%% exceeds limit %.2f%%", trading.ErrTradeLimitExceeded, snapshot.percent(snapshot.VaR()), limits.MaxVaRPercent)
+	}
+
+	proForma := snapshot
+	proForma.LongExposure += math.Max(newValue, 0) - math.Max(current.MarketValue, 0)
+	proForma.ShortExposure += math.Max(-newValue, 0) - math.Max(-current.MarketValue, 0)
+	proForma.GrossExposure = proForma.LongExposure + proForma.ShortExposure
+	proForma.NetExposure = proForma.LongExposure - proForma.ShortExposure
+	proForma.BetaExposure += delta * current.Beta
+
+	check := func(name string, amount, limit float64) error {
+		if limit > 0 && proForma.percent(amount) > limit {
+			return fmt.Errorf("%w: %s would be %.2f%%, limit %.2f%%", trading.ErrTradeLimitExceeded, name, proForma.percent(amount), limit)
+		}
+		return nil
+	}
+	if err := check("gross exposure", proForma.GrossExposure, limits.MaxGrossExposurePercent); err != nil {
+		return err
+	}
+	if err := check("net exposure", math.Abs(proForma.NetExposure), limits.MaxNetExposurePercent); err != nil {
+		return err
+	}
+	if err := check("beta exposure", math.Abs(proForma.BetaExposure), limits.MaxBetaExposurePercent); err != nil {
+		return err
+	}
+	if err := check(req.Symbol+" concentration", math.Abs(newValue), limits.MaxConcentrationPercent); err != nil {
+		return err
+	}
+
+	if allowed, detail, limited := s.correlationAllowance(ctx, snapshot, current, req, price); limited {
+		return fmt.Errorf("%w: %s, at most %d shares allowed", trading.ErrTradeLimitExceeded, detail, allowed)
+	}
+	return nil
+}
+
+// applyExposure 计算权重、敞口和集中度（内部方法）
+func (s *Service) applyExposure(snapshot *Snapshot) {
+	for i := range snapshot.Positions {
+		pos := &snapshot.Positions[i]
+		if pos.MarketValue >= 0 {
+			snapshot.LongExposure += pos.MarketValue
+		} else {
+			snapshot.ShortExposure -= pos.MarketValue
+		}
+		snapshot.BetaExposure += pos.MarketValue * pos.Beta
+		if snapshot.Equity > 0 {
+			pos.Weight = pos.MarketValue / snapshot.Equity
+			if math.Abs(pos.Weight) > snapshot.Concentration {
+				snapshot.Concentration = math.Abs(pos.Weight)
+				snapshot.LargestSymbol = pos.Symbol
+			}
+		}
+	}
+	snapshot.GrossExposure = snapshot.LongExposure + snapshot.ShortExposure
+	snapshot.NetExposure = snapshot.LongExposure - snapshot.ShortExposure
+
+	if snapshot.GrossExposure > 0 {
+		for _, pos := range snapshot.Positions {
+			w := math.Abs(pos.MarketValue) / snapshot.GrossExposure
+			snapshot.HHI += w * w
+		}
+	}
+}
+
+// lastPrice 获取最新报价，失败时返回0（内部方法）
+func (s *Service) lastPrice(ctx context.Context, symbol string) float64 {
+	if s.dataManager == nil {
+		return 0
+	}
+	quote, err := s.dataManager.GetRealTimeQuote(ctx, symbol)
+	if err != nil {
+		return 0
+	}
+	return quote.LastPrice
+}
+
+// dailyReturns 表示按日期索引的日收益率（内部类型）
+type dailyReturns struct {
+	values    map[string]float64
+	lastClose float64
+}
+
+// returns 获取股票的日收益率序列（内部方法）
+func (s *Service) returns(ctx context.Context, symbol string, from, to time.Time) (dailyReturns, error) {
+	result := dailyReturns{values: make(map[string]float64)}
+	if s.dataManager == nil {
+		return result, nil
+	}
+
+	bars, err := s.dataManager.GetStockData(ctx, symbol, "day", from, to)
+	if err != nil {
+		return result, fmt.Errorf("failed to get history for %s: %v", symbol, err)
+	}
+	sort.Slice(bars, func(i, j int) bool { return bars[i].Timestamp.Before(bars[j].Timestamp) })
+
+	// 只保留最近 LookbackDays 个收益率
+	if len(bars) > s.config.LookbackDays+1 {
+		bars = bars[len(bars)-s.config.LookbackDays-1:]
+	}
+	for i := 1; i < len(bars); i++ {
+		if bars[i-1].Close > 0 {
+			result.values[dateKey(bars[i].Timestamp)] = bars[i].Close/bars[i-1].Close - 1
+		}
+	}
+	if len(bars) > 0 {
+		result.lastClose = bars[len(bars)-1].Close
+	}
+	return result, nil
+}
+
+// portfolioReturns 按当前权重合成组合的历史日收益率，只使用所有持仓都有数据的日期（内部函数）
+func portfolioReturns(snapshot Snapshot, symbolReturns map[string]map[string]float64) []float64 {
+	if len(snapshot.Positions) == 0 || snapshot.Equity <= 0 {
+		return nil
+	}
+
+	var dates []string
+	for date := range symbolReturns[snapshot.Positions[0].Symbol] {
+		common := true
+		for _, pos := range snapshot.Positions[1:] {
+			if _, ok := symbolReturns[pos.Symbol][date]; !ok {
+				common = false
+				break
+			}
+		}
+		if common {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	returns := make([]float64, 0, len(dates))
+	for _, date := range dates {
+		var r float64
+		for _, pos := range snapshot.Positions {
+			r += pos.Weight * symbolReturns[pos.Symbol][date]
+		}
+		returns = append(returns, r)
+	}
+	return returns
+}
+
+// alignReturns 返回两个序列共同日期上的收益率（内部函数）
+func alignReturns(asset, benchmark map[string]float64) ([]float64, []float64) {
+	dates := make([]string, 0, len(asset))
+	for date := range asset {
+		if _, ok := benchmark[date]; ok {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	a := make([]float64, len(dates))
+	b := make([]float64, len(dates))
+	for i, date := range dates {
+		a[i] = asset[date]
+		b[i] = benchmark[date]
+	}
+	return a, b
+}
+
+// dateKey 返回日期键（内部函数）
+func dateKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,109 @@
+package risk
+
+import (
+	"math"
+	"sort"
+)
+
+// HistoricalVaR 根据历史收益率序列计算给定置信度下的VaR，返回损失占组合价值的比例（正数）
+func HistoricalVaR(returns []float64, confidence float64) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), returns...)
+	sort.Float64s(sorted)
+
+	// 取 (1-置信度) 分位数，使用向下取整的经验分位数
+	idx := int(math.Floor((1 - confidence) * float64(len(sorted))))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return math.Max(0, -sorted[idx])
+}
+
+// ParametricVaR 假设收益率服从正态分布计算VaR，返回损失占组合价值的比例（正数）
+func ParametricVaR(returns []float64, confidence float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean, stddev := meanStdDev(returns)
+	return math.Max(0, normalQuantile(confidence)*stddev-mean)
+}
+
+// Beta 计算资产相对基准的贝塔系数，两个序列需按日期对齐
+func Beta(asset, benchmark []float64) float64 {
+	n := len(asset)
+	if len(benchmark) < n {
+		n = len(benchmark)
+	}
+	if n < 2 {
+		return 1
+	}
+
+	assetMean, _ := meanStdDev(asset[:n])
+	benchMean, _ := meanStdDev(benchmark[:n])
+
+	var cov, variance float64
+	for i := 0; i < n; i++ {
+		cov += (asset[i] - assetMean) * (benchmark[i] - benchMean)
+		variance += (benchmark[i] - benchMean) * (benchmark[i] - benchMean)
+	}
+	if variance == 0 {
+		return 1
+	}
+	return cov / variance
+}
+
+// Correlation 计算两个按日期对齐的收益率序列的皮尔逊相关系数，样本不足或方差为0时返回0
+func Correlation(a, b []float64) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n < 2 {
+		return 0
+	}
+
+	meanA, _ := meanStdDev(a[:n])
+	meanB, _ := meanStdDev(b[:n])
+
+	var cov, varA, varB float64
+	for i := 0; i < n; i++ {
+		cov += (a[i] - meanA) * (b[i] - meanB)
+		varA += (a[i] - meanA) * (a[i] - meanA)
+		varB += (b[i] - meanB) * (b[i] - meanB)
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
+
+// meanStdDev 计算样本均值和样本标准差（内部函数）
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)-1))
+}
+
+// normalQuantile 返回标准正态分布的分位数（内部函数）
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
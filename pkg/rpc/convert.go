@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/rpc/qhftpb"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// toTimestamp 将时间转换为protobuf时间戳，零值返回nil
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// toOrderPB 将订单转换为protobuf消息
+func toOrderPB(order trading.Order) *qhftpb.Order {
+	pb := &qhftpb.Order{
+		Id:            order.ID,
+		Symbol:        order.Symbol,
+		Quantity:      order.Quantity,
+		FilledQty:     order.FilledQty,
+		Price:         order.Price,
+		StopPrice:     order.StopPrice,
+		Type:          string(order.Type),
+		Side:          string(order.Side),
+		Status:        string(order.Status),
+		CreatedAt:     toTimestamp(order.CreatedAt),
+		UpdatedAt:     toTimestamp(order.UpdatedAt),
+		AvgFillPrice:  order.AvgFillPrice,
+		Commission:    order.Commission,
+		RejectReason:  order.RejectReason,
+		ClientOrderId: order.ClientOrderID,
+		BrokerOrderId: order.BrokerOrderID,
+		Strategy:      order.Strategy,
+		Tags:          order.Tags,
+	}
+	if order.FilledAt != nil {
+		pb.FilledAt = toTimestamp(*order.FilledAt)
+	}
+	return pb
+}
+
+// toPositionPB 将持仓转换为protobuf消息
+func toPositionPB(pos trading.Position) *qhftpb.Position {
+	return &qhftpb.Position{
+		Symbol:        pos.Symbol,
+		Quantity:      pos.Quantity,
+		EntryPrice:    pos.EntryPrice,
+		CurrentPrice:  pos.CurrentPrice,
+		MarketValue:   pos.MarketValue,
+		Cost:          pos.Cost,
+		UnrealizedPnl: pos.UnrealizedPnL,
+		PnlPercent:    pos.PnLPercent,
+		OpenedAt:      toTimestamp(pos.OpenedAt),
+		UpdatedAt:     toTimestamp(pos.UpdatedAt),
+		StopLoss:      pos.StopLoss,
+		TakeProfit:    pos.TakeProfit,
+		Tags:          pos.Tags,
+	}
+}
+
+// toExecutionPB 将成交回报转换为protobuf消息
+func toExecutionPB(exec trading.Execution) *qhftpb.Execution {
+	return &qhftpb.Execution{
+		Id:           exec.ID,
+		OrderId:      exec.OrderID,
+		Symbol:       exec.Symbol,
+		Quantity:     exec.Quantity,
+		Price:        exec.Price,
+		Side:         string(exec.Side),
+		ExecutedAt:   toTimestamp(exec.ExecutedAt),
+		Commission:   exec.Commission,
+		BrokerExecId: exec.BrokerExecID,
+	}
+}
+
+// toAccountPB 将账户转换为protobuf消息
+func toAccountPB(account trading.Account) *qhftpb.Account {
+	return &qhftpb.Account{
+		Id:            account.ID,
+		BrokerId:      account.BrokerID,
+		Cash:          account.Cash,
+		BuyingPower:   account.BuyingPower,
+		Equity:        account.Equity,
+		RealizedPnl:   account.RealizedPnL,
+		UnrealizedPnl: account.UnrealizedPnL,
+		TotalPnl:      account.TotalPnL,
+		DayTradeCount: int32(account.DayTradeCount),
+		UpdatedAt:     toTimestamp(account.UpdatedAt),
+	}
+}
+
+// toQuotePB 将报价转换为protobuf消息
+func toQuotePB(quote datasource.Quote) *qhftpb.Quote {
+	return &qhftpb.Quote{
+		Symbol:    quote.Symbol,
+		Timestamp: toTimestamp(quote.Timestamp),
+		AskPrice:  quote.AskPrice,
+		AskSize:   quote.AskSize,
+		BidPrice:  quote.BidPrice,
+		BidSize:   quote.BidSize,
+		LastPrice: quote.LastPrice,
+		LastSize:  quote.LastSize,
+	}
+}
+
+// toBarPB 将K线数据转换为protobuf消息
+func toBarPB(bar datasource.StockData) *qhftpb.Bar {
+	return &qhftpb.Bar{
+		Symbol:    bar.Symbol,
+		Timestamp: toTimestamp(bar.Timestamp),
+		Open:      bar.Open,
+		High:      bar.High,
+		Low:       bar.Low,
+		Close:     bar.Close,
+		Volume:    bar.Volume,
+		Vwap:      bar.VWAP,
+	}
+}
@@ -0,0 +1,724 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: qhft/v1/qhft.proto
+
+package qhftpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	TradingService_SubmitOrder_FullMethodName        = "/qhft.v1.TradingService/SubmitOrder"
+	TradingService_CancelOrder_FullMethodName        = "/qhft.v1.TradingService/CancelOrder"
+	TradingService_GetOrder_FullMethodName           = "/qhft.v1.TradingService/GetOrder"
+	TradingService_ListOpenOrders_FullMethodName     = "/qhft.v1.TradingService/ListOpenOrders"
+	TradingService_ListPositions_FullMethodName      = "/qhft.v1.TradingService/ListPositions"
+	TradingService_GetAccount_FullMethodName         = "/qhft.v1.TradingService/GetAccount"
+	TradingService_StreamFills_FullMethodName        = "/qhft.v1.TradingService/StreamFills"
+	TradingService_StreamOrderUpdates_FullMethodName = "/qhft.v1.TradingService/StreamOrderUpdates"
+	TradingService_StreamPositions_FullMethodName    = "/qhft.v1.TradingService/StreamPositions"
+)
+
+// TradingServiceClient is the client API for TradingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TradingServiceClient interface {
+	SubmitOrder(ctx context.Context, in *SubmitOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	ListOpenOrders(ctx context.Context, in *ListOpenOrdersRequest, opts ...grpc.CallOption) (*ListOpenOrdersResponse, error)
+	ListPositions(ctx context.Context, in *ListPositionsRequest, opts ...grpc.CallOption) (*ListPositionsResponse, error)
+	GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*Account, error)
+	// StreamFills 推送成交回报
+	StreamFills(ctx context.Context, in *StreamFillsRequest, opts ...grpc.CallOption) (TradingService_StreamFillsClient, error)
+	// StreamOrderUpdates 推送订单状态变化
+	StreamOrderUpdates(ctx context.Context, in *StreamOrderUpdatesRequest, opts ...grpc.CallOption) (TradingService_StreamOrderUpdatesClient, error)
+	// StreamPositions 推送持仓变化
+	StreamPositions(ctx context.Context, in *StreamPositionsRequest, opts ...grpc.CallOption) (TradingService_StreamPositionsClient, error)
+}
+
+type tradingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTradingServiceClient(cc grpc.ClientConnInterface) TradingServiceClient {
+	return &tradingServiceClient{cc}
+}
+
+func (c *tradingServiceClient) SubmitOrder(ctx context.Context, in *SubmitOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, TradingService_SubmitOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingServiceClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error) {
+	out := new(CancelOrderResponse)
+	err := c.cc.Invoke(ctx, TradingService_CancelOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingServiceClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, TradingService_GetOrder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingServiceClient) ListOpenOrders(ctx context.Context, in *ListOpenOrdersRequest, opts ...grpc.CallOption) (*ListOpenOrdersResponse, error) {
+	out := new(ListOpenOrdersResponse)
+	err := c.cc.Invoke(ctx, TradingService_ListOpenOrders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingServiceClient) ListPositions(ctx context.Context, in *ListPositionsRequest, opts ...grpc.CallOption) (*ListPositionsResponse, error) {
+	out := new(ListPositionsResponse)
+	err := c.cc.Invoke(ctx, TradingService_ListPositions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingServiceClient) GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*Account, error) {
+	out := new(Account)
+	err := c.cc.Invoke(ctx, TradingService_GetAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingServiceClient) StreamFills(ctx context.Context, in *StreamFillsRequest, opts ...grpc.CallOption) (TradingService_StreamFillsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TradingService_ServiceDesc.Streams[0], TradingService_StreamFills_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tradingServiceStreamFillsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TradingService_StreamFillsClient interface {
+	Recv() (*Execution, error)
+	grpc.ClientStream
+}
+
+type tradingServiceStreamFillsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tradingServiceStreamFillsClient) Recv() (*Execution, error) {
+	m := new(Execution)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tradingServiceClient) StreamOrderUpdates(ctx context.Context, in *StreamOrderUpdatesRequest, opts ...grpc.CallOption) (TradingService_StreamOrderUpdatesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TradingService_ServiceDesc.Streams[1], TradingService_StreamOrderUpdates_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tradingServiceStreamOrderUpdatesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TradingService_StreamOrderUpdatesClient interface {
+	Recv() (*OrderUpdate, error)
+	grpc.ClientStream
+}
+
+type tradingServiceStreamOrderUpdatesClient struct {
+	grpc.ClientStream
+}
+
+func (x *tradingServiceStreamOrderUpdatesClient) Recv() (*OrderUpdate, error) {
+	m := new(OrderUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *tradingServiceClient) StreamPositions(ctx context.Context, in *StreamPositionsRequest, opts ...grpc.CallOption) (TradingService_StreamPositionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TradingService_ServiceDesc.Streams[2], TradingService_StreamPositions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tradingServiceStreamPositionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TradingService_StreamPositionsClient interface {
+	Recv() (*PositionUpdate, error)
+	grpc.ClientStream
+}
+
+type tradingServiceStreamPositionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tradingServiceStreamPositionsClient) Recv() (*PositionUpdate, error) {
+	m := new(PositionUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TradingServiceServer is the server API for TradingService service.
+// All implementations must embed UnimplementedTradingServiceServer
+// for forward compatibility
+type TradingServiceServer interface {
+	SubmitOrder(context.Context, *SubmitOrderRequest) (*Order, error)
+	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
+	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
+	ListOpenOrders(context.Context, *ListOpenOrdersRequest) (*ListOpenOrdersResponse, error)
+	ListPositions(context.Context, *ListPositionsRequest) (*ListPositionsResponse, error)
+	GetAccount(context.Context, *GetAccountRequest) (*Account, error)
+	// StreamFills 推送成交回报
+	StreamFills(*StreamFillsRequest, TradingService_StreamFillsServer) error
+	// StreamOrderUpdates 推送订单状态变化
+	StreamOrderUpdates(*StreamOrderUpdatesRequest, TradingService_StreamOrderUpdatesServer) error
+	// StreamPositions 推送持仓变化
+	StreamPositions(*StreamPositionsRequest, TradingService_StreamPositionsServer) error
+	mustEmbedUnimplementedTradingServiceServer()
+}
+
+// UnimplementedTradingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedTradingServiceServer struct {
+}
+
+func (UnimplementedTradingServiceServer) SubmitOrder(context.Context, *SubmitOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitOrder not implemented")
+}
+func (UnimplementedTradingServiceServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedTradingServiceServer) GetOrder(context.Context, *GetOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedTradingServiceServer) ListOpenOrders(context.Context, *ListOpenOrdersRequest) (*ListOpenOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOpenOrders not implemented")
+}
+func (UnimplementedTradingServiceServer) ListPositions(context.Context, *ListPositionsRequest) (*ListPositionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPositions not implemented")
+}
+func (UnimplementedTradingServiceServer) GetAccount(context.Context, *GetAccountRequest) (*Account, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccount not implemented")
+}
+func (UnimplementedTradingServiceServer) StreamFills(*StreamFillsRequest, TradingService_StreamFillsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFills not implemented")
+}
+func (UnimplementedTradingServiceServer) StreamOrderUpdates(*StreamOrderUpdatesRequest, TradingService_StreamOrderUpdatesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOrderUpdates not implemented")
+}
+func (UnimplementedTradingServiceServer) StreamPositions(*StreamPositionsRequest, TradingService_StreamPositionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPositions not implemented")
+}
+func (UnimplementedTradingServiceServer) mustEmbedUnimplementedTradingServiceServer() {}
+
+// UnsafeTradingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TradingServiceServer will
+// result in compilation errors.
+type UnsafeTradingServiceServer interface {
+	mustEmbedUnimplementedTradingServiceServer()
+}
+
+func RegisterTradingServiceServer(s grpc.ServiceRegistrar, srv TradingServiceServer) {
+	s.RegisterService(&TradingService_ServiceDesc, srv)
+}
+
+func _TradingService_SubmitOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServiceServer).SubmitOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingService_SubmitOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServiceServer).SubmitOrder(ctx, req.(*SubmitOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingService_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServiceServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingService_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServiceServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingService_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServiceServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingService_GetOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServiceServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingService_ListOpenOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOpenOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServiceServer).ListOpenOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingService_ListOpenOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServiceServer).ListOpenOrders(ctx, req.(*ListOpenOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingService_ListPositions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPositionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServiceServer).ListPositions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingService_ListPositions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServiceServer).ListPositions(ctx, req.(*ListPositionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingService_GetAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingServiceServer).GetAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingService_GetAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingServiceServer).GetAccount(ctx, req.(*GetAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingService_StreamFills_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamFillsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TradingServiceServer).StreamFills(m, &tradingServiceStreamFillsServer{stream})
+}
+
+type TradingService_StreamFillsServer interface {
+	Send(*Execution) error
+	grpc.ServerStream
+}
+
+type tradingServiceStreamFillsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tradingServiceStreamFillsServer) Send(m *Execution) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TradingService_StreamOrderUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamOrderUpdatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TradingServiceServer).StreamOrderUpdates(m, &tradingServiceStreamOrderUpdatesServer{stream})
+}
+
+type TradingService_StreamOrderUpdatesServer interface {
+	Send(*OrderUpdate) error
+	grpc.ServerStream
+}
+
+type tradingServiceStreamOrderUpdatesServer struct {
+	grpc.ServerStream
+}
+
+func (x *tradingServiceStreamOrderUpdatesServer) Send(m *OrderUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TradingService_StreamPositions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamPositionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TradingServiceServer).StreamPositions(m, &tradingServiceStreamPositionsServer{stream})
+}
+
+type TradingService_StreamPositionsServer interface {
+	Send(*PositionUpdate) error
+	grpc.ServerStream
+}
+
+type tradingServiceStreamPositionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tradingServiceStreamPositionsServer) Send(m *PositionUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// TradingService_ServiceDesc is the grpc.ServiceDesc for TradingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TradingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "qhft.v1.TradingService",
+	HandlerType: (*TradingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitOrder",
+			Handler:    _TradingService_SubmitOrder_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _TradingService_CancelOrder_Handler,
+		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _TradingService_GetOrder_Handler,
+		},
+		{
+			MethodName: "ListOpenOrders",
+			Handler:    _TradingService_ListOpenOrders_Handler,
+		},
+		{
+			MethodName: "ListPositions",
+			Handler:    _TradingService_ListPositions_Handler,
+		},
+		{
+			MethodName: "GetAccount",
+			Handler:    _TradingService_GetAccount_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFills",
+			Handler:       _TradingService_StreamFills_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamOrderUpdates",
+			Handler:       _TradingService_StreamOrderUpdates_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamPositions",
+			Handler:       _TradingService_StreamPositions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "qhft/v1/qhft.proto",
+}
+
+const (
+	MarketDataService_GetStockData_FullMethodName    = "/qhft.v1.MarketDataService/GetStockData"
+	MarketDataService_GetQuote_FullMethodName        = "/qhft.v1.MarketDataService/GetQuote"
+	MarketDataService_HealthCheck_FullMethodName     = "/qhft.v1.MarketDataService/HealthCheck"
+	MarketDataService_SubscribeQuotes_FullMethodName = "/qhft.v1.MarketDataService/SubscribeQuotes"
+)
+
+// MarketDataServiceClient is the client API for MarketDataService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MarketDataServiceClient interface {
+	GetStockData(ctx context.Context, in *GetStockDataRequest, opts ...grpc.CallOption) (*GetStockDataResponse, error)
+	GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*Quote, error)
+	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// SubscribeQuotes 推送订阅股票的实时报价
+	SubscribeQuotes(ctx context.Context, in *SubscribeQuotesRequest, opts ...grpc.CallOption) (MarketDataService_SubscribeQuotesClient, error)
+}
+
+type marketDataServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMarketDataServiceClient(cc grpc.ClientConnInterface) MarketDataServiceClient {
+	return &marketDataServiceClient{cc}
+}
+
+func (c *marketDataServiceClient) GetStockData(ctx context.Context, in *GetStockDataRequest, opts ...grpc.CallOption) (*GetStockDataResponse, error) {
+	out := new(GetStockDataResponse)
+	err := c.cc.Invoke(ctx, MarketDataService_GetStockData_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataServiceClient) GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*Quote, error) {
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, MarketDataService_GetQuote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
+	out := new(HealthCheckResponse)
+	err := c.cc.Invoke(ctx, MarketDataService_HealthCheck_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataServiceClient) SubscribeQuotes(ctx context.Context, in *SubscribeQuotesRequest, opts ...grpc.CallOption) (MarketDataService_SubscribeQuotesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MarketDataService_ServiceDesc.Streams[0], MarketDataService_SubscribeQuotes_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &marketDataServiceSubscribeQuotesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MarketDataService_SubscribeQuotesClient interface {
+	Recv() (*Quote, error)
+	grpc.ClientStream
+}
+
+type marketDataServiceSubscribeQuotesClient struct {
+	grpc.ClientStream
+}
+
+func (x *marketDataServiceSubscribeQuotesClient) Recv() (*Quote, error) {
+	m := new(Quote)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MarketDataServiceServer is the server API for MarketDataService service.
+// All implementations must embed UnimplementedMarketDataServiceServer
+// for forward compatibility
+type MarketDataServiceServer interface {
+	GetStockData(context.Context, *GetStockDataRequest) (*GetStockDataResponse, error)
+	GetQuote(context.Context, *GetQuoteRequest) (*Quote, error)
+	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	// SubscribeQuotes 推送订阅股票的实时报价
+	SubscribeQuotes(*SubscribeQuotesRequest, MarketDataService_SubscribeQuotesServer) error
+	mustEmbedUnimplementedMarketDataServiceServer()
+}
+
+// UnimplementedMarketDataServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedMarketDataServiceServer struct {
+}
+
+func (UnimplementedMarketDataServiceServer) GetStockData(context.Context, *GetStockDataRequest) (*GetStockDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStockData not implemented")
+}
+func (UnimplementedMarketDataServiceServer) GetQuote(context.Context, *GetQuoteRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuote not implemented")
+}
+func (UnimplementedMarketDataServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
+}
+func (UnimplementedMarketDataServiceServer) SubscribeQuotes(*SubscribeQuotesRequest, MarketDataService_SubscribeQuotesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeQuotes not implemented")
+}
+func (UnimplementedMarketDataServiceServer) mustEmbedUnimplementedMarketDataServiceServer() {}
+
+// UnsafeMarketDataServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MarketDataServiceServer will
+// result in compilation errors.
+type UnsafeMarketDataServiceServer interface {
+	mustEmbedUnimplementedMarketDataServiceServer()
+}
+
+func RegisterMarketDataServiceServer(s grpc.ServiceRegistrar, srv MarketDataServiceServer) {
+	s.RegisterService(&MarketDataService_ServiceDesc, srv)
+}
+
+func _MarketDataService_GetStockData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStockDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServiceServer).GetStockData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketDataService_GetStockData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServiceServer).GetStockData(ctx, req.(*GetStockDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketDataService_GetQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServiceServer).GetQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketDataService_GetQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServiceServer).GetQuote(ctx, req.(*GetQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketDataService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServiceServer).HealthCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketDataService_HealthCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServiceServer).HealthCheck(ctx, req.(*HealthCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketDataService_SubscribeQuotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeQuotesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketDataServiceServer).SubscribeQuotes(m, &marketDataServiceSubscribeQuotesServer{stream})
+}
+
+type MarketDataService_SubscribeQuotesServer interface {
+	Send(*Quote) error
+	grpc.ServerStream
+}
+
+type marketDataServiceSubscribeQuotesServer struct {
+	grpc.ServerStream
+}
+
+func (x *marketDataServiceSubscribeQuotesServer) Send(m *Quote) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// MarketDataService_ServiceDesc is the grpc.ServiceDesc for MarketDataService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MarketDataService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "qhft.v1.MarketDataService",
+	HandlerType: (*MarketDataServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStockData",
+			Handler:    _MarketDataService_GetStockData_Handler,
+		},
+		{
+			MethodName: "GetQuote",
+			Handler:    _MarketDataService_GetQuote_Handler,
+		},
+		{
+			MethodName: "HealthCheck",
+			Handler:    _MarketDataService_HealthCheck_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeQuotes",
+			Handler:       _MarketDataService_SubscribeQuotes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "qhft/v1/qhft.proto",
+}
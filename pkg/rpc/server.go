@@ -0,0 +1,387 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/rpc/qhftpb"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// 默认的报价推送间隔
+const defaultQuoteInterval = time.Second
+
+// 客户端可以请求的最短报价推送间隔，更短的间隔按此值处理，避免订阅方以过高的频率轮询数据源
+const minQuoteInterval = 100 * time.Millisecond
+
+// Server 实现交易服务和行情服务的gRPC接口
+type Server struct {
+	qhftpb.UnimplementedTradingServiceServer
+	qhftpb.UnimplementedMarketDataServiceServer
+
+	engine        trading.TradingEngine
+	dataManager   *datasource.Manager
+	bus           *events.Bus
+	quoteInterval time.Duration
+}
+
+// NewServer 创建一个新的gRPC服务
+// bus用于订阅引擎发布的成交、订单和持仓事件，应与引擎使用同一个事件总线
+func NewServer(engine trading.TradingEngine, dataManager *datasource.Manager, bus *events.Bus) *Server {
+	return &Server{
+		engine:        engine,
+		dataManager:   dataManager,
+		bus:           bus,
+		quoteInterval: defaultQuoteInterval,
+	}
+}
+
+// SetQuoteInterval 设置报价订阅的默认推送间隔
+func (s *Server) SetQuoteInterval(interval time.Duration) {
+	if interval > 0 {
+		s.quoteInterval = interval
+	}
+}
+
+// Register 将服务注册到gRPC服务器
+func (s *Server) Register(grpcServer *grpc.Server) {
+	qhftpb.RegisterTradingServiceServer(grpcServer, s)
+	qhftpb.RegisterMarketDataServiceServer(grpcServer, s)
+}
+
+// Serve 在指定地址上启动gRPC服务，直到上下文取消
+func (s *Server) Serve(ctx context.Context, addr string, opts ...grpc.ServerOption) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	s.Register(grpcServer)
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+
+	if err := grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// SubmitOrder 提交订单
+func (s *Server) SubmitOrder(ctx context.Context, req *qhftpb.SubmitOrderRequest) (*qhftpb.Order, error) {
+	order, err := s.engine.PlaceOrder(ctx, trading.OrderRequest{
+		Symbol:        req.GetSymbol(),
+		Quantity:      req.GetQuantity(),
+		Price:         req.GetPrice(),
+		StopPrice:     req.GetStopPrice(),
+		Type:          trading.OrderType(req.GetType()),
+		Side:          trading.OrderSide(req.GetSide()),
+		Strategy:      req.GetStrategy(),
+		ClientOrderID: req.GetClientOrderId(),
+		Tags:          req.GetTags(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toOrderPB(*order), nil
+}
+
+// CancelOrder 取消订单
+func (s *Server) CancelOrder(ctx context.Context, req *qhftpb.CancelOrderRequest) (*qhftpb.CancelOrderResponse, error) {
+	if err := s.engine.CancelOrder(ctx, req.GetOrderId()); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &qhftpb.CancelOrderResponse{}, nil
+}
+
+// GetOrder 获取订单
+func (s *Server) GetOrder(ctx context.Context, req *qhftpb.GetOrderRequest) (*qhftpb.Order, error) {
+	order, err := s.engine.GetOrder(ctx, req.GetOrderId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toOrderPB(*order), nil
+}
+
+// ListOpenOrders 获取所有未成交订单
+func (s *Server) ListOpenOrders(ctx context.Context, req *qhftpb.ListOpenOrdersRequest) (*qhftpb.ListOpenOrdersResponse, error) {
+	orders, err := s.engine.GetOpenOrders(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &qhftpb.ListOpenOrdersResponse{Orders: make([]*qhftpb.Order, 0, len(orders))}
+	for _, order := range orders {
+		resp.Orders = append(resp.Orders, toOrderPB(order))
+	}
+	return resp, nil
+}
+
+// ListPositions 获取所有持仓
+func (s *Server) ListPositions(ctx context.Context, req *qhftpb.ListPositionsRequest) (*qhftpb.ListPositionsResponse, error) {
+	positions, err := s.engine.GetPositions(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &qhftpb.ListPositionsResponse{Positions: make([]*qhftpb.Position, 0, len(positions))}
+	for _, pos := range positions {
+		resp.Positions = append(resp.Positions, toPositionPB(pos))
+	}
+	return resp, nil
+}
+
+// GetAccount 获取账户信息
+func (s *Server) GetAccount(ctx context.Context, req *qhftpb.GetAccountRequest) (*qhftpb.Account, error) {
+	account, err := s.engine.GetAccount(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toAccountPB(*account), nil
+}
+
+// StreamFills 推送成交回报
+func (s *Server) StreamFills(req *qhftpb.StreamFillsRequest, stream qhftpb.TradingService_StreamFillsServer) error {
+	symbols := symbolSet(req.GetSymbols())
+	return s.streamEvents(stream.Context(), events.TopicFills, func(evt events.Event) error {
+		exec, ok := evt.Payload.(trading.Execution)
+		if !ok || !symbols.match(exec.Symbol) {
+			return nil
+		}
+		return stream.Send(toExecutionPB(exec))
+	})
+}
+
+// StreamOrderUpdates 推送订单状态变化
+func (s *Server) StreamOrderUpdates(req *qhftpb.StreamOrderUpdatesRequest, stream qhftpb.TradingService_StreamOrderUpdatesServer) error {
+	symbols := symbolSet(req.GetSymbols())
+	return s.streamEvents(stream.Context(), events.TopicOrders, func(evt events.Event) error {
+		update := &qhftpb.OrderUpdate{
+			EventType: evt.Type,
+			Timestamp: toTimestamp(evt.Timestamp),
+		}
+
+		switch payload := evt.Payload.(type) {
+		case trading.Order:
+			if !symbols.match(payload.Symbol) {
+				return nil
+			}
+			update.Order = toOrderPB(payload)
+		case trading.OrderRejection:
+			if !symbols.match(payload.Request.Symbol) {
+				return nil
+			}
+			update.Order = &qhftpb.Order{
+				Symbol:        payload.Request.Symbol,
+				Quantity:      payload.Request.Quantity,
+				Price:         payload.Request.Price,
+				StopPrice:     payload.Request.StopPrice,
+				Type:          string(payload.Request.Type),
+				Side:          string(payload.Request.Side),
+				Status:        string(trading.OrderStatusRejected),
+				RejectReason:  payload.Reason,
+				ClientOrderId: payload.Request.ClientOrderID,
+				Strategy:      payload.Request.Strategy,
+				Tags:          payload.Request.Tags,
+			}
+			update.RejectCode = string(payload.Code)
+			update.RejectReason = payload.Reason
+		default:
+			return nil
+		}
+
+		return stream.Send(update)
+	})
+}
+
+// StreamPositions 推送持仓变化
+func (s *Server) StreamPositions(req *qhftpb.StreamPositionsRequest, stream qhftpb.TradingService_StreamPositionsServer) error {
+	symbols := symbolSet(req.GetSymbols())
+	return s.streamEvents(stream.Context(), events.TopicPositions, func(evt events.Event) error {
+		pos, ok := evt.Payload.(trading.Position)
+		if !ok || !symbols.match(pos.Symbol) {
+			return nil
+		}
+		return stream.Send(&qhftpb.PositionUpdate{
+			EventType: evt.Type,
+			Position:  toPositionPB(pos),
+			Timestamp: toTimestamp(evt.Timestamp),
+		})
+	})
+}
+
+// GetStockData 获取股票K线数据
+func (s *Server) GetStockData(ctx context.Context, req *qhftpb.GetStockDataRequest) (*qhftpb.GetStockDataResponse, error) {
+	if req.GetSymbol() == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+
+	data, err := s.dataManager.GetStockData(ctx, req.GetSymbol(), req.GetTimeframe(), req.GetFrom().AsTime(), req.GetTo().AsTime())
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	resp := &qhftpb.GetStockDataResponse{Bars: make([]*qhftpb.Bar, 0, len(data))}
+	for _, bar := range data {
+		resp.Bars = append(resp.Bars, toBarPB(bar))
+	}
+	return resp, nil
+}
+
+// GetQuote 获取实时报价
+func (s *Server) GetQuote(ctx context.Context, req *qhftpb.GetQuoteRequest) (*qhftpb.Quote, error) {
+	quote, err := s.fetchQuote(ctx, req.GetSymbol())
+	if err != nil {
+		return nil, err
+	}
+	return toQuotePB(*quote), nil
+}
+
+// HealthCheck 检查所有数据源的健康状态
+func (s *Server) HealthCheck(ctx context.Context, req *qhftpb.HealthCheckRequest) (*qhftpb.HealthCheckResponse, error) {
+	results := s.dataManager.HealthCheckAll(ctx)
+
+	resp := &qhftpb.HealthCheckResponse{Sources: make(map[string]string, len(results))}
+	for name, err := range results {
+		if err != nil {
+			resp.Sources[name] = err.Error()
+		} else {
+			resp.Sources[name] = ""
+		}
+	}
+	return resp, nil
+}
+
+// SubscribeQuotes 按固定间隔推送订阅股票的最新报价，报价未变化时不重复推送
+// 请求的间隔不能短于 minQuoteInterval
+func (s *Server) SubscribeQuotes(req *qhftpb.SubscribeQuotesRequest, stream qhftpb.MarketDataService_SubscribeQuotesServer) error {
+	if len(req.GetSymbols()) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one symbol is required")
+	}
+
+	interval := s.subscribeInterval(req.GetIntervalMs())
+	ctx := stream.Context()
+	lastSent := make(map[string]time.Time, len(req.GetSymbols()))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, symbol := range req.GetSymbols() {
+			quote, err := s.fetchQuote(ctx, symbol)
+			if err != nil {
+				continue // 单只股票获取失败不中断订阅
+			}
+			if !quote.Timestamp.After(lastSent[symbol]) {
+				continue
+			}
+			if err := stream.Send(toQuotePB(*quote)); err != nil {
+				return err
+			}
+			lastSent[symbol] = quote.Timestamp
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// subscribeInterval 返回报价订阅的推送间隔，未指定时使用默认间隔，过短时按最短间隔处理（内部方法）
+func (s *Server) subscribeInterval(ms int64) time.Duration {
+	if ms <= 0 {
+		return s.quoteInterval
+	}
+	return max(time.Duration(ms)*time.Millisecond, minQuoteInterval)
+}
+
+// fetchQuote 通过数据源管理器获取报价
+func (s *Server) fetchQuote(ctx context.Context, symbol string) (*datasource.Quote, error) {
+	if symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+
+	quote, err := s.dataManager.GetRealTimeQuote(ctx, symbol)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return quote, nil
+}
+
+// streamEvents 订阅事件总线上的指定主题并逐个处理，直到客户端断开
+func (s *Server) streamEvents(ctx context.Context, topic string, handle func(evt events.Event) error) error {
+	if s.bus == nil {
+		return status.Error(codes.Unimplemented, "event bus is not configured")
+	}
+
+	sub := s.bus.Subscribe(256, topic)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case evt, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if err := handle(evt); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatusError 将交易引擎的错误转换为gRPC状态错误
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, trading.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, trading.ErrTradeDisabled):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	var rejErr *trading.RejectionError
+	if errors.As(err, &rejErr) {
+		switch rejErr.Code {
+		case trading.RejectCodeInvalidParams:
+			return status.Error(codes.InvalidArgument, err.Error())
+		case trading.RejectCodeRiskLimit:
+			return status.Error(codes.ResourceExhausted, err.Error())
+		default:
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+// symbolFilter 表示股票代码过滤集合，为空时匹配所有股票
+type symbolFilter map[string]bool
+
+// symbolSet 根据股票代码列表创建过滤集合
+func symbolSet(symbols []string) symbolFilter {
+	filter := make(symbolFilter, len(symbols))
+	for _, symbol := range symbols {
+		filter[symbol] = true
+	}
+	return filter
+}
+
+// match 检查股票代码是否在过滤集合中
+func (f symbolFilter) match(symbol string) bool {
+	return len(f) == 0 || f[symbol]
+}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/rpc/qhftpb"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// startServer 在内存连接上启动gRPC服务，返回连接到该服务的客户端连接和模拟数据源（内部函数）
+func startServer(t *testing.T) (*grpc.ClientConn, *testutil.MockDataSource) {
+	t.Helper()
+	source := testutil.NewMockDataSource("mock")
+	manager := datasource.NewManager()
+	manager.AddDataSource(source)
+	broker := testutil.NewMockBroker("mock")
+	broker.SetPrice("AAPL", 150)
+	bus := events.NewBus()
+
+	engine := trading.NewBaseTradingEngine(manager, trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	NewServer(engine, manager, bus).Register(grpcServer)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("连接gRPC服务失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, source
+}
+
+func TestSubmitOrderRPC(t *testing.T) {
+	ctx := context.Background()
+	conn, _ := startServer(t)
+	client := qhftpb.NewTradingServiceClient(conn)
+
+	order, err := client.SubmitOrder(ctx, &qhftpb.SubmitOrderRequest{Symbol: "AAPL", Quantity: 10, Type: "market", Side: "buy", ClientOrderId: "c1"})
+	if err != nil {
+		t.Fatalf("提交订单失败: %v", err)
+	}
+	if order.GetStatus() != string(trading.OrderStatusFilled) || order.GetFilledQty() != 10 || order.GetAvgFillPrice() != 150 || order.GetClientOrderId() != "c1" {
+		t.Errorf("返回的订单不正确: %+v", order)
+	}
+
+	// 拒单映射为对应的gRPC状态码
+	if _, err := client.SubmitOrder(ctx, &qhftpb.SubmitOrderRequest{Symbol: "AAPL", Quantity: 0, Type: "market", Side: "buy"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("数量为0的订单应返回 InvalidArgument: %v", err)
+	}
+	if _, err := client.GetOrder(ctx, &qhftpb.GetOrderRequest{OrderId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("不存在的订单应返回 NotFound: %v", err)
+	}
+}
+
+func TestSubscribeQuotesRPC(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn, source := startServer(t)
+	client := qhftpb.NewMarketDataServiceClient(conn)
+
+	now := time.Now()
+	source.SetQuote(datasource.Quote{Symbol: "AAPL", Timestamp: now, LastPrice: 150})
+
+	// 请求的间隔过短时按最短间隔推送
+	start := time.Now()
+	stream, err := client.SubscribeQuotes(ctx, &qhftpb.SubscribeQuotesRequest{Symbols: []string{"AAPL"}, IntervalMs: 1})
+	if err != nil {
+		t.Fatalf("订阅报价失败: %v", err)
+	}
+	first, err := stream.Recv()
+	if err != nil || first.GetSymbol() != "AAPL" || first.GetLastPrice() != 150 {
+		t.Fatalf("首次推送的报价不正确: %+v %v", first, err)
+	}
+
+	source.SetQuote(datasource.Quote{Symbol: "AAPL", Timestamp: now.Add(time.Second), LastPrice: 151})
+	second, err := stream.Recv()
+	if err != nil || second.GetLastPrice() != 151 {
+		t.Fatalf("报价变化后应推送新报价: %+v %v", second, err)
+	}
+	if elapsed := time.Since(start); elapsed < minQuoteInterval {
+		t.Errorf("推送间隔 %v 短于最短间隔 %v", elapsed, minQuoteInterval)
+	}
+
+	// 没有股票的订阅被拒绝
+	empty, err := client.SubscribeQuotes(ctx, &qhftpb.SubscribeQuotesRequest{})
+	if err == nil {
+		_, err = empty.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("没有股票的订阅应返回 InvalidArgument: %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package scanexport
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// 得分归一化方式常量
+const (
+	NormalizeNone       = ""           // 使用原始得分
+	NormalizeZScore     = "zscore"     // 策略内的标准分
+	NormalizePercentile = "percentile" // 策略内的百分位，0到1
+)
+
+// 平分时的比较依据常量
+const (
+	TieBreakLiquidity = "liquidity" // 平均日成交额
+	TieBreakRVOL      = "rvol"      // 相对成交量：最新日成交量与之前平均日成交量之比
+)
+
+// EventScanRanked 是扫描运行器在每轮扫描后发布排名结果的事件类型
+const EventScanRanked = "scan_ranked"
+
+// Ranking 表示一轮扫描所有策略的候选排名
+type Ranking struct {
+	CompletedAt time.Time         `json:"completed_at"`
+	Candidates  []RankedCandidate `json:"candidates"`
+}
+
+// RankOptions 表示候选排名的配置，零值字段使用默认值
+type RankOptions struct {
+	Enabled      bool     `json:"enabled" yaml:"enabled"`             // 扫描运行器在每轮扫描后对所有策略的候选排名
+	Normalize    string   `json:"normalize" yaml:"normalize"`         // zscore、percentile，为空时使用原始得分
+	TieBreakers  []string `json:"tie_breakers" yaml:"tie_breakers"`   // 得分相同时依次比较，liquidity、rvol，越高越靠前
+	LookbackDays int      `json:"lookback_days" yaml:"lookback_days"` // 计算成交额和相对成交量的日线数，默认20
+	Limit        int      `json:"limit" yaml:"limit"`                 // 只返回排名靠前的候选，为0时返回全部
+}
+
+// Validate 检查排名配置是否有效
+func (o RankOptions) Validate() error {
+	switch o.Normalize {
+	case NormalizeNone, NormalizeZScore, NormalizePercentile:
+	default:
+		return fmt.Errorf("unsupported normalization %q", o.Normalize)
+	}
+	for _, tb := range o.TieBreakers {
+		if tb != TieBreakLiquidity && tb != TieBreakRVOL {
+			return fmt.Errorf("unsupported tie-breaker %q", tb)
+		}
+	}
+	if o.LookbackDays < 0 || o.Limit < 0 {
+		return fmt.Errorf("lookback_days and limit must not be negative")
+	}
+	return nil
+}
+
+// Contribution 表示一个触发的条件对候选得分的贡献
+type Contribution struct {
+	Indicator string  `json:"indicator"`
+	Condition string  `json:"condition"`
+	Score     float64 `json:"score"` // 该条件贡献的原始得分
+	Share     float64 `json:"share"` // 占候选原始得分的比例
+}
+
+// RankedCandidate 表示排名后的一只候选股票
+type RankedCandidate struct {
+	Rank          int            `json:"rank"` // 从1开始
+	Symbol        string         `json:"symbol"`
+	Strategy      string         `json:"strategy"`             // 归一化得分最高的策略
+	Strategies    []string       `json:"strategies,omitempty"` // 所有选出该股票的策略
+	Side          string         `json:"side"`                 // buy 或 sell
+	RawScore      float64        `json:"raw_score"`
+	Score         float64        `json:"score"` // 归一化后的得分，用于排名
+	Liquidity     float64        `json:"liquidity,omitempty"`
+	RVOL          float64        `json:"rvol,omitempty"`
+	Contributions []Contribution `json:"contributions"`
+	Explanation   string         `json:"explanation"`
+}
+
+// Rank 合并多个策略的扫描报告，得到按得分排序的候选列表。不同策略的得分范围不同，
+// 按 opts.Normalize 在每个策略内归一化后再比较；同一股票被多个策略选出时保留归一化得分最高的一项。
+// 得分相同时按 opts.TieBreakers 依次比较，需要的日线从dataManager获取，获取失败时该项指标为0
+func Rank(ctx context.Context, dataManager *datasource.Manager, reports []Report, opts RankOptions) ([]RankedCandidate, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.LookbackDays <= 0 {
+		opts.LookbackDays = 20
+	}
+
+	best := make(map[string]*RankedCandidate)
+	for _, report := range reports {
+		scores := make([]float64, len(report.Candidates))
+		for i, candidate := range report.Candidates {
+			scores[i] = candidate.Score()
+		}
+		normalized := normalizeScores(scores, opts.Normalize)
+
+		for i, candidate := range report.Candidates {
+			ranked := newRankedCandidate(report.Strategy, candidate, normalized[i])
+			existing, ok := best[candidate.Symbol]
+			if !ok {
+				best[candidate.Symbol] = &ranked
+				continue
+			}
+			strategies := append(existing.Strategies, report.Strategy)
+			if ranked.Score > existing.Score {
+				*existing = ranked
+			}
+			existing.Strategies = strategies
+		}
+	}
+
+	candidates := make([]RankedCandidate, 0, len(best))
+	for _, candidate := range best {
+		candidates = append(candidates, *candidate)
+	}
+
+	if len(opts.TieBreakers) > 0 && dataManager != nil {
+		to := time.Now()
+		// 多取的天数覆盖周末和节假日
+		from := to.AddDate(0, 0, -(opts.LookbackDays*2 + 10))
+		for i := range candidates {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			bars, err := dataManager.GetStockData(ctx, candidates[i].Symbol, "day", from, to)
+			if err != nil {
+				continue
+			}
+			candidates[i].Liquidity, candidates[i].RVOL = liquidityMetrics(bars, opts.LookbackDays)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		for _, tb := range opts.TieBreakers {
+			va, vb := a.Liquidity, b.Liquidity
+			if tb == TieBreakRVOL {
+				va, vb = a.RVOL, b.RVOL
+			}
+			if va != vb {
+				return va > vb
+			}
+		}
+		if a.RawScore != b.RawScore {
+			return a.RawScore > b.RawScore
+		}
+		return a.Symbol < b.Symbol
+	})
+	if opts.Limit > 0 && len(candidates) > opts.Limit {
+		candidates = candidates[:opts.Limit]
+	}
+	for i := range candidates {
+		candidates[i].Rank = i + 1
+		candidates[i].Explanation = explain(candidates[i], opts)
+	}
+	return candidates, nil
+}
+
+// newRankedCandidate 根据候选的信号计算方向和各条件的贡献（内部函数）
+func newRankedCandidate(strategy string, candidate Candidate, score float64) RankedCandidate {
+	ranked := RankedCandidate{
+		Symbol:     candidate.Symbol,
+		Strategy:   strategy,
+		Strategies: []string{strategy},
+		Side:       "buy",
+		RawScore:   candidate.BuyScore,
+		Score:      score,
+	}
+	if candidate.SellScore > candidate.BuyScore {
+		ranked.Side, ranked.RawScore = "sell", candidate.SellScore
+	}
+	for _, signal := range candidate.Signals {
+		if (ranked.Side == "buy" && !signal.IsBuySignal) || (ranked.Side == "sell" && !signal.IsSellSignal) {
+			continue
+		}
+		contribution := Contribution{Indicator: signal.IndicatorName, Condition: signal.Condition, Score: signal.Score}
+		if ranked.RawScore > 0 {
+			contribution.Share = signal.Score / ranked.RawScore
+		}
+		ranked.Contributions = append(ranked.Contributions, contribution)
+	}
+	sort.SliceStable(ranked.Contributions, func(i, j int) bool {
+		return ranked.Contributions[i].Score > ranked.Contributions[j].Score
+	})
+	return ranked
+}
+
+// normalizeScores 在一个策略的候选内归一化得分（内部函数）
+func normalizeScores(scores []float64, method string) []float64 {
+	n := len(scores)
+	normalized := make([]float64, n)
+	switch method {
+	case NormalizeZScore:
+		var mean, variance float64
+		for _, s := range scores {
+			mean += s
+		}
+		mean /= float64(n)
+		for _, s := range scores {
+			variance += (s - mean) * (s - mean)
+		}
+		std := math.Sqrt(variance / float64(n))
+		for i, s := range scores {
+			if std > 0 {
+				normalized[i] = (s - mean) / std
+			}
+		}
+	case NormalizePercentile:
+		// 得分相同的候选取相同的平均百分位，只有一个候选时为1
+		for i, s := range scores {
+			if n == 1 {
+				normalized[i] = 1
+				continue
+			}
+			below, equal := 0, 0
+			for _, other := range scores {
+				if other < s {
+					below++
+				} else if other == s {
+					equal++
+				}
+			}
+			normalized[i] = (float64(below) + float64(equal-1)/2) / float64(n-1)
+		}
+	default:
+		copy(normalized, scores)
+	}
+	return normalized
+}
+
+// liquidityMetrics 计算最近lookback根日线的平均成交额，以及最新日成交量与之前lookback根日线平均成交量之比（内部函数）
+func liquidityMetrics(bars []datasource.StockData, lookback int) (liquidity, rvol float64) {
+	if len(bars) == 0 {
+		return 0, 0
+	}
+	recent := bars[max(0, len(bars)-lookback):]
+	for _, bar := range recent {
+		liquidity += bar.Close * float64(bar.Volume)
+	}
+	liquidity /= float64(len(recent))
+
+	prior := bars[max(0, len(bars)-1-lookback) : len(bars)-1]
+	if len(prior) > 0 {
+		var volume float64
+		for _, bar := range prior {
+			volume += float64(bar.Volume)
+		}
+		if volume > 0 {
+			rvol = float64(bars[len(bars)-1].Volume) / (volume / float64(len(prior)))
+		}
+	}
+	return liquidity, rvol
+}
+
+// explain 生成候选得分的说明，如 "buy 0.75 (zscore 1.22) via momentum: RSI oversold 67%, MACD cross_above 33%; liquidity 1.2e+07"（内部函数）
+func explain(c RankedCandidate, opts RankOptions) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %.4g", c.Side, c.RawScore)
+	if opts.Normalize != NormalizeNone {
+		fmt.Fprintf(&b, " (%s %.4g)", opts.Normalize, c.Score)
+	}
+	fmt.Fprintf(&b, " via %s", c.Strategy)
+	for i, contribution := range c.Contributions {
+		sep := ", "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&b, "%s%s %s %.0f%%", sep, contribution.Indicator, contribution.Condition, contribution.Share*100)
+	}
+	for i, tb := range opts.TieBreakers {
+		sep := ", "
+		if i == 0 {
+			sep = "; "
+		}
+		value := c.Liquidity
+		if tb == TieBreakRVOL {
+			value = c.RVOL
+		}
+		fmt.Fprintf(&b, "%s%s %.3g", sep, tb, value)
+	}
+	var others []string
+	for _, strategy := range c.Strategies {
+		if strategy != c.Strategy {
+			others = append(others, strategy)
+		}
+	}
+	if len(others) > 0 {
+		fmt.Fprintf(&b, "; also %s", strings.Join(others, ", "))
+	}
+	return b.String()
+}
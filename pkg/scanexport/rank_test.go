@@ -0,0 +1,101 @@
+package scanexport
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+)
+
+// buyCandidate 返回由若干买入信号组成的候选（内部函数）
+func buyCandidate(symbol string, scores ...float64) Candidate {
+	candidate := Candidate{Symbol: symbol}
+	for i, score := range scores {
+		candidate.Signals = append(candidate.Signals, indicators.ScanResult{
+			Symbol: symbol, IndicatorName: []string{"RSI", "MACD", "ROC"}[i], Condition: "cross_above", IsBuySignal: true, Score: score,
+		})
+		candidate.BuyScore += score
+	}
+	return candidate
+}
+
+func TestRank(t *testing.T) {
+	start := time.Now().AddDate(0, 0, -30)
+	source := testutil.NewMockDataSource("")
+	for symbol, price := range map[string]float64{"NVDA": 100, "AAPL": 200, "AMD": 50, "TSLA": 150} {
+		source.SetBars(symbol, "day", testutil.GenerateBars(symbol, start, 24*time.Hour, 20, price, 0))
+	}
+
+	reports := []Report{
+		{Strategy: "momentum", Candidates: []Candidate{buyCandidate("NVDA", 0.6, 0.3), buyCandidate("AMD", 0.5), buyCandidate("TSLA", 0.5)}},
+		{Strategy: "reversion", Candidates: []Candidate{buyCandidate("AAPL", 0.2), buyCandidate("NVDA", 0.1)}},
+	}
+
+	// 百分位：NVDA 在 momentum 中为1，AAPL 在 reversion 中为1，AMD 和 TSLA 为0.25；平分时先比较RVOL（都为1）再比较成交额
+	ranked, err := Rank(context.Background(), testutil.NewManager(source), reports, RankOptions{
+		Normalize:   NormalizePercentile,
+		TieBreakers: []string{TieBreakRVOL, TieBreakLiquidity},
+	})
+	if err != nil {
+		t.Fatalf("排名失败: %v", err)
+	}
+	want := []string{"AAPL", "NVDA", "TSLA", "AMD"}
+	if len(ranked) != len(want) {
+		t.Fatalf("候选数 = %d, 期望 %d: %+v", len(ranked), len(want), ranked)
+	}
+	for i, symbol := range want {
+		if ranked[i].Symbol != symbol || ranked[i].Rank != i+1 {
+			t.Errorf("第%d名 = %s, 期望 %s", i+1, ranked[i].Symbol, symbol)
+		}
+	}
+
+	nvda := ranked[1]
+	if nvda.Strategy != "momentum" || len(nvda.Strategies) != 2 || nvda.Score != 1 || math.Abs(nvda.RawScore-0.9) > 1e-9 {
+		t.Errorf("NVDA 应保留 momentum 的得分: %+v", nvda)
+	}
+	if len(nvda.Contributions) != 2 || nvda.Contributions[0].Indicator != "RSI" || math.Abs(nvda.Contributions[0].Share-2.0/3) > 1e-9 {
+		t.Errorf("贡献不正确: %+v", nvda.Contributions)
+	}
+	if nvda.Liquidity != 100*1000 || nvda.RVOL != 1 {
+		t.Errorf("成交额 = %v, RVOL = %v, 期望 100000 和 1", nvda.Liquidity, nvda.RVOL)
+	}
+	for _, part := range []string{"buy 0.9", "percentile 1", "via momentum", "RSI cross_above 67%", "liquidity 1e+05", "also reversion"} {
+		if !strings.Contains(nvda.Explanation, part) {
+			t.Errorf("说明应包含 %q: %s", part, nvda.Explanation)
+		}
+	}
+
+	limited, _ := Rank(context.Background(), nil, reports, RankOptions{Limit: 2})
+	if len(limited) != 2 || limited[0].Symbol != "NVDA" || limited[1].Symbol != "AMD" {
+		t.Errorf("原始得分排名不正确: %+v", limited)
+	}
+	if _, err := Rank(context.Background(), nil, reports, RankOptions{Normalize: "minmax"}); err == nil {
+		t.Error("不支持的归一化方式应返回错误")
+	}
+}
+
+func TestNormalizeScores(t *testing.T) {
+	z := normalizeScores([]float64{1, 2, 3}, NormalizeZScore)
+	if math.Abs(z[0]+math.Sqrt(1.5)) > 1e-9 || z[1] != 0 || math.Abs(z[2]-math.Sqrt(1.5)) > 1e-9 {
+		t.Errorf("标准分 = %v", z)
+	}
+	if z := normalizeScores([]float64{0.5, 0.5}, NormalizeZScore); z[0] != 0 || z[1] != 0 {
+		t.Errorf("得分相同时标准分应为0: %v", z)
+	}
+	if p := normalizeScores([]float64{0.3}, NormalizePercentile); p[0] != 1 {
+		t.Errorf("只有一个候选时百分位应为1: %v", p)
+	}
+}
+
+func TestLiquidityMetrics(t *testing.T) {
+	bars := []datasource.StockData{{Close: 10, Volume: 100}, {Close: 10, Volume: 300}, {Close: 20, Volume: 800}}
+	liquidity, rvol := liquidityMetrics(bars, 2)
+	if liquidity != (3000+16000)/2 || rvol != 4 {
+		t.Errorf("成交额 = %v, RVOL = %v, 期望 9500 和 4", liquidity, rvol)
+	}
+}
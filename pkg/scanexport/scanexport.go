@@ -0,0 +1,194 @@
+// Package scanexport 将每轮扫描的候选股票列表发送到外部：Webhook、目录中的JSON/CSV文件或消息总线，
+// 使下游工具在定时扫描后自动收到结果
+package scanexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/messaging"
+)
+
+// 输出类型常量
+const (
+	SinkWebhook   = "webhook"   // POST到Webhook
+	SinkFile      = "file"      // 写入目录
+	SinkMessaging = "messaging" // 发布到消息总线
+)
+
+// 文件格式常量
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// SinkConfig 表示一个扫描结果输出的配置
+type SinkConfig struct {
+	Type           string            `json:"type" yaml:"type"`                       // webhook、file 或 messaging
+	URL            string            `json:"url" yaml:"url"`                         // webhook地址
+	Headers        map[string]string `json:"headers" yaml:"headers"`                 // webhook请求头，如认证令牌
+	TimeoutSeconds int               `json:"timeout_seconds" yaml:"timeout_seconds"` // webhook超时，默认10秒
+	Dir            string            `json:"dir" yaml:"dir"`                         // 输出目录
+	Format         string            `json:"format" yaml:"format"`                   // json 或 csv，默认json
+	Subject        string            `json:"subject" yaml:"subject"`                 // 消息主题，默认 <prefix>.scans
+	MinScore       float64           `json:"min_score" yaml:"min_score"`             // 只输出得分不低于该值的候选
+}
+
+// Validate 检查输出配置是否有效
+func (c SinkConfig) Validate() error {
+	switch c.Type {
+	case SinkWebhook:
+		if c.URL == "" {
+			return fmt.Errorf("url is required for webhook sinks")
+		}
+	case SinkFile:
+		if c.Dir == "" {
+			return fmt.Errorf("dir is required for file sinks")
+		}
+		switch c.Format {
+		case "", FormatJSON, FormatCSV:
+		default:
+			return fmt.Errorf("unsupported format %q", c.Format)
+		}
+	case SinkMessaging:
+	default:
+		return fmt.Errorf("unsupported sink type %q", c.Type)
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must not be negative")
+	}
+	return nil
+}
+
+// Candidate 表示扫描得到的一只候选股票
+type Candidate struct {
+	Symbol    string                  `json:"symbol"`
+	BuyScore  float64                 `json:"buy_score"`
+	SellScore float64                 `json:"sell_score"`
+	Signals   []indicators.ScanResult `json:"signals"`
+}
+
+// Score 返回候选的买入和卖出得分中较高者
+func (c Candidate) Score() float64 {
+	if c.SellScore > c.BuyScore {
+		return c.SellScore
+	}
+	return c.BuyScore
+}
+
+// Report 表示一个策略一轮扫描的结果
+type Report struct {
+	Strategy    string      `json:"strategy"`
+	Timeframe   string      `json:"timeframe"`
+	Symbols     int         `json:"symbols"` // 扫描的股票数量
+	StartedAt   time.Time   `json:"started_at"`
+	CompletedAt time.Time   `json:"completed_at"`
+	Candidates  []Candidate `json:"candidates"` // 按得分从高到低排序
+}
+
+// NewReport 根据扫描结果生成报告，候选按得分从高到低排序
+func NewReport(scanner *indicators.Scanner, strategy, timeframe string, symbols int, startedAt time.Time, results map[string][]indicators.ScanResult) Report {
+	report := Report{
+		Strategy:    strategy,
+		Timeframe:   timeframe,
+		Symbols:     symbols,
+		StartedAt:   startedAt,
+		CompletedAt: time.Now(),
+		Candidates:  make([]Candidate, 0, len(results)),
+	}
+	for symbol, signals := range results {
+		report.Candidates = append(report.Candidates, Candidate{
+			Symbol:    symbol,
+			BuyScore:  scanner.CalculateStrategyScore(signals, true),
+			SellScore: scanner.CalculateStrategyScore(signals, false),
+			Signals:   signals,
+		})
+	}
+	sort.Slice(report.Candidates, func(i, j int) bool {
+		a, b := report.Candidates[i], report.Candidates[j]
+		if a.Score() != b.Score() {
+			return a.Score() > b.Score()
+		}
+		return a.Symbol < b.Symbol
+	})
+	return report
+}
+
+// filter 返回只包含得分不低于minScore的候选的报告（内部方法）
+func (r Report) filter(minScore float64) Report {
+	if minScore <= 0 {
+		return r
+	}
+	filtered := r
+	filtered.Candidates = nil
+	for _, candidate := range r.Candidates {
+		if candidate.Score() >= minScore {
+			filtered.Candidates = append(filtered.Candidates, candidate)
+		}
+	}
+	return filtered
+}
+
+// Sink 定义了扫描结果输出的接口
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, report Report) error
+}
+
+// NewSink 根据配置创建输出，消息总线输出需要传入已连接的传输层
+func NewSink(config SinkConfig, transport messaging.Transport, prefix string) (Sink, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var sink Sink
+	switch config.Type {
+	case SinkWebhook:
+		sink = NewWebhookSink(config.URL, config.Headers, time.Duration(config.TimeoutSeconds)*time.Second)
+	case SinkFile:
+		sink = NewFileSink(config.Dir, config.Format)
+	case SinkMessaging:
+		if transport == nil {
+			return nil, fmt.Errorf("messaging sink requires messaging to be enabled")
+		}
+		subject := config.Subject
+		if subject == "" {
+			subject = "scans"
+			if prefix != "" {
+				subject = prefix + ".scans"
+			}
+		}
+		sink = NewTransportSink(transport, subject)
+	}
+
+	if config.MinScore > 0 {
+		sink = &filteredSink{Sink: sink, minScore: config.MinScore}
+	}
+	return sink, nil
+}
+
+// filteredSink 只输出得分达到阈值的候选（内部类型）
+type filteredSink struct {
+	Sink
+	minScore float64
+}
+
+// Send 过滤候选后发送
+func (s *filteredSink) Send(ctx context.Context, report Report) error {
+	return s.Sink.Send(ctx, report.filter(s.minScore))
+}
+
+// SendAll 将报告发送到所有输出，单个输出失败不影响其他输出
+func SendAll(ctx context.Context, sinks []Sink, report Report) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Send(ctx, report); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package scanexport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+)
+
+func testReport() Report {
+	return Report{
+		Strategy:    "momentum",
+		Timeframe:   "day",
+		Symbols:     3,
+		StartedAt:   time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC),
+		CompletedAt: time.Date(2024, 3, 4, 14, 0, 5, 0, time.UTC),
+		Candidates: []Candidate{
+			{Symbol: "NVDA", BuyScore: 0.9, Signals: []indicators.ScanResult{{Symbol: "NVDA", IndicatorName: "rsi"}, {Symbol: "NVDA", IndicatorName: "macd"}}},
+			{Symbol: "AAPL", SellScore: 0.4, Signals: []indicators.ScanResult{{Symbol: "AAPL", IndicatorName: "rsi"}}},
+		},
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(SinkConfig{Type: SinkWebhook, URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}, MinScore: 0.5}, nil, "")
+	if err != nil {
+		t.Fatalf("创建Webhook输出失败: %v", err)
+	}
+	if err := sink.Send(context.Background(), testReport()); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	if received.Strategy != "momentum" || len(received.Candidates) != 1 || received.Candidates[0].Symbol != "NVDA" {
+		t.Errorf("Webhook收到的报告不正确（应过滤低于0.5的候选）: %+v", received)
+	}
+
+	bad, _ := NewSink(SinkConfig{Type: SinkWebhook, URL: server.URL}, nil, "")
+	if err := bad.Send(context.Background(), testReport()); err == nil {
+		t.Error("非2xx响应应返回错误")
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	report := testReport()
+
+	for _, format := range []string{FormatJSON, FormatCSV} {
+		sink := NewFileSink(dir, format)
+		if err := sink.Send(context.Background(), report); err != nil {
+			t.Fatalf("写入%s失败: %v", format, err)
+		}
+		data, err := os.ReadFile(sink.Path(report))
+		if err != nil {
+			t.Fatalf("读取%s失败: %v", format, err)
+		}
+		if !strings.Contains(string(data), "NVDA") {
+			t.Errorf("%s文件应包含候选: %s", format, data)
+		}
+		if format == FormatCSV && !strings.Contains(string(data), "momentum,2024-03-04T14:00:05Z,NVDA,0.9000,0.0000,rsi;macd") {
+			t.Errorf("CSV内容不正确: %s", data)
+		}
+	}
+
+	if _, err := NewSink(SinkConfig{Type: SinkMessaging}, nil, ""); err == nil {
+		t.Error("没有消息总线时应无法创建消息总线输出")
+	}
+}
@@ -0,0 +1,181 @@
+package scanexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/messaging"
+)
+
+// WebhookSink 将扫描报告以JSON POST到Webhook
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookSink 创建Webhook输出，超时为0时使用10秒
+func NewWebhookSink(url string, headers map[string]string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookSink{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 返回输出名称
+func (s *WebhookSink) Name() string {
+	return SinkWebhook
+}
+
+// Send 发送报告，非2xx响应视为失败
+func (s *WebhookSink) Send(ctx context.Context, report Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode scan report: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// FileSink 将每轮扫描报告写入目录中的一个JSON或CSV文件
+type FileSink struct {
+	dir    string
+	format string
+}
+
+// NewFileSink 创建文件输出，格式为空时使用JSON
+func NewFileSink(dir, format string) *FileSink {
+	if format == "" {
+		format = FormatJSON
+	}
+	return &FileSink{dir: dir, format: format}
+}
+
+// Name 返回输出名称
+func (s *FileSink) Name() string {
+	return SinkFile
+}
+
+// Path 返回报告的文件路径：<dir>/<策略>-<完成时间>.<格式>
+func (s *FileSink) Path(report Report) string {
+	name := fmt.Sprintf("%s-%s.%s", sanitize(report.Strategy), report.CompletedAt.UTC().Format("20060102T150405Z"), s.format)
+	return filepath.Join(s.dir, name)
+}
+
+// Send 写入报告，先写入临时文件再重命名，下游工具不会读到写了一半的文件
+func (s *FileSink) Send(ctx context.Context, report Report) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create scan export directory: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if s.format == FormatCSV {
+		if err := writeCSV(&buf, report); err != nil {
+			return err
+		}
+	} else {
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode scan report: %v", err)
+		}
+	}
+
+	path := s.Path(report)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write scan report: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeCSV 以每个候选一行的格式写入报告（内部函数）
+func writeCSV(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"strategy", "completed_at", "symbol", "buy_score", "sell_score", "signals"})
+	for _, candidate := range report.Candidates {
+		names := make([]string, 0, len(candidate.Signals))
+		for _, signal := range candidate.Signals {
+			names = append(names, signal.IndicatorName)
+		}
+		writer.Write([]string{
+			report.Strategy,
+			report.CompletedAt.Format(time.RFC3339),
+			candidate.Symbol,
+			strconv.FormatFloat(candidate.BuyScore, 'f', 4, 64),
+			strconv.FormatFloat(candidate.SellScore, 'f', 4, 64),
+			strings.Join(names, ";"),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// sanitize 将策略名称转换为可用于文件名的形式（内部函数）
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// TransportSink 将扫描报告以JSON发布到消息总线
+type TransportSink struct {
+	transport messaging.Transport
+	subject   string
+}
+
+// NewTransportSink 创建消息总线输出
+func NewTransportSink(transport messaging.Transport, subject string) *TransportSink {
+	return &TransportSink{transport: transport, subject: subject}
+}
+
+// Name 返回输出名称
+func (s *TransportSink) Name() string {
+	return SinkMessaging
+}
+
+// Send 发布报告
+func (s *TransportSink) Send(ctx context.Context, report Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode scan report: %v", err)
+	}
+	return s.transport.Publish(ctx, s.subject, data)
+}
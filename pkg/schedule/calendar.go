@@ -0,0 +1,270 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTimezone 是美股交易所所在时区
+const DefaultTimezone = "America/New_York"
+
+// maxSessionSearch 查找下一个交易时段时最多向后搜索的天数，足以覆盖长周末和连续假期
+const maxSessionSearch = 14
+
+// 美股交易时段（交易所当地时间，自零点起的分钟数）
+const (
+	preMarketOpen   = 4 * 60
+	regularOpen     = 9*60 + 30
+	regularClose    = 16 * 60
+	earlyClose      = 13 * 60
+	afterHoursClose = 20 * 60
+	earlyAfterClose = 17 * 60
+)
+
+// Session 表示一个交易日的交易时段，时间均为交易所时区
+type Session struct {
+	Date       time.Time `json:"date"`        // 交易日零点
+	PreMarket  time.Time `json:"pre_market"`  // 盘前开始
+	Open       time.Time `json:"open"`        // 常规交易开盘
+	Close      time.Time `json:"close"`       // 常规交易收盘
+	AfterHours time.Time `json:"after_hours"` // 盘后结束
+	EarlyClose bool      `json:"early_close"` // 是否提前收盘
+}
+
+// Calendar 表示美股交易日历：按NYSE规则计算节假日和提前收盘日，并可额外指定休市日和提前收盘日
+type Calendar struct {
+	loc         *time.Location
+	holidays    map[string]bool
+	earlyCloses map[string]bool
+}
+
+// NewCalendar 创建交易日历，timezone为空时使用美东时区
+// holidays 和 earlyCloses 为额外的休市日和提前收盘日（格式 2006-01-02），例如临时休市
+func NewCalendar(timezone string, holidays, earlyCloses []string) (*Calendar, error) {
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone %s: %v", timezone, err)
+	}
+
+	c := &Calendar{
+		loc:         loc,
+		holidays:    make(map[string]bool, len(holidays)),
+		earlyCloses: make(map[string]bool, len(earlyCloses)),
+	}
+	for _, day := range holidays {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return nil, fmt.Errorf("invalid holiday %q", day)
+		}
+		c.holidays[day] = true
+	}
+	for _, day := range earlyCloses {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return nil, fmt.Errorf("invalid early close %q", day)
+		}
+		c.earlyCloses[day] = true
+	}
+	return c, nil
+}
+
+// Location 返回交易所时区
+func (c *Calendar) Location() *time.Location {
+	return c.loc
+}
+
+// IsHoliday 检查t所在的日期是否为休市的节假日
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	day := c.dayOf(t)
+	return c.holidays[dateKey(day)] || isNYSEHoliday(day)
+}
+
+// IsTradingDay 检查t所在的日期是否为交易日
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	day := c.dayOf(t)
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.IsHoliday(day)
+}
+
+// IsOpen 检查t是否处于常规交易时段内
+func (c *Calendar) IsOpen(t time.Time) bool {
+	session, ok := c.Session(t)
+	return ok && !t.Before(session.Open) && t.Before(session.Close)
+}
+
+// Session 返回t所在日期的交易时段，非交易日返回false
+func (c *Calendar) Session(t time.Time) (Session, bool) {
+	day := c.dayOf(t)
+	if !c.IsTradingDay(day) {
+		return Session{}, false
+	}
+
+	closeAt, afterAt := regularClose, afterHoursClose
+	early := c.earlyCloses[dateKey(day)] || isNYSEEarlyClose(day)
+	if early {
+		closeAt, afterAt = earlyClose, earlyAfterClose
+	}
+
+	return Session{
+		Date:       day,
+		PreMarket:  atMinute(day, preMarketOpen),
+		Open:       atMinute(day, regularOpen),
+		Close:      atMinute(day, closeAt),
+		AfterHours: atMinute(day, afterAt),
+		EarlyClose: early,
+	}, true
+}
+
+// NextSession 返回常规交易尚未结束的第一个交易时段（包括t所在的交易日）
+func (c *Calendar) NextSession(t time.Time) (Session, bool) {
+	var found Session
+	ok := c.eachSession(t, func(s Session) bool {
+		if s.Close.After(t) {
+			found = s
+			return true
+		}
+		return false
+	})
+	return found, ok
+}
+
+// PreviousSession 返回常规交易在t之前（含t）已经收盘的最近一个交易时段
+func (c *Calendar) PreviousSession(t time.Time) (Session, bool) {
+	day := c.dayOf(t)
+	for i := 0; i < maxSessionSearch; i++ {
+		if session, ok := c.Session(day.AddDate(0, 0, -i)); ok && !session.Close.After(t) {
+			return session, true
+		}
+	}
+	return Session{}, false
+}
+
+// eachSession 从t所在日期开始依次遍历交易时段，直到fn返回true（内部方法）
+func (c *Calendar) eachSession(t time.Time, fn func(Session) bool) bool {
+	day := c.dayOf(t)
+	for i := 0; i < maxSessionSearch; i++ {
+		if session, ok := c.Session(day.AddDate(0, 0, i)); ok && fn(session) {
+			return true
+		}
+	}
+	return false
+}
+
+// dayOf 返回t在交易所时区的日期零点（内部方法）
+func (c *Calendar) dayOf(t time.Time) time.Time {
+	t = t.In(c.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// atMinute 返回日期day零点之后minute分钟的时间，按当地时钟计算以正确处理夏令时（内部函数）
+func atMinute(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, day.Location())
+}
+
+// dateKey 返回日期的 2006-01-02 格式（内部函数）
+func dateKey(day time.Time) string {
+	return day.Format("2006-01-02")
+}
+
+// isNYSEHoliday 按NYSE规则判断日期是否为节假日（内部函数）
+func isNYSEHoliday(day time.Time) bool {
+	year, month, date := day.Date()
+	weekday := day.Weekday()
+
+	switch month {
+	case time.January:
+		// 元旦，周日顺延到周一；周六时不在前一年12月31日补休
+		if date == 1 && weekday != time.Saturday && weekday != time.Sunday || date == 2 && weekday == time.Monday {
+			return true
+		}
+		// 马丁·路德·金纪念日：1月第三个周一
+		return weekday == time.Monday && nthWeekday(date) == 3
+	case time.February:
+		// 总统日：2月第三个周一
+		return weekday == time.Monday && nthWeekday(date) == 3
+	case time.May:
+		// 阵亡将士纪念日：5月最后一个周一
+		return weekday == time.Monday && date+7 > 31
+	case time.June:
+		// 六月节，2022年起休市
+		return year >= 2022 && isObserved(day, 19)
+	case time.July:
+		// 独立日
+		return isObserved(day, 4)
+	case time.September:
+		// 劳动节：9月第一个周一
+		return weekday == time.Monday && nthWeekday(date) == 1
+	case time.November:
+		// 感恩节：11月第四个周四
+		return weekday == time.Thursday && nthWeekday(date) == 4
+	case time.December:
+		// 圣诞节
+		return isObserved(day, 25)
+	}
+
+	// 耶稣受难日：复活节前的周五
+	easter := easterSunday(year, day.Location())
+	return dateKey(day) == dateKey(easter.AddDate(0, 0, -2))
+}
+
+// isNYSEEarlyClose 按NYSE规则判断日期是否在13:00提前收盘（内部函数）
+// 独立日前一天、感恩节后一天和平安夜，均须本身为交易日
+func isNYSEEarlyClose(day time.Time) bool {
+	_, month, date := day.Date()
+	weekday := day.Weekday()
+	if weekday == time.Saturday || weekday == time.Sunday || isNYSEHoliday(day) {
+		return false
+	}
+
+	switch {
+	case month == time.July && date == 3:
+		return true
+	case month == time.November && weekday == time.Friday:
+		return nthWeekday(date-1) == 4
+	case month == time.December && date == 24:
+		return true
+	}
+	return false
+}
+
+// isObserved 判断日期是否为固定日期节假日的休市日：周六提前到周五，周日顺延到周一（内部函数）
+func isObserved(day time.Time, holiday int) bool {
+	date := day.Day()
+	switch day.Weekday() {
+	case time.Friday:
+		return date == holiday || date == holiday-1
+	case time.Monday:
+		return date == holiday || date == holiday+1
+	case time.Saturday, time.Sunday:
+		return false
+	default:
+		return date == holiday
+	}
+}
+
+// nthWeekday 返回日期是当月第几个同样的星期几（内部函数）
+func nthWeekday(date int) int {
+	return (date-1)/7 + 1
+}
+
+// easterSunday 使用格里高利历算法计算复活节日期（内部函数）
+func easterSunday(year int, loc *time.Location) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,243 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 计算任务的下一次运行时间
+type Schedule interface {
+	// Next 返回严格晚于after的下一次运行时间，没有可运行时间时返回零值
+	Next(after time.Time) time.Time
+}
+
+// Window 表示交易时段窗口
+type Window string
+
+// 交易时段窗口常量
+const (
+	WindowRegular    Window = "rth"        // 常规交易时段
+	WindowPreMarket  Window = "premarket"  // 盘前
+	WindowAfterHours Window = "afterhours" // 盘后
+	WindowExtended   Window = "extended"   // 盘前到盘后结束
+)
+
+// Parse 解析日历表达式，支持以下形式（不区分大小写）：
+//
+//	every 5m                    按固定间隔运行
+//	every 5m during rth         在常规交易时段内按间隔运行，也支持 premarket、afterhours、extended
+//	at open / at close          在交易日开盘或收盘时运行
+//	5 minutes before close      在交易日开盘或收盘前后的偏移时间运行
+//	at 16:30                    在交易日的指定时刻（交易所时区）运行
+//
+// 间隔和偏移既可以使用Go时长格式（如 90s、1h30m），也可以写作 "5 minutes"、"1 hour"
+func Parse(expr string, calendar *Calendar) (Schedule, error) {
+	fields := strings.Fields(strings.ToLower(expr))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty schedule expression")
+	}
+
+	switch fields[0] {
+	case "every":
+		return parseEvery(expr, fields[1:], calendar)
+	case "at":
+		if len(fields) == 2 {
+			if anchor, ok := parseAnchor(fields[1]); ok {
+				return &sessionSchedule{calendar: calendar, anchor: anchor}, nil
+			}
+			if minute, ok := parseClock(fields[1]); ok {
+				return &dailySchedule{calendar: calendar, minute: minute}, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid schedule expression %q", expr)
+	}
+
+	// <时长> before|after open|close
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid schedule expression %q", expr)
+	}
+	n := len(fields)
+	anchor, ok := parseAnchor(fields[n-1])
+	if !ok || (fields[n-2] != "before" && fields[n-2] != "after") {
+		return nil, fmt.Errorf("invalid schedule expression %q", expr)
+	}
+	offset, err := parseDuration(fields[:n-2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule expression %q: %v", expr, err)
+	}
+	if fields[n-2] == "before" {
+		offset = -offset
+	}
+	return &sessionSchedule{calendar: calendar, anchor: anchor, offset: offset}, nil
+}
+
+// parseEvery 解析 every 表达式（内部函数）
+func parseEvery(expr string, fields []string, calendar *Calendar) (Schedule, error) {
+	var window Window
+	if n := len(fields); n >= 2 && fields[n-2] == "during" {
+		window = Window(strings.ReplaceAll(fields[n-1], "-", ""))
+		switch window {
+		case WindowRegular, WindowPreMarket, WindowAfterHours, WindowExtended:
+		default:
+			return nil, fmt.Errorf("invalid schedule expression %q: unknown window %q", expr, fields[n-1])
+		}
+		fields = fields[:n-2]
+	}
+
+	interval, err := parseDuration(fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule expression %q: %v", expr, err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid schedule expression %q: interval must be positive", expr)
+	}
+
+	if window == "" {
+		return &intervalSchedule{interval: interval}, nil
+	}
+	return &windowSchedule{calendar: calendar, window: window, interval: interval}, nil
+}
+
+// parseAnchor 解析 open、close 锚点（内部函数）
+func parseAnchor(s string) (string, bool) {
+	switch s {
+	case "open", "close":
+		return s, true
+	}
+	return "", false
+}
+
+// parseClock 解析 HH:MM 格式的时刻，返回自零点起的分钟数（内部函数）
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// parseDuration 解析 "5m" 或 "5 minutes" 形式的时长（内部函数）
+func parseDuration(fields []string) (time.Duration, error) {
+	switch len(fields) {
+	case 1:
+		return time.ParseDuration(fields[0])
+	case 2:
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", strings.Join(fields, " "))
+		}
+		var unit time.Duration
+		switch strings.TrimSuffix(fields[1], "s") {
+		case "second", "sec":
+			unit = time.Second
+		case "minute", "min":
+			unit = time.Minute
+		case "hour":
+			unit = time.Hour
+		default:
+			return 0, fmt.Errorf("unknown duration unit %q", fields[1])
+		}
+		return time.Duration(n) * unit, nil
+	default:
+		return 0, fmt.Errorf("missing duration")
+	}
+}
+
+// intervalSchedule 按固定间隔运行，对齐到间隔的整数倍
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Next 返回下一个间隔整数倍的时间
+func (s *intervalSchedule) Next(after time.Time) time.Time {
+	return after.Truncate(s.interval).Add(s.interval)
+}
+
+// windowSchedule 在交易时段窗口内从窗口开始按间隔运行
+type windowSchedule struct {
+	calendar *Calendar
+	window   Window
+	interval time.Duration
+}
+
+// Next 返回窗口内下一个运行时间，当天窗口已结束时顺延到下一个交易日
+func (s *windowSchedule) Next(after time.Time) time.Time {
+	var next time.Time
+	s.calendar.eachSession(after, func(session Session) bool {
+		start, end := s.bounds(session)
+		if !end.After(after) {
+			return false
+		}
+		t := start
+		if !after.Before(start) {
+			t = start.Add((after.Sub(start)/s.interval + 1) * s.interval)
+		}
+		if t.Before(end) {
+			next = t
+			return true
+		}
+		return false
+	})
+	return next
+}
+
+// bounds 返回交易时段中窗口的起止时间（内部方法）
+func (s *windowSchedule) bounds(session Session) (time.Time, time.Time) {
+	switch s.window {
+	case WindowPreMarket:
+		return session.PreMarket, session.Open
+	case WindowAfterHours:
+		return session.Close, session.AfterHours
+	case WindowExtended:
+		return session.PreMarket, session.AfterHours
+	default:
+		return session.Open, session.Close
+	}
+}
+
+// sessionSchedule 在每个交易日的开盘或收盘前后固定偏移处运行，提前收盘日以实际收盘时间为准
+type sessionSchedule struct {
+	calendar *Calendar
+	anchor   string
+	offset   time.Duration
+}
+
+// Next 返回下一个交易日锚点加偏移的时间
+func (s *sessionSchedule) Next(after time.Time) time.Time {
+	var next time.Time
+	// 偏移可能使运行时间落在前一天，从前一天开始查找
+	s.calendar.eachSession(after.AddDate(0, 0, -1), func(session Session) bool {
+		t := session.Open
+		if s.anchor == "close" {
+			t = session.Close
+		}
+		t = t.Add(s.offset)
+		if t.After(after) {
+			next = t
+			return true
+		}
+		return false
+	})
+	return next
+}
+
+// dailySchedule 在每个交易日的固定时刻运行
+type dailySchedule struct {
+	calendar *Calendar
+	minute   int
+}
+
+// Next 返回下一个交易日指定时刻的时间
+func (s *dailySchedule) Next(after time.Time) time.Time {
+	var next time.Time
+	s.calendar.eachSession(after, func(session Session) bool {
+		if t := atMinute(session.Date, s.minute); t.After(after) {
+			next = t
+			return true
+		}
+		return false
+	})
+	return next
+}
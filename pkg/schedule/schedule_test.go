@@ -0,0 +1,132 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+func newTestCalendar(t *testing.T) *Calendar {
+	t.Helper()
+	cal, err := NewCalendar(DefaultTimezone, []string{"2025-01-09"}, nil)
+	if err != nil {
+		t.Fatalf("创建交易日历失败: %v", err)
+	}
+	return cal
+}
+
+// et 返回美东时间
+func et(cal *Calendar, s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, cal.Location())
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCalendarHolidays(t *testing.T) {
+	cal := newTestCalendar(t)
+
+	holidays := []string{
+		"2024-01-01", // 元旦
+		"2024-01-15", // 马丁·路德·金纪念日
+		"2024-02-19", // 总统日
+		"2024-03-29", // 耶稣受难日
+		"2024-05-27", // 阵亡将士纪念日
+		"2024-06-19", // 六月节
+		"2024-07-04", // 独立日
+		"2024-09-02", // 劳动节
+		"2024-11-28", // 感恩节
+		"2024-12-25", // 圣诞节
+		"2026-07-03", // 独立日为周六，提前到周五
+		"2025-01-09", // 配置的额外休市日
+	}
+	for _, day := range holidays {
+		if cal.IsTradingDay(et(cal, day+" 12:00")) {
+			t.Errorf("%s 应为休市日", day)
+		}
+	}
+
+	for _, day := range []string{"2024-03-28", "2024-07-05", "2021-12-31", "2021-06-18"} {
+		if !cal.IsTradingDay(et(cal, day+" 12:00")) {
+			t.Errorf("%s 应为交易日", day)
+		}
+	}
+
+	for _, day := range []string{"2024-07-03", "2024-11-29", "2024-12-24"} {
+		session, ok := cal.Session(et(cal, day+" 12:00"))
+		if !ok || !session.EarlyClose || session.Close.Hour() != 13 {
+			t.Errorf("%s 应在13:00提前收盘: %+v", day, session)
+		}
+	}
+}
+
+func TestParseSchedules(t *testing.T) {
+	cal := newTestCalendar(t)
+
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"every 5m during rth", "2024-03-04 09:00", "2024-03-04 09:30"},
+		{"every 5m during RTH", "2024-03-04 09:31", "2024-03-04 09:35"},
+		{"every 5 minutes during rth", "2024-03-04 15:55", "2024-03-05 09:30"},
+		{"every 5m during rth", "2024-03-28 16:10", "2024-04-01 09:30"}, // 跨过耶稣受难日和周末
+		{"every 1h during premarket", "2024-03-04 05:30", "2024-03-04 06:00"},
+		{"5 minutes before close", "2024-03-04 10:00", "2024-03-04 15:55"},
+		{"5 minutes before close", "2024-11-29 10:00", "2024-11-29 12:55"}, // 提前收盘日
+		{"15m after open", "2024-03-04 09:45", "2024-03-05 09:45"},
+		{"at close", "2024-03-08 17:00", "2024-03-11 16:00"},
+		{"at 17:30", "2024-03-04 18:00", "2024-03-05 17:30"},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expr, cal)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", tt.expr, err)
+			continue
+		}
+		got := schedule.Next(et(cal, tt.after))
+		if want := et(cal, tt.want); !got.Equal(want) {
+			t.Errorf("%q 在 %s 之后的运行时间 = %s, 期望 %s", tt.expr, tt.after, got.In(cal.Location()), want)
+		}
+	}
+
+	for _, expr := range []string{"", "every", "every 5m during lunch", "sometimes", "5 fortnights before close", "at noon"} {
+		if _, err := Parse(expr, cal); err == nil {
+			t.Errorf("%q 应解析失败", expr)
+		}
+	}
+}
+
+func TestSchedulerRunNow(t *testing.T) {
+	scheduler := NewScheduler(newTestCalendar(t))
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicSchedule)
+	defer sub.Close()
+	scheduler.SetEventBus(bus)
+
+	if err := scheduler.Add("reconcile", "every 5m during rth", func(ctx context.Context) error {
+		return errors.New("broker unavailable")
+	}); err != nil {
+		t.Fatalf("注册任务失败: %v", err)
+	}
+	if err := scheduler.Add("reconcile", "at close", func(ctx context.Context) error { return nil }); err == nil {
+		t.Error("重复的任务名应返回错误")
+	}
+
+	if err := scheduler.RunNow(context.Background(), "reconcile"); err == nil {
+		t.Error("任务失败应返回错误")
+	}
+
+	jobs := scheduler.Jobs()
+	if len(jobs) != 1 || jobs[0].Runs != 1 || jobs[0].Failures != 1 || jobs[0].LastError != "broker unavailable" {
+		t.Errorf("任务状态不正确: %+v", jobs)
+	}
+	if evt := <-sub.C; evt.Type != EventJobFailed {
+		t.Errorf("事件类型 = %s, 期望 %s", evt.Type, EventJobFailed)
+	}
+}
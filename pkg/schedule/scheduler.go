@@ -0,0 +1,210 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// 调度事件类型常量
+const (
+	EventJobCompleted = "job_completed" // 任务运行成功
+	EventJobFailed    = "job_failed"    // 任务运行失败
+)
+
+// JobFunc 是定时任务执行的函数
+type JobFunc func(ctx context.Context) error
+
+// JobStatus 表示任务的运行状态
+type JobStatus struct {
+	Name      string        `json:"name"`
+	Expr      string        `json:"expr"`
+	NextRun   time.Time     `json:"next_run"`
+	LastRun   time.Time     `json:"last_run,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	LastError string        `json:"last_error,omitempty"`
+	Runs      int           `json:"runs"`
+	Failures  int           `json:"failures"`
+}
+
+// job 表示一个已注册的定时任务（内部类型）
+type job struct {
+	schedule Schedule
+	fn       JobFunc
+	status   JobStatus
+}
+
+// Scheduler 按日历表达式运行定时任务，例如扫描、收盘汇总、对账和数据回补
+// 每个任务在独立的goroutine中运行，上一次运行未结束时不会重叠执行
+type Scheduler struct {
+	calendar *Calendar
+	eventBus *events.Bus
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	now  func() time.Time
+}
+
+// NewScheduler 创建一个使用指定交易日历的调度器
+func NewScheduler(calendar *Calendar) *Scheduler {
+	return &Scheduler{
+		calendar: calendar,
+		jobs:     make(map[string]*job),
+		now:      time.Now,
+	}
+}
+
+// Calendar 返回调度器使用的交易日历
+func (s *Scheduler) Calendar() *Calendar {
+	return s.calendar
+}
+
+// SetEventBus 设置事件总线，任务运行结果以 schedule 主题发布
+func (s *Scheduler) SetEventBus(bus *events.Bus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventBus = bus
+}
+
+// Add 注册一个定时任务，表达式格式参见 Parse，必须在Run之前调用
+func (s *Scheduler) Add(name, expr string, fn JobFunc) error {
+	if name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	if fn == nil {
+		return fmt.Errorf("job %s has no function", name)
+	}
+	schedule, err := Parse(expr, s.calendar)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("job %s already exists", name)
+	}
+	s.jobs[name] = &job{
+		schedule: schedule,
+		fn:       fn,
+		status: JobStatus{
+			Name:    name,
+			Expr:    expr,
+			NextRun: schedule.Next(s.now()),
+		},
+	}
+	return nil
+}
+
+// Len 返回已注册的任务数量
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+// Jobs 返回按名称排序的任务状态
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status)
+	}
+	sort.Slice(statuses, func(i, k int) bool {
+		return statuses[i].Name < statuses[k].Name
+	})
+	return statuses
+}
+
+// Run 运行所有任务，阻塞直到上下文取消
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.runJob(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// runJob 循环等待任务的下一次运行时间并执行（内部方法）
+func (s *Scheduler) runJob(ctx context.Context, j *job) {
+	for {
+		s.mu.Lock()
+		next := j.schedule.Next(s.now())
+		j.status.NextRun = next
+		s.mu.Unlock()
+
+		// 日历中找不到下一次运行时间，任务不再运行
+		if next.IsZero() {
+			<-ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.RunNow(ctx, j.status.Name)
+	}
+}
+
+// RunNow 立即运行指定任务一次，返回任务的错误
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("job %s not found", name)
+	}
+
+	started := s.now()
+	err := j.fn(ctx)
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	j.status.LastRun = started
+	j.status.Duration = s.now().Sub(started)
+	j.status.Runs++
+	j.status.LastError = ""
+	eventType := EventJobCompleted
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+		eventType = EventJobFailed
+	}
+	status := j.status
+	bus := s.eventBus
+	s.mu.Unlock()
+
+	bus.Publish(events.Event{
+		Topic:     events.TopicSchedule,
+		Type:      eventType,
+		Timestamp: started,
+		Payload:   status,
+	})
+	return err
+}
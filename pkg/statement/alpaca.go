@@ -0,0 +1,112 @@
+package statement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// SourceAlpaca 是Alpaca对账单的来源名称
+const SourceAlpaca = "alpaca"
+
+// ParseAlpacaCSV 解析Alpaca账户活动导出的CSV文件（activity_type为FILL的记录）
+// 列名与账户活动API的字段相同：transaction_time、symbol、side、qty、price、order_id、id，
+// 列的顺序不限，非成交类的活动（如分红、入金）会被跳过
+func ParseAlpacaCSV(r io.Reader, account string) ([]Fill, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alpaca header: %w", err)
+	}
+	cols := columnIndex(header)
+	for _, name := range []string{"transaction_time", "symbol", "side", "qty", "price", "id"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("alpaca export is missing column %s", name)
+		}
+	}
+
+	var fills []Fill
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		get := func(name string) string { return field(record, cols, name) }
+
+		if activity := get("activity_type"); activity != "" && !strings.EqualFold(activity, "FILL") {
+			continue
+		}
+
+		fill := Fill{
+			Source:      SourceAlpaca,
+			Account:     account,
+			Symbol:      strings.ToUpper(get("symbol")),
+			OrderID:     get("order_id"),
+			ExecutionID: get("id"),
+		}
+
+		switch strings.ToLower(get("side")) {
+		case "buy":
+			fill.Side = trading.OrderSideBuy
+		case "sell", "sell_short":
+			fill.Side = trading.OrderSideSell
+		default:
+			return nil, fmt.Errorf("line %d: unknown side %q", line, get("side"))
+		}
+
+		if fill.Time, err = time.Parse(time.RFC3339Nano, get("transaction_time")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid transaction_time: %w", line, err)
+		}
+		if fill.Quantity, err = parseQuantity(get("qty")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if fill.Price, err = strconv.ParseFloat(get("price"), 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid price: %w", line, err)
+		}
+		fills = append(fills, fill)
+	}
+	return fills, nil
+}
+
+// columnIndex 返回小写列名到列序号的映射（内部函数）
+func columnIndex(header []string) map[string]int {
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	return cols
+}
+
+// field 返回记录中指定列的值，列不存在时返回空字符串（内部函数）
+func field(record []string, cols map[string]int, name string) string {
+	i, ok := cols[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseQuantity 解析成交数量，负数取绝对值；不支持碎股（内部函数）
+func parseQuantity(s string) (int64, error) {
+	qty, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	qty = math.Abs(qty)
+	if qty == 0 || qty != math.Trunc(qty) {
+		return 0, fmt.Errorf("unsupported quantity %q", s)
+	}
+	return int64(qty), nil
+}
@@ -0,0 +1,230 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// SourceIBKR 是盈透证券对账单的来源名称
+const SourceIBKR = "ibkr"
+
+// ParseIBKRFlex 解析盈透证券Flex查询导出的交易记录，支持XML和CSV格式
+// 只导入股票（assetCategory为STK）的执行级别记录，撤销的成交会被跳过。
+// Flex查询中的时间没有时区，按loc解析，为空时使用美东时间
+func ParseIBKRFlex(r io.Reader, loc *time.Location) ([]Fill, error) {
+	if loc == nil {
+		var err error
+		if loc, err = time.LoadLocation("America/New_York"); err != nil {
+			return nil, err
+		}
+	}
+
+	buffered := bufio.NewReader(r)
+	head, _ := buffered.Peek(512)
+	if bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))), []byte("<")) {
+		return parseIBKRXML(buffered, loc)
+	}
+	return parseIBKRCSV(buffered, loc)
+}
+
+// parseIBKRXML 解析Flex XML中的Trade元素（内部函数）
+func parseIBKRXML(r io.Reader, loc *time.Location) ([]Fill, error) {
+	decoder := xml.NewDecoder(r)
+	var fills []Fill
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse flex xml: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Trade" {
+			continue
+		}
+		attrs := make(map[string]string, len(start.Attr))
+		for _, attr := range start.Attr {
+			attrs[flexKey(attr.Name.Local)] = strings.TrimSpace(attr.Value)
+		}
+
+		fill, ok, err := ibkrFill(attrs, loc)
+		if err != nil {
+			return nil, fmt.Errorf("trade %s: %w", attrs["tradeid"], err)
+		}
+		if ok {
+			fills = append(fills, fill)
+		}
+	}
+	return fills, nil
+}
+
+// parseIBKRCSV 解析Flex CSV，文件中可以包含多个带表头的段落（内部函数）
+func parseIBKRCSV(r io.Reader, loc *time.Location) ([]Fill, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var (
+		header []string
+		fills  []Fill
+	)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		// 带有HEADER/DATA前缀的格式
+		switch {
+		case len(record) > 0 && record[0] == "HEADER":
+			header = nil
+			record = record[1:]
+		case len(record) > 0 && record[0] == "DATA":
+			record = record[1:]
+		}
+
+		if header == nil || isFlexHeader(record) {
+			header = make([]string, len(record))
+			for i, name := range record {
+				header[i] = flexKey(name)
+			}
+			continue
+		}
+
+		attrs := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) {
+				attrs[name] = strings.TrimSpace(record[i])
+			}
+		}
+		if _, isTrade := attrs["buysell"]; !isTrade {
+			continue
+		}
+
+		fill, ok, err := ibkrFill(attrs, loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			fills = append(fills, fill)
+		}
+	}
+	return fills, nil
+}
+
+// isFlexHeader 检查CSV记录是否为表头行（内部函数）
+func isFlexHeader(record []string) bool {
+	for _, name := range record {
+		switch flexKey(name) {
+		case "symbol", "buysell", "tradeid":
+			return true
+		}
+	}
+	return false
+}
+
+// ibkrFill 将一条Flex交易记录转换为成交，不需要导入的记录返回false（内部函数）
+func ibkrFill(attrs map[string]string, loc *time.Location) (Fill, bool, error) {
+	if category := attrs["assetcategory"]; category != "" && category != "STK" {
+		return Fill{}, false, nil
+	}
+	if detail := attrs["levelofdetail"]; detail != "" && detail != "EXECUTION" {
+		return Fill{}, false, nil
+	}
+
+	fill := Fill{
+		Source:      SourceIBKR,
+		Account:     attrs["accountid"],
+		Symbol:      strings.ToUpper(attrs["symbol"]),
+		OrderID:     attrs["iborderid"],
+		ExecutionID: attrs["tradeid"],
+	}
+	if fill.Account == "" {
+		fill.Account = attrs["clientaccountid"]
+	}
+	if fill.ExecutionID == "" {
+		fill.ExecutionID = attrs["ibexecid"]
+	}
+
+	buySell := strings.ToUpper(attrs["buysell"])
+	switch {
+	case strings.Contains(buySell, "(CA."):
+		// 已撤销的成交
+		return Fill{}, false, nil
+	case strings.HasPrefix(buySell, "BUY"):
+		fill.Side = trading.OrderSideBuy
+	case strings.HasPrefix(buySell, "SELL"):
+		fill.Side = trading.OrderSideSell
+	default:
+		return Fill{}, false, fmt.Errorf("unknown buy/sell %q", attrs["buysell"])
+	}
+
+	var err error
+	if fill.Time, err = parseFlexTime(attrs, loc); err != nil {
+		return Fill{}, false, err
+	}
+	if fill.Quantity, err = parseQuantity(attrs["quantity"]); err != nil {
+		return Fill{}, false, err
+	}
+	if fill.Price, err = strconv.ParseFloat(attrs["tradeprice"], 64); err != nil {
+		return Fill{}, false, fmt.Errorf("invalid trade price %q", attrs["tradeprice"])
+	}
+	if commission := attrs["ibcommission"]; commission != "" {
+		value, err := strconv.ParseFloat(commission, 64)
+		if err != nil {
+			return Fill{}, false, fmt.Errorf("invalid commission %q", commission)
+		}
+		// 盈透以负数表示支付的佣金
+		fill.Commission = math.Abs(value)
+	}
+	return fill, true, nil
+}
+
+// parseFlexTime 解析Flex的成交时间，支持 20240304;093512、2024-03-04, 09:35:12 等格式，
+// 没有dateTime时使用tradeDate和tradeTime（内部函数）
+func parseFlexTime(attrs map[string]string, loc *time.Location) (time.Time, error) {
+	raw := attrs["datetime"]
+	if raw == "" {
+		raw = attrs["tradedate"] + attrs["tradetime"]
+	}
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, raw)
+
+	switch len(digits) {
+	case 14:
+		return time.ParseInLocation("20060102150405", digits, loc)
+	case 8:
+		return time.ParseInLocation("20060102", digits, loc)
+	default:
+		return time.Time{}, fmt.Errorf("invalid date time %q", raw)
+	}
+}
+
+// flexKey 将Flex字段名规范化为小写字母和数字，使XML属性名和CSV列名一致（内部函数）
+func flexKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
@@ -0,0 +1,224 @@
+// Package statement 从券商对账单导入历史成交，重建交易记录和交易日志，
+// 使采用本系统之前的交易历史也能出现在统计和报表中
+package statement
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/logger"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// ImportedTag 是导入的交易日志附加的标签
+const ImportedTag = "imported"
+
+// Fill 表示对账单中的一笔成交
+type Fill struct {
+	Source      string            `json:"source"` // 对账单来源，如 alpaca、ibkr
+	Account     string            `json:"account,omitempty"`
+	Symbol      string            `json:"symbol"`
+	Side        trading.OrderSide `json:"side"`
+	Quantity    int64             `json:"quantity"`
+	Price       float64           `json:"price"`
+	Commission  float64           `json:"commission,omitempty"`
+	Time        time.Time         `json:"time"`
+	OrderID     string            `json:"order_id,omitempty"`
+	ExecutionID string            `json:"execution_id"`
+}
+
+// Result 表示从成交重建的交易记录
+type Result struct {
+	Trades  []trading.Trade        `json:"trades"`
+	Entries []logger.TradeLogEntry `json:"entries"`
+	Open    []trading.Position     `json:"open,omitempty"` // 对账单结束时仍未平仓的持仓
+}
+
+// lot 表示重建过程中某个账户在某只股票上的持仓（内部类型）
+type lot struct {
+	quantity   int64 // 空头为负数
+	entryPrice float64
+	openedAt   time.Time
+	commission float64 // 开仓累计的手续费，平仓时按比例计入交易
+}
+
+// Reconstruct 按时间顺序回放成交，以平均成本法重建交易和交易日志
+// 每次减仓生成一条交易记录，卖出超过多头数量（或没有持仓时卖出）视为开空。
+// 交易ID由来源和成交ID生成，重复导入同一份对账单不会产生重复的交易
+func Reconstruct(fills []Fill) Result {
+	sorted := append([]Fill(nil), fills...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	var result Result
+	lots := make(map[string]*lot)
+	for _, fill := range sorted {
+		key := fill.Account + "|" + fill.Symbol
+		l := lots[key]
+		if l == nil {
+			l = &lot{}
+			lots[key] = l
+		}
+
+		signed := fill.Quantity
+		if fill.Side == trading.OrderSideSell {
+			signed = -signed
+		}
+
+		entry := logger.TradeLogEntry{
+			Type:        string(fill.Side),
+			Timestamp:   fill.Time,
+			Symbol:      fill.Symbol,
+			Quantity:    fill.Quantity,
+			Price:       fill.Price,
+			Amount:      float64(fill.Quantity) * fill.Price,
+			Commission:  fill.Commission,
+			Account:     fill.Account,
+			OrderID:     fill.OrderID,
+			ExecutionID: fill.ExecutionID,
+			Tags:        []string{ImportedTag, fill.Source},
+		}
+
+		remaining := signed
+		commission := fill.Commission
+
+		// 与持仓方向相反的成交先平仓
+		if l.quantity*signed < 0 {
+			dir := int64(1)
+			if l.quantity < 0 {
+				dir = -1
+			}
+			closed := min(fill.Quantity, l.quantity*dir) * dir
+			share := float64(closed*dir) / float64(fill.Quantity)
+			entryCommission := l.commission * float64(closed) / float64(l.quantity)
+			pnl := float64(closed)*(fill.Price-l.entryPrice) - entryCommission - commission*share
+			closedAt := fill.Time
+
+			result.Trades = append(result.Trades, trading.Trade{
+				ID:                 fmt.Sprintf("%s-%s", fill.Source, fill.ExecutionID),
+				Symbol:             fill.Symbol,
+				EntryPrice:         l.entryPrice,
+				ExitPrice:          fill.Price,
+				Quantity:           closed,
+				RealizedPnL:        pnl,
+				RealizedPnLPercent: (fill.Price/l.entryPrice - 1) * 100 * float64(dir),
+				Commission:         entryCommission + commission*share,
+				OpenedAt:           l.openedAt,
+				ClosedAt:           &closedAt,
+				HoldTime:           closedAt.Sub(l.openedAt).Hours(),
+				Tags:               []string{ImportedTag, fill.Source},
+			})
+
+			entry.PnL = pnl
+			entry.PnLPercent = (fill.Price/l.entryPrice - 1) * 100 * float64(dir)
+			entry.EntryPrice = l.entryPrice
+			entry.HoldTime = closedAt.Sub(l.openedAt).Hours()
+
+			l.commission -= entryCommission
+			l.quantity -= closed
+			remaining += closed
+			commission -= commission * share
+		}
+
+		// 剩余的成交开仓或加仓
+		if remaining != 0 {
+			if l.quantity == 0 {
+				l.openedAt = fill.Time
+				l.entryPrice = 0
+				l.commission = 0
+			}
+			cost := float64(l.quantity)*l.entryPrice + float64(remaining)*fill.Price
+			l.quantity += remaining
+			l.entryPrice = cost / float64(l.quantity)
+			l.commission += commission
+		}
+
+		entry.Position = l.quantity
+		if entry.EntryPrice == 0 {
+			entry.EntryPrice = l.entryPrice
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	keys := make([]string, 0, len(lots))
+	for key := range lots {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		l := lots[key]
+		if l.quantity == 0 {
+			continue
+		}
+		symbol := key[strings.Index(key, "|")+1:]
+		result.Open = append(result.Open, trading.Position{
+			Symbol:     symbol,
+			Quantity:   l.quantity,
+			EntryPrice: l.entryPrice,
+			Cost:       float64(l.quantity) * l.entryPrice,
+			OpenedAt:   l.openedAt,
+			Tags:       []string{ImportedTag},
+		})
+	}
+
+	return result
+}
+
+// TradeImporter 是可以导入历史交易的交易引擎
+type TradeImporter interface {
+	ImportTrades(trades []trading.Trade) (int, error)
+}
+
+// Import 将重建的交易导入交易引擎，并将交易日志写入交易日志记录器
+// 交易日志中已有相同成交ID的记录会被跳过，返回导入的交易数量和交易日志数量
+func Import(engine TradeImporter, tradeLogger logger.TradeLogger, result Result) (int, int, error) {
+	trades := 0
+	if engine != nil {
+		n, err := engine.ImportTrades(result.Trades)
+		if err != nil {
+			return n, 0, fmt.Errorf("failed to import trades: %w", err)
+		}
+		trades = n
+	}
+	if tradeLogger == nil {
+		return trades, 0, nil
+	}
+
+	// 按日期读取已有的交易日志，跳过已经导入的成交
+	seen := make(map[string]map[string]bool)
+	entries := 0
+	for _, entry := range result.Entries {
+		day := entry.Timestamp.Format("2006-01-02")
+		if seen[day] == nil {
+			seen[day] = make(map[string]bool)
+			existing, err := tradeLogger.GetDailyLogs(entry.Timestamp)
+			if err != nil {
+				return trades, entries, fmt.Errorf("failed to read trade logs for %s: %w", day, err)
+			}
+			for _, e := range existing {
+				if e.ExecutionID != "" {
+					seen[day][e.ExecutionID] = true
+				}
+			}
+		}
+		if entry.ExecutionID != "" && seen[day][entry.ExecutionID] {
+			continue
+		}
+
+		var err error
+		if entry.Type == string(trading.OrderSideSell) {
+			err = tradeLogger.LogSell(entry)
+		} else {
+			err = tradeLogger.LogBuy(entry)
+		}
+		if err != nil {
+			return trades, entries, fmt.Errorf("failed to write trade log: %w", err)
+		}
+		seen[day][entry.ExecutionID] = true
+		entries++
+	}
+	return trades, entries, nil
+}
@@ -0,0 +1,115 @@
+package statement
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/logger"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+const alpacaCSV = `id,activity_type,transaction_time,type,price,qty,side,symbol,leaves_qty,order_id,cum_qty,order_status
+20240304093000001::a,FILL,2024-03-04T14:30:00Z,fill,100,10,buy,AAPL,0,o1,10,filled
+20240304100000001::b,DIV,2024-03-04T15:00:00Z,,,,,AAPL,,,,
+20240305150000001::c,FILL,2024-03-05T20:00:00Z,partial_fill,110,4,sell,AAPL,6,o2,4,partially_filled
+20240305150100001::d,FILL,2024-03-05T20:01:00Z,fill,105,6,sell,AAPL,0,o2,10,filled
+`
+
+const ibkrXML = `<FlexQueryResponse queryName="trades" type="AF">
+<FlexStatements count="1">
+<FlexStatement accountId="U1234567">
+<Trades>
+<Trade accountId="U1234567" assetCategory="STK" symbol="MSFT" dateTime="20240304;100000" buySell="SELL" quantity="-5" tradePrice="400" ibCommission="-1" tradeID="t1" ibOrderID="9001" levelOfDetail="EXECUTION" />
+<Trade accountId="U1234567" assetCategory="OPT" symbol="MSFT 240315C00400000" dateTime="20240304;100500" buySell="BUY" quantity="1" tradePrice="5" tradeID="t2" levelOfDetail="EXECUTION" />
+<Trade accountId="U1234567" assetCategory="STK" symbol="MSFT" dateTime="20240306;110000" buySell="BUY" quantity="5" tradePrice="390" ibCommission="-1" tradeID="t3" ibOrderID="9002" levelOfDetail="EXECUTION" />
+</Trades>
+</FlexStatement>
+</FlexStatements>
+</FlexQueryResponse>`
+
+const ibkrCSV = `"ClientAccountID","AssetCategory","Symbol","DateTime","Buy/Sell","Quantity","TradePrice","IBCommission","TradeID"
+"U1234567","STK","MSFT","2024-03-04, 10:00:00","SELL","-5","400","-1","t1"
+"U1234567","STK","MSFT","2024-03-05, 10:00:00","BUY (Ca.)","5","395","0","t9"
+"U1234567","STK","MSFT","2024-03-06, 11:00:00","BUY","5","390","-1","t3"
+`
+
+func TestParseStatements(t *testing.T) {
+	fills, err := ParseAlpacaCSV(strings.NewReader(alpacaCSV), "alpaca-main")
+	if err != nil {
+		t.Fatalf("解析Alpaca导出失败: %v", err)
+	}
+	if len(fills) != 3 || fills[1].Side != trading.OrderSideSell || fills[1].Quantity != 4 || fills[0].ExecutionID != "20240304093000001::a" {
+		t.Fatalf("Alpaca成交不正确: %+v", fills)
+	}
+
+	for name, input := range map[string]string{"xml": ibkrXML, "csv": ibkrCSV} {
+		fills, err := ParseIBKRFlex(strings.NewReader(input), nil)
+		if err != nil {
+			t.Fatalf("解析IBKR %s 失败: %v", name, err)
+		}
+		if len(fills) != 2 {
+			t.Fatalf("IBKR %s 应有2笔股票成交: %+v", name, fills)
+		}
+		first := fills[0]
+		if first.Account != "U1234567" || first.Side != trading.OrderSideSell || first.Quantity != 5 || first.Commission != 1 {
+			t.Errorf("IBKR %s 成交不正确: %+v", name, first)
+		}
+		if first.Time.UTC().Hour() != 15 {
+			t.Errorf("IBKR %s 时间应按美东时间解析: %s", name, first.Time.UTC())
+		}
+	}
+}
+
+func TestReconstructAndImport(t *testing.T) {
+	alpaca, _ := ParseAlpacaCSV(strings.NewReader(alpacaCSV), "alpaca-main")
+	ibkr, _ := ParseIBKRFlex(strings.NewReader(ibkrXML), nil)
+	result := Reconstruct(append(alpaca, ibkr...))
+
+	// AAPL分两次卖出：4×(110−100) + 6×(105−100) = 70；MSFT空头回补：5×(400−390) − 2 = 48
+	pnl := make(map[string]float64)
+	for _, trade := range result.Trades {
+		pnl[trade.Symbol] += trade.RealizedPnL
+	}
+	if len(result.Trades) != 3 || math.Abs(pnl["AAPL"]-70) > 1e-9 || math.Abs(pnl["MSFT"]-48) > 1e-9 {
+		t.Fatalf("重建的交易不正确: %v %+v", pnl, result.Trades)
+	}
+	if len(result.Entries) != 5 || len(result.Open) != 0 {
+		t.Errorf("交易日志 %d 条、未平仓 %d 个, 期望 5 和 0", len(result.Entries), len(result.Open))
+	}
+
+	dir := t.TempDir()
+	sysLogger, err := logger.NewLogger(logger.LogConfig{Level: logger.LogLevelError, Output: logger.LogOutputFile, FilePath: filepath.Join(dir, "system.log")})
+	if err != nil {
+		t.Fatalf("创建系统日志记录器失败: %v", err)
+	}
+	defer sysLogger.Close()
+	tradeLogger, err := logger.NewTradeLogger(filepath.Join(dir, "trades"), sysLogger)
+	if err != nil {
+		t.Fatalf("创建交易日志记录器失败: %v", err)
+	}
+	defer tradeLogger.Close()
+
+	engine := trading.NewBaseTradingEngine(nil, trading.BrokerConfig{}, trading.TradingLimits{})
+	trades, entries, err := Import(engine, tradeLogger, result)
+	if err != nil || trades != 3 || entries != 5 {
+		t.Fatalf("导入结果 %d %d %v, 期望 3 5", trades, entries, err)
+	}
+
+	// 重复导入不产生重复记录
+	if trades, entries, err := Import(engine, tradeLogger, result); err != nil || trades != 0 || entries != 0 {
+		t.Errorf("重复导入结果 %d %d %v, 期望 0 0", trades, entries, err)
+	}
+
+	stats, err := engine.GetTradeStats(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Now())
+	if err != nil || stats.TotalTrades != 3 {
+		t.Errorf("交易统计应包含导入的交易: %+v %v", stats, err)
+	}
+	logs, _ := tradeLogger.GetDailyLogs(time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC))
+	if len(logs) != 2 || logs[0].PnL != 40 {
+		t.Errorf("3月5日的交易日志不正确: %+v", logs)
+	}
+}
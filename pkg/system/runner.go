@@ -0,0 +1,497 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/config"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/scanexport"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// Service 表示由Runner管理生命周期的后台服务，例如数据源推送流或自定义监控
+// Run 应阻塞直到上下文取消；返回非nil错误会触发整个系统关闭
+type Service interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// serviceFunc 是基于函数的Service实现
+type serviceFunc struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// NewService 使用函数创建一个Service
+func NewService(name string, run func(ctx context.Context) error) Service {
+	return &serviceFunc{name: name, run: run}
+}
+
+// Name 返回服务名称
+func (s *serviceFunc) Name() string {
+	return s.name
+}
+
+// Run 运行服务
+func (s *serviceFunc) Run(ctx context.Context) error {
+	return s.run(ctx)
+}
+
+// shutdownHook 表示关闭时执行的回调
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Runner 管理系统所有组件的启动和有序关闭
+// 启动：HTTP服务（/metrics、/healthz、/readyz）、指标采集、定时扫描、监控列表、策略编排、定时任务以及注册的服务
+// 关闭：停止所有服务 → 取消未完成订单 → 禁用交易引擎 → 停止HTTP服务 → 执行关闭回调 → 关闭数据源和日志
+type Runner struct {
+	system *System
+
+	mu       sync.Mutex
+	services []Service
+	hooks    []shutdownHook
+	signals  []os.Signal
+}
+
+// NewRunner 创建一个新的生命周期管理器
+func NewRunner(system *System) *Runner {
+	return &Runner{
+		system:  system,
+		signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+}
+
+// AddService 注册一个后台服务，必须在Run之前调用
+func (r *Runner) AddService(service Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.services = append(r.services, service)
+}
+
+// OnShutdown 注册一个关闭回调，在服务停止后、系统组件关闭前按注册顺序执行
+func (r *Runner) OnShutdown(name string, fn func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run 启动所有组件并阻塞，直到收到SIGINT/SIGTERM、上下文取消或某个服务失败，随后有序关闭
+func (r *Runner) Run(ctx context.Context) error {
+	sys := r.system
+	cfg := sys.Config
+
+	ctx, stopSignals := signal.NotifyContext(ctx, r.signals...)
+	defer stopSignals()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 启动HTTP服务
+	var httpServer *http.Server
+	httpErr := make(chan error, 1)
+	if cfg.Server.Port > 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", sys.Metrics.Handler())
+		sys.Health.Register(mux)
+		if sys.Webhooks != nil {
+			mux.Handle(cfg.Webhooks.Path, sys.Webhooks.Handler(cfg.Webhooks.Path))
+		}
+
+		httpServer = &http.Server{Addr: cfg.Server.Address(), Handler: mux}
+		go func() {
+			sys.Logger.Info("HTTP服务已启动: %s", httpServer.Addr)
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				httpErr <- err
+			}
+		}()
+	}
+
+	// 启动后台服务
+	r.mu.Lock()
+	services := append(r.builtinServices(), r.services...)
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	serviceErr := make(chan error, len(services))
+	for _, service := range services {
+		wg.Add(1)
+		go func(service Service) {
+			defer wg.Done()
+			sys.Logger.Debug("服务已启动: %s", service.Name())
+			if err := service.Run(runCtx); err != nil && !errors.Is(err, context.Canceled) {
+				serviceErr <- fmt.Errorf("service %s failed: %w", service.Name(), err)
+			}
+		}(service)
+	}
+
+	// 等待退出条件
+	var runErr error
+	select {
+	case <-ctx.Done():
+		sys.Logger.Info("收到退出信号，开始关闭系统")
+	case runErr = <-serviceErr:
+		sys.Logger.Error("服务异常退出，开始关闭系统: %v", runErr)
+	case runErr = <-httpErr:
+		runErr = fmt.Errorf("http server failed: %w", runErr)
+		sys.Logger.Error("HTTP服务异常退出，开始关闭系统: %v", runErr)
+	}
+
+	shutdownErr := r.shutdown(cancel, &wg, httpServer)
+	return errors.Join(runErr, shutdownErr)
+}
+
+// shutdown 按顺序关闭系统（内部方法）
+func (r *Runner) shutdown(cancel context.CancelFunc, wg *sync.WaitGroup, httpServer *http.Server) error {
+	sys := r.system
+	cfg := sys.Config
+
+	timeout := time.Duration(cfg.Shutdown.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, done := context.WithTimeout(context.Background(), timeout)
+	defer done()
+
+	var errs []error
+
+	// 停止所有服务，不再产生新的订单
+	cancel()
+	waited := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("timed out waiting for services to stop"))
+	}
+
+	// 取消未完成订单并禁用交易引擎
+	if cfg.Shutdown.CancelOpenOrders && sys.Engine.IsEnabled() {
+		if err := r.cancelOpenOrders(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if sys.Engine.IsEnabled() {
+		if err := sys.Engine.Disable(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// 停止HTTP服务
+	if httpServer != nil {
+		if err := httpServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop http server: %w", err))
+		}
+	}
+
+	// 执行关闭回调
+	r.mu.Lock()
+	hooks := append([]shutdownHook(nil), r.hooks...)
+	r.mu.Unlock()
+	for _, hook := range hooks {
+		if err := hook.fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %s failed: %w", hook.name, err))
+		}
+	}
+
+	sys.Logger.Info("系统已关闭")
+
+	// 关闭数据源、交易日志和系统日志
+	if err := sys.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// cancelOpenOrders 取消所有未完成订单（内部方法）
+func (r *Runner) cancelOpenOrders(ctx context.Context) error {
+	orders, err := r.system.Engine.GetOpenOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get open orders: %w", err)
+	}
+
+	// 券商管理的止损和止盈挂单在进程退出后继续保护持仓，不撤销
+	keepStops := r.system.Config.Trading.Broker.StopMode == trading.StopModeBroker
+
+	var errs []error
+	for _, order := range orders {
+		if keepStops && trading.IsProtective(order) {
+			continue
+		}
+		if err := r.system.Engine.CancelOrder(ctx, order.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel order %s: %w", order.ID, err))
+			continue
+		}
+		r.system.Logger.Info("关闭时取消订单: %s %s", order.ID, order.Symbol)
+	}
+	return errors.Join(errs...)
+}
+
+// builtinServices 返回根据配置启用的内置服务（内部方法）
+func (r *Runner) builtinServices() []Service {
+	sys := r.system
+	cfg := sys.Config
+
+	services := []Service{
+		NewService("metrics", func(ctx context.Context) error {
+			sys.Metrics.Run(ctx, sys.EventBus)
+			return nil
+		}),
+	}
+
+	if _, ok := sys.Engine.GetBroker().(trading.ExecutionStream); ok {
+		// 券商异步推送的成交、撤单和拒绝更新订单和持仓
+		services = append(services, NewService("executions", sys.Engine.RunExecutionStream))
+	}
+
+	if cfg.Trading.EquityIntervalSeconds > 0 {
+		interval := time.Duration(cfg.Trading.EquityIntervalSeconds) * time.Second
+		services = append(services, NewService("equity", func(ctx context.Context) error {
+			return sys.Engine.RunEquityRecorder(ctx, interval)
+		}))
+	}
+
+	if cfg.Trading.MarkIntervalSeconds > 0 {
+		interval := time.Duration(cfg.Trading.MarkIntervalSeconds) * time.Second
+		services = append(services, NewService("marks", func(ctx context.Context) error {
+			return sys.Engine.RunPositionMarker(ctx, interval)
+		}))
+	}
+
+	if cfg.Trading.StopIntervalSeconds > 0 {
+		interval := time.Duration(cfg.Trading.StopIntervalSeconds) * time.Second
+		services = append(services, NewService("stops", func(ctx context.Context) error {
+			return sys.Engine.RunStopMonitor(ctx, interval)
+		}))
+	}
+
+	if cfg.Trading.Halts.Enabled {
+		// 除持仓和未完成订单外，也检测监控列表中的股票，停牌期间不触发
+		services = append(services, NewService("halts", func(ctx context.Context) error {
+			return sys.Engine.RunHaltMonitor(ctx, r.watchlistSymbols)
+		}))
+	}
+
+	if cfg.Trading.AccountWatch.Enabled {
+		// 告警在 risk 主题发布，同时写入日志
+		services = append(services, NewService("account_watch", func(ctx context.Context) error {
+			go sys.logAccountAlerts(ctx)
+			return sys.Engine.RunAccountWatch(ctx)
+		}))
+	}
+
+	if sys.Symbols != nil && sys.Symbols.RefreshedAt().IsZero() {
+		// 缓存为空时在后台拉取一次完整的股票列表，不阻塞启动
+		services = append(services, NewService("symbols", func(ctx context.Context) error {
+			if err := sys.refreshSymbols(ctx); err != nil {
+				sys.Logger.Warn("初始化股票列表缓存失败: %v", err)
+			}
+			return nil
+		}))
+	}
+
+	if cfg.Watchlist.Enabled {
+		interval := time.Duration(cfg.Watchlist.ScanIntervalSeconds) * time.Second
+		services = append(services, NewService("watchlist", func(ctx context.Context) error {
+			sys.Watchlist.StartWatchlistMonitor(ctx, interval)
+			return nil
+		}))
+	}
+
+	if cfg.Scanner.Enabled && cfg.Scanner.Schedule != "" {
+		// 按日历表达式扫描，由调度器驱动
+		err := sys.Scheduler.Add("scanner", cfg.Scanner.Schedule, func(ctx context.Context) error {
+			r.scanOnce(ctx, cfg.Scanner)
+			return nil
+		})
+		if err != nil {
+			sys.Logger.Error("注册定时扫描失败: %v", err)
+		}
+	} else if cfg.Scanner.Enabled {
+		services = append(services, NewService("scanner", r.runScanner))
+	}
+	if cfg.Scanner.Enabled && cfg.Scanner.Prime != "" {
+		// 开盘前预取扫描所需的K线，开盘后的第一轮扫描只需增量获取
+		if err := sys.Scheduler.Add("scanner_prime", cfg.Scanner.Prime, r.primeScanner); err != nil {
+			sys.Logger.Error("注册扫描预热失败: %v", err)
+		}
+	}
+
+	if sys.Risk != nil {
+		services = append(services, NewService("risk", sys.Risk.Run))
+	}
+	if sys.Bridge != nil {
+		services = append(services, NewService("messaging", sys.Bridge.Run))
+	}
+	if sys.OrderConsumer != nil {
+		services = append(services, NewService("messaging-orders", sys.OrderConsumer.Run))
+	}
+
+	if sys.Orchestrator != nil {
+		services = append(services, NewService("orchestrator", func(ctx context.Context) error {
+			return sys.Orchestrator.Run(ctx, sys.EventBus)
+		}))
+	}
+
+	if sys.Scheduler.Len() > 0 {
+		services = append(services, NewService("scheduler", sys.Scheduler.Run))
+	}
+
+	return services
+}
+
+// watchlistSymbols 返回监控列表中活跃项的股票代码（内部方法）
+func (r *Runner) watchlistSymbols() []string {
+	if r.system.Watchlist == nil {
+		return nil
+	}
+	var symbols []string
+	for _, item := range r.system.Watchlist.GetActiveItems() {
+		symbols = append(symbols, item.Symbol)
+	}
+	return symbols
+}
+
+// runScanner 按配置的间隔定时扫描股票（内部方法）
+func (r *Runner) runScanner(ctx context.Context) error {
+	sys := r.system
+	cfg := sys.Config.Scanner
+
+	ticker := time.NewTicker(time.Duration(cfg.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		r.scanOnce(ctx, cfg)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scanOnce 执行一轮扫描，结果通过事件总线发布（内部方法）
+func (r *Runner) scanOnce(ctx context.Context, cfg config.ScannerConfig) {
+	sys := r.system
+
+	strategies := cfg.Strategies
+	if len(strategies) == 0 {
+		all := sys.Scanner.GetAllStrategies()
+		for _, name := range sortedKeys(all) {
+			if all[name].Enabled {
+				strategies = append(strategies, name)
+			}
+		}
+	}
+
+	symbols, err := r.scanSymbols(ctx, cfg)
+	if err != nil {
+		sys.Logger.Warn("获取%s成分股失败: %v", cfg.Universe, err)
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -cfg.LookbackDays)
+	var reports []scanexport.Report
+	for _, name := range strategies {
+		if ctx.Err() != nil {
+			return
+		}
+		// 策略设置了自己的股票范围时扫描策略的股票
+		strategySymbols, err := sys.Scanner.StrategySymbols(ctx, name, symbols)
+		if err != nil {
+			sys.Logger.Warn("获取策略%s的股票范围失败: %v", name, err)
+			continue
+		}
+		startedAt := time.Now()
+		results, err := sys.Scanner.ScanMultipleSymbols(ctx, strategySymbols, name, from, to, cfg.Timeframe)
+		if err != nil {
+			sys.Logger.Warn("扫描策略%s失败: %v", name, err)
+		}
+
+		// 部分股票扫描失败时仍然输出已得到的候选
+		report := scanexport.NewReport(sys.Scanner, name, cfg.Timeframe, len(strategySymbols), startedAt, results)
+		reports = append(reports, report)
+		if len(sys.ScanSinks) > 0 {
+			if err := scanexport.SendAll(ctx, sys.ScanSinks, report); err != nil {
+				sys.Logger.Warn("输出策略%s的扫描结果失败: %v", name, err)
+			}
+		}
+	}
+
+	if cfg.Ranking.Enabled {
+		ranked, err := scanexport.Rank(ctx, sys.DataManager, reports, cfg.Ranking)
+		if err != nil {
+			sys.Logger.Warn("候选排名失败: %v", err)
+			return
+		}
+		sys.EventBus.Publish(events.Event{
+			Topic:     events.TopicSignals,
+			Type:      scanexport.EventScanRanked,
+			Timestamp: time.Now(),
+			Payload:   scanexport.Ranking{CompletedAt: time.Now(), Candidates: ranked},
+		})
+	}
+}
+
+// primeScanner 按扫描配置预取扫描股票和市场过滤股票的K线，填充数据源的K线缓存（内部方法）
+func (r *Runner) primeScanner(ctx context.Context) error {
+	sys := r.system
+	cfg := sys.Config.Scanner
+
+	symbols, err := r.scanSymbols(ctx, cfg)
+	if err != nil {
+		sys.Logger.Warn("获取%s成分股失败: %v", cfg.Universe, err)
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -cfg.LookbackDays)
+	report, err := sys.Scanner.Prime(ctx, symbols, cfg.Strategies, from, to, cfg.Timeframe)
+	for key, reason := range report.Failed {
+		sys.Logger.Warn("预取%s的K线失败: %s", key, reason)
+	}
+	if err != nil {
+		return fmt.Errorf("scanner prime failed: %w", err)
+	}
+	sys.Logger.Info("扫描预热完成: %s", report)
+	return nil
+}
+
+// scanSymbols 返回扫描的股票：配置的股票加上指数或ETF的成分股，每轮扫描重新读取成分股（内部方法）
+// 获取成分股失败时只扫描配置的股票
+func (r *Runner) scanSymbols(ctx context.Context, cfg config.ScannerConfig) ([]string, error) {
+	if cfg.Universe == "" || r.system.Constituents == nil {
+		return cfg.Symbols, nil
+	}
+	constituents, err := r.system.Constituents.GetConstituents(ctx, cfg.Universe)
+	if err != nil {
+		return cfg.Symbols, err
+	}
+
+	symbols := append([]string(nil), cfg.Symbols...)
+	seen := make(map[string]bool, len(symbols)+len(constituents))
+	for _, symbol := range symbols {
+		seen[symbol] = true
+	}
+	for _, symbol := range datasource.ConstituentSymbols(constituents) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols, nil
+}
@@ -0,0 +1,153 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/config"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/logger"
+	"github.com/yourusername/qhft-system/pkg/monitoring"
+	"github.com/yourusername/qhft-system/pkg/schedule"
+	"github.com/yourusername/qhft-system/pkg/testutil"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// newTestSystem 创建只包含Runner所需组件的系统，不启动HTTP服务，交易引擎使用模拟券商（内部函数）
+func newTestSystem(t *testing.T) (*System, *testutil.MockBroker) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Shutdown.TimeoutSeconds = 5
+	cfg.Shutdown.CancelOpenOrders = true
+
+	log, _ := logger.NewCaptureLogger(logger.LogLevelDebug)
+	broker := testutil.NewMockBroker("")
+	engine := trading.NewBaseTradingEngine(nil, trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.Enable()
+	return &System{
+		Config:    cfg,
+		Logger:    log,
+		EventBus:  events.NewBus(),
+		Metrics:   monitoring.NewMetrics(),
+		Health:    monitoring.NewHealthChecker(time.Second),
+		Engine:    engine,
+		Scheduler: schedule.NewScheduler(nil),
+	}, broker
+}
+
+func TestRunnerShutdownOrder(t *testing.T) {
+	sys, broker := newTestSystem(t)
+	broker.Script(testutil.Accept())
+	order, err := sys.Engine.PlaceOrder(context.Background(), trading.OrderRequest{Symbol: "AAPL", Quantity: 10, Type: trading.OrderTypeLimit, Side: trading.OrderSideBuy, Price: 100})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+
+	var mu sync.Mutex
+	var steps []string
+	record := func(step string) {
+		mu.Lock()
+		defer mu.Unlock()
+		steps = append(steps, step)
+	}
+
+	runner := NewRunner(sys)
+	started := make(chan struct{})
+	runner.AddService(NewService("worker", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		// 服务停止时订单尚未撤销，交易引擎仍然启用
+		if len(broker.Canceled()) != 0 || !sys.Engine.IsEnabled() {
+			t.Error("服务应在撤单和禁用交易引擎之前停止")
+		}
+		record("service")
+		return nil
+	}))
+	for _, name := range []string{"first", "second"} {
+		name := name
+		runner.OnShutdown(name, func(ctx context.Context) error {
+			// 回调执行时未完成订单已撤销，交易引擎已禁用
+			if canceled := broker.Canceled(); len(canceled) != 1 || canceled[0].ID != order.ID {
+				t.Errorf("回调执行前应已撤销未完成订单: %+v", canceled)
+			}
+			if sys.Engine.IsEnabled() {
+				t.Error("回调执行前交易引擎应已禁用")
+			}
+			record(name)
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runner.Run(ctx) }()
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("正常关闭不应返回错误: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Runner没有在上下文取消后退出")
+	}
+	if got := strings.Join(steps, ","); got != "service,first,second" {
+		t.Errorf("关闭顺序不正确: %s", got)
+	}
+}
+
+func TestRunnerServiceError(t *testing.T) {
+	sys, _ := newTestSystem(t)
+	failure := errors.New("feed disconnected")
+	hookErr := errors.New("flush failed")
+
+	runner := NewRunner(sys)
+	stopped := make(chan struct{})
+	runner.AddService(NewService("feed", func(ctx context.Context) error {
+		return failure
+	}))
+	runner.AddService(NewService("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return nil
+	}))
+	hooked := false
+	runner.OnShutdown("flush", func(ctx context.Context) error {
+		hooked = true
+		return hookErr
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- runner.Run(context.Background()) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("服务失败后Runner应关闭系统并退出")
+	}
+
+	// 返回的错误同时包含服务错误和关闭回调的错误
+	if !errors.Is(err, failure) || !strings.Contains(err.Error(), "service feed failed") {
+		t.Errorf("应返回服务错误: %v", err)
+	}
+	if !errors.Is(err, hookErr) {
+		t.Errorf("应返回关闭回调的错误: %v", err)
+	}
+
+	// 其他服务停止，交易引擎禁用，关闭回调执行
+	select {
+	case <-stopped:
+	default:
+		t.Error("其他服务应已停止")
+	}
+	if sys.Engine.IsEnabled() || !hooked {
+		t.Errorf("服务失败后应完成关闭: enabled=%v hooked=%v", sys.Engine.IsEnabled(), hooked)
+	}
+}
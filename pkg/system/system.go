@@ -0,0 +1,805 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/chaos"
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/compliance"
+	"github.com/yourusername/qhft-system/pkg/config"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/fix"
+	"github.com/yourusername/qhft-system/pkg/gateway"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/logger"
+	"github.com/yourusername/qhft-system/pkg/messaging"
+	"github.com/yourusername/qhft-system/pkg/monitoring"
+	"github.com/yourusername/qhft-system/pkg/orchestrator"
+	"github.com/yourusername/qhft-system/pkg/report"
+	"github.com/yourusername/qhft-system/pkg/risk"
+	"github.com/yourusername/qhft-system/pkg/scanexport"
+	"github.com/yourusername/qhft-system/pkg/schedule"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// System 包含根据配置创建并相互连接好的系统组件
+type System struct {
+	Config       *config.Config
+	Logger       logger.Logger
+	TradeLogger  logger.TradeLogger
+	EventBus     *events.Bus
+	Metrics      *monitoring.Metrics
+	Health       *monitoring.HealthChecker
+	Orchestrator *orchestrator.Orchestrator // 未启用编排时为空
+	DataManager  *datasource.Manager
+	Symbols      *datasource.SymbolCache        // 未配置股票列表缓存时为空
+	Constituents datasource.ConstituentProvider // 未配置成分股目录时为空
+	Recorder     *datasource.Recorder           // 未启用行情录制时为空
+	Registry     *indicators.IndicatorRegistry
+	Scanner      *indicators.Scanner
+	Engine       *trading.BaseTradingEngine
+	WAL          *trading.FileWAL         // 未配置预写日志时为空
+	Equity       *trading.FileEquityStore // 未配置权益快照文件时为空
+	FIXBroker    *fix.Broker              // 未使用FIX券商时为空
+	Risk         *risk.Service            // 未启用风险服务时为空
+	Allocator    *risk.Allocator          // 未启用资金分配时为空
+	Watchlist    *trading.Watchlist
+	Scheduler    *schedule.Scheduler
+
+	// 消息总线组件，未启用时为空
+	Transport     messaging.Transport
+	Bridge        *messaging.Bridge
+	OrderConsumer *messaging.OrderConsumer
+
+	Webhooks *gateway.WebhookGateway // 未启用Webhook时为空
+
+	ScanSinks []scanexport.Sink // 扫描结果输出
+
+	// Clock 是延迟或回放模拟模式下的模拟时钟，引擎、监控列表、日志和数据源共用；其他模式下为空，使用系统时间
+	Clock clock.Clock
+}
+
+// NewSystemFromConfig 根据配置创建系统：初始化日志、数据源管理器、交易引擎、扫描器和监控列表，
+// 并将它们连接到同一个事件总线上
+func NewSystemFromConfig(cfg *config.Config) (*System, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &System{
+		Config:   cfg,
+		EventBus: events.NewBus(),
+		Metrics:  monitoring.NewMetrics(),
+	}
+
+	var err error
+	if s.Clock, err = paperClock(cfg.Paper); err != nil {
+		return nil, err
+	}
+	logConfig := cfg.Logging
+	logConfig.Clock = s.Clock
+	if s.Logger, err = logger.NewLogger(logConfig); err != nil {
+		return nil, fmt.Errorf("failed to create logger: %v", err)
+	}
+	if cfg.Paper.Enabled {
+		s.Logger = s.Logger.WithField(logger.SimulatedTag, true)
+	}
+
+	tradeLogOptions := cfg.TradeLog.TradeLoggerOptions
+	tradeLogOptions.Simulated = cfg.Paper.Enabled
+	tradeLogOptions.Clock = s.Clock
+	if s.TradeLogger, err = logger.NewTradeLoggerWithOptions(cfg.TradeLog.Dir, s.Logger, tradeLogOptions); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to create trade logger: %v", err)
+	}
+
+	if cfg.Recorder.Enabled {
+		if s.Recorder, err = datasource.NewRecorder(cfg.Recorder.Dir, int64(cfg.Recorder.MaxFileMB)<<20); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	if s.DataManager, err = newDataManager(cfg, s.Clock, s.Metrics, s.Recorder); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	s.Registry = indicators.NewIndicatorRegistry()
+	s.Scanner = indicators.NewScanner(s.Registry, s.DataManager)
+	s.Scanner.SetEventBus(s.EventBus)
+	for _, name := range sortedKeys(cfg.Strategies) {
+		sc := cfg.Strategies[name]
+		if err := s.Scanner.AddStrategy(indicators.Strategy{
+			Name:       name,
+			Enabled:    sc.Enabled,
+			Indicators: sc.Indicators,
+			Filters:    sc.Filters,
+			Universe:   sc.Universe,
+		}); err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to add strategy %s: %v", name, err)
+		}
+	}
+
+	brokerConfig := cfg.Trading.Broker
+	if cfg.Paper.Enabled {
+		brokerConfig.IsPaperTrading = true
+	}
+	s.Engine = trading.NewBaseTradingEngine(s.DataManager, brokerConfig, cfg.Trading.Limits)
+	s.Engine.SetClock(s.Clock)
+	if broker, ok := s.Engine.GetBroker().(*trading.SimulatedBroker); ok {
+		broker.SetClock(s.Clock)
+	}
+	s.Engine.SetTradeLogger(s.TradeLogger)
+	s.Engine.SetLogger(s.Logger)
+	s.Engine.SetRounding(cfg.Trading.Rounding)
+	s.Engine.SetRateGuard(cfg.Trading.RateGuard)
+	s.Engine.SetHaltDetection(cfg.Trading.Halts)
+	s.Engine.SetShortSaleChecks(cfg.Trading.ShortSale)
+	s.Engine.SetAccrual(cfg.Trading.Accrual)
+	s.Engine.SetDividendSource(trading.NewCorporateActionDividends(s.DataManager))
+	s.Engine.SetAccountWatch(cfg.Trading.AccountWatch)
+	// 模拟模式下保留引擎默认的模拟券商，不连接真实券商
+	if cfg.Trading.Broker.Name == config.BrokerFIX && !cfg.Paper.Enabled {
+		if s.FIXBroker, err = fix.NewBroker(cfg.Trading.FIX); err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.Engine.SetBroker(s.FIXBroker)
+		if err := s.FIXBroker.Start(); err != nil {
+			s.FIXBroker = nil
+			s.Close()
+			return nil, err
+		}
+	}
+	if cfg.Chaos.Enabled {
+		s.Logger.Warn("故障注入已启用，券商和数据源会按配置随机出错")
+		s.Engine.SetBroker(chaos.NewBroker(s.Engine.GetBroker(), cfg.Chaos.Broker, cfg.Chaos.Seed))
+	}
+	if walPath := cfg.Trading.WALPath; walPath != "" {
+		// 模拟模式使用独立的预写日志，避免与实盘状态混在一起
+		if cfg.Paper.Enabled {
+			walPath += ".paper"
+		}
+		if err := s.recoverEngine(walPath, cfg.Trading.WALSync); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	if equityPath := cfg.Trading.EquityPath; equityPath != "" {
+		if cfg.Paper.Enabled {
+			equityPath += ".paper"
+		}
+		if s.Equity, err = trading.OpenFileEquityStore(equityPath); err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.Engine.SetEquityStore(s.Equity)
+	}
+	s.Engine.SetEventBus(s.EventBus)
+	if err := s.Metrics.WatchEngine(s.Engine); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to register engine metrics: %v", err)
+	}
+	if cfg.Trading.Enabled {
+		if err := s.Engine.Enable(); err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to enable trading engine: %v", err)
+		}
+	}
+
+	if cfg.Risk.Enabled {
+		s.Risk = risk.NewService(s.Engine, s.DataManager, risk.Config{
+			Interval:     time.Duration(cfg.Risk.IntervalSeconds) * time.Second,
+			Benchmark:    cfg.Risk.Benchmark,
+			LookbackDays: cfg.Risk.LookbackDays,
+			Confidence:   cfg.Risk.Confidence,
+			HorizonDays:  cfg.Risk.HorizonDays,
+			Limits:       cfg.Risk.Limits,
+		})
+		s.Risk.SetEventBus(s.EventBus)
+		s.Engine.AddPreTradeCheck(s.Risk.CheckOrder)
+		s.Engine.Use(s.Risk.CorrelationMiddleware())
+	}
+	if cfg.Risk.Allocation.Enabled {
+		s.Allocator = risk.NewAllocator(s.Engine, cfg.Risk.Allocation.AllocationPolicy)
+		s.Allocator.SetClock(s.Clock)
+		s.Allocator.SetEventBus(s.EventBus)
+	}
+
+	s.Watchlist = trading.NewWatchlist(s.Engine, s.DataManager)
+	s.Watchlist.SetClock(s.Clock)
+	s.Watchlist.SetEventBus(s.EventBus)
+
+	if cfg.Orchestrator.Enabled {
+		s.Orchestrator = orchestrator.NewOrchestrator(s.Engine, s.Scanner, s.DataManager, orchestrator.Config{
+			Interval:       time.Duration(cfg.Orchestrator.IntervalSeconds) * time.Second,
+			ConflictPolicy: cfg.Orchestrator.ConflictPolicy,
+		})
+		for _, allocation := range cfg.Orchestrator.Allocations {
+			if err := s.Orchestrator.AddAllocation(allocation); err != nil {
+				s.Close()
+				return nil, fmt.Errorf("failed to add allocation: %v", err)
+			}
+		}
+	}
+
+	if cfg.Messaging.Enabled {
+		mc := cfg.Messaging
+		if s.Transport, err = messaging.NewTransport(mc.Driver, mc.URL, mc.Group); err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.Bridge = messaging.NewBridge(s.EventBus, s.Transport, messaging.BridgeConfig{
+			Source:       mc.Source,
+			Prefix:       mc.Prefix,
+			ExportTopics: mc.ExportTopics,
+			ImportTopics: mc.ImportTopics,
+		})
+		if mc.AcceptOrders {
+			s.OrderConsumer = messaging.NewOrderConsumer(s.Engine, s.Transport, mc.Prefix)
+		}
+	}
+
+	for i, sc := range cfg.Scanner.Sinks {
+		sink, err := scanexport.NewSink(sc, s.Transport, cfg.Messaging.Prefix)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to create scanner sink %d: %v", i, err)
+		}
+		s.ScanSinks = append(s.ScanSinks, sink)
+	}
+
+	if cfg.Webhooks.Enabled {
+		s.Webhooks = gateway.NewWebhookGateway(s.Engine, s.Watchlist, s.EventBus)
+		for _, source := range cfg.Webhooks.Sources {
+			if err := s.Webhooks.AddSource(source); err != nil {
+				s.Close()
+				return nil, err
+			}
+		}
+	}
+
+	calendar, err := cfg.Schedule.Calendar()
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	s.Scheduler = schedule.NewScheduler(calendar)
+	s.Engine.SetCalendar(calendar)
+	s.Scheduler.SetEventBus(s.EventBus)
+	if cfg.Schedule.EODSummary != "" {
+		if err := s.Scheduler.Add("eod_summary", cfg.Schedule.EODSummary, s.logDailySummary); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	if cfg.Universes.Dir != "" {
+		s.Constituents = datasource.NewFileConstituents(cfg.Universes.Dir)
+		s.Scanner.SetConstituents(s.Constituents)
+	}
+	if cfg.Symbols.CachePath != "" {
+		if err := s.initSymbolCache(cfg.Symbols); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	if cfg.Recorder.Enabled && cfg.Recorder.Verify != "" {
+		if err := s.Scheduler.Add("recorder_verify", cfg.Recorder.Verify, s.verifyRecordings); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	if cfg.Compliance.Enabled {
+		if err := s.Scheduler.Add("compliance_export", cfg.Compliance.Schedule, s.exportOrderEvents); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	if cfg.Statements.Enabled {
+		if err := s.Scheduler.Add("monthly_statement", cfg.Statements.Schedule, s.writeMonthlyStatement); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	if cfg.Trading.Accrual.Enabled {
+		if err := s.Scheduler.Add("accruals", cfg.Trading.Accrual.Schedule, s.Engine.RunAccruals); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	if s.Allocator != nil {
+		if err := s.Scheduler.Add("strategy_allocation", cfg.Risk.Allocation.Schedule, s.rebalanceStrategies); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	s.Health = newHealthChecker(cfg, s)
+
+	s.Logger.WithFields(map[string]interface{}{
+		"datasources":   len(s.DataManager.GetAllDataSources()),
+		"strategies":    len(cfg.Strategies),
+		"paper_trading": brokerConfig.IsPaperTrading,
+		"paper_mode":    cfg.Paper.Enabled,
+	}).Info("系统初始化完成")
+
+	return s, nil
+}
+
+// initSymbolCache 读取股票列表缓存，并注册按日历刷新缓存的定时任务（内部方法）
+func (s *System) initSymbolCache(cfg config.SymbolsConfig) error {
+	source, err := s.DataManager.GetPrimaryDataSource()
+	if err != nil {
+		return fmt.Errorf("symbol cache requires a primary data source: %v", err)
+	}
+	s.Symbols = datasource.NewSymbolCache(cfg.CachePath, source)
+	s.Symbols.SetEventBus(s.EventBus)
+	if err := s.Symbols.Load(); err != nil {
+		return err
+	}
+	if cfg.Refresh == "" {
+		return nil
+	}
+	return s.Scheduler.Add("symbols_refresh", cfg.Refresh, s.refreshSymbols)
+}
+
+// refreshSymbols 刷新股票列表缓存并记录新上市和退市的股票（内部方法）
+func (s *System) refreshSymbols(ctx context.Context) error {
+	ctx = datasource.WithProgress(ctx, func(p datasource.Progress) {
+		s.Logger.WithFields(map[string]interface{}{
+			"pages":  p.Done,
+			"stocks": p.Items,
+		}).Debug("正在下载股票列表")
+	})
+	changes, err := s.Symbols.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh symbols: %w", err)
+	}
+	s.Logger.WithFields(map[string]interface{}{
+		"total":   changes.Total,
+		"added":   len(changes.Added),
+		"removed": len(changes.Removed),
+		"changed": len(changes.Changed),
+	}).Info("股票列表已刷新")
+	return nil
+}
+
+// logAccountAlerts 订阅 risk 主题，把账户快照告警写入日志，直到上下文取消（内部方法）
+func (s *System) logAccountAlerts(ctx context.Context) {
+	sub := s.EventBus.Subscribe(64, events.TopicRisk)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			alert, ok := evt.Payload.(trading.AccountAlert)
+			if !ok {
+				continue
+			}
+			s.Logger.WithFields(map[string]interface{}{
+				"type":         alert.Type,
+				"equity":       alert.Current.Equity,
+				"buying_power": alert.Current.BuyingPower,
+				"changes":      len(alert.Changes),
+			}).Warn("账户异常: %s", alert.Message)
+		}
+	}
+}
+
+// verifyRecordings 抽查录制的K线与主数据源是否一致，记录不一致和修复的股票（内部方法）
+func (s *System) verifyRecordings(ctx context.Context) error {
+	provider, err := s.DataManager.GetPrimaryDataSource()
+	if err != nil {
+		return err
+	}
+	cfg := s.Config.Recorder
+	local := datasource.NewRecordedDataSource("recordings", cfg.Dir)
+	report, err := datasource.VerifyRecording(ctx, local, provider, datasource.IntegrityOptions{
+		Samples: cfg.VerifySamples,
+		Days:    cfg.VerifyDays,
+		Repair:  cfg.Repair,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify recordings: %w", err)
+	}
+
+	for _, check := range report.Checks {
+		if !check.Drift && check.Error == "" {
+			continue
+		}
+		s.Logger.WithFields(map[string]interface{}{
+			"symbol":        check.Symbol,
+			"date":          check.Date,
+			"local_bars":    check.LocalBars,
+			"provider_bars": check.ProviderBars,
+			"corrupt_lines": check.CorruptLines,
+			"repaired":      check.Repaired,
+			"error":         check.Error,
+		}).Warn("录制数据与数据源不一致")
+	}
+	s.Logger.WithFields(map[string]interface{}{
+		"checked":  len(report.Checks),
+		"drifted":  report.Drifted,
+		"repaired": report.Repaired,
+		"failed":   report.Failed,
+	}).Info("录制数据校验完成")
+	return nil
+}
+
+// recoverEngine 打开预写日志并回放以恢复引擎状态（内部方法）
+func (s *System) recoverEngine(path string, syncWrites bool) error {
+	wal, err := trading.OpenFileWAL(path, syncWrites)
+	if err != nil {
+		return err
+	}
+	s.WAL = wal
+	s.Engine.SetWAL(wal)
+
+	report, err := s.Engine.Recover()
+	if err != nil {
+		return err
+	}
+
+	s.Logger.WithFields(map[string]interface{}{
+		"records":   report.Records,
+		"orders":    report.Orders,
+		"positions": report.Positions,
+		"trades":    report.Trades,
+	}).Info("已从预写日志恢复引擎状态")
+	for _, order := range report.Unresolved {
+		s.Logger.Warn("订单%s(%s)在提交券商时中断，需要与券商对账", order.ID, order.Symbol)
+	}
+	return nil
+}
+
+// exportOrderEvents 从预写日志导出当天的订单记录簿（内部方法）
+func (s *System) exportOrderEvents(ctx context.Context) error {
+	if s.WAL == nil {
+		return fmt.Errorf("compliance export requires the engine wal")
+	}
+	loc := s.Scheduler.Calendar().Location()
+	path, count, err := compliance.ExportDay(s.WAL, s.Config.Compliance.Dir, clock.Or(s.Clock).Now(), loc)
+	if err != nil {
+		return fmt.Errorf("failed to export order events: %w", err)
+	}
+	s.Logger.WithFields(map[string]interface{}{
+		"path":   path,
+		"events": count,
+	}).Info("已导出订单记录簿")
+	return nil
+}
+
+// rebalanceStrategies 按策略近期业绩调整资金分配并更新交易引擎的策略限制（内部方法）
+func (s *System) rebalanceStrategies(ctx context.Context) error {
+	result, err := s.Allocator.Rebalance(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to rebalance strategies: %w", err)
+	}
+	weights := make(map[string]interface{}, len(result.Strategies))
+	for _, allocation := range result.Strategies {
+		weights[allocation.Strategy] = fmt.Sprintf("%.1f%%", allocation.Weight)
+	}
+	s.Logger.WithFields(weights).Info("已调整策略资金分配")
+	return nil
+}
+
+// writeMonthlyStatement 在每月最后一个交易日生成当月的对账单，其他日期直接返回（内部方法）
+// 引擎只保存当前持仓，月末持仓取自运行时的持仓，因此对账单需要在月末收盘后生成
+func (s *System) writeMonthlyStatement(ctx context.Context) error {
+	calendar := s.Scheduler.Calendar()
+	now := clock.Or(s.Clock).Now().In(calendar.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !calendar.IsTradingDay(today) {
+		return nil
+	}
+	if next, ok := calendar.NextSession(today.AddDate(0, 0, 1)); ok && next.Date.Month() == today.Month() {
+		return nil
+	}
+
+	positions, err := s.Engine.GetPositions(ctx)
+	if err != nil {
+		return err
+	}
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	trades, err := s.Engine.GetTrades(ctx, "", time.Time{}, now)
+	if err != nil {
+		return err
+	}
+	equity, err := s.Engine.GetEquityHistory(ctx, start, now)
+	if err != nil {
+		return err
+	}
+	ledger, err := s.Engine.GetLedger(ctx, start, now)
+	if err != nil {
+		return err
+	}
+	account := s.Config.Statements.Account
+	if account == "" {
+		account = s.Config.Trading.Broker.AccountID
+	}
+	statement := report.BuildMonthly(report.MonthlyInput{
+		Account:   account,
+		Month:     now,
+		Positions: positions,
+		Trades:    trades,
+		Equity:    equity,
+		Ledger:    ledger,
+	}, now)
+
+	if err := os.MkdirAll(s.Config.Statements.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create statements dir: %w", err)
+	}
+	path := filepath.Join(s.Config.Statements.Dir, report.FileName(start))
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create statement: %w", err)
+	}
+	if err := report.WritePDF(file, statement); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write statement: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write statement: %w", err)
+	}
+	s.Logger.WithFields(map[string]interface{}{
+		"path":   path,
+		"trades": len(statement.Trades),
+	}).Info("已生成月度对账单")
+	return nil
+}
+
+// logDailySummary 根据交易日的交易统计和账户权益写入每日汇总（内部方法）
+func (s *System) logDailySummary(ctx context.Context) error {
+	now := time.Now().In(s.Scheduler.Calendar().Location())
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	stats, err := s.Engine.GetTradeStats(ctx, day, now)
+	if err != nil {
+		return err
+	}
+	account, err := s.Engine.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	summary := logger.DailySummary{
+		Date:               day,
+		TotalTrades:        stats.TotalTrades,
+		WinningTrades:      stats.WinningTrades,
+		LosingTrades:       stats.LosingTrades,
+		WinRate:            stats.WinRate * 100,
+		GrossProfit:        stats.AverageProfit * float64(stats.WinningTrades),
+		GrossLoss:          stats.AverageLoss * float64(stats.LosingTrades),
+		LargestWin:         stats.LargestWin,
+		LargestLoss:        stats.LargestLoss,
+		AverageWin:         stats.AverageProfit,
+		AverageLoss:        stats.AverageLoss,
+		ProfitFactor:       stats.ProfitFactor,
+		AverageHoldingTime: stats.AverageHoldTime,
+		FinalEquity:        account.Equity,
+	}
+	summary.NetProfit = summary.GrossProfit - summary.GrossLoss
+	if stats.TotalTrades > 0 {
+		summary.AverageTrade = summary.NetProfit / float64(stats.TotalTrades)
+	}
+	if account.LastEquity > 0 {
+		summary.DailyReturn = (account.Equity - account.LastEquity) / account.LastEquity * 100
+	}
+
+	return s.TradeLogger.LogSummary(summary)
+}
+
+// newDataManager 根据配置创建数据源管理器，时间戳统一转换到交易所时区，报价标注交易时段，
+// 每个数据源的请求都会记录到指标中；recorder不为空时录制从真实数据源获取的行情，
+// 配置了扫描预热时缓存K线；simClock不为空时数据源只返回模拟时钟之前的数据（内部函数）
+func newDataManager(cfg *config.Config, simClock clock.Clock, metrics *monitoring.Metrics, recorder *datasource.Recorder) (*datasource.Manager, error) {
+	calendar, err := cfg.Schedule.Calendar()
+	if err != nil {
+		return nil, err
+	}
+	manager := datasource.NewManager()
+
+	for _, key := range sortedKeys(cfg.DataSources) {
+		ds := cfg.DataSources[key]
+		if !ds.Enabled {
+			continue
+		}
+
+		var source datasource.DataSource
+		switch ds.Type {
+		case config.DataSourceTypePolygon:
+			polygon, err := datasource.NewPolygonDataSource(ds.DataSourceConfig)
+			if err != nil {
+				manager.Close()
+				return nil, fmt.Errorf("failed to create data source %s: %v", key, err)
+			}
+			source = polygon
+		case config.DataSourceTypeFinnhub:
+			finnhub, err := datasource.NewFinnhubDataSource(ds.DataSourceConfig)
+			if err != nil {
+				manager.Close()
+				return nil, fmt.Errorf("failed to create data source %s: %v", key, err)
+			}
+			source = finnhub
+		case config.DataSourceTypeIBKR:
+			ibkr, err := datasource.NewIBKRDataSource(ds.DataSourceConfig)
+			if err != nil {
+				manager.Close()
+				return nil, fmt.Errorf("failed to create data source %s: %v", key, err)
+			}
+			source = ibkr
+		case config.DataSourceTypeRecording:
+			source = datasource.NewRecordedDataSource(ds.Name, ds.Path)
+		default:
+			manager.Close()
+			return nil, fmt.Errorf("unsupported data source type %q", ds.Type)
+		}
+		if cfg.Chaos.Enabled {
+			// 故障注入包装在最内层，相当于网络请求出错，录制和缓存等包装照常处理错误
+			source = chaos.NewDataSource(source, cfg.Chaos.DataSource, cfg.Chaos.Seed)
+		}
+
+		var sourceLoc *time.Location
+		if ds.Timezone != "" {
+			if sourceLoc, err = time.LoadLocation(ds.Timezone); err != nil {
+				manager.Close()
+				return nil, fmt.Errorf("invalid timezone for data source %s: %v", key, err)
+			}
+		}
+		source = datasource.NewTimezoneDataSource(source, calendar.Location(), sourceLoc)
+		if recorder != nil && ds.Type != config.DataSourceTypeRecording {
+			source = datasource.NewRecordingDataSource(source, recorder)
+		}
+
+		if simClock != nil {
+			source = datasource.NewClockDataSource(source, simClock)
+		}
+		source = datasource.NewSessionDataSource(source, calendar)
+		if cfg.Scanner.Prime != "" {
+			source = datasource.NewBarCacheDataSource(source)
+		}
+
+		if err := manager.AddDataSource(metrics.InstrumentDataSource(source)); err != nil {
+			manager.Close()
+			return nil, err
+		}
+
+		if ds.Primary {
+			if err := manager.SetPrimaryDataSource(ds.Name); err != nil {
+				manager.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if r := cfg.QuoteRouting; r.Enabled {
+		manager.EnableQuoteRouting(datasource.QuoteRoutingOptions{
+			Window:         r.Window,
+			SwitchMargin:   r.SwitchMargin,
+			MinSuccessRate: r.MinSuccessRate,
+			ProbeInterval:  time.Duration(r.ProbeSeconds) * time.Second,
+		})
+	}
+
+	// 配置已校验，复权方式有效
+	adjustment, _ := datasource.ParseAdjustment(cfg.CorporateActions.Adjustment)
+	manager.SetAdjustment(adjustment)
+
+	return manager, nil
+}
+
+// paperClock 按模拟模式配置创建模拟时钟，延迟模式落后系统时间，回放模式从历史时间点按倍速前进；
+// 其他模式返回空，使用系统时间（内部函数）
+func paperClock(paper config.PaperConfig) (clock.Clock, error) {
+	if !paper.Enabled {
+		return nil, nil
+	}
+	switch paper.Data {
+	case config.PaperDataDelayed:
+		return clock.Delayed(time.Duration(paper.DelaySeconds) * time.Second), nil
+	case config.PaperDataReplay:
+		start, err := paper.ReplayStartTime()
+		if err != nil {
+			return nil, err
+		}
+		return clock.NewReplay(start, paper.ReplaySpeed), nil
+	default:
+		return nil, nil
+	}
+}
+
+// newHealthChecker 创建汇总数据源、券商、时钟和引擎状态的健康检查器（内部函数）
+func newHealthChecker(cfg *config.Config, s *System) *monitoring.HealthChecker {
+	health := monitoring.NewHealthChecker(time.Duration(cfg.Monitoring.HealthCheckTimeoutSeconds) * time.Second)
+	health.AddLivenessCheck("datasources", monitoring.DataSourceCheck(s.DataManager))
+	health.AddLivenessCheck("broker", func(ctx context.Context) monitoring.CheckResult {
+		// 每次检查时读取券商，以便支持运行时通过SetBroker替换
+		return monitoring.BrokerCheck(s.Engine.GetBroker())(ctx)
+	})
+	if cfg.Monitoring.ClockReferenceURL != "" {
+		health.AddLivenessCheck("clock_skew", monitoring.ClockSkewCheck(
+			monitoring.HTTPDateClock(nil, cfg.Monitoring.ClockReferenceURL),
+			time.Duration(cfg.Monitoring.MaxClockSkewMillis)*time.Millisecond,
+		))
+	}
+	health.AddReadinessCheck("engine", monitoring.EngineEnabledCheck(s.Engine))
+	return health
+}
+
+// Close 关闭系统组件，返回关闭过程中遇到的错误
+func (s *System) Close() error {
+	var errs []error
+
+	if s.FIXBroker != nil {
+		s.FIXBroker.Stop()
+	}
+	if s.Transport != nil {
+		if err := s.Transport.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.DataManager != nil {
+		if err := s.DataManager.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.Recorder != nil {
+		if err := s.Recorder.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.WAL != nil {
+		if err := s.WAL.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.Equity != nil {
+		if err := s.Equity.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.TradeLogger != nil {
+		if err := s.TradeLogger.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if s.Logger != nil {
+		if err := s.Logger.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sortedKeys 返回排序后的键名，保证初始化顺序稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,180 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// Fill 表示模拟券商对一个订单的预设处理结果
+type Fill struct {
+	Status       trading.OrderStatus // 订单状态，为空时为 filled
+	Quantity     int64               // 成交数量，为0且状态为 filled 时全部成交
+	Price        float64             // 成交价，为0时使用 SetPrice 设置的价格或订单价格
+	Commission   float64
+	RejectReason string
+	Err          error // 不为空时 SubmitOrder 返回该错误，如模拟网络故障
+}
+
+// Reject 返回以reason拒绝订单的预设结果
+func Reject(reason string) Fill {
+	return Fill{Status: trading.OrderStatusRejected, RejectReason: reason}
+}
+
+// Accept 返回只接受订单、不立即成交的预设结果，之后可以用 Push 推送成交
+func Accept() Fill {
+	return Fill{Status: trading.OrderStatusAccepted}
+}
+
+// PartialFill 返回按price部分成交quantity股的预设结果
+func PartialFill(quantity int64, price float64) Fill {
+	return Fill{Status: trading.OrderStatusPartial, Quantity: quantity, Price: price}
+}
+
+// MockBroker 是按脚本处理订单的券商：预设的结果按下单顺序依次使用，用完后按默认价格全部成交。
+// 记录收到的订单和撤单，并实现 trading.ExecutionStream，可以用 Push 模拟券商异步推送的回报
+type MockBroker struct {
+	mu       sync.Mutex
+	name     string
+	script   []Fill
+	prices   map[string]float64
+	orders   []trading.Order
+	canceled []trading.Order
+	health   error
+	updates  chan trading.OrderUpdate
+	seq      int
+}
+
+// NewMockBroker 创建一个模拟券商，名称为空时为 mock
+func NewMockBroker(name string) *MockBroker {
+	if name == "" {
+		name = "mock"
+	}
+	return &MockBroker{
+		name:    name,
+		prices:  make(map[string]float64),
+		updates: make(chan trading.OrderUpdate, 100),
+	}
+}
+
+// Script 追加预设的处理结果，按下单顺序依次使用
+func (b *MockBroker) Script(fills ...Fill) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.script = append(b.script, fills...)
+}
+
+// SetPrice 设置股票的默认成交价，没有设置时按订单价格成交
+func (b *MockBroker) SetPrice(symbol string, price float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prices[symbol] = price
+}
+
+// SetHealth 设置 HealthCheck 返回的错误
+func (b *MockBroker) SetHealth(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.health = err
+}
+
+// Orders 返回收到的所有订单，按下单顺序排列
+func (b *MockBroker) Orders() []trading.Order {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]trading.Order(nil), b.orders...)
+}
+
+// Canceled 返回收到撤单请求的订单
+func (b *MockBroker) Canceled() []trading.Order {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]trading.Order(nil), b.canceled...)
+}
+
+// Name 返回券商名称
+func (b *MockBroker) Name() string {
+	return b.name
+}
+
+// SubmitOrder 按下一个预设结果处理订单，没有预设结果时全部成交
+func (b *MockBroker) SubmitOrder(ctx context.Context, order trading.Order) (*trading.Order, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	order.BrokerOrderID = fmt.Sprintf("mock-%d", b.seq)
+	b.orders = append(b.orders, order)
+
+	var fill Fill
+	if len(b.script) > 0 {
+		fill = b.script[0]
+		b.script = b.script[1:]
+	}
+	if fill.Err != nil {
+		return nil, fill.Err
+	}
+	if fill.Status == "" {
+		fill.Status = trading.OrderStatusFilled
+	}
+
+	now := time.Now()
+	order.Status = fill.Status
+	order.RejectReason = fill.RejectReason
+	order.UpdatedAt = now
+	switch fill.Status {
+	case trading.OrderStatusFilled, trading.OrderStatusPartial:
+		order.FilledQty = fill.Quantity
+		if order.FilledQty == 0 && fill.Status == trading.OrderStatusFilled {
+			order.FilledQty = order.Quantity
+		}
+		order.AvgFillPrice = b.fillPrice(order, fill)
+		order.Commission = fill.Commission
+		order.FilledAt = &now
+	}
+	return &order, nil
+}
+
+// fillPrice 返回预设的成交价、默认价格或订单价格，调用方持锁（内部方法）
+func (b *MockBroker) fillPrice(order trading.Order, fill Fill) float64 {
+	if fill.Price > 0 {
+		return fill.Price
+	}
+	if price, ok := b.prices[order.Symbol]; ok {
+		return price
+	}
+	return order.Price
+}
+
+// CancelOrder 记录撤单请求，总是成功
+func (b *MockBroker) CancelOrder(ctx context.Context, order trading.Order) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.canceled = append(b.canceled, order)
+	return nil
+}
+
+// HealthCheck 返回 SetHealth 设置的错误
+func (b *MockBroker) HealthCheck(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.health
+}
+
+// OrderUpdates 返回 Push 推送的订单状态变化
+func (b *MockBroker) OrderUpdates() <-chan trading.OrderUpdate {
+	return b.updates
+}
+
+// Push 推送一次订单状态变化，模拟券商异步回报，通道满时阻塞
+func (b *MockBroker) Push(update trading.OrderUpdate) {
+	b.updates <- update
+}
+
+// Close 关闭订单状态变化通道
+func (b *MockBroker) Close() {
+	close(b.updates)
+}
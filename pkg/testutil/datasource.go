@@ -0,0 +1,360 @@
+// Package testutil 提供不需要网络的模拟数据源和模拟券商，用于单元测试策略和交易引擎
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// MockDataSource 是返回预设K线和报价的数据源，结果只取决于预设的数据，并记录每个方法的调用次数
+type MockDataSource struct {
+	mu      sync.Mutex
+	name    string
+	enabled bool
+	bars    map[string][]datasource.StockData // 键为 股票/周期
+	quotes  map[string]*datasource.Quote
+	history map[string][]datasource.Quote
+	trades  map[string][]datasource.TradePrint
+	options map[string][]datasource.OptionQuote
+	funds   map[string]*datasource.Fundamentals
+	actions map[string]datasource.CorporateActions
+	stocks  []datasource.Stock
+	errs    map[string]error
+	calls   map[string]int
+}
+
+// NewMockDataSource 创建一个启用的模拟数据源，名称为空时为 mock
+func NewMockDataSource(name string) *MockDataSource {
+	if name == "" {
+		name = "mock"
+	}
+	return &MockDataSource{
+		name:    name,
+		enabled: true,
+		bars:    make(map[string][]datasource.StockData),
+		quotes:  make(map[string]*datasource.Quote),
+		history: make(map[string][]datasource.Quote),
+		trades:  make(map[string][]datasource.TradePrint),
+		options: make(map[string][]datasource.OptionQuote),
+		funds:   make(map[string]*datasource.Fundamentals),
+		actions: make(map[string]datasource.CorporateActions),
+		errs:    make(map[string]error),
+		calls:   make(map[string]int),
+	}
+}
+
+// SetBars 设置股票某周期的K线，按时间升序保存
+func (m *MockDataSource) SetBars(symbol, timeframe string, bars []datasource.StockData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sorted := append([]datasource.StockData(nil), bars...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	m.bars[symbol+"/"+timeframe] = sorted
+}
+
+// SetQuote 设置股票的实时报价
+func (m *MockDataSource) SetQuote(quote datasource.Quote) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.quotes[quote.Symbol] = &quote
+}
+
+// SetHistoricalQuotes 设置股票的历史报价，按时间升序保存
+func (m *MockDataSource) SetHistoricalQuotes(symbol string, quotes []datasource.Quote) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sorted := append([]datasource.Quote(nil), quotes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	m.history[symbol] = sorted
+}
+
+// SetTrades 设置股票的逐笔成交，按时间升序保存
+func (m *MockDataSource) SetTrades(symbol string, trades []datasource.TradePrint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sorted := append([]datasource.TradePrint(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	m.trades[symbol] = sorted
+}
+
+// SetOptionChain 设置标的股票的期权链，按到期日和行权价排序保存
+func (m *MockDataSource) SetOptionChain(underlying string, chain []datasource.OptionQuote) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sorted := append([]datasource.OptionQuote(nil), chain...)
+	datasource.SortOptionChain(sorted)
+	m.options[underlying] = sorted
+}
+
+// SetFundamentals 设置股票的基本面数据
+func (m *MockDataSource) SetFundamentals(fundamentals datasource.Fundamentals) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.funds[fundamentals.Symbol] = &fundamentals
+}
+
+// SetCorporateActions 设置股票的拆股和分红
+func (m *MockDataSource) SetCorporateActions(actions datasource.CorporateActions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.actions[actions.Symbol] = actions
+}
+
+// SetStocks 设置 GetAllStocks 返回的股票列表
+func (m *MockDataSource) SetStocks(stocks []datasource.Stock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stocks = append([]datasource.Stock(nil), stocks...)
+}
+
+// SetEnabled 设置数据源是否启用
+func (m *MockDataSource) SetEnabled(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enabled = enabled
+}
+
+// FailWith 让指定方法（如 "GetRealTimeQuote"）返回err，err为nil时恢复正常
+func (m *MockDataSource) FailWith(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil {
+		delete(m.errs, method)
+		return
+	}
+	m.errs[method] = err
+}
+
+// Calls 返回指定方法被调用的次数
+func (m *MockDataSource) Calls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+// call 记录一次方法调用并返回该方法预设的错误（内部方法）
+func (m *MockDataSource) call(method string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls[method]++
+	return m.errs[method]
+}
+
+// Name 返回数据源名称
+func (m *MockDataSource) Name() string {
+	return m.name
+}
+
+// IsEnabled 检查数据源是否启用
+func (m *MockDataSource) IsEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enabled
+}
+
+// HealthCheck 没有预设错误时总是健康
+func (m *MockDataSource) HealthCheck(ctx context.Context) (bool, error) {
+	if err := m.call("HealthCheck"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetStockData 返回[from, to]内预设的K线
+func (m *MockDataSource) GetStockData(ctx context.Context, symbol string, timeframe string, from, to time.Time) ([]datasource.StockData, error) {
+	if err := m.call("GetStockData"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var data []datasource.StockData
+	for _, bar := range m.bars[symbol+"/"+timeframe] {
+		if !bar.Timestamp.Before(from) && !bar.Timestamp.After(to) {
+			data = append(data, bar)
+		}
+	}
+	return data, nil
+}
+
+// GetMultipleStockData 逐个返回多只股票预设的K线
+func (m *MockDataSource) GetMultipleStockData(ctx context.Context, symbols []string, timeframe string, from, to time.Time) (map[string][]datasource.StockData, error) {
+	return datasource.FetchStockData(ctx, m.Name(), func(ctx context.Context, symbol string) ([]datasource.StockData, error) {
+		return m.GetStockData(ctx, symbol, timeframe, from, to)
+	}, symbols)
+}
+
+// GetRealTimeQuote 返回预设的报价，没有预设报价时返回代码为 NO_DATA 的错误
+func (m *MockDataSource) GetRealTimeQuote(ctx context.Context, symbol string) (*datasource.Quote, error) {
+	if err := m.call("GetRealTimeQuote"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	quote, ok := m.quotes[symbol]
+	if !ok {
+		return nil, &datasource.DataSourceError{
+			Source:  m.name,
+			Code:    "NO_DATA",
+			Message: fmt.Sprintf("no quote for %s", symbol),
+			Time:    time.Now(),
+		}
+	}
+	copied := *quote
+	return &copied, nil
+}
+
+// GetRealTimeQuotes 逐个返回多只股票预设的报价
+func (m *MockDataSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*datasource.Quote, error) {
+	return datasource.FetchQuotes(ctx, m.GetRealTimeQuote, symbols, 1)
+}
+
+// GetHistoricalQuotes 返回[from, to]内预设的历史报价
+func (m *MockDataSource) GetHistoricalQuotes(ctx context.Context, symbol string, from, to time.Time) ([]datasource.Quote, error) {
+	if err := m.call("GetHistoricalQuotes"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var quotes []datasource.Quote
+	for _, quote := range m.history[symbol] {
+		if !quote.Timestamp.Before(from) && !quote.Timestamp.After(to) {
+			quotes = append(quotes, quote)
+		}
+	}
+	return quotes, nil
+}
+
+// GetTrades 返回[from, to]内预设的逐笔成交
+func (m *MockDataSource) GetTrades(ctx context.Context, symbol string, from, to time.Time) ([]datasource.TradePrint, error) {
+	if err := m.call("GetTrades"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var trades []datasource.TradePrint
+	for _, trade := range m.trades[symbol] {
+		if !trade.Timestamp.Before(from) && !trade.Timestamp.After(to) {
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+// GetOptionChain 返回预设的期权链，expiry 不为零值时只返回该到期日的合约
+func (m *MockDataSource) GetOptionChain(ctx context.Context, underlying string, expiry time.Time) ([]datasource.OptionQuote, error) {
+	if err := m.call("GetOptionChain"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var chain []datasource.OptionQuote
+	for _, quote := range m.options[underlying] {
+		if expiry.IsZero() || quote.Contract.Expiry.Format("2006-01-02") == expiry.Format("2006-01-02") {
+			chain = append(chain, quote)
+		}
+	}
+	return chain, nil
+}
+
+// GetFundamentals 返回预设的基本面数据，未设置时返回 NO_DATA 错误
+func (m *MockDataSource) GetFundamentals(ctx context.Context, symbol string) (*datasource.Fundamentals, error) {
+	if err := m.call("GetFundamentals"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fundamentals, ok := m.funds[symbol]
+	if !ok {
+		return nil, &datasource.DataSourceError{
+			Source:  m.name,
+			Code:    "NO_DATA",
+			Message: fmt.Sprintf("no fundamentals for %s", symbol),
+			Time:    time.Now(),
+		}
+	}
+	copied := *fundamentals
+	return &copied, nil
+}
+
+// GetCorporateActions 返回预设的公司行动中除权除息日在[from, to]内的部分，未设置时返回 NO_DATA 错误
+func (m *MockDataSource) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*datasource.CorporateActions, error) {
+	if err := m.call("GetCorporateActions"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	actions, ok := m.actions[symbol]
+	if !ok {
+		return nil, &datasource.DataSourceError{
+			Source:  m.name,
+			Code:    "NO_DATA",
+			Message: fmt.Sprintf("no corporate actions for %s", symbol),
+			Time:    time.Now(),
+		}
+	}
+	return actions.Between(from, to), nil
+}
+
+// SubscribeQuotes 按 datasource.DefaultQuotePollInterval 轮询预设的报价，报价变化时推送
+func (m *MockDataSource) SubscribeQuotes(ctx context.Context, symbols []string) (<-chan datasource.Quote, error) {
+	if err := m.call("SubscribeQuotes"); err != nil {
+		return nil, err
+	}
+	return datasource.PollQuotes(ctx, m.GetRealTimeQuotes, symbols, datasource.DefaultQuotePollInterval)
+}
+
+// GetAllStocks 返回预设的股票列表
+func (m *MockDataSource) GetAllStocks(ctx context.Context) ([]datasource.Stock, error) {
+	if err := m.call("GetAllStocks"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]datasource.Stock(nil), m.stocks...), nil
+}
+
+// Close 模拟数据源没有需要关闭的资源
+func (m *MockDataSource) Close() error {
+	return nil
+}
+
+// GenerateBars 生成n根确定的K线：从start开始每隔interval一根，收盘价从price开始每根变化step，
+// 开盘价为上一根的收盘价，最高价和最低价为开盘价和收盘价上下各0.1%，成交量固定为1000
+func GenerateBars(symbol string, start time.Time, interval time.Duration, n int, price, step float64) []datasource.StockData {
+	bars := make([]datasource.StockData, 0, n)
+	open := price
+	for i := 0; i < n; i++ {
+		closePrice := price + float64(i)*step
+		high, low := open, closePrice
+		if closePrice > high {
+			high, low = closePrice, open
+		}
+		bars = append(bars, datasource.StockData{
+			Symbol:    symbol,
+			Timestamp: start.Add(time.Duration(i) * interval),
+			Open:      open,
+			High:      high * 1.001,
+			Low:       low * 0.999,
+			Close:     closePrice,
+			Volume:    1000,
+		})
+		open = closePrice
+	}
+	return bars
+}
+
+// NewManager 创建包含给定数据源的数据源管理器，第一个数据源为主数据源
+func NewManager(sources ...datasource.DataSource) *datasource.Manager {
+	manager := datasource.NewManager()
+	for _, source := range sources {
+		if err := manager.AddDataSource(source); err != nil {
+			panic(err)
+		}
+	}
+	return manager
+}
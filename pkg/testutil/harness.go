@@ -0,0 +1,290 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// DefaultHarnessLookback 是 Harness 扫描时默认向前获取K线的时间范围
+const DefaultHarnessLookback = 60 * 24 * time.Hour
+
+// Harness 是确定性的端到端测试环境：模拟数据源、模拟券商（trading.SimulatedBroker）、交易引擎、
+// 监控列表和扫描器按系统的方式接在一起，共用一个手动时钟，ID按顺序生成。
+// 场景脚本由 Step 组成，逐步设置行情、推进时钟并运行扫描、监控列表和止损检查，结果只取决于脚本，
+// 用于发现跨模块流程的回归
+type Harness struct {
+	Clock     *clock.Manual
+	Source    *MockDataSource
+	Data      *datasource.Manager
+	Broker    *trading.SimulatedBroker
+	Engine    *trading.BaseTradingEngine
+	Watchlist *trading.Watchlist
+	Scanner   *indicators.Scanner
+	Bus       *events.Bus
+
+	Timeframe string        // 扫描使用的K线周期，默认为 day
+	Lookback  time.Duration // 扫描向前获取K线的时间范围，默认为 DefaultHarnessLookback
+
+	start  time.Time
+	mu     sync.Mutex
+	seq    int
+	errors []error
+}
+
+// NewHarness 创建从start开始的测试环境，引擎按limits检查订单并已启用
+func NewHarness(start time.Time, limits trading.TradingLimits) *Harness {
+	h := &Harness{
+		Clock:     clock.NewManual(start),
+		Source:    NewMockDataSource(""),
+		Bus:       events.NewBus(),
+		Timeframe: "day",
+		Lookback:  DefaultHarnessLookback,
+		start:     start,
+	}
+	ids := trading.IDGeneratorFunc(h.nextID)
+
+	h.Data = NewManager(h.Source)
+	h.Broker = trading.NewSimulatedBroker("", h.Data)
+	h.Broker.SetClock(h.Clock)
+
+	h.Engine = trading.NewBaseTradingEngine(h.Data, trading.BrokerConfig{IsPaperTrading: true}, limits)
+	h.Engine.SetBroker(h.Broker)
+	h.Engine.SetClock(h.Clock)
+	h.Engine.SetIDGenerator(ids)
+	h.Engine.SetEventBus(h.Bus)
+	h.Engine.Enable()
+
+	h.Watchlist = trading.NewWatchlist(h.Engine, h.Data)
+	h.Watchlist.SetClock(h.Clock)
+	h.Watchlist.SetIDGenerator(ids)
+	h.Watchlist.SetEventBus(h.Bus)
+
+	h.Scanner = indicators.NewScanner(indicators.NewIndicatorRegistry(), h.Data)
+	h.Scanner.SetEventBus(h.Bus)
+	return h
+}
+
+// nextID 按顺序生成ID，补零使ID按字典序即为生成顺序（内部方法）
+func (h *Harness) nextID(prefix string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	return fmt.Sprintf("%s-%06d", prefix, h.seq)
+}
+
+// Step 是场景脚本中的一步，返回错误时场景停止
+type Step func(ctx context.Context, h *Harness) error
+
+// Run 依次执行场景脚本的每一步，某一步出错时停止并返回该步的序号和错误
+func (h *Harness) Run(ctx context.Context, steps ...Step) error {
+	for i, step := range steps {
+		if err := step(ctx, h); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Errors 返回场景执行期间下单失败的错误，如监控项下单被拒绝、止损平仓失败
+func (h *Harness) Errors() []error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]error(nil), h.errors...)
+}
+
+// addError 记录一个下单失败的错误（内部方法）
+func (h *Harness) addError(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errors = append(h.errors, err)
+}
+
+// Orders 返回引擎中从开始到当前时间的所有订单，按创建时间和ID排序
+func (h *Harness) Orders() []trading.Order {
+	orders, _ := h.Engine.GetOrderHistory(context.Background(), "", h.start, h.Clock.Now())
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders
+}
+
+// OrderLog 按 Orders 的顺序返回每个订单的摘要，格式为 "<方向> <股票> <数量> <状态> @<成交价> [标签]"，
+// 便于用一个字符串切片断言场景产生的全部订单
+func (h *Harness) OrderLog() []string {
+	orders := h.Orders()
+	log := make([]string, 0, len(orders))
+	for _, order := range orders {
+		line := fmt.Sprintf("%s %s %d %s @%.2f", order.Side, order.Symbol, order.Quantity, order.Status, order.AvgFillPrice)
+		for _, tag := range order.Tags {
+			line += " " + tag
+		}
+		log = append(log, line)
+	}
+	return log
+}
+
+// Advance 将时钟推进d
+func Advance(d time.Duration) Step {
+	return func(ctx context.Context, h *Harness) error {
+		h.Clock.Advance(d)
+		return nil
+	}
+}
+
+// SetPrice 以当前时间设置股票的报价，买卖价等于最新价
+func SetPrice(symbol string, price float64) Step {
+	return func(ctx context.Context, h *Harness) error {
+		h.Source.SetQuote(datasource.Quote{
+			Symbol:    symbol,
+			Timestamp: h.Clock.Now(),
+			LastPrice: price,
+			BidPrice:  price,
+			AskPrice:  price,
+		})
+		return nil
+	}
+}
+
+// GapOpen 以当前时间设置股票开盘跳空的报价：前一交易日收盘于prevClose，开盘价为open
+func GapOpen(symbol string, prevClose, open float64) Step {
+	return func(ctx context.Context, h *Harness) error {
+		h.Source.SetQuote(datasource.Quote{
+			Symbol:       symbol,
+			Timestamp:    h.Clock.Now(),
+			LastPrice:    open,
+			BidPrice:     open,
+			AskPrice:     open,
+			RegularClose: prevClose,
+		})
+		return nil
+	}
+}
+
+// FeedOutage 模拟行情中断，报价和K线请求都返回错误，直到 FeedRestored
+func FeedOutage() Step {
+	return func(ctx context.Context, h *Harness) error {
+		err := &datasource.DataSourceError{Source: h.Source.Name(), Code: "CONNECTION_ERROR", Message: "feed outage", Time: h.Clock.Now()}
+		for _, method := range feedMethods {
+			h.Source.FailWith(method, err)
+		}
+		return nil
+	}
+}
+
+// FeedRestored 恢复 FeedOutage 中断的行情
+func FeedRestored() Step {
+	return func(ctx context.Context, h *Harness) error {
+		for _, method := range feedMethods {
+			h.Source.FailWith(method, nil)
+		}
+		return nil
+	}
+}
+
+// feedMethods 是行情中断时失败的数据源方法（内部变量）
+var feedMethods = []string{"GetRealTimeQuote", "GetStockData", "GetHistoricalQuotes", "HealthCheck"}
+
+// Scan 按策略扫描symbols，每个买入信号以template为模板加入买入表：
+// 模板没有设置目标价时以信号价格为目标价，监控项带有策略名称和扫描结果；已在监控列表中的股票跳过
+func Scan(strategy indicators.Strategy, symbols []string, template trading.WatchlistItem) Step {
+	return func(ctx context.Context, h *Harness) error {
+		strategy.Enabled = true
+		if _, err := h.Scanner.GetStrategy(strategy.Name); err != nil {
+			if err := h.Scanner.AddStrategy(strategy); err != nil {
+				return err
+			}
+		}
+
+		to := h.Clock.Now()
+		results, err := h.Scanner.ScanMultipleSymbols(ctx, symbols, strategy.Name, to.Add(-h.Lookback), to, h.Timeframe)
+		if err != nil {
+			return err
+		}
+		for _, symbol := range symbols {
+			var signals []indicators.ScanResult
+			for _, result := range results[symbol] {
+				if result.IsBuySignal {
+					signals = append(signals, result)
+				}
+			}
+			if len(signals) == 0 {
+				continue
+			}
+			if _, err := h.Watchlist.GetItemBySymbol(symbol); err == nil {
+				continue
+			}
+
+			item := template
+			item.Symbol = symbol
+			item.IsBuyList = true
+			item.Strategy = strategy.Name
+			item.ScanResults = signals
+			if item.TargetPrice <= 0 {
+				item.TargetPrice = signals[0].Value
+			}
+			if err := h.Watchlist.AddItem(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Watch 将监控项加入监控列表
+func Watch(item trading.WatchlistItem) Step {
+	return func(ctx context.Context, h *Harness) error {
+		return h.Watchlist.AddItem(item)
+	}
+}
+
+// ScanWatchlist 扫描监控列表并为触发的监控项下单，与监控列表的定期扫描相同；下单失败记录到 Errors
+func ScanWatchlist() Step {
+	return func(ctx context.Context, h *Harness) error {
+		triggered, err := h.Watchlist.ScanWatchlist(ctx)
+		if err != nil {
+			return err
+		}
+		for _, err := range h.Watchlist.ExecuteWatchlistItems(ctx, triggered) {
+			h.addError(err)
+		}
+		return nil
+	}
+}
+
+// CheckStops 按最新报价检查持仓的止损和止盈，平仓失败记录到 Errors
+func CheckStops() Step {
+	return func(ctx context.Context, h *Harness) error {
+		triggers, err := h.Engine.CheckStops(ctx)
+		if err != nil {
+			return err
+		}
+		for _, trigger := range triggers {
+			if trigger.Error != "" {
+				h.addError(fmt.Errorf("%s %s: %s", trigger.Kind, trigger.Position.Symbol, trigger.Error))
+			}
+		}
+		return nil
+	}
+}
+
+// Order 直接向引擎下单，下单失败记录到 Errors
+func Order(req trading.OrderRequest) Step {
+	return func(ctx context.Context, h *Harness) error {
+		if _, err := h.Engine.PlaceOrder(ctx, req); err != nil {
+			h.addError(err)
+		}
+		return nil
+	}
+}
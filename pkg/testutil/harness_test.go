@@ -0,0 +1,140 @@
+package testutil
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/indicators"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+// harnessStart 是场景开始的时间，美东时间上午开盘
+var harnessStart = time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+
+// expectOrders 断言场景产生的订单摘要
+func expectOrders(t *testing.T, h *Harness, expected ...string) {
+	t.Helper()
+	if log := h.OrderLog(); !reflect.DeepEqual(log, expected) {
+		t.Errorf("订单不正确:\n实际 %q\n期望 %q", log, expected)
+	}
+}
+
+func TestScenarioGapOpen(t *testing.T) {
+	h := NewHarness(harnessStart, trading.TradingLimits{MaxPositions: 10})
+	// 两只股票前41个交易日收盘价从60涨到100，5日ROC为正时产生买入信号
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		h.Source.SetBars(symbol, "day", GenerateBars(symbol, harnessStart.AddDate(0, 0, -41), 24*time.Hour, 41, 60, 1))
+	}
+	momentum := indicators.Strategy{Name: "momentum", Indicators: []indicators.IndicatorConfig{
+		{Type: indicators.IndicatorTypeROC, Parameters: indicators.IndicatorParams{"period": 5},
+			BuyCondition: indicators.ConditionAboveThreshold},
+	}}
+
+	err := h.Run(context.Background(),
+		Scan(momentum, []string{"AAPL", "MSFT"}, trading.WatchlistItem{Quantity: 10, StopLoss: 90}),
+		// 开盘时AAPL向下跳空到目标价以下，MSFT向上跳空
+		GapOpen("AAPL", 100, 95),
+		GapOpen("MSFT", 100, 105),
+		ScanWatchlist(),
+		Advance(time.Minute),
+		SetPrice("MSFT", 104),
+		ScanWatchlist(),
+	)
+	if err != nil {
+		t.Fatalf("场景执行失败: %v", err)
+	}
+
+	// 跳空低开按开盘价成交，而不是目标价；向上跳空不追高
+	expectOrders(t, h, "buy AAPL 10 filled @95.00")
+	items := map[string]trading.WatchlistItem{}
+	for _, item := range h.Watchlist.GetAllItems() {
+		items[item.Symbol] = item
+	}
+	if item := items["AAPL"]; item.Status != trading.WatchStatusTriggered || item.TargetPrice != 100 || item.OrderID == "" || len(item.ScanResults) == 0 {
+		t.Errorf("AAPL的监控项不正确: %+v", item)
+	}
+	if item := items["MSFT"]; item.Status != trading.WatchStatusActive {
+		t.Errorf("MSFT的监控项应保持活跃: %s", item.Status)
+	}
+	position, err := h.Engine.GetPosition(context.Background(), "AAPL")
+	if err != nil || position.StopLoss != 90 || !position.OpenedAt.Equal(harnessStart) {
+		t.Errorf("持仓应带有监控项的止损: %+v %v", position, err)
+	}
+	if errs := h.Errors(); len(errs) != 0 {
+		t.Errorf("不应有下单错误: %v", errs)
+	}
+}
+
+func TestScenarioStopRun(t *testing.T) {
+	h := NewHarness(harnessStart, trading.TradingLimits{MaxPositions: 10})
+
+	err := h.Run(context.Background(),
+		Watch(trading.WatchlistItem{Symbol: "AAPL", Quantity: 10, IsBuyList: true, TargetPrice: 100, StopLoss: 95}),
+		SetPrice("AAPL", 100),
+		ScanWatchlist(),
+		// 价格下探但未触及止损
+		Advance(time.Minute),
+		SetPrice("AAPL", 96),
+		CheckStops(),
+		// 快速击穿止损后反弹，止损只触发一次
+		Advance(time.Minute),
+		SetPrice("AAPL", 94),
+		CheckStops(),
+		Advance(time.Minute),
+		SetPrice("AAPL", 99),
+		CheckStops(),
+		ScanWatchlist(),
+	)
+	if err != nil {
+		t.Fatalf("场景执行失败: %v", err)
+	}
+
+	expectOrders(t, h, "buy AAPL 10 filled @100.00", "sell AAPL 10 filled @94.00 stop_loss")
+	orders := h.Orders()
+	if !orders[1].CreatedAt.Equal(harnessStart.Add(2 * time.Minute)) {
+		t.Errorf("止损订单的时间不正确: %v", orders[1].CreatedAt)
+	}
+	if position, err := h.Engine.GetPosition(context.Background(), "AAPL"); err == nil && position.Quantity != 0 {
+		t.Errorf("止损后应已平仓: %+v", position)
+	}
+}
+
+func TestScenarioFeedOutage(t *testing.T) {
+	h := NewHarness(harnessStart, trading.TradingLimits{MaxPositions: 10})
+
+	err := h.Run(context.Background(),
+		SetPrice("AAPL", 100),
+		Order(trading.OrderRequest{Symbol: "AAPL", Quantity: 10, Side: trading.OrderSideBuy, Type: trading.OrderTypeMarket, StopLoss: 95}),
+		Watch(trading.WatchlistItem{Symbol: "MSFT", Quantity: 5, IsBuyList: true, TargetPrice: 50}),
+		// 行情中断期间价格越过止损和目标价，没有报价时不平仓也不买入
+		FeedOutage(),
+		Advance(time.Minute),
+		SetPrice("AAPL", 90),
+		SetPrice("MSFT", 45),
+		CheckStops(),
+		ScanWatchlist(),
+		// 行情恢复后按最新报价处理
+		Advance(time.Minute),
+		FeedRestored(),
+		CheckStops(),
+		ScanWatchlist(),
+	)
+	if err != nil {
+		t.Fatalf("场景执行失败: %v", err)
+	}
+
+	expectOrders(t, h,
+		"buy AAPL 10 filled @100.00",
+		"sell AAPL 10 filled @90.00 stop_loss",
+		"buy MSFT 5 filled @45.00",
+	)
+	orders := h.Orders()
+	if !orders[1].CreatedAt.Equal(harnessStart.Add(2*time.Minute)) || !orders[2].CreatedAt.Equal(orders[1].CreatedAt) {
+		t.Errorf("行情恢复前不应下单: %v %v", orders[1].CreatedAt, orders[2].CreatedAt)
+	}
+	if errs := h.Errors(); len(errs) != 0 {
+		t.Errorf("不应有下单错误: %v", errs)
+	}
+}
@@ -0,0 +1,80 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/trading"
+)
+
+func TestMockDataSource(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)
+	source := NewMockDataSource("")
+	source.SetBars("AAPL", "minute", GenerateBars("AAPL", start, time.Minute, 10, 100, 0.5))
+
+	bars, err := source.GetStockData(ctx, "AAPL", "minute", start.Add(2*time.Minute), start.Add(4*time.Minute))
+	if err != nil || len(bars) != 3 || bars[0].Close != 101 || bars[0].Open != 100.5 {
+		t.Fatalf("应返回范围内的预设K线: %+v %v", bars, err)
+	}
+
+	if _, err := source.GetRealTimeQuote(ctx, "AAPL"); err == nil {
+		t.Error("没有预设报价时应返回错误")
+	}
+	source.SetQuote(datasource.Quote{Symbol: "AAPL", LastPrice: 104.5})
+	source.FailWith("GetRealTimeQuote", errors.New("timeout"))
+	if _, err := source.GetRealTimeQuote(ctx, "AAPL"); err == nil || err.Error() != "timeout" {
+		t.Errorf("应返回预设的错误: %v", err)
+	}
+	source.FailWith("GetRealTimeQuote", nil)
+	if quote, err := source.GetRealTimeQuote(ctx, "AAPL"); err != nil || quote.LastPrice != 104.5 {
+		t.Errorf("应返回预设的报价: %+v %v", quote, err)
+	}
+	if source.Calls("GetRealTimeQuote") != 3 {
+		t.Errorf("调用次数 = %d, 期望 3", source.Calls("GetRealTimeQuote"))
+	}
+}
+
+func TestMockBrokerWithEngine(t *testing.T) {
+	ctx := context.Background()
+	source := NewMockDataSource("")
+	source.SetQuote(datasource.Quote{Symbol: "AAPL", LastPrice: 100})
+	broker := NewMockBroker("")
+	broker.SetPrice("AAPL", 100)
+	broker.Script(Fill{}, Reject("insufficient buying power"), Accept())
+
+	engine := trading.NewBaseTradingEngine(NewManager(source), trading.BrokerConfig{}, trading.TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	if order, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, trading.OrderTypeMarket, trading.OrderSideBuy); err != nil || order.Status != trading.OrderStatusFilled {
+		t.Fatalf("第一个订单应全部成交: %+v %v", order, err)
+	}
+	if order, _ := engine.SubmitOrder(ctx, "AAPL", 10, 0, trading.OrderTypeMarket, trading.OrderSideBuy); order != nil && order.Status != trading.OrderStatusRejected {
+		t.Errorf("第二个订单应被拒绝: %+v", order)
+	}
+	order, err := engine.SubmitOrder(ctx, "AAPL", 5, 99, trading.OrderTypeLimit, trading.OrderSideBuy)
+	if err != nil || order.Status != trading.OrderStatusAccepted {
+		t.Fatalf("第三个订单应只被接受: %+v %v", order, err)
+	}
+
+	// 推送异步成交回报
+	filled := *order
+	filled.Status = trading.OrderStatusFilled
+	filled.FilledQty = 5
+	filled.AvgFillPrice = 99
+	broker.Push(trading.OrderUpdate{Order: filled})
+	broker.Close()
+	engine.RunExecutionStream(ctx)
+
+	pos, err := engine.GetPosition(ctx, "AAPL")
+	if err != nil || pos.Quantity != 15 {
+		t.Errorf("持仓应包含同步和异步的成交: %+v %v", pos, err)
+	}
+	if len(broker.Orders()) != 3 {
+		t.Errorf("券商收到的订单数量 = %d, 期望 3", len(broker.Orders()))
+	}
+}
@@ -0,0 +1,194 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// EventAccountAlert 是账户快照出现异常时在 risk 主题发布的事件类型
+const EventAccountAlert = "account_alert"
+
+// 账户告警类型
+const (
+	AccountAlertEquityJump            = "equity_jump"              // 权益较上次快照上涨超过阈值
+	AccountAlertEquityDrop            = "equity_drop"              // 权益较上次快照下跌超过阈值
+	AccountAlertNegativeBuyingPower   = "negative_buying_power"    // 购买力变为负数
+	AccountAlertMarginCall            = "margin_call"              // 出现追加保证金通知
+	AccountAlertMarginCallCleared     = "margin_call_cleared"      // 追加保证金通知解除
+	AccountAlertDayTradingCall        = "day_trading_call"         // 出现日内交易追缴通知
+	AccountAlertDayTradingCallCleared = "day_trading_call_cleared" // 日内交易追缴通知解除
+	AccountAlertLocked                = "account_locked"           // 账户被锁定
+	AccountAlertUnlocked              = "account_unlocked"         // 账户解除锁定
+)
+
+// AccountWatchConfig 表示账户快照监控的配置
+type AccountWatchConfig struct {
+	Enabled             bool    `json:"enabled" yaml:"enabled"`
+	IntervalSeconds     int     `json:"interval_seconds" yaml:"interval_seconds"`           // 快照间隔，默认60秒
+	EquityChangePercent float64 `json:"equity_change_percent" yaml:"equity_change_percent"` // 两次快照之间权益变动超过该百分比时告警，为0时不检查
+}
+
+// Validate 检查配置是否有效
+func (c AccountWatchConfig) Validate() error {
+	if c.IntervalSeconds < 0 || c.EquityChangePercent < 0 {
+		return fmt.Errorf("values must not be negative")
+	}
+	return nil
+}
+
+// Interval 返回快照间隔
+func (c AccountWatchConfig) Interval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// AccountChange 表示两次账户快照之间变化的一个字段
+type AccountChange struct {
+	Field    string      `json:"field"` // 字段的JSON名称
+	Previous interface{} `json:"previous"`
+	Current  interface{} `json:"current"`
+}
+
+// AccountAlert 表示一次账户异常，作为 account_alert 事件的内容
+type AccountAlert struct {
+	Type          string          `json:"type"`
+	Message       string          `json:"message"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Previous      *Account        `json:"previous,omitempty"` // 上次快照，第一次快照时为空
+	Current       Account         `json:"current"`
+	ChangePercent float64         `json:"change_percent,omitempty"` // 权益告警的变动百分比
+	Changes       []AccountChange `json:"changes,omitempty"`        // 与上次快照相比变化的字段
+}
+
+// DiffAccounts 比较两次账户快照，按固定顺序返回变化的余额、保证金和状态字段
+func DiffAccounts(prev, curr Account) []AccountChange {
+	fields := []AccountChange{
+		{"cash", prev.Cash, curr.Cash},
+		{"buying_power", prev.BuyingPower, curr.BuyingPower},
+		{"equity", prev.Equity, curr.Equity},
+		{"margin_used", prev.MarginUsed, curr.MarginUsed},
+		{"initial_margin", prev.InitialMargin, curr.InitialMargin},
+		{"maintenance_margin", prev.MaintenanceMargin, curr.MaintenanceMargin},
+		{"day_trade_count", prev.DayTradeCount, curr.DayTradeCount},
+		{"is_locked", prev.IsLocked, curr.IsLocked},
+		{"is_pattern_day_trader", prev.IsPatternDayTrader, curr.IsPatternDayTrader},
+		{"is_day_trading_calls", prev.IsDayTradingCalls, curr.IsDayTradingCalls},
+		{"is_margin_calls", prev.IsMarginCalls, curr.IsMarginCalls},
+	}
+
+	var changes []AccountChange
+	for _, field := range fields {
+		if field.Previous != field.Current {
+			changes = append(changes, field)
+		}
+	}
+	return changes
+}
+
+// SetAccountWatch 设置账户快照监控
+func (e *BaseTradingEngine) SetAccountWatch(config AccountWatchConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.accountWatch = config
+}
+
+// CheckAccount 记录账户快照并与上次快照比较，权益变动超过阈值、购买力变为负数或追缴和锁定状态变化时
+// 在 risk 主题发布 account_alert 事件，返回本次的告警。第一次快照没有比较对象，只检查购买力和状态标志
+func (e *BaseTradingEngine) CheckAccount(ctx context.Context) ([]AccountAlert, error) {
+	account, err := e.GetAccount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %v", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	curr := *account
+	prev := e.lastAccount
+	e.lastAccount = &curr
+
+	var base Account
+	var changes []AccountChange
+	if prev != nil {
+		base = *prev
+		changes = DiffAccounts(base, curr)
+	}
+
+	now := e.now()
+	var alerts []AccountAlert
+	alert := func(alertType, message string) *AccountAlert {
+		alerts = append(alerts, AccountAlert{
+			Type:      alertType,
+			Message:   message,
+			Timestamp: now,
+			Previous:  prev,
+			Current:   curr,
+			Changes:   changes,
+		})
+		return &alerts[len(alerts)-1]
+	}
+
+	if threshold := e.accountWatch.EquityChangePercent; prev != nil && threshold > 0 && base.Equity > 0 {
+		change := (curr.Equity - base.Equity) / base.Equity * 100
+		if math.Abs(change) >= threshold {
+			alertType := AccountAlertEquityJump
+			if change < 0 {
+				alertType = AccountAlertEquityDrop
+			}
+			alert(alertType, fmt.Sprintf("equity changed %.2f%% from %.2f to %.2f", change, base.Equity, curr.Equity)).ChangePercent = change
+		}
+	}
+	if curr.BuyingPower < 0 && (prev == nil || base.BuyingPower >= 0) {
+		alert(AccountAlertNegativeBuyingPower, fmt.Sprintf("buying power is negative: %.2f", curr.BuyingPower))
+	}
+	flags := []struct {
+		prev, curr   bool
+		set, cleared string
+		name         string
+	}{
+		{base.IsMarginCalls, curr.IsMarginCalls, AccountAlertMarginCall, AccountAlertMarginCallCleared, "margin call"},
+		{base.IsDayTradingCalls, curr.IsDayTradingCalls, AccountAlertDayTradingCall, AccountAlertDayTradingCallCleared, "day trading call"},
+		{base.IsLocked, curr.IsLocked, AccountAlertLocked, AccountAlertUnlocked, "account lock"},
+	}
+	for _, flag := range flags {
+		switch {
+		case flag.curr && !flag.prev:
+			alert(flag.set, flag.name+" flag set")
+		case !flag.curr && flag.prev:
+			alert(flag.cleared, flag.name+" flag cleared")
+		}
+	}
+
+	for _, a := range alerts {
+		e.publish(events.TopicRisk, EventAccountAlert, a)
+	}
+	return alerts, nil
+}
+
+// RunAccountWatch 按配置的间隔记录账户快照并检查异常，直到上下文取消
+func (e *BaseTradingEngine) RunAccountWatch(ctx context.Context) error {
+	e.mu.RLock()
+	interval := e.accountWatch.Interval()
+	e.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.CheckAccount(ctx); err != nil {
+			e.log().Error("检查账户失败: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
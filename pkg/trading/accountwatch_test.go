@@ -0,0 +1,100 @@
+package trading
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+func TestDiffAccounts(t *testing.T) {
+	prev := Account{Cash: 1000, Equity: 1500, IsMarginCalls: false, UnrealizedPnL: 5}
+	curr := Account{Cash: 1000, Equity: 1400, IsMarginCalls: true, UnrealizedPnL: -5}
+
+	changes := DiffAccounts(prev, curr)
+	if len(changes) != 2 || changes[0].Field != "equity" || changes[1].Field != "is_margin_calls" {
+		t.Fatalf("应只列出变化的余额和状态字段: %+v", changes)
+	}
+	if changes[0].Previous != 1500.0 || changes[0].Current != 1400.0 {
+		t.Errorf("变化前后的数值不正确: %+v", changes[0])
+	}
+	if changes := DiffAccounts(prev, prev); len(changes) != 0 {
+		t.Errorf("相同的快照不应有变化: %+v", changes)
+	}
+}
+
+func TestCheckAccount(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicRisk)
+	defer sub.Close()
+
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetEventBus(bus)
+	engine.SetAccountWatch(AccountWatchConfig{Enabled: true, EquityChangePercent: 5})
+	engine.Enable()
+
+	if alerts, err := engine.CheckAccount(ctx); err != nil || len(alerts) != 0 {
+		t.Fatalf("正常账户的第一次快照不应告警: %+v %v", alerts, err)
+	}
+
+	// 按100买入500股后跌到80，权益下跌10%，同时券商发出追加保证金通知
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 500, Type: OrderTypeMarket, Side: OrderSideBuy}); err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	engine.ApplyMarks(map[string]float64{"AAPL": 80})
+	engine.mu.Lock()
+	engine.account.IsMarginCalls = true
+	engine.mu.Unlock()
+	alerts, err := engine.CheckAccount(ctx)
+	if err != nil || len(alerts) != 2 {
+		t.Fatalf("应发现权益下跌和追加保证金通知: %+v %v", alerts, err)
+	}
+	if alerts[0].Type != AccountAlertEquityDrop || alerts[0].ChangePercent != -10 || alerts[0].Previous == nil || alerts[0].Previous.Equity != 100000 {
+		t.Errorf("权益下跌告警不正确: %+v", alerts[0])
+	}
+	if alerts[1].Type != AccountAlertMarginCall || len(alerts[1].Changes) != 3 {
+		t.Errorf("追加保证金告警不正确: %+v", alerts[1])
+	}
+	for range alerts {
+		select {
+		case evt := <-sub.C:
+			if evt.Type != EventAccountAlert {
+				t.Errorf("应发布账户告警事件: %+v", evt)
+			}
+		default:
+			t.Error("没有发布账户告警事件")
+		}
+	}
+
+	// 变动低于阈值不告警，购买力变为负数只在第一次告警
+	engine.ApplyMarks(map[string]float64{"AAPL": 82})
+	engine.mu.Lock()
+	engine.account.BuyingPower = -500
+	engine.mu.Unlock()
+	if alerts, _ := engine.CheckAccount(ctx); len(alerts) != 1 || alerts[0].Type != AccountAlertNegativeBuyingPower {
+		t.Fatalf("应只发现购买力为负: %+v", alerts)
+	}
+	if alerts, _ := engine.CheckAccount(ctx); len(alerts) != 0 {
+		t.Errorf("购买力持续为负不应重复告警: %+v", alerts)
+	}
+
+	// 从91000回升到100000，上涨约9.9%
+	engine.ApplyMarks(map[string]float64{"AAPL": 100})
+	engine.mu.Lock()
+	engine.account.IsMarginCalls = false
+	engine.mu.Unlock()
+	alerts, _ = engine.CheckAccount(ctx)
+	if len(alerts) != 2 || alerts[0].Type != AccountAlertEquityJump || alerts[1].Type != AccountAlertMarginCallCleared {
+		t.Errorf("应发现权益上涨和追加保证金通知解除: %+v", alerts)
+	}
+
+	// 第一次快照时已经存在的异常状态也需要告警
+	restarted := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	restarted.ensureAccount()
+	restarted.account.IsLocked = true
+	if alerts, _ := restarted.CheckAccount(ctx); len(alerts) != 1 || alerts[0].Type != AccountAlertLocked || alerts[0].Previous != nil {
+		t.Errorf("第一次快照应报告账户已锁定: %+v", alerts)
+	}
+}
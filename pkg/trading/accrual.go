@@ -0,0 +1,298 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// DividendLookbackDays 是检测除息日时向前查找的天数，错过的运行（如周末和休市）在此范围内补上
+const DividendLookbackDays = 7
+
+// AccrualConfig 表示分红和利息计提的配置
+type AccrualConfig struct {
+	Enabled           bool    `json:"enabled" yaml:"enabled"`
+	Schedule          string  `json:"schedule" yaml:"schedule"`                       // 计提的日历表达式，应在开盘前运行，如 "at 09:00"
+	MarginRatePercent float64 `json:"margin_rate_percent" yaml:"margin_rate_percent"` // 现金为负（融资）时的年化利率
+	CashRatePercent   float64 `json:"cash_rate_percent" yaml:"cash_rate_percent"`     // 现金余额的年化利率，为0时不计息
+	DayCount          int     `json:"day_count" yaml:"day_count"`                     // 年化利率的计息天数，默认360
+}
+
+// Validate 检查配置是否有效
+func (c AccrualConfig) Validate() error {
+	if c.MarginRatePercent < 0 || c.CashRatePercent < 0 || c.DayCount < 0 {
+		return fmt.Errorf("values must not be negative")
+	}
+	return nil
+}
+
+// dayCount 返回计息天数（内部方法）
+func (c AccrualConfig) dayCount() int {
+	if c.DayCount <= 0 {
+		return 360
+	}
+	return c.DayCount
+}
+
+// Dividend 表示一次现金分红
+type Dividend struct {
+	Symbol     string    `json:"symbol"`
+	ExDate     time.Time `json:"ex_date"`  // 除息日零点，在此之前开仓并持有到除息日的持仓获得分红
+	PayDate    time.Time `json:"pay_date"` // 派息日，为零值时在除息日派发
+	CashAmount float64   `json:"cash_amount"` // 每股分红
+}
+
+// DividendSource 提供股票的分红日程，通常来自数据源的公司行动
+type DividendSource interface {
+	// Dividends 返回股票在[from, to]内除息的分红
+	Dividends(ctx context.Context, symbols []string, from, to time.Time) ([]Dividend, error)
+}
+
+// corporateActionDividends 从数据源的公司行动中读取现金分红（内部类型）
+type corporateActionDividends struct {
+	provider datasource.CorporateActionProvider
+}
+
+// NewCorporateActionDividends 返回从公司行动中读取现金分红的分红来源，provider 通常为数据源管理器
+func NewCorporateActionDividends(provider datasource.CorporateActionProvider) DividendSource {
+	return &corporateActionDividends{provider: provider}
+}
+
+// Dividends 逐只股票获取除息日在[from, to]内的现金分红
+func (c *corporateActionDividends) Dividends(ctx context.Context, symbols []string, from, to time.Time) ([]Dividend, error) {
+	var dividends []Dividend
+	for _, symbol := range symbols {
+		actions, err := c.provider.GetCorporateActions(ctx, symbol, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get corporate actions of %s: %v", symbol, err)
+		}
+		for _, dividend := range actions.Dividends {
+			dividends = append(dividends, Dividend{
+				Symbol:     symbol,
+				ExDate:     dividend.ExDate,
+				PayDate:    dividend.PayDate,
+				CashAmount: dividend.CashAmount,
+			})
+		}
+	}
+	return dividends, nil
+}
+
+// DividendEntitlement 表示持仓在除息日获得的分红，派息日记入现金账簿
+type DividendEntitlement struct {
+	Dividend
+	Position string     `json:"position"` // 持仓键，对冲模式下包含策略
+	Strategy string     `json:"strategy,omitempty"`
+	Quantity int64      `json:"quantity"` // 除息日的持仓数量，空头为负数
+	Amount   float64    `json:"amount"`   // 应收的分红，空头需要支付时为负数
+	PaidAt   *time.Time `json:"paid_at,omitempty"`
+}
+
+// key 返回应收分红的唯一键（内部方法）
+func (d DividendEntitlement) key() string {
+	return d.Position + "|" + d.ExDate.Format("2006-01-02")
+}
+
+// SetDividendSource 设置分红日程的来源，为空时不处理分红
+func (e *BaseTradingEngine) SetDividendSource(source DividendSource) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dividendSource = source
+}
+
+// SetAccrual 设置利息计提
+func (e *BaseTradingEngine) SetAccrual(config AccrualConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.accrual = config
+}
+
+// GetDividendEntitlements 返回所有应收分红，按除息日和持仓排序
+func (e *BaseTradingEngine) GetDividendEntitlements() []DividendEntitlement {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	entitlements := make([]DividendEntitlement, 0, len(e.entitlements))
+	for _, entitlement := range e.entitlements {
+		entitlements = append(entitlements, entitlement)
+	}
+	sort.Slice(entitlements, func(i, j int) bool {
+		if !entitlements[i].ExDate.Equal(entitlements[j].ExDate) {
+			return entitlements[i].ExDate.Before(entitlements[j].ExDate)
+		}
+		return entitlements[i].Position < entitlements[j].Position
+	})
+	return entitlements
+}
+
+// ProcessDividends 检测持仓股票最近的除息日并记录应收分红，然后把到达派息日的分红记入现金账簿，
+// 返回本次新记录或派发的应收分红。只有在除息日之前开仓的持仓获得分红，数量取检测时的持仓，
+// 因此应在除息日开盘前运行。应收分红和派息写入预写日志，重启后不会重复处理
+func (e *BaseTradingEngine) ProcessDividends(ctx context.Context) ([]DividendEntitlement, error) {
+	e.mu.RLock()
+	source := e.dividendSource
+	now := e.now()
+	symbols := make(map[string]bool)
+	for _, pos := range e.positions {
+		symbols[pos.Symbol] = true
+	}
+	e.mu.RUnlock()
+
+	var dividends []Dividend
+	if source != nil && len(symbols) > 0 {
+		list := make([]string, 0, len(symbols))
+		for symbol := range symbols {
+			list = append(list, symbol)
+		}
+		sort.Strings(list)
+		var err error
+		dividends, err = source.Dividends(ctx, list, now.AddDate(0, 0, -DividendLookbackDays), now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dividends: %v", err)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var changed []DividendEntitlement
+	for _, dividend := range dividends {
+		if dividend.CashAmount <= 0 || dividend.ExDate.After(now) {
+			continue
+		}
+		for key, pos := range e.positions {
+			if pos.Symbol != dividend.Symbol || pos.Quantity == 0 || !pos.OpenedAt.Before(dividend.ExDate) {
+				continue
+			}
+			entitlement := DividendEntitlement{
+				Dividend: dividend,
+				Position: key,
+				Strategy: pos.Strategy,
+				Quantity: pos.Quantity,
+				Amount:   math.Round(float64(pos.Quantity)*dividend.CashAmount*100) / 100,
+			}
+			if _, exists := e.entitlements[entitlement.key()]; exists {
+				continue
+			}
+			if e.wal != nil && !e.replaying {
+				if err := e.wal.Append(WALRecord{Type: WALDividendEntitled, Timestamp: now, Dividend: &entitlement}); err != nil {
+					return changed, err
+				}
+			}
+			e.applyEntitlement(entitlement)
+			changed = append(changed, entitlement)
+		}
+	}
+
+	// 派发到达派息日的分红
+	keys := make([]string, 0, len(e.entitlements))
+	for key, entitlement := range e.entitlements {
+		if entitlement.PaidAt == nil && !entitlement.payDate().After(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entitlement := e.entitlements[key]
+		paidAt := now
+		entitlement.PaidAt = &paidAt
+		entry, _ := CashMovement{Kind: LedgerKindDividend, Amount: entitlement.Amount, Symbol: entitlement.Symbol}.entry()
+		entry.Timestamp = now
+		entry.Description = fmt.Sprintf("%d shares x %.4f ex %s", entitlement.Quantity, entitlement.CashAmount, entitlement.ExDate.Format("2006-01-02"))
+		if entitlement.Amount == 0 {
+			e.applyEntitlement(entitlement)
+			continue
+		}
+		if err := e.recordCash(&entry, &entitlement); err != nil {
+			return changed, err
+		}
+		changed = append(changed, entitlement)
+	}
+	return changed, nil
+}
+
+// AccrueInterest 按上次计提以来的天数和当前现金余额计提利息并记入现金账簿：现金为负时按融资利率支付，
+// 为正时按现金利率收取。第一次调用只记录计提起点，重启后从账簿中最后一条利息分录的日期继续。
+// 没有需要计提的天数或利息为0时返回nil
+func (e *BaseTradingEngine) AccrueInterest(ctx context.Context) (*LedgerEntry, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	today := e.accrualDay(now)
+	if e.interestDate.IsZero() {
+		for i := len(e.ledger) - 1; i >= 0; i-- {
+			if e.ledger[i].Kind == LedgerKindInterest {
+				e.interestDate = e.accrualDay(e.ledger[i].Timestamp)
+				break
+			}
+		}
+	}
+	if e.interestDate.IsZero() {
+		e.interestDate = today
+		return nil, nil
+	}
+	days := int(math.Round(today.Sub(e.interestDate).Hours() / 24))
+	if days <= 0 {
+		return nil, nil
+	}
+	e.interestDate = today
+
+	e.ensureAccount()
+	cash := e.account.Cash
+	rate := e.accrual.CashRatePercent
+	if cash < 0 {
+		rate = e.accrual.MarginRatePercent
+	}
+	amount := math.Round(cash*rate/100*float64(days)/float64(e.accrual.dayCount())*100) / 100
+	if amount == 0 {
+		return nil, nil
+	}
+
+	entry, _ := CashMovement{Kind: LedgerKindInterest, Amount: amount}.entry()
+	entry.Timestamp = now
+	entry.Description = fmt.Sprintf("%d days on %.2f at %.2f%%", days, cash, rate)
+	if err := e.recordCash(&entry, nil); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// RunAccruals 处理分红并计提利息，用于定时任务
+func (e *BaseTradingEngine) RunAccruals(ctx context.Context) error {
+	if _, err := e.ProcessDividends(ctx); err != nil {
+		return err
+	}
+	_, err := e.AccrueInterest(ctx)
+	return err
+}
+
+// payDate 返回派息日，未提供时为除息日（内部方法）
+func (d DividendEntitlement) payDate() time.Time {
+	if d.PayDate.IsZero() {
+		return d.ExDate
+	}
+	return d.PayDate
+}
+
+// applyEntitlement 记录或更新应收分红（内部方法，调用方持锁）
+func (e *BaseTradingEngine) applyEntitlement(entitlement DividendEntitlement) {
+	if e.entitlements == nil {
+		e.entitlements = make(map[string]DividendEntitlement)
+	}
+	e.entitlements[entitlement.key()] = entitlement
+}
+
+// accrualDay 返回计提使用的日期，有交易日历时按交易所时区划分（内部方法，调用方持锁）
+func (e *BaseTradingEngine) accrualDay(t time.Time) time.Time {
+	loc := time.UTC
+	if e.calendar != nil {
+		loc = e.calendar.Location()
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,183 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// staticDividendSource 是返回固定分红日程的测试数据源
+type staticDividendSource struct {
+	dividends []Dividend
+}
+
+func (s *staticDividendSource) Dividends(ctx context.Context, symbols []string, from, to time.Time) ([]Dividend, error) {
+	held := make(map[string]bool)
+	for _, symbol := range symbols {
+		held[symbol] = true
+	}
+	var result []Dividend
+	for _, dividend := range s.dividends {
+		if held[dividend.Symbol] && !dividend.ExDate.Before(from) && !dividend.ExDate.After(to) {
+			result = append(result, dividend)
+		}
+	}
+	return result, nil
+}
+
+// actionProvider 是返回固定公司行动的测试数据
+type actionProvider map[string]datasource.CorporateActions
+
+func (p actionProvider) GetCorporateActions(ctx context.Context, symbol string, from, to time.Time) (*datasource.CorporateActions, error) {
+	actions := p[symbol]
+	return actions.Between(from, to), nil
+}
+
+func TestCorporateActionDividends(t *testing.T) {
+	day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	source := NewCorporateActionDividends(actionProvider{
+		"AAPL": {Symbol: "AAPL", Dividends: []datasource.CashDividend{
+			{Symbol: "AAPL", ExDate: day, PayDate: day.AddDate(0, 0, 6), CashAmount: 0.25},
+			{Symbol: "AAPL", ExDate: day.AddDate(0, -3, 0), CashAmount: 0.24},
+		}},
+	})
+
+	dividends, err := source.Dividends(context.Background(), []string{"AAPL", "TSLA"}, day.AddDate(0, 0, -7), day)
+	if err != nil || len(dividends) != 1 {
+		t.Fatalf("应只返回范围内的分红: %+v %v", dividends, err)
+	}
+	if d := dividends[0]; d.Symbol != "AAPL" || d.CashAmount != 0.25 || !d.PayDate.Equal(day.AddDate(0, 0, 6)) {
+		t.Errorf("分红不正确: %+v", d)
+	}
+}
+
+func TestProcessDividends(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	wal, err := OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	// 测试券商按系统时间成交，引擎时钟设在开仓后的第10天
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 10)
+	sim := clock.NewManual(day.Add(9 * time.Hour))
+	source := &staticDividendSource{dividends: []Dividend{
+		{Symbol: "AAPL", ExDate: day.AddDate(0, 0, -2), PayDate: day.AddDate(0, 0, 3), CashAmount: 0.24},
+		{Symbol: "MSFT", ExDate: day.AddDate(0, 0, -1), CashAmount: 0.75},
+		{Symbol: "NVDA", ExDate: day.AddDate(0, 0, -1), CashAmount: 0.04}, // 未持仓
+		{Symbol: "AAPL", ExDate: day.AddDate(0, 0, 5), CashAmount: 0.24},  // 尚未除息
+	}}
+	// 对冲模式下卖出建立空头
+	engine := NewBaseTradingEngine(nil, BrokerConfig{PositionMode: PositionModeHedging}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetWAL(wal)
+	engine.Enable()
+	engine.SubmitOrder(ctx, "AAPL", 100, 0, OrderTypeMarket, OrderSideBuy)
+	engine.SubmitOrder(ctx, "MSFT", 20, 0, OrderTypeMarket, OrderSideSell)
+	engine.SetClock(sim)
+	engine.SetDividendSource(source)
+
+	changed, err := engine.ProcessDividends(ctx)
+	if err != nil {
+		t.Fatalf("处理分红失败: %v", err)
+	}
+	// 两条应收分红，MSFT 在除息日派息，空头支付15美元
+	if len(changed) != 3 {
+		t.Fatalf("应记录两条应收分红并派发一条: %+v", changed)
+	}
+	entitlements := engine.GetDividendEntitlements()
+	if len(entitlements) != 2 || entitlements[0].Symbol != "AAPL" || entitlements[0].Amount != 24 || entitlements[0].PaidAt != nil {
+		t.Fatalf("AAPL 的应收分红不正确: %+v", entitlements)
+	}
+	if entitlements[1].Amount != -15 || entitlements[1].Quantity != -20 || entitlements[1].PaidAt == nil {
+		t.Fatalf("MSFT 空头应在派息日支付分红: %+v", entitlements[1])
+	}
+	if changed, _ := engine.ProcessDividends(ctx); len(changed) != 0 {
+		t.Errorf("重复处理不应再次记录分红: %+v", changed)
+	}
+
+	sim.Advance(4 * 24 * time.Hour)
+	if changed, _ := engine.ProcessDividends(ctx); len(changed) != 1 || changed[0].Symbol != "AAPL" || changed[0].PaidAt == nil {
+		t.Fatalf("到达派息日应派发 AAPL 的分红: %+v", changed)
+	}
+	ledger, _ := engine.GetLedger(ctx, day, time.Time{})
+	if len(ledger) != 2 || ledger[0].Debit != LedgerAccountDividends || ledger[1].Credit != LedgerAccountDividends || ledger[1].Amount != 24 {
+		t.Fatalf("分红应记入现金账簿: %+v", ledger)
+	}
+	want := 100000 - 10000 + 2000 - 15 + 24.0
+	if account, _ := engine.GetAccount(ctx); math.Abs(account.Cash-want) > 1e-9 {
+		t.Errorf("分红应调整账户现金: %v", account.Cash)
+	}
+	wal.Close()
+
+	// 恢复后应收分红和派息状态与恢复前一致，不会重复派发
+	wal, err = OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{PositionMode: PositionModeHedging}, TradingLimits{MaxPositions: 10})
+	recovered.SetWAL(wal)
+	if _, err := recovered.Recover(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	recovered.SetClock(sim)
+	recovered.SetDividendSource(source)
+	if changed, _ := recovered.ProcessDividends(ctx); len(changed) != 0 {
+		t.Errorf("恢复后不应重复处理分红: %+v", changed)
+	}
+	restored := recovered.GetDividendEntitlements()
+	if len(restored) != 2 || restored[0].PaidAt == nil || restored[1].PaidAt == nil {
+		t.Errorf("恢复的应收分红不正确: %+v", restored)
+	}
+	if account, _ := recovered.GetAccount(ctx); math.Abs(account.Cash-want) > 1e-9 {
+		t.Errorf("恢复的账户现金不正确: %v", account.Cash)
+	}
+}
+
+func TestAccrueInterest(t *testing.T) {
+	ctx := context.Background()
+	sim := clock.NewManual(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetClock(sim)
+	engine.SetAccrual(AccrualConfig{Enabled: true, CashRatePercent: 3.6, MarginRatePercent: 7.2})
+
+	if entry, err := engine.AccrueInterest(ctx); entry != nil || err != nil {
+		t.Fatalf("第一次计提只记录起点: %+v %v", entry, err)
+	}
+
+	// 10万现金按3.6%计息10天
+	sim.Advance(10 * 24 * time.Hour)
+	entry, err := engine.AccrueInterest(ctx)
+	if err != nil || entry == nil || entry.Kind != LedgerKindInterest || entry.Debit != LedgerAccountCash || entry.Amount != 100 {
+		t.Fatalf("现金利息不正确: %+v %v", entry, err)
+	}
+	if entry, _ := engine.AccrueInterest(ctx); entry != nil {
+		t.Errorf("同一天不应重复计提: %+v", entry)
+	}
+
+	// 现金为负时按融资利率支付利息
+	engine.RecordCashMovement(ctx, CashMovement{Kind: LedgerKindWithdrawal, Amount: 150100})
+	sim.Advance(24 * time.Hour)
+	entry, err = engine.AccrueInterest(ctx)
+	if err != nil || entry == nil || entry.Debit != LedgerAccountInterest || entry.Amount != 10 {
+		t.Fatalf("融资利息不正确: %+v %v", entry, err)
+	}
+	if account, _ := engine.GetAccount(ctx); math.Abs(account.Cash+50010) > 1e-9 {
+		t.Errorf("利息应调整账户现金: %v", account.Cash)
+	}
+
+	// 计提起点从账簿中最后一条利息分录恢复
+	restarted := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	restarted.SetClock(sim)
+	restarted.SetAccrual(AccrualConfig{CashRatePercent: 3.6})
+	restarted.RecordCashMovement(ctx, CashMovement{Kind: LedgerKindInterest, Amount: 1, Time: sim.Now().AddDate(0, 0, -2)})
+	if entry, _ := restarted.AccrueInterest(ctx); entry == nil || entry.Amount != 20 {
+		t.Errorf("应从最后一条利息分录计提两天: %+v", entry)
+	}
+}
@@ -0,0 +1,88 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// Broker 定义了券商接口，交易引擎通过它提交和取消订单
+type Broker interface {
+	// Name 返回券商名称
+	Name() string
+
+	// SubmitOrder 提交订单，返回券商接受（或立即成交）后的订单
+	SubmitOrder(ctx context.Context, order Order) (*Order, error)
+
+	// CancelOrder 取消订单
+	CancelOrder(ctx context.Context, order Order) error
+
+	// HealthCheck 检查与券商的连接状态
+	HealthCheck(ctx context.Context) error
+}
+
+// SimulatedBroker 是一个模拟券商，接受所有订单，市价单按最新报价立即成交
+type SimulatedBroker struct {
+	name        string
+	dataManager *datasource.Manager
+	clock       clock.Clock // 成交时间使用的时钟，为空时使用系统时间
+}
+
+// NewSimulatedBroker 创建一个新的模拟券商
+func NewSimulatedBroker(name string, dataManager *datasource.Manager) *SimulatedBroker {
+	if name == "" {
+		name = "simulated"
+	}
+
+	return &SimulatedBroker{
+		name:        name,
+		dataManager: dataManager,
+	}
+}
+
+// SetClock 设置成交时间使用的时钟，回放时与引擎使用同一个模拟时钟；应在下单前设置
+func (b *SimulatedBroker) SetClock(c clock.Clock) {
+	b.clock = c
+}
+
+// Name 返回券商名称
+func (b *SimulatedBroker) Name() string {
+	return b.name
+}
+
+// SubmitOrder 提交订单，市价单在能获取报价时立即成交
+func (b *SimulatedBroker) SubmitOrder(ctx context.Context, order Order) (*Order, error) {
+	order.Status = OrderStatusAccepted
+	order.BrokerOrderID = fmt.Sprintf("sim-%s", order.ID)
+
+	if order.Type != OrderTypeMarket || b.dataManager == nil {
+		return &order, nil
+	}
+
+	// 获取最新价格
+	quote, err := b.dataManager.GetRealTimeQuote(ctx, order.Symbol)
+	if err != nil {
+		return &order, nil
+	}
+
+	filledTime := clock.Or(b.clock).Now()
+	order.Status = OrderStatusFilled
+	order.FilledQty = order.Quantity
+	order.AvgFillPrice = quote.LastPrice
+	order.FilledAt = &filledTime
+	order.UpdatedAt = filledTime
+
+	return &order, nil
+}
+
+// CancelOrder 取消订单，模拟券商总是成功
+func (b *SimulatedBroker) CancelOrder(ctx context.Context, order Order) error {
+	return nil
+}
+
+// HealthCheck 检查连接状态，模拟券商总是健康的
+func (b *SimulatedBroker) HealthCheck(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,21 @@
+package trading
+
+import (
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+)
+
+// SetClock 设置引擎时钟，订单、持仓、事件和风控统计的时间戳都来自该时钟
+// 回测和回放时传入模拟时钟，为空时使用系统时间；应在引擎开始交易前设置
+// 下单的确认截止时间是真实的延迟预算，始终使用系统时间
+func (e *BaseTradingEngine) SetClock(c clock.Clock) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clock = c
+}
+
+// now 返回引擎时钟的当前时间（内部方法）
+func (e *BaseTradingEngine) now() time.Time {
+	return clock.Or(e.clock).Now()
+}
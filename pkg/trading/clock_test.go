@@ -0,0 +1,84 @@
+package trading
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+func TestEngineClock(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	sim := clock.NewManual(start)
+
+	engine := NewBaseTradingEngine(datasource.NewManager(), BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetClock(sim)
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicOrders)
+	defer sub.Close()
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	order, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy)
+	if err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if !order.CreatedAt.Equal(start) {
+		t.Errorf("订单时间应来自引擎时钟: %v", order.CreatedAt)
+	}
+	if evt := <-sub.C; !evt.Timestamp.Equal(start) {
+		t.Errorf("事件时间应来自引擎时钟: %v", evt.Timestamp)
+	}
+
+	sim.Advance(time.Hour)
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 5, 0, OrderTypeMarket, OrderSideSell); err != nil {
+		t.Fatalf("卖出失败: %v", err)
+	}
+	position, err := engine.GetPosition(ctx, "AAPL")
+	if err != nil {
+		t.Fatalf("获取持仓失败: %v", err)
+	}
+	if !position.UpdatedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("持仓更新时间应随时钟推进: %v", position.UpdatedAt)
+	}
+}
+
+func TestWatchlistClock(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	sim := clock.NewManual(start)
+	manager := datasource.NewManager()
+	manager.AddDataSource(&quoteSource{quotes: map[string]*datasource.Quote{
+		"AAPL": {Symbol: "AAPL", LastPrice: 100, Timestamp: start},
+	}})
+
+	engine := NewBaseTradingEngine(manager, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	watchlist := NewWatchlist(engine, manager)
+	watchlist.SetClock(sim)
+	expires := start.Add(time.Hour)
+	if err := watchlist.AddItem(WatchlistItem{Symbol: "AAPL", Quantity: 10, IsBuyList: true, TargetPrice: 90, ExpiresAt: &expires}); err != nil {
+		t.Fatalf("添加监控项失败: %v", err)
+	}
+	item, _ := watchlist.GetItemBySymbol("AAPL")
+	if !item.AddedAt.Equal(start) {
+		t.Errorf("添加时间应来自时钟: %v", item.AddedAt)
+	}
+
+	// 系统时间早已超过过期时间，但按模拟时钟监控项仍然有效
+	watchlist.ScanWatchlist(ctx)
+	if item, _ := watchlist.GetItem(item.ID); item.Status != WatchStatusActive {
+		t.Errorf("模拟时钟未到过期时间时应保持活跃: %s", item.Status)
+	}
+
+	sim.Advance(2 * time.Hour)
+	watchlist.ScanWatchlist(ctx)
+	item, _ = watchlist.GetItem(item.ID)
+	if item.Status != WatchStatusExpired || !item.UpdatedAt.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("时钟推进后监控项应过期: %s %v", item.Status, item.UpdatedAt)
+	}
+}
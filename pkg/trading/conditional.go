@@ -0,0 +1,467 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// ChainStatus 表示条件订单链的状态
+type ChainStatus string
+
+// 条件订单链状态常量
+const (
+	ChainStatusActive    ChainStatus = "active"    // 仍有等待条件或未完成的腿
+	ChainStatusCompleted ChainStatus = "completed" // 所有腿都已成交、取消或失败
+	ChainStatusCanceled  ChainStatus = "canceled"  // 订单链被取消
+)
+
+// LegStatus 表示条件订单链中一条腿的状态
+type LegStatus string
+
+// 条件订单腿状态常量
+const (
+	LegStatusWaiting   LegStatus = "waiting"   // 等待前置腿成交或价格条件
+	LegStatusSubmitted LegStatus = "submitted" // 订单已提交，尚未结束
+	LegStatusFilled    LegStatus = "filled"    // 订单已结束且有成交（可能是部分成交后撤单）
+	LegStatusCanceled  LegStatus = "canceled"  // 订单没有成交就结束，或因前置腿未成交、同组其他腿成交而不再提交
+	LegStatusFailed    LegStatus = "failed"    // 下单被拒绝
+)
+
+// 条件订单链的订单标签，子订单还带有 chain:<订单链ID> 标签
+const TagOrderChain = "order_chain"
+
+// EventOrderChainUpdated 是条件订单链状态变化时在 orders 主题发布的事件类型
+const EventOrderChainUpdated = "order_chain_updated"
+
+// ChainLeg 表示条件订单链中的一条腿，条件满足时提交其中的订单
+type ChainLeg struct {
+	Name        string       `json:"name"`
+	Order       OrderRequest `json:"order"`                  // 数量为0时使用前置腿的成交数量
+	After       string       `json:"after,omitempty"`        // 前置腿的名称，该腿有成交且已结束后才提交
+	PriceAbove  float64      `json:"price_above,omitempty"`  // 最新价不低于该价格时才提交
+	PriceBelow  float64      `json:"price_below,omitempty"`  // 最新价不高于该价格时才提交
+	PriceSymbol string       `json:"price_symbol,omitempty"` // 价格条件使用的股票，为空时为本腿订单的股票
+	OCOGroup    string       `json:"oco_group,omitempty"`    // 同组的腿一条有成交后撤销其余各腿，如止损和止盈
+
+	Status       LegStatus `json:"status"`
+	OrderID      string    `json:"order_id,omitempty"`
+	FilledQty    int64     `json:"filled_qty"`
+	AvgFillPrice float64   `json:"avg_fill_price,omitempty"`
+	Error        string    `json:"error,omitempty"` // 下单失败、撤单失败或不再提交的原因
+}
+
+// terminal 检查腿是否已经结束（内部方法）
+func (l *ChainLeg) terminal() bool {
+	return l.Status == LegStatusFilled || l.Status == LegStatusCanceled || l.Status == LegStatusFailed
+}
+
+// OrderChain 表示由引擎管理的条件订单链，例如入场成交后同时挂出止损和止盈（同一OCO组），
+// 或配对交易中一条腿成交后再提交另一条腿。订单链的每次变化写入预写日志，重启后继续管理
+type OrderChain struct {
+	ID        string      `json:"id"`
+	Strategy  string      `json:"strategy,omitempty"` // 各腿订单没有指定策略时使用
+	Legs      []ChainLeg  `json:"legs"`
+	Status    ChainStatus `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Validate 检查订单链是否有效，前置腿必须排在依赖它的腿之前
+func (c OrderChain) Validate() error {
+	if len(c.Legs) == 0 {
+		return fmt.Errorf("order chain requires at least one leg")
+	}
+	names := make(map[string]bool, len(c.Legs))
+	for i, leg := range c.Legs {
+		if leg.Name == "" {
+			return fmt.Errorf("legs[%d]: name is required", i)
+		}
+		if names[leg.Name] {
+			return fmt.Errorf("legs[%d]: duplicate name %q", i, leg.Name)
+		}
+		if leg.After != "" && !names[leg.After] {
+			return fmt.Errorf("legs[%d]: after %q must name an earlier leg", i, leg.After)
+		}
+		names[leg.Name] = true
+
+		if leg.Order.Symbol == "" {
+			return fmt.Errorf("legs[%d]: %w", i, ErrInvalidSymbol)
+		}
+		if leg.Order.Side != OrderSideBuy && leg.Order.Side != OrderSideSell {
+			return fmt.Errorf("legs[%d]: %w", i, ErrInvalidOrderSide)
+		}
+		if leg.Order.Quantity < 0 || (leg.Order.Quantity == 0 && leg.After == "") {
+			return fmt.Errorf("legs[%d]: %w", i, ErrInvalidQuantity)
+		}
+		if leg.PriceAbove < 0 || leg.PriceBelow < 0 {
+			return fmt.Errorf("legs[%d]: %w", i, ErrInvalidPrice)
+		}
+	}
+	return nil
+}
+
+// leg 返回指定名称的腿（内部方法）
+func (c *OrderChain) leg(name string) *ChainLeg {
+	for i := range c.Legs {
+		if c.Legs[i].Name == name {
+			return &c.Legs[i]
+		}
+	}
+	return nil
+}
+
+// copy 返回订单链的副本，腿列表、标签和元数据不与原订单链共享（内部方法）
+func (c *OrderChain) copy() OrderChain {
+	copied := *c
+	copied.Legs = append([]ChainLeg(nil), c.Legs...)
+	for i := range copied.Legs {
+		copied.Legs[i].Order.Tags = append([]string(nil), copied.Legs[i].Order.Tags...)
+		copied.Legs[i].Order.Metadata = cloneMetadata(copied.Legs[i].Order.Metadata)
+	}
+	return copied
+}
+
+// changed 检查订单链的状态或各腿的进度是否与之前不同（内部方法）
+func (c *OrderChain) changed(before OrderChain) bool {
+	if c.Status != before.Status {
+		return true
+	}
+	for i, leg := range c.Legs {
+		old := before.Legs[i]
+		if leg.Status != old.Status || leg.OrderID != old.OrderID || leg.FilledQty != old.FilledQty || leg.Error != old.Error {
+			return true
+		}
+	}
+	return false
+}
+
+// chainTag 返回订单链子订单的标签（内部函数）
+func chainTag(id string) string {
+	return "chain:" + id
+}
+
+// chainOf 返回订单所属的订单链ID，不属于订单链时为空（内部函数）
+func chainOf(tags []string) string {
+	for _, tag := range tags {
+		if id, ok := strings.CutPrefix(tag, "chain:"); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// SubmitOrderChain 校验并登记条件订单链，立即提交没有条件的腿，返回订单链的当前状态
+// 之后由 CheckOrderChains 根据成交和最新价格提交其余各腿
+func (e *BaseTradingEngine) SubmitOrderChain(ctx context.Context, chain OrderChain) (*OrderChain, error) {
+	if err := chain.Validate(); err != nil {
+		return nil, reject(RejectCodeInvalidParams, err)
+	}
+
+	e.chainMu.Lock()
+	defer e.chainMu.Unlock()
+
+	now := e.now()
+	e.mu.Lock()
+	chain.ID = e.newID("chain")
+	e.mu.Unlock()
+	chain.Status = ChainStatusActive
+	chain.CreatedAt, chain.UpdatedAt = now, now
+	chain = chain.copy()
+	for i := range chain.Legs {
+		leg := &chain.Legs[i]
+		leg.Status, leg.OrderID, leg.FilledQty, leg.AvgFillPrice, leg.Error = LegStatusWaiting, "", 0, 0, ""
+	}
+
+	// 提交任何订单之前先写入预写日志，写入失败时不登记
+	if err := e.recordChain(&chain); err != nil {
+		return nil, reject(RejectCodeInternal, err)
+	}
+	if e.chains == nil {
+		e.chains = make(map[string]*OrderChain)
+	}
+	e.chains[chain.ID] = &chain
+
+	before := chain.copy()
+	e.syncChain(ctx, &chain, e.quotePrice(ctx))
+	e.chainUpdated(&chain, before)
+	result := chain.copy()
+	return &result, nil
+}
+
+// GetOrderChain 返回条件订单链
+func (e *BaseTradingEngine) GetOrderChain(id string) (*OrderChain, error) {
+	e.chainMu.Lock()
+	defer e.chainMu.Unlock()
+
+	chain, exists := e.chains[id]
+	if !exists {
+		return nil, fmt.Errorf("order chain %s not found", id)
+	}
+	result := chain.copy()
+	return &result, nil
+}
+
+// GetOrderChains 返回按创建时间排序的所有条件订单链
+func (e *BaseTradingEngine) GetOrderChains() []OrderChain {
+	e.chainMu.Lock()
+	defer e.chainMu.Unlock()
+
+	chains := make([]OrderChain, 0, len(e.chains))
+	for _, chain := range e.chains {
+		chains = append(chains, chain.copy())
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i].CreatedAt.Before(chains[j].CreatedAt)
+	})
+	return chains
+}
+
+// CancelOrderChain 撤销订单链所有未完成的订单，等待中的腿不再提交，已成交的数量作为普通持仓保留
+func (e *BaseTradingEngine) CancelOrderChain(ctx context.Context, id string) error {
+	e.chainMu.Lock()
+	defer e.chainMu.Unlock()
+
+	chain, exists := e.chains[id]
+	if !exists {
+		return fmt.Errorf("order chain %s not found", id)
+	}
+	if chain.Status != ChainStatusActive {
+		return fmt.Errorf("order chain %s is already %s", id, chain.Status)
+	}
+	before := chain.copy()
+	for i := range chain.Legs {
+		leg := &chain.Legs[i]
+		switch leg.Status {
+		case LegStatusWaiting:
+			leg.Status, leg.Error = LegStatusCanceled, "chain canceled"
+		case LegStatusSubmitted:
+			e.cancelLeg(ctx, leg)
+		}
+	}
+	e.refreshChain(chain)
+	chain.Status = ChainStatusCanceled
+	e.chainUpdated(chain, before)
+	return nil
+}
+
+// CheckOrderChains 按成交和最新报价推进进行中的条件订单链，返回状态有变化的订单链
+func (e *BaseTradingEngine) CheckOrderChains(ctx context.Context) ([]OrderChain, error) {
+	return e.checkOrderChains(ctx, e.quotePrice(ctx))
+}
+
+// checkOrderChains 使用给定的报价函数推进条件订单链（内部方法）
+func (e *BaseTradingEngine) checkOrderChains(ctx context.Context, price func(symbol string) (float64, bool)) ([]OrderChain, error) {
+	if !e.IsEnabled() {
+		return nil, nil
+	}
+
+	e.chainMu.Lock()
+	defer e.chainMu.Unlock()
+
+	var changed []OrderChain
+	for _, chain := range e.chains {
+		if chain.Status != ChainStatusActive {
+			continue
+		}
+		before := chain.copy()
+		e.syncChain(ctx, chain, price)
+		if e.chainUpdated(chain, before) {
+			changed = append(changed, chain.copy())
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].CreatedAt.Before(changed[j].CreatedAt)
+	})
+	return changed, nil
+}
+
+// quotePrice 返回通过数据管理器获取最新价的报价函数（内部方法）
+func (e *BaseTradingEngine) quotePrice(ctx context.Context) func(symbol string) (float64, bool) {
+	return func(symbol string) (float64, bool) {
+		if e.dataManager == nil {
+			return 0, false
+		}
+		quote, err := e.dataManager.GetRealTimeQuote(ctx, symbol)
+		if err != nil || quote == nil || quote.LastPrice <= 0 {
+			return 0, false
+		}
+		return quote.LastPrice, true
+	}
+}
+
+// syncChain 反复更新各腿的成交并提交条件已满足的腿，直到没有新的进展（内部方法，调用方持有 chainMu）
+// 立即成交的订单可以在同一次检查中触发后续的腿
+func (e *BaseTradingEngine) syncChain(ctx context.Context, chain *OrderChain, price func(symbol string) (float64, bool)) {
+	for progress := true; progress; {
+		progress = false
+		e.refreshChain(chain)
+		e.cancelOCOSiblings(ctx, chain)
+
+		for i := range chain.Legs {
+			leg := &chain.Legs[i]
+			if leg.Status != LegStatusWaiting {
+				continue
+			}
+			var parent *ChainLeg
+			if leg.After != "" {
+				parent = chain.leg(leg.After)
+				if parent.Status == LegStatusCanceled || parent.Status == LegStatusFailed {
+					leg.Status, leg.Error = LegStatusCanceled, fmt.Sprintf("leg %s was not filled", parent.Name)
+					progress = true
+					continue
+				}
+				if parent.Status != LegStatusFilled {
+					continue
+				}
+			}
+			if !legPriceMet(leg, price) {
+				continue
+			}
+
+			req := leg.Order
+			if req.Quantity == 0 {
+				req.Quantity = parent.FilledQty
+			}
+			if req.Strategy == "" {
+				req.Strategy = chain.Strategy
+			}
+			req.Tags = append(append([]string(nil), req.Tags...), TagOrderChain, chainTag(chain.ID))
+			order, err := e.PlaceOrder(ctx, req)
+			if err != nil {
+				leg.Status, leg.Error = LegStatusFailed, err.Error()
+			} else {
+				leg.Status, leg.OrderID = LegStatusSubmitted, order.ID
+			}
+			progress = true
+		}
+	}
+
+	for _, leg := range chain.Legs {
+		if !leg.terminal() {
+			return
+		}
+	}
+	chain.Status = ChainStatusCompleted
+}
+
+// legPriceMet 检查腿的价格条件是否满足，没有价格条件时总是满足（内部函数）
+func legPriceMet(leg *ChainLeg, price func(symbol string) (float64, bool)) bool {
+	if leg.PriceAbove <= 0 && leg.PriceBelow <= 0 {
+		return true
+	}
+	symbol := leg.PriceSymbol
+	if symbol == "" {
+		symbol = leg.Order.Symbol
+	}
+	last, ok := price(symbol)
+	if !ok || last <= 0 {
+		return false
+	}
+	if leg.PriceAbove > 0 && last < leg.PriceAbove {
+		return false
+	}
+	if leg.PriceBelow > 0 && last > leg.PriceBelow {
+		return false
+	}
+	return true
+}
+
+// refreshChain 根据订单的最新状态更新已提交的腿（内部方法，调用方持有 chainMu）
+func (e *BaseTradingEngine) refreshChain(chain *OrderChain) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for i := range chain.Legs {
+		leg := &chain.Legs[i]
+		if leg.OrderID == "" {
+			continue
+		}
+		order, ok := e.orders[leg.OrderID]
+		if !ok {
+			continue
+		}
+		leg.FilledQty, leg.AvgFillPrice = order.FilledQty, order.AvgFillPrice
+		if leg.Status == LegStatusSubmitted && !isOpenOrder(order) {
+			if order.FilledQty > 0 {
+				leg.Status = LegStatusFilled
+			} else {
+				leg.Status = LegStatusCanceled
+			}
+		}
+	}
+}
+
+// cancelOCOSiblings 同一OCO组中一条腿有成交后，撤销其余腿的订单，等待中的腿不再提交（内部方法，调用方持有 chainMu）
+// 部分成交时即撤销，避免止损和止盈同时成交而反向开仓
+func (e *BaseTradingEngine) cancelOCOSiblings(ctx context.Context, chain *OrderChain) {
+	for i := range chain.Legs {
+		filled := &chain.Legs[i]
+		if filled.OCOGroup == "" || filled.FilledQty == 0 {
+			continue
+		}
+		for j := range chain.Legs {
+			sibling := &chain.Legs[j]
+			if j == i || sibling.OCOGroup != filled.OCOGroup {
+				continue
+			}
+			switch sibling.Status {
+			case LegStatusWaiting:
+				sibling.Status, sibling.Error = LegStatusCanceled, fmt.Sprintf("leg %s in OCO group %s filled", filled.Name, filled.OCOGroup)
+			case LegStatusSubmitted:
+				if sibling.FilledQty == 0 {
+					e.cancelLeg(ctx, sibling)
+				}
+			}
+		}
+	}
+	e.refreshChain(chain)
+}
+
+// cancelLeg 撤销腿的未完成订单，失败时记录到腿的错误中（内部方法，调用方持有 chainMu）
+func (e *BaseTradingEngine) cancelLeg(ctx context.Context, leg *ChainLeg) {
+	if _, open := e.openOrder(leg.OrderID); !open {
+		return
+	}
+	if err := e.CancelOrder(ctx, leg.OrderID); err != nil {
+		leg.Error = fmt.Sprintf("cancel %s: %v", leg.OrderID, err)
+	}
+}
+
+// chainUpdated 在订单链有变化时写入预写日志并发布事件，返回是否有变化（内部方法，调用方持有 chainMu）
+func (e *BaseTradingEngine) chainUpdated(chain *OrderChain, before OrderChain) bool {
+	if !chain.changed(before) {
+		return false
+	}
+	chain.UpdatedAt = e.now()
+	if err := e.recordChain(chain); err != nil {
+		e.log().Error("记录订单链%s失败: %v", chain.ID, err)
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.publish(events.TopicOrders, EventOrderChainUpdated, chain.copy())
+	return true
+}
+
+// recordChain 将订单链的当前状态写入预写日志（内部方法）
+func (e *BaseTradingEngine) recordChain(chain *OrderChain) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.wal == nil || e.replaying {
+		return nil
+	}
+	copied := chain.copy()
+	return e.wal.Append(WALRecord{Type: WALOrderChainUpdated, Timestamp: e.now(), Chain: &copied})
+}
+
+// applyChain 恢复订单链的最新状态（内部方法，由 Recover 在启用引擎之前调用）
+func (e *BaseTradingEngine) applyChain(chain OrderChain) {
+	if e.chains == nil {
+		e.chains = make(map[string]*OrderChain)
+	}
+	e.chains[chain.ID] = &chain
+}
@@ -0,0 +1,154 @@
+package trading
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrderChainBracket(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10, DuplicateWindowSeconds: 300})
+	engine.SetBroker(&restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}})
+	engine.Enable()
+
+	// 入场成交后同时挂出止损和止盈，两者互相撤销
+	chain, err := engine.SubmitOrderChain(ctx, OrderChain{
+		Strategy: "breakout",
+		Legs: []ChainLeg{
+			{Name: "entry", Order: OrderRequest{Symbol: "AAPL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 10}},
+			{Name: "stop", After: "entry", OCOGroup: "exit", Order: OrderRequest{Symbol: "AAPL", Side: OrderSideSell, Type: OrderTypeStop, StopPrice: 95}},
+			{Name: "target", After: "entry", OCOGroup: "exit", Order: OrderRequest{Symbol: "AAPL", Side: OrderSideSell, Type: OrderTypeLimit, Price: 110}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("提交订单链失败: %v", err)
+	}
+	if chain.Legs[0].Status != LegStatusFilled || chain.Legs[1].Status != LegStatusSubmitted || chain.Legs[2].Status != LegStatusSubmitted {
+		t.Fatalf("入场立即成交后应在同一次提交中挂出止损和止盈: %+v", chain.Legs)
+	}
+	target, _ := engine.GetOrder(ctx, chain.Legs[2].OrderID)
+	if target.Quantity != 10 || target.Strategy != "breakout" || chainOf(target.Tags) != chain.ID {
+		t.Errorf("未指定数量的腿应使用前置腿的成交数量: %+v", target)
+	}
+
+	engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: Order{ID: target.ID, Status: OrderStatusFilled, FilledQty: 10, AvgFillPrice: 110}})
+	changed, _ := engine.CheckOrderChains(ctx)
+	if len(changed) != 1 || changed[0].Status != ChainStatusCompleted || changed[0].Legs[1].Status != LegStatusCanceled {
+		t.Fatalf("止盈成交后应撤销止损并结束订单链: %+v", changed)
+	}
+	if stop, _ := engine.GetOrder(ctx, chain.Legs[1].OrderID); stop.Status != OrderStatusCanceled {
+		t.Errorf("同组的止损单应被撤销: %+v", stop)
+	}
+	if err := engine.CancelOrderChain(ctx, chain.ID); err == nil {
+		t.Error("已结束的订单链不能取消")
+	}
+}
+
+func TestOrderChainConditions(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}})
+	engine.Enable()
+
+	if _, err := engine.SubmitOrderChain(ctx, OrderChain{Legs: []ChainLeg{
+		{Name: "hedge", After: "entry", Order: OrderRequest{Symbol: "MSFT", Side: OrderSideSell, Type: OrderTypeMarket}},
+		{Name: "entry", Order: OrderRequest{Symbol: "AAPL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 10}},
+	}}); GetRejectCode(err) != RejectCodeInvalidParams {
+		t.Errorf("前置腿必须排在前面: %v", err)
+	}
+
+	// 换仓：AAPL限价买单成交后卖出持有的MSFT；突破腿在NVDA突破500后买入
+	if _, err := engine.SubmitOrder(ctx, "MSFT", 5, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	chain, err := engine.SubmitOrderChain(ctx, OrderChain{Legs: []ChainLeg{
+		{Name: "long", Order: OrderRequest{Symbol: "AAPL", Side: OrderSideBuy, Type: OrderTypeLimit, Price: 99, Quantity: 10}},
+		{Name: "sell", After: "long", Order: OrderRequest{Symbol: "MSFT", Side: OrderSideSell, Type: OrderTypeMarket, Quantity: 5}},
+		{Name: "breakout", PriceAbove: 500, PriceSymbol: "NVDA", Order: OrderRequest{Symbol: "SMH", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 3}},
+	}})
+	if err != nil {
+		t.Fatalf("提交订单链失败: %v", err)
+	}
+	prices := map[string]float64{"NVDA": 490}
+	quote := func(symbol string) (float64, bool) {
+		price, ok := prices[symbol]
+		return price, ok
+	}
+	if changed, _ := engine.checkOrderChains(ctx, quote); len(changed) != 0 {
+		t.Fatalf("条件未满足时不应提交: %+v", changed)
+	}
+
+	prices["NVDA"] = 505
+	engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: Order{ID: chain.Legs[0].OrderID, Status: OrderStatusFilled, FilledQty: 10, AvgFillPrice: 99}})
+	changed, _ := engine.checkOrderChains(ctx, quote)
+	if len(changed) != 1 || changed[0].Status != ChainStatusCompleted {
+		t.Fatalf("成交和价格条件满足后应提交其余各腿: %+v", changed)
+	}
+	if pos, err := engine.GetPosition(ctx, "MSFT"); err == nil {
+		t.Errorf("换仓的第二条腿应已卖出: %+v", pos)
+	}
+	if pos, err := engine.GetPosition(ctx, "SMH"); err != nil || pos.Quantity != 3 {
+		t.Errorf("价格条件满足后应买入: %+v %v", pos, err)
+	}
+
+	// 前置腿没有成交就结束时，后续的腿不再提交
+	canceled, _ := engine.SubmitOrderChain(ctx, OrderChain{Legs: []ChainLeg{
+		{Name: "entry", Order: OrderRequest{Symbol: "AMD", Side: OrderSideBuy, Type: OrderTypeLimit, Price: 90, Quantity: 10}},
+		{Name: "stop", After: "entry", Order: OrderRequest{Symbol: "AMD", Side: OrderSideSell, Type: OrderTypeStop, StopPrice: 85}},
+	}})
+	engine.CancelOrder(ctx, canceled.Legs[0].OrderID)
+	changed, _ = engine.checkOrderChains(ctx, quote)
+	if len(changed) != 1 || changed[0].Status != ChainStatusCompleted || changed[0].Legs[1].Status != LegStatusCanceled || changed[0].Legs[1].Error == "" {
+		t.Errorf("入场撤单后止损不应提交: %+v", changed)
+	}
+}
+
+func TestOrderChainRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	ctx := context.Background()
+	limits := TradingLimits{MaxPositions: 10}
+
+	wal, err := OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	broker := &restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, limits)
+	engine.SetBroker(broker)
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	chain, err := engine.SubmitOrderChain(ctx, OrderChain{Legs: []ChainLeg{
+		{Name: "entry", Order: OrderRequest{Symbol: "AAPL", Side: OrderSideBuy, Type: OrderTypeLimit, Price: 99, Quantity: 10}},
+		{Name: "target", After: "entry", Order: OrderRequest{Symbol: "AAPL", Side: OrderSideSell, Type: OrderTypeLimit, Price: 110}},
+	}})
+	if err != nil {
+		t.Fatalf("提交订单链失败: %v", err)
+	}
+	wal.Close()
+
+	// 重启后订单链和挂单一起恢复，入场成交后继续提交后续的腿
+	wal, err = OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, limits)
+	recovered.SetBroker(broker)
+	recovered.SetWAL(wal)
+	if _, err := recovered.Recover(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	restored, err := recovered.GetOrderChain(chain.ID)
+	if err != nil || restored.Legs[0].Status != LegStatusSubmitted || restored.Legs[0].OrderID != chain.Legs[0].OrderID {
+		t.Fatalf("订单链应从预写日志恢复: %+v %v", restored, err)
+	}
+
+	recovered.Enable()
+	recovered.ApplyOrderUpdate(ctx, OrderUpdate{Order: Order{ID: restored.Legs[0].OrderID, Status: OrderStatusFilled, FilledQty: 10, AvgFillPrice: 99}})
+	changed, _ := recovered.CheckOrderChains(ctx)
+	if len(changed) != 1 || changed[0].Legs[1].Status != LegStatusSubmitted {
+		t.Fatalf("恢复后应继续管理订单链: %+v", changed)
+	}
+}
@@ -0,0 +1,79 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// timeoutCancelWait 是超时订单自动撤单的最长等待时间
+const timeoutCancelWait = 5 * time.Second
+
+// OrderTimeout 表示订单超时事件的内容
+type OrderTimeout struct {
+	Order     Order         `json:"order"`
+	Budget    time.Duration `json:"budget"`  // 下单到截止时间的延迟预算
+	Elapsed   time.Duration `json:"elapsed"` // 下单到发现超时的实际耗时
+	Canceled  bool          `json:"canceled"`
+	CancelErr string        `json:"cancel_error,omitempty"` // 撤单失败时订单保留在引擎中，需要与券商对账
+}
+
+// submitDeadline 返回订单的确认截止时间，取上下文截止时间和请求延迟预算中较早者（内部函数）
+func submitDeadline(ctx context.Context, req OrderRequest, now time.Time) (time.Time, bool) {
+	deadline, ok := ctx.Deadline()
+	if req.MaxLatencyMillis > 0 {
+		budget := now.Add(time.Duration(req.MaxLatencyMillis) * time.Millisecond)
+		if !ok || budget.Before(deadline) {
+			deadline, ok = budget, true
+		}
+	}
+	return deadline, ok
+}
+
+// acknowledgedBy 检查券商是否在截止时间前确认了订单（内部函数）
+// 已成交的订单无法撤销，即使确认较晚也视为已确认
+func acknowledgedBy(order Order, deadline time.Time) bool {
+	switch order.Status {
+	case OrderStatusFilled:
+		return true
+	case OrderStatusAccepted, OrderStatusPartial:
+		return !time.Now().After(deadline)
+	default:
+		return false
+	}
+}
+
+// cancelTimedOut 撤销未在截止时间内确认的订单并发布超时事件，返回给下单方的错误（内部方法，调用方持锁）
+// started 是提交时的系统时间，订单的创建时间来自引擎时钟，不能用于计算耗时
+func (e *BaseTradingEngine) cancelTimedOut(order Order, started, deadline time.Time) error {
+	timeout := OrderTimeout{
+		Budget:  deadline.Sub(started),
+		Elapsed: time.Since(started),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutCancelWait)
+	defer cancel()
+	if err := e.broker.CancelOrder(ctx, order); err != nil {
+		// 预写日志中订单停留在已提交状态，恢复时会作为待对账订单列出
+		timeout.CancelErr = err.Error()
+		e.orders[order.ID] = order
+	} else {
+		order.Status = OrderStatusCanceled
+		order.UpdatedAt = e.now()
+		e.recordOrLog(WALOrderCanceled, &order, nil)
+		e.orders[order.ID] = order
+		e.publish(events.TopicOrders, EventOrderCanceled, order)
+		timeout.Canceled = true
+	}
+
+	timeout.Order = order
+	e.publish(events.TopicOrders, EventOrderTimeout, timeout)
+
+	err := fmt.Errorf("%w: broker did not acknowledge order %s within %s", ErrDeadlineExceeded, order.ID, timeout.Budget)
+	if timeout.CancelErr != "" {
+		err = fmt.Errorf("%w, cancel failed: %s", err, timeout.CancelErr)
+	}
+	return reject(RejectCodeDeadline, err)
+}
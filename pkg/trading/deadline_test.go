@@ -0,0 +1,89 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// unackedBroker 是只返回已提交状态、不确认订单的测试券商
+type unackedBroker struct {
+	delay    time.Duration
+	canceled []string
+}
+
+func (b *unackedBroker) Name() string { return "unacked" }
+
+func (b *unackedBroker) SubmitOrder(ctx context.Context, order Order) (*Order, error) {
+	select {
+	case <-time.After(b.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	order.Status = OrderStatusSubmitted
+	return &order, nil
+}
+
+func (b *unackedBroker) CancelOrder(ctx context.Context, order Order) error {
+	b.canceled = append(b.canceled, order.ID)
+	return nil
+}
+
+func (b *unackedBroker) HealthCheck(ctx context.Context) error { return nil }
+
+func TestOrderDeadline(t *testing.T) {
+	ctx := context.Background()
+	broker := &unackedBroker{delay: time.Second}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicOrders)
+	defer sub.Close()
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	req := OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy, MaxLatencyMillis: 20}
+	_, err := engine.PlaceOrder(ctx, req)
+	if !errors.Is(err, ErrDeadlineExceeded) {
+		t.Fatalf("券商超时未确认应返回截止时间错误: %v", err)
+	}
+	var rejection *RejectionError
+	if !errors.As(err, &rejection) || rejection.Code != RejectCodeDeadline {
+		t.Errorf("拒单代码应为 %s: %v", RejectCodeDeadline, err)
+	}
+	if len(broker.canceled) != 1 {
+		t.Fatalf("超时订单应被自动撤销: %v", broker.canceled)
+	}
+
+	var timeout *OrderTimeout
+	for len(sub.C) > 0 {
+		evt := <-sub.C
+		if payload, ok := evt.Payload.(OrderTimeout); ok && evt.Type == EventOrderTimeout {
+			timeout = &payload
+		}
+	}
+	if timeout == nil || !timeout.Canceled || timeout.Order.Status != OrderStatusCanceled {
+		t.Errorf("应发布已撤单的超时事件: %+v", timeout)
+	}
+
+	// 在截止时间内返回但没有确认的订单同样会被撤销
+	broker.delay = 0
+	if _, err := engine.PlaceOrder(ctx, req); !errors.Is(err, ErrDeadlineExceeded) {
+		t.Errorf("未确认的订单应返回截止时间错误: %v", err)
+	}
+	if open, _ := engine.GetOpenOrders(ctx); len(open) != 0 {
+		t.Errorf("撤销后不应有未完成订单: %+v", open)
+	}
+
+	// 没有延迟预算时不撤单
+	req.MaxLatencyMillis = 0
+	if _, err := engine.PlaceOrder(ctx, req); err != nil {
+		t.Errorf("没有截止时间的订单不应被拒绝: %v", err)
+	}
+	if len(broker.canceled) != 2 {
+		t.Errorf("撤单次数 = %d, 期望 2", len(broker.canceled))
+	}
+}
@@ -0,0 +1,39 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+)
+
+// checkDuplicateEntry 检查同一策略在该股票上是否已有同方向的未完成订单，或在窗口期内已成交的同方向订单（内部方法，调用方持锁）
+// 扫描器和自选股同时触发同一信号时，可以避免重复开仓。买入开多和卖出开空都受保护，减少已有持仓的订单不受限制；
+// 止损和止盈挂单不算开仓订单。没有策略归属的订单视为同一个策略
+func (e *BaseTradingEngine) checkDuplicateEntry(req OrderRequest, now time.Time) error {
+	window := time.Duration(e.limits.DuplicateWindowSeconds) * time.Second
+	if window <= 0 || e.reducesPosition(req) {
+		return nil
+	}
+
+	for _, order := range e.orders {
+		if order.Symbol != req.Symbol || order.Side != req.Side || order.Strategy != req.Strategy || IsProtective(order) {
+			continue
+		}
+		// 同一个分批订单计划或条件订单链的子订单不算重复
+		if id := planOf(req.Tags); id != "" && id == planOf(order.Tags) {
+			continue
+		}
+		if id := chainOf(req.Tags); id != "" && id == chainOf(order.Tags) {
+			continue
+		}
+
+		switch order.Status {
+		case OrderStatusPending, OrderStatusSubmitted, OrderStatusAccepted, OrderStatusPartial:
+			return fmt.Errorf("%w: open order %s for %s", ErrDuplicateOrder, order.ID, req.Symbol)
+		case OrderStatusFilled:
+			if order.FilledAt != nil && now.Sub(*order.FilledAt) < window {
+				return fmt.Errorf("%w: order %s for %s filled %s ago", ErrDuplicateOrder, order.ID, req.Symbol, now.Sub(*order.FilledAt).Round(time.Second))
+			}
+		}
+	}
+	return nil
+}
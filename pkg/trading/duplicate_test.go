@@ -0,0 +1,76 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDuplicateEntryGuard(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10, DuplicateWindowSeconds: 60})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+
+	req := OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy, Strategy: "momentum"}
+	if _, err := engine.PlaceOrder(ctx, req); err != nil {
+		t.Fatalf("首次买入失败: %v", err)
+	}
+
+	// 窗口期内同一策略再次买入被拒绝
+	_, err := engine.PlaceOrder(ctx, req)
+	var rejection *RejectionError
+	if !errors.Is(err, ErrDuplicateOrder) || !errors.As(err, &rejection) || rejection.Code != RejectCodeDuplicate {
+		t.Errorf("重复开仓应以 %s 拒绝: %v", RejectCodeDuplicate, err)
+	}
+
+	// 其他策略和卖出不受影响
+	other := req
+	other.Strategy = "breakout"
+	if _, err := engine.PlaceOrder(ctx, other); err != nil {
+		t.Errorf("其他策略买入不应被拒绝: %v", err)
+	}
+	sell := req
+	sell.Side = OrderSideSell
+	if _, err := engine.PlaceOrder(ctx, sell); err != nil {
+		t.Errorf("卖出不应被拒绝: %v", err)
+	}
+
+	// 关闭保护后允许再次买入
+	engine.SetLimits(TradingLimits{MaxPositions: 10})
+	if _, err := engine.PlaceOrder(ctx, req); err != nil {
+		t.Errorf("关闭保护后买入不应被拒绝: %v", err)
+	}
+}
+
+func TestDuplicateEntryGuardShort(t *testing.T) {
+	ctx := context.Background()
+	broker := &restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{PositionMode: PositionModeHedging, StopMode: StopModeBroker}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, DuplicateWindowSeconds: 300})
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	short := OrderRequest{Symbol: "TSLA", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideSell, Strategy: "s"}
+	if _, err := engine.PlaceOrder(ctx, short); err != nil {
+		t.Fatalf("卖空失败: %v", err)
+	}
+	if open, _ := engine.GetOpenOrders(ctx); len(open) != 1 || open[0].Side != OrderSideBuy || !IsProtective(open[0]) {
+		t.Fatalf("空头应挂出买入止损单: %+v", open)
+	}
+
+	// 窗口期内同一策略再次卖空被拒绝
+	if _, err := engine.PlaceOrder(ctx, short); !errors.Is(err, ErrDuplicateOrder) {
+		t.Errorf("重复卖空应被拒绝: %v", err)
+	}
+
+	// 空头自己的买入止损单不影响回补
+	if _, err := engine.ClosePosition(ctx, "TSLA", 0); err != nil {
+		t.Fatalf("回补空头失败: %v", err)
+	}
+	if positions, _ := engine.GetPositions(ctx); len(positions) != 0 {
+		t.Errorf("回补后不应有持仓: %+v", positions)
+	}
+	if open, _ := engine.GetOpenOrders(ctx); len(open) != 0 {
+		t.Errorf("回补后止损单应被撤销: %+v", open)
+	}
+}
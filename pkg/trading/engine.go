@@ -7,7 +7,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/yourusername/qhft-system/pkg/clock"
 	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/logger"
+	"github.com/yourusername/qhft-system/pkg/schedule"
 )
 
 // 错误常量
@@ -22,12 +26,56 @@ var (
 	ErrTradeLimitExceeded = errors.New("trading limit exceeded")
 	ErrAccountNotFound  = errors.New("account not found")
 	ErrBrokerNotAvailable = errors.New("broker not available")
+	ErrDeadlineExceeded = errors.New("order deadline exceeded")
+	ErrDuplicateOrder = errors.New("duplicate entry order")
+	ErrSymbolRestricted = errors.New("symbol is restricted")
+	ErrStrategyPaused = errors.New("strategy is paused")
+	ErrTradeNotFound = errors.New("trade not found")
+	ErrDailyLossHalt = errors.New("daily loss limit reached")
+	ErrSymbolHalted = errors.New("symbol is halted")
+	ErrShortSaleRestricted = errors.New("short sale restriction in effect")
+	ErrNoBorrow = errors.New("shares not available to borrow")
 )
 
+// RejectionError 表示带有拒单原因代码的错误
+type RejectionError struct {
+	Code RejectCode
+	Err  error
+}
+
+// Error 返回错误描述
+func (e *RejectionError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 返回底层错误，便于使用errors.Is判断
+func (e *RejectionError) Unwrap() error {
+	return e.Err
+}
+
+// reject 使用拒单原因代码包装错误
+func reject(code RejectCode, err error) error {
+	return &RejectionError{Code: code, Err: err}
+}
+
+// GetRejectCode 获取错误对应的拒单原因代码
+func GetRejectCode(err error) RejectCode {
+	var rejErr *RejectionError
+	if errors.As(err, &rejErr) {
+		return rejErr.Code
+	}
+	return RejectCodeInternal
+}
+
+// PreTradeCheck 是下单前的风控检查，返回错误时订单以 RejectCodeRiskLimit 拒绝
+// 检查在引擎持有锁时调用，不能再调用引擎的方法
+type PreTradeCheck func(ctx context.Context, req OrderRequest) error
+
 // TradingEngine 定义了交易引擎的接口
 type TradingEngine interface {
 	// 订单操作
 	SubmitOrder(ctx context.Context, symbol string, quantity int64, price float64, orderType OrderType, orderSide OrderSide) (*Order, error)
+	PlaceOrder(ctx context.Context, req OrderRequest) (*Order, error)
 	CancelOrder(ctx context.Context, orderID string) error
 	GetOrder(ctx context.Context, orderID string) (*Order, error)
 	GetOpenOrders(ctx context.Context) ([]Order, error)
@@ -36,14 +84,20 @@ type TradingEngine interface {
 	// 持仓操作
 	GetPositions(ctx context.Context) ([]Position, error)
 	GetPosition(ctx context.Context, symbol string) (*Position, error)
+	GetStrategyPosition(ctx context.Context, symbol, strategy string) (*Position, error)
 	ClosePosition(ctx context.Context, symbol string, quantity int64) (*Order, error)
 	
 	// 账户操作
 	GetAccount(ctx context.Context) (*Account, error)
+	GetEquityHistory(ctx context.Context, from, to time.Time) ([]EquitySnapshot, error)
+	GetIntradayPnL(ctx context.Context, bucket time.Duration) ([]PnLPoint, error)
 	
 	// 交易统计
 	GetTradeStats(ctx context.Context, startTime, endTime time.Time) (*TradeStats, error)
 	GetTrades(ctx context.Context, symbol string, startTime, endTime time.Time) ([]Trade, error)
+	GetTrade(ctx context.Context, id string) (*Trade, error)
+	ReviewTrade(ctx context.Context, id string, review TradeReview) (*Trade, error)
+	GetExecutionQuality(ctx context.Context, startTime, endTime time.Time) (*ExecutionQualityReport, error)
 	
 	// 引擎控制
 	IsEnabled() bool
@@ -51,11 +105,24 @@ type TradingEngine interface {
 	Disable() error
 	GetLimits() TradingLimits
 	SetLimits(limits TradingLimits) error
+	
+	// 受限股票
+	AddRestrictedSymbol(symbol, reason string, expiresAt time.Time) error
+	RemoveRestrictedSymbol(symbol string) error
+	GetRestrictedSymbols() []RestrictedSymbol
+	CheckRestricted(symbol string) error
+	CheckHalted(symbol string) error
+	
+	// 策略暂停
+	PauseStrategy(strategy, reason string)
+	ResumeStrategy(strategy string) error
+	GetPausedStrategies() []StrategyPause
 }
 
 // BaseTradingEngine 提供基本的交易引擎实现
 type BaseTradingEngine struct {
 	mu            sync.RWMutex
+	protectMu     sync.Mutex // 串行化券商止损和止盈挂单的同步，调用券商时不持有 mu
 	enabled       bool
 	dataManager   *datasource.Manager
 	limits        TradingLimits
@@ -64,8 +131,46 @@ type BaseTradingEngine struct {
 	positions     map[string]Position
 	account       Account
 	trades        []Trade
+	tradeIDs      map[string]bool // trades 中所有交易的ID，用于导入时去重
+	ledger        []LedgerEntry // 现金账簿，按记账顺序排列
+	dividendSource DividendSource
+	accrual       AccrualConfig
+	entitlements  map[string]DividendEntitlement // 按持仓和除息日记录的应收分红
+	interestDate  time.Time                      // 上次计提利息的日期
 	executionChan chan Execution
 	errorChan     chan error
+	tradeLogger   logger.TradeLogger
+	logger        logger.Logger // 后台任务的运行日志，为空时使用全局默认日志
+	eventBus      *events.Bus
+	broker        Broker
+	wal           WAL
+	replaying     bool // 正在回放预写日志
+	checks        []PreTradeCheck
+	rounding      RoundingConfig
+	middlewares   []OrderMiddleware
+	equityStore   EquityStore
+	execQuality   executionQuality
+	ids           IDGenerator
+	restricted    map[string]RestrictedSymbol
+	rateGuard     RateGuardConfig
+	orderTimes    map[string][]time.Time // 按策略记录的下单时间，用于频率异常检测
+	cancelTimes   map[string][]time.Time // 按策略记录的撤单时间
+	paused        map[string]StrategyPause
+	lossDay       time.Time      // 当日亏损统计所在日期的零点
+	dayEquity     float64        // 当日起始权益，当日首次检查时记录
+	dayEquityAt   time.Time      // 记录当日起始权益的时间，之后的出入金不计入当日盈亏
+	lossHalt      *DailyLossHalt // 当日亏损熔断，未熔断时为空
+	calendar      *schedule.Calendar // 交易日历，用于收盘前平仓
+	planMu        sync.Mutex             // 串行化分批订单计划的管理，持有时可以调用引擎的下单方法
+	plans         map[string]*OrderPlan
+	chainMu       sync.Mutex             // 串行化条件订单链的管理，持有时可以调用引擎的下单方法
+	chains        map[string]*OrderChain
+	haltConfig    HaltConfig
+	halts         map[string]SymbolHalt // 检测到停牌的股票
+	shortSale     ShortSaleConfig
+	accountWatch  AccountWatchConfig
+	lastAccount   *Account // 上次的账户快照
+	clock         clock.Clock // 引擎时钟，为空时使用系统时间
 }
 
 // NewBaseTradingEngine 创建基本交易引擎
@@ -77,8 +182,10 @@ func NewBaseTradingEngine(dataManager *datasource.Manager, brokerConfig BrokerCo
 		brokerConfig:  brokerConfig,
 		orders:        make(map[string]Order),
 		positions:     make(map[string]Position),
+		tradeIDs:      make(map[string]bool),
 		executionChan: make(chan Execution, 100), // 缓冲通道，避免阻塞
 		errorChan:     make(chan error, 100),
+		broker:        NewSimulatedBroker(brokerConfig.Name, dataManager),
 	}
 }
 
@@ -94,6 +201,7 @@ func (e *BaseTradingEngine) Enable() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.enabled = true
+	e.recordOrLog(WALEngineEnabled, nil, nil)
 	return nil
 }
 
@@ -102,6 +210,7 @@ func (e *BaseTradingEngine) Disable() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.enabled = false
+	e.recordOrLog(WALEngineDisabled, nil, nil)
 	return nil
 }
 
@@ -117,103 +226,337 @@ func (e *BaseTradingEngine) SetLimits(limits TradingLimits) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.limits = limits
+	e.recordOrLog(WALLimitsUpdated, nil, &limits)
 	return nil
 }
 
+// SetTradeLogger 设置交易日志记录器，用于记录拒单等信息
+func (e *BaseTradingEngine) SetTradeLogger(tradeLogger logger.TradeLogger) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tradeLogger = tradeLogger
+}
+
+// SetLogger 设置运行日志记录器，权益记录、止损监控等后台任务的错误写入该日志
+func (e *BaseTradingEngine) SetLogger(l logger.Logger) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.logger = l
+}
+
+// log 返回运行日志记录器，未设置时使用全局默认日志（内部方法，调用方不能持锁）
+func (e *BaseTradingEngine) log() logger.Logger {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.logLocked()
+}
+
+// logLocked 同 log，供已持锁的调用方使用（内部方法，调用方持锁）
+func (e *BaseTradingEngine) logLocked() logger.Logger {
+	if e.logger != nil {
+		return e.logger
+	}
+	return logger.GetDefaultLogger()
+}
+
+// SetBroker 设置券商，为空时使用模拟券商
+func (e *BaseTradingEngine) SetBroker(broker Broker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if broker == nil {
+		broker = NewSimulatedBroker(e.brokerConfig.Name, e.dataManager)
+	}
+	e.broker = broker
+}
+
+// AddPreTradeCheck 添加下单前的风控检查，按添加顺序执行
+func (e *BaseTradingEngine) AddPreTradeCheck(check PreTradeCheck) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.checks = append(e.checks, check)
+}
+
+// GetBroker 返回当前使用的券商
+func (e *BaseTradingEngine) GetBroker() Broker {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.broker
+}
+
 // SubmitOrder 提交订单
 func (e *BaseTradingEngine) SubmitOrder(ctx context.Context, symbol string, quantity int64, price float64, orderType OrderType, orderSide OrderSide) (*Order, error) {
+	return e.PlaceOrder(ctx, OrderRequest{
+		Symbol:   symbol,
+		Quantity: quantity,
+		Price:    price,
+		Type:     orderType,
+		Side:     orderSide,
+	})
+}
+
+// PlaceOrder 根据下单请求提交订单，请求依次经过注册的中间件，被拒绝的请求会记录到交易日志
+func (e *BaseTradingEngine) PlaceOrder(ctx context.Context, req OrderRequest) (*Order, error) {
+	order, err := e.orderHandler()(ctx, req)
+	if err != nil {
+		e.logRejection(req, err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// placeOrder 校验并提交订单（内部方法）
+func (e *BaseTradingEngine) placeOrder(ctx context.Context, req OrderRequest) (*Order, error) {
 	if !e.IsEnabled() {
-		return nil, ErrTradeDisabled
+		return nil, reject(RejectCodeTradingDisabled, ErrTradeDisabled)
 	}
 	
+	// 在加锁前获取到达报价，用于执行质量分析
+	arrival := e.arrivalQuote(ctx, req.Symbol)
+	// 卖出订单同样在加锁前查询可借券情况
+	locate := e.locateShort(ctx, req)
+	
 	e.mu.Lock()
-	defer e.mu.Unlock()
+	protect := "" // 成交后需要重新挂出止损和止盈的持仓，释放锁后再调用券商
+	defer func() {
+		e.mu.Unlock()
+		if protect != "" {
+			e.syncProtectiveOrders(ctx, protect)
+		}
+	}()
 	
 	// 检查参数
-	if symbol == "" {
-		return nil, ErrInvalidSymbol
+	if req.Symbol == "" {
+		return nil, reject(RejectCodeInvalidParams, ErrInvalidSymbol)
 	}
-	if quantity <= 0 {
-		return nil, ErrInvalidQuantity
+	if req.Quantity <= 0 {
+		return nil, reject(RejectCodeInvalidParams, ErrInvalidQuantity)
 	}
-	if price < 0 && orderType != OrderTypeMarket {
-		return nil, ErrInvalidPrice
+	if req.Price < 0 && req.Type != OrderTypeMarket {
+		return nil, reject(RejectCodeInvalidParams, ErrInvalidPrice)
+	}
+	if req.StopLoss < 0 || req.TakeProfit < 0 {
+		return nil, reject(RejectCodeInvalidParams, ErrInvalidPrice)
 	}
 	
 	// 验证订单类型
-	switch orderType {
+	switch req.Type {
 	case OrderTypeMarket, OrderTypeLimit, OrderTypeStop:
 		// 有效的订单类型
 	default:
-		return nil, ErrInvalidOrderType
+		return nil, reject(RejectCodeInvalidParams, ErrInvalidOrderType)
 	}
 	
 	// 验证订单方向
-	switch orderSide {
+	switch req.Side {
 	case OrderSideBuy, OrderSideSell:
 		// 有效的订单方向
 	default:
-		return nil, ErrInvalidOrderSide
+		return nil, reject(RejectCodeInvalidParams, ErrInvalidOrderSide)
+	}
+	
+	if err := ValidateMetadata(req.Metadata); err != nil {
+		return nil, reject(RejectCodeInvalidParams, err)
 	}
 	
+	// 价格和数量取整到有效的报价单位和交易单位
+	if e.rounding.Enabled {
+		rounded, err := e.rounding.Apply(req)
+		if err != nil {
+			return nil, reject(RejectCodeInvalidParams, err)
+		}
+		req = rounded
+	}
+	
+	// 暂停的策略只允许减仓
+	if err := e.checkPaused(req); err != nil {
+		return nil, reject(RejectCodeStrategyPaused, err)
+	}
+	
+	// 当日亏损熔断期间只允许减仓
+	if err := e.checkDailyLossHalt(req, e.now()); err != nil {
+		return nil, reject(RejectCodeRiskLimit, err)
+	}
+	
+	// 停牌的股票不能下单
+	if err := e.halted(req.Symbol); err != nil {
+		return nil, reject(RejectCodeHalted, err)
+	}
+	
+	// 受限股票只允许减仓
+	if err := e.checkRestricted(req, e.now()); err != nil {
+		return nil, reject(RejectCodeRestricted, err)
+	}
+	
+	// 开空或加空的订单检查卖空限制和可借券情况
+	shortWarnings, err := e.checkShortSale(req, arrival, locate)
+	if err != nil {
+		return nil, err
+	}
+	shortQty := e.shortQuantity(req)
+	
 	// 检查交易限制
 	positionCount := len(e.positions)
-	if orderSide == OrderSideBuy && positionCount >= e.limits.MaxPositions {
-		return nil, fmt.Errorf("%w: maximum positions reached (%d)", ErrTradeLimitExceeded, e.limits.MaxPositions)
+	if req.Side == OrderSideBuy && positionCount >= e.limits.MaxPositions {
+		return nil, reject(RejectCodeRiskLimit, fmt.Errorf("%w: maximum positions reached (%d)", ErrTradeLimitExceeded, e.limits.MaxPositions))
+	}
+	
+	// 检查订单所属策略的交易限制
+	if err := e.checkStrategyLimits(req, arrival, e.now()); err != nil {
+		return nil, reject(RejectCodeRiskLimit, err)
+	}
+	
+	// 同一策略在窗口期内不重复开仓
+	if err := e.checkDuplicateEntry(req, e.now()); err != nil {
+		return nil, reject(RejectCodeDuplicate, err)
+	}
+	
+	// 执行外部风控检查
+	for _, check := range e.checks {
+		if err := check(ctx, req); err != nil {
+			return nil, reject(RejectCodeRiskLimit, err)
+		}
 	}
 	
 	// TODO: 实现更多限制检查...
 	
+	// 下单频率超过上限时暂停策略，本次订单不再提交
+	if err := e.recordOrderRate(req.Strategy, e.now()); err != nil {
+		return nil, reject(RejectCodeStrategyPaused, err)
+	}
+	
 	// 创建新订单
-	now := time.Now()
+	now := e.now()
 	order := Order{
-		ID:        fmt.Sprintf("order-%d", now.UnixNano()),
-		Symbol:    symbol,
-		Quantity:  quantity,
-		Price:     price,
-		Type:      orderType,
-		Side:      orderSide,
-		Status:    OrderStatusPending,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-	
-	// 在实际系统中，这里应该调用券商API提交订单
-	// 这里我们假设订单已提交并接受
-	order.Status = OrderStatusAccepted
-	
-	// 保存订单
-	e.orders[order.ID] = order
-	
-	// 如果是市价单，假设立即成交
-	if orderType == OrderTypeMarket {
-		// 获取最新价格
-		quote, err := e.dataManager.GetPrimaryDataSource()
-		if err == nil {
-			realTimequote, err := quote.GetRealTimeQuote(ctx, symbol)
-			if err == nil {
-				fillPrice := realTimequote.LastPrice
-				filledTime := time.Now()
-				
-				// 更新订单
-				order.Status = OrderStatusFilled
-				order.FilledQty = quantity
-				order.AvgFillPrice = fillPrice
-				order.FilledAt = &filledTime
-				order.UpdatedAt = filledTime
-				
-				// 更新持仓
-				e.updatePosition(order)
-				
-				// 更新订单保存
-				e.orders[order.ID] = order
-			}
-		}
+		ID:            e.newID("order"),
+		Symbol:        req.Symbol,
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		StopPrice:     req.StopPrice,
+		Type:          req.Type,
+		Side:          req.Side,
+		Status:        OrderStatusPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		ClientOrderID: req.ClientOrderID,
+		Strategy:      req.Strategy,
+		Tags:          req.Tags,
+		StopLoss:      req.StopLoss,
+		TakeProfit:    req.TakeProfit,
+		TimeExit:      req.TimeExit,
+		Metadata:      cloneMetadata(req.Metadata),
+	}
+	
+	// 提交前先写入预写日志，写入失败时不提交
+	if err := e.record(WALOrderSubmitted, &order, nil); err != nil {
+		return nil, reject(RejectCodeInternal, err)
+	}
+	
+	// 提交到券商，有确认截止时间时券商调用受截止时间约束
+	// 截止时间是真实的延迟预算，使用系统时间而不是引擎时钟
+	started := time.Now()
+	deadline, hasDeadline := submitDeadline(ctx, req, started)
+	if hasDeadline && !started.Before(deadline) {
+		e.recordOrLog(WALOrderRejected, &order, nil)
+		return nil, reject(RejectCodeDeadline, fmt.Errorf("%w before submission", ErrDeadlineExceeded))
+	}
+	submitCtx := ctx
+	if hasDeadline {
+		var cancel context.CancelFunc
+		submitCtx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	submitted, err := e.broker.SubmitOrder(submitCtx, order)
+	if err != nil && hasDeadline && errors.Is(submitCtx.Err(), context.DeadlineExceeded) {
+		// 超时后券商可能已经收到订单，撤单以免遗留未知状态的订单
+		pending := order
+		pending.Status = OrderStatusSubmitted
+		return nil, e.cancelTimedOut(pending, started, deadline)
+	}
+	if err != nil {
+		rejected := order
+		rejected.Status = OrderStatusRejected
+		rejected.RejectReason = err.Error()
+		e.recordOrLog(WALOrderRejected, &rejected, nil)
+		return nil, reject(RejectCodeBrokerReject, err)
+	}
+	order = *submitted
+	// 券商返回的订单不一定保留括号订单的止损和止盈以及自定义元数据
+	order.StopLoss, order.TakeProfit = req.StopLoss, req.TakeProfit
+	order.TimeExit = req.TimeExit
+	order.Metadata = cloneMetadata(req.Metadata)
+	if hasDeadline && !acknowledgedBy(order, deadline) {
+		return nil, e.cancelTimedOut(order, started, deadline)
+	}
+	e.recordArrival(order, e.broker.Name(), arrival)
+	
+	// 保存订单，立即成交的订单先发布接受事件再发布成交事件
+	accepted := order
+	if accepted.Status == OrderStatusFilled {
+		accepted.Status = OrderStatusAccepted
+		accepted.FilledQty = 0
+		accepted.AvgFillPrice = 0
+		accepted.FilledAt = nil
+	}
+	e.recordOrLog(WALOrderAccepted, &accepted, nil)
+	e.orders[order.ID] = accepted
+	e.publish(events.TopicOrders, EventOrderAccepted, accepted)
+	if len(shortWarnings) > 0 {
+		e.publishShortSaleWarning(accepted, shortQty, locate, shortWarnings)
+	}
+	
+	if order.Status == OrderStatusFilled {
+		// 更新订单和持仓
+		e.recordOrLog(WALOrderFilled, &order, nil)
+		e.orders[order.ID] = order
+		e.updatePosition(order)
+		e.recordFill(order)
+		e.publishFill(order)
+		protect = e.positionKey(order)
 	}
 	
 	return &order, nil
 }
 
+// logRejection 将被拒绝的下单请求写入交易日志（内部方法）
+func (e *BaseTradingEngine) logRejection(req OrderRequest, err error) {
+	e.mu.RLock()
+	tradeLogger := e.tradeLogger
+	e.publish(events.TopicOrders, EventOrderRejected, OrderRejection{
+		Request:   req,
+		Code:      GetRejectCode(err),
+		Reason:    err.Error(),
+		Timestamp: e.now(),
+	})
+	e.mu.RUnlock()
+
+	if tradeLogger == nil {
+		return
+	}
+
+	entry := logger.RejectionEntry{
+		Timestamp:     e.now(),
+		Code:          string(GetRejectCode(err)),
+		Reason:        err.Error(),
+		Source:        "engine",
+		Symbol:        req.Symbol,
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		StopPrice:     req.StopPrice,
+		OrderType:     string(req.Type),
+		Side:          string(req.Side),
+		Strategy:      req.Strategy,
+		ClientOrderID: req.ClientOrderID,
+		Tags:          req.Tags,
+		Metadata:      req.Metadata,
+	}
+
+	if logErr := tradeLogger.LogRejection(entry); logErr != nil {
+		fmt.Printf("Failed to log order rejection: %v\n", logErr)
+	}
+}
+
 // CancelOrder 取消订单
 func (e *BaseTradingEngine) CancelOrder(ctx context.Context, orderID string) error {
 	if !e.IsEnabled() {
@@ -233,13 +576,20 @@ func (e *BaseTradingEngine) CancelOrder(ctx context.Context, orderID string) err
 		return fmt.Errorf("cannot cancel order with status %s", order.Status)
 	}
 	
-	// 在实际系统中，这里应该调用券商API取消订单
-	// 这里我们假设订单已取消
+	// 撤单频率超过上限时暂停策略，撤单本身继续执行
+	e.recordCancelRate(order.Strategy, e.now())
+	
+	// 通过券商取消订单
+	if err := e.broker.CancelOrder(ctx, order); err != nil {
+		return fmt.Errorf("broker failed to cancel order: %w", err)
+	}
 	order.Status = OrderStatusCanceled
-	order.UpdatedAt = time.Now()
+	order.UpdatedAt = e.now()
 	
 	// 更新订单
+	e.recordOrLog(WALOrderCanceled, &order, nil)
 	e.orders[orderID] = order
+	e.publish(events.TopicOrders, EventOrderCanceled, order)
 	
 	return nil
 }
@@ -313,6 +663,10 @@ func (e *BaseTradingEngine) GetPosition(ctx context.Context, symbol string) (*Po
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	
+	if e.hedging() {
+		return e.netPosition(symbol)
+	}
+	
 	pos, exists := e.positions[symbol]
 	if !exists {
 		return nil, fmt.Errorf("no position found for symbol %s", symbol)
@@ -327,6 +681,10 @@ func (e *BaseTradingEngine) ClosePosition(ctx context.Context, symbol string, qu
 		return nil, ErrTradeDisabled
 	}
 	
+	if e.hedging() {
+		return e.closeHedgedPositions(ctx, symbol, quantity)
+	}
+	
 	e.mu.Lock()
 	pos, exists := e.positions[symbol]
 	if !exists {
@@ -345,38 +703,50 @@ func (e *BaseTradingEngine) ClosePosition(ctx context.Context, symbol string, qu
 	return e.SubmitOrder(ctx, symbol, quantity, 0, OrderTypeMarket, OrderSideSell)
 }
 
-// GetAccount 获取账户信息
+// GetAccount 获取账户信息的副本，权益按现金加持仓市值重新计算，修改返回的账户不影响引擎
 func (e *BaseTradingEngine) GetAccount(ctx context.Context) (*Account, error) {
-	e.mu.RLock()
-	defer e.mu.RUnlock()
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	
 	// 在实际系统中，这里应该调用券商API获取最新账户信息
-	// 这里我们简单返回当前账户
+	// 这里我们按引擎的现金和持仓计算当前账户
+	e.ensureAccount()
+	e.refreshAccount()
 	
-	// 计算未实现盈亏
-	var unrealizedPnL float64
+	account := e.account
+	return &account, nil
+}
+
+// refreshAccount 按持仓重新计算账户的未实现盈亏、总盈亏和权益（内部方法，调用方持锁）
+// 空头持仓的市值为负数，卖空所得已计入现金，因此权益为现金加所有持仓市值之和
+func (e *BaseTradingEngine) refreshAccount() {
+	var unrealizedPnL, marketValue float64
 	for _, pos := range e.positions {
 		unrealizedPnL += pos.UnrealizedPnL
+		marketValue += pos.MarketValue
 	}
 	
 	e.account.UnrealizedPnL = unrealizedPnL
 	e.account.TotalPnL = e.account.RealizedPnL + unrealizedPnL
-	e.account.UpdatedAt = time.Now()
-	
-	// 如果初始账户为空，创建一个默认账户
-	if e.account.ID == "" {
-		e.account.ID = "default-account"
-		e.account.BrokerID = e.brokerConfig.Name
-		e.account.Cash = 100000 // 默认10万美元
-		e.account.BuyingPower = e.account.Cash * 2 // 假设2倍杠杆
-		e.account.Equity = e.account.Cash + e.account.UnrealizedPnL
-		e.account.UpdatedAt = time.Now()
-		e.account.MaxPositionSize = 1000
-		e.account.MaxPositionValuePercent = e.limits.MaxPositionSizePercent
-		e.account.MaxDailyTrades = e.limits.MaxDailyTrades
+	e.account.Equity = e.account.Cash + marketValue
+	e.account.UpdatedAt = e.now()
+}
+
+// ensureAccount 如果初始账户为空，创建一个默认账户（内部方法，调用方持锁）
+func (e *BaseTradingEngine) ensureAccount() {
+	if e.account.ID != "" {
+		return
 	}
-	
-	return &e.account, nil
+	e.account.ID = "default-account"
+	e.account.BrokerID = e.brokerConfig.Name
+	e.account.Cash = 100000 // 默认10万美元
+	e.account.BuyingPower = e.account.Cash * 2 // 假设2倍杠杆
+	e.account.Equity = e.account.Cash + e.account.UnrealizedPnL
+	e.account.UpdatedAt = e.now()
+	e.account.MaxPositionSize = 1000
+	e.account.MaxPositionValuePercent = e.limits.MaxPositionSizePercent
+	e.account.MaxDailyTrades = e.limits.MaxDailyTrades
+	e.account.PositionMode = e.positionMode()
 }
 
 // GetTradeStats 获取交易统计
@@ -488,6 +858,11 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 	if order.Status != OrderStatusFilled {
 		return
 	}
+	e.postFill(order)
+	if e.hedging() {
+		e.updateHedgedPosition(order)
+		return
+	}
 	
 	// 更新现有持仓或创建新持仓
 	symbol := order.Symbol
@@ -504,7 +879,9 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 				CurrentPrice: order.AvgFillPrice,
 				Cost:         float64(order.FilledQty) * order.AvgFillPrice,
 				OpenedAt:     *order.FilledAt,
-				UpdatedAt:    time.Now(),
+				UpdatedAt:    e.now(),
+				Strategy:     order.Strategy,
+				Metadata:     cloneMetadata(order.Metadata),
 			}
 			
 			// 设置止损和止盈
@@ -515,6 +892,7 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 			if e.limits.TakeProfitPercent > 0 {
 				pos.TakeProfit = pos.EntryPrice * (1 + e.limits.TakeProfitPercent/100)
 			}
+			applyBracket(&pos, order)
 		} else {
 			// 加仓，计算平均成本
 			totalQuantity := pos.Quantity + order.FilledQty
@@ -523,7 +901,7 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 			pos.Cost = totalCost
 			pos.EntryPrice = totalCost / float64(totalQuantity)
 			pos.CurrentPrice = order.AvgFillPrice
-			pos.UpdatedAt = time.Now()
+			pos.UpdatedAt = e.now()
 			
 			// 更新止损和止盈
 			if e.limits.StopLossPercent > 0 {
@@ -533,6 +911,7 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 			if e.limits.TakeProfitPercent > 0 {
 				pos.TakeProfit = pos.EntryPrice * (1 + e.limits.TakeProfitPercent/100)
 			}
+			applyBracket(&pos, order)
 		}
 	} else {
 		// 卖出
@@ -541,10 +920,11 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 			return
 		}
 		
-		// 减仓
+		// 减仓，剩余持仓按开仓均价计算成本
 		pos.Quantity -= order.FilledQty
+		pos.Cost = float64(pos.Quantity) * pos.EntryPrice
 		pos.CurrentPrice = order.AvgFillPrice
-		pos.UpdatedAt = time.Now()
+		pos.UpdatedAt = e.now()
 		
 		// 计算实现盈亏
 		realizedPnL := float64(order.FilledQty) * (order.AvgFillPrice - pos.EntryPrice)
@@ -559,7 +939,7 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 			holdTimeHours := closedTime.Sub(pos.OpenedAt).Hours()
 			
 			trade := Trade{
-				ID:                 fmt.Sprintf("trade-%d", time.Now().UnixNano()),
+				ID:                 e.tradeID(order.ID),
 				Symbol:             symbol,
 				EntryOrder:         e.orders[order.ID], // 这里应该是开仓订单ID
 				ExitOrder:          &order,
@@ -572,12 +952,17 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 				OpenedAt:           pos.OpenedAt,
 				ClosedAt:           closedTime,
 				HoldTime:           holdTimeHours,
+				Strategy:           pos.Strategy,
+				Metadata:           mergeMetadata(pos.Metadata, order.Metadata),
 			}
 			
-			e.trades = append(e.trades, trade)
+			e.appendTrade(trade)
 			
 			// 删除持仓
 			delete(e.positions, symbol)
+			pos.MarketValue = 0
+			pos.UnrealizedPnL = 0
+			e.publish(events.TopicPositions, EventPositionClosed, pos)
 		} else {
 			// 更新持仓
 			e.positions[symbol] = pos
@@ -590,5 +975,6 @@ func (e *BaseTradingEngine) updatePosition(order Order) {
 		pos.UnrealizedPnL = pos.MarketValue - pos.Cost
 		pos.PnLPercent = (pos.CurrentPrice/pos.EntryPrice - 1) * 100
 		e.positions[symbol] = pos
+		e.publish(events.TopicPositions, EventPositionUpdated, pos)
 	}
 } 
\ No newline at end of file
@@ -0,0 +1,249 @@
+package trading
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// EquitySnapshot 表示某一时刻的账户权益快照
+type EquitySnapshot struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Equity        float64   `json:"equity"`
+	Cash          float64   `json:"cash"`
+	RealizedPnL   float64   `json:"realized_pnl"`
+	UnrealizedPnL float64   `json:"unrealized_pnl"`
+	LongExposure  float64   `json:"long_exposure"`  // 多头市值
+	ShortExposure float64   `json:"short_exposure"` // 空头市值的绝对值
+	GrossExposure float64   `json:"gross_exposure"`
+	NetExposure   float64   `json:"net_exposure"`
+	Positions     int       `json:"positions"`
+}
+
+// EquityStore 定义了账户权益快照存储的接口
+type EquityStore interface {
+	// Append 追加一个快照，快照按时间顺序追加
+	Append(snapshot EquitySnapshot) error
+
+	// Range 返回时间范围内（包含两端）按时间排序的快照
+	Range(from, to time.Time) ([]EquitySnapshot, error)
+
+	// Close 关闭存储
+	Close() error
+}
+
+// memoryEquityStore 是保存在内存中的权益快照存储，未配置存储时使用（内部类型）
+type memoryEquityStore struct {
+	mu        sync.RWMutex
+	snapshots []EquitySnapshot
+}
+
+// Append 追加一个快照
+func (s *memoryEquityStore) Append(snapshot EquitySnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots = append(s.snapshots, snapshot)
+	return nil
+}
+
+// Range 返回时间范围内的快照
+func (s *memoryEquityStore) Range(from, to time.Time) ([]EquitySnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var snapshots []EquitySnapshot
+	for _, snapshot := range s.snapshots {
+		if inRange(snapshot.Timestamp, from, to) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+// Close 关闭存储
+func (s *memoryEquityStore) Close() error {
+	return nil
+}
+
+// FileEquityStore 是基于文件的权益快照存储，每行一条JSON记录
+type FileEquityStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenFileEquityStore 打开或创建权益快照文件
+func OpenFileEquityStore(path string) (*FileEquityStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create equity directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open equity store: %v", err)
+	}
+	return &FileEquityStore{path: path, file: file}, nil
+}
+
+// Append 追加一个快照
+func (s *FileEquityStore) Append(snapshot EquitySnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("equity store is closed")
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode equity snapshot: %v", err)
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write equity snapshot: %v", err)
+	}
+	return nil
+}
+
+// Range 读取文件并返回时间范围内的快照，末尾未写完的记录会被忽略
+func (s *FileEquityStore) Range(from, to time.Time) ([]EquitySnapshot, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open equity store: %v", err)
+	}
+	defer file.Close()
+
+	var snapshots []EquitySnapshot
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return snapshots, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read equity store: %v", err)
+		}
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		var snapshot EquitySnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("corrupt equity snapshot at line %d: %v", line, err)
+		}
+		if inRange(snapshot.Timestamp, from, to) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+}
+
+// Close 关闭存储
+func (s *FileEquityStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// inRange 检查时间是否在范围内，零值表示不限（内部函数）
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
+
+// SetEquityStore 设置账户权益快照的存储，为空时使用内存存储
+func (e *BaseTradingEngine) SetEquityStore(store EquityStore) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.equityStore = store
+}
+
+// equity 返回权益快照存储，未设置时创建内存存储（内部方法）
+func (e *BaseTradingEngine) equity() EquityStore {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.equityStore == nil {
+		e.equityStore = &memoryEquityStore{}
+	}
+	return e.equityStore
+}
+
+// RecordEquity 记录当前的账户权益、现金和持仓敞口
+func (e *BaseTradingEngine) RecordEquity(ctx context.Context) (*EquitySnapshot, error) {
+	snapshot, err := e.equitySnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.equity().Append(snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// equitySnapshot 计算当前的账户权益快照（内部方法）
+func (e *BaseTradingEngine) equitySnapshot(ctx context.Context) (EquitySnapshot, error) {
+	account, err := e.GetAccount(ctx)
+	if err != nil {
+		return EquitySnapshot{}, err
+	}
+	positions, err := e.GetPositions(ctx)
+	if err != nil {
+		return EquitySnapshot{}, err
+	}
+
+	snapshot := EquitySnapshot{
+		Timestamp:     e.now(),
+		Equity:        account.Equity,
+		Cash:          account.Cash,
+		RealizedPnL:   account.RealizedPnL,
+		UnrealizedPnL: account.UnrealizedPnL,
+		Positions:     len(positions),
+	}
+	for _, pos := range positions {
+		if pos.MarketValue >= 0 {
+			snapshot.LongExposure += pos.MarketValue
+		} else {
+			snapshot.ShortExposure -= pos.MarketValue
+		}
+	}
+	snapshot.GrossExposure = snapshot.LongExposure + snapshot.ShortExposure
+	snapshot.NetExposure = snapshot.LongExposure - snapshot.ShortExposure
+	return snapshot, nil
+}
+
+// GetEquityHistory 返回时间范围内的账户权益快照，用于计算回撤、绩效报告和权益曲线
+func (e *BaseTradingEngine) GetEquityHistory(ctx context.Context, from, to time.Time) ([]EquitySnapshot, error) {
+	return e.equity().Range(from, to)
+}
+
+// RunEquityRecorder 按间隔记录账户权益快照，直到上下文取消
+func (e *BaseTradingEngine) RunEquityRecorder(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.RecordEquity(ctx); err != nil {
+			e.log().Error("记录权益快照失败: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package trading
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEquityHistory(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "equity.jsonl")
+	store, err := OpenFileEquityStore(path)
+	if err != nil {
+		t.Fatalf("打开权益快照文件失败: %v", err)
+	}
+	defer store.Close()
+
+	engine := NewBaseTradingEngine(nil, BrokerConfig{PositionMode: PositionModeHedging}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetEquityStore(store)
+	engine.Enable()
+
+	start := time.Now()
+	if _, err := engine.RecordEquity(ctx); err != nil {
+		t.Fatalf("记录权益快照失败: %v", err)
+	}
+	engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy, Strategy: "long"})
+	engine.PlaceOrder(ctx, OrderRequest{Symbol: "MSFT", Quantity: 4, Type: OrderTypeMarket, Side: OrderSideSell, Strategy: "short"})
+	snapshot, err := engine.RecordEquity(ctx)
+	if err != nil {
+		t.Fatalf("记录权益快照失败: %v", err)
+	}
+	if snapshot.LongExposure != 1000 || snapshot.ShortExposure != 400 || snapshot.GrossExposure != 1400 || snapshot.NetExposure != 600 || snapshot.Positions != 2 {
+		t.Errorf("敞口不正确: %+v", snapshot)
+	}
+
+	history, err := engine.GetEquityHistory(ctx, start, time.Now())
+	if err != nil || len(history) != 2 {
+		t.Fatalf("应有2个权益快照: %+v %v", history, err)
+	}
+	if history[1].Equity != snapshot.Equity || history[0].Positions != 0 {
+		t.Errorf("权益快照不正确: %+v", history)
+	}
+	if history, _ := engine.GetEquityHistory(ctx, time.Now().Add(time.Hour), time.Time{}); len(history) != 0 {
+		t.Errorf("时间范围外不应有快照: %+v", history)
+	}
+}
+
+func TestEquityTracksFillsAndMarks(t *testing.T) {
+	ctx := context.Background()
+	broker := &fixedPriceBroker{price: 100}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	before, err := engine.RecordEquity(ctx)
+	if err != nil || before.Equity != 100000 {
+		t.Fatalf("初始权益不正确: %+v %v", before, err)
+	}
+
+	// 按100买入100股后上涨到110，权益增加1000
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 100, Type: OrderTypeMarket, Side: OrderSideBuy}); err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	engine.ApplyMarks(map[string]float64{"AAPL": 110})
+	after, err := engine.RecordEquity(ctx)
+	if err != nil || after.Cash != 90000 || after.Equity != 101000 || after.UnrealizedPnL != 1000 {
+		t.Fatalf("成交和估值后权益应变化: %+v %v", after, err)
+	}
+
+	// 按110卖出后权益等于现金
+	broker.price = 110
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 100, Type: OrderTypeMarket, Side: OrderSideSell}); err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	closed, _ := engine.RecordEquity(ctx)
+	if closed.Equity != 101000 || closed.Cash != 101000 || closed.Positions != 0 {
+		t.Errorf("平仓后权益应等于现金: %+v", closed)
+	}
+
+	// 返回的账户是副本
+	account, _ := engine.GetAccount(ctx)
+	account.Cash = 0
+	if again, _ := engine.GetAccount(ctx); again.Cash != 101000 {
+		t.Errorf("修改返回的账户不应影响引擎: %+v", again)
+	}
+}
@@ -0,0 +1,94 @@
+package trading
+
+import (
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// 引擎事件类型常量
+const (
+	EventOrderAccepted    = "order_accepted"     // 订单已接受
+	EventOrderFilled      = "order_filled"       // 订单已成交
+	EventOrderCanceled    = "order_canceled"     // 订单已取消
+	EventOrderRejected    = "order_rejected"     // 订单被拒绝
+	EventOrderTimeout     = "order_timeout"      // 券商未在截止时间内确认订单
+	EventExecution        = "execution"          // 成交回报
+	EventPositionUpdated  = "position_updated"   // 持仓更新
+	EventPositionClosed   = "position_closed"    // 持仓已平仓
+	EventPositionMarked   = "position_marked"    // 持仓按最新价格重新估值
+	EventStrategyPaused   = "strategy_paused"    // 策略被暂停，如下单频率异常
+	EventStrategyResumed  = "strategy_resumed"   // 策略已恢复
+	EventStopTriggered    = "stop_triggered"     // 本地触发止损或止盈
+	EventTimeExit         = "time_exit"          // 持仓达到最长持有时间或收盘前平仓
+	EventDailyLossHalt    = "daily_loss_halt"    // 当日亏损达到上限，撤单并平仓
+	EventDailyLossReset   = "daily_loss_reset"   // 当日亏损熔断解除
+	EventSymbolHalted     = "symbol_halted"      // 检测到股票停牌
+	EventSymbolResumed    = "symbol_resumed"     // 停牌的股票恢复交易
+	EventShortSaleWarning = "short_sale_warning" // 卖空订单难以借券或借券费率较高
+)
+
+// OrderRejection 表示订单被拒绝事件的内容
+type OrderRejection struct {
+	Request   OrderRequest `json:"request"`
+	Code      RejectCode   `json:"code"`
+	Reason    string       `json:"reason"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// SetEventBus 设置事件总线，引擎的订单、成交和持仓变化会发布到总线上
+func (e *BaseTradingEngine) SetEventBus(bus *events.Bus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.eventBus = bus
+}
+
+// publish 发布引擎事件（内部方法），事件总线未设置或正在回放预写日志时忽略
+func (e *BaseTradingEngine) publish(topic, eventType string, payload interface{}) {
+	if e.eventBus == nil || e.replaying {
+		return
+	}
+
+	e.eventBus.Publish(events.Event{
+		Topic:     topic,
+		Type:      eventType,
+		Timestamp: e.now(),
+		Payload:   payload,
+	})
+}
+
+// publishFill 发布订单成交及对应的成交回报事件（内部方法）
+func (e *BaseTradingEngine) publishFill(order Order) {
+	if e.replaying || order.FilledAt == nil {
+		return
+	}
+
+	e.emitExecution(e.fillExecution(order))
+	e.publish(events.TopicOrders, EventOrderFilled, order)
+}
+
+// fillExecution 由成交的订单生成成交回报（内部方法）
+func (e *BaseTradingEngine) fillExecution(order Order) Execution {
+	return Execution{
+		ID:         e.newID("exec"),
+		OrderID:    order.ID,
+		Symbol:     order.Symbol,
+		Quantity:   order.FilledQty,
+		Price:      order.AvgFillPrice,
+		Side:       order.Side,
+		ExecutedAt: *order.FilledAt,
+		Commission: order.Commission,
+	}
+}
+
+// emitExecution 将成交回报写入成交通道并发布到事件总线，通道满时丢弃（内部方法）
+func (e *BaseTradingEngine) emitExecution(execution Execution) {
+	if e.replaying {
+		return
+	}
+	select {
+	case e.executionChan <- execution:
+	default:
+	}
+	e.publish(events.TopicFills, EventExecution, execution)
+}
@@ -0,0 +1,315 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// arrivalQuoteTimeout 是下单时获取到达报价的超时时间
+const arrivalQuoteTimeout = 250 * time.Millisecond
+
+// unattributedStrategy 是没有策略归属的订单在报告中使用的分组名
+const unattributedStrategy = "unattributed"
+
+// ExecutionRecord 表示一个订单的执行质量记录，包括提交时的报价和成交结果
+type ExecutionRecord struct {
+	OrderID     string     `json:"order_id"`
+	Symbol      string     `json:"symbol"`
+	Side        OrderSide  `json:"side"`
+	Type        OrderType  `json:"type"`
+	Quantity    int64      `json:"quantity"`
+	Broker      string     `json:"broker"`
+	Strategy    string     `json:"strategy,omitempty"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	ArrivalBid  float64    `json:"arrival_bid"`
+	ArrivalAsk  float64    `json:"arrival_ask"`
+	ArrivalLast float64    `json:"arrival_last"`
+	FilledAt    *time.Time `json:"filled_at,omitempty"`
+	FilledQty   int64      `json:"filled_qty"`
+	FillPrice   float64    `json:"fill_price"`
+}
+
+// ArrivalPrice 返回到达价格：有双边报价时取中间价，否则取最新成交价
+func (r ExecutionRecord) ArrivalPrice() float64 {
+	if r.ArrivalBid > 0 && r.ArrivalAsk > 0 {
+		return (r.ArrivalBid + r.ArrivalAsk) / 2
+	}
+	return r.ArrivalLast
+}
+
+// SlippageBps 返回相对到达价格的滑点（基点），正数表示成本
+func (r ExecutionRecord) SlippageBps() (float64, bool) {
+	arrival := r.ArrivalPrice()
+	if r.FilledQty == 0 || arrival <= 0 {
+		return 0, false
+	}
+	return r.sign() * (r.FillPrice - arrival) / arrival * 10000, true
+}
+
+// EffectiveSpread 返回有效价差 2*方向*(成交价-中间价)
+func (r ExecutionRecord) EffectiveSpread() (float64, bool) {
+	if r.FilledQty == 0 || r.ArrivalBid <= 0 || r.ArrivalAsk <= 0 {
+		return 0, false
+	}
+	return 2 * r.sign() * (r.FillPrice - r.ArrivalPrice()), true
+}
+
+// SpreadCapture 返回价差捕获率 1-有效价差/报价价差：在对手价成交为0，在中间价成交为1
+func (r ExecutionRecord) SpreadCapture() (float64, bool) {
+	effective, ok := r.EffectiveSpread()
+	quoted := r.ArrivalAsk - r.ArrivalBid
+	if !ok || quoted <= 0 {
+		return 0, false
+	}
+	return 1 - effective/quoted, true
+}
+
+// Latency 返回从提交到成交的时间
+func (r ExecutionRecord) Latency() (time.Duration, bool) {
+	if r.FilledAt == nil {
+		return 0, false
+	}
+	return r.FilledAt.Sub(r.SubmittedAt), true
+}
+
+// sign 买入为1，卖出为-1（内部方法）
+func (r ExecutionRecord) sign() float64 {
+	if r.Side == OrderSideSell {
+		return -1
+	}
+	return 1
+}
+
+// LatencyDistribution 表示成交延迟的分布
+type LatencyDistribution struct {
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// ExecutionQualityStats 表示一组订单的执行质量统计
+type ExecutionQualityStats struct {
+	Orders             int                 `json:"orders"`
+	Filled             int                 `json:"filled"`
+	AvgSlippageBps     float64             `json:"avg_slippage_bps"`
+	MedianSlippageBps  float64             `json:"median_slippage_bps"`
+	AvgEffectiveSpread float64             `json:"avg_effective_spread"`
+	AvgSpreadCapture   float64             `json:"avg_spread_capture"`
+	Latency            LatencyDistribution `json:"latency"`
+}
+
+// ExecutionQualityReport 表示按券商和策略分组的执行质量报告
+type ExecutionQualityReport struct {
+	From       time.Time                        `json:"from"`
+	To         time.Time                        `json:"to"`
+	Overall    ExecutionQualityStats            `json:"overall"`
+	ByBroker   map[string]ExecutionQualityStats `json:"by_broker"`
+	ByStrategy map[string]ExecutionQualityStats `json:"by_strategy"`
+}
+
+// executionQuality 保存订单的执行质量记录（内部类型）
+type executionQuality struct {
+	mu      sync.Mutex
+	records map[string]*ExecutionRecord
+}
+
+// arrivalQuote 获取下单时的到达报价，失败时返回空报价（内部方法）
+func (e *BaseTradingEngine) arrivalQuote(ctx context.Context, symbol string) datasource.Quote {
+	if e.dataManager == nil {
+		return datasource.Quote{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, arrivalQuoteTimeout)
+	defer cancel()
+	quote, err := e.dataManager.GetRealTimeQuote(ctx, symbol)
+	if err != nil || quote == nil {
+		return datasource.Quote{}
+	}
+	return *quote
+}
+
+// recordArrival 记录订单提交时的报价（内部方法）
+func (e *BaseTradingEngine) recordArrival(order Order, broker string, quote datasource.Quote) {
+	e.execQuality.mu.Lock()
+	defer e.execQuality.mu.Unlock()
+
+	if e.execQuality.records == nil {
+		e.execQuality.records = make(map[string]*ExecutionRecord)
+	}
+	e.execQuality.records[order.ID] = &ExecutionRecord{
+		OrderID:     order.ID,
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		Type:        order.Type,
+		Quantity:    order.Quantity,
+		Broker:      broker,
+		Strategy:    order.Strategy,
+		SubmittedAt: order.CreatedAt,
+		ArrivalBid:  quote.BidPrice,
+		ArrivalAsk:  quote.AskPrice,
+		ArrivalLast: quote.LastPrice,
+	}
+}
+
+// recordFill 记录订单的成交结果（内部方法）
+func (e *BaseTradingEngine) recordFill(order Order) {
+	e.execQuality.mu.Lock()
+	defer e.execQuality.mu.Unlock()
+
+	record, ok := e.execQuality.records[order.ID]
+	if !ok {
+		return
+	}
+	record.FilledQty = order.FilledQty
+	record.FillPrice = order.AvgFillPrice
+	if order.FilledAt != nil {
+		filledAt := *order.FilledAt
+		record.FilledAt = &filledAt
+	}
+}
+
+// GetExecutionRecords 获取时间范围内提交的订单的执行质量记录
+func (e *BaseTradingEngine) GetExecutionRecords(ctx context.Context, startTime, endTime time.Time) ([]ExecutionRecord, error) {
+	e.execQuality.mu.Lock()
+	defer e.execQuality.mu.Unlock()
+
+	var records []ExecutionRecord
+	for _, record := range e.execQuality.records {
+		if (startTime.IsZero() || !record.SubmittedAt.Before(startTime)) &&
+			(endTime.IsZero() || !record.SubmittedAt.After(endTime)) {
+			records = append(records, *record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].SubmittedAt.Before(records[j].SubmittedAt)
+	})
+
+	return records, nil
+}
+
+// GetExecutionQuality 计算时间范围内的滑点、有效价差捕获率和成交延迟分布，按券商和策略分组
+func (e *BaseTradingEngine) GetExecutionQuality(ctx context.Context, startTime, endTime time.Time) (*ExecutionQualityReport, error) {
+	records, err := e.GetExecutionRecords(ctx, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	byBroker := make(map[string][]ExecutionRecord)
+	byStrategy := make(map[string][]ExecutionRecord)
+	for _, record := range records {
+		byBroker[record.Broker] = append(byBroker[record.Broker], record)
+		strategy := record.Strategy
+		if strategy == "" {
+			strategy = unattributedStrategy
+		}
+		byStrategy[strategy] = append(byStrategy[strategy], record)
+	}
+
+	report := &ExecutionQualityReport{
+		From:       startTime,
+		To:         endTime,
+		Overall:    computeExecutionQuality(records),
+		ByBroker:   make(map[string]ExecutionQualityStats, len(byBroker)),
+		ByStrategy: make(map[string]ExecutionQualityStats, len(byStrategy)),
+	}
+	for broker, group := range byBroker {
+		report.ByBroker[broker] = computeExecutionQuality(group)
+	}
+	for strategy, group := range byStrategy {
+		report.ByStrategy[strategy] = computeExecutionQuality(group)
+	}
+
+	return report, nil
+}
+
+// computeExecutionQuality 计算一组记录的执行质量统计（内部函数）
+func computeExecutionQuality(records []ExecutionRecord) ExecutionQualityStats {
+	stats := ExecutionQualityStats{Orders: len(records)}
+
+	var slippages []float64
+	var spreadSum, captureSum float64
+	var spreadCount, captureCount int
+	var latencies []time.Duration
+
+	for _, record := range records {
+		if record.FilledQty == 0 {
+			continue
+		}
+		stats.Filled++
+
+		if slippage, ok := record.SlippageBps(); ok {
+			slippages = append(slippages, slippage)
+		}
+		if spread, ok := record.EffectiveSpread(); ok {
+			spreadSum += spread
+			spreadCount++
+		}
+		if capture, ok := record.SpreadCapture(); ok {
+			captureSum += capture
+			captureCount++
+		}
+		if latency, ok := record.Latency(); ok {
+			latencies = append(latencies, latency)
+		}
+	}
+
+	if len(slippages) > 0 {
+		sort.Float64s(slippages)
+		var sum float64
+		for _, s := range slippages {
+			sum += s
+		}
+		stats.AvgSlippageBps = sum / float64(len(slippages))
+		mid := len(slippages) / 2
+		if len(slippages)%2 == 0 {
+			stats.MedianSlippageBps = (slippages[mid-1] + slippages[mid]) / 2
+		} else {
+			stats.MedianSlippageBps = slippages[mid]
+		}
+	}
+	if spreadCount > 0 {
+		stats.AvgEffectiveSpread = spreadSum / float64(spreadCount)
+	}
+	if captureCount > 0 {
+		stats.AvgSpreadCapture = captureSum / float64(captureCount)
+	}
+	stats.Latency = latencyDistribution(latencies)
+
+	return stats
+}
+
+// latencyDistribution 计算延迟分布，分位数使用最近秩法（内部函数）
+func latencyDistribution(latencies []time.Duration) LatencyDistribution {
+	dist := LatencyDistribution{Count: len(latencies)}
+	if len(latencies) == 0 {
+		return dist
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var sum time.Duration
+	for _, l := range latencies {
+		sum += l
+	}
+
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p*float64(len(latencies)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		return latencies[rank]
+	}
+
+	dist.Mean = sum / time.Duration(len(latencies))
+	dist.P50 = percentile(0.50)
+	dist.P90 = percentile(0.90)
+	dist.P99 = percentile(0.99)
+	dist.Max = latencies[len(latencies)-1]
+	return dist
+}
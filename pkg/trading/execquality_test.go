@@ -0,0 +1,75 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+func TestExecutionQuality(t *testing.T) {
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{})
+	base := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	quote := datasource.Quote{BidPrice: 99.9, AskPrice: 100.1, LastPrice: 100}
+
+	fills := []struct {
+		order   Order
+		broker  string
+		price   float64
+		latency time.Duration
+	}{
+		// 在对手价买入：滑点10bps，价差捕获0
+		{Order{ID: "o1", Symbol: "AAPL", Side: OrderSideBuy, Quantity: 10, Strategy: "momentum"}, "alpaca", 100.1, 100 * time.Millisecond},
+		// 在中间价卖出：滑点0，价差捕获1
+		{Order{ID: "o2", Symbol: "AAPL", Side: OrderSideSell, Quantity: 10, Strategy: "momentum"}, "alpaca", 100, 300 * time.Millisecond},
+		// 卖出价高于中间价为负滑点
+		{Order{ID: "o3", Symbol: "AAPL", Side: OrderSideSell, Quantity: 10}, "fix", 100.05, 200 * time.Millisecond},
+	}
+	for i, f := range fills {
+		order := f.order
+		order.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		engine.recordArrival(order, f.broker, quote)
+
+		filledAt := order.CreatedAt.Add(f.latency)
+		order.FilledQty = order.Quantity
+		order.AvgFillPrice = f.price
+		order.FilledAt = &filledAt
+		engine.recordFill(order)
+	}
+	// 未成交的订单只计入订单数
+	engine.recordArrival(Order{ID: "o4", Symbol: "AAPL", Side: OrderSideBuy, CreatedAt: base.Add(time.Hour)}, "fix", quote)
+
+	report, err := engine.GetExecutionQuality(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("获取执行质量报告失败: %v", err)
+	}
+
+	overall := report.Overall
+	if overall.Orders != 4 || overall.Filled != 3 {
+		t.Errorf("订单统计不正确: %+v", overall)
+	}
+	if math.Abs(overall.MedianSlippageBps-0) > 1e-6 || math.Abs(overall.AvgSlippageBps-5.0/3) > 1e-6 {
+		t.Errorf("滑点 = %v/%v, 期望 平均5/3 中位数0", overall.AvgSlippageBps, overall.MedianSlippageBps)
+	}
+	if math.Abs(overall.AvgSpreadCapture-(0+1+1.5)/3) > 1e-6 {
+		t.Errorf("价差捕获率 = %v", overall.AvgSpreadCapture)
+	}
+	if overall.Latency.Count != 3 || overall.Latency.P50 != 200*time.Millisecond || overall.Latency.Max != 300*time.Millisecond {
+		t.Errorf("延迟分布不正确: %+v", overall.Latency)
+	}
+
+	if stats := report.ByBroker["alpaca"]; stats.Orders != 2 || math.Abs(stats.AvgSlippageBps-5) > 1e-6 {
+		t.Errorf("alpaca 统计不正确: %+v", stats)
+	}
+	if stats := report.ByStrategy[unattributedStrategy]; stats.Orders != 2 || stats.Filled != 1 {
+		t.Errorf("未归属策略统计不正确: %+v", stats)
+	}
+
+	// 按提交时间筛选
+	report, _ = engine.GetExecutionQuality(context.Background(), base.Add(30*time.Second), base.Add(3*time.Minute))
+	if report.Overall.Orders != 2 {
+		t.Errorf("时间筛选后订单数 = %d, 期望 2", report.Overall.Orders)
+	}
+}
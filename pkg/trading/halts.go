@@ -0,0 +1,220 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// 停牌原因
+const (
+	HaltReasonHalted    = "halted"     // 数据源报告停牌
+	HaltReasonLimitUp   = "limit_up"   // 价格触及LULD上限
+	HaltReasonLimitDown = "limit_down" // 价格触及LULD下限
+	HaltReasonNoQuotes  = "no_quotes"  // 交易时段内长时间没有报价
+)
+
+// HaltConfig 表示停牌检测的配置
+// 检测到停牌的股票不能下单，监控列表也不触发，直到报价恢复正常
+type HaltConfig struct {
+	Enabled         bool `json:"enabled" yaml:"enabled"`
+	IntervalSeconds int  `json:"interval_seconds" yaml:"interval_seconds"` // 检查间隔，默认5秒
+	StaleSeconds    int  `json:"stale_seconds" yaml:"stale_seconds"`       // 交易时段内报价超过该时间未更新视为停牌，为0时不检查，需要交易日历
+}
+
+// Validate 检查配置是否有效
+func (c HaltConfig) Validate() error {
+	if c.IntervalSeconds < 0 || c.StaleSeconds < 0 {
+		return fmt.Errorf("values must not be negative")
+	}
+	return nil
+}
+
+// Interval 返回检查间隔
+func (c HaltConfig) Interval() time.Duration {
+	if c.IntervalSeconds <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
+
+// SymbolHalt 表示检测到停牌的股票，作为 symbol_halted 和 symbol_resumed 事件的内容
+type SymbolHalt struct {
+	Symbol    string     `json:"symbol"`
+	Reason    string     `json:"reason"`
+	Price     float64    `json:"price,omitempty"` // 检测到停牌时的最新价
+	HaltedAt  time.Time  `json:"halted_at"`
+	ResumedAt *time.Time `json:"resumed_at,omitempty"`
+}
+
+// SetHaltDetection 设置停牌检测
+func (e *BaseTradingEngine) SetHaltDetection(config HaltConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.haltConfig = config
+}
+
+// GetHaltedSymbols 返回按股票代码排序的停牌股票
+func (e *BaseTradingEngine) GetHaltedSymbols() []SymbolHalt {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	halts := make([]SymbolHalt, 0, len(e.halts))
+	for _, halt := range e.halts {
+		halts = append(halts, halt)
+	}
+	sort.Slice(halts, func(i, j int) bool {
+		return halts[i].Symbol < halts[j].Symbol
+	})
+	return halts
+}
+
+// CheckHalted 检查股票是否停牌，停牌时返回包含原因的 ErrSymbolHalted
+func (e *BaseTradingEngine) CheckHalted(symbol string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.halted(symbol)
+}
+
+// halted 检查股票是否停牌（内部方法，调用方持锁）
+func (e *BaseTradingEngine) halted(symbol string) error {
+	halt, exists := e.halts[symbol]
+	if !exists {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrSymbolHalted, symbol, halt.Reason)
+}
+
+// CheckHalts 获取持仓、未完成订单和给定股票的最新报价并检测停牌，返回本次新检测到的停牌
+// 已停牌的股票报价恢复正常后解除停牌；在 risk 主题发布 symbol_halted 和 symbol_resumed 事件
+func (e *BaseTradingEngine) CheckHalts(ctx context.Context, symbols []string) ([]SymbolHalt, error) {
+	if e.dataManager == nil {
+		return nil, nil
+	}
+
+	e.mu.RLock()
+	if !e.haltConfig.Enabled {
+		e.mu.RUnlock()
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	var watched []string
+	add := func(symbol string) {
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			watched = append(watched, symbol)
+		}
+	}
+	for _, pos := range e.positions {
+		if pos.Quantity != 0 {
+			add(pos.Symbol)
+		}
+	}
+	for _, order := range e.orders {
+		if isOpenOrder(order) {
+			add(order.Symbol)
+		}
+	}
+	for symbol := range e.halts {
+		add(symbol)
+	}
+	e.mu.RUnlock()
+	for _, symbol := range symbols {
+		add(symbol)
+	}
+	if len(watched) == 0 {
+		return nil, nil
+	}
+
+	// 部分股票获取报价失败时按没有报价处理，全部失败时可能是数据源故障，不做判断
+	quotes, err := e.dataManager.GetRealTimeQuotes(ctx, watched)
+	if err != nil && len(quotes) == 0 {
+		return nil, err
+	}
+	return e.ApplyHaltQuotes(watched, quotes, e.now()), err
+}
+
+// ApplyHaltQuotes 按报价检测停牌：数据源报告停牌、价格触及LULD区间，或交易时段内报价过期或缺失
+// 可由报价推送直接调用；symbols 中没有报价的股票按缺失处理，返回本次新检测到的停牌
+func (e *BaseTradingEngine) ApplyHaltQuotes(symbols []string, quotes map[string]*datasource.Quote, now time.Time) []SymbolHalt {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.halts == nil {
+		e.halts = make(map[string]SymbolHalt)
+	}
+	// 报价过期只在常规交易时段内检查，盘前盘后和休市时没有报价是正常的
+	checkStale := e.haltConfig.StaleSeconds > 0 && e.calendar != nil && e.calendar.IsOpen(now)
+	stale := time.Duration(e.haltConfig.StaleSeconds) * time.Second
+
+	var detected []SymbolHalt
+	for _, symbol := range symbols {
+		quote := quotes[symbol]
+		reason := haltReason(quote, checkStale, stale, now)
+		halt, halted := e.halts[symbol]
+		switch {
+		case reason != "" && !halted:
+			halt = SymbolHalt{Symbol: symbol, Reason: reason, Price: markPrice(quote), HaltedAt: now}
+			e.halts[symbol] = halt
+			e.publish(events.TopicRisk, EventSymbolHalted, halt)
+			detected = append(detected, halt)
+		case reason == "" && halted && quote != nil:
+			delete(e.halts, symbol)
+			halt.ResumedAt = &now
+			e.publish(events.TopicRisk, EventSymbolResumed, halt)
+		}
+	}
+	return detected
+}
+
+// haltReason 返回报价表明的停牌原因，正常时返回空字符串（内部函数）
+func haltReason(quote *datasource.Quote, checkStale bool, stale time.Duration, now time.Time) string {
+	if quote == nil {
+		if checkStale {
+			return HaltReasonNoQuotes
+		}
+		return ""
+	}
+	price := markPrice(quote)
+	switch {
+	case quote.Halted:
+		return HaltReasonHalted
+	case quote.LimitUp > 0 && price >= quote.LimitUp:
+		return HaltReasonLimitUp
+	case quote.LimitDown > 0 && price > 0 && price <= quote.LimitDown:
+		return HaltReasonLimitDown
+	case checkStale && (quote.Timestamp.IsZero() || now.Sub(quote.Timestamp) > stale):
+		return HaltReasonNoQuotes
+	}
+	return ""
+}
+
+// RunHaltMonitor 按配置的间隔检测停牌，直到上下文取消；symbols 返回需要额外检测的股票，如监控列表中的股票
+func (e *BaseTradingEngine) RunHaltMonitor(ctx context.Context, symbols func() []string) error {
+	e.mu.RLock()
+	interval := e.haltConfig.Interval()
+	e.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var extra []string
+		if symbols != nil {
+			extra = symbols()
+		}
+		if _, err := e.CheckHalts(ctx, extra); err != nil {
+			e.log().Error("检查停牌失败: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/schedule"
+)
+
+func TestHaltDetection(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	source := &quoteSource{quotes: map[string]*datasource.Quote{
+		"AAPL": {Symbol: "AAPL", LastPrice: 100, Timestamp: now},
+		"MSFT": {Symbol: "MSFT", LastPrice: 50, Timestamp: now},
+	}}
+	manager := datasource.NewManager()
+	manager.AddDataSource(source)
+
+	engine := NewBaseTradingEngine(manager, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetHaltDetection(HaltConfig{Enabled: true})
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicRisk)
+	defer sub.Close()
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	watchlist := NewWatchlist(engine, manager)
+	if err := watchlist.AddItem(WatchlistItem{Symbol: "MSFT", Quantity: 10, IsBuyList: true, TargetPrice: 60}); err != nil {
+		t.Fatalf("添加监控项失败: %v", err)
+	}
+
+	if halts, err := engine.CheckHalts(ctx, []string{"MSFT"}); err != nil || len(halts) != 0 {
+		t.Fatalf("报价正常时不应停牌: %+v %v", halts, err)
+	}
+
+	// 持仓股票被数据源报告停牌，监控列表的股票触及LULD上限
+	source.quotes["AAPL"] = &datasource.Quote{Symbol: "AAPL", LastPrice: 100, Timestamp: now, Halted: true}
+	source.quotes["MSFT"] = &datasource.Quote{Symbol: "MSFT", LastPrice: 55, Timestamp: now, LimitUp: 55, LimitDown: 45}
+	halts, err := engine.CheckHalts(ctx, []string{"MSFT"})
+	if err != nil || len(halts) != 2 {
+		t.Fatalf("应检测到两个停牌: %+v %v", halts, err)
+	}
+	reasons := map[string]string{}
+	for _, halt := range engine.GetHaltedSymbols() {
+		reasons[halt.Symbol] = halt.Reason
+	}
+	if reasons["AAPL"] != HaltReasonHalted || reasons["MSFT"] != HaltReasonLimitUp {
+		t.Errorf("停牌原因不正确: %+v", reasons)
+	}
+	if evt := <-sub.C; evt.Type != EventSymbolHalted {
+		t.Errorf("应发布停牌事件: %+v", evt)
+	}
+	<-sub.C
+
+	// 停牌期间所有订单都被拒绝，监控列表不触发，再次检查不重复发布
+	_, err = engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideSell)
+	if GetRejectCode(err) != RejectCodeHalted || !errors.Is(err, ErrSymbolHalted) {
+		t.Errorf("停牌期间下单应被拒绝: %v", err)
+	}
+	if triggered, _ := watchlist.ScanWatchlist(ctx); len(triggered) != 0 {
+		t.Errorf("停牌期间监控列表不应触发: %+v", triggered)
+	}
+	if halts, _ := engine.CheckHalts(ctx, []string{"MSFT"}); len(halts) != 0 {
+		t.Errorf("已停牌的股票不应重复检测: %+v", halts)
+	}
+
+	// 报价恢复正常后解除停牌
+	source.quotes["AAPL"] = &datasource.Quote{Symbol: "AAPL", LastPrice: 101, Timestamp: now}
+	source.quotes["MSFT"] = &datasource.Quote{Symbol: "MSFT", LastPrice: 52, Timestamp: now, LimitUp: 57, LimitDown: 47}
+	if _, err := engine.CheckHalts(ctx, nil); err != nil {
+		t.Fatalf("检测停牌失败: %v", err)
+	}
+	if halted := engine.GetHaltedSymbols(); len(halted) != 0 {
+		t.Errorf("报价恢复后应解除停牌: %+v", halted)
+	}
+	if evt := <-sub.C; evt.Type != EventSymbolResumed {
+		t.Errorf("应发布恢复交易事件: %+v", evt)
+	}
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideSell); err != nil {
+		t.Errorf("恢复交易后应允许下单: %v", err)
+	}
+	if triggered, _ := watchlist.ScanWatchlist(ctx); len(triggered) != 1 {
+		t.Errorf("恢复交易后监控列表应触发: %+v", triggered)
+	}
+}
+
+func TestHaltStaleQuotes(t *testing.T) {
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{})
+	engine.SetHaltDetection(HaltConfig{Enabled: true, StaleSeconds: 60})
+	calendar, err := schedule.NewCalendar("", nil, nil)
+	if err != nil {
+		t.Fatalf("创建交易日历失败: %v", err)
+	}
+	session, ok := calendar.NextSession(time.Now())
+	if !ok {
+		t.Fatal("找不到交易日")
+	}
+	open := session.Open.Add(time.Hour)
+	quotes := map[string]*datasource.Quote{"AAPL": {Symbol: "AAPL", LastPrice: 100, Timestamp: open.Add(-5 * time.Minute)}}
+
+	// 未设置交易日历时不检查报价是否过期
+	if halts := engine.ApplyHaltQuotes([]string{"AAPL", "MSFT"}, quotes, open); len(halts) != 0 {
+		t.Errorf("没有交易日历时不应检查过期报价: %+v", halts)
+	}
+
+	// 收盘后没有报价是正常的
+	engine.SetCalendar(calendar)
+	if halts := engine.ApplyHaltQuotes([]string{"AAPL", "MSFT"}, quotes, session.AfterHours.Add(time.Minute)); len(halts) != 0 {
+		t.Errorf("休市时不应检查过期报价: %+v", halts)
+	}
+
+	halts := engine.ApplyHaltQuotes([]string{"AAPL", "MSFT"}, quotes, open)
+	if len(halts) != 2 || halts[0].Reason != HaltReasonNoQuotes || halts[1].Reason != HaltReasonNoQuotes {
+		t.Fatalf("交易时段内过期或缺失的报价应视为停牌: %+v", halts)
+	}
+
+	// 缺失报价的股票在收到新报价前保持停牌
+	quotes["AAPL"].Timestamp = open
+	engine.ApplyHaltQuotes([]string{"AAPL", "MSFT"}, quotes, open)
+	if err := engine.CheckHalted("AAPL"); err != nil {
+		t.Errorf("收到新报价后应解除停牌: %v", err)
+	}
+	if err := engine.CheckHalted("MSFT"); !errors.Is(err, ErrSymbolHalted) {
+		t.Errorf("没有报价的股票应保持停牌: %v", err)
+	}
+}
+
+func TestHaltConfigValidate(t *testing.T) {
+	if err := (HaltConfig{Enabled: true, StaleSeconds: -1}).Validate(); err == nil {
+		t.Error("负数应返回错误")
+	}
+	if interval := (HaltConfig{}).Interval(); interval != 5*time.Second {
+		t.Errorf("默认检查间隔 = %v, 期望 5s", interval)
+	}
+}
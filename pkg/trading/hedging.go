@@ -0,0 +1,233 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// positionMode 返回账户的持仓模式，未配置时为净额模式（内部方法）
+func (e *BaseTradingEngine) positionMode() PositionMode {
+	if e.brokerConfig.PositionMode == "" {
+		return PositionModeNetting
+	}
+	return e.brokerConfig.PositionMode
+}
+
+// hedging 检查账户是否为对冲模式（内部方法）
+func (e *BaseTradingEngine) hedging() bool {
+	return e.positionMode() == PositionModeHedging
+}
+
+// hedgeKey 返回对冲模式下持仓的键，没有策略归属的持仓以股票代码为键（内部函数）
+func hedgeKey(symbol, strategy string) string {
+	if strategy == "" {
+		return symbol
+	}
+	return symbol + "/" + strategy
+}
+
+// direction 返回持仓数量的方向，多头为1，空头为-1（内部函数）
+func direction(quantity int64) int64 {
+	if quantity < 0 {
+		return -1
+	}
+	return 1
+}
+
+// updateHedgedPosition 更新对冲模式下的持仓（内部方法，调用方持锁）
+// 每个策略在同一股票上有独立的持仓：买入先回补该策略的空头，卖出先平掉该策略的多头，超出的部分反向开仓。
+// 每次减仓都会生成一条交易记录，交易记录的盈亏之和等于账户的已实现盈亏
+func (e *BaseTradingEngine) updateHedgedPosition(order Order) {
+	key := hedgeKey(order.Symbol, order.Strategy)
+	pos, exists := e.positions[key]
+	now := e.now()
+
+	signed := order.FilledQty
+	if order.Side == OrderSideSell {
+		signed = -signed
+	}
+
+	// 与持仓方向相反的成交先平仓
+	if exists && pos.Quantity*signed < 0 {
+		dir := direction(pos.Quantity)
+		closed := min(order.FilledQty, pos.Quantity*dir) * dir
+		realizedPnL := float64(closed) * (order.AvgFillPrice - pos.EntryPrice)
+		e.account.RealizedPnL += realizedPnL
+
+		closedAt := *order.FilledAt
+		exit := order
+		e.appendTrade(Trade{
+			ID:                 e.tradeID(order.ID),
+			Symbol:             order.Symbol,
+			ExitOrder:          &exit,
+			EntryPrice:         pos.EntryPrice,
+			ExitPrice:          order.AvgFillPrice,
+			Quantity:           closed,
+			RealizedPnL:        realizedPnL,
+			RealizedPnLPercent: (order.AvgFillPrice/pos.EntryPrice - 1) * 100 * float64(dir),
+			Commission:         order.Commission,
+			OpenedAt:           pos.OpenedAt,
+			ClosedAt:           &closedAt,
+			HoldTime:           closedAt.Sub(pos.OpenedAt).Hours(),
+			Strategy:           pos.Strategy,
+			Metadata:           mergeMetadata(pos.Metadata, order.Metadata),
+		})
+
+		pos.Quantity -= closed
+		pos.Cost = float64(pos.Quantity) * pos.EntryPrice
+		pos.CurrentPrice = order.AvgFillPrice
+		pos.UpdatedAt = now
+		signed += closed
+
+		if pos.Quantity == 0 {
+			delete(e.positions, key)
+			pos.MarketValue = 0
+			pos.UnrealizedPnL = 0
+			e.publish(events.TopicPositions, EventPositionClosed, pos)
+			exists = false
+		}
+	}
+
+	// 剩余的成交开仓或加仓
+	if signed != 0 {
+		if !exists {
+			pos = Position{
+				Symbol:   order.Symbol,
+				Strategy: order.Strategy,
+				OpenedAt: *order.FilledAt,
+				Metadata: cloneMetadata(order.Metadata),
+			}
+		}
+		pos.Quantity += signed
+		pos.Cost += float64(signed) * order.AvgFillPrice
+		pos.EntryPrice = pos.Cost / float64(pos.Quantity)
+		pos.CurrentPrice = order.AvgFillPrice
+		pos.UpdatedAt = now
+
+		// 空头的止损在开仓价之上，止盈在开仓价之下
+		dir := float64(direction(pos.Quantity))
+		if e.limits.StopLossPercent > 0 {
+			pos.StopLoss = pos.EntryPrice * (1 - dir*e.limits.StopLossPercent/100)
+		}
+		if e.limits.TakeProfitPercent > 0 {
+			pos.TakeProfit = pos.EntryPrice * (1 + dir*e.limits.TakeProfitPercent/100)
+		}
+		applyBracket(&pos, order)
+	}
+
+	if pos.Quantity != 0 {
+		pos.MarketValue = float64(pos.Quantity) * pos.CurrentPrice
+		pos.UnrealizedPnL = pos.MarketValue - pos.Cost
+		pos.PnLPercent = (pos.CurrentPrice/pos.EntryPrice - 1) * 100 * float64(direction(pos.Quantity))
+		e.positions[key] = pos
+		e.publish(events.TopicPositions, EventPositionUpdated, pos)
+	}
+}
+
+// hedgedPositions 返回对冲模式下某只股票按策略排序的所有持仓（内部方法，调用方持锁）
+func (e *BaseTradingEngine) hedgedPositions(symbol string) []Position {
+	var legs []Position
+	for _, pos := range e.positions {
+		if pos.Symbol == symbol {
+			legs = append(legs, pos)
+		}
+	}
+	sort.Slice(legs, func(i, j int) bool {
+		return legs[i].Strategy < legs[j].Strategy
+	})
+	return legs
+}
+
+// netPosition 汇总对冲模式下某只股票各策略的持仓（内部方法，调用方持锁）
+func (e *BaseTradingEngine) netPosition(symbol string) (*Position, error) {
+	legs := e.hedgedPositions(symbol)
+	if len(legs) == 0 {
+		return nil, fmt.Errorf("no position found for symbol %s", symbol)
+	}
+	if len(legs) == 1 {
+		return &legs[0], nil
+	}
+
+	net := Position{Symbol: symbol, OpenedAt: legs[0].OpenedAt}
+	for _, leg := range legs {
+		net.Quantity += leg.Quantity
+		net.Cost += leg.Cost
+		net.MarketValue += leg.MarketValue
+		net.UnrealizedPnL += leg.UnrealizedPnL
+		net.CurrentPrice = leg.CurrentPrice
+		if leg.OpenedAt.Before(net.OpenedAt) {
+			net.OpenedAt = leg.OpenedAt
+		}
+		if leg.UpdatedAt.After(net.UpdatedAt) {
+			net.UpdatedAt = leg.UpdatedAt
+		}
+	}
+	if net.Quantity != 0 {
+		net.EntryPrice = net.Cost / float64(net.Quantity)
+	}
+	return &net, nil
+}
+
+// GetStrategyPosition 获取策略在某只股票上的持仓，净额模式下返回该股票的持仓
+func (e *BaseTradingEngine) GetStrategyPosition(ctx context.Context, symbol, strategy string) (*Position, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	key := symbol
+	if e.hedging() {
+		key = hedgeKey(symbol, strategy)
+	}
+	pos, exists := e.positions[key]
+	if !exists {
+		return nil, fmt.Errorf("no position found for symbol %s", symbol)
+	}
+	return &pos, nil
+}
+
+// closeHedgedPositions 以市价平掉对冲模式下某只股票的持仓（内部方法）
+// 数量为0时平掉所有策略的持仓；指定数量时该股票只能有一个策略持仓
+func (e *BaseTradingEngine) closeHedgedPositions(ctx context.Context, symbol string, quantity int64) (*Order, error) {
+	e.mu.RLock()
+	legs := e.hedgedPositions(symbol)
+	e.mu.RUnlock()
+
+	if len(legs) == 0 {
+		return nil, fmt.Errorf("no position found for symbol %s", symbol)
+	}
+	if quantity > 0 && len(legs) > 1 {
+		return nil, fmt.Errorf("symbol %s has positions for %d strategies, close them by strategy", symbol, len(legs))
+	}
+
+	var (
+		last *Order
+		errs []error
+	)
+	for _, leg := range legs {
+		size := leg.Quantity * direction(leg.Quantity)
+		if quantity > 0 && quantity < size {
+			size = quantity
+		}
+		side := OrderSideSell
+		if leg.Quantity < 0 {
+			side = OrderSideBuy
+		}
+
+		order, err := e.PlaceOrder(ctx, OrderRequest{
+			Symbol:   symbol,
+			Quantity: size,
+			Type:     OrderTypeMarket,
+			Side:     side,
+			Strategy: leg.Strategy,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", hedgeKey(symbol, leg.Strategy), err))
+			continue
+		}
+		last = order
+	}
+	return last, errors.Join(errs...)
+}
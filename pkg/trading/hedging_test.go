@@ -0,0 +1,67 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHedgingPositions(t *testing.T) {
+	ctx := context.Background()
+	broker := &fixedPriceBroker{price: 100}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{PositionMode: PositionModeHedging}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	place := func(strategy string, side OrderSide, quantity int64) {
+		t.Helper()
+		if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: quantity, Type: OrderTypeMarket, Side: side, Strategy: strategy}); err != nil {
+			t.Fatalf("%s %s 下单失败: %v", strategy, side, err)
+		}
+	}
+
+	// 两个策略在同一股票上分别持有多头和空头
+	place("momentum", OrderSideBuy, 10)
+	place("reversion", OrderSideSell, 5)
+
+	positions, _ := engine.GetPositions(ctx)
+	if len(positions) != 2 {
+		t.Fatalf("对冲模式下应有2个持仓: %+v", positions)
+	}
+	short, err := engine.GetStrategyPosition(ctx, "AAPL", "reversion")
+	if err != nil || short.Quantity != -5 {
+		t.Fatalf("reversion 应持有5股空头: %+v %v", short, err)
+	}
+	if net, _ := engine.GetPosition(ctx, "AAPL"); net.Quantity != 5 {
+		t.Errorf("净持仓 = %d, 期望 5", net.Quantity)
+	}
+
+	// 价格下跌：多头亏损，空头盈利，盈亏分别归属到各自的策略
+	broker.price = 90
+	place("momentum", OrderSideSell, 10)
+	place("reversion", OrderSideBuy, 5)
+
+	trades, _ := engine.GetTrades(ctx, "AAPL", time.Time{}, time.Now())
+	pnl := make(map[string]float64)
+	for _, trade := range trades {
+		pnl[trade.Strategy] += trade.RealizedPnL
+	}
+	if math.Abs(pnl["momentum"]+100) > 1e-9 || math.Abs(pnl["reversion"]-50) > 1e-9 {
+		t.Errorf("策略盈亏 = %v, 期望 momentum=-100 reversion=50", pnl)
+	}
+	account, _ := engine.GetAccount(ctx)
+	if math.Abs(account.RealizedPnL+50) > 1e-9 || account.PositionMode != PositionModeHedging {
+		t.Errorf("账户已实现盈亏 = %.2f 模式 = %s, 期望 -50 hedging", account.RealizedPnL, account.PositionMode)
+	}
+	if positions, _ := engine.GetPositions(ctx); len(positions) != 0 {
+		t.Errorf("平仓后不应有持仓: %+v", positions)
+	}
+
+	// 卖出超过多头数量时反向开空
+	place("momentum", OrderSideBuy, 3)
+	place("momentum", OrderSideSell, 8)
+	if pos, err := engine.GetStrategyPosition(ctx, "AAPL", "momentum"); err != nil || pos.Quantity != -5 || pos.EntryPrice != 90 {
+		t.Errorf("应反向持有5股空头: %+v %v", pos, err)
+	}
+}
@@ -0,0 +1,127 @@
+package trading
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IDGenerator 定义了订单、交易和监控项ID生成器的接口
+// 生成的ID必须唯一，同一生成器生成的ID应按生成顺序单调递增
+type IDGenerator interface {
+	// NewID 生成带前缀的ID，前缀如 order、exec、watch
+	NewID(prefix string) string
+}
+
+// IDGeneratorFunc 将函数适配为ID生成器
+type IDGeneratorFunc func(prefix string) string
+
+// NewID 生成ID
+func (f IDGeneratorFunc) NewID(prefix string) string {
+	return f(prefix)
+}
+
+// UUIDv7Generator 生成 <前缀>-<UUIDv7> 格式的ID
+// 时间戳为毫秒精度，同一毫秒内使用12位计数器保证单调递增，计数器用尽或时钟回拨时
+// 沿用上一个时间戳继续递增，因此同一生成器生成的ID按字典序即为生成顺序
+type UUIDv7Generator struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	lastMs  int64
+	counter uint16
+}
+
+// NewUUIDv7Generator 创建UUIDv7生成器
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{now: time.Now}
+}
+
+// DefaultIDGenerator 是未设置ID生成器时使用的默认生成器
+var DefaultIDGenerator IDGenerator = NewUUIDv7Generator()
+
+// NewID 生成带前缀的UUIDv7，前缀为空时只返回UUID
+func (g *UUIDv7Generator) NewID(prefix string) string {
+	id := g.next()
+	if prefix == "" {
+		return id
+	}
+	return prefix + "-" + id
+}
+
+// next 生成下一个UUIDv7（内部方法）
+func (g *UUIDv7Generator) next() string {
+	var b [16]byte
+	if _, err := rand.Read(b[8:]); err != nil {
+		panic("trading: failed to read random bytes: " + err.Error())
+	}
+
+	g.mu.Lock()
+	ms := g.now().UnixMilli()
+	if ms > g.lastMs {
+		g.lastMs = ms
+		// 计数器从随机值的低位开始，保留足够的递增空间
+		g.counter = binary.BigEndian.Uint16(b[14:]) & 0x01ff
+	} else {
+		g.counter++
+		if g.counter > 0x0fff {
+			g.lastMs++
+			g.counter = 0
+		}
+	}
+	ms, counter := g.lastMs, g.counter
+	g.mu.Unlock()
+
+	// 48位毫秒时间戳 | 4位版本 | 12位计数器 | 2位变体 | 62位随机数
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = 0x70 | byte(counter>>8)
+	b[7] = byte(counter)
+	b[8] = 0x80 | (b[8] & 0x3f)
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf[:])
+}
+
+// SetIDGenerator 设置订单和成交回报的ID生成器，为空时使用默认生成器
+func (e *BaseTradingEngine) SetIDGenerator(generator IDGenerator) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ids = generator
+}
+
+// newID 使用引擎的ID生成器生成ID（内部方法）
+func (e *BaseTradingEngine) newID(prefix string) string {
+	if e.ids == nil {
+		return DefaultIDGenerator.NewID(prefix)
+	}
+	return e.ids.NewID(prefix)
+}
+
+// tradeID 按平仓订单的ID生成交易ID，如 order-<UUID> 对应 trade-<UUID>（内部方法，调用方持锁）
+// 回放预写日志时同一笔成交得到相同的交易ID，重启前返回的ID仍然有效；
+// 同一订单多次部分平仓产生的交易依次加上 -2、-3 等序号
+func (e *BaseTradingEngine) tradeID(orderID string) string {
+	base := "trade-" + strings.TrimPrefix(orderID, "order-")
+	id := base
+	for n := 2; e.hasTrade(id); n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return id
+}
@@ -0,0 +1,94 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUUIDv7GeneratorMonotonic(t *testing.T) {
+	// 时钟停在同一毫秒后回拨，ID仍应单调递增
+	clock := time.UnixMilli(1700000000000)
+	g := NewUUIDv7Generator()
+	g.now = func() time.Time { return clock }
+
+	var ids []string
+	for i := 0; i < 5000; i++ {
+		if i == 2500 {
+			clock = clock.Add(-time.Second)
+		}
+		ids = append(ids, g.NewID("order"))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("第%d个ID %s 不大于前一个 %s", i, ids[i], ids[i-1])
+		}
+	}
+	id := strings.TrimPrefix(ids[0], "order-")
+	if len(id) != 36 || id[14] != '7' || !strings.ContainsRune("89ab", rune(id[19])) {
+		t.Errorf("ID不是UUIDv7格式: %s", ids[0])
+	}
+}
+
+func TestUUIDv7GeneratorConcurrent(t *testing.T) {
+	g := NewUUIDv7Generator()
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				id := g.NewID("order")
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("重复的ID: %s", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestEngineIDGenerator(t *testing.T) {
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 10})
+	engine.Enable()
+
+	var mu sync.Mutex
+	n := 0
+	engine.SetIDGenerator(IDGeneratorFunc(func(prefix string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return fmt.Sprintf("%s-%d", prefix, n)
+	}))
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		order, err := engine.PlaceOrder(context.Background(), OrderRequest{Symbol: "AAPL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1})
+		if err != nil {
+			t.Fatalf("下单失败: %v", err)
+		}
+		ids = append(ids, order.ID)
+	}
+	if !sort.StringsAreSorted(ids) || ids[0] != "order-1" {
+		t.Errorf("订单ID应由设置的生成器生成: %v", ids)
+	}
+
+	watchlist := NewWatchlist(engine, nil)
+	watchlist.SetIDGenerator(IDGeneratorFunc(func(prefix string) string { return prefix + "-1" }))
+	if err := watchlist.AddItem(WatchlistItem{Symbol: "AAPL", Quantity: 1}); err != nil {
+		t.Fatalf("添加监控项失败: %v", err)
+	}
+	if _, err := watchlist.GetItem("watch-1"); err != nil {
+		t.Errorf("监控项ID应由设置的生成器生成: %v", err)
+	}
+}
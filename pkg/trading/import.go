@@ -0,0 +1,62 @@
+package trading
+
+import (
+	"sort"
+)
+
+// ImportTrades 导入历史交易（例如从券商对账单重建的交易），使其出现在交易统计和报表中
+// 交易按ID去重，已存在的交易会被跳过；配置了预写日志时导入的交易会写入日志，重启后可以恢复。
+// 导入的交易不影响持仓和账户盈亏，返回实际导入的数量
+func (e *BaseTradingEngine) ImportTrades(trades []Trade) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	imported := 0
+	for i := range trades {
+		trade := trades[i]
+		if e.hasTrade(trade.ID) {
+			continue
+		}
+		if e.wal != nil && !e.replaying {
+			if err := e.wal.Append(WALRecord{Type: WALTradeImported, Trade: &trade}); err != nil {
+				return imported, err
+			}
+		}
+		e.importTrade(trade)
+		imported++
+	}
+	return imported, nil
+}
+
+// hasTrade 检查是否已有指定ID的交易（内部方法，调用方持锁）
+func (e *BaseTradingEngine) hasTrade(id string) bool {
+	return e.tradeIDs[id]
+}
+
+// appendTrade 在交易列表末尾追加交易并记录其ID（内部方法，调用方持锁）
+func (e *BaseTradingEngine) appendTrade(trade Trade) {
+	e.trades = append(e.trades, trade)
+	e.tradeIDs[trade.ID] = true
+}
+
+// importTrade 按平仓时间顺序插入交易（内部方法，调用方持锁）
+func (e *BaseTradingEngine) importTrade(trade Trade) {
+	if e.hasTrade(trade.ID) {
+		return
+	}
+	closedAt := trade.OpenedAt
+	if trade.ClosedAt != nil {
+		closedAt = *trade.ClosedAt
+	}
+	i := sort.Search(len(e.trades), func(i int) bool {
+		other := e.trades[i].OpenedAt
+		if e.trades[i].ClosedAt != nil {
+			other = *e.trades[i].ClosedAt
+		}
+		return other.After(closedAt)
+	})
+	e.trades = append(e.trades, Trade{})
+	copy(e.trades[i+1:], e.trades[i:])
+	e.trades[i] = trade
+	e.tradeIDs[trade.ID] = true
+}
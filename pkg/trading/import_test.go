@@ -0,0 +1,98 @@
+package trading
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestImportTrades(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	ctx := context.Background()
+	limits := TradingLimits{MaxPositions: 10}
+
+	wal, err := OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, limits)
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	// 引擎自己平仓产生一笔交易
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideSell); err != nil {
+		t.Fatalf("卖出失败: %v", err)
+	}
+	live := engine.trades[0]
+
+	// 导入的交易按平仓时间插入，同一批中重复的ID和引擎已有的交易被跳过
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 1, d, 16, 0, 0, 0, time.UTC)
+		return &at
+	}
+	trades := []Trade{
+		{ID: "stmt-2", Symbol: "MSFT", OpenedAt: *day(1), ClosedAt: day(2), RealizedPnL: 20},
+		{ID: "stmt-1", Symbol: "MSFT", OpenedAt: *day(1), ClosedAt: day(1), RealizedPnL: 10},
+		{ID: "stmt-2", Symbol: "MSFT", OpenedAt: *day(1), ClosedAt: day(3), RealizedPnL: 99},
+		live,
+	}
+	if n, err := engine.ImportTrades(trades); err != nil || n != 2 {
+		t.Fatalf("应导入2笔交易: %d %v", n, err)
+	}
+	if n, _ := engine.ImportTrades(trades); n != 0 {
+		t.Errorf("再次导入应全部跳过: %d", n)
+	}
+	want := []string{"stmt-1", "stmt-2", live.ID}
+	checkOrder := func(name string, e *BaseTradingEngine) {
+		t.Helper()
+		if len(e.trades) != len(want) {
+			t.Fatalf("%s交易数量 = %d, 期望 %d", name, len(e.trades), len(want))
+		}
+		for i, id := range want {
+			if e.trades[i].ID != id {
+				t.Errorf("%s第%d笔交易 = %s, 期望 %s", name, i, e.trades[i].ID, id)
+			}
+		}
+		if e.trades[1].RealizedPnL != 20 {
+			t.Errorf("%s应保留第一次出现的交易: %+v", name, e.trades[1])
+		}
+	}
+	checkOrder("导入后", engine)
+	wal.Close()
+
+	// 回放日志恢复导入的交易，恢复后仍按ID去重
+	wal, err = OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	imported := 0
+	wal.Replay(func(record WALRecord) error {
+		if record.Type == WALTradeImported {
+			imported++
+		}
+		return nil
+	})
+	if imported != 2 {
+		t.Errorf("应写入2条导入记录: %d", imported)
+	}
+
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, limits)
+	recovered.SetWAL(wal)
+	report, err := recovered.Recover()
+	if err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	if report.Trades != 3 {
+		t.Errorf("恢复报告不正确: %+v", report)
+	}
+	checkOrder("恢复后", recovered)
+	if n, _ := recovered.ImportTrades(trades[:3]); n != 0 {
+		t.Errorf("恢复后再次导入应全部跳过: %d", n)
+	}
+}
@@ -0,0 +1,76 @@
+package trading
+
+import (
+	"context"
+	"time"
+)
+
+// PnLPoint 表示日内盈亏曲线上的一个采样点
+type PnLPoint struct {
+	Time          time.Time `json:"time"`           // 采样区间的开始时间
+	RealizedPnL   float64   `json:"realized_pnl"`   // 当日已实现盈亏
+	UnrealizedPnL float64   `json:"unrealized_pnl"` // 持仓的未实现盈亏
+	TotalPnL      float64   `json:"total_pnl"`
+	Equity        float64   `json:"equity"`
+	GrossExposure float64   `json:"gross_exposure"`
+	NetExposure   float64   `json:"net_exposure"`
+}
+
+// GetIntradayPnL 返回当日（本地时间零点起）按bucket采样的盈亏和敞口，最后一个点为当前的实时值
+// 每个区间取区间内最后一个权益快照，没有快照的区间不输出；bucket不大于0时输出每个快照。
+// 当日已实现盈亏以零点前最后一个快照为基准，没有更早的快照时以当日第一个快照为基准。
+// 采样依赖权益快照记录（trading.equity_interval_seconds），未记录时只返回当前值
+func (e *BaseTradingEngine) GetIntradayPnL(ctx context.Context, bucket time.Duration) ([]PnLPoint, error) {
+	now := e.now()
+	year, month, day := now.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	// 向前多取一天，用于确定当日已实现盈亏的基准
+	snapshots, err := e.equity().Range(start.AddDate(0, 0, -1), now)
+	if err != nil {
+		return nil, err
+	}
+	live, err := e.equitySnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live.Timestamp = now
+	return intradayPnL(append(snapshots, live), start, bucket), nil
+}
+
+// intradayPnL 将按时间排序的权益快照按区间采样为日内盈亏曲线（内部函数）
+func intradayPnL(snapshots []EquitySnapshot, start time.Time, bucket time.Duration) []PnLPoint {
+	baseline, hasBaseline := 0.0, false
+	var points []PnLPoint
+	for _, snapshot := range snapshots {
+		if snapshot.Timestamp.Before(start) {
+			baseline, hasBaseline = snapshot.RealizedPnL, true
+			continue
+		}
+		if !hasBaseline {
+			baseline, hasBaseline = snapshot.RealizedPnL, true
+		}
+
+		at := snapshot.Timestamp
+		if bucket > 0 {
+			at = start.Add(at.Sub(start) / bucket * bucket)
+		}
+		point := PnLPoint{
+			Time:          at,
+			RealizedPnL:   snapshot.RealizedPnL - baseline,
+			UnrealizedPnL: snapshot.UnrealizedPnL,
+			Equity:        snapshot.Equity,
+			GrossExposure: snapshot.GrossExposure,
+			NetExposure:   snapshot.NetExposure,
+		}
+		point.TotalPnL = point.RealizedPnL + point.UnrealizedPnL
+
+		// 同一区间内较晚的快照覆盖较早的快照
+		if n := len(points); bucket > 0 && n > 0 && points[n-1].Time.Equal(at) {
+			points[n-1] = point
+		} else {
+			points = append(points, point)
+		}
+	}
+	return points
+}
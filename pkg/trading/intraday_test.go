@@ -0,0 +1,80 @@
+package trading
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIntradayPnL(t *testing.T) {
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return start.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	snapshots := []EquitySnapshot{
+		{Timestamp: start.Add(-time.Hour), RealizedPnL: 500}, // 前一日收盘，作为基准
+		{Timestamp: at(9, 31), RealizedPnL: 500, UnrealizedPnL: 20, GrossExposure: 1000},
+		{Timestamp: at(9, 44), RealizedPnL: 560, UnrealizedPnL: -10, GrossExposure: 800},
+		{Timestamp: at(10, 5), RealizedPnL: 600, UnrealizedPnL: 0, Equity: 100600},
+	}
+
+	points := intradayPnL(snapshots, start, 15*time.Minute)
+	if len(points) != 2 {
+		t.Fatalf("采样点数量 = %d, 期望 2: %+v", len(points), points)
+	}
+	first := points[0]
+	if !first.Time.Equal(at(9, 30)) || first.RealizedPnL != 60 || first.UnrealizedPnL != -10 || first.TotalPnL != 50 || first.GrossExposure != 800 {
+		t.Errorf("区间内应取最后一个快照: %+v", first)
+	}
+	if !points[1].Time.Equal(at(10, 0)) || points[1].RealizedPnL != 100 || points[1].Equity != 100600 {
+		t.Errorf("第二个采样点不正确: %+v", points[1])
+	}
+
+	// 没有零点前的快照时以当日第一个快照为基准
+	points = intradayPnL(snapshots[1:], start, 0)
+	if len(points) != 3 || points[0].RealizedPnL != 0 || points[2].RealizedPnL != 100 {
+		t.Errorf("不采样时应输出每个快照: %+v", points)
+	}
+}
+
+func TestGetIntradayPnLLive(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+
+	engine.RecordEquity(ctx)
+	engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy)
+	engine.ApplyMarks(map[string]float64{"AAPL": 103})
+
+	points, err := engine.GetIntradayPnL(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("获取日内盈亏失败: %v", err)
+	}
+	last := points[len(points)-1]
+	if last.UnrealizedPnL != 30 || last.GrossExposure != 1030 {
+		t.Errorf("最后一个采样点应为实时值: %+v", last)
+	}
+}
+
+func TestGetIntradayPnLPartialExit(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+
+	// 买入100股后按成本价卖出一半，剩余50股按101估值
+	engine.RecordEquity(ctx)
+	engine.SubmitOrder(ctx, "AAPL", 100, 0, OrderTypeMarket, OrderSideBuy)
+	engine.SubmitOrder(ctx, "AAPL", 50, 0, OrderTypeMarket, OrderSideSell)
+	engine.ApplyMarks(map[string]float64{"AAPL": 101})
+
+	points, err := engine.GetIntradayPnL(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("获取日内盈亏失败: %v", err)
+	}
+	last := points[len(points)-1]
+	if last.RealizedPnL != 0 || last.UnrealizedPnL != 50 || last.TotalPnL != 50 || last.Equity != 100050 || last.GrossExposure != 5050 {
+		t.Errorf("部分平仓后的日内盈亏不正确: %+v", last)
+	}
+}
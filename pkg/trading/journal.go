@@ -0,0 +1,151 @@
+package trading
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TradeGrades 是交易复盘可用的评分
+var TradeGrades = []string{"A", "B", "C", "D", "F"}
+
+// TradeReview 表示对已完成交易的复盘记录，每次修改整体替换交易原有的复盘内容
+type TradeReview struct {
+	Notes       string   `json:"notes,omitempty"`
+	Setups      []string `json:"setups,omitempty"`      // 交易形态标签
+	Screenshots []string `json:"screenshots,omitempty"` // 截图的路径或URL
+	Grade       string   `json:"grade,omitempty"`       // A到F，为空表示未评分
+}
+
+// Validate 检查复盘记录是否有效
+func (r TradeReview) Validate() error {
+	if r.Grade != "" && !slices.Contains(TradeGrades, r.Grade) {
+		return fmt.Errorf("grade must be one of %s, got %q", strings.Join(TradeGrades, ", "), r.Grade)
+	}
+	for _, setup := range r.Setups {
+		if strings.TrimSpace(setup) == "" {
+			return fmt.Errorf("setup tags must not be empty")
+		}
+	}
+	return nil
+}
+
+// GetTrade 获取指定ID的交易
+func (e *BaseTradingEngine) GetTrade(ctx context.Context, id string) (*Trade, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	i := e.tradeIndex(Trade{ID: id})
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTradeNotFound, id)
+	}
+	trade := e.trades[i]
+	return &trade, nil
+}
+
+// ReviewTrade 为已平仓的交易添加或修改复盘记录（备注、交易形态、截图和评分），返回修改后的交易
+// 配置了预写日志时复盘记录写入日志，重启后可以恢复
+func (e *BaseTradingEngine) ReviewTrade(ctx context.Context, id string, review TradeReview) (*Trade, error) {
+	if err := review.Validate(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	i := e.tradeIndex(Trade{ID: id})
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTradeNotFound, id)
+	}
+	if e.trades[i].ClosedAt == nil {
+		return nil, fmt.Errorf("trade %s is not closed", id)
+	}
+
+	now := e.now()
+	if e.wal != nil && !e.replaying {
+		// 同时记录平仓订单，旧版本的日志中交易ID在恢复时重新生成，按平仓订单匹配
+		ref := Trade{ID: id, Symbol: e.trades[i].Symbol, ExitOrder: e.trades[i].ExitOrder}
+		if err := e.wal.Append(WALRecord{Type: WALTradeReviewed, Timestamp: now, Trade: &ref, Review: &review}); err != nil {
+			return nil, err
+		}
+	}
+	e.applyReview(&e.trades[i], review, now)
+
+	trade := e.trades[i]
+	return &trade, nil
+}
+
+// tradeIndex 按ID查找交易，找不到时按平仓订单查找，返回-1表示不存在（内部方法，调用方持锁）
+func (e *BaseTradingEngine) tradeIndex(ref Trade) int {
+	for i, trade := range e.trades {
+		if trade.ID == ref.ID {
+			return i
+		}
+	}
+	if ref.ExitOrder == nil || ref.ExitOrder.ID == "" {
+		return -1
+	}
+	for i, trade := range e.trades {
+		if trade.ExitOrder != nil && trade.ExitOrder.ID == ref.ExitOrder.ID && trade.Symbol == ref.Symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyReview 将复盘记录写入交易（内部方法，调用方持锁）
+func (e *BaseTradingEngine) applyReview(trade *Trade, review TradeReview, at time.Time) {
+	trade.Notes = review.Notes
+	trade.Setups = slices.Clone(review.Setups)
+	trade.Screenshots = slices.Clone(review.Screenshots)
+	trade.Grade = review.Grade
+	trade.ReviewedAt = &at
+}
+
+// WriteTradesCSV 以每笔交易一行的格式导出交易，包含复盘记录，多个标签、形态和截图以分号分隔，
+// 自定义元数据按键排序导出为 key=value 并以分号分隔
+func WriteTradesCSV(w io.Writer, trades []Trade) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{
+		"id", "symbol", "strategy", "quantity", "entry_price", "exit_price", "realized_pnl", "realized_pnl_percent",
+		"commission", "opened_at", "closed_at", "hold_time", "tags", "setups", "grade", "notes", "screenshots", "reviewed_at", "metadata",
+	})
+	for _, trade := range trades {
+		writer.Write([]string{
+			trade.ID,
+			trade.Symbol,
+			trade.Strategy,
+			strconv.FormatInt(trade.Quantity, 10),
+			strconv.FormatFloat(trade.EntryPrice, 'f', -1, 64),
+			strconv.FormatFloat(trade.ExitPrice, 'f', -1, 64),
+			strconv.FormatFloat(trade.RealizedPnL, 'f', 2, 64),
+			strconv.FormatFloat(trade.RealizedPnLPercent, 'f', 2, 64),
+			strconv.FormatFloat(trade.Commission, 'f', 2, 64),
+			trade.OpenedAt.Format(time.RFC3339),
+			formatOptionalTime(trade.ClosedAt),
+			strconv.FormatFloat(trade.HoldTime, 'f', 2, 64),
+			strings.Join(trade.Tags, ";"),
+			strings.Join(trade.Setups, ";"),
+			trade.Grade,
+			trade.Notes,
+			strings.Join(trade.Screenshots, ";"),
+			formatOptionalTime(trade.ReviewedAt),
+			formatMetadata(trade.Metadata),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatOptionalTime 格式化可能为空的时间，为空时返回空字符串（内部函数）
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,81 @@
+package trading
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReviewTrade(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	wal, err := OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy)
+	engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideSell)
+	trades, _ := engine.GetTrades(ctx, "", time.Time{}, time.Now())
+	if len(trades) != 1 {
+		t.Fatalf("应有1笔交易: %+v", trades)
+	}
+	id := trades[0].ID
+
+	if _, err := engine.ReviewTrade(ctx, id, TradeReview{Grade: "E"}); err == nil {
+		t.Error("无效的评分应返回错误")
+	}
+	if _, err := engine.ReviewTrade(ctx, "missing", TradeReview{}); !errors.Is(err, ErrTradeNotFound) {
+		t.Errorf("不存在的交易应返回 ErrTradeNotFound: %v", err)
+	}
+
+	review := TradeReview{
+		Notes:       "追高入场，等回踩更好",
+		Setups:      []string{"breakout"},
+		Screenshots: []string{"charts/aapl-entry.png"},
+		Grade:       "C",
+	}
+	if _, err := engine.ReviewTrade(ctx, id, review); err != nil {
+		t.Fatalf("添加复盘记录失败: %v", err)
+	}
+	review.Grade = "B"
+	trade, err := engine.ReviewTrade(ctx, id, review)
+	if err != nil || trade.Grade != "B" || trade.ReviewedAt == nil || trade.Setups[0] != "breakout" {
+		t.Fatalf("修改复盘记录失败: %+v %v", trade, err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTradesCSV(&buf, []Trade{*trade}); err != nil {
+		t.Fatalf("导出交易失败: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], ",breakout,B,追高入场，等回踩更好,charts/aapl-entry.png,") {
+		t.Errorf("导出的交易不正确: %q", buf.String())
+	}
+	wal.Close()
+
+	// 复盘记录从预写日志恢复，成交生成的交易按平仓订单匹配
+	wal, err = OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	recovered.SetWAL(wal)
+	if _, err := recovered.Recover(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	trades, _ = recovered.GetTrades(ctx, "", time.Time{}, time.Now())
+	if len(trades) != 1 || trades[0].Grade != "B" || trades[0].Notes != review.Notes {
+		t.Errorf("恢复的复盘记录不正确: %+v", trades)
+	}
+}
@@ -0,0 +1,292 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// LedgerAccount 表示现金账簿的科目
+type LedgerAccount string
+
+// 账簿科目常量，每条分录借记一个科目、贷记另一个科目
+const (
+	LedgerAccountCash        LedgerAccount = "cash"        // 现金
+	LedgerAccountSecurities  LedgerAccount = "securities"  // 证券，买入成本和卖出所得
+	LedgerAccountCapital     LedgerAccount = "capital"     // 资本，期初余额和出入金
+	LedgerAccountCommissions LedgerAccount = "commissions" // 手续费支出
+	LedgerAccountDividends   LedgerAccount = "dividends"   // 股息收入，空头支付的股息为负
+	LedgerAccountInterest    LedgerAccount = "interest"    // 利息收入，融资利息为负
+)
+
+// LedgerEntryKind 表示分录的类型
+type LedgerEntryKind string
+
+// 分录类型常量
+const (
+	LedgerKindOpening    LedgerEntryKind = "opening"    // 账簿开立时的期初现金
+	LedgerKindFill       LedgerEntryKind = "fill"       // 成交的买入成本或卖出所得
+	LedgerKindCommission LedgerEntryKind = "commission" // 成交的手续费
+	LedgerKindDeposit    LedgerEntryKind = "deposit"    // 入金
+	LedgerKindWithdrawal LedgerEntryKind = "withdrawal" // 出金
+	LedgerKindDividend   LedgerEntryKind = "dividend"   // 股息
+	LedgerKindInterest   LedgerEntryKind = "interest"   // 利息
+)
+
+// CashDriftTolerance 是对账时允许的现金差额，低于该值视为舍入误差
+const CashDriftTolerance = 0.01
+
+// EventCashDrift 是对账发现账户现金与账簿余额不一致时发布的事件类型
+const EventCashDrift = "cash_drift"
+
+// LedgerEntry 表示一条复式记账分录，金额总是正数，方向由借贷科目决定
+type LedgerEntry struct {
+	ID          string          `json:"id"`
+	Timestamp   time.Time       `json:"timestamp"`
+	Kind        LedgerEntryKind `json:"kind"`
+	Debit       LedgerAccount   `json:"debit"`
+	Credit      LedgerAccount   `json:"credit"`
+	Amount      float64         `json:"amount"`
+	CashBalance float64         `json:"cash_balance"` // 记入本分录后账簿的现金余额
+	Symbol      string          `json:"symbol,omitempty"`
+	OrderID     string          `json:"order_id,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// CashDelta 返回分录对现金的影响，借记现金为正，贷记现金为负
+func (l LedgerEntry) CashDelta() float64 {
+	switch {
+	case l.Debit == LedgerAccountCash:
+		return l.Amount
+	case l.Credit == LedgerAccountCash:
+		return -l.Amount
+	}
+	return 0
+}
+
+// CashMovement 表示一笔成交以外的现金变动，用于记录出入金、股息和利息
+type CashMovement struct {
+	Kind        LedgerEntryKind `json:"kind"`   // deposit、withdrawal、dividend 或 interest
+	Amount      float64         `json:"amount"` // 出入金为正数；股息和利息为正时收入，为负时支出
+	Symbol      string          `json:"symbol,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Time        time.Time       `json:"time,omitempty"` // 为空时使用引擎时钟
+}
+
+// entry 将现金变动转换为分录，借贷科目由类型和金额的正负决定（内部方法）
+func (m CashMovement) entry() (LedgerEntry, error) {
+	if m.Amount == 0 || math.IsNaN(m.Amount) || math.IsInf(m.Amount, 0) {
+		return LedgerEntry{}, fmt.Errorf("invalid cash movement amount %v", m.Amount)
+	}
+	entry := LedgerEntry{Kind: m.Kind, Amount: math.Abs(m.Amount), Symbol: m.Symbol, Description: m.Description, Timestamp: m.Time}
+
+	var income LedgerAccount
+	switch m.Kind {
+	case LedgerKindDeposit, LedgerKindWithdrawal:
+		if m.Amount < 0 {
+			return LedgerEntry{}, fmt.Errorf("%s amount must be positive", m.Kind)
+		}
+		entry.Debit, entry.Credit = LedgerAccountCash, LedgerAccountCapital
+		if m.Kind == LedgerKindWithdrawal {
+			entry.Debit, entry.Credit = LedgerAccountCapital, LedgerAccountCash
+		}
+		return entry, nil
+	case LedgerKindDividend:
+		income = LedgerAccountDividends
+	case LedgerKindInterest:
+		income = LedgerAccountInterest
+	default:
+		return LedgerEntry{}, fmt.Errorf("invalid cash movement kind %q", m.Kind)
+	}
+
+	entry.Debit, entry.Credit = LedgerAccountCash, income
+	if m.Amount < 0 {
+		entry.Debit, entry.Credit = income, LedgerAccountCash
+	}
+	return entry, nil
+}
+
+// CashReconciliation 表示一次现金对账的结果
+type CashReconciliation struct {
+	Timestamp     time.Time `json:"timestamp"`
+	LedgerBalance float64   `json:"ledger_balance"` // 按账簿所有分录重新累加的现金余额
+	AccountCash   float64   `json:"account_cash"`   // 账户的现金，券商提供现金余额时为券商的数值
+	Drift         float64   `json:"drift"`          // 账户现金减去账簿余额
+	Entries       int       `json:"entries"`
+	Reconciled    bool      `json:"reconciled"` // 差额不超过 CashDriftTolerance
+}
+
+// CashReporter 是可选的券商接口，提供券商账户的现金余额，对账时以券商的数值为准
+type CashReporter interface {
+	// AccountCash 返回券商账户当前的现金余额
+	AccountCash(ctx context.Context) (float64, error)
+}
+
+// RecordCashMovement 记录一笔出入金、股息或利息，同时调整账户现金
+// 配置了预写日志时分录写入日志，重启后恢复
+func (e *BaseTradingEngine) RecordCashMovement(ctx context.Context, movement CashMovement) (*LedgerEntry, error) {
+	entry, err := movement.entry()
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = e.now()
+	}
+	if err := e.recordCash(&entry, nil); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// recordCash 为成交以外的现金变动分配ID和现金余额，写入预写日志后记入账簿；
+// 派发分红时应收分红与分录写入同一条记录，恢复时不会重复派发（内部方法，调用方持锁）
+func (e *BaseTradingEngine) recordCash(entry *LedgerEntry, dividend *DividendEntitlement) error {
+	e.openLedger(entry.Timestamp)
+	entry.ID = e.newID("ledger")
+	entry.CashBalance = e.ledgerBalance() + entry.CashDelta()
+	if e.wal != nil && !e.replaying {
+		if err := e.wal.Append(WALRecord{Type: WALCashRecorded, Timestamp: e.now(), Ledger: entry, Dividend: dividend}); err != nil {
+			return err
+		}
+	}
+	e.appendLedger(*entry)
+	if dividend != nil {
+		e.applyEntitlement(*dividend)
+	}
+	return nil
+}
+
+// GetLedger 返回时间范围内（包含两端）按记账顺序排列的分录，to 为零值时不限制结束时间
+func (e *BaseTradingEngine) GetLedger(ctx context.Context, from, to time.Time) ([]LedgerEntry, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var entries []LedgerEntry
+	for _, entry := range e.ledger {
+		if entry.Timestamp.Before(from) || (!to.IsZero() && entry.Timestamp.After(to)) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ReconcileCash 按账簿的所有分录重新累加现金余额并与账户现金比较，差额超过 CashDriftTolerance 时
+// 在 risk 主题发布 cash_drift 事件。券商实现了 CashReporter 时先用券商的现金余额更新账户
+func (e *BaseTradingEngine) ReconcileCash(ctx context.Context) (*CashReconciliation, error) {
+	e.mu.RLock()
+	reporter, ok := e.broker.(CashReporter)
+	e.mu.RUnlock()
+	reported := 0.0
+	if ok {
+		cash, err := reporter.AccountCash(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get broker cash: %v", err)
+		}
+		reported = cash
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.openLedger(now)
+	if ok {
+		e.account.Cash = reported
+	}
+
+	result := CashReconciliation{Timestamp: now, AccountCash: e.account.Cash, Entries: len(e.ledger)}
+	for _, entry := range e.ledger {
+		result.LedgerBalance += entry.CashDelta()
+	}
+	result.Drift = result.AccountCash - result.LedgerBalance
+	result.Reconciled = math.Abs(result.Drift) <= CashDriftTolerance
+	if !result.Reconciled {
+		e.publish(events.TopicRisk, EventCashDrift, result)
+	}
+	return &result, nil
+}
+
+// postFill 为成交记入买卖金额和手续费分录（内部方法，调用方持锁）
+func (e *BaseTradingEngine) postFill(order Order) {
+	at := e.now()
+	if order.FilledAt != nil {
+		at = *order.FilledAt
+	}
+	e.openLedger(at)
+
+	fill := LedgerEntry{
+		Timestamp: at,
+		Kind:      LedgerKindFill,
+		Debit:     LedgerAccountSecurities,
+		Credit:    LedgerAccountCash,
+		Amount:    float64(order.FilledQty) * order.AvgFillPrice,
+		Symbol:    order.Symbol,
+		OrderID:   order.ID,
+	}
+	if order.Side == OrderSideSell {
+		fill.Debit, fill.Credit = LedgerAccountCash, LedgerAccountSecurities
+	}
+	e.postEntry(fill)
+
+	if order.Commission > 0 {
+		e.postEntry(LedgerEntry{
+			Timestamp: at,
+			Kind:      LedgerKindCommission,
+			Debit:     LedgerAccountCommissions,
+			Credit:    LedgerAccountCash,
+			Amount:    order.Commission,
+			Symbol:    order.Symbol,
+			OrderID:   order.ID,
+		})
+	}
+}
+
+// openLedger 账簿为空时以账户当前的现金记入期初余额，账户未初始化时先创建默认账户（内部方法，调用方持锁）
+func (e *BaseTradingEngine) openLedger(at time.Time) {
+	if len(e.ledger) > 0 {
+		return
+	}
+	e.ensureAccount()
+	e.ledger = append(e.ledger, LedgerEntry{
+		ID:          e.newID("ledger"),
+		Timestamp:   at,
+		Kind:        LedgerKindOpening,
+		Debit:       LedgerAccountCash,
+		Credit:      LedgerAccountCapital,
+		Amount:      e.account.Cash,
+		CashBalance: e.account.Cash,
+	})
+}
+
+// postEntry 为分录分配ID和现金余额后记入账簿，金额为0的分录不记录（内部方法，调用方持锁）
+func (e *BaseTradingEngine) postEntry(entry LedgerEntry) {
+	if entry.Amount <= 0 {
+		return
+	}
+	entry.ID = e.newID("ledger")
+	entry.CashBalance = e.ledgerBalance() + entry.CashDelta()
+	e.appendLedger(entry)
+}
+
+// appendLedger 将分录记入账簿并按分录调整账户现金（内部方法，调用方持锁）
+func (e *BaseTradingEngine) appendLedger(entry LedgerEntry) {
+	e.openLedger(entry.Timestamp)
+	e.ledger = append(e.ledger, entry)
+	e.account.Cash += entry.CashDelta()
+}
+
+// ledgerBalance 返回账簿最后一条分录之后的现金余额（内部方法，调用方持锁）
+func (e *BaseTradingEngine) ledgerBalance() float64 {
+	if len(e.ledger) == 0 {
+		return 0
+	}
+	return e.ledger[len(e.ledger)-1].CashBalance
+}
@@ -0,0 +1,121 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// cashReportingBroker 是报告固定现金余额的测试券商
+type cashReportingBroker struct {
+	restingBroker
+	cash float64
+}
+
+func (b *cashReportingBroker) AccountCash(ctx context.Context) (float64, error) { return b.cash, nil }
+
+func TestCashLedger(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	wal, err := OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	broker := &restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	// 买入1000美元，限价卖单成交1100美元并支付1.5美元手续费
+	engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy)
+	sell, _ := engine.SubmitOrder(ctx, "AAPL", 10, 110, OrderTypeLimit, OrderSideSell)
+	engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: Order{ID: sell.ID, Status: OrderStatusFilled, FilledQty: 10, AvgFillPrice: 110, Commission: 1.5}})
+
+	if _, err := engine.RecordCashMovement(ctx, CashMovement{Kind: LedgerKindWithdrawal, Amount: -5}); err == nil {
+		t.Error("出金金额为负数时应返回错误")
+	}
+	if _, err := engine.RecordCashMovement(ctx, CashMovement{Kind: "transfer", Amount: 5}); err == nil {
+		t.Error("未知的现金变动类型应返回错误")
+	}
+	engine.RecordCashMovement(ctx, CashMovement{Kind: LedgerKindDeposit, Amount: 5000})
+	dividend, err := engine.RecordCashMovement(ctx, CashMovement{Kind: LedgerKindDividend, Amount: -12, Symbol: "MSFT"})
+	if err != nil || dividend.Debit != LedgerAccountDividends || dividend.Credit != LedgerAccountCash || dividend.Amount != 12 {
+		t.Fatalf("空头支付的股息应借记股息科目: %+v %v", dividend, err)
+	}
+
+	ledger, _ := engine.GetLedger(ctx, time.Time{}, time.Time{})
+	kinds := []LedgerEntryKind{LedgerKindOpening, LedgerKindFill, LedgerKindFill, LedgerKindCommission, LedgerKindDeposit, LedgerKindDividend}
+	if len(ledger) != len(kinds) {
+		t.Fatalf("账簿分录数量不正确: %+v", ledger)
+	}
+	for i, kind := range kinds {
+		if ledger[i].Kind != kind {
+			t.Errorf("第%d条分录应为 %s: %+v", i, kind, ledger[i])
+		}
+	}
+	want := 100000 - 1000 + 1100 - 1.5 + 5000 - 12
+	if last := ledger[len(ledger)-1]; math.Abs(last.CashBalance-want) > 1e-9 {
+		t.Errorf("现金余额应为 %v: %+v", want, last)
+	}
+	if account, _ := engine.GetAccount(ctx); math.Abs(account.Cash-want) > 1e-9 {
+		t.Errorf("分录应同时调整账户现金: %v", account.Cash)
+	}
+	if result, _ := engine.ReconcileCash(ctx); !result.Reconciled || result.Entries != 6 {
+		t.Errorf("账户现金应与账簿一致: %+v", result)
+	}
+	wal.Close()
+
+	// 成交分录由回放的成交重新生成，其他现金变动从预写日志恢复
+	wal, err = OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	recovered.SetWAL(wal)
+	if _, err := recovered.Recover(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	restored, _ := recovered.GetLedger(ctx, time.Time{}, time.Time{})
+	if len(restored) != len(kinds) || math.Abs(restored[len(restored)-1].CashBalance-want) > 1e-9 {
+		t.Errorf("恢复的账簿不正确: %+v", restored)
+	}
+}
+
+func TestReconcileCashDrift(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicRisk)
+	defer sub.Close()
+
+	broker := &cashReportingBroker{restingBroker: restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}, cash: 99000}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(broker)
+	engine.SetEventBus(bus)
+	engine.Enable()
+	engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy)
+
+	if result, err := engine.ReconcileCash(ctx); err != nil || !result.Reconciled {
+		t.Fatalf("券商现金与账簿一致时应对账通过: %+v %v", result, err)
+	}
+
+	// 券商多扣了25美元，账簿中没有对应的分录
+	broker.cash = 98975
+	result, err := engine.ReconcileCash(ctx)
+	if err != nil || result.Reconciled || math.Abs(result.Drift+25) > 1e-9 || result.LedgerBalance != 99000 {
+		t.Fatalf("应发现未解释的现金差额: %+v %v", result, err)
+	}
+	select {
+	case evt := <-sub.C:
+		if evt.Type != EventCashDrift {
+			t.Errorf("应发布现金差额事件: %+v", evt)
+		}
+	default:
+		t.Error("没有发布现金差额事件")
+	}
+}
@@ -0,0 +1,192 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// TagDailyLoss 是当日亏损熔断平仓订单的标签
+const TagDailyLoss = "daily_loss"
+
+// DailyLossHalt 表示账户当日亏损达到上限后的交易熔断，作为 daily_loss_halt 事件的内容
+type DailyLossHalt struct {
+	Day            time.Time `json:"day"`          // 熔断所在日期的零点，次日自动解除
+	Loss           float64   `json:"loss"`         // 当日权益的下降（不含出入金），正数表示亏损
+	LossPercent    float64   `json:"loss_percent"` // 亏损占当日起始权益的百分比
+	LimitPercent   float64   `json:"limit_percent"`
+	StartEquity    float64   `json:"start_equity"`
+	HaltedAt       time.Time `json:"halted_at"`
+	CanceledOrders []string  `json:"canceled_orders,omitempty"`
+	FlattenOrders  []string  `json:"flatten_orders,omitempty"` // 平仓市价单的ID
+	Errors         []string  `json:"errors,omitempty"`         // 撤单或平仓失败的原因
+}
+
+// GetDailyLossHalt 返回当日的亏损熔断，未熔断时返回空
+func (e *BaseTradingEngine) GetDailyLossHalt() *DailyLossHalt {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.lossHalt == nil || !e.lossHalt.Day.Equal(startOfDay(e.now())) {
+		return nil
+	}
+	halt := *e.lossHalt
+	return &halt
+}
+
+// ClearDailyLossHalt 手动解除当日亏损熔断；亏损仍超过上限时下次检查会再次熔断，需先用 SetLimits 调整上限
+func (e *BaseTradingEngine) ClearDailyLossHalt() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lossHalt == nil {
+		return fmt.Errorf("trading is not halted by the daily loss limit")
+	}
+	halt := *e.lossHalt
+	e.lossHalt = nil
+	e.publish(events.TopicRisk, EventDailyLossReset, halt)
+	return nil
+}
+
+// CheckDailyLoss 检查账户当日亏损是否达到 MaxDailyLossPercent，达到时熔断：
+// 撤销所有未完成的订单，以市价单平掉所有持仓，并在 risk 主题发布 daily_loss_halt 事件。
+// 熔断期间只允许减仓，次日自动解除；已熔断时为尚未平掉且没有平仓单的持仓重新下单，不再发布事件
+func (e *BaseTradingEngine) CheckDailyLoss(ctx context.Context) (*DailyLossHalt, error) {
+	if !e.IsEnabled() {
+		return nil, nil
+	}
+	// 先获取一次账户，未初始化的账户在这里创建
+	account, err := e.GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := e.now()
+
+	e.mu.Lock()
+	e.rollLossDay(now)
+	if e.dayEquity <= 0 {
+		e.dayEquity = account.Equity
+		e.dayEquityAt = now
+	}
+	triggered := false
+	if e.lossHalt == nil {
+		limit := e.limits.MaxDailyLossPercent
+		if limit <= 0 || e.dayEquity <= 0 {
+			e.mu.Unlock()
+			return nil, nil
+		}
+		loss := -e.dailyPnL()
+		percent := loss / e.dayEquity * 100
+		if percent < limit {
+			e.mu.Unlock()
+			return nil, nil
+		}
+		e.lossHalt = &DailyLossHalt{
+			Day:          e.lossDay,
+			Loss:         loss,
+			LossPercent:  percent,
+			LimitPercent: limit,
+			StartEquity:  e.dayEquity,
+			HaltedAt:     now,
+		}
+		triggered = true
+	}
+
+	var orders []Order
+	if triggered {
+		for _, order := range e.orders {
+			if isOpenOrder(order) {
+				orders = append(orders, order)
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	var canceled, flatten, errs []string
+	for _, order := range orders {
+		if err := e.CancelOrder(ctx, order.ID); err != nil {
+			errs = append(errs, fmt.Sprintf("cancel %s: %v", order.ID, err))
+			continue
+		}
+		canceled = append(canceled, order.ID)
+	}
+
+	// 撤单之后再确定需要平仓的持仓，原有的平仓单已撤销的持仓也会平掉
+	var positions []Position
+	e.mu.RLock()
+	for key, pos := range e.positions {
+		if pos.Quantity != 0 && !e.hasExitOrder(key, TagStopLoss, TagTakeProfit, TagDailyLoss, TagMaxHold, TagSessionClose) {
+			positions = append(positions, pos)
+		}
+	}
+	e.mu.RUnlock()
+	for _, pos := range positions {
+		order, err := e.PlaceOrder(ctx, exitRequest(pos, TagDailyLoss))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("flatten %s: %v", pos.Symbol, err))
+			continue
+		}
+		flatten = append(flatten, order.ID)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.lossHalt == nil {
+		// 检查期间被手动解除
+		return nil, nil
+	}
+	e.lossHalt.CanceledOrders = append(e.lossHalt.CanceledOrders, canceled...)
+	e.lossHalt.FlattenOrders = append(e.lossHalt.FlattenOrders, flatten...)
+	e.lossHalt.Errors = append(e.lossHalt.Errors, errs...)
+	halt := *e.lossHalt
+	if triggered {
+		e.publish(events.TopicRisk, EventDailyLossHalt, halt)
+	}
+	return &halt, nil
+}
+
+// checkDailyLossHalt 检查是否处于当日亏损熔断，熔断期间只允许减仓（内部方法，调用方持锁）
+func (e *BaseTradingEngine) checkDailyLossHalt(req OrderRequest, now time.Time) error {
+	e.rollLossDay(now)
+	if e.lossHalt == nil || e.reducesPosition(req) {
+		return nil
+	}
+	return fmt.Errorf("%w: daily loss %.2f%% reached limit %.2f%%", ErrDailyLossHalt, e.lossHalt.LossPercent, e.lossHalt.LimitPercent)
+}
+
+// rollLossDay 进入新的一天时重置当日起始权益并解除前一天的熔断（内部方法，调用方持锁）
+func (e *BaseTradingEngine) rollLossDay(now time.Time) {
+	day := startOfDay(now)
+	if e.lossDay.Equal(day) {
+		return
+	}
+	e.lossDay = day
+	e.dayEquity = 0
+	e.dayEquityAt = time.Time{}
+	if e.lossHalt != nil {
+		halt := *e.lossHalt
+		e.lossHalt = nil
+		e.publish(events.TopicRisk, EventDailyLossReset, halt)
+	}
+}
+
+// dailyPnL 返回账户当日的盈亏：当前权益减去当日起始权益，扣除之后的出入金（内部方法，调用方持锁）
+// 之前几天积累的未实现盈亏已包含在起始权益中，不计入当日；导入的历史交易不影响现金和持仓，也不计入
+func (e *BaseTradingEngine) dailyPnL() float64 {
+	e.refreshAccount()
+	pnl := e.account.Equity - e.dayEquity
+	for _, entry := range e.ledger {
+		if (entry.Kind == LedgerKindDeposit || entry.Kind == LedgerKindWithdrawal) && !entry.Timestamp.Before(e.dayEquityAt) {
+			pnl -= entry.CashDelta()
+		}
+	}
+	return pnl
+}
+
+// startOfDay 返回t所在日期的零点（内部函数）
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
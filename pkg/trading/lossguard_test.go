@@ -0,0 +1,171 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+func TestDailyLossHalt(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10, MaxDailyLossPercent: 1})
+	engine.SetBroker(&restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}})
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicRisk)
+	defer sub.Close()
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 100, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	resting, err := engine.SubmitOrder(ctx, "MSFT", 10, 50, OrderTypeLimit, OrderSideBuy)
+	if err != nil {
+		t.Fatalf("挂单失败: %v", err)
+	}
+	if halt, err := engine.CheckDailyLoss(ctx); halt != nil || err != nil {
+		t.Fatalf("没有亏损时不应熔断: %+v %v", halt, err)
+	}
+
+	// 账户权益10万，持仓亏损1100（1.1%）超过1%的上限
+	engine.ApplyMarks(map[string]float64{"AAPL": 89})
+	halt, err := engine.CheckDailyLoss(ctx)
+	if err != nil || halt == nil {
+		t.Fatalf("亏损超过上限时应熔断: %+v %v", halt, err)
+	}
+	if halt.Loss != 1100 || halt.StartEquity != 100000 || len(halt.CanceledOrders) != 1 || halt.CanceledOrders[0] != resting.ID || len(halt.FlattenOrders) != 1 {
+		t.Errorf("熔断记录不正确: %+v", halt)
+	}
+	if positions, _ := engine.GetPositions(ctx); len(positions) != 0 {
+		t.Errorf("熔断后应平掉所有持仓: %+v", positions)
+	}
+	if open, _ := engine.GetOpenOrders(ctx); len(open) != 0 {
+		t.Errorf("熔断后应撤销所有订单: %+v", open)
+	}
+	evt := <-sub.C
+	if evt.Type != EventDailyLossHalt {
+		t.Errorf("应发布熔断事件: %+v", evt)
+	}
+
+	// 熔断期间不能开仓，再次检查不重复下单
+	_, err = engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy)
+	if GetRejectCode(err) != RejectCodeRiskLimit || !errors.Is(err, ErrDailyLossHalt) {
+		t.Errorf("熔断期间开仓应被拒绝: %v", err)
+	}
+	if again, _ := engine.CheckDailyLoss(ctx); again == nil || len(again.FlattenOrders) != 1 {
+		t.Errorf("再次检查应保持熔断且不重复平仓: %+v", again)
+	}
+	if engine.GetDailyLossHalt() == nil {
+		t.Error("应返回当日的熔断状态")
+	}
+
+	// 手动解除后可以开仓；平仓价与成本相同，当日没有亏损，不再熔断
+	if err := engine.ClearDailyLossHalt(); err != nil {
+		t.Fatalf("解除熔断失败: %v", err)
+	}
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Errorf("解除熔断后应允许开仓: %v", err)
+	}
+	if halt, _ := engine.CheckDailyLoss(ctx); halt != nil {
+		t.Errorf("没有亏损时不应再次熔断: %+v", halt)
+	}
+
+	// 前一天的熔断在次日自动解除
+	engine.mu.Lock()
+	engine.lossDay = engine.lossDay.AddDate(0, 0, -1)
+	engine.lossHalt = &DailyLossHalt{Day: engine.lossDay}
+	engine.mu.Unlock()
+	if _, err := engine.SubmitOrder(ctx, "MSFT", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Errorf("次日应允许开仓: %v", err)
+	}
+	if engine.GetDailyLossHalt() != nil {
+		t.Error("次日熔断应已解除")
+	}
+}
+
+func TestDailyLossBaseline(t *testing.T) {
+	ctx := context.Background()
+	sim := clock.NewManual(time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC))
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10, MaxDailyLossPercent: 1})
+	engine.SetClock(sim)
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+
+	// 前一天买入后下跌10%，亏损1000没有检查
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 100, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	engine.ApplyMarks(map[string]float64{"AAPL": 90})
+
+	// 次日开盘时之前的亏损已包含在起始权益中，不应熔断
+	sim.Set(time.Date(2024, 3, 8, 9, 30, 0, 0, time.UTC))
+	if halt, err := engine.CheckDailyLoss(ctx); halt != nil || err != nil {
+		t.Fatalf("之前几天的亏损不应计入当日: %+v %v", halt, err)
+	}
+
+	// 出金和导入的当日亏损交易都不计入当日亏损
+	today := sim.Now()
+	engine.RecordCashMovement(ctx, CashMovement{Kind: LedgerKindWithdrawal, Amount: 5000})
+	engine.ImportTrades([]Trade{{ID: "statement-1", Symbol: "MSFT", RealizedPnL: -5000, OpenedAt: today.Add(-time.Hour), ClosedAt: &today}})
+	engine.ApplyMarks(map[string]float64{"AAPL": 88})
+	if halt, err := engine.CheckDailyLoss(ctx); halt != nil || err != nil {
+		t.Fatalf("出金和导入的交易不应触发熔断: %+v %v", halt, err)
+	}
+
+	// 当日从90跌到79，亏损1100超过起始权益99000的1%
+	engine.ApplyMarks(map[string]float64{"AAPL": 79})
+	halt, err := engine.CheckDailyLoss(ctx)
+	if err != nil || halt == nil || halt.Loss != 1100 || halt.StartEquity != 99000 {
+		t.Fatalf("当日亏损超过上限时应熔断: %+v %v", halt, err)
+	}
+}
+
+// exitRestingBroker 是止损市价单挂单等待、其他订单立即成交的测试券商，模拟暂时无法成交的平仓单
+type exitRestingBroker struct {
+	restingBroker
+}
+
+func (b *exitRestingBroker) SubmitOrder(ctx context.Context, order Order) (*Order, error) {
+	if slices.Contains(order.Tags, TagStopLoss) {
+		order.Status = OrderStatusAccepted
+		return &order, nil
+	}
+	return b.fixedPriceBroker.SubmitOrder(ctx, order)
+}
+
+func TestDailyLossHaltCanceledExit(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10, MaxDailyLossPercent: 1})
+	engine.SetBroker(&exitRestingBroker{restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}})
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 100, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if halt, _ := engine.CheckDailyLoss(ctx); halt != nil {
+		t.Fatalf("没有亏损时不应熔断: %+v", halt)
+	}
+	engine.ApplyMarks(map[string]float64{"AAPL": 89})
+	pos, _ := engine.GetPosition(ctx, "AAPL")
+	exit, err := engine.PlaceOrder(ctx, exitRequest(*pos, TagStopLoss))
+	if err != nil || exit.Status != OrderStatusAccepted {
+		t.Fatalf("止损单应挂单等待: %+v %v", exit, err)
+	}
+
+	// 熔断撤销尚未成交的止损单后，持仓没有平仓单，应以市价平掉
+	halt, err := engine.CheckDailyLoss(ctx)
+	if err != nil || halt == nil {
+		t.Fatalf("亏损超过上限时应熔断: %+v %v", halt, err)
+	}
+	if len(halt.CanceledOrders) != 1 || halt.CanceledOrders[0] != exit.ID || len(halt.FlattenOrders) != 1 {
+		t.Errorf("应撤销止损单并平仓: %+v", halt)
+	}
+	if positions, _ := engine.GetPositions(ctx); len(positions) != 0 {
+		t.Errorf("止损单撤销后持仓应被平掉: %+v", positions)
+	}
+}
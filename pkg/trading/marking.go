@@ -0,0 +1,106 @@
+package trading
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// markPrice 返回报价的估值价格：最新成交价，没有成交价时使用买卖中间价（内部函数）
+func markPrice(quote *datasource.Quote) float64 {
+	if quote == nil {
+		return 0
+	}
+	if quote.LastPrice > 0 {
+		return quote.LastPrice
+	}
+	if quote.BidPrice > 0 && quote.AskPrice > 0 {
+		return (quote.BidPrice + quote.AskPrice) / 2
+	}
+	return 0
+}
+
+// markPosition 按价格更新持仓的当前价、市值和未实现盈亏（内部函数）
+func markPosition(pos *Position, price float64, now time.Time) {
+	pos.CurrentPrice = price
+	pos.MarketValue = float64(pos.Quantity) * price
+	pos.UnrealizedPnL = pos.MarketValue - pos.Cost
+	if pos.EntryPrice > 0 {
+		pos.PnLPercent = (price/pos.EntryPrice - 1) * 100 * float64(direction(pos.Quantity))
+	}
+	pos.UpdatedAt = now
+}
+
+// ApplyMarks 按股票的最新价格重新估值持仓，返回价格有变化的持仓数量
+// 可由报价推送直接调用；每个重新估值的持仓在 positions 主题发布 position_marked 事件
+func (e *BaseTradingEngine) ApplyMarks(prices map[string]float64) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	marked := 0
+	for key, pos := range e.positions {
+		price, ok := prices[pos.Symbol]
+		if !ok || price <= 0 || pos.Quantity == 0 || price == pos.CurrentPrice {
+			continue
+		}
+		markPosition(&pos, price, now)
+		e.positions[key] = pos
+		e.publish(events.TopicPositions, EventPositionMarked, pos)
+		marked++
+	}
+	return marked
+}
+
+// MarkPositions 通过数据源管理器批量获取持仓股票的最新报价并重新估值持仓，返回价格有变化的持仓数量
+// 部分股票获取报价失败时仍估值其余持仓，并返回错误
+func (e *BaseTradingEngine) MarkPositions(ctx context.Context) (int, error) {
+	if e.dataManager == nil {
+		return 0, nil
+	}
+
+	e.mu.RLock()
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, pos := range e.positions {
+		if pos.Quantity != 0 && !seen[pos.Symbol] {
+			seen[pos.Symbol] = true
+			symbols = append(symbols, pos.Symbol)
+		}
+	}
+	e.mu.RUnlock()
+	if len(symbols) == 0 {
+		return 0, nil
+	}
+
+	quotes, err := e.dataManager.GetRealTimeQuotes(ctx, symbols)
+
+	prices := make(map[string]float64, len(quotes))
+	for symbol, quote := range quotes {
+		if price := markPrice(quote); price > 0 {
+			prices[symbol] = price
+		}
+	}
+	return e.ApplyMarks(prices), err
+}
+
+// RunPositionMarker 按间隔重新估值持仓，直到上下文取消
+// 当日亏损由止损检查（RunStopMonitor）按估值后的权益检查，这里不重复检查，避免两个循环同时熔断平仓
+func (e *BaseTradingEngine) RunPositionMarker(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.MarkPositions(ctx); err != nil {
+			e.log().Warn("持仓估值失败: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
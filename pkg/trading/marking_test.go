@@ -0,0 +1,117 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// quoteSource 返回固定报价的数据源，未实现的方法不应被调用
+type quoteSource struct {
+	datasource.DataSource
+	quotes map[string]*datasource.Quote
+}
+
+func (s *quoteSource) Name() string    { return "quotes" }
+func (s *quoteSource) IsEnabled() bool { return true }
+
+func (s *quoteSource) GetRealTimeQuote(ctx context.Context, symbol string) (*datasource.Quote, error) {
+	quote, ok := s.quotes[symbol]
+	if !ok {
+		return nil, &datasource.DataSourceError{Source: s.Name(), Code: "NO_DATA", Message: "no quote for " + symbol}
+	}
+	return quote, nil
+}
+
+func (s *quoteSource) GetRealTimeQuotes(ctx context.Context, symbols []string) (map[string]*datasource.Quote, error) {
+	return datasource.FetchQuotes(ctx, s.GetRealTimeQuote, symbols, 1)
+}
+
+func TestMarkPositions(t *testing.T) {
+	ctx := context.Background()
+	source := &quoteSource{quotes: map[string]*datasource.Quote{}}
+	manager := datasource.NewManager()
+	manager.AddDataSource(source)
+
+	engine := NewBaseTradingEngine(manager, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	broker := &fixedPriceBroker{price: 100}
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		if _, err := engine.SubmitOrder(ctx, symbol, 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+			t.Fatalf("买入%s失败: %v", symbol, err)
+		}
+	}
+
+	// MSFT没有报价，AAPL只有买卖价时按中间价估值
+	source.quotes["AAPL"] = &datasource.Quote{Symbol: "AAPL", BidPrice: 109.9, AskPrice: 110.1}
+	marked, err := engine.MarkPositions(ctx)
+	if err == nil {
+		t.Error("缺少报价的股票应返回错误")
+	}
+	if marked != 1 {
+		t.Errorf("估值的持仓数量 = %d, 期望 1", marked)
+	}
+
+	pos, _ := engine.GetPosition(ctx, "AAPL")
+	if math.Abs(pos.CurrentPrice-110) > 1e-9 || math.Abs(pos.MarketValue-1100) > 1e-9 ||
+		math.Abs(pos.UnrealizedPnL-100) > 1e-9 || math.Abs(pos.PnLPercent-10) > 1e-9 {
+		t.Errorf("估值后的持仓不正确: %+v", pos)
+	}
+	if pos, _ := engine.GetPosition(ctx, "MSFT"); pos.CurrentPrice != 100 {
+		t.Errorf("没有报价的持仓不应改变: %+v", pos)
+	}
+	account, _ := engine.GetAccount(ctx)
+	if math.Abs(account.UnrealizedPnL-100) > 1e-9 {
+		t.Errorf("账户未实现盈亏 = %v, 期望 100", account.UnrealizedPnL)
+	}
+
+	// 价格未变化的持仓不重复估值
+	if marked := engine.ApplyMarks(map[string]float64{"AAPL": 110}); marked != 0 {
+		t.Errorf("价格未变化时估值数量 = %d, 期望 0", marked)
+	}
+}
+
+func TestApplyMarksShort(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{PositionMode: PositionModeHedging}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 50})
+	engine.Enable()
+
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "TSLA", Side: OrderSideSell, Type: OrderTypeMarket, Quantity: 10, Strategy: "short"}); err != nil {
+		t.Fatalf("卖空失败: %v", err)
+	}
+	engine.ApplyMarks(map[string]float64{"TSLA": 45})
+
+	pos, err := engine.GetStrategyPosition(ctx, "TSLA", "short")
+	if err != nil {
+		t.Fatalf("获取持仓失败: %v", err)
+	}
+	if math.Abs(pos.MarketValue+450) > 1e-9 || math.Abs(pos.UnrealizedPnL-50) > 1e-9 || math.Abs(pos.PnLPercent-10) > 1e-9 {
+		t.Errorf("空头估值不正确: %+v", pos)
+	}
+}
+
+func TestApplyMarksPartialExit(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+
+	// 买入100股后卖出一半，剩余持仓的成本按开仓均价计算
+	engine.SubmitOrder(ctx, "AAPL", 100, 0, OrderTypeMarket, OrderSideBuy)
+	engine.SubmitOrder(ctx, "AAPL", 50, 0, OrderTypeMarket, OrderSideSell)
+	engine.ApplyMarks(map[string]float64{"AAPL": 101})
+
+	pos, _ := engine.GetPosition(ctx, "AAPL")
+	if pos.Quantity != 50 || math.Abs(pos.Cost-5000) > 1e-9 || math.Abs(pos.UnrealizedPnL-50) > 1e-9 {
+		t.Errorf("部分平仓后的估值不正确: %+v", pos)
+	}
+	account, _ := engine.GetAccount(ctx)
+	if math.Abs(account.UnrealizedPnL-50) > 1e-9 || math.Abs(account.Equity-100050) > 1e-9 {
+		t.Errorf("账户未实现盈亏 = %v 权益 = %v, 期望 50 和 100050", account.UnrealizedPnL, account.Equity)
+	}
+}
@@ -0,0 +1,62 @@
+package trading
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+)
+
+// 自定义元数据的上限，避免集成方把大块数据塞进订单和预写日志
+const (
+	MaxMetadataEntries     = 32  // 每个订单、交易或监控项最多的键数
+	MaxMetadataKeyLength   = 64  // 键的最大长度（字节）
+	MaxMetadataValueLength = 256 // 值的最大长度（字节）
+)
+
+// ValidateMetadata 检查自定义元数据：键不能为空，键数和键值的长度不超过上限
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataEntries {
+		return fmt.Errorf("metadata has %d entries, at most %d allowed", len(metadata), MaxMetadataEntries)
+	}
+	for key, value := range metadata {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("metadata key must not be empty")
+		}
+		if len(key) > MaxMetadataKeyLength {
+			return fmt.Errorf("metadata key %q is longer than %d bytes", key, MaxMetadataKeyLength)
+		}
+		if len(value) > MaxMetadataValueLength {
+			return fmt.Errorf("metadata value of %q is longer than %d bytes", key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// cloneMetadata 复制元数据，使订单、持仓和交易之间不共享同一个map，为空时返回nil（内部函数）
+func cloneMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	return maps.Clone(metadata)
+}
+
+// mergeMetadata 合并开仓和平仓的元数据，同名的键以平仓订单为准（内部函数）
+func mergeMetadata(entry, exit map[string]string) map[string]string {
+	merged := cloneMetadata(entry)
+	if len(exit) > 0 && merged == nil {
+		merged = make(map[string]string, len(exit))
+	}
+	maps.Copy(merged, exit)
+	return merged
+}
+
+// formatMetadata 将元数据按键排序格式化为 key=value，以分号分隔，用于CSV导出（内部函数）
+func formatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
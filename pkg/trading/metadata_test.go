@@ -0,0 +1,119 @@
+package trading
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrderMetadata(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	wal, err := OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy,
+		Metadata: map[string]string{"": "x"}}); GetRejectCode(err) != RejectCodeInvalidParams {
+		t.Errorf("空的元数据键应被拒绝: %v", err)
+	}
+
+	metadata := map[string]string{"signal_id": "sig-1", "source": "scanner"}
+	buy, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy, Metadata: metadata})
+	if err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	metadata["signal_id"] = "changed"
+	if buy.Metadata["signal_id"] != "sig-1" {
+		t.Errorf("订单应保存元数据的副本: %+v", buy.Metadata)
+	}
+	if pos, err := engine.GetPosition(ctx, "AAPL"); err != nil || pos.Metadata["source"] != "scanner" {
+		t.Errorf("开仓订单的元数据应传递到持仓: %+v %v", pos, err)
+	}
+
+	engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideSell,
+		Metadata: map[string]string{"source": "stop", "exit_reason": "target"}})
+	trades, _ := engine.GetTrades(ctx, "", time.Time{}, time.Now())
+	want := map[string]string{"signal_id": "sig-1", "source": "stop", "exit_reason": "target"}
+	if len(trades) != 1 || len(trades[0].Metadata) != 3 || trades[0].Metadata["source"] != "stop" || trades[0].Metadata["signal_id"] != "sig-1" {
+		t.Fatalf("交易应合并开仓和平仓的元数据，同名键以平仓为准: %+v", trades)
+	}
+
+	var buf bytes.Buffer
+	WriteTradesCSV(&buf, trades)
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), ",exit_reason=target;signal_id=sig-1;source=stop") {
+		t.Errorf("导出的元数据应按键排序: %q", buf.String())
+	}
+	wal.Close()
+
+	// 元数据随订单写入预写日志，恢复后重新生成的交易同样带有元数据
+	wal, err = OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	recovered.SetWAL(wal)
+	if _, err := recovered.Recover(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	order, err := recovered.GetOrder(ctx, buy.ID)
+	if err != nil || order.Metadata["signal_id"] != "sig-1" {
+		t.Errorf("恢复的订单应带有元数据: %+v %v", order, err)
+	}
+	trades, _ = recovered.GetTrades(ctx, "", time.Time{}, time.Now())
+	for key, value := range want {
+		if len(trades) != 1 || trades[0].Metadata[key] != value {
+			t.Errorf("恢复的交易元数据不正确: %+v", trades)
+			break
+		}
+	}
+}
+
+func TestValidateMetadata(t *testing.T) {
+	if err := ValidateMetadata(nil); err != nil {
+		t.Errorf("空元数据应有效: %v", err)
+	}
+	if err := ValidateMetadata(map[string]string{"k": strings.Repeat("v", MaxMetadataValueLength+1)}); err == nil {
+		t.Error("过长的值应返回错误")
+	}
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxMetadataEntries; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+	if err := ValidateMetadata(tooMany); err == nil {
+		t.Error("超过键数上限应返回错误")
+	}
+}
+
+func TestWatchlistItemMetadata(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+	watchlist := NewWatchlist(engine, nil)
+
+	if err := watchlist.AddItem(WatchlistItem{Symbol: "AAPL", Quantity: 10, Metadata: map[string]string{" ": "x"}}); err == nil {
+		t.Error("无效的元数据应被拒绝")
+	}
+	item := WatchlistItem{ID: "w1", Symbol: "AAPL", Quantity: 10, IsBuyList: true, Metadata: map[string]string{"alert_id": "tv-42"}}
+	if err := watchlist.AddItem(item); err != nil {
+		t.Fatalf("添加监控项失败: %v", err)
+	}
+	if errs := watchlist.ExecuteWatchlistItems(ctx, []WatchlistItem{item}); len(errs) != 0 {
+		t.Fatalf("执行监控项失败: %v", errs)
+	}
+	executed, _ := watchlist.GetItem("w1")
+	order, err := engine.GetOrder(ctx, executed.OrderID)
+	if err != nil || order.Metadata["alert_id"] != "tv-42" {
+		t.Errorf("监控项的元数据应随订单提交: %+v %v", order, err)
+	}
+}
@@ -0,0 +1,37 @@
+package trading
+
+import (
+	"context"
+)
+
+// OrderHandler 处理下单请求并返回订单
+type OrderHandler func(ctx context.Context, req OrderRequest) (*Order, error)
+
+// OrderMiddleware 包装下单处理函数，可以在下单前检查或修改请求，在下单后执行附加操作
+// 与 PreTradeCheck 不同，中间件在引擎锁之外运行，可以调用引擎的方法
+type OrderMiddleware func(next OrderHandler) OrderHandler
+
+// Use 注册下单中间件，先注册的中间件在外层，最先收到请求、最后收到结果
+func (e *BaseTradingEngine) Use(middlewares ...OrderMiddleware) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.middlewares = append(e.middlewares, middlewares...)
+}
+
+// Reject 使用拒单原因代码包装错误，中间件拒绝请求时使用，未包装的错误记录为 INTERNAL
+func Reject(code RejectCode, err error) error {
+	return reject(code, err)
+}
+
+// orderHandler 按注册顺序组合中间件和引擎的下单处理（内部方法）
+func (e *BaseTradingEngine) orderHandler() OrderHandler {
+	e.mu.RLock()
+	middlewares := e.middlewares
+	e.mu.RUnlock()
+
+	handler := OrderHandler(e.placeOrder)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,58 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOrderMiddleware(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+
+	var calls []string
+	var filled []string
+
+	// 业务规则：禁止交易仙股
+	engine.Use(func(next OrderHandler) OrderHandler {
+		return func(ctx context.Context, req OrderRequest) (*Order, error) {
+			calls = append(calls, "rule")
+			if req.Symbol == "PENNY" {
+				return nil, Reject(RejectCodeRiskLimit, errors.New("penny stocks are not allowed"))
+			}
+			return next(ctx, req)
+		}
+	})
+	// 补充标签并在成交后记录，中间件内可以调用引擎方法
+	engine.Use(func(next OrderHandler) OrderHandler {
+		return func(ctx context.Context, req OrderRequest) (*Order, error) {
+			calls = append(calls, "enrich")
+			req.Tags = append(req.Tags, "desk-a")
+			order, err := next(ctx, req)
+			if err == nil && order.Status == OrderStatusFilled {
+				if _, err := engine.GetPosition(ctx, order.Symbol); err == nil {
+					filled = append(filled, order.Symbol)
+				}
+			}
+			return order, err
+		}
+	})
+
+	order, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	if len(order.Tags) != 1 || order.Tags[0] != "desk-a" || len(filled) != 1 {
+		t.Errorf("中间件应补充标签并在成交后执行: tags=%v filled=%v", order.Tags, filled)
+	}
+	if len(calls) != 2 || calls[0] != "rule" || calls[1] != "enrich" {
+		t.Errorf("中间件调用顺序 = %v, 期望 [rule enrich]", calls)
+	}
+
+	_, err = engine.PlaceOrder(ctx, OrderRequest{Symbol: "PENNY", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy})
+	if GetRejectCode(err) != RejectCodeRiskLimit {
+		t.Errorf("业务规则应以 %s 拒绝: %v", RejectCodeRiskLimit, err)
+	}
+}
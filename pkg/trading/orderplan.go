@@ -0,0 +1,501 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// PlanStatus 表示分批订单计划的状态
+type PlanStatus string
+
+// 分批订单计划状态常量
+const (
+	PlanStatusEntering PlanStatus = "entering" // 入场订单挂单中，尚无成交
+	PlanStatusOpen     PlanStatus = "open"     // 已有入场成交，出场订单由引擎管理
+	PlanStatusClosed   PlanStatus = "closed"   // 入场结束且已全部出场
+	PlanStatusCanceled PlanStatus = "canceled" // 计划被取消，或入场订单全部失败
+)
+
+// 分批订单计划的订单标签，子订单还带有 plan:<计划ID> 标签
+const (
+	TagOrderPlan = "order_plan"
+	TagPlanTrail = "plan_trail" // 跟踪止损触发后平掉剩余数量的市价单
+)
+
+// EventOrderPlanUpdated 是分批订单计划状态变化时在 orders 主题发布的事件类型
+const EventOrderPlanUpdated = "order_plan_updated"
+
+// PlanTranche 表示分批入场或出场中的一批
+type PlanTranche struct {
+	Quantity      int64   `json:"quantity,omitempty"`       // 入场数量，只用于入场
+	Percent       float64 `json:"percent,omitempty"`        // 已成交入场数量的比例，只用于出场
+	Price         float64 `json:"price,omitempty"`          // 限价，为0时按偏移计算
+	OffsetPercent float64 `json:"offset_percent,omitempty"` // 入场相对 ReferencePrice、出场相对入场均价的偏移，正数表示有利方向；入场价格和偏移都为0时使用市价单
+	OrderID       string  `json:"order_id,omitempty"`       // 当前的子订单
+	FilledQty     int64   `json:"filled_qty"`
+	settled       int64   // 出场撤单重挂前的子订单的成交数量（内部字段）
+}
+
+// OrderPlan 表示由引擎管理的分批入场和出场计划，例如在三个价位分批买入，
+// 到达第一个目标价卖出一半，剩余数量跟踪止损。子订单带有 order_plan 和 plan:<ID> 标签
+type OrderPlan struct {
+	ID             string        `json:"id"`
+	Symbol         string        `json:"symbol"`
+	Side           OrderSide     `json:"side"` // 入场方向
+	Strategy       string        `json:"strategy,omitempty"`
+	ReferencePrice float64       `json:"reference_price,omitempty"` // 入场偏移的基准价
+	Entries        []PlanTranche `json:"entries"`
+	Exits          []PlanTranche `json:"exits,omitempty"`         // 比例之和不超过100，剩余数量由跟踪止损或持仓的止损管理
+	TrailPercent   float64       `json:"trail_percent,omitempty"` // 第一批出场成交后（没有出场分批时为入场成交后），剩余数量从最有利价格回撤该比例时以市价平仓
+	Status         PlanStatus    `json:"status"`
+	EntryQty       int64         `json:"entry_qty"` // 已成交的入场数量
+	AvgEntryPrice  float64       `json:"avg_entry_price,omitempty"`
+	ExitQty        int64         `json:"exit_qty"`             // 已成交的出场数量
+	HighWater      float64       `json:"high_water,omitempty"` // 跟踪止损启动后的最有利价格
+	TrailOrderID   string        `json:"trail_order_id,omitempty"`
+	Errors         []string      `json:"errors,omitempty"` // 子订单下单或撤单失败的原因
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// Validate 检查计划是否有效
+func (p OrderPlan) Validate() error {
+	if p.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if p.Side != OrderSideBuy && p.Side != OrderSideSell {
+		return ErrInvalidOrderSide
+	}
+	if len(p.Entries) == 0 {
+		return fmt.Errorf("order plan requires at least one entry")
+	}
+	for i, entry := range p.Entries {
+		if entry.Quantity <= 0 {
+			return fmt.Errorf("entries[%d]: %w", i, ErrInvalidQuantity)
+		}
+		if entry.Price < 0 {
+			return fmt.Errorf("entries[%d]: %w", i, ErrInvalidPrice)
+		}
+		if entry.Price == 0 && entry.OffsetPercent != 0 && p.ReferencePrice <= 0 {
+			return fmt.Errorf("entries[%d]: offset requires a reference price", i)
+		}
+	}
+	total := 0.0
+	for i, exit := range p.Exits {
+		if exit.Percent <= 0 || exit.Percent > 100 {
+			return fmt.Errorf("exits[%d]: percent must be between 0 and 100", i)
+		}
+		if exit.Price < 0 || (exit.Price == 0 && exit.OffsetPercent <= 0) {
+			return fmt.Errorf("exits[%d]: a target price or positive offset is required", i)
+		}
+		total += exit.Percent
+	}
+	if total > 100 {
+		return fmt.Errorf("exit percents add up to %.2f, more than 100", total)
+	}
+	if p.TrailPercent < 0 || p.TrailPercent >= 100 {
+		return fmt.Errorf("trail percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// active 检查计划是否仍由引擎管理（内部方法）
+func (p *OrderPlan) active() bool {
+	return p.Status == PlanStatusEntering || p.Status == PlanStatusOpen
+}
+
+// direction 返回入场方向，多头为1，空头为-1（内部方法）
+func (p *OrderPlan) direction() float64 {
+	if p.Side == OrderSideSell {
+		return -1
+	}
+	return 1
+}
+
+// exitSide 返回出场的订单方向（内部方法）
+func (p *OrderPlan) exitSide() OrderSide {
+	if p.Side == OrderSideSell {
+		return OrderSideBuy
+	}
+	return OrderSideSell
+}
+
+// copy 返回计划的副本，分批列表不与原计划共享（内部方法）
+func (p *OrderPlan) copy() OrderPlan {
+	copied := *p
+	copied.Entries = append([]PlanTranche(nil), p.Entries...)
+	copied.Exits = append([]PlanTranche(nil), p.Exits...)
+	copied.Errors = append([]string(nil), p.Errors...)
+	return copied
+}
+
+// planTag 返回计划子订单的标签（内部函数）
+func planTag(id string) string {
+	return "plan:" + id
+}
+
+// planOf 返回订单所属的计划ID，不属于计划时为空（内部函数）
+func planOf(tags []string) string {
+	for _, tag := range tags {
+		if id, ok := strings.CutPrefix(tag, "plan:"); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// SubmitOrderPlan 校验计划并提交入场子订单，返回计划的当前状态
+// 之后由 CheckOrderPlans 根据成交挂出和调整出场订单；所有入场订单都被拒绝时返回错误
+func (e *BaseTradingEngine) SubmitOrderPlan(ctx context.Context, plan OrderPlan) (*OrderPlan, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, reject(RejectCodeInvalidParams, err)
+	}
+
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+
+	now := e.now()
+	e.mu.Lock()
+	plan.ID = e.newID("plan")
+	e.mu.Unlock()
+	plan.Status = PlanStatusEntering
+	plan.CreatedAt, plan.UpdatedAt = now, now
+	plan.EntryQty, plan.ExitQty, plan.AvgEntryPrice, plan.HighWater, plan.TrailOrderID = 0, 0, 0, 0, ""
+	plan.Entries = append([]PlanTranche(nil), plan.Entries...)
+	plan.Exits = append([]PlanTranche(nil), plan.Exits...)
+
+	placed := 0
+	dir := plan.direction()
+	for i := range plan.Entries {
+		entry := &plan.Entries[i]
+		entry.OrderID, entry.FilledQty, entry.settled = "", 0, 0
+		req := e.planRequest(&plan, plan.Side, entry.Quantity, TagOrderPlan)
+		if price := entry.Price; price > 0 {
+			req.Type, req.Price = OrderTypeLimit, price
+		} else if entry.OffsetPercent != 0 {
+			req.Type, req.Price = OrderTypeLimit, plan.ReferencePrice*(1-dir*entry.OffsetPercent/100)
+		}
+		order, err := e.PlaceOrder(ctx, req)
+		if err != nil {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("entries[%d]: %v", i, err))
+			continue
+		}
+		entry.OrderID = order.ID
+		placed++
+	}
+	if placed == 0 {
+		return nil, fmt.Errorf("all entries of the order plan were rejected: %s", strings.Join(plan.Errors, "; "))
+	}
+
+	if e.plans == nil {
+		e.plans = make(map[string]*OrderPlan)
+	}
+	e.plans[plan.ID] = &plan
+	// 立即成交的入场订单马上挂出出场订单
+	e.syncPlan(ctx, &plan, 0, false)
+	e.publishPlan(&plan)
+	result := plan.copy()
+	return &result, nil
+}
+
+// GetOrderPlan 返回分批订单计划
+func (e *BaseTradingEngine) GetOrderPlan(id string) (*OrderPlan, error) {
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+
+	plan, exists := e.plans[id]
+	if !exists {
+		return nil, fmt.Errorf("order plan %s not found", id)
+	}
+	result := plan.copy()
+	return &result, nil
+}
+
+// GetOrderPlans 返回按创建时间排序的所有分批订单计划
+func (e *BaseTradingEngine) GetOrderPlans() []OrderPlan {
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+
+	plans := make([]OrderPlan, 0, len(e.plans))
+	for _, plan := range e.plans {
+		plans = append(plans, plan.copy())
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].CreatedAt.Before(plans[j].CreatedAt)
+	})
+	return plans
+}
+
+// CancelOrderPlan 撤销计划所有未完成的子订单并停止管理，已成交的数量作为普通持仓保留
+func (e *BaseTradingEngine) CancelOrderPlan(ctx context.Context, id string) error {
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+
+	plan, exists := e.plans[id]
+	if !exists {
+		return fmt.Errorf("order plan %s not found", id)
+	}
+	if !plan.active() {
+		return fmt.Errorf("order plan %s is already %s", id, plan.Status)
+	}
+	e.refreshPlan(plan)
+	e.cancelPlanOrders(ctx, plan, plan.Entries)
+	e.cancelPlanOrders(ctx, plan, plan.Exits)
+	plan.Status = PlanStatusCanceled
+	plan.UpdatedAt = e.now()
+	e.publishPlan(plan)
+	return nil
+}
+
+// CheckOrderPlans 按最新报价管理进行中的分批订单计划：根据入场成交挂出和调整出场订单，
+// 更新跟踪止损，并在全部出场后结束计划。返回状态有变化的计划
+func (e *BaseTradingEngine) CheckOrderPlans(ctx context.Context) ([]OrderPlan, error) {
+	return e.checkOrderPlans(ctx, func(symbol string) (float64, bool) {
+		if e.dataManager == nil {
+			return 0, false
+		}
+		quote, err := e.dataManager.GetRealTimeQuote(ctx, symbol)
+		if err != nil || quote == nil || quote.LastPrice <= 0 {
+			return 0, false
+		}
+		return quote.LastPrice, true
+	})
+}
+
+// checkOrderPlans 使用给定的报价函数管理分批订单计划（内部方法）
+func (e *BaseTradingEngine) checkOrderPlans(ctx context.Context, price func(symbol string) (float64, bool)) ([]OrderPlan, error) {
+	if !e.IsEnabled() {
+		return nil, nil
+	}
+
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+
+	var changed []OrderPlan
+	for _, plan := range e.plans {
+		if !plan.active() {
+			continue
+		}
+		before := plan.copy()
+		last, ok := price(plan.Symbol)
+		e.syncPlan(ctx, plan, last, ok)
+		if plan.Status != before.Status || plan.EntryQty != before.EntryQty || plan.ExitQty != before.ExitQty || plan.TrailOrderID != before.TrailOrderID {
+			plan.UpdatedAt = e.now()
+			e.publishPlan(plan)
+			changed = append(changed, plan.copy())
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].CreatedAt.Before(changed[j].CreatedAt)
+	})
+	return changed, nil
+}
+
+// planRequest 返回计划子订单的市价下单请求（内部方法）
+func (e *BaseTradingEngine) planRequest(plan *OrderPlan, side OrderSide, quantity int64, tag string) OrderRequest {
+	tags := []string{TagOrderPlan, planTag(plan.ID)}
+	if tag != TagOrderPlan {
+		tags = append(tags, tag)
+	}
+	return OrderRequest{
+		Symbol:   plan.Symbol,
+		Side:     side,
+		Type:     OrderTypeMarket,
+		Quantity: quantity,
+		Strategy: plan.Strategy,
+		Tags:     tags,
+	}
+}
+
+// refreshPlan 根据子订单的最新状态更新计划的成交数量，返回入场是否已经结束（内部方法，调用方持有 planMu）
+func (e *BaseTradingEngine) refreshPlan(plan *OrderPlan) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	entriesDone := true
+	plan.EntryQty = 0
+	cost := 0.0
+	for i := range plan.Entries {
+		entry := &plan.Entries[i]
+		if order, ok := e.orders[entry.OrderID]; ok {
+			entry.FilledQty = order.FilledQty
+			cost += float64(order.FilledQty) * order.AvgFillPrice
+			if isOpenOrder(order) {
+				entriesDone = false
+			}
+		}
+		plan.EntryQty += entry.FilledQty
+	}
+	if plan.EntryQty > 0 {
+		plan.AvgEntryPrice = cost / float64(plan.EntryQty)
+	}
+
+	plan.ExitQty = 0
+	for i := range plan.Exits {
+		exit := &plan.Exits[i]
+		exit.FilledQty = exit.settled
+		if order, ok := e.orders[exit.OrderID]; ok {
+			exit.FilledQty += order.FilledQty
+		}
+		plan.ExitQty += exit.FilledQty
+	}
+	if order, ok := e.orders[plan.TrailOrderID]; ok {
+		plan.ExitQty += order.FilledQty
+	}
+	return entriesDone
+}
+
+// openOrder 返回仍未完成的子订单（内部方法）
+func (e *BaseTradingEngine) openOrder(id string) (Order, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	order, ok := e.orders[id]
+	return order, ok && isOpenOrder(order)
+}
+
+// cancelPlanOrders 撤销各批中未完成的子订单（内部方法，调用方持有 planMu）
+func (e *BaseTradingEngine) cancelPlanOrders(ctx context.Context, plan *OrderPlan, tranches []PlanTranche) {
+	for i := range tranches {
+		e.cancelTranche(ctx, plan, &tranches[i])
+	}
+}
+
+// cancelTranche 撤销一批的当前子订单，失败时记录到计划的错误中（内部方法，调用方持有 planMu）
+func (e *BaseTradingEngine) cancelTranche(ctx context.Context, plan *OrderPlan, tranche *PlanTranche) error {
+	if _, open := e.openOrder(tranche.OrderID); !open {
+		return nil
+	}
+	if err := e.CancelOrder(ctx, tranche.OrderID); err != nil {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("cancel %s: %v", tranche.OrderID, err))
+		return err
+	}
+	return nil
+}
+
+// syncPlan 根据成交和最新价格推进计划（内部方法，调用方持有 planMu）
+func (e *BaseTradingEngine) syncPlan(ctx context.Context, plan *OrderPlan, last float64, hasPrice bool) {
+	entriesDone := e.refreshPlan(plan)
+	switch {
+	case plan.EntryQty == 0 && entriesDone:
+		plan.Status = PlanStatusCanceled
+		return
+	case plan.EntryQty > 0 && plan.Status == PlanStatusEntering:
+		plan.Status = PlanStatusOpen
+	}
+	if plan.EntryQty == 0 {
+		return
+	}
+
+	if remaining := plan.EntryQty - plan.ExitQty; remaining <= 0 {
+		if entriesDone {
+			e.cancelPlanOrders(ctx, plan, plan.Exits)
+			plan.Status = PlanStatusClosed
+		}
+		return
+	}
+	if plan.TrailOrderID != "" {
+		// 跟踪止损已触发，等待平仓单成交
+		return
+	}
+
+	if e.trailPlan(ctx, plan, last, hasPrice) {
+		return
+	}
+	e.placePlanExits(ctx, plan)
+}
+
+// placePlanExits 按已成交的入场数量挂出或调整各批出场的限价单（内部方法，调用方持有 planMu）
+func (e *BaseTradingEngine) placePlanExits(ctx context.Context, plan *OrderPlan) {
+	dir := plan.direction()
+	for i := range plan.Exits {
+		exit := &plan.Exits[i]
+		target := int64(math.Floor(float64(plan.EntryQty) * exit.Percent / 100))
+		want := target - exit.FilledQty
+
+		if order, open := e.openOrder(exit.OrderID); open {
+			if order.Quantity-order.FilledQty == want {
+				continue
+			}
+			// 入场数量变化后撤单，按新的数量重新挂单，原订单的成交计入该批
+			if e.cancelTranche(ctx, plan, exit) != nil {
+				continue
+			}
+			e.refreshPlan(plan)
+			exit.settled, exit.OrderID = exit.FilledQty, ""
+			want = target - exit.FilledQty
+		}
+		if want <= 0 {
+			continue
+		}
+
+		price := exit.Price
+		if price <= 0 {
+			price = plan.AvgEntryPrice * (1 + dir*exit.OffsetPercent/100)
+		}
+		req := e.planRequest(plan, plan.exitSide(), want, TagOrderPlan)
+		req.Type, req.Price = OrderTypeLimit, price
+		order, err := e.PlaceOrder(ctx, req)
+		if err != nil {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("exits[%d]: %v", i, err))
+			continue
+		}
+		exit.OrderID = order.ID
+	}
+	e.refreshPlan(plan)
+}
+
+// trailPlan 更新跟踪止损，触发时撤销其余子订单并以市价平掉剩余数量，返回是否已触发（内部方法，调用方持有 planMu）
+func (e *BaseTradingEngine) trailPlan(ctx context.Context, plan *OrderPlan, last float64, hasPrice bool) bool {
+	if plan.TrailPercent <= 0 || !hasPrice || last <= 0 {
+		return false
+	}
+	armed := len(plan.Exits) == 0
+	for _, exit := range plan.Exits {
+		if exit.FilledQty > 0 {
+			armed = true
+		}
+	}
+	if !armed {
+		return false
+	}
+
+	dir := plan.direction()
+	if plan.HighWater == 0 || (last-plan.HighWater)*dir > 0 {
+		plan.HighWater = last
+	}
+	if (plan.HighWater-last)*dir < plan.HighWater*plan.TrailPercent/100 {
+		return false
+	}
+
+	e.cancelPlanOrders(ctx, plan, plan.Entries)
+	e.cancelPlanOrders(ctx, plan, plan.Exits)
+	e.refreshPlan(plan)
+	remaining := plan.EntryQty - plan.ExitQty
+	if remaining <= 0 {
+		return true
+	}
+	order, err := e.PlaceOrder(ctx, e.planRequest(plan, plan.exitSide(), remaining, TagPlanTrail))
+	if err != nil {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("trail: %v", err))
+		return true
+	}
+	plan.TrailOrderID = order.ID
+	e.refreshPlan(plan)
+	if plan.ExitQty >= plan.EntryQty {
+		plan.Status = PlanStatusClosed
+	}
+	return true
+}
+
+// publishPlan 发布计划状态变化事件（内部方法）
+func (e *BaseTradingEngine) publishPlan(plan *OrderPlan) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	e.publish(events.TopicOrders, EventOrderPlanUpdated, plan.copy())
+}
@@ -0,0 +1,99 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestOrderPlan(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10, DuplicateWindowSeconds: 300})
+	engine.SetBroker(&restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}})
+	engine.Enable()
+
+	// 市价买入10股，98和95各挂10股；入场均价上方10%卖出一半，剩余回撤5%平仓
+	plan, err := engine.SubmitOrderPlan(ctx, OrderPlan{
+		Symbol:         "AAPL",
+		Side:           OrderSideBuy,
+		Strategy:       "ladder",
+		ReferencePrice: 100,
+		Entries:        []PlanTranche{{Quantity: 10}, {Quantity: 10, OffsetPercent: 2}, {Quantity: 10, Price: 95}},
+		Exits:          []PlanTranche{{Percent: 50, OffsetPercent: 10}},
+		TrailPercent:   5,
+	})
+	if err != nil {
+		t.Fatalf("提交计划失败: %v", err)
+	}
+	if plan.Status != PlanStatusOpen || plan.EntryQty != 10 || len(plan.Errors) != 0 {
+		t.Fatalf("第一批入场应已成交，同一计划的子订单不算重复开仓: %+v", plan)
+	}
+	second, _ := engine.GetOrder(ctx, plan.Entries[1].OrderID)
+	exit, _ := engine.GetOrder(ctx, plan.Exits[0].OrderID)
+	if second.Price != 98 || exit.Quantity != 5 || math.Abs(exit.Price-110) > 1e-9 || exit.Side != OrderSideSell {
+		t.Fatalf("子订单不正确: entry=%+v exit=%+v", second, exit)
+	}
+
+	// 第二批成交后按20股重新挂出场单
+	if err := engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: Order{ID: second.ID, Status: OrderStatusFilled, FilledQty: 10, AvgFillPrice: 98}}); err != nil {
+		t.Fatalf("应用成交失败: %v", err)
+	}
+	price := 99.0
+	quote := func(string) (float64, bool) { return price, true }
+	changed, _ := engine.checkOrderPlans(ctx, quote)
+	if len(changed) != 1 || changed[0].EntryQty != 20 || math.Abs(changed[0].AvgEntryPrice-99) > 1e-9 {
+		t.Fatalf("入场成交应更新计划: %+v", changed)
+	}
+	if old, _ := engine.GetOrder(ctx, exit.ID); old.Status != OrderStatusCanceled {
+		t.Errorf("原出场单应被撤销: %+v", old)
+	}
+	plan, _ = engine.GetOrderPlan(plan.ID)
+	exit, _ = engine.GetOrder(ctx, plan.Exits[0].OrderID)
+	if exit.Quantity != 10 || math.Abs(exit.Price-108.9) > 1e-9 {
+		t.Fatalf("出场单应按20股和新的均价重新挂出: %+v", exit)
+	}
+
+	// 第一个目标成交后剩余数量开始跟踪，从最高价回撤5%时撤销剩余入场并平仓
+	engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: Order{ID: exit.ID, Status: OrderStatusFilled, FilledQty: 10, AvgFillPrice: 108.9}})
+	price = 110
+	engine.checkOrderPlans(ctx, quote)
+	price = 104
+	changed, _ = engine.checkOrderPlans(ctx, quote)
+	if len(changed) != 1 || changed[0].Status != PlanStatusClosed || changed[0].TrailOrderID == "" || changed[0].HighWater != 110 {
+		t.Fatalf("跟踪止损应平掉剩余数量并结束计划: %+v", changed)
+	}
+	if third, _ := engine.GetOrder(ctx, plan.Entries[2].OrderID); third.Status != OrderStatusCanceled {
+		t.Errorf("未成交的入场单应被撤销: %+v", third)
+	}
+	if _, err := engine.GetPosition(ctx, "AAPL"); err == nil {
+		t.Error("计划结束后持仓应已平仓")
+	}
+	if err := engine.CancelOrderPlan(ctx, plan.ID); err == nil {
+		t.Error("已结束的计划不能取消")
+	}
+}
+
+func TestCancelOrderPlan(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}})
+	engine.Enable()
+
+	if _, err := engine.SubmitOrderPlan(ctx, OrderPlan{Symbol: "AAPL", Side: OrderSideBuy, Entries: []PlanTranche{{Quantity: 10, OffsetPercent: 1}}}); err == nil {
+		t.Error("没有基准价时偏移入场应返回错误")
+	}
+
+	plan, err := engine.SubmitOrderPlan(ctx, OrderPlan{Symbol: "AAPL", Side: OrderSideBuy, Entries: []PlanTranche{{Quantity: 10, Price: 90}, {Quantity: 10, Price: 85}}})
+	if err != nil || plan.Status != PlanStatusEntering {
+		t.Fatalf("提交计划失败: %+v %v", plan, err)
+	}
+	if err := engine.CancelOrderPlan(ctx, plan.ID); err != nil {
+		t.Fatalf("取消计划失败: %v", err)
+	}
+	if open, _ := engine.GetOpenOrders(ctx); len(open) != 0 {
+		t.Errorf("取消后子订单应被撤销: %+v", open)
+	}
+	if plans := engine.GetOrderPlans(); len(plans) != 1 || plans[0].Status != PlanStatusCanceled {
+		t.Errorf("计划状态应为已取消: %+v", plans)
+	}
+}
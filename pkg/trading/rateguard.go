@@ -0,0 +1,161 @@
+package trading
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// RateGuardConfig 表示下单频率异常检测的配置
+// 策略在时间窗口内提交的订单或撤单超过上限时自动暂停，避免程序错误向券商大量发送订单
+type RateGuardConfig struct {
+	Enabled       bool `json:"enabled" yaml:"enabled"`
+	WindowSeconds int  `json:"window_seconds" yaml:"window_seconds"` // 统计窗口，默认60秒
+	MaxOrders     int  `json:"max_orders" yaml:"max_orders"`         // 窗口内每个策略最多提交的订单数，为0时不检查
+	MaxCancels    int  `json:"max_cancels" yaml:"max_cancels"`       // 窗口内每个策略最多的撤单数，为0时不检查
+}
+
+// Validate 检查配置是否有效
+func (c RateGuardConfig) Validate() error {
+	if c.WindowSeconds < 0 || c.MaxOrders < 0 || c.MaxCancels < 0 {
+		return fmt.Errorf("values must not be negative")
+	}
+	if c.Enabled && c.MaxOrders == 0 && c.MaxCancels == 0 {
+		return fmt.Errorf("max_orders or max_cancels is required when enabled")
+	}
+	return nil
+}
+
+// window 返回统计窗口（内部方法）
+func (c RateGuardConfig) window() time.Duration {
+	if c.WindowSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+// StrategyPause 表示被暂停的策略，没有策略归属的订单以空字符串表示
+type StrategyPause struct {
+	Strategy  string    `json:"strategy"`
+	Reason    string    `json:"reason"`
+	PausedAt  time.Time `json:"paused_at"`
+	Automatic bool      `json:"automatic"` // 由下单频率异常检测自动暂停
+}
+
+// SetRateGuard 设置下单频率异常检测
+func (e *BaseTradingEngine) SetRateGuard(config RateGuardConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rateGuard = config
+}
+
+// PauseStrategy 暂停策略，暂停期间该策略只能提交减仓订单
+func (e *BaseTradingEngine) PauseStrategy(strategy, reason string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pauseStrategy(strategy, reason, false, e.now())
+}
+
+// ResumeStrategy 恢复被暂停的策略，并清空其下单频率统计
+func (e *BaseTradingEngine) ResumeStrategy(strategy string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pause, exists := e.paused[strategy]
+	if !exists {
+		return fmt.Errorf("strategy %q is not paused", strategy)
+	}
+	delete(e.paused, strategy)
+	delete(e.orderTimes, strategy)
+	delete(e.cancelTimes, strategy)
+	e.publish(events.TopicRisk, EventStrategyResumed, pause)
+	return nil
+}
+
+// GetPausedStrategies 返回按策略名称排序的暂停策略
+func (e *BaseTradingEngine) GetPausedStrategies() []StrategyPause {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	pauses := make([]StrategyPause, 0, len(e.paused))
+	for _, pause := range e.paused {
+		pauses = append(pauses, pause)
+	}
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].Strategy < pauses[j].Strategy
+	})
+	return pauses
+}
+
+// pauseStrategy 暂停策略并发布告警事件（内部方法，调用方持锁）
+func (e *BaseTradingEngine) pauseStrategy(strategy, reason string, automatic bool, now time.Time) {
+	if _, exists := e.paused[strategy]; exists {
+		return
+	}
+	if e.paused == nil {
+		e.paused = make(map[string]StrategyPause)
+	}
+	pause := StrategyPause{Strategy: strategy, Reason: reason, PausedAt: now, Automatic: automatic}
+	e.paused[strategy] = pause
+	e.publish(events.TopicRisk, EventStrategyPaused, pause)
+}
+
+// checkPaused 检查订单所属策略是否被暂停，减仓订单不受暂停影响（内部方法，调用方持锁）
+func (e *BaseTradingEngine) checkPaused(req OrderRequest) error {
+	pause, exists := e.paused[req.Strategy]
+	if !exists || e.reducesPosition(req) {
+		return nil
+	}
+	return fmt.Errorf("%w: strategy %q paused: %s", ErrStrategyPaused, req.Strategy, pause.Reason)
+}
+
+// countEvent 在时间窗口内记录一次下单或撤单，返回窗口内的次数（内部函数）
+func countEvent(times map[string][]time.Time, strategy string, now time.Time, window time.Duration) int {
+	cutoff := now.Add(-window)
+	kept := times[strategy][:0]
+	for _, t := range times[strategy] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	times[strategy] = kept
+	return len(kept)
+}
+
+// recordOrderRate 记录策略提交的订单，超过上限时暂停策略（内部方法，调用方持锁）
+func (e *BaseTradingEngine) recordOrderRate(strategy string, now time.Time) error {
+	if !e.rateGuard.Enabled || e.rateGuard.MaxOrders <= 0 {
+		return nil
+	}
+	if e.orderTimes == nil {
+		e.orderTimes = make(map[string][]time.Time)
+	}
+
+	window := e.rateGuard.window()
+	count := countEvent(e.orderTimes, strategy, now, window)
+	if count <= e.rateGuard.MaxOrders {
+		return nil
+	}
+	reason := fmt.Sprintf("%d orders in %s exceeds limit %d", count, window, e.rateGuard.MaxOrders)
+	e.pauseStrategy(strategy, reason, true, now)
+	return fmt.Errorf("%w: strategy %q paused: %s", ErrStrategyPaused, strategy, reason)
+}
+
+// recordCancelRate 记录策略的撤单，超过上限时暂停策略，撤单本身不受影响（内部方法，调用方持锁）
+func (e *BaseTradingEngine) recordCancelRate(strategy string, now time.Time) {
+	if !e.rateGuard.Enabled || e.rateGuard.MaxCancels <= 0 {
+		return
+	}
+	if e.cancelTimes == nil {
+		e.cancelTimes = make(map[string][]time.Time)
+	}
+
+	window := e.rateGuard.window()
+	count := countEvent(e.cancelTimes, strategy, now, window)
+	if count > e.rateGuard.MaxCancels {
+		e.pauseStrategy(strategy, fmt.Sprintf("%d cancels in %s exceeds limit %d", count, window, e.rateGuard.MaxCancels), true, now)
+	}
+}
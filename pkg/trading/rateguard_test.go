@@ -0,0 +1,62 @@
+package trading
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+func TestRateGuard(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&unackedBroker{})
+	engine.SetRateGuard(RateGuardConfig{Enabled: true, WindowSeconds: 60, MaxOrders: 3, MaxCancels: 2})
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicRisk)
+	defer sub.Close()
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	place := func(strategy string) (*Order, error) {
+		return engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Side: OrderSideBuy, Type: OrderTypeLimit, Price: 10, Quantity: 1, Strategy: strategy})
+	}
+
+	var orders []*Order
+	for i := 0; i < 3; i++ {
+		order, err := place("runaway")
+		if err != nil {
+			t.Fatalf("第%d个订单被拒绝: %v", i+1, err)
+		}
+		orders = append(orders, order)
+	}
+	if _, err := place("runaway"); GetRejectCode(err) != RejectCodeStrategyPaused {
+		t.Fatalf("超过下单频率的订单应被拒绝: %v", err)
+	}
+	if _, err := place("runaway"); GetRejectCode(err) != RejectCodeStrategyPaused {
+		t.Errorf("暂停期间的订单应被拒绝: %v", err)
+	}
+	if _, err := place("other"); err != nil {
+		t.Errorf("其他策略不受影响: %v", err)
+	}
+
+	evt := <-sub.C
+	pause, ok := evt.Payload.(StrategyPause)
+	if evt.Type != EventStrategyPaused || !ok || pause.Strategy != "runaway" || !pause.Automatic {
+		t.Errorf("暂停事件不正确: %+v", evt)
+	}
+
+	// 恢复后重新统计，撤单过多再次暂停，撤单本身仍然执行
+	if err := engine.ResumeStrategy("runaway"); err != nil {
+		t.Fatalf("恢复策略失败: %v", err)
+	}
+	for _, order := range orders {
+		if err := engine.CancelOrder(ctx, order.ID); err != nil {
+			t.Errorf("撤单失败: %v", err)
+		}
+	}
+	paused := engine.GetPausedStrategies()
+	if len(paused) != 1 || paused[0].Strategy != "runaway" {
+		t.Errorf("撤单过多后策略应被暂停: %+v", paused)
+	}
+}
@@ -0,0 +1,155 @@
+package trading
+
+import (
+	"fmt"
+)
+
+// RecoveryReport 表示从预写日志恢复引擎状态的结果
+type RecoveryReport struct {
+	Records    int     `json:"records"`
+	Orders     int     `json:"orders"`
+	Positions  int     `json:"positions"`
+	Trades     int     `json:"trades"`
+	Unresolved []Order `json:"unresolved,omitempty"` // 已生成但没有券商结果的订单，需要与券商对账
+}
+
+// SetWAL 设置预写日志，之后所有改变引擎状态的事件都会先写入日志
+func (e *BaseTradingEngine) SetWAL(wal WAL) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.wal = wal
+}
+
+// Recover 回放预写日志重建订单、持仓、交易和账户盈亏，应在启用引擎之前调用
+// 回放过程中不会发布事件也不会再次写入日志；引擎启用状态不会被恢复，进行中的条件订单链在启用后继续管理
+func (e *BaseTradingEngine) Recover() (*RecoveryReport, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.wal == nil {
+		return nil, fmt.Errorf("wal is not configured")
+	}
+
+	e.replaying = true
+	defer func() { e.replaying = false }()
+
+	report := &RecoveryReport{}
+	err := e.wal.Replay(func(record WALRecord) error {
+		report.Records++
+		return e.applyRecord(record)
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to replay wal: %v", err)
+	}
+
+	for _, order := range e.orders {
+		if order.Status == OrderStatusPending {
+			report.Unresolved = append(report.Unresolved, order)
+		}
+	}
+	report.Orders = len(e.orders)
+	report.Positions = len(e.positions)
+	report.Trades = len(e.trades)
+
+	return report, nil
+}
+
+// record 将状态变化写入预写日志（内部方法，调用方持锁）
+func (e *BaseTradingEngine) record(recordType string, order *Order, limits *TradingLimits) error {
+	if e.wal == nil || e.replaying {
+		return nil
+	}
+
+	record := WALRecord{Type: recordType, Timestamp: e.now(), Limits: limits}
+	if order != nil {
+		copied := *order
+		record.Order = &copied
+	}
+	return e.wal.Append(record)
+}
+
+// recordOrLog 写入预写日志，失败时只输出错误（内部方法，调用方持锁）
+// 用于券商已经执行、无法回退的状态变化
+func (e *BaseTradingEngine) recordOrLog(recordType string, order *Order, limits *TradingLimits) {
+	if err := e.record(recordType, order, limits); err != nil {
+		e.logLocked().Error("写入预写日志%s失败: %v", recordType, err)
+	}
+}
+
+// applyRecord 将一条日志记录应用到引擎状态（内部方法，调用方持锁）
+func (e *BaseTradingEngine) applyRecord(record WALRecord) error {
+	switch record.Type {
+	case WALOrderSubmitted, WALOrderAccepted, WALOrderCanceled:
+		if record.Order == nil {
+			return fmt.Errorf("wal record %d (%s) has no order", record.Seq, record.Type)
+		}
+		e.orders[record.Order.ID] = *record.Order
+
+	case WALOrderRejected:
+		if record.Order != nil {
+			delete(e.orders, record.Order.ID)
+		}
+
+	case WALOrderFilled:
+		if record.Order == nil {
+			return fmt.Errorf("wal record %d (%s) has no order", record.Seq, record.Type)
+		}
+		e.orders[record.Order.ID] = *record.Order
+		e.updatePosition(*record.Order)
+
+	case WALOrderUpdated:
+		if record.Order == nil {
+			return fmt.Errorf("wal record %d (%s) has no order", record.Seq, record.Type)
+		}
+		e.orders[record.Order.ID] = *record.Order
+		if record.Fill != nil {
+			e.updatePosition(*record.Fill)
+		}
+
+	case WALTradeImported:
+		if record.Trade == nil {
+			return fmt.Errorf("wal record %d (%s) has no trade", record.Seq, record.Type)
+		}
+		e.importTrade(*record.Trade)
+
+	case WALTradeReviewed:
+		if record.Trade == nil || record.Review == nil {
+			return fmt.Errorf("wal record %d (%s) has no trade review", record.Seq, record.Type)
+		}
+		if i := e.tradeIndex(*record.Trade); i >= 0 {
+			e.applyReview(&e.trades[i], *record.Review, record.Timestamp)
+		}
+
+	case WALSymbolRestricted, WALSymbolUnrestricted:
+		if record.Restriction == nil {
+			return fmt.Errorf("wal record %d (%s) has no restriction", record.Seq, record.Type)
+		}
+		e.applyRestriction(record.Type, *record.Restriction)
+
+	case WALOrderChainUpdated:
+		if record.Chain == nil {
+			return fmt.Errorf("wal record %d (%s) has no order chain", record.Seq, record.Type)
+		}
+		e.applyChain(*record.Chain)
+
+	case WALCashRecorded:
+		if record.Ledger == nil {
+			return fmt.Errorf("wal record %d (%s) has no ledger entry", record.Seq, record.Type)
+		}
+		e.appendLedger(*record.Ledger)
+		if record.Dividend != nil {
+			e.applyEntitlement(*record.Dividend)
+		}
+
+	case WALDividendEntitled:
+		if record.Dividend == nil {
+			return fmt.Errorf("wal record %d (%s) has no dividend", record.Seq, record.Type)
+		}
+		e.applyEntitlement(*record.Dividend)
+
+	case WALEngineEnabled, WALEngineDisabled, WALLimitsUpdated:
+		// 仅用于审计，不恢复
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package trading
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+	"github.com/yourusername/qhft-system/pkg/logger"
+)
+
+func TestRejectionLogRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 8, 15, 0, 0, 0, time.UTC)
+	sysLogger, _ := logger.NewCaptureLogger(logger.LogLevelInfo)
+	tradeLogger, err := logger.NewTradeLogger(t.TempDir(), sysLogger)
+	if err != nil {
+		t.Fatalf("创建交易日志失败: %v", err)
+	}
+	defer tradeLogger.Close()
+
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetClock(clock.NewManual(now))
+	engine.SetBroker(&restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}, rejectResting: true})
+	engine.SetTradeLogger(tradeLogger)
+	engine.Enable()
+
+	// 参数无效的订单由引擎拒绝，券商拒绝的订单带券商拒单代码
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 0, Type: OrderTypeMarket, Side: OrderSideBuy, Strategy: "momentum", ClientOrderID: "c1"}); GetRejectCode(err) != RejectCodeInvalidParams {
+		t.Fatalf("数量为0的订单应以 %s 拒绝: %v", RejectCodeInvalidParams, err)
+	}
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "MSFT", Quantity: 5, Price: 300, Type: OrderTypeLimit, Side: OrderSideSell, Metadata: map[string]string{"source": "test"}}); GetRejectCode(err) != RejectCodeBrokerReject {
+		t.Fatalf("券商拒绝的订单应以 %s 拒绝: %v", RejectCodeBrokerReject, err)
+	}
+
+	rejections, err := tradeLogger.GetRejections(now)
+	if err != nil {
+		t.Fatalf("读取拒单日志失败: %v", err)
+	}
+	if len(rejections) != 2 {
+		t.Fatalf("应记录两条拒单: %+v", rejections)
+	}
+	invalid, broker := rejections[0], rejections[1]
+	if invalid.Code != string(RejectCodeInvalidParams) || invalid.Symbol != "AAPL" || invalid.Strategy != "momentum" || invalid.ClientOrderID != "c1" || invalid.Reason == "" || !invalid.Timestamp.Equal(now) {
+		t.Errorf("参数无效的拒单记录不正确: %+v", invalid)
+	}
+	if broker.Code != string(RejectCodeBrokerReject) || broker.Symbol != "MSFT" || broker.Quantity != 5 || broker.Price != 300 ||
+		broker.OrderType != string(OrderTypeLimit) || broker.Side != string(OrderSideSell) || broker.Metadata["source"] != "test" {
+		t.Errorf("券商拒单记录不正确: %+v", broker)
+	}
+}
@@ -0,0 +1,138 @@
+package trading
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RestrictedSymbol 表示受限股票，如停牌、难以借券或合规限制的股票
+type RestrictedSymbol struct {
+	Symbol    string     `json:"symbol"`
+	Reason    string     `json:"reason"`
+	AddedAt   time.Time  `json:"added_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // 为空时一直有效，直到被移除
+}
+
+// Active 检查限制在给定时间是否有效
+func (r RestrictedSymbol) Active(now time.Time) bool {
+	return r.ExpiresAt == nil || now.Before(*r.ExpiresAt)
+}
+
+// AddRestrictedSymbol 将股票加入受限列表，expiresAt 为零值时一直有效
+// 已经受限的股票会更新原因和到期时间
+func (e *BaseTradingEngine) AddRestrictedSymbol(symbol, reason string, expiresAt time.Time) error {
+	if symbol == "" {
+		return ErrInvalidSymbol
+	}
+	now := e.now()
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		return fmt.Errorf("expiry %s is in the past", expiresAt.Format(time.RFC3339))
+	}
+
+	restriction := RestrictedSymbol{Symbol: symbol, Reason: reason, AddedAt: now}
+	if !expiresAt.IsZero() {
+		restriction.ExpiresAt = &expiresAt
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.recordRestriction(WALSymbolRestricted, restriction); err != nil {
+		return err
+	}
+	e.applyRestriction(WALSymbolRestricted, restriction)
+	return nil
+}
+
+// RemoveRestrictedSymbol 将股票移出受限列表
+func (e *BaseTradingEngine) RemoveRestrictedSymbol(symbol string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	restriction, exists := e.restricted[symbol]
+	if !exists {
+		return fmt.Errorf("symbol %s is not restricted", symbol)
+	}
+	if err := e.recordRestriction(WALSymbolUnrestricted, restriction); err != nil {
+		return err
+	}
+	e.applyRestriction(WALSymbolUnrestricted, restriction)
+	return nil
+}
+
+// GetRestrictedSymbols 返回按股票代码排序的有效限制，已到期的限制不返回
+func (e *BaseTradingEngine) GetRestrictedSymbols() []RestrictedSymbol {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	now := e.now()
+	restrictions := make([]RestrictedSymbol, 0, len(e.restricted))
+	for _, restriction := range e.restricted {
+		if restriction.Active(now) {
+			restrictions = append(restrictions, restriction)
+		}
+	}
+	sort.Slice(restrictions, func(i, j int) bool {
+		return restrictions[i].Symbol < restrictions[j].Symbol
+	})
+	return restrictions
+}
+
+// CheckRestricted 检查股票是否受限，受限时返回包含原因的 ErrSymbolRestricted
+func (e *BaseTradingEngine) CheckRestricted(symbol string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.restriction(symbol, e.now())
+}
+
+// restriction 检查股票是否有有效的限制（内部方法，调用方持锁）
+func (e *BaseTradingEngine) restriction(symbol string, now time.Time) error {
+	restriction, exists := e.restricted[symbol]
+	if !exists || !restriction.Active(now) {
+		return nil
+	}
+	if restriction.Reason == "" {
+		return fmt.Errorf("%w: %s", ErrSymbolRestricted, symbol)
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrSymbolRestricted, symbol, restriction.Reason)
+}
+
+// checkRestricted 检查订单的股票是否受限（内部方法，调用方持锁）
+// 受限股票只允许减少已有持仓的订单，避免因限制而无法止损离场
+func (e *BaseTradingEngine) checkRestricted(req OrderRequest, now time.Time) error {
+	err := e.restriction(req.Symbol, now)
+	if err == nil || e.reducesPosition(req) {
+		return nil
+	}
+	return err
+}
+
+// reducesPosition 检查订单是否减少已有持仓，对冲模式下按订单所属策略的持仓判断（内部方法，调用方持锁）
+func (e *BaseTradingEngine) reducesPosition(req OrderRequest) bool {
+	key := req.Symbol
+	if e.hedging() {
+		key = hedgeKey(req.Symbol, req.Strategy)
+	}
+	pos, exists := e.positions[key]
+	return exists && pos.Quantity != 0 && (pos.Quantity > 0) != (req.Side == OrderSideBuy)
+}
+
+// recordRestriction 将受限列表的变化写入预写日志（内部方法，调用方持锁）
+func (e *BaseTradingEngine) recordRestriction(recordType string, restriction RestrictedSymbol) error {
+	if e.wal == nil || e.replaying {
+		return nil
+	}
+	return e.wal.Append(WALRecord{Type: recordType, Restriction: &restriction})
+}
+
+// applyRestriction 更新受限列表（内部方法，调用方持锁）
+func (e *BaseTradingEngine) applyRestriction(recordType string, restriction RestrictedSymbol) {
+	if recordType == WALSymbolUnrestricted {
+		delete(e.restricted, restriction.Symbol)
+		return
+	}
+	if e.restricted == nil {
+		e.restricted = make(map[string]RestrictedSymbol)
+	}
+	e.restricted[restriction.Symbol] = restriction
+}
@@ -0,0 +1,78 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRestrictedSymbols(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	wal, err := OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 10})
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if err := engine.AddRestrictedSymbol("AAPL", "halted", time.Time{}); err != nil {
+		t.Fatalf("添加受限股票失败: %v", err)
+	}
+	if err := engine.AddRestrictedSymbol("GME", "hard to borrow", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("添加受限股票失败: %v", err)
+	}
+	if err := engine.AddRestrictedSymbol("TSLA", "", time.Now().Add(-time.Hour)); err == nil {
+		t.Error("已过期的限制应被拒绝")
+	}
+
+	_, err = engine.SubmitOrder(ctx, "AAPL", 5, 0, OrderTypeMarket, OrderSideBuy)
+	if GetRejectCode(err) != RejectCodeRestricted || !errors.Is(err, ErrSymbolRestricted) {
+		t.Errorf("受限股票的买单应被拒绝: %v", err)
+	}
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 4, 0, OrderTypeMarket, OrderSideSell); err != nil {
+		t.Errorf("受限股票的减仓订单不应被拒绝: %v", err)
+	}
+
+	watchlist := NewWatchlist(engine, nil)
+	if err := watchlist.AddItem(WatchlistItem{Symbol: "GME", Quantity: 1}); !errors.Is(err, ErrSymbolRestricted) {
+		t.Errorf("受限股票不能加入监控列表: %v", err)
+	}
+
+	if err := engine.RemoveRestrictedSymbol("AAPL"); err != nil {
+		t.Fatalf("移除受限股票失败: %v", err)
+	}
+	if err := engine.RemoveRestrictedSymbol("AAPL"); err == nil {
+		t.Error("移除不在列表中的股票应返回错误")
+	}
+	wal.Close()
+
+	// 受限列表从预写日志恢复
+	wal, err = OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	recovered.SetWAL(wal)
+	if _, err := recovered.Recover(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	restricted := recovered.GetRestrictedSymbols()
+	if len(restricted) != 1 || restricted[0].Symbol != "GME" || restricted[0].Reason != "hard to borrow" || restricted[0].ExpiresAt == nil {
+		t.Errorf("恢复的受限列表不正确: %+v", restricted)
+	}
+
+	expired := RestrictedSymbol{Symbol: "GME", ExpiresAt: restricted[0].ExpiresAt}
+	if expired.Active(restricted[0].ExpiresAt.Add(time.Second)) {
+		t.Error("到期后限制不应有效")
+	}
+}
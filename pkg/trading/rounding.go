@@ -0,0 +1,143 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// TickBand 表示价格区间内的最小报价单位，价格不低于 MinPrice 时使用该单位
+type TickBand struct {
+	MinPrice float64 `json:"min_price" yaml:"min_price"`
+	Tick     float64 `json:"tick" yaml:"tick"`
+}
+
+// SymbolRounding 表示单只股票的报价单位和交易单位，未设置的字段使用默认规则
+type SymbolRounding struct {
+	Ticks   []TickBand `json:"ticks,omitempty" yaml:"ticks"`
+	LotSize int64      `json:"lot_size,omitempty" yaml:"lot_size"`
+}
+
+// RoundingConfig 表示下单前价格和数量的取整规则
+type RoundingConfig struct {
+	Enabled bool                      `json:"enabled" yaml:"enabled"`
+	Ticks   []TickBand                `json:"ticks" yaml:"ticks"`       // 默认报价单位表，为空时使用美股规则
+	LotSize int64                     `json:"lot_size" yaml:"lot_size"` // 默认交易单位，为0时为1股
+	Symbols map[string]SymbolRounding `json:"symbols" yaml:"symbols"`
+}
+
+// DefaultTickBands 是美股的报价单位（Reg NMS 612）：1美元及以上为0.01，1美元以下为0.0001
+var DefaultTickBands = []TickBand{
+	{MinPrice: 0, Tick: 0.0001},
+	{MinPrice: 1, Tick: 0.01},
+}
+
+// Validate 检查取整规则是否有效
+func (c RoundingConfig) Validate() error {
+	if c.LotSize < 0 {
+		return fmt.Errorf("lot_size must not be negative")
+	}
+	if err := validateTickBands(c.Ticks); err != nil {
+		return err
+	}
+	for symbol, rules := range c.Symbols {
+		if rules.LotSize < 0 {
+			return fmt.Errorf("symbols.%s.lot_size must not be negative", symbol)
+		}
+		if err := validateTickBands(rules.Ticks); err != nil {
+			return fmt.Errorf("symbols.%s: %w", symbol, err)
+		}
+	}
+	return nil
+}
+
+// validateTickBands 检查报价单位表（内部函数）
+func validateTickBands(bands []TickBand) error {
+	for _, band := range bands {
+		if band.Tick <= 0 {
+			return fmt.Errorf("tick must be positive")
+		}
+		if band.MinPrice < 0 {
+			return fmt.Errorf("tick min_price must not be negative")
+		}
+	}
+	return nil
+}
+
+// tickFor 返回价格适用的报价单位（内部方法）
+func (c RoundingConfig) tickFor(symbol string, price float64) float64 {
+	bands := c.Symbols[symbol].Ticks
+	if len(bands) == 0 {
+		bands = c.Ticks
+	}
+	if len(bands) == 0 {
+		bands = DefaultTickBands
+	}
+
+	sorted := append([]TickBand(nil), bands...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].MinPrice < sorted[j].MinPrice
+	})
+
+	tick := sorted[0].Tick
+	for _, band := range sorted {
+		if price >= band.MinPrice {
+			tick = band.Tick
+		}
+	}
+	return tick
+}
+
+// lotFor 返回股票的交易单位（内部方法）
+func (c RoundingConfig) lotFor(symbol string) int64 {
+	if lot := c.Symbols[symbol].LotSize; lot > 0 {
+		return lot
+	}
+	if c.LotSize > 0 {
+		return c.LotSize
+	}
+	return 1
+}
+
+// snapPrice 将价格取整到报价单位，up为true时向上取整，否则向下取整（内部函数）
+// 先按1e-9的容差消除浮点误差，避免已经对齐的价格被多移动一个单位
+func snapPrice(price, tick float64, up bool) float64 {
+	steps := price / tick
+	if up {
+		steps = math.Ceil(steps - 1e-9)
+	} else {
+		steps = math.Floor(steps + 1e-9)
+	}
+	// 按报价单位的小数位数四舍五入，去掉乘法引入的浮点尾数
+	decimals := math.Max(0, math.Ceil(-math.Log10(tick)))
+	scale := math.Pow(10, decimals)
+	return math.Round(steps*tick*scale) / scale
+}
+
+// Apply 按规则调整下单请求：数量向下取整到交易单位，限价和止损价取整到报价单位
+// 买单价格向下取整、卖单价格向上取整，限价单的成交价不会比原请求更差。数量不足一个交易单位时返回错误
+func (c RoundingConfig) Apply(req OrderRequest) (OrderRequest, error) {
+	lot := c.lotFor(req.Symbol)
+	if quantity := req.Quantity / lot * lot; quantity != req.Quantity {
+		if quantity <= 0 {
+			return req, fmt.Errorf("%w: %d is below lot size %d for %s", ErrInvalidQuantity, req.Quantity, lot, req.Symbol)
+		}
+		req.Quantity = quantity
+	}
+
+	up := req.Side == OrderSideSell
+	if req.Price > 0 && req.Type != OrderTypeMarket {
+		req.Price = snapPrice(req.Price, c.tickFor(req.Symbol, req.Price), up)
+	}
+	if req.StopPrice > 0 {
+		req.StopPrice = snapPrice(req.StopPrice, c.tickFor(req.Symbol, req.StopPrice), up)
+	}
+	return req, nil
+}
+
+// SetRounding 设置下单前的价格和数量取整规则，规则未启用时不调整
+func (e *BaseTradingEngine) SetRounding(rounding RoundingConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rounding = rounding
+}
@@ -0,0 +1,50 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRoundingApply(t *testing.T) {
+	rounding := RoundingConfig{
+		Enabled: true,
+		Symbols: map[string]SymbolRounding{
+			"7203.T": {Ticks: []TickBand{{MinPrice: 0, Tick: 1}, {MinPrice: 3000, Tick: 5}}, LotSize: 100},
+		},
+	}
+
+	tests := []struct {
+		req       OrderRequest
+		wantPrice float64
+		wantStop  float64
+		wantQty   int64
+	}{
+		{OrderRequest{Symbol: "AAPL", Quantity: 10, Price: 150.2537, Type: OrderTypeLimit, Side: OrderSideBuy}, 150.25, 0, 10},
+		{OrderRequest{Symbol: "AAPL", Quantity: 10, Price: 150.2537, Type: OrderTypeLimit, Side: OrderSideSell}, 150.26, 0, 10},
+		{OrderRequest{Symbol: "AAPL", Quantity: 10, Price: 150.25, Type: OrderTypeLimit, Side: OrderSideSell}, 150.25, 0, 10},
+		{OrderRequest{Symbol: "PENNY", Quantity: 10, Price: 0.123456, Type: OrderTypeLimit, Side: OrderSideBuy}, 0.1234, 0, 10},
+		{OrderRequest{Symbol: "AAPL", Quantity: 10, StopPrice: 99.999, Type: OrderTypeStop, Side: OrderSideSell}, 0, 100, 10},
+		{OrderRequest{Symbol: "7203.T", Quantity: 250, Price: 3012, Type: OrderTypeLimit, Side: OrderSideBuy}, 3010, 0, 200},
+	}
+	for _, tt := range tests {
+		got, err := rounding.Apply(tt.req)
+		if err != nil {
+			t.Errorf("%+v 取整失败: %v", tt.req, err)
+			continue
+		}
+		if got.Price != tt.wantPrice || got.StopPrice != tt.wantStop || got.Quantity != tt.wantQty {
+			t.Errorf("%s %s 取整结果 价格=%v 止损=%v 数量=%d, 期望 %v %v %d", tt.req.Symbol, tt.req.Side, got.Price, got.StopPrice, got.Quantity, tt.wantPrice, tt.wantStop, tt.wantQty)
+		}
+	}
+
+	// 不足一个交易单位的订单被引擎拒绝
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 3000})
+	engine.SetRounding(rounding)
+	engine.Enable()
+	_, err := engine.PlaceOrder(context.Background(), OrderRequest{Symbol: "7203.T", Quantity: 50, Type: OrderTypeMarket, Side: OrderSideBuy})
+	if !errors.Is(err, ErrInvalidQuantity) || GetRejectCode(err) != RejectCodeInvalidParams {
+		t.Errorf("不足一个交易单位应以 %s 拒绝: %v", RejectCodeInvalidParams, err)
+	}
+}
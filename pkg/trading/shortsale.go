@@ -0,0 +1,183 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// shortLocateTimeout 是下单前查询可借券情况的超时时间，超时按查询失败处理
+const shortLocateTimeout = 2 * time.Second
+
+// ssrDropPercent 是触发卖空限制（Reg SHO 201）的跌幅，相对前一常规交易时段收盘价
+const ssrDropPercent = 10
+
+// ShortSaleConfig 表示卖空检查的配置
+// 启用后，开空或加空的订单在卖空限制生效时只能以高于买一价的限价提交，
+// 券商提供可借券查询时还检查是否可借、可借数量和借券费率
+type ShortSaleConfig struct {
+	Enabled              bool    `json:"enabled" yaml:"enabled"`
+	RequireLocate        bool    `json:"require_locate" yaml:"require_locate"`                   // 无法确认可借券（券商不支持查询或查询失败）时拒绝，为false时只发布警告
+	MaxBorrowFeePercent  float64 `json:"max_borrow_fee_percent" yaml:"max_borrow_fee_percent"`   // 年化借券费率超过该值时拒绝，为0时不检查
+	WarnBorrowFeePercent float64 `json:"warn_borrow_fee_percent" yaml:"warn_borrow_fee_percent"` // 年化借券费率超过该值时发布警告，为0时不检查
+}
+
+// Validate 检查配置是否有效
+func (c ShortSaleConfig) Validate() error {
+	if c.MaxBorrowFeePercent < 0 || c.WarnBorrowFeePercent < 0 {
+		return fmt.Errorf("borrow fee percents must not be negative")
+	}
+	return nil
+}
+
+// ShortAvailability 表示券商提供的股票可借券情况
+type ShortAvailability struct {
+	Symbol       string  `json:"symbol"`
+	Shortable    bool    `json:"shortable"`
+	EasyToBorrow bool    `json:"easy_to_borrow"`
+	Shares       int64   `json:"shares,omitempty"`      // 可借数量，为0时表示券商未提供
+	FeePercent   float64 `json:"fee_percent,omitempty"` // 年化借券费率（百分比）
+}
+
+// ShortLocator 是可以查询可借券情况的券商
+type ShortLocator interface {
+	// ShortAvailability 返回股票当前的可借券情况
+	ShortAvailability(ctx context.Context, symbol string) (ShortAvailability, error)
+}
+
+// ShortSaleWarning 表示卖空订单通过检查但存在风险，作为 short_sale_warning 事件的内容
+type ShortSaleWarning struct {
+	OrderID      string             `json:"order_id"`
+	Symbol       string             `json:"symbol"`
+	Strategy     string             `json:"strategy,omitempty"`
+	Quantity     int64              `json:"quantity"` // 开空或加空的数量
+	Availability *ShortAvailability `json:"availability,omitempty"`
+	Warnings     []string           `json:"warnings"`
+	Timestamp    time.Time          `json:"timestamp"`
+}
+
+// shortLocate 表示下单前查询的可借券情况（内部类型）
+type shortLocate struct {
+	availability *ShortAvailability
+	err          error
+}
+
+// SetShortSaleChecks 设置卖空检查
+func (e *BaseTradingEngine) SetShortSaleChecks(config ShortSaleConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shortSale = config
+}
+
+// ShortRestricted 检查报价是否表明卖空限制生效：数据源报告限制，或最新价较前一常规交易时段收盘价下跌10%以上
+// 限制在触发后的下一个交易日仍然有效，这部分只能依靠数据源报告
+func ShortRestricted(quote datasource.Quote) bool {
+	return quote.ShortRestricted || quote.GapPercent() <= -ssrDropPercent
+}
+
+// locateShort 在加锁前向券商查询卖出订单股票的可借券情况，未启用检查或按当前持仓不会开空或加空时返回空，不查询券商（内部方法）
+func (e *BaseTradingEngine) locateShort(ctx context.Context, req OrderRequest) *shortLocate {
+	if req.Side != OrderSideSell {
+		return nil
+	}
+	e.mu.RLock()
+	enabled := e.shortSale.Enabled
+	shortQty := e.shortQuantity(req)
+	locator, ok := e.broker.(ShortLocator)
+	e.mu.RUnlock()
+	if !enabled || shortQty == 0 {
+		return nil
+	}
+	if !ok {
+		return &shortLocate{err: fmt.Errorf("broker does not provide borrow availability")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shortLocateTimeout)
+	defer cancel()
+	availability, err := locator.ShortAvailability(ctx, req.Symbol)
+	if err != nil {
+		return &shortLocate{err: err}
+	}
+	return &shortLocate{availability: &availability}
+}
+
+// shortQuantity 返回订单开空或加空的数量，平多的部分不计入（内部方法，调用方持锁）
+func (e *BaseTradingEngine) shortQuantity(req OrderRequest) int64 {
+	if req.Side != OrderSideSell {
+		return 0
+	}
+	key := req.Symbol
+	if e.hedging() {
+		key = hedgeKey(req.Symbol, req.Strategy)
+	}
+	if pos, exists := e.positions[key]; exists && pos.Quantity > 0 {
+		return max(req.Quantity-pos.Quantity, 0)
+	}
+	return req.Quantity
+}
+
+// checkShortSale 检查开空或加空的订单，拒绝时返回带拒单代码的错误，通过时返回需要发布的警告（内部方法，调用方持锁）
+func (e *BaseTradingEngine) checkShortSale(req OrderRequest, quote datasource.Quote, locate *shortLocate) ([]string, error) {
+	quantity := e.shortQuantity(req)
+	if !e.shortSale.Enabled || quantity == 0 {
+		return nil, nil
+	}
+
+	// 卖空限制生效时只能以高于买一价的价格卖空
+	if ShortRestricted(quote) {
+		switch {
+		case req.Type != OrderTypeLimit:
+			return nil, reject(RejectCodeShortRestricted, fmt.Errorf("%w: %s requires a limit order above the bid", ErrShortSaleRestricted, req.Symbol))
+		case quote.BidPrice > 0 && req.Price <= quote.BidPrice:
+			return nil, reject(RejectCodeShortRestricted, fmt.Errorf("%w: %s limit %.4f must be above the bid %.4f", ErrShortSaleRestricted, req.Symbol, req.Price, quote.BidPrice))
+		}
+	}
+
+	var warnings []string
+	if locate == nil || locate.err != nil {
+		reason := "borrow availability unknown"
+		if locate != nil {
+			reason = fmt.Sprintf("borrow availability unknown: %v", locate.err)
+		}
+		if e.shortSale.RequireLocate {
+			return nil, reject(RejectCodeNoBorrow, fmt.Errorf("%w: %s %s", ErrNoBorrow, req.Symbol, reason))
+		}
+		return append(warnings, reason), nil
+	}
+
+	availability := locate.availability
+	switch {
+	case !availability.Shortable:
+		return nil, reject(RejectCodeNoBorrow, fmt.Errorf("%w: %s is not shortable", ErrNoBorrow, req.Symbol))
+	case availability.Shares > 0 && availability.Shares < quantity:
+		return nil, reject(RejectCodeNoBorrow, fmt.Errorf("%w: %s has %d shares to borrow, need %d", ErrNoBorrow, req.Symbol, availability.Shares, quantity))
+	case e.shortSale.MaxBorrowFeePercent > 0 && availability.FeePercent > e.shortSale.MaxBorrowFeePercent:
+		return nil, reject(RejectCodeNoBorrow, fmt.Errorf("%w: %s borrow fee %.2f%% exceeds limit %.2f%%", ErrNoBorrow, req.Symbol, availability.FeePercent, e.shortSale.MaxBorrowFeePercent))
+	}
+	if !availability.EasyToBorrow {
+		warnings = append(warnings, "hard to borrow")
+	}
+	if e.shortSale.WarnBorrowFeePercent > 0 && availability.FeePercent > e.shortSale.WarnBorrowFeePercent {
+		warnings = append(warnings, fmt.Sprintf("borrow fee %.2f%% above %.2f%%", availability.FeePercent, e.shortSale.WarnBorrowFeePercent))
+	}
+	return warnings, nil
+}
+
+// publishShortSaleWarning 在 risk 主题发布卖空警告（内部方法，调用方持锁）
+func (e *BaseTradingEngine) publishShortSaleWarning(order Order, quantity int64, locate *shortLocate, warnings []string) {
+	warning := ShortSaleWarning{
+		OrderID:   order.ID,
+		Symbol:    order.Symbol,
+		Strategy:  order.Strategy,
+		Quantity:  quantity,
+		Warnings:  warnings,
+		Timestamp: e.now(),
+	}
+	if locate != nil {
+		warning.Availability = locate.availability
+	}
+	e.publish(events.TopicRisk, EventShortSaleWarning, warning)
+}
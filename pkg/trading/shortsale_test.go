@@ -0,0 +1,124 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// locatingBroker 按固定的可借券情况回答查询的券商
+type locatingBroker struct {
+	fixedPriceBroker
+	availability map[string]ShortAvailability
+	queries      int
+}
+
+func (b *locatingBroker) ShortAvailability(ctx context.Context, symbol string) (ShortAvailability, error) {
+	b.queries++
+	availability, ok := b.availability[symbol]
+	if !ok {
+		return ShortAvailability{}, errors.New("no borrow data for " + symbol)
+	}
+	return availability, nil
+}
+
+func TestShortSaleChecks(t *testing.T) {
+	ctx := context.Background()
+	source := &quoteSource{quotes: map[string]*datasource.Quote{
+		"AAPL": {Symbol: "AAPL", LastPrice: 100, BidPrice: 99.9, AskPrice: 100.1},
+		"MSFT": {Symbol: "MSFT", LastPrice: 100, BidPrice: 99.9, AskPrice: 100.1},
+		"TSLA": {Symbol: "TSLA", LastPrice: 85, BidPrice: 84.9, AskPrice: 85.1, RegularClose: 100},
+	}}
+	manager := datasource.NewManager()
+	manager.AddDataSource(source)
+
+	engine := NewBaseTradingEngine(manager, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	broker := &locatingBroker{
+		fixedPriceBroker: fixedPriceBroker{price: 100},
+		availability: map[string]ShortAvailability{
+			"AAPL": {Symbol: "AAPL", Shortable: false},
+			"MSFT": {Symbol: "MSFT", Shortable: true, Shares: 100, FeePercent: 8},
+			"TSLA": {Symbol: "TSLA", Shortable: true, EasyToBorrow: true, FeePercent: 0.3},
+		},
+	}
+	engine.SetBroker(broker)
+	engine.SetShortSaleChecks(ShortSaleConfig{Enabled: true, RequireLocate: true, MaxBorrowFeePercent: 20, WarnBorrowFeePercent: 5})
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicRisk)
+	defer sub.Close()
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	// 平多不受卖空检查限制，超出多头的部分才是卖空
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 15, 0, OrderTypeMarket, OrderSideSell); GetRejectCode(err) != RejectCodeNoBorrow || !errors.Is(err, ErrNoBorrow) {
+		t.Errorf("不可卖空的股票应被拒绝: %v", err)
+	}
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideSell); err != nil {
+		t.Errorf("平多不应被拒绝: %v", err)
+	}
+	if broker.queries != 1 {
+		t.Errorf("只有开空的卖出订单需要查询可借券情况: %d", broker.queries)
+	}
+
+	// 可借数量不足时拒绝，难以借券和费率较高时发布警告
+	if _, err := engine.SubmitOrder(ctx, "MSFT", 200, 0, OrderTypeMarket, OrderSideSell); GetRejectCode(err) != RejectCodeNoBorrow {
+		t.Errorf("可借数量不足应被拒绝: %v", err)
+	}
+	order, err := engine.SubmitOrder(ctx, "MSFT", 50, 0, OrderTypeMarket, OrderSideSell)
+	if err != nil {
+		t.Fatalf("卖空失败: %v", err)
+	}
+	evt := <-sub.C
+	warning, ok := evt.Payload.(ShortSaleWarning)
+	if evt.Type != EventShortSaleWarning || !ok || warning.OrderID != order.ID || warning.Quantity != 50 || len(warning.Warnings) != 2 {
+		t.Errorf("应发布卖空警告: %+v", evt)
+	}
+
+	// 跌幅超过10%触发卖空限制，只允许高于买一价的限价单
+	if _, err := engine.SubmitOrder(ctx, "TSLA", 10, 0, OrderTypeMarket, OrderSideSell); GetRejectCode(err) != RejectCodeShortRestricted || !errors.Is(err, ErrShortSaleRestricted) {
+		t.Errorf("卖空限制生效时市价卖空应被拒绝: %v", err)
+	}
+	if _, err := engine.SubmitOrder(ctx, "TSLA", 10, 84.9, OrderTypeLimit, OrderSideSell); GetRejectCode(err) != RejectCodeShortRestricted {
+		t.Errorf("不高于买一价的限价卖空应被拒绝: %v", err)
+	}
+	if _, err := engine.SubmitOrder(ctx, "TSLA", 10, 85, OrderTypeLimit, OrderSideSell); err != nil {
+		t.Errorf("高于买一价的限价卖空应被接受: %v", err)
+	}
+
+	// 费率超过上限时拒绝
+	engine.SetShortSaleChecks(ShortSaleConfig{Enabled: true, MaxBorrowFeePercent: 5})
+	if _, err := engine.SubmitOrder(ctx, "MSFT", 10, 0, OrderTypeMarket, OrderSideSell); GetRejectCode(err) != RejectCodeNoBorrow {
+		t.Errorf("借券费率超过上限应被拒绝: %v", err)
+	}
+}
+
+func TestShortSaleWithoutLocator(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.SetShortSaleChecks(ShortSaleConfig{Enabled: true, RequireLocate: true})
+	bus := events.NewBus()
+	sub := bus.Subscribe(10, events.TopicRisk)
+	defer sub.Close()
+	engine.SetEventBus(bus)
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideSell); GetRejectCode(err) != RejectCodeNoBorrow {
+		t.Errorf("要求确认可借券时券商不支持查询应被拒绝: %v", err)
+	}
+
+	// 不要求确认时照常提交并发布警告
+	engine.SetShortSaleChecks(ShortSaleConfig{Enabled: true})
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideSell); err != nil {
+		t.Fatalf("卖空失败: %v", err)
+	}
+	if evt := <-sub.C; evt.Type != EventShortSaleWarning {
+		t.Errorf("应发布卖空警告: %+v", evt)
+	}
+}
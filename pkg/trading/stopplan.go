@@ -0,0 +1,177 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/indicators"
+)
+
+// 止损建议的计算方法
+const (
+	StopMethodATR   = "atr"   // 入场价减去ATR的倍数
+	StopMethodSwing = "swing" // 最近的摆动低点（空头为高点）之外，没有合适的摆动点时按ATR计算
+)
+
+// StopPlanOptions 表示止损建议的参数，零值字段使用默认值
+type StopPlanOptions struct {
+	Method      string  // 计算方法，默认为 atr
+	Timeframe   string  // K线周期，默认为 day
+	Lookback    int     // 使用的K线数，默认60
+	ATRPeriod   int     // ATR周期，默认14
+	StopATR     float64 // 止损距离的ATR倍数，默认2
+	TargetATR   float64 // 止盈距离的ATR倍数，为0时按 RewardRisk 计算
+	RewardRisk  float64 // 止盈距离与止损距离之比，默认2
+	SwingBars   int     // 摆动点两侧的K线数，默认3
+	SwingBuffer float64 // 摆动点止损在摆动点之外再留出的ATR倍数，默认0.25
+	Beta        float64 // 大于0时ATR距离乘以贝塔系数，高贝塔股票的止损更宽
+}
+
+// withDefaults 返回填充默认值后的参数（内部方法）
+func (o StopPlanOptions) withDefaults() StopPlanOptions {
+	if o.Method == "" {
+		o.Method = StopMethodATR
+	}
+	if o.Timeframe == "" {
+		o.Timeframe = "day"
+	}
+	if o.Lookback <= 0 {
+		o.Lookback = 60
+	}
+	if o.ATRPeriod <= 0 {
+		o.ATRPeriod = 14
+	}
+	if o.StopATR <= 0 {
+		o.StopATR = 2
+	}
+	if o.RewardRisk <= 0 {
+		o.RewardRisk = 2
+	}
+	if o.SwingBars <= 0 {
+		o.SwingBars = 3
+	}
+	if o.SwingBuffer <= 0 {
+		o.SwingBuffer = 0.25
+	}
+	return o
+}
+
+// StopPlan 表示一次入场的建议止损和止盈
+type StopPlan struct {
+	Symbol     string    `json:"symbol"`
+	Side       OrderSide `json:"side"` // 入场方向，买入为多头
+	EntryPrice float64   `json:"entry_price"`
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	ATR        float64   `json:"atr"`
+	Risk       float64   `json:"risk"`   // 每股风险，即入场价与止损价的距离
+	Method     string    `json:"method"` // 实际使用的计算方法
+}
+
+// Bracket 把止损和止盈设置到下单请求上，使其成为括号订单
+func (p StopPlan) Bracket(req *OrderRequest) {
+	req.StopLoss = p.StopLoss
+	req.TakeProfit = p.TakeProfit
+}
+
+// ApplyTo 把止损和止盈设置到监控项上
+func (p StopPlan) ApplyTo(item *WatchlistItem) {
+	item.StopLoss = p.StopLoss
+	item.TakeProfit = p.TakeProfit
+}
+
+// SuggestStopPlan 获取symbol最近的K线，按入场方向和入场价计算建议的止损和止盈
+func SuggestStopPlan(ctx context.Context, dataManager *datasource.Manager, symbol string, side OrderSide, entry float64, opts StopPlanOptions) (StopPlan, error) {
+	opts = opts.withDefaults()
+
+	// 按日历时间多取一些，覆盖非交易日
+	period := datasource.BarDuration(opts.Timeframe)
+	if period == 0 {
+		return StopPlan{}, fmt.Errorf("unsupported timeframe: %s", opts.Timeframe)
+	}
+	span := period * time.Duration(opts.Lookback)
+	to := time.Now()
+	from := to.Add(-2*span - 7*24*time.Hour)
+	data, err := dataManager.GetStockData(ctx, symbol, opts.Timeframe, from, to)
+	if err != nil {
+		return StopPlan{}, fmt.Errorf("failed to fetch %s bars for stop plan: %w", symbol, err)
+	}
+	if len(data) > opts.Lookback {
+		data = data[len(data)-opts.Lookback:]
+	}
+
+	plan, err := ComputeStopPlan(data, side, entry, opts)
+	plan.Symbol = symbol
+	return plan, err
+}
+
+// ComputeStopPlan 用给定的K线按入场方向和入场价计算建议的止损和止盈
+func ComputeStopPlan(data []datasource.StockData, side OrderSide, entry float64, opts StopPlanOptions) (StopPlan, error) {
+	opts = opts.withDefaults()
+	if entry <= 0 {
+		return StopPlan{}, ErrInvalidPrice
+	}
+	if side != OrderSideBuy && side != OrderSideSell {
+		return StopPlan{}, ErrInvalidOrderSide
+	}
+	if opts.Method != StopMethodATR && opts.Method != StopMethodSwing {
+		return StopPlan{}, fmt.Errorf("unsupported stop method: %s", opts.Method)
+	}
+
+	atrValues := indicators.AverageTrueRange(data, opts.ATRPeriod)
+	if len(data) < opts.ATRPeriod+1 || atrValues[len(atrValues)-1] <= 0 {
+		return StopPlan{}, errors.New("not enough bars to compute ATR for stop plan")
+	}
+
+	atr := atrValues[len(atrValues)-1]
+	if opts.Beta > 0 {
+		atr *= opts.Beta
+	}
+	dir := 1.0
+	if side == OrderSideSell {
+		dir = -1
+	}
+
+	plan := StopPlan{
+		Side:       side,
+		EntryPrice: entry,
+		ATR:        atr,
+		Method:     StopMethodATR,
+		StopLoss:   entry - dir*opts.StopATR*atr,
+	}
+	if opts.Method == StopMethodSwing {
+		if level, ok := nearestSwing(data, opts.SwingBars, side, entry); ok {
+			plan.StopLoss = level - dir*opts.SwingBuffer*atr
+			plan.Method = StopMethodSwing
+		}
+	}
+
+	plan.Risk = (entry - plan.StopLoss) * dir
+	if opts.TargetATR > 0 {
+		plan.TakeProfit = entry + dir*opts.TargetATR*atr
+	} else {
+		plan.TakeProfit = entry + dir*opts.RewardRisk*plan.Risk
+	}
+	if plan.StopLoss <= 0 {
+		return plan, fmt.Errorf("stop loss %.2f is not positive", plan.StopLoss)
+	}
+	return plan, nil
+}
+
+// nearestSwing 返回入场价下方最近确认的摆动低点（空头为上方的摆动高点）（内部函数）
+func nearestSwing(data []datasource.StockData, bars int, side OrderSide, entry float64) (float64, bool) {
+	swings := indicators.FindSwings(data, bars, bars)
+	for i := len(swings) - 1; i >= 0; i-- {
+		s := swings[i]
+		if side == OrderSideBuy && !s.High && s.Price < entry {
+			return s.Price, true
+		}
+		if side == OrderSideSell && s.High && s.Price > entry {
+			return s.Price, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,77 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// stopPlanBars 生成真实波幅为2的K线，dip大于0时第10根的最低价为dip，成为摆动低点（内部函数）
+func stopPlanBars(dip float64) []datasource.StockData {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	data := make([]datasource.StockData, 20)
+	for i := range data {
+		data[i] = datasource.StockData{Symbol: "AAPL", Timestamp: start.AddDate(0, 0, i), Open: 100, High: 101, Low: 99, Close: 100}
+	}
+	if dip > 0 {
+		data[10].Low = dip
+	}
+	return data
+}
+
+func TestComputeStopPlan(t *testing.T) {
+	data := stopPlanBars(0)
+
+	plan, err := ComputeStopPlan(data, OrderSideBuy, 100, StopPlanOptions{ATRPeriod: 5})
+	if err != nil {
+		t.Fatalf("计算止损建议失败: %v", err)
+	}
+	if plan.ATR != 2 || plan.StopLoss != 96 || plan.TakeProfit != 108 || plan.Method != StopMethodATR {
+		t.Errorf("ATR止损建议 = %+v", plan)
+	}
+
+	plan, _ = ComputeStopPlan(data, OrderSideSell, 100, StopPlanOptions{ATRPeriod: 5, TargetATR: 3, Beta: 1.5})
+	if plan.StopLoss != 106 || plan.TakeProfit != 91 {
+		t.Errorf("贝塔调整的空头止损建议 = %+v", plan)
+	}
+
+	// 摆动低点94，再留出0.25倍ATR
+	data = stopPlanBars(94)
+	plan, _ = ComputeStopPlan(data, OrderSideBuy, 100, StopPlanOptions{Method: StopMethodSwing, ATRPeriod: 5})
+	if plan.Method != StopMethodSwing || math.Abs(plan.StopLoss-(94-0.25*plan.ATR)) > 1e-9 || math.Abs(plan.TakeProfit-(100+2*plan.Risk)) > 1e-9 {
+		t.Errorf("摆动点止损建议 = %+v", plan)
+	}
+
+	// 空头上方没有摆动高点时按ATR计算
+	plan, _ = ComputeStopPlan(data, OrderSideSell, 100, StopPlanOptions{Method: StopMethodSwing, ATRPeriod: 5})
+	if plan.Method != StopMethodATR {
+		t.Errorf("没有摆动高点时应按ATR计算: %+v", plan)
+	}
+
+	if _, err := ComputeStopPlan(data[:3], OrderSideBuy, 100, StopPlanOptions{}); err == nil {
+		t.Error("K线不足时应返回错误")
+	}
+}
+
+func TestStopPlanBracketOrder(t *testing.T) {
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, TakeProfitPercent: 5})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+	plan := StopPlan{StopLoss: 95, TakeProfit: 110}
+
+	req := OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy}
+	plan.Bracket(&req)
+	if _, err := engine.PlaceOrder(context.Background(), req); err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	pos, err := engine.GetPosition(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("获取持仓失败: %v", err)
+	}
+	if pos.StopLoss != 95 || pos.TakeProfit != 110 {
+		t.Errorf("持仓应使用括号订单的止损和止盈: %+v", pos)
+	}
+}
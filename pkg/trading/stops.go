@@ -0,0 +1,452 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// StopMode 表示持仓止损和止盈的管理方式
+type StopMode string
+
+// 止损管理方式常量
+const (
+	StopModeLocal  StopMode = "local"  // 引擎根据报价触发，以市价平仓
+	StopModeBroker StopMode = "broker" // 作为止损单和限价单挂在券商，进程退出后仍然有效
+)
+
+// 止损和止盈订单的标签
+const (
+	TagStopLoss   = "stop_loss"
+	TagTakeProfit = "take_profit"
+)
+
+// OCOBroker 是支持互相取消订单组（one-cancels-other）的券商，组内一个订单成交后券商撤销其余订单
+type OCOBroker interface {
+	// SubmitOCO 将订单作为一组提交，返回券商接受后的订单，顺序与参数相同
+	SubmitOCO(ctx context.Context, orders []Order) ([]Order, error)
+}
+
+// StopTrigger 表示本地触发的止损、止盈或定时平仓，作为 stop_triggered 或 time_exit 事件的内容
+type StopTrigger struct {
+	Position Position  `json:"position"`
+	Kind     string    `json:"kind"` // stop_loss、take_profit、max_hold 或 session_close
+	Price    float64   `json:"price"`
+	OrderID  string    `json:"order_id,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// IsProtective 检查订单是否为止损或止盈订单
+func IsProtective(order Order) bool {
+	return slices.Contains(order.Tags, TagStopLoss) || slices.Contains(order.Tags, TagTakeProfit)
+}
+
+// applyBracket 用括号订单附带的止损和止盈价代替默认值，并记录订单指定的定时平仓设置（内部函数）
+func applyBracket(pos *Position, order Order) {
+	if order.TimeExit != nil {
+		pos.TimeExit = order.TimeExit
+	}
+	if order.StopLoss > 0 {
+		pos.StopLoss = order.StopLoss
+	}
+	if order.TakeProfit > 0 {
+		pos.TakeProfit = order.TakeProfit
+	}
+}
+
+// stopMode 返回止损管理方式，未配置时为本地管理（内部方法）
+func (e *BaseTradingEngine) stopMode() StopMode {
+	if e.brokerConfig.StopMode == "" {
+		return StopModeLocal
+	}
+	return e.brokerConfig.StopMode
+}
+
+// positionKey 返回订单对应的持仓键（内部方法）
+func (e *BaseTradingEngine) positionKey(order Order) string {
+	if e.hedging() {
+		return hedgeKey(order.Symbol, order.Strategy)
+	}
+	return order.Symbol
+}
+
+// isOpenOrder 检查订单是否仍在券商挂单中（内部函数）
+func isOpenOrder(order Order) bool {
+	switch order.Status {
+	case OrderStatusPending, OrderStatusSubmitted, OrderStatusAccepted, OrderStatusPartial:
+		return true
+	}
+	return false
+}
+
+// syncProtectiveOrders 在持仓变化后重新挂出券商的止损和止盈订单（内部方法，调用方不能持锁）
+// 原有挂单先撤销，再按新的持仓数量和止损/止盈价格挂单；挂单失败的持仓由本地检查兜底
+// 止损和止盈属于同一个订单组，券商实现 OCOBroker 时作为一组提交，由券商在一个成交后撤销另一个；
+// 否则分别提交，其中一个成交后由成交回报触发的重新挂单撤销另一个
+// 要撤销和挂出的订单在持锁时确定，调用券商时释放锁，同一时间只有一个同步在进行
+func (e *BaseTradingEngine) syncProtectiveOrders(ctx context.Context, key string) {
+	e.protectMu.Lock()
+	defer e.protectMu.Unlock()
+
+	// 价格已越过止损或止盈时挂单立即成交，按成交后的持仓重新挂单
+	for e.syncProtectiveOnce(ctx, key) {
+	}
+}
+
+// syncProtectiveOnce 撤销持仓原有的挂单并挂出新的止损和止盈订单，有挂单立即成交时返回true（内部方法，调用方不能持锁）
+func (e *BaseTradingEngine) syncProtectiveOnce(ctx context.Context, key string) bool {
+	e.mu.Lock()
+	if e.stopMode() != StopModeBroker || e.replaying {
+		e.mu.Unlock()
+		return false
+	}
+	broker := e.broker
+	_, oco := broker.(OCOBroker)
+	var cancels []Order
+	for _, order := range e.restingProtective(key) {
+		if oco && e.ocoSiblingFilled(order) {
+			// 同组的另一个挂单已全部成交，券商已撤销该挂单，只更新本地状态
+			e.protectiveCanceled(order)
+			continue
+		}
+		cancels = append(cancels, order)
+	}
+	e.mu.Unlock()
+
+	errs := make([]error, len(cancels))
+	for i, order := range cancels {
+		errs[i] = broker.CancelOrder(ctx, order)
+	}
+
+	e.mu.Lock()
+	for i, order := range cancels {
+		if errs[i] != nil {
+			e.logLocked().Error("撤销保护挂单%s失败: %v", order.ID, errs[i])
+			continue
+		}
+		if current, ok := e.orders[order.ID]; ok && isOpenOrder(current) {
+			e.protectiveCanceled(current)
+		}
+	}
+	legs := e.protectiveLegs(key)
+	e.mu.Unlock()
+
+	if oco && len(legs) > 1 {
+		return e.submitProtective(ctx, broker, legs)
+	}
+	for _, leg := range legs {
+		if e.submitProtective(ctx, broker, []Order{leg}) {
+			return true
+		}
+	}
+	return false
+}
+
+// protectiveLegs 按持仓当前的数量和止损/止盈价格构造同一订单组的挂单，没有持仓时返回空（内部方法，调用方持锁）
+func (e *BaseTradingEngine) protectiveLegs(key string) []Order {
+	pos, exists := e.positions[key]
+	if !exists || pos.Quantity == 0 {
+		return nil
+	}
+
+	side := OrderSideSell
+	if pos.Quantity < 0 {
+		side = OrderSideBuy
+	}
+	group := e.newID("oco")
+	var legs []Order
+	for _, leg := range []struct {
+		orderType OrderType
+		price     float64
+		tag       string
+	}{
+		{OrderTypeStop, pos.StopLoss, TagStopLoss},
+		{OrderTypeLimit, pos.TakeProfit, TagTakeProfit},
+	} {
+		if leg.price <= 0 {
+			continue
+		}
+		order, err := e.protectiveOrder(pos, side, leg.orderType, leg.price, leg.tag, group)
+		if err != nil {
+			e.logLocked().Warn("%s的%s挂单无法取整，改由本地检查兜底: %v", pos.Symbol, leg.tag, err)
+			continue
+		}
+		legs = append(legs, order)
+	}
+	return legs
+}
+
+// protectiveOrder 按持仓构造一个止损或止盈挂单，启用取整时价格和数量按取整规则调整（内部方法，调用方持锁）
+// 持仓不足一个交易单位时返回错误
+func (e *BaseTradingEngine) protectiveOrder(pos Position, side OrderSide, orderType OrderType, price float64, tag, group string) (Order, error) {
+	req := OrderRequest{Symbol: pos.Symbol, Quantity: pos.Quantity * direction(pos.Quantity), Type: orderType, Side: side}
+	if orderType == OrderTypeStop {
+		req.StopPrice = price
+	} else {
+		req.Price = price
+	}
+	if e.rounding.Enabled {
+		rounded, err := e.rounding.Apply(req)
+		if err != nil {
+			return Order{}, err
+		}
+		req = rounded
+	}
+
+	now := e.now()
+	order := Order{
+		ID:        e.newID("order"),
+		Symbol:    pos.Symbol,
+		Quantity:  req.Quantity,
+		Price:     req.Price,
+		StopPrice: req.StopPrice,
+		Type:      orderType,
+		Side:      side,
+		Status:    OrderStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Strategy:  pos.Strategy,
+		Tags:      []string{tag},
+		OCOGroup:  group,
+	}
+	return order, nil
+}
+
+// submitProtective 向券商提交止损或止盈挂单，多个挂单作为互相取消的订单组提交（内部方法，调用方不能持锁）
+// 任一挂单立即成交时更新持仓并返回true；挂单失败时只记录日志，该持仓由本地检查兜底
+func (e *BaseTradingEngine) submitProtective(ctx context.Context, broker Broker, legs []Order) bool {
+	e.mu.Lock()
+	for i := range legs {
+		if err := e.record(WALOrderSubmitted, &legs[i], nil); err != nil {
+			e.logLocked().Error("记录%s的%s挂单失败: %v", legs[i].Symbol, protectiveTags(legs), err)
+			e.mu.Unlock()
+			return false
+		}
+	}
+	e.mu.Unlock()
+
+	var submitted []Order
+	var err error
+	if len(legs) == 1 {
+		var order *Order
+		if order, err = broker.SubmitOrder(ctx, legs[0]); err == nil {
+			submitted = []Order{*order}
+		}
+	} else {
+		submitted, err = broker.(OCOBroker).SubmitOCO(ctx, legs)
+		if err == nil && len(submitted) != len(legs) {
+			err = fmt.Errorf("broker returned %d orders for %d-leg OCO group", len(submitted), len(legs))
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		for _, leg := range legs {
+			e.rejectProtective(leg, err)
+		}
+		e.logLocked().Warn("券商拒绝%s的%s挂单，改由本地检查兜底: %v", legs[0].Symbol, protectiveTags(legs), err)
+		return false
+	}
+	filled := false
+	for _, order := range submitted {
+		if e.acceptProtective(order) {
+			filled = true
+		}
+	}
+	return filled
+}
+
+// protectiveTags 返回挂单的标签，用于日志（内部函数）
+func protectiveTags(legs []Order) string {
+	tags := make([]string, 0, len(legs))
+	for _, leg := range legs {
+		tags = append(tags, leg.Tags...)
+	}
+	return strings.Join(tags, "/")
+}
+
+// acceptProtective 记录券商接受的止损或止盈挂单，立即成交时更新持仓并返回true（内部方法，调用方持锁）
+func (e *BaseTradingEngine) acceptProtective(order Order) bool {
+	if order.Status == OrderStatusFilled {
+		e.recordOrLog(WALOrderFilled, &order, nil)
+		e.orders[order.ID] = order
+		e.updatePosition(order)
+		e.recordFill(order)
+		e.publishFill(order)
+		return true
+	}
+	e.recordOrLog(WALOrderAccepted, &order, nil)
+	e.orders[order.ID] = order
+	e.publish(events.TopicOrders, EventOrderAccepted, order)
+	return false
+}
+
+// rejectProtective 记录被券商拒绝的止损或止盈挂单（内部方法，调用方持锁）
+func (e *BaseTradingEngine) rejectProtective(order Order, err error) {
+	order.Status = OrderStatusRejected
+	order.RejectReason = err.Error()
+	e.recordOrLog(WALOrderRejected, &order, nil)
+}
+
+// protectiveCanceled 记录已在券商撤销的止损或止盈挂单（内部方法，调用方持锁）
+func (e *BaseTradingEngine) protectiveCanceled(order Order) {
+	order.Status = OrderStatusCanceled
+	order.UpdatedAt = e.now()
+	e.recordOrLog(WALOrderCanceled, &order, nil)
+	e.orders[order.ID] = order
+	e.publish(events.TopicOrders, EventOrderCanceled, order)
+}
+
+// ocoSiblingFilled 检查订单所在的订单组中是否有其他订单已全部成交（内部方法，调用方持锁）
+func (e *BaseTradingEngine) ocoSiblingFilled(order Order) bool {
+	if order.OCOGroup == "" {
+		return false
+	}
+	for _, other := range e.orders {
+		if other.ID != order.ID && other.OCOGroup == order.OCOGroup && other.Status == OrderStatusFilled {
+			return true
+		}
+	}
+	return false
+}
+
+// restingProtective 返回持仓仍在券商挂单中的止损和止盈订单（内部方法，调用方持锁）
+// 挂单从订单表中查找，从预写日志恢复后同样有效
+func (e *BaseTradingEngine) restingProtective(key string) []Order {
+	var orders []Order
+	for _, order := range e.orders {
+		if order.Type != OrderTypeMarket && isOpenOrder(order) && IsProtective(order) && e.positionKey(order) == key {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+// hasPendingExit 检查持仓是否已有未完成的本地止损或止盈平仓单，避免重复触发（内部方法，调用方持锁）
+func (e *BaseTradingEngine) hasPendingExit(key string) bool {
+	for _, order := range e.orders {
+		if order.Type == OrderTypeMarket && isOpenOrder(order) && IsProtective(order) && e.positionKey(order) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckStops 按最新报价检查持仓的止损和止盈，触发时以市价平仓并返回触发记录
+// 券商模式下有券商挂单保护的持仓由券商触发，不在本地检查；挂单失败的持仓仍由本地检查兜底
+func (e *BaseTradingEngine) CheckStops(ctx context.Context) ([]StopTrigger, error) {
+	if e.dataManager == nil {
+		return nil, nil
+	}
+	return e.checkStops(ctx, func(symbol string) (float64, bool) {
+		quote, err := e.dataManager.GetRealTimeQuote(ctx, symbol)
+		if err != nil || quote == nil || quote.LastPrice <= 0 {
+			return 0, false
+		}
+		return quote.LastPrice, true
+	})
+}
+
+// checkStops 使用给定的报价函数检查止损和止盈（内部方法）
+func (e *BaseTradingEngine) checkStops(ctx context.Context, price func(symbol string) (float64, bool)) ([]StopTrigger, error) {
+	if !e.IsEnabled() {
+		return nil, nil
+	}
+
+	e.mu.RLock()
+	var candidates []Position
+	for key, pos := range e.positions {
+		if pos.Quantity == 0 || (pos.StopLoss <= 0 && pos.TakeProfit <= 0) {
+			continue
+		}
+		if len(e.restingProtective(key)) > 0 || e.hasPendingExit(key) {
+			continue
+		}
+		candidates = append(candidates, pos)
+	}
+	e.mu.RUnlock()
+
+	var triggers []StopTrigger
+	for _, pos := range candidates {
+		last, ok := price(pos.Symbol)
+		if !ok {
+			continue
+		}
+
+		long := pos.Quantity > 0
+		kind := ""
+		switch {
+		case pos.StopLoss > 0 && ((long && last <= pos.StopLoss) || (!long && last >= pos.StopLoss)):
+			kind = TagStopLoss
+		case pos.TakeProfit > 0 && ((long && last >= pos.TakeProfit) || (!long && last <= pos.TakeProfit)):
+			kind = TagTakeProfit
+		default:
+			continue
+		}
+
+		trigger := StopTrigger{Position: pos, Kind: kind, Price: last, Time: e.now()}
+		order, err := e.PlaceOrder(ctx, exitRequest(pos, kind))
+		if err != nil {
+			trigger.Error = err.Error()
+		} else {
+			trigger.OrderID = order.ID
+		}
+
+		e.mu.RLock()
+		e.publish(events.TopicPositions, EventStopTriggered, trigger)
+		e.mu.RUnlock()
+		triggers = append(triggers, trigger)
+	}
+	return triggers, nil
+}
+
+// exitRequest 返回以市价平掉整个持仓的下单请求，订单带有平仓原因的标签（内部函数）
+func exitRequest(pos Position, tag string) OrderRequest {
+	side := OrderSideSell
+	if pos.Quantity < 0 {
+		side = OrderSideBuy
+	}
+	return OrderRequest{
+		Symbol:   pos.Symbol,
+		Side:     side,
+		Type:     OrderTypeMarket,
+		Quantity: pos.Quantity * direction(pos.Quantity),
+		Strategy: pos.Strategy,
+		Tags:     []string{tag},
+	}
+}
+
+// RunStopMonitor 按间隔检查持仓的止损、止盈、分批订单计划、条件订单链、定时平仓以及当日亏损上限，直到上下文取消
+func (e *BaseTradingEngine) RunStopMonitor(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := e.CheckStops(ctx); err != nil {
+				e.log().Error("检查止损失败: %v", err)
+			}
+			if _, err := e.CheckOrderPlans(ctx); err != nil {
+				e.log().Error("检查订单计划失败: %v", err)
+			}
+			if _, err := e.CheckOrderChains(ctx); err != nil {
+				e.log().Error("检查订单链失败: %v", err)
+			}
+			if _, err := e.CheckTimeExits(ctx); err != nil {
+				e.log().Error("检查定时平仓失败: %v", err)
+			}
+			if _, err := e.CheckDailyLoss(ctx); err != nil {
+				e.log().Error("检查单日亏损失败: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// restingBroker 是市价单按固定价格成交、其他订单挂单等待的测试券商
+type restingBroker struct {
+	fixedPriceBroker
+	rejectResting bool
+	canceled      []string
+}
+
+func (b *restingBroker) CancelOrder(ctx context.Context, order Order) error {
+	b.canceled = append(b.canceled, order.ID)
+	return nil
+}
+
+func (b *restingBroker) SubmitOrder(ctx context.Context, order Order) (*Order, error) {
+	if order.Type == OrderTypeMarket {
+		return b.fixedPriceBroker.SubmitOrder(ctx, order)
+	}
+	if b.rejectResting {
+		return nil, errors.New("stop orders not supported")
+	}
+	order.Status = OrderStatusAccepted
+	return &order, nil
+}
+
+// ocoBroker 是支持互相取消订单组的挂单测试券商
+type ocoBroker struct {
+	restingBroker
+	groups [][]Order
+}
+
+func (b *ocoBroker) SubmitOCO(ctx context.Context, orders []Order) ([]Order, error) {
+	b.groups = append(b.groups, orders)
+	accepted := make([]Order, len(orders))
+	for i, order := range orders {
+		order.Status = OrderStatusAccepted
+		accepted[i] = order
+	}
+	return accepted, nil
+}
+
+// fillProtective 模拟券商推送持仓的止损挂单全部成交的回报（内部函数）
+func fillProtective(t *testing.T, engine *BaseTradingEngine, price float64) (stop, takeProfit Order) {
+	t.Helper()
+	ctx := context.Background()
+	open, _ := engine.GetOpenOrders(ctx)
+	if len(open) != 2 {
+		t.Fatalf("应挂出止损和止盈两个订单: %+v", open)
+	}
+	for _, order := range open {
+		if order.Type == OrderTypeStop {
+			stop = order
+		} else {
+			takeProfit = order
+		}
+	}
+	if stop.OCOGroup == "" || stop.OCOGroup != takeProfit.OCOGroup {
+		t.Fatalf("止损和止盈应属于同一个订单组: %+v %+v", stop, takeProfit)
+	}
+
+	filled := stop
+	filled.Status = OrderStatusFilled
+	filled.FilledQty = stop.Quantity
+	filled.AvgFillPrice = price
+	update := OrderUpdate{Order: filled, Execution: &Execution{ID: "e1", Price: price, Quantity: stop.Quantity, BrokerExecID: "E1"}}
+	if err := engine.ApplyOrderUpdate(ctx, update); err != nil {
+		t.Fatalf("应用止损成交失败: %v", err)
+	}
+	return stop, takeProfit
+}
+
+func TestLocalStops(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, TakeProfitPercent: 5})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+
+	price := 99.0
+	quote := func(string) (float64, bool) { return price, true }
+	if triggers, _ := engine.checkStops(ctx, quote); len(triggers) != 0 {
+		t.Fatalf("未越过止损价不应触发: %+v", triggers)
+	}
+
+	price = 97
+	triggers, _ := engine.checkStops(ctx, quote)
+	if len(triggers) != 1 || triggers[0].Kind != TagStopLoss || triggers[0].OrderID == "" || triggers[0].Error != "" {
+		t.Fatalf("止损应被触发: %+v", triggers)
+	}
+	if _, err := engine.GetPosition(ctx, "AAPL"); err == nil {
+		t.Error("止损后持仓应已平仓")
+	}
+}
+
+func TestBrokerStops(t *testing.T) {
+	ctx := context.Background()
+	broker := &restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{StopMode: StopModeBroker}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, TakeProfitPercent: 5})
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	open, _ := engine.GetOpenOrders(ctx)
+	if len(open) != 2 {
+		t.Fatalf("应挂出止损和止盈两个订单: %+v", open)
+	}
+	for _, order := range open {
+		if !IsProtective(order) || order.Side != OrderSideSell || order.Quantity != 10 {
+			t.Errorf("挂单不正确: %+v", order)
+		}
+		if order.Type == OrderTypeStop && order.StopPrice != 98 || order.Type == OrderTypeLimit && order.Price != 105 {
+			t.Errorf("挂单价格不正确: %+v", order)
+		}
+	}
+
+	// 券商保护的持仓不在本地触发
+	quote := func(string) (float64, bool) { return 97, true }
+	if triggers, _ := engine.checkStops(ctx, quote); len(triggers) != 0 {
+		t.Errorf("券商保护的持仓不应在本地触发: %+v", triggers)
+	}
+
+	// 手动平仓后挂单被撤销
+	if _, err := engine.ClosePosition(ctx, "AAPL", 0); err != nil {
+		t.Fatalf("平仓失败: %v", err)
+	}
+	if open, _ := engine.GetOpenOrders(ctx); len(open) != 0 {
+		t.Errorf("平仓后挂单应被撤销: %+v", open)
+	}
+
+	// 券商拒绝挂单时由本地检查兜底
+	broker.rejectResting = true
+	if _, err := engine.SubmitOrder(ctx, "MSFT", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if triggers, _ := engine.checkStops(ctx, quote); len(triggers) != 1 {
+		t.Errorf("挂单失败的持仓应由本地触发止损: %+v", triggers)
+	}
+}
+
+func TestProtectiveSiblingCanceled(t *testing.T) {
+	ctx := context.Background()
+	broker := &restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{StopMode: StopModeBroker}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, TakeProfitPercent: 5})
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+
+	// 券商不支持订单组时，止损成交后由引擎撤销止盈挂单，不会反向开仓
+	_, takeProfit := fillProtective(t, engine, 98)
+	if len(broker.canceled) != 1 || broker.canceled[0] != takeProfit.ID {
+		t.Errorf("止损成交后应向券商撤销止盈挂单: %v", broker.canceled)
+	}
+	if open, _ := engine.GetOpenOrders(ctx); len(open) != 0 {
+		t.Errorf("止损成交后不应留有挂单: %+v", open)
+	}
+	if _, err := engine.GetPosition(ctx, "AAPL"); err == nil {
+		t.Error("止损成交后持仓应已平仓")
+	}
+}
+
+func TestBrokerStopsOCO(t *testing.T) {
+	ctx := context.Background()
+	broker := &ocoBroker{restingBroker: restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{StopMode: StopModeBroker}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, TakeProfitPercent: 5})
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if len(broker.groups) != 1 || len(broker.groups[0]) != 2 {
+		t.Fatalf("止损和止盈应作为一组提交: %+v", broker.groups)
+	}
+
+	// 券商在止损成交后撤销止盈挂单，引擎只更新本地状态
+	_, takeProfit := fillProtective(t, engine, 98)
+	if len(broker.canceled) != 0 {
+		t.Errorf("券商已撤销同组挂单，不应重复撤销: %v", broker.canceled)
+	}
+	if order, err := engine.GetOrder(ctx, takeProfit.ID); err != nil || order.Status != OrderStatusCanceled {
+		t.Errorf("止盈挂单应已撤销: %+v %v", order, err)
+	}
+	if len(broker.groups) != 1 {
+		t.Errorf("平仓后不应重新挂单: %+v", broker.groups)
+	}
+}
+
+func TestBrokerStopsRounding(t *testing.T) {
+	ctx := context.Background()
+	broker := &ocoBroker{restingBroker: restingBroker{fixedPriceBroker: fixedPriceBroker{price: 153.37}}}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{StopMode: StopModeBroker}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, TakeProfitPercent: 3})
+	engine.SetBroker(broker)
+	engine.SetRounding(RoundingConfig{Enabled: true, Symbols: map[string]SymbolRounding{"MSFT": {LotSize: 100}}})
+	engine.Enable()
+
+	// 止损价150.3026和止盈价157.9711取整到0.01后再提交给券商，卖出挂单向上取整
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if len(broker.groups) != 1 || len(broker.groups[0]) != 2 {
+		t.Fatalf("止损和止盈应作为一组提交: %+v", broker.groups)
+	}
+	for _, leg := range broker.groups[0] {
+		if leg.Type == OrderTypeStop && leg.StopPrice != 150.31 || leg.Type == OrderTypeLimit && leg.Price != 157.98 || leg.Quantity != 10 {
+			t.Errorf("券商收到的挂单价格未取整: %+v", leg)
+		}
+	}
+
+	// 部分成交的持仓不足整手，挂单数量向下取整到交易单位
+	resting, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "MSFT", Quantity: 200, Price: 100, Type: OrderTypeLimit, Side: OrderSideBuy})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	partial := *resting
+	partial.Status = OrderStatusPartial
+	partial.FilledQty = 150
+	partial.AvgFillPrice = 100
+	if err := engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: partial}); err != nil {
+		t.Fatalf("应用部分成交失败: %v", err)
+	}
+	if len(broker.groups) != 2 {
+		t.Fatalf("部分成交后应挂出止损和止盈: %+v", broker.groups)
+	}
+	for _, leg := range broker.groups[1] {
+		if leg.Quantity != 100 {
+			t.Errorf("挂单数量应取整到交易单位: %+v", leg)
+		}
+	}
+}
+
+// lockCheckingBroker 在挂单和撤单时检查引擎锁是否已释放的测试券商
+type lockCheckingBroker struct {
+	restingBroker
+	engine *BaseTradingEngine
+	locked int
+}
+
+func (b *lockCheckingBroker) check() {
+	if !b.engine.mu.TryLock() {
+		b.locked++
+		return
+	}
+	b.engine.mu.Unlock()
+}
+
+func (b *lockCheckingBroker) SubmitOrder(ctx context.Context, order Order) (*Order, error) {
+	if order.Type != OrderTypeMarket {
+		b.check()
+	}
+	return b.restingBroker.SubmitOrder(ctx, order)
+}
+
+func (b *lockCheckingBroker) CancelOrder(ctx context.Context, order Order) error {
+	b.check()
+	return b.restingBroker.CancelOrder(ctx, order)
+}
+
+func TestProtectiveOrdersOutsideLock(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{StopMode: StopModeBroker}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, TakeProfitPercent: 5})
+	broker := &lockCheckingBroker{restingBroker: restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}, engine: engine}
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	// 下单成交和成交回报触发的挂单与撤单都在释放引擎锁后调用券商
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	fillProtective(t, engine, 98)
+	if len(broker.canceled) != 1 {
+		t.Fatalf("止损成交后应撤销止盈挂单: %v", broker.canceled)
+	}
+	if broker.locked != 0 {
+		t.Errorf("调用券商时不应持有引擎锁: %d", broker.locked)
+	}
+}
@@ -0,0 +1,128 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/datasource"
+)
+
+// StrategyLimits 表示单个策略的交易限制，在账户级限制之外对带有策略归属的订单生效，为0的限制不检查
+type StrategyLimits struct {
+	MaxPositions int      `json:"max_positions" yaml:"max_positions"`   // 策略同时持有的股票数量上限
+	MaxDailyLoss float64  `json:"max_daily_loss" yaml:"max_daily_loss"` // 当日亏损（已实现加未实现）达到该金额后不再开仓
+	MaxNotional  float64  `json:"max_notional" yaml:"max_notional"`     // 策略持仓市值（绝对值）加订单金额的上限
+	TimeExit     TimeExit `json:"time_exit" yaml:"time_exit"`           // 策略持仓的定时平仓设置，非零字段覆盖账户级设置
+}
+
+// Validate 检查策略限制是否有效
+func (l StrategyLimits) Validate() error {
+	if l.MaxPositions < 0 || l.MaxDailyLoss < 0 || l.MaxNotional < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return l.TimeExit.Validate()
+}
+
+// strategyExposure 表示某个策略当前的持仓汇总（内部类型）
+type strategyExposure struct {
+	positions  int
+	notional   float64
+	unrealized float64
+	quantity   int64 // 策略在下单股票上的持仓数量，空头为负数
+	price      float64
+}
+
+// strategyExposure 汇总策略的持仓（内部方法，调用方持锁）
+func (e *BaseTradingEngine) strategyExposure(strategy, symbol string) strategyExposure {
+	var exposure strategyExposure
+	for _, pos := range e.positions {
+		if pos.Strategy != strategy || pos.Quantity == 0 {
+			continue
+		}
+		price := pos.CurrentPrice
+		if price <= 0 {
+			price = pos.EntryPrice
+		}
+		exposure.positions++
+		exposure.notional += math.Abs(float64(pos.Quantity) * price)
+		exposure.unrealized += pos.UnrealizedPnL
+		if pos.Symbol == symbol {
+			exposure.quantity += pos.Quantity
+			exposure.price = price
+		}
+	}
+	return exposure
+}
+
+// strategyRealizedPnL 返回策略在当日平仓交易的已实现盈亏（内部方法，调用方持锁）
+func (e *BaseTradingEngine) strategyRealizedPnL(strategy string, now time.Time) float64 {
+	year, month, day := now.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	pnl := 0.0
+	for _, trade := range e.trades {
+		if trade.Strategy == strategy && trade.ClosedAt != nil && !trade.ClosedAt.Before(start) {
+			pnl += trade.RealizedPnL
+		}
+	}
+	return pnl
+}
+
+// referencePrice 返回估算订单金额使用的价格：限价、止损价、到达报价，最后是持仓的当前价格（内部函数）
+func referencePrice(req OrderRequest, arrival datasource.Quote, fallback float64) float64 {
+	switch {
+	case req.Type == OrderTypeLimit && req.Price > 0:
+		return req.Price
+	case req.Type == OrderTypeStop && req.StopPrice > 0:
+		return req.StopPrice
+	case arrival.LastPrice > 0:
+		return arrival.LastPrice
+	case req.Side == OrderSideBuy && arrival.AskPrice > 0:
+		return arrival.AskPrice
+	case req.Side == OrderSideSell && arrival.BidPrice > 0:
+		return arrival.BidPrice
+	}
+	return fallback
+}
+
+// checkStrategyLimits 检查订单是否超出其策略的交易限制（内部方法，调用方持锁）
+// 只有增加策略持仓的订单受限制，减仓和平仓的订单始终允许，避免策略在亏损后无法退出
+func (e *BaseTradingEngine) checkStrategyLimits(req OrderRequest, arrival datasource.Quote, now time.Time) error {
+	if req.Strategy == "" {
+		return nil
+	}
+	limits, ok := e.limits.Strategies[req.Strategy]
+	if !ok {
+		return nil
+	}
+
+	exposure := e.strategyExposure(req.Strategy, req.Symbol)
+	increasing := exposure.quantity == 0 || (exposure.quantity > 0) == (req.Side == OrderSideBuy)
+	if !increasing {
+		return nil
+	}
+
+	if limits.MaxPositions > 0 && exposure.quantity == 0 && exposure.positions >= limits.MaxPositions {
+		return fmt.Errorf("%w: strategy %s maximum positions reached (%d)", ErrTradeLimitExceeded, req.Strategy, limits.MaxPositions)
+	}
+
+	if limits.MaxDailyLoss > 0 {
+		pnl := e.strategyRealizedPnL(req.Strategy, now) + exposure.unrealized
+		if -pnl >= limits.MaxDailyLoss {
+			return fmt.Errorf("%w: strategy %s daily loss %.2f reached limit %.2f", ErrTradeLimitExceeded, req.Strategy, -pnl, limits.MaxDailyLoss)
+		}
+	}
+
+	if limits.MaxNotional > 0 {
+		price := referencePrice(req, arrival, exposure.price)
+		if price <= 0 {
+			return fmt.Errorf("%w: strategy %s has a notional limit but no price is available for %s", ErrTradeLimitExceeded, req.Strategy, req.Symbol)
+		}
+		notional := exposure.notional + float64(req.Quantity)*price
+		if notional > limits.MaxNotional {
+			return fmt.Errorf("%w: strategy %s notional %.2f would exceed limit %.2f", ErrTradeLimitExceeded, req.Strategy, notional, limits.MaxNotional)
+		}
+	}
+	return nil
+}
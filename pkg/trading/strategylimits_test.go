@@ -0,0 +1,46 @@
+package trading
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStrategyLimits(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{
+		MaxPositions: 10,
+		Strategies: map[string]StrategyLimits{
+			"momentum": {MaxPositions: 1, MaxDailyLoss: 15, MaxNotional: 1000},
+		},
+	})
+	broker := &fixedPriceBroker{price: 10}
+	engine.SetBroker(broker)
+	engine.Enable()
+
+	buy := func(symbol string, quantity int64, strategy string) error {
+		_, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: symbol, Side: OrderSideBuy, Type: OrderTypeLimit, Price: 10, Quantity: quantity, Strategy: strategy})
+		return err
+	}
+
+	if err := buy("AAPL", 50, "momentum"); err != nil {
+		t.Fatalf("未超出策略限制的订单被拒绝: %v", err)
+	}
+	if err := buy("MSFT", 10, "momentum"); GetRejectCode(err) != RejectCodeRiskLimit {
+		t.Errorf("超出策略持仓数量的订单应被拒绝: %v", err)
+	}
+	if err := buy("AAPL", 60, "momentum"); GetRejectCode(err) != RejectCodeRiskLimit {
+		t.Errorf("超出策略持仓市值的订单应被拒绝: %v", err)
+	}
+	if err := buy("MSFT", 10, ""); err != nil {
+		t.Errorf("没有策略归属的订单不受策略限制: %v", err)
+	}
+
+	// 亏损25后只允许减仓
+	broker.price = 9.5
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Side: OrderSideSell, Type: OrderTypeMarket, Quantity: 50, Strategy: "momentum"}); err != nil {
+		t.Fatalf("减仓订单不应受策略限制: %v", err)
+	}
+	if err := buy("AAPL", 10, "momentum"); GetRejectCode(err) != RejectCodeRiskLimit {
+		t.Errorf("达到当日亏损限制后开仓应被拒绝: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+)
+
+// OrderUpdate 表示券商推送的一次订单状态变化，如成交、撤单、拒绝或过期
+type OrderUpdate struct {
+	Order     Order      `json:"order"`               // 券商侧的最新订单，FilledQty、AvgFillPrice 和 Commission 为累计值
+	Execution *Execution `json:"execution,omitempty"` // 本次回报包含的成交，没有成交时为空
+}
+
+// ExecutionStream 是可以异步推送订单状态变化的券商，如通过WebSocket或FIX会话接收回报的券商
+type ExecutionStream interface {
+	// OrderUpdates 返回订单状态变化通道，券商关闭时关闭通道
+	OrderUpdates() <-chan OrderUpdate
+}
+
+// Executions 返回成交回报通道，下单时立即成交和券商异步推送的成交都会写入，通道满时丢弃
+func (e *BaseTradingEngine) Executions() <-chan Execution {
+	return e.executionChan
+}
+
+// findOrder 按订单ID或券商订单ID查找订单（内部方法，调用方持锁）
+func (e *BaseTradingEngine) findOrder(order Order) (Order, bool) {
+	if existing, ok := e.orders[order.ID]; ok {
+		return existing, true
+	}
+	if order.BrokerOrderID == "" {
+		return Order{}, false
+	}
+	for _, existing := range e.orders {
+		if existing.BrokerOrderID == order.BrokerOrderID {
+			return existing, true
+		}
+	}
+	return Order{}, false
+}
+
+// ApplyOrderUpdate 将券商推送的订单状态变化应用到订单和持仓
+// 按累计成交数量计算新增成交，重复或过期的回报会被忽略；成交后重新挂出该持仓的券商止损和止盈订单
+func (e *BaseTradingEngine) ApplyOrderUpdate(ctx context.Context, update OrderUpdate) error {
+	e.mu.Lock()
+	protect := "" // 成交后需要重新挂出止损和止盈的持仓，释放锁后再调用券商
+	defer func() {
+		e.mu.Unlock()
+		if protect != "" {
+			e.syncProtectiveOrders(ctx, protect)
+		}
+	}()
+
+	current, ok := e.findOrder(update.Order)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, update.Order.ID)
+	}
+
+	reported := update.Order
+	delta := reported.FilledQty - current.FilledQty
+	if reported.Status == current.Status && delta <= 0 {
+		return nil
+	}
+	if !isOpenOrder(current) && delta <= 0 {
+		// 已终结的订单不会回到未完成状态
+		return nil
+	}
+
+	now := e.now()
+	next := current
+	next.Status = reported.Status
+	next.UpdatedAt = now
+	if reported.BrokerOrderID != "" {
+		next.BrokerOrderID = reported.BrokerOrderID
+	}
+	if reported.RejectReason != "" {
+		next.RejectReason = reported.RejectReason
+	}
+
+	var fill *Order
+	if delta > 0 {
+		filledAt := now
+		price := 0.0
+		if update.Execution != nil {
+			price = update.Execution.Price
+			if !update.Execution.ExecutedAt.IsZero() {
+				filledAt = update.Execution.ExecutedAt
+			}
+		}
+		if price <= 0 {
+			// 没有单笔成交价时由累计均价推算
+			price = (reported.AvgFillPrice*float64(reported.FilledQty) - current.AvgFillPrice*float64(current.FilledQty)) / float64(delta)
+		}
+
+		f := current
+		f.Status = OrderStatusFilled
+		f.FilledQty = delta
+		f.AvgFillPrice = price
+		f.FilledAt = &filledAt
+		f.Commission = reported.Commission - current.Commission
+		fill = &f
+
+		next.FilledQty = reported.FilledQty
+		next.AvgFillPrice = reported.AvgFillPrice
+		if next.AvgFillPrice <= 0 {
+			next.AvgFillPrice = (current.AvgFillPrice*float64(current.FilledQty) + price*float64(delta)) / float64(next.FilledQty)
+		}
+		next.Commission = reported.Commission
+		next.FilledAt = &filledAt
+	}
+
+	if err := e.recordUpdate(next, fill); err != nil {
+		e.logLocked().Error("记录订单%s的状态更新失败: %v", next.ID, err)
+	}
+	e.orders[next.ID] = next
+
+	if fill != nil {
+		e.updatePosition(*fill)
+		e.recordFill(next)
+
+		execution := e.fillExecution(*fill)
+		if update.Execution != nil {
+			execution.ID = update.Execution.ID
+			execution.BrokerExecID = update.Execution.BrokerExecID
+		}
+		e.emitExecution(execution)
+	}
+
+	switch next.Status {
+	case OrderStatusAccepted:
+		e.publish(events.TopicOrders, EventOrderAccepted, next)
+	case OrderStatusFilled, OrderStatusPartial:
+		if fill != nil {
+			e.publish(events.TopicOrders, EventOrderFilled, next)
+		}
+	case OrderStatusCanceled, OrderStatusExpired:
+		e.publish(events.TopicOrders, EventOrderCanceled, next)
+	case OrderStatusRejected:
+		e.publish(events.TopicOrders, EventOrderRejected, OrderRejection{
+			Request:   orderRequest(next),
+			Code:      RejectCodeBrokerReject,
+			Reason:    next.RejectReason,
+			Timestamp: now,
+		})
+	}
+
+	if fill != nil {
+		protect = e.positionKey(next)
+	}
+	return nil
+}
+
+// orderRequest 由订单还原下单请求（内部函数）
+func orderRequest(order Order) OrderRequest {
+	return OrderRequest{
+		Symbol:        order.Symbol,
+		Quantity:      order.Quantity,
+		Price:         order.Price,
+		StopPrice:     order.StopPrice,
+		Type:          order.Type,
+		Side:          order.Side,
+		Strategy:      order.Strategy,
+		ClientOrderID: order.ClientOrderID,
+		Tags:          order.Tags,
+		StopLoss:      order.StopLoss,
+		TakeProfit:    order.TakeProfit,
+		TimeExit:      order.TimeExit,
+		Metadata:      order.Metadata,
+	}
+}
+
+// recordUpdate 将券商推送的订单状态变化写入预写日志（内部方法，调用方持锁）
+func (e *BaseTradingEngine) recordUpdate(order Order, fill *Order) error {
+	if e.wal == nil || e.replaying {
+		return nil
+	}
+	return e.wal.Append(WALRecord{Type: WALOrderUpdated, Timestamp: e.now(), Order: &order, Fill: fill})
+}
+
+// RunExecutionStream 消费券商推送的订单状态变化，直到上下文取消或券商关闭通道
+// 券商不支持推送时直接返回
+func (e *BaseTradingEngine) RunExecutionStream(ctx context.Context) error {
+	stream, ok := e.GetBroker().(ExecutionStream)
+	if !ok {
+		return nil
+	}
+	updates := stream.OrderUpdates()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if err := e.ApplyOrderUpdate(ctx, update); err != nil {
+				e.log().Error("应用订单%s的状态更新失败: %v", update.Order.ID, err)
+			}
+		}
+	}
+}
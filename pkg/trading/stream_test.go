@@ -0,0 +1,121 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyOrderUpdate(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	wal, err := OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	engine.SetBroker(&unackedBroker{})
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	order, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Side: OrderSideBuy, Type: OrderTypeLimit, Price: 10.1, Quantity: 100})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+
+	partial := *order
+	partial.Status = OrderStatusPartial
+	partial.FilledQty = 40
+	partial.AvgFillPrice = 10
+	update := OrderUpdate{Order: partial, Execution: &Execution{ID: "e1", Price: 10, Quantity: 40, BrokerExecID: "E1"}}
+	for i := 0; i < 2; i++ {
+		// 重复的回报被忽略
+		if err := engine.ApplyOrderUpdate(ctx, update); err != nil {
+			t.Fatalf("应用部分成交失败: %v", err)
+		}
+	}
+	if pos, _ := engine.GetPosition(ctx, "AAPL"); pos == nil || pos.Quantity != 40 {
+		t.Fatalf("部分成交后的持仓不正确: %+v", pos)
+	}
+	if execution := <-engine.Executions(); execution.BrokerExecID != "E1" || execution.Quantity != 40 {
+		t.Errorf("成交回报不正确: %+v", execution)
+	}
+
+	// 没有单笔成交价时由累计均价推算：(10.06×100 − 10×40) / 60 = 10.1
+	filled := partial
+	filled.Status = OrderStatusFilled
+	filled.FilledQty = 100
+	filled.AvgFillPrice = 10.06
+	if err := engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: filled}); err != nil {
+		t.Fatalf("应用全部成交失败: %v", err)
+	}
+	execution := <-engine.Executions()
+	if execution.Quantity != 60 || math.Abs(execution.Price-10.1) > 1e-9 {
+		t.Errorf("推算的成交不正确: %+v", execution)
+	}
+
+	// 撤单和拒绝
+	other, _ := engine.PlaceOrder(ctx, OrderRequest{Symbol: "MSFT", Side: OrderSideBuy, Type: OrderTypeLimit, Price: 300, Quantity: 1})
+	canceled := *other
+	canceled.Status = OrderStatusCanceled
+	if err := engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: canceled}); err != nil {
+		t.Fatalf("应用撤单失败: %v", err)
+	}
+	if got, _ := engine.GetOrder(ctx, other.ID); got.Status != OrderStatusCanceled {
+		t.Errorf("订单应已撤销: %+v", got)
+	}
+	if err := engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: Order{ID: "unknown"}}); err == nil {
+		t.Error("未知订单应返回错误")
+	}
+	wal.Close()
+
+	// 异步成交从预写日志恢复
+	wal, err = OpenFileWAL(path, false)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	recovered.SetWAL(wal)
+	if _, err := recovered.Recover(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	pos, err := recovered.GetPosition(ctx, "AAPL")
+	if err != nil || pos.Quantity != 100 || math.Abs(pos.EntryPrice-10.06) > 1e-9 {
+		t.Errorf("恢复的持仓不正确: %+v %v", pos, err)
+	}
+}
+
+func TestProtectiveOrderFill(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{StopMode: StopModeBroker}, TradingLimits{MaxPositions: 10, StopLossPercent: 2, TakeProfitPercent: 5})
+	engine.SetBroker(&restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}})
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	open, _ := engine.GetOpenOrders(ctx)
+	var stop Order
+	for _, order := range open {
+		if order.Type == OrderTypeStop {
+			stop = order
+		}
+	}
+
+	// 券商触发止损单后，止盈单被撤销
+	stop.Status = OrderStatusFilled
+	stop.FilledQty = 10
+	stop.AvgFillPrice = 98
+	if err := engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: stop}); err != nil {
+		t.Fatalf("应用止损成交失败: %v", err)
+	}
+	if _, err := engine.GetPosition(ctx, "AAPL"); err == nil {
+		t.Error("止损成交后持仓应已平仓")
+	}
+	if open, _ := engine.GetOpenOrders(ctx); len(open) != 0 {
+		t.Errorf("止损成交后止盈单应被撤销: %+v", open)
+	}
+}
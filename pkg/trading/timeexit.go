@@ -0,0 +1,130 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/events"
+	"github.com/yourusername/qhft-system/pkg/schedule"
+)
+
+// 定时平仓订单的标签
+const (
+	TagMaxHold      = "max_hold"      // 达到最长持有时间
+	TagSessionClose = "session_close" // 常规交易收盘前平仓
+)
+
+// TimeExit 表示持仓的定时平仓设置，为0的字段不检查
+type TimeExit struct {
+	MaxHoldMinutes         int `json:"max_hold_minutes,omitempty" yaml:"max_hold_minutes"`                   // 开仓后最长持有的分钟数
+	ExitBeforeCloseMinutes int `json:"exit_before_close_minutes,omitempty" yaml:"exit_before_close_minutes"` // 在常规交易收盘前若干分钟平仓，需要交易日历
+}
+
+// Validate 检查定时平仓设置是否有效
+func (t TimeExit) Validate() error {
+	if t.MaxHoldMinutes < 0 || t.ExitBeforeCloseMinutes < 0 {
+		return fmt.Errorf("time exit minutes must not be negative")
+	}
+	return nil
+}
+
+// merge 用覆盖设置中的非零字段代替当前设置（内部方法）
+func (t TimeExit) merge(override TimeExit) TimeExit {
+	if override.MaxHoldMinutes > 0 {
+		t.MaxHoldMinutes = override.MaxHoldMinutes
+	}
+	if override.ExitBeforeCloseMinutes > 0 {
+		t.ExitBeforeCloseMinutes = override.ExitBeforeCloseMinutes
+	}
+	return t
+}
+
+// SetCalendar 设置交易日历，用于确定收盘前平仓的时间，未设置时不检查 ExitBeforeCloseMinutes
+func (e *BaseTradingEngine) SetCalendar(calendar *schedule.Calendar) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calendar = calendar
+}
+
+// timeExit 返回持仓生效的定时平仓设置：账户级设置依次被策略和开仓订单的设置覆盖（内部方法，调用方持锁）
+func (e *BaseTradingEngine) timeExit(pos Position) TimeExit {
+	exit := e.limits.TimeExit
+	if limits, ok := e.limits.Strategies[pos.Strategy]; ok && pos.Strategy != "" {
+		exit = exit.merge(limits.TimeExit)
+	}
+	if pos.TimeExit != nil {
+		exit = exit.merge(*pos.TimeExit)
+	}
+	return exit
+}
+
+// hasExitOrder 检查持仓是否已有带指定标签、未完成的市价平仓单（内部方法，调用方持锁）
+func (e *BaseTradingEngine) hasExitOrder(key string, tags ...string) bool {
+	for _, order := range e.orders {
+		if order.Type != OrderTypeMarket || !isOpenOrder(order) || e.positionKey(order) != key {
+			continue
+		}
+		for _, tag := range tags {
+			if slices.Contains(order.Tags, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckTimeExits 检查持仓是否达到最长持有时间或进入收盘前的平仓时间，到期时以市价平仓并返回触发记录
+// 每个触发在 positions 主题发布 time_exit 事件；收盘前平仓只在常规交易时段内检查
+func (e *BaseTradingEngine) CheckTimeExits(ctx context.Context) ([]StopTrigger, error) {
+	return e.checkTimeExits(ctx, e.now())
+}
+
+// checkTimeExits 按给定时间检查定时平仓（内部方法）
+func (e *BaseTradingEngine) checkTimeExits(ctx context.Context, now time.Time) ([]StopTrigger, error) {
+	if !e.IsEnabled() {
+		return nil, nil
+	}
+
+	e.mu.RLock()
+	var session schedule.Session
+	hasSession := false
+	if e.calendar != nil {
+		session, hasSession = e.calendar.Session(now)
+	}
+	var due []StopTrigger
+	for key, pos := range e.positions {
+		if pos.Quantity == 0 || e.hasExitOrder(key, TagStopLoss, TagTakeProfit, TagDailyLoss, TagMaxHold, TagSessionClose) {
+			continue
+		}
+		exit := e.timeExit(pos)
+		kind := ""
+		switch {
+		case exit.MaxHoldMinutes > 0 && !now.Before(pos.OpenedAt.Add(time.Duration(exit.MaxHoldMinutes)*time.Minute)):
+			kind = TagMaxHold
+		case exit.ExitBeforeCloseMinutes > 0 && hasSession && now.Before(session.Close) &&
+			!now.Before(session.Close.Add(-time.Duration(exit.ExitBeforeCloseMinutes)*time.Minute)):
+			kind = TagSessionClose
+		default:
+			continue
+		}
+		due = append(due, StopTrigger{Position: pos, Kind: kind, Price: pos.CurrentPrice, Time: now})
+	}
+	e.mu.RUnlock()
+
+	for i := range due {
+		trigger := &due[i]
+		order, err := e.PlaceOrder(ctx, exitRequest(trigger.Position, trigger.Kind))
+		if err != nil {
+			trigger.Error = err.Error()
+		} else {
+			trigger.OrderID = order.ID
+		}
+
+		e.mu.RLock()
+		e.publish(events.TopicPositions, EventTimeExit, *trigger)
+		e.mu.RUnlock()
+	}
+	return due, nil
+}
@@ -0,0 +1,81 @@
+package trading
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/schedule"
+)
+
+func TestTimeExits(t *testing.T) {
+	ctx := context.Background()
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{
+		MaxPositions: 10,
+		TimeExit:     TimeExit{ExitBeforeCloseMinutes: 10},
+		Strategies:   map[string]StrategyLimits{"scalp": {TimeExit: TimeExit{MaxHoldMinutes: 120}}},
+	})
+	engine.SetBroker(&fixedPriceBroker{price: 100})
+	engine.Enable()
+
+	for _, req := range []OrderRequest{
+		{Symbol: "AAPL", Strategy: "scalp"},
+		{Symbol: "MSFT", Strategy: "swing"},
+		{Symbol: "NVDA", Strategy: "scalp", TimeExit: &TimeExit{MaxHoldMinutes: 30}},
+	} {
+		req.Side, req.Type, req.Quantity = OrderSideBuy, OrderTypeMarket, 10
+		if _, err := engine.PlaceOrder(ctx, req); err != nil {
+			t.Fatalf("买入%s失败: %v", req.Symbol, err)
+		}
+	}
+	opened := time.Now()
+
+	if triggers, _ := engine.checkTimeExits(ctx, opened.Add(10*time.Minute)); len(triggers) != 0 {
+		t.Fatalf("未到期的持仓不应平仓: %+v", triggers)
+	}
+
+	// 订单的设置覆盖策略的2小时
+	triggers, _ := engine.checkTimeExits(ctx, opened.Add(45*time.Minute))
+	if len(triggers) != 1 || triggers[0].Position.Symbol != "NVDA" || triggers[0].Kind != TagMaxHold || triggers[0].OrderID == "" {
+		t.Fatalf("NVDA应在30分钟后平仓: %+v", triggers)
+	}
+
+	triggers, _ = engine.checkTimeExits(ctx, opened.Add(3*time.Hour))
+	if len(triggers) != 1 || triggers[0].Position.Symbol != "AAPL" || triggers[0].Kind != TagMaxHold {
+		t.Fatalf("AAPL应按策略设置在2小时后平仓: %+v", triggers)
+	}
+
+	// 设置交易日历后，在交易日收盘前10分钟平掉剩余持仓，收盘后不再检查
+	calendar, err := schedule.NewCalendar("", nil, nil)
+	if err != nil {
+		t.Fatalf("创建交易日历失败: %v", err)
+	}
+	engine.SetCalendar(calendar)
+	session, ok := calendar.NextSession(opened.AddDate(0, 0, 1))
+	if !ok {
+		t.Fatal("找不到交易日")
+	}
+	if triggers, _ := engine.checkTimeExits(ctx, session.Close); len(triggers) != 0 {
+		t.Errorf("收盘后不应平仓: %+v", triggers)
+	}
+	if triggers, _ := engine.checkTimeExits(ctx, session.Close.Add(-15*time.Minute)); len(triggers) != 0 {
+		t.Errorf("收盘前15分钟不应平仓: %+v", triggers)
+	}
+	triggers, _ = engine.checkTimeExits(ctx, session.Close.Add(-5*time.Minute))
+	if len(triggers) != 1 || triggers[0].Position.Symbol != "MSFT" || triggers[0].Kind != TagSessionClose {
+		t.Fatalf("MSFT应在收盘前平仓: %+v", triggers)
+	}
+	if positions, _ := engine.GetPositions(ctx); len(positions) != 0 {
+		t.Errorf("所有持仓应已平仓: %+v", positions)
+	}
+}
+
+func TestTimeExitMerge(t *testing.T) {
+	base := TimeExit{MaxHoldMinutes: 60, ExitBeforeCloseMinutes: 10}
+	if merged := base.merge(TimeExit{MaxHoldMinutes: 30}); merged.MaxHoldMinutes != 30 || merged.ExitBeforeCloseMinutes != 10 {
+		t.Errorf("只有非零字段覆盖: %+v", merged)
+	}
+	if err := (TimeExit{MaxHoldMinutes: -1}).Validate(); err == nil {
+		t.Error("负数应返回错误")
+	}
+}
@@ -57,13 +57,66 @@ type Order struct {
 	RejectReason  string      `json:"reject_reason,omitempty"`
 	ClientOrderID string      `json:"client_order_id,omitempty"`
 	BrokerOrderID string      `json:"broker_order_id,omitempty"`
+	Strategy      string      `json:"strategy,omitempty"`
 	Tags          []string    `json:"tags,omitempty"`
+	OCOGroup      string      `json:"oco_group,omitempty"`   // 互相取消的订单组，同一持仓的止损和止盈挂单属于同一组
+	StopLoss      float64     `json:"stop_loss,omitempty"`   // 开仓成交后持仓的止损价，见 OrderRequest.StopLoss
+	TakeProfit    float64     `json:"take_profit,omitempty"` // 开仓成交后持仓的止盈价
+	TimeExit      *TimeExit   `json:"time_exit,omitempty"`   // 开仓成交后持仓的定时平仓设置，见 OrderRequest.TimeExit
+	Metadata      map[string]string `json:"metadata,omitempty"` // 集成方附加的自定义元数据，见 OrderRequest.Metadata
 }
 
+// OrderRequest 表示一次下单请求
+type OrderRequest struct {
+	Symbol        string    `json:"symbol"`
+	Quantity      int64     `json:"quantity"`
+	Price         float64   `json:"price"`
+	StopPrice     float64   `json:"stop_price,omitempty"`
+	Type          OrderType `json:"type"`
+	Side          OrderSide `json:"side"`
+	Strategy      string    `json:"strategy,omitempty"`
+	ClientOrderID string    `json:"client_order_id,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+
+	// MaxLatencyMillis 是券商确认订单的最长等待时间（毫秒），超时后引擎自动撤单，为0时只使用上下文的截止时间
+	MaxLatencyMillis int64 `json:"max_latency_ms,omitempty"`
+
+	// StopLoss 和 TakeProfit 使订单成为括号订单：开仓或加仓成交后用作持仓的止损和止盈价，
+	// 代替按 TradingLimits 百分比计算的默认值，为0时使用默认值。可以由 StopPlan.Bracket 设置
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	TakeProfit float64 `json:"take_profit,omitempty"`
+
+	// TimeExit 覆盖开仓后持仓的定时平仓设置，非零字段优先于策略和账户级的设置
+	TimeExit *TimeExit `json:"time_exit,omitempty"`
+
+	// Metadata 是集成方附加的自定义键值，如外部系统的关联ID。引擎不解释其内容，
+	// 原样保存在订单和预写日志中，随成交传递到持仓和交易，并写入拒单日志，上限见 ValidateMetadata
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// RejectCode 表示机器可读的拒单原因代码
+type RejectCode string
+
+// 拒单原因代码常量
+const (
+	RejectCodeTradingDisabled RejectCode = "TRADING_DISABLED" // 交易已禁用
+	RejectCodeInvalidParams   RejectCode = "INVALID_PARAMS"   // 订单参数无效
+	RejectCodeRiskLimit       RejectCode = "RISK_LIMIT"       // 超出风控限制
+	RejectCodeBrokerReject    RejectCode = "BROKER_REJECT"    // 券商拒单
+	RejectCodeInternal        RejectCode = "INTERNAL"         // 内部错误
+	RejectCodeDeadline        RejectCode = "DEADLINE_EXCEEDED" // 超出确认延迟预算
+	RejectCodeDuplicate       RejectCode = "DUPLICATE_ORDER"   // 重复的开仓订单
+	RejectCodeRestricted      RejectCode = "SYMBOL_RESTRICTED" // 股票在受限列表中
+	RejectCodeStrategyPaused  RejectCode = "STRATEGY_PAUSED"   // 策略已被暂停
+	RejectCodeHalted          RejectCode = "SYMBOL_HALTED"     // 股票已停牌
+	RejectCodeShortRestricted RejectCode = "SHORT_RESTRICTED"  // 卖空限制生效，订单价格不满足要求
+	RejectCodeNoBorrow        RejectCode = "NO_BORROW"         // 无法借券卖空
+)
+
 // Position 表示持仓
 type Position struct {
 	Symbol        string    `json:"symbol"`
-	Quantity      int64     `json:"quantity"`
+	Quantity      int64     `json:"quantity"` // 对冲模式下空头持仓为负数
 	EntryPrice    float64   `json:"entry_price"`
 	CurrentPrice  float64   `json:"current_price"`
 	MarketValue   float64   `json:"market_value"`
@@ -75,6 +128,9 @@ type Position struct {
 	StopLoss      float64   `json:"stop_loss,omitempty"`
 	TakeProfit    float64   `json:"take_profit,omitempty"`
 	Tags          []string  `json:"tags,omitempty"`
+	Strategy      string    `json:"strategy,omitempty"` // 开仓订单的策略，对冲模式下按策略区分持仓
+	TimeExit      *TimeExit `json:"time_exit,omitempty"` // 开仓订单指定的定时平仓设置
+	Metadata      map[string]string `json:"metadata,omitempty"` // 开仓订单的自定义元数据
 }
 
 // Account 表示交易账户
@@ -101,6 +157,7 @@ type Account struct {
 	MaxPositionSize        int64     `json:"max_position_size"`
 	MaxPositionValuePercent float64   `json:"max_position_value_percent"`
 	MaxDailyTrades         int       `json:"max_daily_trades"`
+	PositionMode           PositionMode `json:"position_mode"`
 }
 
 // Execution 表示交易执行记录
@@ -141,7 +198,7 @@ type Trade struct {
 	ExitOrder      *Order     `json:"exit_order,omitempty"`
 	EntryPrice     float64    `json:"entry_price"`
 	ExitPrice      float64    `json:"exit_price,omitempty"`
-	Quantity       int64      `json:"quantity"`
+	Quantity       int64      `json:"quantity"` // 空头交易为负数
 	RealizedPnL    float64    `json:"realized_pnl"`
 	RealizedPnLPercent float64 `json:"realized_pnl_percent"`
 	Commission     float64    `json:"commission"`
@@ -151,6 +208,11 @@ type Trade struct {
 	Tags           []string   `json:"tags,omitempty"`
 	Notes          string     `json:"notes,omitempty"`
 	Strategy       string     `json:"strategy,omitempty"`
+	Setups         []string   `json:"setups,omitempty"`      // 复盘时标注的交易形态，如 breakout、pullback
+	Screenshots    []string   `json:"screenshots,omitempty"` // 复盘截图的路径或URL
+	Grade          string     `json:"grade,omitempty"`       // 复盘评分，A到F
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"` // 开仓和平仓订单的自定义元数据，同名的键以平仓订单为准
 }
 
 // BrokerConfig 表示券商配置
@@ -161,8 +223,19 @@ type BrokerConfig struct {
 	AccountID    string `json:"account_id" yaml:"account_id"`
 	IsPaperTrading bool   `json:"is_paper_trading" yaml:"is_paper_trading"`
 	BaseURL      string `json:"base_url" yaml:"base_url"`
+	PositionMode PositionMode `json:"position_mode" yaml:"position_mode"` // 账户的持仓模式，为空时为净额模式
+	StopMode     StopMode     `json:"stop_mode" yaml:"stop_mode"`         // 止损和止盈的管理方式，为空时由引擎本地管理
 }
 
+// PositionMode 表示账户的持仓模式
+type PositionMode string
+
+// 持仓模式常量
+const (
+	PositionModeNetting PositionMode = "netting" // 净额模式：每只股票一个持仓，卖出减少多头
+	PositionModeHedging PositionMode = "hedging" // 对冲模式：每个策略在同一股票上有独立的多头或空头持仓
+)
+
 // TradingLimits 表示交易限制
 type TradingLimits struct {
 	MaxPositions          int     `json:"max_positions" yaml:"max_positions"`
@@ -170,4 +243,12 @@ type TradingLimits struct {
 	MaxDailyTrades        int     `json:"max_daily_trades" yaml:"max_daily_trades"`
 	StopLossPercent       float64 `json:"stop_loss_percent" yaml:"stop_loss_percent"`
 	TakeProfitPercent     float64 `json:"take_profit_percent" yaml:"take_profit_percent"`
+	// DuplicateWindowSeconds 是重复开仓保护的时间窗口（秒），为0时不检查
+	DuplicateWindowSeconds int `json:"duplicate_window_seconds" yaml:"duplicate_window_seconds"`
+	// MaxDailyLossPercent 是账户当日亏损（已实现加未实现）占当日起始权益的上限，达到后撤销所有订单、平掉所有持仓并停止开仓到次日，为0时不检查
+	MaxDailyLossPercent float64 `json:"max_daily_loss_percent" yaml:"max_daily_loss_percent"`
+	// Strategies 是按策略名称配置的交易限制，对 OrderRequest.Strategy 匹配的订单生效
+	Strategies map[string]StrategyLimits `json:"strategies,omitempty" yaml:"strategies"`
+	// TimeExit 是持仓的定时平仓设置，可以按策略、监控项或订单覆盖
+	TimeExit TimeExit `json:"time_exit" yaml:"time_exit"`
 } 
\ No newline at end of file
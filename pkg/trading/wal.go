@@ -0,0 +1,179 @@
+package trading
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 预写日志记录类型常量
+const (
+	WALOrderSubmitted     = "order_submitted"     // 订单已生成，尚未提交到券商
+	WALOrderAccepted      = "order_accepted"      // 券商已接受
+	WALOrderRejected      = "order_rejected"      // 券商拒绝
+	WALOrderFilled        = "order_filled"        // 订单已成交
+	WALOrderCanceled      = "order_canceled"      // 订单已取消
+	WALOrderUpdated       = "order_updated"       // 券商推送的订单状态变化，可能包含新增成交
+	WALEngineEnabled      = "engine_enabled"      // 引擎已启用（仅用于审计）
+	WALEngineDisabled     = "engine_disabled"     // 引擎已禁用（仅用于审计）
+	WALLimitsUpdated      = "limits_updated"      // 交易限制已更新（仅用于审计）
+	WALTradeImported      = "trade_imported"      // 从券商对账单导入的历史交易
+	WALSymbolRestricted   = "symbol_restricted"   // 股票加入受限列表
+	WALSymbolUnrestricted = "symbol_unrestricted" // 股票移出受限列表
+	WALTradeReviewed      = "trade_reviewed"      // 交易的复盘记录已修改
+	WALOrderChainUpdated  = "order_chain_updated" // 条件订单链的最新状态
+	WALCashRecorded       = "cash_recorded"       // 成交以外的现金变动分录，如出入金和股息
+	WALDividendEntitled   = "dividend_entitled"   // 持仓在除息日获得的应收分红
+)
+
+// WALRecord 表示预写日志中的一条记录
+type WALRecord struct {
+	Seq         uint64               `json:"seq"`
+	Type        string               `json:"type"`
+	Timestamp   time.Time            `json:"timestamp"`
+	Order       *Order               `json:"order,omitempty"`
+	Limits      *TradingLimits       `json:"limits,omitempty"`
+	Trade       *Trade               `json:"trade,omitempty"`
+	Restriction *RestrictedSymbol    `json:"restriction,omitempty"`
+	Fill        *Order               `json:"fill,omitempty"` // 订单状态变化中的新增成交，FilledQty 为本次成交数量
+	Review      *TradeReview         `json:"review,omitempty"`
+	Chain       *OrderChain          `json:"chain,omitempty"`
+	Ledger      *LedgerEntry         `json:"ledger,omitempty"`
+	Dividend    *DividendEntitlement `json:"dividend,omitempty"` // 应收分红，派息时与现金分录写入同一条记录
+}
+
+// WAL 定义了引擎状态预写日志的接口
+type WAL interface {
+	// Append 追加一条记录，序号由实现分配
+	Append(record WALRecord) error
+
+	// Replay 按写入顺序回放所有记录
+	Replay(fn func(record WALRecord) error) error
+
+	// Close 关闭日志
+	Close() error
+}
+
+// FileWAL 是基于文件的追加写预写日志，每行一条JSON记录
+type FileWAL struct {
+	mu         sync.Mutex
+	path       string
+	file       *os.File
+	syncWrites bool
+	seq        uint64
+}
+
+// OpenFileWAL 打开或创建预写日志文件，syncWrites 为true时每次追加后同步到磁盘
+func OpenFileWAL(path string, syncWrites bool) (*FileWAL, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create wal directory: %v", err)
+	}
+
+	w := &FileWAL{path: path, syncWrites: syncWrites}
+
+	// 读取最后的序号
+	if err := w.Replay(func(record WALRecord) error {
+		w.seq = record.Seq
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wal: %v", err)
+	}
+	w.file = file
+
+	return w, nil
+}
+
+// Append 追加一条记录
+func (w *FileWAL) Append(record WALRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("wal is closed")
+	}
+
+	record.Seq = w.seq + 1
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode wal record: %v", err)
+	}
+	data = append(data, '\n')
+
+	if _, err := w.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write wal record: %v", err)
+	}
+	if w.syncWrites {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync wal: %v", err)
+		}
+	}
+
+	w.seq = record.Seq
+	return nil
+}
+
+// Replay 按写入顺序回放所有记录
+// 文件末尾不完整的记录（写入过程中崩溃）会被忽略，中间损坏的记录返回错误
+func (w *FileWAL) Replay(fn func(record WALRecord) error) error {
+	file, err := os.Open(w.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open wal: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// 没有换行符结尾的记录是未写完的记录
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read wal: %v", err)
+		}
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		var record WALRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("corrupt wal record at line %d: %v", line, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// Close 关闭日志
+func (w *FileWAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
@@ -0,0 +1,262 @@
+package trading
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fixedPriceBroker 是按固定价格立即成交所有订单的测试券商
+type fixedPriceBroker struct {
+	price float64
+}
+
+func (b *fixedPriceBroker) Name() string { return "fixed" }
+
+func (b *fixedPriceBroker) SubmitOrder(ctx context.Context, order Order) (*Order, error) {
+	now := time.Now()
+	order.Status = OrderStatusFilled
+	order.FilledQty = order.Quantity
+	order.AvgFillPrice = b.price
+	order.FilledAt = &now
+	return &order, nil
+}
+
+func (b *fixedPriceBroker) CancelOrder(ctx context.Context, order Order) error { return nil }
+
+func (b *fixedPriceBroker) HealthCheck(ctx context.Context) error { return nil }
+
+func TestWALRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	ctx := context.Background()
+	limits := TradingLimits{MaxPositions: 10}
+
+	wal, err := OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+
+	broker := &fixedPriceBroker{price: 100}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, limits)
+	engine.SetBroker(broker)
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 10, 0, OrderTypeMarket, OrderSideBuy); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	broker.price = 110
+	if _, err := engine.SubmitOrder(ctx, "AAPL", 4, 0, OrderTypeMarket, OrderSideSell); err != nil {
+		t.Fatalf("卖出失败: %v", err)
+	}
+	wal.Close()
+
+	// 使用新的引擎回放日志
+	wal, err = OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, limits)
+	recovered.SetWAL(wal)
+	report, err := recovered.Recover()
+	if err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+
+	if report.Orders != 2 || report.Positions != 1 || len(report.Unresolved) != 0 {
+		t.Errorf("恢复报告不正确: %+v", report)
+	}
+	if recovered.IsEnabled() {
+		t.Error("恢复后引擎不应被自动启用")
+	}
+
+	pos, err := recovered.GetPosition(ctx, "AAPL")
+	if err != nil {
+		t.Fatalf("获取持仓失败: %v", err)
+	}
+	if pos.Quantity != 6 || pos.EntryPrice != 100 {
+		t.Errorf("持仓 = %d @ %.2f, 期望 6 @ 100", pos.Quantity, pos.EntryPrice)
+	}
+
+	account, _ := recovered.GetAccount(ctx)
+	if account.RealizedPnL != 40 {
+		t.Errorf("RealizedPnL = %.2f, 期望 40", account.RealizedPnL)
+	}
+
+	// 恢复后继续追加的记录序号应连续
+	recovered.Enable()
+	var last uint64
+	wal.Replay(func(record WALRecord) error {
+		last = record.Seq
+		return nil
+	})
+	if last != uint64(report.Records)+1 {
+		t.Errorf("最后的记录序号 = %d, 期望 %d", last, report.Records+1)
+	}
+}
+
+func TestWALRecoveryMatchesEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	ctx := context.Background()
+	limits := TradingLimits{MaxPositions: 10}
+
+	wal, err := OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	broker := &restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, limits)
+	engine.SetBroker(broker)
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	place := func(req OrderRequest) *Order {
+		t.Helper()
+		order, err := engine.PlaceOrder(ctx, req)
+		if err != nil {
+			t.Fatalf("下单失败: %+v %v", req, err)
+		}
+		return order
+	}
+
+	// 市价买入立即成交，限价买单挂单后由券商推送部分成交，另一个限价单撤销，最后市价卖出部分持仓
+	place(OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy})
+	resting := place(OrderRequest{Symbol: "AAPL", Quantity: 5, Price: 95, Type: OrderTypeLimit, Side: OrderSideBuy})
+	partial := *resting
+	partial.Status = OrderStatusPartial
+	partial.FilledQty = 2
+	partial.AvgFillPrice = 95
+	partial.Commission = 1
+	if err := engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: partial}); err != nil {
+		t.Fatalf("应用部分成交失败: %v", err)
+	}
+	canceled := place(OrderRequest{Symbol: "MSFT", Quantity: 4, Price: 200, Type: OrderTypeLimit, Side: OrderSideBuy})
+	if err := engine.CancelOrder(ctx, canceled.ID); err != nil {
+		t.Fatalf("撤单失败: %v", err)
+	}
+	broker.price = 110
+	place(OrderRequest{Symbol: "AAPL", Quantity: 4, Type: OrderTypeMarket, Side: OrderSideSell})
+	wal.Close()
+
+	wal, err = OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{}, limits)
+	recovered.SetWAL(wal)
+	report, err := recovered.Recover()
+	if err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	if report.Orders != 4 || report.Positions != 1 || len(report.Unresolved) != 0 {
+		t.Errorf("恢复报告不正确: %+v", report)
+	}
+
+	// 订单的状态、成交数量、均价和佣金与原引擎一致
+	for id, want := range engine.orders {
+		got, err := recovered.GetOrder(ctx, id)
+		if err != nil {
+			t.Errorf("恢复后缺少订单%s: %v", id, err)
+			continue
+		}
+		if got.Status != want.Status || got.FilledQty != want.FilledQty || got.AvgFillPrice != want.AvgFillPrice || got.Commission != want.Commission {
+			t.Errorf("订单%s不一致: 恢复 %+v, 原引擎 %+v", id, *got, want)
+		}
+	}
+	if got, _ := recovered.GetOrder(ctx, canceled.ID); got == nil || got.Status != OrderStatusCanceled {
+		t.Errorf("撤销的订单应恢复为已撤销: %+v", got)
+	}
+	open, _ := recovered.GetOpenOrders(ctx)
+	if len(open) != 1 || open[0].ID != resting.ID || open[0].FilledQty != 2 {
+		t.Errorf("恢复后应只有部分成交的限价单未完成: %+v", open)
+	}
+
+	// 持仓和现金与原引擎一致
+	wantPositions, _ := engine.GetPositions(ctx)
+	gotPositions, _ := recovered.GetPositions(ctx)
+	if len(gotPositions) != 1 || len(wantPositions) != 1 {
+		t.Fatalf("持仓数量不一致: 恢复 %+v, 原引擎 %+v", gotPositions, wantPositions)
+	}
+	if got, want := gotPositions[0], wantPositions[0]; got.Symbol != "AAPL" || got.Quantity != 8 || got.Quantity != want.Quantity || got.EntryPrice != want.EntryPrice {
+		t.Errorf("持仓不一致: 恢复 %+v, 原引擎 %+v", got, want)
+	}
+	wantAccount, _ := engine.GetAccount(ctx)
+	gotAccount, _ := recovered.GetAccount(ctx)
+	if gotAccount.Cash != wantAccount.Cash || gotAccount.RealizedPnL != wantAccount.RealizedPnL || gotAccount.Cash == 100000 {
+		t.Errorf("账户不一致: 恢复 cash=%.2f pnl=%.2f, 原引擎 cash=%.2f pnl=%.2f",
+			gotAccount.Cash, gotAccount.RealizedPnL, wantAccount.Cash, wantAccount.RealizedPnL)
+	}
+}
+
+func TestWALRecoveryTradeIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "engine.wal")
+	ctx := context.Background()
+	limits := TradingLimits{MaxPositions: 10}
+
+	wal, err := OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("打开预写日志失败: %v", err)
+	}
+	engine := NewBaseTradingEngine(nil, BrokerConfig{PositionMode: PositionModeHedging}, limits)
+	engine.SetBroker(&restingBroker{fixedPriceBroker: fixedPriceBroker{price: 100}})
+	engine.SetWAL(wal)
+	engine.Enable()
+
+	// 限价卖单分两次部分平仓，同一订单产生两笔交易
+	if _, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 10, Type: OrderTypeMarket, Side: OrderSideBuy, Strategy: "momentum"}); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	exit, err := engine.PlaceOrder(ctx, OrderRequest{Symbol: "AAPL", Quantity: 10, Price: 105, Type: OrderTypeLimit, Side: OrderSideSell, Strategy: "momentum"})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	for _, filled := range []int64{4, 10} {
+		update := *exit
+		update.Status = OrderStatusPartial
+		if filled == exit.Quantity {
+			update.Status = OrderStatusFilled
+		}
+		update.FilledQty = filled
+		update.AvgFillPrice = 105
+		if err := engine.ApplyOrderUpdate(ctx, OrderUpdate{Order: update}); err != nil {
+			t.Fatalf("应用成交失败: %v", err)
+		}
+	}
+	trades, _ := engine.GetTrades(ctx, "AAPL", time.Time{}, time.Now())
+	if len(trades) != 2 || trades[0].ID == trades[1].ID {
+		t.Fatalf("应产生两笔ID不同的交易: %+v", trades)
+	}
+	if _, err := engine.ReviewTrade(ctx, trades[1].ID, TradeReview{Notes: "按计划止盈"}); err != nil {
+		t.Fatalf("复盘失败: %v", err)
+	}
+	wal.Close()
+
+	wal, err = OpenFileWAL(path, true)
+	if err != nil {
+		t.Fatalf("重新打开预写日志失败: %v", err)
+	}
+	defer wal.Close()
+	recovered := NewBaseTradingEngine(nil, BrokerConfig{PositionMode: PositionModeHedging}, limits)
+	recovered.SetWAL(wal)
+	if _, err := recovered.Recover(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+
+	// 重启前返回的交易ID在恢复后仍然有效，复盘记录归属到同一笔交易
+	for _, want := range trades {
+		got, err := recovered.GetTrade(ctx, want.ID)
+		if err != nil || got.Quantity != want.Quantity || got.ExitOrder == nil || got.ExitOrder.ID != exit.ID {
+			t.Errorf("恢复后按ID查找交易%s失败: %+v %v", want.ID, got, err)
+		}
+	}
+	if got, _ := recovered.GetTrade(ctx, trades[1].ID); got == nil || got.Notes != "按计划止盈" {
+		t.Errorf("复盘记录应恢复到同一笔交易: %+v", got)
+	}
+	if got, _ := recovered.GetTrade(ctx, trades[0].ID); got == nil || got.Notes != "" {
+		t.Errorf("另一笔交易不应有复盘记录: %+v", got)
+	}
+}
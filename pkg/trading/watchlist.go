@@ -7,7 +7,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/yourusername/qhft-system/pkg/clock"
 	"github.com/yourusername/qhft-system/pkg/datasource"
+	"github.com/yourusername/qhft-system/pkg/events"
 	"github.com/yourusername/qhft-system/pkg/indicators"
 )
 
@@ -20,6 +22,14 @@ const (
 	WatchStatusTriggered WatchlistItemStatus = "triggered" // 已触发
 	WatchStatusExpired   WatchlistItemStatus = "expired"   // 已过期
 	WatchStatusInvalid   WatchlistItemStatus = "invalid"   // 无效的
+	WatchStatusPaused    WatchlistItemStatus = "paused"    // 已暂停，不检查触发条件和过期
+)
+
+// 监控列表事件类型常量
+const (
+	EventWatchlistTriggered = "watchlist_triggered" // 监控项已触发
+	EventWatchlistExpired   = "watchlist_expired"   // 监控项已过期
+	EventWatchlistExecuted  = "watchlist_executed"  // 监控项已下单
 )
 
 // WatchlistItem 表示监控项
@@ -41,6 +51,8 @@ type WatchlistItem struct {
 	Tags          []string             `json:"tags,omitempty"`
 	OrderID       string               `json:"order_id,omitempty"`
 	IsBuyList     bool                 `json:"is_buy_list"`
+	TimeExit      *TimeExit            `json:"time_exit,omitempty"` // 买入后持仓的定时平仓设置，覆盖策略和账户级设置
+	Metadata      map[string]string    `json:"metadata,omitempty"`  // 集成方附加的自定义元数据，执行时随订单提交
 }
 
 // Watchlist 表示监控列表（买入表或卖出表）
@@ -49,6 +61,9 @@ type Watchlist struct {
 	items      map[string]WatchlistItem
 	engine     TradingEngine
 	dataManager *datasource.Manager
+	eventBus   *events.Bus
+	ids        IDGenerator
+	clock      clock.Clock // 为空时使用系统时间
 }
 
 // NewWatchlist 创建新的监控列表
@@ -60,35 +75,101 @@ func NewWatchlist(engine TradingEngine, dataManager *datasource.Manager) *Watchl
 	}
 }
 
-// AddItem 添加监控项
+// SetEventBus 设置事件总线，监控项的触发、过期和下单会发布到总线上
+func (w *Watchlist) SetEventBus(bus *events.Bus) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.eventBus = bus
+}
+
+// SetIDGenerator 设置监控项的ID生成器，为空时使用默认生成器
+func (w *Watchlist) SetIDGenerator(generator IDGenerator) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ids = generator
+}
+
+// SetClock 设置监控列表的时钟，监控项的时间戳和过期判断使用该时钟，为空时使用系统时间
+func (w *Watchlist) SetClock(c clock.Clock) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.clock = c
+}
+
+// now 返回监控列表时钟的当前时间（内部方法）
+func (w *Watchlist) now() time.Time {
+	return clock.Or(w.clock).Now()
+}
+
+// newID 生成监控项ID，调用方需持有锁（内部方法）
+func (w *Watchlist) newID() string {
+	if w.ids == nil {
+		return DefaultIDGenerator.NewID("watch")
+	}
+	return w.ids.NewID("watch")
+}
+
+// publish 发布监控列表事件（内部方法）
+func (w *Watchlist) publish(eventType string, item WatchlistItem) {
+	w.mu.RLock()
+	bus := w.eventBus
+	w.mu.RUnlock()
+
+	bus.Publish(events.Event{
+		Topic:     events.TopicWatchlist,
+		Type:      eventType,
+		Timestamp: w.now(),
+		Payload:   item,
+	})
+}
+
+// AddItem 添加监控项，受限股票不能加入监控列表
 func (w *Watchlist) AddItem(item WatchlistItem) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	item, err := w.prepareItem(item)
+	if err != nil {
+		return err
+	}
+
+	// 存储项目
+	w.items[item.ID] = item
+
+	return nil
+}
+
+// prepareItem 验证监控项并设置默认值，调用方需持有锁（内部方法）
+func (w *Watchlist) prepareItem(item WatchlistItem) (WatchlistItem, error) {
 	// 验证必填字段
 	if item.Symbol == "" {
-		return errors.New("symbol is required")
+		return item, errors.New("symbol is required")
 	}
 	if item.Quantity <= 0 {
-		return errors.New("quantity must be positive")
+		return item, errors.New("quantity must be positive")
+	}
+	if err := ValidateMetadata(item.Metadata); err != nil {
+		return item, err
+	}
+	if w.engine != nil {
+		if err := w.engine.CheckRestricted(item.Symbol); err != nil {
+			return item, err
+		}
 	}
 
 	// 设置默认值
 	if item.ID == "" {
-		item.ID = fmt.Sprintf("watch-%s-%d", item.Symbol, time.Now().UnixNano())
+		item.ID = w.newID()
 	}
 	if item.Status == "" {
 		item.Status = WatchStatusActive
 	}
 	if item.AddedAt.IsZero() {
-		item.AddedAt = time.Now()
+		item.AddedAt = w.now()
 	}
-	item.UpdatedAt = time.Now()
-
-	// 存储项目
-	w.items[item.ID] = item
+	item.UpdatedAt = w.now()
 
-	return nil
+	return item, nil
 }
 
 // GetItem 获取监控项
@@ -128,10 +209,14 @@ func (w *Watchlist) UpdateItem(id string, updatedItem WatchlistItem) error {
 		return fmt.Errorf("item with ID '%s' not found", id)
 	}
 
+	if err := ValidateMetadata(updatedItem.Metadata); err != nil {
+		return err
+	}
+
 	// 保留不可修改的字段
 	updatedItem.ID = item.ID
 	updatedItem.AddedAt = item.AddedAt
-	updatedItem.UpdatedAt = time.Now()
+	updatedItem.UpdatedAt = w.now()
 
 	// 存储更新后的项目
 	w.items[id] = updatedItem
@@ -180,6 +265,20 @@ func (w *Watchlist) GetActiveItems() []WatchlistItem {
 	return activeItems
 }
 
+// fetchQuotes 批量获取监控项的最新报价，获取失败的股票不包含在结果中（内部方法）
+func (w *Watchlist) fetchQuotes(ctx context.Context, items []WatchlistItem) map[string]*datasource.Quote {
+	if len(items) == 0 || w.dataManager == nil {
+		return nil
+	}
+	symbols := make([]string, 0, len(items))
+	for _, item := range items {
+		symbols = append(symbols, item.Symbol)
+	}
+	// 扫描可以容忍数据源出错时使用过期的缓存数据
+	quotes, _ := w.dataManager.GetRealTimeQuotes(datasource.AcceptStale(ctx), symbols)
+	return quotes
+}
+
 // ScanWatchlist 扫描监控列表中的股票
 func (w *Watchlist) ScanWatchlist(ctx context.Context) ([]WatchlistItem, error) {
 	// 获取活跃的监控项
@@ -189,25 +288,27 @@ func (w *Watchlist) ScanWatchlist(ctx context.Context) ([]WatchlistItem, error)
 	var updatedItems []WatchlistItem
 	var triggeredItems []WatchlistItem
 	
+	// 批量获取最新价格
+	quotes := w.fetchQuotes(ctx, activeItems)
+	
 	// 逐个检查监控项
 	for _, item := range activeItems {
 		// 跳过已过期的项目
-		if item.ExpiresAt != nil && item.ExpiresAt.Before(time.Now()) {
+		if item.ExpiresAt != nil && item.ExpiresAt.Before(w.now()) {
 			item.Status = WatchStatusExpired
-			item.UpdatedAt = time.Now()
+			item.UpdatedAt = w.now()
 			updatedItems = append(updatedItems, item)
 			continue
 		}
 		
-		// 获取最新价格
-		ds, err := w.dataManager.GetPrimaryDataSource()
-		if err != nil {
-			continue // 跳过无法获取数据源的项目
+		quote, ok := quotes[item.Symbol]
+		if !ok {
+			continue // 跳过无法获取报价的项目
 		}
 		
-		quote, err := ds.GetRealTimeQuote(ctx, item.Symbol)
-		if err != nil {
-			continue // 跳过无法获取报价的项目
+		// 停牌期间暂停触发，恢复交易后继续检查
+		if w.engine != nil && w.engine.CheckHalted(item.Symbol) != nil {
+			continue
 		}
 		
 		lastPrice := quote.LastPrice
@@ -231,21 +332,32 @@ func (w *Watchlist) ScanWatchlist(ctx context.Context) ([]WatchlistItem, error)
 		}
 		
 		if triggered {
-			now := time.Now()
+			now := w.now()
 			item.Status = WatchStatusTriggered
 			item.TriggeredAt = &now
 			item.UpdatedAt = now
 			
-			triggeredItems = append(triggeredItems, item)
 			updatedItems = append(updatedItems, item)
 		}
 	}
 	
-	// 更新状态已改变的项目
+	// 更新状态已改变的项目，扫描期间被暂停或删除的项目不再更新
 	for _, item := range updatedItems {
 		w.mu.Lock()
+		current, exists := w.items[item.ID]
+		if !exists || current.Status != WatchStatusActive {
+			w.mu.Unlock()
+			continue
+		}
 		w.items[item.ID] = item
 		w.mu.Unlock()
+
+		if item.Status == WatchStatusTriggered {
+			triggeredItems = append(triggeredItems, item)
+			w.publish(EventWatchlistTriggered, item)
+		} else if item.Status == WatchStatusExpired {
+			w.publish(EventWatchlistExpired, item)
+		}
 	}
 	
 	return triggeredItems, nil
@@ -260,11 +372,26 @@ func (w *Watchlist) ExecuteWatchlistItems(ctx context.Context, triggeredItems []
 		var order *Order
 		
 		if item.IsBuyList {
-			// 买入表项目，执行买入
-			order, err = w.engine.SubmitOrder(ctx, item.Symbol, item.Quantity, 0, OrderTypeMarket, OrderSideBuy)
+			// 买入表项目，执行买入，监控项的止损、止盈和定时平仓设置作为括号订单带到持仓上
+			order, err = w.engine.PlaceOrder(ctx, OrderRequest{
+				Symbol:     item.Symbol,
+				Quantity:   item.Quantity,
+				Type:       OrderTypeMarket,
+				Side:       OrderSideBuy,
+				StopLoss:   item.StopLoss,
+				TakeProfit: item.TakeProfit,
+				TimeExit:   item.TimeExit,
+				Metadata:   item.Metadata,
+			})
 		} else {
 			// 卖出表项目，执行卖出
-			order, err = w.engine.SubmitOrder(ctx, item.Symbol, item.Quantity, 0, OrderTypeMarket, OrderSideSell)
+			order, err = w.engine.PlaceOrder(ctx, OrderRequest{
+				Symbol:   item.Symbol,
+				Quantity: item.Quantity,
+				Type:     OrderTypeMarket,
+				Side:     OrderSideSell,
+				Metadata: item.Metadata,
+			})
 		}
 		
 		if err != nil {
@@ -274,11 +401,13 @@ func (w *Watchlist) ExecuteWatchlistItems(ctx context.Context, triggeredItems []
 		
 		// 更新监控项状态
 		item.OrderID = order.ID
-		item.UpdatedAt = time.Now()
+		item.UpdatedAt = w.now()
 		
 		w.mu.Lock()
 		w.items[item.ID] = item
 		w.mu.Unlock()
+
+		w.publish(EventWatchlistExecuted, item)
 	}
 	
 	return errors
@@ -0,0 +1,159 @@
+package trading
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// WatchlistFilter 表示批量操作选择监控项的条件，多个条件同时满足才选中，零值选中所有监控项
+type WatchlistFilter struct {
+	IDs      []string            `json:"ids,omitempty"`
+	Symbols  []string            `json:"symbols,omitempty"`
+	Tag      string              `json:"tag,omitempty"`      // 包含该标签的监控项
+	Strategy string              `json:"strategy,omitempty"` // 策略名称
+	Status   WatchlistItemStatus `json:"status,omitempty"`
+	Before   time.Time           `json:"before,omitempty"` // 在该时间之前添加的监控项
+}
+
+// Match 检查监控项是否满足过滤条件
+func (f WatchlistFilter) Match(item WatchlistItem) bool {
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, item.ID) {
+		return false
+	}
+	if len(f.Symbols) > 0 && !slices.Contains(f.Symbols, item.Symbol) {
+		return false
+	}
+	if f.Tag != "" && !slices.Contains(item.Tags, f.Tag) {
+		return false
+	}
+	if f.Strategy != "" && item.Strategy != f.Strategy {
+		return false
+	}
+	if f.Status != "" && item.Status != f.Status {
+		return false
+	}
+	if !f.Before.IsZero() && !item.AddedAt.Before(f.Before) {
+		return false
+	}
+	return true
+}
+
+// AddItems 在一次加锁中添加多个监控项，任何一项验证失败时都不添加，返回添加后的监控项（包括生成的ID）
+func (w *Watchlist) AddItems(items []WatchlistItem) ([]WatchlistItem, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	added := make([]WatchlistItem, 0, len(items))
+	ids := make(map[string]bool, len(items))
+	for i, item := range items {
+		prepared, err := w.prepareItem(item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d (%s): %w", i, item.Symbol, err)
+		}
+		if ids[prepared.ID] {
+			return nil, fmt.Errorf("item %d (%s): duplicate ID '%s'", i, item.Symbol, prepared.ID)
+		}
+		ids[prepared.ID] = true
+		added = append(added, prepared)
+	}
+	for _, item := range added {
+		w.items[item.ID] = item
+	}
+	return added, nil
+}
+
+// PauseItems 暂停满足条件的活跃监控项，暂停期间不检查触发条件和过期，返回被暂停的监控项
+func (w *Watchlist) PauseItems(filter WatchlistFilter) []WatchlistItem {
+	return w.updateItems(filter, func(item *WatchlistItem) bool {
+		if item.Status != WatchStatusActive {
+			return false
+		}
+		item.Status = WatchStatusPaused
+		return true
+	})
+}
+
+// ResumeItems 恢复满足条件的已暂停监控项，暂停期间已过期的监控项在下一次扫描时过期，返回被恢复的监控项
+func (w *Watchlist) ResumeItems(filter WatchlistFilter) []WatchlistItem {
+	return w.updateItems(filter, func(item *WatchlistItem) bool {
+		if item.Status != WatchStatusPaused {
+			return false
+		}
+		item.Status = WatchStatusActive
+		return true
+	})
+}
+
+// ExtendExpiries 将满足条件的活跃和已暂停监控项的过期时间延后d，没有过期时间的监控项不变，返回被修改的监控项
+func (w *Watchlist) ExtendExpiries(filter WatchlistFilter, d time.Duration) []WatchlistItem {
+	return w.updateItems(filter, func(item *WatchlistItem) bool {
+		if item.ExpiresAt == nil || (item.Status != WatchStatusActive && item.Status != WatchStatusPaused) {
+			return false
+		}
+		expiresAt := item.ExpiresAt.Add(d)
+		item.ExpiresAt = &expiresAt
+		return true
+	})
+}
+
+// RemoveItems 删除满足条件的监控项，返回被删除的监控项
+func (w *Watchlist) RemoveItems(filter WatchlistFilter) []WatchlistItem {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var removed []WatchlistItem
+	for id, item := range w.items {
+		if filter.Match(item) {
+			removed = append(removed, item)
+			delete(w.items, id)
+		}
+	}
+	sortWatchlistItems(removed)
+	return removed
+}
+
+// FilterItems 返回满足条件的监控项，按添加时间排序
+func (w *Watchlist) FilterItems(filter WatchlistFilter) []WatchlistItem {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var items []WatchlistItem
+	for _, item := range w.items {
+		if filter.Match(item) {
+			items = append(items, item)
+		}
+	}
+	sortWatchlistItems(items)
+	return items
+}
+
+// updateItems 在一次加锁中修改满足条件的监控项，update 返回 false 的监控项不修改（内部方法）
+func (w *Watchlist) updateItems(filter WatchlistFilter, update func(item *WatchlistItem) bool) []WatchlistItem {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	var updated []WatchlistItem
+	for id, item := range w.items {
+		if !filter.Match(item) || !update(&item) {
+			continue
+		}
+		item.UpdatedAt = now
+		w.items[id] = item
+		updated = append(updated, item)
+	}
+	sortWatchlistItems(updated)
+	return updated
+}
+
+// sortWatchlistItems 按添加时间和ID排序（内部函数）
+func sortWatchlistItems(items []WatchlistItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].AddedAt.Equal(items[j].AddedAt) {
+			return items[i].AddedAt.Before(items[j].AddedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+}
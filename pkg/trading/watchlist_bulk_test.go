@@ -0,0 +1,78 @@
+package trading
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/qhft-system/pkg/clock"
+)
+
+func TestWatchlistBulkOperations(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	manual := clock.NewManual(start)
+	engine := NewBaseTradingEngine(nil, BrokerConfig{}, TradingLimits{MaxPositions: 10})
+	if err := engine.AddRestrictedSymbol("GME", "hard to borrow", time.Time{}); err != nil {
+		t.Fatalf("添加受限股票失败: %v", err)
+	}
+	watchlist := NewWatchlist(engine, nil)
+	watchlist.SetClock(manual)
+
+	expires := start.Add(time.Hour)
+	added, err := watchlist.AddItems([]WatchlistItem{
+		{Symbol: "AAPL", Quantity: 10, Tags: []string{"earnings"}, ExpiresAt: &expires, IsBuyList: true, TargetPrice: 1},
+		{Symbol: "MSFT", Quantity: 5, Tags: []string{"earnings", "tech"}},
+		{Symbol: "XOM", Quantity: 20, Strategy: "value", ExpiresAt: &expires},
+	})
+	if err != nil || len(added) != 3 || added[0].ID == "" || added[0].Status != WatchStatusActive {
+		t.Fatalf("批量添加失败: %+v %v", added, err)
+	}
+
+	// 任何一项验证失败时都不添加
+	if _, err := watchlist.AddItems([]WatchlistItem{{Symbol: "NVDA", Quantity: 1}, {Symbol: "GME", Quantity: 1}}); err == nil {
+		t.Error("包含受限股票时应返回错误")
+	}
+	if _, err := watchlist.AddItems([]WatchlistItem{{ID: "dup", Symbol: "NVDA", Quantity: 1}, {ID: "dup", Symbol: "AMD", Quantity: 1}}); err == nil {
+		t.Error("重复的ID应返回错误")
+	}
+	if n := len(watchlist.GetAllItems()); n != 3 {
+		t.Fatalf("验证失败时不应添加任何监控项: %d", n)
+	}
+
+	manual.Advance(time.Minute)
+	earnings := WatchlistFilter{Tag: "earnings"}
+	if paused := watchlist.PauseItems(earnings); len(paused) != 2 || paused[0].Symbol != "AAPL" || !paused[0].UpdatedAt.Equal(manual.Now()) {
+		t.Errorf("应暂停带标签的监控项: %+v", paused)
+	}
+	if paused := watchlist.PauseItems(earnings); len(paused) != 0 {
+		t.Errorf("已暂停的监控项不应重复暂停: %+v", paused)
+	}
+	if active := watchlist.GetActiveItems(); len(active) != 1 || active[0].Symbol != "XOM" {
+		t.Errorf("暂停的监控项不应是活跃的: %+v", active)
+	}
+
+	// 暂停期间不检查触发条件
+	if triggered, _ := watchlist.ScanWatchlist(context.Background()); len(triggered) != 0 {
+		t.Errorf("暂停的监控项不应触发: %+v", triggered)
+	}
+
+	extended := watchlist.ExtendExpiries(WatchlistFilter{}, 24*time.Hour)
+	if len(extended) != 2 || !extended[0].ExpiresAt.Equal(expires.Add(24*time.Hour)) {
+		t.Errorf("应延后有过期时间的监控项: %+v", extended)
+	}
+	if !expires.Equal(start.Add(time.Hour)) {
+		t.Error("延后过期时间不应修改原来的时间")
+	}
+
+	if resumed := watchlist.ResumeItems(WatchlistFilter{Tag: "tech"}); len(resumed) != 1 || resumed[0].Status != WatchStatusActive {
+		t.Errorf("应恢复带标签的已暂停监控项: %+v", resumed)
+	}
+
+	removed := watchlist.RemoveItems(WatchlistFilter{Status: WatchStatusActive, Before: start.Add(time.Second)})
+	if len(removed) != 2 || removed[0].Symbol != "MSFT" || removed[1].Symbol != "XOM" {
+		t.Errorf("应删除满足所有条件的监控项: %+v", removed)
+	}
+	if remaining := watchlist.FilterItems(WatchlistFilter{}); len(remaining) != 1 || remaining[0].Status != WatchStatusPaused {
+		t.Errorf("剩余的监控项不正确: %+v", remaining)
+	}
+}